@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -135,9 +136,14 @@ func main() {
 	// --- Initialize Dependencies & Services ---
 	querier := db.New(dbConn) // sqlc generated querier
 	otpStore := auth.NewInMemoryOTPStore()
-	messageSender, err := outbox.NewLogFileMessageSender(cfg.OTPLogPath, logger)
+	messageSender, err := outbox.NewSMSSender(cfg, logger)
 	if err != nil {
-		slog.Error("Failed to create LogFileMessageSender", "path", cfg.OTPLogPath, "error", err)
+		slog.Error("Failed to create SMS sender", "provider", cfg.SMSProvider, "error", err)
+		os.Exit(1)
+	}
+	emailSender, err := outbox.NewEmailSender(cfg, logger)
+	if err != nil {
+		slog.Error("Failed to create email sender", "smtp_host", cfg.SMTPHost, "error", err)
 		os.Exit(1)
 	}
 
@@ -156,18 +162,49 @@ func main() {
 
 	userService := service.NewUserService(querier, otpStore, cfg, logger)
 	scheduleService := service.NewScheduleService(querier, logger, cfg)
+	notificationPreferencesService := service.NewNotificationPreferencesService(querier, cfg, logger)
 	pointsService := service.NewPointsService(querier, logger)
 	bookingService := service.NewBookingService(querier, cfg, logger, pointsService)
-	reportService := service.NewReportService(querier, logger, pointsService)
-	reportArchivingService := service.NewReportArchivingService(querier, logger)
+	bookingService.SetReminderScheduler(service.NewScheduler(querier, notificationPreferencesService, logger))
+	reportAbuseService := service.NewReportAbuseService(querier, logger)
+	reportService := service.NewReportService(querier, logger, pointsService, reportAbuseService)
+	reportArchivingService := service.NewReportArchivingService(querier, auditService, logger)
+	reportGeoService := service.NewReportGeoService(querier, logger)
+	reportExportService := service.NewReportExportService(reportGeoService, auditService, logger)
+	reportPDFService := service.NewReportPDFService(querier, reportGeoService, auditService, cfg.MapTileCacheDir, logger)
+	dataExportService := service.NewDataExportService(querier, auditService, logger)
 	adminDashboardService := service.NewAdminDashboardService(querier, scheduleService, logger)
-	broadcastService := service.NewBroadcastService(querier, logger, cfg)
+	broadcastService := service.NewBroadcastService(querier, notificationPreferencesService, scheduleService, logger, cfg)
 	emergencyContactService := service.NewEmergencyContactService(querier, logger)
+	incidentCategoryService := service.NewIncidentCategoryService(querier, logger)
+	incidentEscalationService := service.NewIncidentEscalationService(querier, emergencyContactService, cfg, logger)
+	reportService.SetEscalationService(incidentEscalationService)
+	reportDuplicateService := service.NewReportDuplicateService(querier, pointsService, logger)
+	reportService.SetDuplicateService(reportDuplicateService)
+	patrolTrackingService := service.NewPatrolTrackingService(querier, cfg, logger)
+	handoverService := service.NewHandoverService(querier, cfg, logger)
 
 	// Instantiate PushSender service
 	pushSenderService := service.NewPushSender(querier, cfg, logger)
 
-	outboxDispatcherService := outbox.NewDispatcherService(querier, messageSender, pushSenderService, logger, cfg)
+	outboxDispatcherService := outbox.NewDispatcherService(querier, messageSender, pushSenderService, emailSender, notificationPreferencesService, logger, cfg)
+	sosService := service.NewSOSService(querier, reportService, incidentEscalationService, outboxDispatcherService, logger)
+	tipService := service.NewTipService(querier, cfg, logger)
+	watchlistService := service.NewWatchlistService(querier, cfg, logger)
+	outboxDeadLetterService := service.NewOutboxDeadLetterService(querier, logger)
+	userEmailService := service.NewUserEmailService(querier, logger)
+	broadcastGroupService := service.NewBroadcastGroupService(querier, logger)
+	broadcastTemplateService := service.NewBroadcastTemplateService(querier, logger)
+	reportService.SetWatchlistService(watchlistService)
+	tipService.SetWatchlistService(watchlistService)
+
+	// Real-time event stream fed by the services that change shared state
+	eventBroker := service.NewEventBroker(service.DefaultEventBufferSize, logger)
+	bookingService.SetEventBroker(eventBroker)
+	reportService.SetEventBroker(eventBroker)
+	broadcastService.SetEventBroker(eventBroker)
+	sosService.SetEventBroker(eventBroker)
+	watchlistService.SetEventBroker(eventBroker)
 
 	pushAPIHandler := api.NewPushHandler(querier, cfg, logger)
 
@@ -203,7 +240,8 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Auto-archive old reports daily at 2 AM
+	// Apply report retention policies daily at 2 AM: archive old reports, then purge expired archives
+	// and drop stale off-shift report and tip line rate limiting records
 	_, err = cronScheduler.AddFunc("0 2 * * *", func() {
 		ctx := context.Background()
 		archived, err := reportArchivingService.ArchiveOldReports(ctx)
@@ -212,28 +250,62 @@ func main() {
 		} else if archived > 0 {
 			slog.Info("Successfully auto-archived old reports", "archived_count", archived)
 		}
+
+		purged, err := reportArchivingService.PurgeArchivedReports(ctx, false, nil, "", "")
+		if err != nil {
+			slog.Error("Failed to purge expired archived reports", "error", err)
+		} else if len(purged.Reports) > 0 {
+			slog.Info("Successfully purged expired archived reports", "deleted", purged.Deleted, "anonymised", purged.Anonymised)
+		}
+
+		// Off-shift report submissions only matter inside the rate limit window
+		if _, err := reportService.CleanupOffShiftReportSubmissions(ctx, 24*time.Hour); err != nil {
+			slog.Error("Failed to clean up old off-shift report submissions", "error", err)
+		}
+		if _, err := tipService.CleanupOldSubmissions(ctx, 24*time.Hour); err != nil {
+			slog.Error("Failed to clean up old tip submissions", "error", err)
+		}
 	})
 	if err != nil {
 		slog.Error("Failed to add report archiving job to cron", "error", err)
 		os.Exit(1)
 	}
 
-	// Add the ProcessPendingOutboxItems job
-	_, err = cronScheduler.AddFunc("@every 1m", func() {
-		ctx := context.Background()
-		processed, errCount := outboxDispatcherService.ProcessPendingOutboxItems(ctx)
-		if errCount > 0 {
-			slog.ErrorContext(ctx, "Failed to process some outbox items", "processed", processed, "errors", errCount)
-		} else {
-			slog.InfoContext(ctx, "Processed outbox items successfully", "count", processed)
+	// Stop live location sharing for shifts that have ended every 5 minutes
+	_, err = cronScheduler.AddFunc("@every 5m", func() {
+		if _, err := patrolTrackingService.StopEndedSharing(context.Background(), time.Now()); err != nil {
+			slog.Error("Failed to stop location sharing for ended shifts", "error", err)
+		}
+	})
+	if err != nil {
+		slog.Error("Failed to add location sharing expiry job to cron", "error", err)
+		os.Exit(1)
+	}
+
+	// Purge patrol breadcrumbs past their retention period daily at 3 AM
+	_, err = cronScheduler.AddFunc("0 3 * * *", func() {
+		if _, err := patrolTrackingService.PurgeOldLocations(context.Background(), time.Now()); err != nil {
+			slog.Error("Failed to purge old patrol locations", "error", err)
 		}
 	})
 	if err != nil {
-		slog.ErrorContext(context.Background(), "Failed to schedule outbox processing job", "error", err)
+		slog.Error("Failed to add patrol location purge job to cron", "error", err)
+		os.Exit(1)
+	}
+
+	// Alert owls to shifts in the next 24 hours that nobody has booked, daily at 4 PM
+	_, err = cronScheduler.AddFunc("0 16 * * *", func() {
+		if _, err := adminDashboardService.NotifyCoverageGaps(context.Background()); err != nil {
+			slog.Error("Failed to send coverage alerts", "error", err)
+		}
+	})
+	if err != nil {
+		slog.Error("Failed to add coverage alert job to cron", "error", err)
+		os.Exit(1)
 	}
 
 	cronScheduler.Start()
-	slog.Info("Cron scheduler started for outbox processing, broadcasts, and report archiving.")
+	slog.Info("Cron scheduler started for outbox processing, broadcasts, report archiving, patrol tracking and coverage alerts.")
 
 	// --- Setup HTTP Router & Handlers ---
 	s := fuego.NewServer(
@@ -265,12 +337,16 @@ func main() {
 			start := time.Now()
 			wrapper := w // fuego does not have chi's WrapResponseWriter, so use w directly
 			next.ServeHTTP(wrapper, r)
+			remoteAddr := r.RemoteAddr
+			if strings.HasPrefix(r.URL.Path, apiPrefix+"/tips") {
+				remoteAddr = "" // The tip line is anonymous, so never log who used it
+			}
 			slog.Info("HTTP request",
 				"method", r.Method,
 				"path", r.URL.Path,
 				// No status or bytes_written without wrapper, unless custom ResponseWriter is implemented
 				"latency_ms", time.Since(start).Milliseconds(),
-				"remote_addr", r.RemoteAddr,
+				"remote_addr", remoteAddr,
 			)
 		})
 	})
@@ -283,14 +359,34 @@ func main() {
 	adminScheduleAPIHandler := api.NewAdminScheduleHandlers(logger, scheduleService, auditService)
 	adminUserAPIHandler := api.NewAdminUserHandler(querier, auditService, logger)
 	adminBookingAPIHandler := api.NewAdminBookingHandler(bookingService, logger)
-	adminReportAPIHandler := api.NewAdminReportHandler(reportService, scheduleService, querier, auditService, logger)
-	adminBroadcastAPIHandler := api.NewAdminBroadcastHandler(querier, logger)
-	broadcastAPIHandler := api.NewBroadcastHandler(querier, logger)
+	adminReportAPIHandler := api.NewAdminReportHandler(reportService, scheduleService, handoverService, querier, auditService, logger)
+	adminReportGeoAPIHandler := api.NewAdminReportGeoHandler(reportGeoService, reportExportService, logger)
+	adminReportPDFAPIHandler := api.NewAdminReportPDFHandler(reportPDFService, logger)
+	adminDataExportAPIHandler := api.NewAdminDataExportHandler(dataExportService, logger)
+	adminBroadcastAPIHandler := api.NewAdminBroadcastHandler(querier, broadcastService, logger)
+	adminBroadcastGroupAPIHandler := api.NewAdminBroadcastGroupHandler(broadcastGroupService, logger)
+	adminBroadcastTemplateAPIHandler := api.NewAdminBroadcastTemplateHandler(broadcastTemplateService, logger)
+	adminPushAPIHandler := api.NewAdminPushHandler(pushSenderService, logger)
+	broadcastAPIHandler := api.NewBroadcastHandler(querier, broadcastService, logger)
 	adminDashboardAPIHandler := api.NewAdminDashboardHandler(adminDashboardService, logger)
 	emergencyContactAPIHandler := api.NewEmergencyContactHandler(emergencyContactService, logger)
+	incidentCategoryAPIHandler := api.NewIncidentCategoryHandler(incidentCategoryService, logger)
+	incidentEscalationAPIHandler := api.NewIncidentEscalationHandler(incidentEscalationService, auditService, logger)
+	adminReportDuplicateAPIHandler := api.NewAdminReportDuplicateHandler(reportDuplicateService, auditService, logger)
+	adminRetentionAPIHandler := api.NewAdminRetentionHandler(reportArchivingService, auditService, logger)
+	patrolTrackingAPIHandler := api.NewPatrolTrackingHandler(patrolTrackingService, logger)
+	handoverAPIHandler := api.NewHandoverHandler(handoverService, logger)
+	sosAPIHandler := api.NewSOSHandler(sosService, auditService, logger)
+	tipAPIHandler := api.NewTipHandler(tipService, auditService, logger)
+	smsStatusHandler := api.NewSMSStatusHandler(messageSender, outboxDispatcherService, logger)
+	adminOutboxAPIHandler := api.NewAdminOutboxHandler(outboxDeadLetterService, auditService, logger)
+	userEmailAPIHandler := api.NewUserEmailHandler(userEmailService, logger)
+	notificationPreferencesAPIHandler := api.NewNotificationPreferencesHandler(notificationPreferencesService, logger)
+	adminWatchlistAPIHandler := api.NewAdminWatchlistHandler(watchlistService, auditService, logger)
+	eventStreamAPIHandler := api.NewEventStreamHandler(eventBroker, logger)
 	adminAuditAPIHandler := api.NewAdminAuditHandler(auditService, querier, logger)
 	leaderboardAPIHandler := api.NewLeaderboardHandler(pointsService, logger)
-	calendarAPIHandler := api.NewCalendarHandler(bookingService, querier, logger)
+	calendarAPIHandler := api.NewCalendarHandler(bookingService, handoverService, querier, logger)
 
 	// Debug: Check handler initialization
 	logger.Info("Handler initialization", "booking_handler_nil", bookingAPIHandler == nil, "report_handler_nil", reportAPIHandler == nil, "calendar_handler_nil", calendarAPIHandler == nil)
@@ -317,6 +413,13 @@ func main() {
 	fuego.GetStd(publicAPI, "/emergency-contacts", emergencyContactAPIHandler.GetEmergencyContactsHandler)
 	fuego.GetStd(publicAPI, "/emergency-contacts/default", emergencyContactAPIHandler.GetDefaultEmergencyContactHandler)
 
+	// Anonymous tip line (public access, proof of work and rate limited)
+	fuego.GetStd(publicAPI, "/tips/challenge", tipAPIHandler.GetTipChallengeHandler)
+	fuego.PostStd(publicAPI, "/tips", tipAPIHandler.SubmitTipHandler)
+
+	// SMS delivery report callbacks (authenticated by provider signature or token)
+	fuego.PostStd(publicAPI, "/sms/status/{provider}", smsStatusHandler.SMSStatusCallbackHandler)
+
 	// Health check endpoints for monitoring
 	fuego.GetStd(s, "/health", func(w http.ResponseWriter, r *http.Request) {
 		// Check database connectivity
@@ -369,11 +472,24 @@ func main() {
 	fuego.Post(protected, "/bookings", bookingAPIHandler.CreateBookingFuego)
 	fuego.GetStd(protected, "/bookings/my", bookingAPIHandler.GetMyBookingsHandler)
 	fuego.Post(protected, "/bookings/{id}/checkin", bookingAPIHandler.MarkCheckInFuego)
+	fuego.PostStd(protected, "/bookings/{id}/checkout", bookingAPIHandler.MarkCheckOutHandler)
+	fuego.PostStd(protected, "/bookings/{id}/location-sharing", patrolTrackingAPIHandler.StartSharingHandler)
+	fuego.DeleteStd(protected, "/bookings/{id}/location-sharing", patrolTrackingAPIHandler.StopSharingHandler)
+	fuego.PostStd(protected, "/bookings/{id}/locations", patrolTrackingAPIHandler.RecordLocationsHandler)
+	fuego.PutStd(protected, "/bookings/{id}/handover", handoverAPIHandler.SaveHandoverNoteHandler)
+	fuego.GetStd(protected, "/bookings/{id}/handover", handoverAPIHandler.GetHandoverNoteHandler)
+	fuego.GetStd(protected, "/bookings/{id}/handover/received", handoverAPIHandler.ListReceivedHandoverNotesHandler)
 	fuego.Delete(protected, "/bookings/{id}", bookingAPIHandler.CancelBookingFuego)
 	fuego.PostStd(protected, "/bookings/{id}/report", reportAPIHandler.CreateReportHandler)
 	fuego.PostStd(protected, "/reports/off-shift", reportAPIHandler.CreateOffShiftReportHandler)
+	fuego.PostStd(protected, "/reports/{id}/acknowledge", incidentEscalationAPIHandler.AcknowledgeEscalationHandler)
+	fuego.PostStd(protected, "/sos", sosAPIHandler.RaiseSOSHandler)
+	fuego.PostStd(protected, "/sos/{id}/acknowledge", sosAPIHandler.AcknowledgeSOSHandler)
+	fuego.GetStd(protected, "/events", eventStreamAPIHandler.StreamEventsHandler)
 	fuego.GetStd(protected, "/user/reports", reportAPIHandler.ListReportsHandler)
+	fuego.GetStd(protected, "/incident-categories", incidentCategoryAPIHandler.ListIncidentCategoriesHandler)
 	fuego.GetStd(protected, "/broadcasts", broadcastAPIHandler.ListUserBroadcasts)
+	fuego.PostStd(protected, "/broadcasts/{id}/read", broadcastAPIHandler.AcknowledgeBroadcast)
 	fuego.PostStd(protected, "/push/subscribe", pushAPIHandler.SubscribePush)
 	fuego.DeleteStd(protected, "/push/subscribe/{endpoint}", pushAPIHandler.UnsubscribePush)
 
@@ -385,6 +501,12 @@ func main() {
 	fuego.GetStd(protected, "/user/points/history", leaderboardAPIHandler.GetUserPointsHistoryHandler)
 	fuego.GetStd(protected, "/user/achievements", leaderboardAPIHandler.GetUserAchievementsHandler)
 	fuego.GetStd(protected, "/user/achievements/available", leaderboardAPIHandler.GetAvailableAchievementsHandler)
+	fuego.GetStd(protected, "/user/email", userEmailAPIHandler.GetEmailHandler)
+	fuego.PutStd(protected, "/user/email", userEmailAPIHandler.SetEmailHandler)
+	fuego.PostStd(protected, "/user/email/verify", userEmailAPIHandler.VerifyEmailHandler)
+	fuego.DeleteStd(protected, "/user/email", userEmailAPIHandler.DeleteEmailHandler)
+	fuego.GetStd(protected, "/user/notification-preferences", notificationPreferencesAPIHandler.GetPreferencesHandler)
+	fuego.PutStd(protected, "/user/notification-preferences", notificationPreferencesAPIHandler.UpdatePreferencesHandler)
 
 	// Calendar routes (require auth)
 	logger.Info("Registering calendar routes", "handler_nil", calendarAPIHandler == nil)
@@ -413,6 +535,9 @@ func main() {
 	fuego.PostStd(admin, "/users", adminUserAPIHandler.AdminCreateUser)
 	fuego.GetStd(admin, "/users/{id}", adminUserAPIHandler.AdminGetUser)
 	fuego.GetStd(admin, "/users/{userId}/bookings", adminBookingAPIHandler.GetUserBookingsHandler)
+	fuego.GetStd(admin, "/users/{id}/trust-score", adminReportAPIHandler.AdminGetReporterTrustHandler)
+	fuego.GetStd(admin, "/users/{id}/push-devices", adminPushAPIHandler.AdminListUserPushDevices)
+	fuego.PostStd(admin, "/users/{id}/push-devices/{deviceId}/test", adminPushAPIHandler.AdminTestUserPushDevice)
 	fuego.PutStd(admin, "/users/{id}", adminUserAPIHandler.AdminUpdateUser)
 	fuego.DeleteStd(admin, "/users/{id}", adminUserAPIHandler.AdminDeleteUser)
 	fuego.PostStd(admin, "/users/bulk-delete", adminUserAPIHandler.AdminBulkDeleteUsers)
@@ -424,16 +549,46 @@ func main() {
 	// Admin Reports
 	fuego.GetStd(admin, "/reports", adminReportAPIHandler.AdminListReportsHandler)
 	fuego.GetStd(admin, "/reports/archived", adminReportAPIHandler.AdminListArchivedReportsHandler)
+	fuego.GetStd(admin, "/reports/heatmap", adminReportGeoAPIHandler.AdminReportHeatmapHandler)
+	fuego.GetStd(admin, "/reports/export", adminReportGeoAPIHandler.AdminExportReportsHandler)
+	fuego.GetStd(admin, "/reports/pdf", adminReportPDFAPIHandler.AdminReportsPDFHandler)
 	fuego.GetStd(admin, "/reports/{id}", adminReportAPIHandler.AdminGetReportHandler)
 	fuego.PutStd(admin, "/reports/{id}/archive", adminReportAPIHandler.AdminArchiveReportHandler)
 	fuego.PutStd(admin, "/reports/{id}/unarchive", adminReportAPIHandler.AdminUnarchiveReportHandler)
+	fuego.PutStd(admin, "/reports/{id}/flag", adminReportAPIHandler.AdminFlagReportHandler)
+	fuego.DeleteStd(admin, "/reports/{id}/flag", adminReportAPIHandler.AdminUnflagReportHandler)
+	fuego.GetStd(admin, "/reports/{id}/escalation", incidentEscalationAPIHandler.AdminGetEscalationHandler)
+	fuego.GetStd(admin, "/reports/{id}/pdf", adminReportPDFAPIHandler.AdminReportPDFHandler)
+	fuego.GetStd(admin, "/reports/{id}/duplicates", adminReportDuplicateAPIHandler.AdminGetReportDuplicatesHandler)
+	fuego.PostStd(admin, "/reports/{id}/merge", adminReportDuplicateAPIHandler.AdminMergeReportsHandler)
 	fuego.DeleteStd(admin, "/reports/{id}", adminReportAPIHandler.AdminDeleteReportHandler)
 
+	// Admin Data Exports
+	fuego.GetStd(admin, "/exports", adminDataExportAPIHandler.AdminListExportDatasetsHandler)
+	fuego.GetStd(admin, "/exports/{dataset}", adminDataExportAPIHandler.AdminExportDatasetHandler)
+
 	// Admin Broadcasts
 	fuego.GetStd(admin, "/broadcasts", adminBroadcastAPIHandler.AdminListBroadcasts)
 	fuego.PostStd(admin, "/broadcasts", adminBroadcastAPIHandler.AdminCreateBroadcast)
+	fuego.PostStd(admin, "/broadcasts/preview", adminBroadcastAPIHandler.AdminPreviewBroadcastAudience)
 	fuego.GetStd(admin, "/broadcasts/{id}", adminBroadcastAPIHandler.AdminGetBroadcast)
 	fuego.DeleteStd(admin, "/broadcasts/{id}", adminBroadcastAPIHandler.AdminDeleteBroadcast)
+	fuego.GetStd(admin, "/broadcasts/{id}/recipients", adminBroadcastAPIHandler.AdminListBroadcastRecipients)
+	fuego.GetStd(admin, "/broadcasts/{id}/receipts", adminBroadcastAPIHandler.AdminGetBroadcastReceipts)
+	fuego.PostStd(admin, "/broadcasts/{id}/resend-unread", adminBroadcastAPIHandler.AdminResendUnreadBroadcast)
+	fuego.PutStd(admin, "/broadcasts/{id}/schedule", adminBroadcastAPIHandler.AdminScheduleBroadcast)
+	fuego.GetStd(admin, "/broadcast-groups", adminBroadcastGroupAPIHandler.AdminListBroadcastGroups)
+	fuego.PostStd(admin, "/broadcast-groups", adminBroadcastGroupAPIHandler.AdminCreateBroadcastGroup)
+	fuego.GetStd(admin, "/broadcast-groups/{id}", adminBroadcastGroupAPIHandler.AdminGetBroadcastGroup)
+	fuego.PutStd(admin, "/broadcast-groups/{id}", adminBroadcastGroupAPIHandler.AdminUpdateBroadcastGroup)
+	fuego.DeleteStd(admin, "/broadcast-groups/{id}", adminBroadcastGroupAPIHandler.AdminDeleteBroadcastGroup)
+	fuego.PostStd(admin, "/broadcast-groups/{id}/members", adminBroadcastGroupAPIHandler.AdminAddBroadcastGroupMembers)
+	fuego.DeleteStd(admin, "/broadcast-groups/{id}/members/{userId}", adminBroadcastGroupAPIHandler.AdminRemoveBroadcastGroupMember)
+	fuego.GetStd(admin, "/broadcast-templates", adminBroadcastTemplateAPIHandler.AdminListBroadcastTemplates)
+	fuego.PostStd(admin, "/broadcast-templates", adminBroadcastTemplateAPIHandler.AdminCreateBroadcastTemplate)
+	fuego.GetStd(admin, "/broadcast-templates/{id}", adminBroadcastTemplateAPIHandler.AdminGetBroadcastTemplate)
+	fuego.PutStd(admin, "/broadcast-templates/{id}", adminBroadcastTemplateAPIHandler.AdminUpdateBroadcastTemplate)
+	fuego.DeleteStd(admin, "/broadcast-templates/{id}", adminBroadcastTemplateAPIHandler.AdminDeleteBroadcastTemplate)
 
 	// Test user broadcasts under admin for debugging
 	fuego.GetStd(admin, "/test-broadcasts", broadcastAPIHandler.ListUserBroadcasts)
@@ -493,52 +648,6 @@ func main() {
 		}
 	})
 
-	// Debug endpoint to test push notifications
-	fuego.PostStd(admin, "/debug/test-push", func(w http.ResponseWriter, r *http.Request) {
-		userIDVal := r.Context().Value(api.UserIDKey)
-		userID, ok := userIDVal.(int64)
-		if !ok {
-			http.Error(w, "User ID not found in context", http.StatusUnauthorized)
-			return
-		}
-
-		// Create test push notification payload
-		testPayload := map[string]interface{}{
-			"type":  "test",
-			"title": "Test Push Notification",
-			"body":  "This is a test push notification from the admin panel",
-			"data": map[string]interface{}{
-				"test": true,
-			},
-		}
-
-		payloadBytes, err := json.Marshal(testPayload)
-		if err != nil {
-			logger.Error("Failed to marshal test push payload", "error", err)
-			http.Error(w, "Failed to create test payload: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Send test push notification
-		err = pushSenderService.Send(r.Context(), userID, payloadBytes, 300) // 5 minutes TTL
-		if err != nil {
-			logger.Error("Failed to send test push notification", "user_id", userID, "error", err)
-			http.Error(w, "Failed to send test: " + err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		response := map[string]interface{}{
-			"message": "Test push notification sent",
-			"user_id": userID,
-			"payload": testPayload,
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			logger.Error("Failed to encode test push response", "error", err)
-		}
-	})
-
 	// Simple test handler - mimicking working admin handlers
 	fuego.GetStd(admin, "/simple-test", func(w http.ResponseWriter, r *http.Request) {
 		logger.Info("Simple test handler called")
@@ -570,6 +679,52 @@ func main() {
 	fuego.DeleteStd(admin, "/emergency-contacts/{id}", emergencyContactAPIHandler.AdminDeleteEmergencyContactHandler)
 	fuego.PutStd(admin, "/emergency-contacts/{id}/default", emergencyContactAPIHandler.AdminSetDefaultEmergencyContactHandler)
 
+	// Admin Incident Categories
+	fuego.GetStd(admin, "/incident-categories", incidentCategoryAPIHandler.AdminListIncidentCategoriesHandler)
+	fuego.PostStd(admin, "/incident-categories", incidentCategoryAPIHandler.AdminCreateIncidentCategoryHandler)
+	fuego.GetStd(admin, "/incident-categories/{id}", incidentCategoryAPIHandler.AdminGetIncidentCategoryHandler)
+	fuego.PutStd(admin, "/incident-categories/{id}", incidentCategoryAPIHandler.AdminUpdateIncidentCategoryHandler)
+	fuego.DeleteStd(admin, "/incident-categories/{id}", incidentCategoryAPIHandler.AdminDeleteIncidentCategoryHandler)
+
+	// Admin Report Retention
+	fuego.GetStd(admin, "/retention-policies", adminRetentionAPIHandler.AdminListRetentionPoliciesHandler)
+	fuego.PostStd(admin, "/retention-policies", adminRetentionAPIHandler.AdminCreateRetentionPolicyHandler)
+	fuego.PutStd(admin, "/retention-policies/{id}", adminRetentionAPIHandler.AdminUpdateRetentionPolicyHandler)
+	fuego.DeleteStd(admin, "/retention-policies/{id}", adminRetentionAPIHandler.AdminDeleteRetentionPolicyHandler)
+	fuego.GetStd(admin, "/retention/preview", adminRetentionAPIHandler.AdminRetentionPreviewHandler)
+	fuego.PostStd(admin, "/retention/purge", adminRetentionAPIHandler.AdminPurgeReportsHandler)
+
+	// Admin Live Patrol Tracking
+	fuego.GetStd(admin, "/patrols/live", patrolTrackingAPIHandler.AdminListLivePositionsHandler)
+	fuego.GetStd(admin, "/bookings/{id}/track", patrolTrackingAPIHandler.AdminGetTrackHandler)
+
+	// Admin Watchlist
+	fuego.GetStd(admin, "/watchlist", adminWatchlistAPIHandler.ListWatchlistEntriesHandler)
+	fuego.PostStd(admin, "/watchlist", adminWatchlistAPIHandler.CreateWatchlistEntryHandler)
+	fuego.GetStd(admin, "/watchlist/{id}", adminWatchlistAPIHandler.GetWatchlistEntryHandler)
+	fuego.PutStd(admin, "/watchlist/{id}", adminWatchlistAPIHandler.UpdateWatchlistEntryHandler)
+	fuego.DeleteStd(admin, "/watchlist/{id}", adminWatchlistAPIHandler.DeleteWatchlistEntryHandler)
+	fuego.PostStd(admin, "/watchlist/{id}/photos", adminWatchlistAPIHandler.UploadWatchlistPhotoHandler)
+	fuego.GetStd(admin, "/watchlist/{id}/photos/{photoId}", adminWatchlistAPIHandler.GetWatchlistPhotoHandler)
+	fuego.DeleteStd(admin, "/watchlist/{id}/photos/{photoId}", adminWatchlistAPIHandler.DeleteWatchlistPhotoHandler)
+
+	// Admin Tip Line
+	fuego.GetStd(admin, "/tips", tipAPIHandler.AdminListTipsHandler)
+	fuego.GetStd(admin, "/tips/{id}", tipAPIHandler.AdminGetTipHandler)
+	fuego.PostStd(admin, "/tips/{id}/promote", tipAPIHandler.AdminPromoteTipHandler)
+	fuego.PostStd(admin, "/tips/{id}/discard", tipAPIHandler.AdminDiscardTipHandler)
+
+	// Admin Outbox Dead Letters
+	fuego.GetStd(admin, "/outbox/dead-letters", adminOutboxAPIHandler.ListDeadLettersHandler)
+	fuego.GetStd(admin, "/outbox/dead-letters/{id}", adminOutboxAPIHandler.GetDeadLetterHandler)
+	fuego.PostStd(admin, "/outbox/dead-letters/{id}/requeue", adminOutboxAPIHandler.RequeueDeadLetterHandler)
+	fuego.PostStd(admin, "/outbox/dead-letters/{id}/discard", adminOutboxAPIHandler.DiscardDeadLetterHandler)
+
+	// Admin SOS Alerts
+	fuego.GetStd(admin, "/sos", sosAPIHandler.AdminListSOSAlertsHandler)
+	fuego.GetStd(admin, "/sos/{id}", sosAPIHandler.AdminGetSOSAlertHandler)
+	fuego.PostStd(admin, "/sos/{id}/stand-down", sosAPIHandler.AdminStandDownSOSHandler)
+
 	// Admin Audit Trail
 	fuego.GetStd(admin, "/audit-events", adminAuditAPIHandler.AdminListAuditEvents)
 	fuego.GetStd(admin, "/audit-events/stats", adminAuditAPIHandler.AdminGetAuditStats)
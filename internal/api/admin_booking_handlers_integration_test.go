@@ -45,20 +45,22 @@ func (m *MockMessageSender) Send(recipient, messageType, payload string) error {
 }
 
 type adminTestApp struct {
-	Router          *chi.Mux
-	DB              *sql.DB
-	Logger          *slog.Logger
-	Config          *config.Config
-	Querier         db.Querier
-	UserService     *service.UserService
-	ScheduleService *service.ScheduleService
-	BookingService  *service.BookingService
-	ReportService   *service.ReportService
-	OutboxService   *outbox.DispatcherService
-	PushService     *service.PushSender
-	mockSMSSender   *MockMessageSender
-	Cron            *cron.Cron
-	OTPStore        auth.OTPStore
+	Router           *chi.Mux
+	DB               *sql.DB
+	Logger           *slog.Logger
+	Config           *config.Config
+	Querier          db.Querier
+	UserService      *service.UserService
+	ScheduleService  *service.ScheduleService
+	BookingService   *service.BookingService
+	ReportService    *service.ReportService
+	BroadcastService *service.BroadcastService
+	OutboxService    *outbox.DispatcherService
+	EventBroker      *service.EventBroker
+	PushService      *service.PushSender
+	mockSMSSender    *MockMessageSender
+	Cron             *cron.Cron
+	OTPStore         auth.OTPStore
 }
 
 // newAdminTestApp sets up the application for admin-related integration tests.
@@ -81,6 +83,8 @@ func newAdminTestApp(t *testing.T) *adminTestApp {
 		VAPIDPublic:          "test_public_key",
 		VAPIDPrivate:         "test_private_key",
 		VAPIDSubject:         "mailto:test@example.com",
+		TipProofOfWorkBits:   8,
+		WatchlistPhotoDir:    t.TempDir(),
 	}
 
 	loggerOpts := &slog.HandlerOptions{Level: slog.LevelDebug}
@@ -143,11 +147,15 @@ func newAdminTestApp(t *testing.T) *adminTestApp {
 	scheduleService := service.NewScheduleService(querier, logger, cfg)
 	pointsService := service.NewPointsService(querier, logger)
 	bookingService := service.NewBookingService(querier, cfg, logger, pointsService)
-	reportService := service.NewReportService(querier, logger, pointsService)
+	reportAbuseService := service.NewReportAbuseService(querier, logger)
+	reportService := service.NewReportService(querier, logger, pointsService, reportAbuseService)
 
 	auditService := service.NewAuditService(querier, logger)
 	pushService := service.NewPushSender(querier, cfg, logger)
-	outboxService := outbox.NewDispatcherService(querier, mockSender, pushService, logger, cfg)
+	notificationPreferencesService := service.NewNotificationPreferencesService(querier, cfg, logger)
+	bookingService.SetReminderScheduler(service.NewScheduler(querier, notificationPreferencesService, logger))
+	outboxService := outbox.NewDispatcherService(querier, mockSender, pushService, nil, notificationPreferencesService, logger, cfg)
+	adminPushAPIHandler := api.NewAdminPushHandler(pushService, logger)
 
 	cronScheduler := cron.New()
 	// todo: setup cron jobs if they interfere or are needed by test flows
@@ -161,14 +169,61 @@ func newAdminTestApp(t *testing.T) *adminTestApp {
 	adminScheduleAPIHandler := api.NewAdminScheduleHandlers(logger, scheduleService, auditService)
 	adminUserAPIHandler := api.NewAdminUserHandler(querier, auditService, logger)
 	adminBookingAPIHandler := api.NewAdminBookingHandler(bookingService, logger)
-	adminReportAPIHandler := api.NewAdminReportHandler(reportService, scheduleService, querier, auditService, logger)
-	adminBroadcastAPIHandler := api.NewAdminBroadcastHandler(querier, logger)
+	handoverService := service.NewHandoverService(querier, cfg, logger)
+	handoverAPIHandler := api.NewHandoverHandler(handoverService, logger)
+	adminReportAPIHandler := api.NewAdminReportHandler(reportService, scheduleService, handoverService, querier, auditService, logger)
+	reportGeoService := service.NewReportGeoService(querier, logger)
+	reportExportService := service.NewReportExportService(reportGeoService, auditService, logger)
+	adminReportGeoAPIHandler := api.NewAdminReportGeoHandler(reportGeoService, reportExportService, logger)
+	reportPDFService := service.NewReportPDFService(querier, reportGeoService, auditService, cfg.MapTileCacheDir, logger)
+	adminReportPDFAPIHandler := api.NewAdminReportPDFHandler(reportPDFService, logger)
+	dataExportService := service.NewDataExportService(querier, auditService, logger)
+	adminDataExportAPIHandler := api.NewAdminDataExportHandler(dataExportService, logger)
+	broadcastService := service.NewBroadcastService(querier, notificationPreferencesService, scheduleService, logger, cfg)
+	adminBroadcastAPIHandler := api.NewAdminBroadcastHandler(querier, broadcastService, logger)
+	broadcastAPIHandler := api.NewBroadcastHandler(querier, broadcastService, logger)
+	broadcastGroupService := service.NewBroadcastGroupService(querier, logger)
+	adminBroadcastGroupAPIHandler := api.NewAdminBroadcastGroupHandler(broadcastGroupService, logger)
+	adminBroadcastTemplateAPIHandler := api.NewAdminBroadcastTemplateHandler(service.NewBroadcastTemplateService(querier, logger), logger)
 	adminDashboardService := service.NewAdminDashboardService(querier, scheduleService, logger)
 	adminDashboardAPIHandler := api.NewAdminDashboardHandler(adminDashboardService, logger)
-	// pushAPIHandler := api.NewPushHandler(querier, cfg, logger) // If needed
+	reportAPIHandler := api.NewReportHandler(reportService, auditService, logger)
+	incidentCategoryService := service.NewIncidentCategoryService(querier, logger)
+	incidentCategoryAPIHandler := api.NewIncidentCategoryHandler(incidentCategoryService, logger)
+	emergencyContactService := service.NewEmergencyContactService(querier, logger)
+	incidentEscalationService := service.NewIncidentEscalationService(querier, emergencyContactService, cfg, logger)
+	reportService.SetEscalationService(incidentEscalationService)
+	incidentEscalationAPIHandler := api.NewIncidentEscalationHandler(incidentEscalationService, auditService, logger)
+	reportDuplicateService := service.NewReportDuplicateService(querier, pointsService, logger)
+	reportService.SetDuplicateService(reportDuplicateService)
+	adminReportDuplicateAPIHandler := api.NewAdminReportDuplicateHandler(reportDuplicateService, auditService, logger)
+	reportArchivingService := service.NewReportArchivingService(querier, auditService, logger)
+	adminRetentionAPIHandler := api.NewAdminRetentionHandler(reportArchivingService, auditService, logger)
+	patrolTrackingService := service.NewPatrolTrackingService(querier, cfg, logger)
+	patrolTrackingAPIHandler := api.NewPatrolTrackingHandler(patrolTrackingService, logger)
+	sosService := service.NewSOSService(querier, reportService, incidentEscalationService, outboxService, logger)
+	sosAPIHandler := api.NewSOSHandler(sosService, auditService, logger)
+	eventBroker := service.NewEventBroker(service.DefaultEventBufferSize, logger)
+	bookingService.SetEventBroker(eventBroker)
+	reportService.SetEventBroker(eventBroker)
+	sosService.SetEventBroker(eventBroker)
+	eventStreamAPIHandler := api.NewEventStreamHandler(eventBroker, logger)
+	tipService := service.NewTipService(querier, cfg, logger)
+	tipAPIHandler := api.NewTipHandler(tipService, auditService, logger)
+	watchlistService := service.NewWatchlistService(querier, cfg, logger)
+	watchlistService.SetEventBroker(eventBroker)
+	reportService.SetWatchlistService(watchlistService)
+	tipService.SetWatchlistService(watchlistService)
+	adminWatchlistAPIHandler := api.NewAdminWatchlistHandler(watchlistService, auditService, logger)
+	adminOutboxAPIHandler := api.NewAdminOutboxHandler(service.NewOutboxDeadLetterService(querier, logger), auditService, logger)
+	userEmailAPIHandler := api.NewUserEmailHandler(service.NewUserEmailService(querier, logger), logger)
+	notificationPreferencesAPIHandler := api.NewNotificationPreferencesHandler(notificationPreferencesService, logger)
+	pushAPIHandler := api.NewPushHandler(querier, cfg, logger)
 
 	// Public routes
 	router.Post("/auth/register", authAPIHandler.RegisterHandler)
+	router.Get("/api/tips/challenge", tipAPIHandler.GetTipChallengeHandler)
+	router.Post("/api/tips", tipAPIHandler.SubmitTipHandler)
 	router.Post("/auth/verify", authAPIHandler.VerifyHandler)
 	// ... other public routes if necessary for setup ...
 
@@ -195,10 +250,14 @@ func newAdminTestApp(t *testing.T) *adminTestApp {
 			ur.Put("/{id}", adminUserAPIHandler.AdminUpdateUser)
 			ur.Delete("/{id}", adminUserAPIHandler.AdminDeleteUser)
 			ur.Post("/bulk-delete", adminUserAPIHandler.AdminBulkDeleteUsers)
+			ur.Get("/{id}/trust-score", adminReportAPIHandler.AdminGetReporterTrustHandler)
+			ur.Get("/{id}/push-devices", adminPushAPIHandler.AdminListUserPushDevices)
+			ur.Post("/{id}/push-devices/{deviceId}/test", adminPushAPIHandler.AdminTestUserPushDevice)
 		})
 		// Admin Bookings
 		r.Route("/bookings", func(br chi.Router) {
 			br.Post("/assign", adminBookingAPIHandler.AssignUserToShiftHandler)
+			br.Get("/{id}/track", patrolTrackingAPIHandler.AdminGetTrackHandler)
 		})
 		// Admin Reports
 		r.Route("/reports", func(rr chi.Router) {
@@ -206,13 +265,101 @@ func newAdminTestApp(t *testing.T) *adminTestApp {
 			rr.Get("/{id}", adminReportAPIHandler.AdminGetReportHandler)
 			rr.Put("/{id}/archive", adminReportAPIHandler.AdminArchiveReportHandler)
 			rr.Put("/{id}/unarchive", adminReportAPIHandler.AdminUnarchiveReportHandler)
+			rr.Put("/{id}/flag", adminReportAPIHandler.AdminFlagReportHandler)
+			rr.Delete("/{id}/flag", adminReportAPIHandler.AdminUnflagReportHandler)
 			rr.Get("/archived", adminReportAPIHandler.AdminListArchivedReportsHandler)
+			rr.Get("/heatmap", adminReportGeoAPIHandler.AdminReportHeatmapHandler)
+			rr.Get("/export", adminReportGeoAPIHandler.AdminExportReportsHandler)
+			rr.Get("/pdf", adminReportPDFAPIHandler.AdminReportsPDFHandler)
+			rr.Get("/{id}/pdf", adminReportPDFAPIHandler.AdminReportPDFHandler)
+			rr.Get("/{id}/escalation", incidentEscalationAPIHandler.AdminGetEscalationHandler)
+			rr.Get("/{id}/duplicates", adminReportDuplicateAPIHandler.AdminGetReportDuplicatesHandler)
+			rr.Post("/{id}/merge", adminReportDuplicateAPIHandler.AdminMergeReportsHandler)
+		})
+		// Admin Data Exports
+		r.Route("/exports", func(er chi.Router) {
+			er.Get("/", adminDataExportAPIHandler.AdminListExportDatasetsHandler)
+			er.Get("/{dataset}", adminDataExportAPIHandler.AdminExportDatasetHandler)
 		})
 		// Admin Broadcasts
 		r.Route("/broadcasts", func(br chi.Router) {
 			br.Get("/", adminBroadcastAPIHandler.AdminListBroadcasts)
 			br.Post("/", adminBroadcastAPIHandler.AdminCreateBroadcast)
+			br.Post("/preview", adminBroadcastAPIHandler.AdminPreviewBroadcastAudience)
 			br.Get("/{id}", adminBroadcastAPIHandler.AdminGetBroadcast)
+			br.Get("/{id}/recipients", adminBroadcastAPIHandler.AdminListBroadcastRecipients)
+			br.Get("/{id}/receipts", adminBroadcastAPIHandler.AdminGetBroadcastReceipts)
+			br.Post("/{id}/resend-unread", adminBroadcastAPIHandler.AdminResendUnreadBroadcast)
+			br.Put("/{id}/schedule", adminBroadcastAPIHandler.AdminScheduleBroadcast)
+		})
+		r.Route("/broadcast-groups", func(gr chi.Router) {
+			gr.Get("/", adminBroadcastGroupAPIHandler.AdminListBroadcastGroups)
+			gr.Post("/", adminBroadcastGroupAPIHandler.AdminCreateBroadcastGroup)
+			gr.Get("/{id}", adminBroadcastGroupAPIHandler.AdminGetBroadcastGroup)
+			gr.Put("/{id}", adminBroadcastGroupAPIHandler.AdminUpdateBroadcastGroup)
+			gr.Delete("/{id}", adminBroadcastGroupAPIHandler.AdminDeleteBroadcastGroup)
+			gr.Post("/{id}/members", adminBroadcastGroupAPIHandler.AdminAddBroadcastGroupMembers)
+			gr.Delete("/{id}/members/{userId}", adminBroadcastGroupAPIHandler.AdminRemoveBroadcastGroupMember)
+		})
+		r.Route("/broadcast-templates", func(tr chi.Router) {
+			tr.Get("/", adminBroadcastTemplateAPIHandler.AdminListBroadcastTemplates)
+			tr.Post("/", adminBroadcastTemplateAPIHandler.AdminCreateBroadcastTemplate)
+			tr.Get("/{id}", adminBroadcastTemplateAPIHandler.AdminGetBroadcastTemplate)
+			tr.Put("/{id}", adminBroadcastTemplateAPIHandler.AdminUpdateBroadcastTemplate)
+			tr.Delete("/{id}", adminBroadcastTemplateAPIHandler.AdminDeleteBroadcastTemplate)
+		})
+		// Admin Incident Categories
+		r.Route("/incident-categories", func(cr chi.Router) {
+			cr.Get("/", incidentCategoryAPIHandler.AdminListIncidentCategoriesHandler)
+			cr.Post("/", incidentCategoryAPIHandler.AdminCreateIncidentCategoryHandler)
+			cr.Get("/{id}", incidentCategoryAPIHandler.AdminGetIncidentCategoryHandler)
+			cr.Put("/{id}", incidentCategoryAPIHandler.AdminUpdateIncidentCategoryHandler)
+			cr.Delete("/{id}", incidentCategoryAPIHandler.AdminDeleteIncidentCategoryHandler)
+		})
+		// Admin Report Retention
+		r.Route("/retention-policies", func(pr chi.Router) {
+			pr.Get("/", adminRetentionAPIHandler.AdminListRetentionPoliciesHandler)
+			pr.Post("/", adminRetentionAPIHandler.AdminCreateRetentionPolicyHandler)
+			pr.Put("/{id}", adminRetentionAPIHandler.AdminUpdateRetentionPolicyHandler)
+			pr.Delete("/{id}", adminRetentionAPIHandler.AdminDeleteRetentionPolicyHandler)
+		})
+		r.Get("/retention/preview", adminRetentionAPIHandler.AdminRetentionPreviewHandler)
+		r.Post("/retention/purge", adminRetentionAPIHandler.AdminPurgeReportsHandler)
+		// Admin Live Patrol Tracking
+		r.Get("/patrols/live", patrolTrackingAPIHandler.AdminListLivePositionsHandler)
+		// Admin Watchlist
+		r.Route("/watchlist", func(wr chi.Router) {
+			wr.Get("/", adminWatchlistAPIHandler.ListWatchlistEntriesHandler)
+			wr.Post("/", adminWatchlistAPIHandler.CreateWatchlistEntryHandler)
+			wr.Get("/{id}", adminWatchlistAPIHandler.GetWatchlistEntryHandler)
+			wr.Put("/{id}", adminWatchlistAPIHandler.UpdateWatchlistEntryHandler)
+			wr.Delete("/{id}", adminWatchlistAPIHandler.DeleteWatchlistEntryHandler)
+			wr.Post("/{id}/photos", adminWatchlistAPIHandler.UploadWatchlistPhotoHandler)
+			wr.Get("/{id}/photos/{photoId}", adminWatchlistAPIHandler.GetWatchlistPhotoHandler)
+			wr.Delete("/{id}/photos/{photoId}", adminWatchlistAPIHandler.DeleteWatchlistPhotoHandler)
+		})
+
+		// Admin Tip Line
+		r.Route("/tips", func(tr chi.Router) {
+			tr.Get("/", tipAPIHandler.AdminListTipsHandler)
+			tr.Get("/{id}", tipAPIHandler.AdminGetTipHandler)
+			tr.Post("/{id}/promote", tipAPIHandler.AdminPromoteTipHandler)
+			tr.Post("/{id}/discard", tipAPIHandler.AdminDiscardTipHandler)
+		})
+
+		// Admin Outbox Dead Letters
+		r.Route("/outbox/dead-letters", func(or chi.Router) {
+			or.Get("/", adminOutboxAPIHandler.ListDeadLettersHandler)
+			or.Get("/{id}", adminOutboxAPIHandler.GetDeadLetterHandler)
+			or.Post("/{id}/requeue", adminOutboxAPIHandler.RequeueDeadLetterHandler)
+			or.Post("/{id}/discard", adminOutboxAPIHandler.DiscardDeadLetterHandler)
+		})
+
+		// Admin SOS Alerts
+		r.Route("/sos", func(sr chi.Router) {
+			sr.Get("/", sosAPIHandler.AdminListSOSAlertsHandler)
+			sr.Get("/{id}", sosAPIHandler.AdminGetSOSAlertHandler)
+			sr.Post("/{id}/stand-down", sosAPIHandler.AdminStandDownSOSHandler)
 		})
 		// Admin Dashboard
 		r.Get("/dashboard", adminDashboardAPIHandler.GetDashboardHandler)
@@ -222,24 +369,48 @@ func newAdminTestApp(t *testing.T) *adminTestApp {
 	router.Group(func(r chi.Router) {
 		r.Use(api.AuthMiddleware(cfg, logger, createTestSessionStore()))
 		r.Post("/bookings", bookingAPIHandler.CreateBookingHandler)
+		r.Post("/api/reports/off-shift", reportAPIHandler.CreateOffShiftReportHandler)
+		r.Post("/api/reports/{id}/acknowledge", incidentEscalationAPIHandler.AcknowledgeEscalationHandler)
+		r.Post("/api/bookings/{id}/checkout", bookingAPIHandler.MarkCheckOutHandler)
+		r.Post("/api/sos", sosAPIHandler.RaiseSOSHandler)
+		r.Post("/api/sos/{id}/acknowledge", sosAPIHandler.AcknowledgeSOSHandler)
+		r.Get("/api/events", eventStreamAPIHandler.StreamEventsHandler)
+		r.Post("/api/bookings/{id}/location-sharing", patrolTrackingAPIHandler.StartSharingHandler)
+		r.Delete("/api/bookings/{id}/location-sharing", patrolTrackingAPIHandler.StopSharingHandler)
+		r.Post("/api/bookings/{id}/locations", patrolTrackingAPIHandler.RecordLocationsHandler)
+		r.Put("/api/bookings/{id}/handover", handoverAPIHandler.SaveHandoverNoteHandler)
+		r.Get("/api/bookings/{id}/handover", handoverAPIHandler.GetHandoverNoteHandler)
+		r.Get("/api/bookings/{id}/handover/received", handoverAPIHandler.ListReceivedHandoverNotesHandler)
+		r.Get("/api/incident-categories", incidentCategoryAPIHandler.ListIncidentCategoriesHandler)
+		r.Get("/api/user/email", userEmailAPIHandler.GetEmailHandler)
+		r.Put("/api/user/email", userEmailAPIHandler.SetEmailHandler)
+		r.Post("/api/user/email/verify", userEmailAPIHandler.VerifyEmailHandler)
+		r.Delete("/api/user/email", userEmailAPIHandler.DeleteEmailHandler)
+		r.Get("/api/user/notification-preferences", notificationPreferencesAPIHandler.GetPreferencesHandler)
+		r.Put("/api/user/notification-preferences", notificationPreferencesAPIHandler.UpdatePreferencesHandler)
+		r.Post("/api/push/subscribe", pushAPIHandler.SubscribePush)
+		r.Get("/api/broadcasts", broadcastAPIHandler.ListUserBroadcasts)
+		r.Post("/api/broadcasts/{id}/read", broadcastAPIHandler.AcknowledgeBroadcast)
 		// ... other protected routes
 	})
 
 	return &adminTestApp{
-		Router:          router,
-		DB:              dbConn,
-		Logger:          logger,
-		Config:          cfg,
-		Querier:         querier,
-		UserService:     userService,
-		ScheduleService: scheduleService,
-		BookingService:  bookingService,
-		ReportService:   reportService,
-		PushService:     pushService,
-		OutboxService:   outboxService,
-		mockSMSSender:   mockSender,
-		Cron:            cronScheduler,
-		OTPStore:        otpStore,
+		Router:           router,
+		DB:               dbConn,
+		Logger:           logger,
+		Config:           cfg,
+		Querier:          querier,
+		UserService:      userService,
+		ScheduleService:  scheduleService,
+		BookingService:   bookingService,
+		ReportService:    reportService,
+		BroadcastService: broadcastService,
+		PushService:      pushService,
+		OutboxService:    outboxService,
+		EventBroker:      eventBroker,
+		mockSMSSender:    mockSender,
+		Cron:             cronScheduler,
+		OTPStore:         otpStore,
 	}
 }
 
@@ -0,0 +1,189 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminBroadcastAudiences(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	admin, adminToken := app.createTestUserAndLogin(t, "+15550007001", "Test Admin", "admin")
+	booked, _ := app.createTestUserAndLogin(t, "+15550007002", "Booked Owl", "owl")
+	active, _ := app.createTestUserAndLogin(t, "+15550007003", "Active Owl", "owl")
+	newcomer, _ := app.createTestUserAndLogin(t, "+15550007004", "New Guest", "guest")
+
+	schedule, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+		Name:            "Audience Patrol",
+		CronExpr:        "0 12 * * *",
+		DurationMinutes: 120,
+		Timezone:        sql.NullString{String: "UTC", Valid: true},
+	})
+	require.NoError(t, err)
+	shiftStart := time.Now().UTC().AddDate(0, 0, 3).Truncate(24 * time.Hour).Add(12 * time.Hour)
+	_, err = app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+		UserID:     booked.UserID,
+		ScheduleID: schedule.ScheduleID,
+		ShiftStart: shiftStart,
+		ShiftEnd:   shiftStart.Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = app.DB.Exec(`UPDATE users SET last_activity_date = ? WHERE user_id = ?`, time.Now().UTC().AddDate(0, 0, -2).Format("2006-01-02"), active.UserID)
+	require.NoError(t, err)
+	_, err = app.DB.Exec(`UPDATE users SET last_activity_date = ? WHERE user_id = ?`, time.Now().UTC().AddDate(0, 0, -60).Format("2006-01-02"), booked.UserID)
+	require.NoError(t, err)
+
+	preview := func(t *testing.T, body string) (int, service.AudiencePreview) {
+		t.Helper()
+		rr := app.makeRequest(t, "POST", "/api/admin/broadcasts/preview", bytes.NewBufferString(body), adminToken)
+		var result service.AudiencePreview
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		}
+		return rr.Code, result
+	}
+	userIDs := func(recipients []service.BroadcastRecipient) []int64 {
+		ids := make([]int64, 0, len(recipients))
+		for _, recipient := range recipients {
+			ids = append(ids, recipient.UserID)
+		}
+		return ids
+	}
+
+	t.Run("presets", func(t *testing.T) {
+		code, result := preview(t, `{"audience": "all"}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 4, result.RecipientCount)
+
+		code, result = preview(t, `{"audience": "owls"}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.ElementsMatch(t, []int64{booked.UserID, active.UserID}, userIDs(result.Sample))
+
+		code, result = preview(t, `{"audience": "active"}`)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []int64{active.UserID}, userIDs(result.Sample))
+	})
+
+	t.Run("custom filters", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			filter string
+			want   []int64
+		}{
+			{"role", `{"role": "guest"}`, []int64{newcomer.UserID}},
+			{"active within days", `{"active_within_days": 90}`, []int64{booked.UserID, active.UserID}},
+			{"schedule", fmt.Sprintf(`{"schedule_id": %d}`, schedule.ScheduleID), []int64{booked.UserID}},
+			{"shift date", fmt.Sprintf(`{"shift_date": %q}`, shiftStart.Format("2006-01-02")), []int64{booked.UserID}},
+			{"other shift date", fmt.Sprintf(`{"shift_date": %q}`, shiftStart.AddDate(0, 0, 1).Format("2006-01-02")), []int64{}},
+			{"never booked", `{"onboarding": "never_booked"}`, []int64{admin.UserID, active.UserID, newcomer.UserID}},
+			{"criteria combine", `{"role": "owl", "onboarding": "never_booked"}`, []int64{active.UserID}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				code, result := preview(t, fmt.Sprintf(`{"audience": "custom", "audience_filter": %s}`, tt.filter))
+				require.Equal(t, http.StatusOK, code)
+				assert.Equal(t, len(tt.want), result.RecipientCount)
+				assert.ElementsMatch(t, tt.want, userIDs(result.Sample))
+			})
+		}
+	})
+
+	t.Run("invalid audiences are refused", func(t *testing.T) {
+		for _, body := range []string{
+			`{"audience": "everyone"}`,
+			`{"audience": "custom"}`,
+			`{"audience": "custom", "audience_filter": {"role": "wizard"}}`,
+			`{"audience": "custom", "audience_filter": {"shift_date": "next tuesday"}}`,
+			`{"audience": "custom", "audience_filter": {"onboarding": "halfway"}}`,
+			`{"audience": "custom", "audience_filter": {"active_within_days": -1}}`,
+			`{"audience": "custom", "audience_filter": {"schedule_id": 9999}}`,
+			`{"audience": "custom", "audience_filter": {"group_id": 9999}}`,
+		} {
+			code, _ := preview(t, body)
+			assert.Equal(t, http.StatusBadRequest, code, "body %s", body)
+		}
+	})
+
+	t.Run("groups", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", "/api/admin/broadcast-groups", bytes.NewBufferString(`{"name": "Street captains"}`), adminToken)
+		require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+		var group service.BroadcastGroup
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &group))
+
+		rr = app.makeRequest(t, "POST", "/api/admin/broadcast-groups", bytes.NewBufferString(`{"name": "Street captains"}`), adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		groupPath := fmt.Sprintf("/api/admin/broadcast-groups/%d", group.GroupID)
+		rr = app.makeRequest(t, "POST", groupPath+"/members", bytes.NewBufferString(fmt.Sprintf(`{"user_ids": [%d, %d]}`, booked.UserID, newcomer.UserID)), adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &group))
+		assert.Len(t, group.Members, 2)
+
+		rr = app.makeRequest(t, "POST", groupPath+"/members", bytes.NewBufferString(`{"user_ids": [9999]}`), adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		code, result := preview(t, fmt.Sprintf(`{"audience": "custom", "audience_filter": {"group_id": %d}}`, group.GroupID))
+		require.Equal(t, http.StatusOK, code)
+		assert.ElementsMatch(t, []int64{booked.UserID, newcomer.UserID}, userIDs(result.Sample))
+
+		rr = app.makeRequest(t, "DELETE", fmt.Sprintf("%s/members/%d", groupPath, newcomer.UserID), nil, adminToken)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+
+		code, result = preview(t, fmt.Sprintf(`{"audience": "custom", "audience_filter": {"group_id": %d}}`, group.GroupID))
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []int64{booked.UserID}, userIDs(result.Sample))
+
+		rr = app.makeRequest(t, "DELETE", groupPath, nil, adminToken)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		rr = app.makeRequest(t, "GET", groupPath, nil, adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("recipients are stored when the broadcast is created", func(t *testing.T) {
+		body := fmt.Sprintf(`{"title": "Shift briefing", "message": "See you at the gate", "audience": "custom",
+			"audience_filter": {"schedule_id": %d}, "push_enabled": true, "scheduled_at": %q}`,
+			schedule.ScheduleID, time.Now().UTC().Add(time.Hour).Format(time.RFC3339))
+		rr := app.makeRequest(t, "POST", "/api/admin/broadcasts", bytes.NewBufferString(body), adminToken)
+		require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+		var broadcast api.BroadcastResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &broadcast))
+		assert.Equal(t, "pending", broadcast.Status)
+		assert.Equal(t, int64(1), broadcast.RecipientCount)
+		require.NotNil(t, broadcast.AudienceFilter)
+		assert.Equal(t, schedule.ScheduleID, broadcast.AudienceFilter.ScheduleID)
+
+		// A booking made after the broadcast was created does not add a recipient
+		_, err := app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+			UserID:     active.UserID,
+			ScheduleID: schedule.ScheduleID,
+			ShiftStart: shiftStart.AddDate(0, 0, 1),
+			ShiftEnd:   shiftStart.AddDate(0, 0, 1).Add(2 * time.Hour),
+		})
+		require.NoError(t, err)
+
+		rr = app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/broadcasts/%d/recipients", broadcast.BroadcastID), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var recipients []service.BroadcastRecipient
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &recipients))
+		assert.Equal(t, []int64{booked.UserID}, userIDs(recipients))
+
+		rr = app.makeRequest(t, "GET", "/api/admin/broadcasts/9999/recipients", nil, adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,258 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"night-owls-go/internal/service"
+)
+
+// AdminBroadcastGroupHandler handles the named groups broadcasts can be sent to.
+type AdminBroadcastGroupHandler struct {
+	groupService *service.BroadcastGroupService
+	logger       *slog.Logger
+}
+
+// NewAdminBroadcastGroupHandler creates a new AdminBroadcastGroupHandler.
+func NewAdminBroadcastGroupHandler(groupService *service.BroadcastGroupService, logger *slog.Logger) *AdminBroadcastGroupHandler {
+	return &AdminBroadcastGroupHandler{
+		groupService: groupService,
+		logger:       logger.With("handler", "AdminBroadcastGroupHandler"),
+	}
+}
+
+// BroadcastGroupRequest is the body for creating or updating a broadcast group.
+type BroadcastGroupRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// AddBroadcastGroupMembersRequest lists the users to add to a broadcast group.
+type AddBroadcastGroupMembersRequest struct {
+	UserIDs []int64 `json:"user_ids"`
+}
+
+func (h *AdminBroadcastGroupHandler) respondWithGroupError(w http.ResponseWriter, err error, groupID int64) {
+	switch {
+	case errors.Is(err, service.ErrBroadcastGroupNotFound):
+		RespondWithError(w, http.StatusNotFound, "Broadcast group not found", h.logger, "group_id", groupID)
+	case errors.Is(err, service.ErrInvalidBroadcastGroup):
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+	case errors.Is(err, service.ErrBroadcastGroupNameTaken):
+		RespondWithError(w, http.StatusConflict, err.Error(), h.logger)
+	case errors.Is(err, service.ErrUserNotFound):
+		RespondWithError(w, http.StatusNotFound, "User not found", h.logger, "group_id", groupID)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to process broadcast group", h.logger, "error", err)
+	}
+}
+
+func (h *AdminBroadcastGroupHandler) groupID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid broadcast group ID", h.logger, "id", idStr)
+		return 0, false
+	}
+	return id, true
+}
+
+// AdminListBroadcastGroups handles GET /api/admin/broadcast-groups
+// @Summary List broadcast groups
+// @Tags admin-broadcasts
+// @Produce json
+// @Success 200 {array} db.ListBroadcastGroupsRow "Groups with member counts"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-groups [get]
+func (h *AdminBroadcastGroupHandler) AdminListBroadcastGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := h.groupService.ListGroups(r.Context())
+	if err != nil {
+		h.respondWithGroupError(w, err, 0)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, groups, h.logger)
+}
+
+// AdminGetBroadcastGroup handles GET /api/admin/broadcast-groups/{id}
+// @Summary Get a broadcast group with its members
+// @Tags admin-broadcasts
+// @Produce json
+// @Param id path int true "Group ID"
+// @Success 200 {object} service.BroadcastGroup "Group"
+// @Failure 400 {object} ErrorResponse "Invalid group ID"
+// @Failure 404 {object} ErrorResponse "Group not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-groups/{id} [get]
+func (h *AdminBroadcastGroupHandler) AdminGetBroadcastGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.groupID(w, r)
+	if !ok {
+		return
+	}
+	group, err := h.groupService.GetGroup(r.Context(), id)
+	if err != nil {
+		h.respondWithGroupError(w, err, id)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, group, h.logger)
+}
+
+// AdminCreateBroadcastGroup handles POST /api/admin/broadcast-groups
+// @Summary Create a broadcast group
+// @Tags admin-broadcasts
+// @Accept json
+// @Produce json
+// @Param request body BroadcastGroupRequest true "Group"
+// @Success 201 {object} service.BroadcastGroup "Group created"
+// @Failure 400 {object} ErrorResponse "Invalid name"
+// @Failure 409 {object} ErrorResponse "Name already taken"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-groups [post]
+func (h *AdminBroadcastGroupHandler) AdminCreateBroadcastGroup(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	var req BroadcastGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload", h.logger, "error", err)
+		return
+	}
+
+	group, err := h.groupService.CreateGroup(r.Context(), req.Name, req.Description, userID)
+	if err != nil {
+		h.respondWithGroupError(w, err, 0)
+		return
+	}
+	RespondWithJSON(w, http.StatusCreated, group, h.logger)
+}
+
+// AdminUpdateBroadcastGroup handles PUT /api/admin/broadcast-groups/{id}
+// @Summary Rename a broadcast group or change its description
+// @Tags admin-broadcasts
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param request body BroadcastGroupRequest true "Group"
+// @Success 200 {object} service.BroadcastGroup "Group updated"
+// @Failure 400 {object} ErrorResponse "Invalid name"
+// @Failure 404 {object} ErrorResponse "Group not found"
+// @Failure 409 {object} ErrorResponse "Name already taken"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-groups/{id} [put]
+func (h *AdminBroadcastGroupHandler) AdminUpdateBroadcastGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.groupID(w, r)
+	if !ok {
+		return
+	}
+
+	var req BroadcastGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload", h.logger, "error", err)
+		return
+	}
+
+	group, err := h.groupService.UpdateGroup(r.Context(), id, req.Name, req.Description)
+	if err != nil {
+		h.respondWithGroupError(w, err, id)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, group, h.logger)
+}
+
+// AdminDeleteBroadcastGroup handles DELETE /api/admin/broadcast-groups/{id}
+// @Summary Delete a broadcast group
+// @Tags admin-broadcasts
+// @Param id path int true "Group ID"
+// @Success 204 "Group deleted"
+// @Failure 400 {object} ErrorResponse "Invalid group ID"
+// @Failure 404 {object} ErrorResponse "Group not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-groups/{id} [delete]
+func (h *AdminBroadcastGroupHandler) AdminDeleteBroadcastGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.groupID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.groupService.DeleteGroup(r.Context(), id); err != nil {
+		h.respondWithGroupError(w, err, id)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminAddBroadcastGroupMembers handles POST /api/admin/broadcast-groups/{id}/members
+// @Summary Add users to a broadcast group
+// @Tags admin-broadcasts
+// @Accept json
+// @Produce json
+// @Param id path int true "Group ID"
+// @Param request body AddBroadcastGroupMembersRequest true "Users to add"
+// @Success 200 {object} service.BroadcastGroup "Group with its members"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Group or user not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-groups/{id}/members [post]
+func (h *AdminBroadcastGroupHandler) AdminAddBroadcastGroupMembers(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.groupID(w, r)
+	if !ok {
+		return
+	}
+
+	var req AddBroadcastGroupMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload", h.logger, "error", err)
+		return
+	}
+	if len(req.UserIDs) == 0 {
+		RespondWithError(w, http.StatusBadRequest, "user_ids is required", h.logger)
+		return
+	}
+
+	group, err := h.groupService.AddMembers(r.Context(), id, req.UserIDs)
+	if err != nil {
+		h.respondWithGroupError(w, err, id)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, group, h.logger)
+}
+
+// AdminRemoveBroadcastGroupMember handles DELETE /api/admin/broadcast-groups/{id}/members/{userId}
+// @Summary Remove a user from a broadcast group
+// @Tags admin-broadcasts
+// @Param id path int true "Group ID"
+// @Param userId path int true "User ID"
+// @Success 204 "Member removed"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 404 {object} ErrorResponse "User is not in the group"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-groups/{id}/members/{userId} [delete]
+func (h *AdminBroadcastGroupHandler) AdminRemoveBroadcastGroupMember(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.groupID(w, r)
+	if !ok {
+		return
+	}
+	userIDStr := r.PathValue("userId")
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid user ID", h.logger, "user_id", userIDStr)
+		return
+	}
+
+	if err := h.groupService.RemoveMember(r.Context(), id, userID); err != nil {
+		h.respondWithGroupError(w, err, id)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
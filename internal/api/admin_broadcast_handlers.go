@@ -1,9 +1,9 @@
 package api
 
 import (
-	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
@@ -11,47 +11,63 @@ import (
 	"time"
 
 	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
 )
 
 // AdminBroadcastHandler handles admin broadcast operations.
 type AdminBroadcastHandler struct {
-	querier db.Querier
-	logger  *slog.Logger
+	querier          db.Querier
+	broadcastService *service.BroadcastService
+	logger           *slog.Logger
 }
 
 // NewAdminBroadcastHandler creates a new AdminBroadcastHandler.
-func NewAdminBroadcastHandler(querier db.Querier, logger *slog.Logger) *AdminBroadcastHandler {
+func NewAdminBroadcastHandler(querier db.Querier, broadcastService *service.BroadcastService, logger *slog.Logger) *AdminBroadcastHandler {
 	return &AdminBroadcastHandler{
-		querier: querier,
-		logger:  logger.With("handler", "AdminBroadcastHandler"),
+		querier:          querier,
+		broadcastService: broadcastService,
+		logger:           logger.With("handler", "AdminBroadcastHandler"),
 	}
 }
 
 // CreateBroadcastRequest defines the expected JSON body for creating a broadcast.
+// Audience is a preset (all, admins, owls, active) or custom, in which case
+// AudienceFilter selects the recipients. The message may use the variables
+// {{.name}}, {{.first_name}}, {{.next_shift}} and {{.unfilled_slots}}; a title
+// or message left out is taken from the template. Recurrence is a cron
+// expression such as "0 18 * * 0" to send the broadcast on every run.
 type CreateBroadcastRequest struct {
-	Title       string     `json:"title"`
-	Message     string     `json:"message"`
-	Audience    string     `json:"audience"`
-	PushEnabled bool       `json:"push_enabled"`
-	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	Title          string                  `json:"title"`
+	Message        string                  `json:"message"`
+	TemplateID     *int64                  `json:"template_id,omitempty"`
+	Audience       string                  `json:"audience"`
+	AudienceFilter *service.AudienceFilter `json:"audience_filter,omitempty"`
+	PushEnabled    bool                    `json:"push_enabled"`
+	Urgent         bool                    `json:"urgent"` // Reaches users who opted out of broadcasts, even during quiet hours
+	ScheduledAt    *time.Time              `json:"scheduled_at,omitempty"`
+	Recurrence     string                  `json:"recurrence,omitempty"`
 }
 
 // BroadcastResponse represents a broadcast in API responses.
 type BroadcastResponse struct {
-	BroadcastID    int64      `json:"broadcast_id"`
-	Title          string     `json:"title"`
-	Message        string     `json:"message"`
-	Audience       string     `json:"audience"`
-	SenderUserID   int64      `json:"sender_user_id"`
-	SenderName     string     `json:"sender_name,omitempty"`
-	PushEnabled    bool       `json:"push_enabled"`
-	ScheduledAt    *time.Time `json:"scheduled_at"`
-	SentAt         *time.Time `json:"sent_at"`
-	Status         string     `json:"status"`
-	RecipientCount int64      `json:"recipient_count"`
-	SentCount      int64      `json:"sent_count"`
-	FailedCount    int64      `json:"failed_count"`
-	CreatedAt      time.Time  `json:"created_at"`
+	BroadcastID    int64                   `json:"broadcast_id"`
+	Title          string                  `json:"title"`
+	Message        string                  `json:"message"`
+	Audience       string                  `json:"audience"`
+	AudienceFilter *service.AudienceFilter `json:"audience_filter,omitempty"`
+	SenderUserID   int64                   `json:"sender_user_id"`
+	SenderName     string                  `json:"sender_name,omitempty"`
+	PushEnabled    bool                    `json:"push_enabled"`
+	Urgent         bool                    `json:"urgent"`
+	ScheduledAt    *time.Time              `json:"scheduled_at"` // Next run of a recurring broadcast
+	Recurrence     string                  `json:"recurrence,omitempty"`
+	ParentID       *int64                  `json:"parent_broadcast_id,omitempty"` // The recurring broadcast this was a run of
+	SentAt         *time.Time              `json:"sent_at"`
+	Status         string                  `json:"status"`
+	RecipientCount int64                   `json:"recipient_count"`
+	SentCount      int64                   `json:"sent_count"`
+	FailedCount    int64                   `json:"failed_count"`
+	CreatedAt      time.Time               `json:"created_at"`
 }
 
 // AdminCreateBroadcast handles POST /api/admin/broadcasts
@@ -69,12 +85,17 @@ func (h *AdminBroadcastHandler) AdminCreateBroadcast(w http.ResponseWriter, r *h
 		return
 	}
 
-	if req.Message == "" {
+	var templateID int64
+	if req.TemplateID != nil {
+		templateID = *req.TemplateID
+	}
+
+	if req.Message == "" && templateID == 0 {
 		RespondWithError(w, http.StatusBadRequest, "Message is required", h.logger)
 		return
 	}
 
-	if req.Title == "" {
+	if req.Title == "" && templateID == 0 {
 		RespondWithError(w, http.StatusBadRequest, "Title is required", h.logger)
 		return
 	}
@@ -84,43 +105,29 @@ func (h *AdminBroadcastHandler) AdminCreateBroadcast(w http.ResponseWriter, r *h
 		return
 	}
 
-	// Validate audience values
-	validAudiences := map[string]bool{
-		"all":    true,
-		"admins": true,
-		"owls":   true,
-		"active": true,
-	}
-	if !validAudiences[req.Audience] {
-		RespondWithError(w, http.StatusBadRequest, "Invalid audience", h.logger)
-		return
-	}
-
-	// Calculate recipient count based on audience
-	recipientCount, err := h.calculateRecipientCount(r.Context(), req.Audience)
-	if err != nil {
-		RespondWithError(w, http.StatusInternalServerError, "Failed to calculate recipient count", h.logger, "error", err)
-		return
-	}
-
 	var scheduledAt sql.NullTime
 	if req.ScheduledAt != nil {
 		scheduledAt = sql.NullTime{Time: *req.ScheduledAt, Valid: true}
 	}
-
-	params := db.CreateBroadcastParams{
-		Title:          req.Title,
-		Message:        req.Message,
-		Audience:       req.Audience,
-		SenderUserID:   userID,
-		PushEnabled:    req.PushEnabled,
-		ScheduledAt:    scheduledAt,
-		RecipientCount: sql.NullInt64{Int64: recipientCount, Valid: true},
+	var filter service.AudienceFilter
+	if req.AudienceFilter != nil {
+		filter = *req.AudienceFilter
 	}
 
-	broadcast, err := h.querier.CreateBroadcast(r.Context(), params)
+	broadcast, err := h.broadcastService.CreateBroadcast(r.Context(), service.CreateBroadcastParams{
+		Title:        req.Title,
+		Message:      req.Message,
+		TemplateID:   templateID,
+		Audience:     req.Audience,
+		Filter:       filter,
+		SenderUserID: userID,
+		PushEnabled:  req.PushEnabled,
+		Urgent:       req.Urgent,
+		ScheduledAt:  scheduledAt,
+		Recurrence:   req.Recurrence,
+	})
 	if err != nil {
-		RespondWithError(w, http.StatusInternalServerError, "Failed to create broadcast", h.logger, "error", err)
+		h.respondWithBroadcastError(w, err, "Failed to create broadcast")
 		return
 	}
 
@@ -129,9 +136,13 @@ func (h *AdminBroadcastHandler) AdminCreateBroadcast(w http.ResponseWriter, r *h
 		Title:          broadcast.Title,
 		Message:        broadcast.Message,
 		Audience:       broadcast.Audience,
+		AudienceFilter: audienceFilterResponse(broadcast.AudienceFilter),
 		SenderUserID:   broadcast.SenderUserID,
 		PushEnabled:    broadcast.PushEnabled,
+		Urgent:         broadcast.Urgent,
 		ScheduledAt:    nullTimeToPointer(broadcast.ScheduledAt),
+		Recurrence:     broadcast.RecurrenceCron.String,
+		ParentID:       nullInt64ToPointer(broadcast.ParentBroadcastID),
 		SentAt:         nullTimeToPointer(broadcast.SentAt),
 		Status:         broadcast.Status,
 		RecipientCount: nullInt64ToInt64(broadcast.RecipientCount),
@@ -158,10 +169,14 @@ func (h *AdminBroadcastHandler) AdminListBroadcasts(w http.ResponseWriter, r *ht
 			Title:          broadcast.Title,
 			Message:        broadcast.Message,
 			Audience:       broadcast.Audience,
+			AudienceFilter: audienceFilterResponse(broadcast.AudienceFilter),
 			SenderUserID:   broadcast.SenderUserID,
 			SenderName:     broadcast.SenderName,
 			PushEnabled:    broadcast.PushEnabled,
+			Urgent:         broadcast.Urgent,
 			ScheduledAt:    nullTimeToPointer(broadcast.ScheduledAt),
+			Recurrence:     broadcast.RecurrenceCron.String,
+			ParentID:       nullInt64ToPointer(broadcast.ParentBroadcastID),
 			SentAt:         nullTimeToPointer(broadcast.SentAt),
 			Status:         broadcast.Status,
 			RecipientCount: nullInt64ToInt64(broadcast.RecipientCount),
@@ -211,9 +226,13 @@ func (h *AdminBroadcastHandler) AdminGetBroadcast(w http.ResponseWriter, r *http
 		Title:          broadcast.Title,
 		Message:        broadcast.Message,
 		Audience:       broadcast.Audience,
+		AudienceFilter: audienceFilterResponse(broadcast.AudienceFilter),
 		SenderUserID:   broadcast.SenderUserID,
 		PushEnabled:    broadcast.PushEnabled,
+		Urgent:         broadcast.Urgent,
 		ScheduledAt:    nullTimeToPointer(broadcast.ScheduledAt),
+		Recurrence:     broadcast.RecurrenceCron.String,
+		ParentID:       nullInt64ToPointer(broadcast.ParentBroadcastID),
 		SentAt:         nullTimeToPointer(broadcast.SentAt),
 		Status:         broadcast.Status,
 		RecipientCount: nullInt64ToInt64(broadcast.RecipientCount),
@@ -225,38 +244,247 @@ func (h *AdminBroadcastHandler) AdminGetBroadcast(w http.ResponseWriter, r *http
 	RespondWithJSON(w, http.StatusOK, response, h.logger)
 }
 
-// calculateRecipientCount calculates how many users would receive the broadcast based on audience
-func (h *AdminBroadcastHandler) calculateRecipientCount(ctx context.Context, audience string) (int64, error) {
-	users, err := h.querier.ListUsers(ctx, nil)
+func (h *AdminBroadcastHandler) respondWithBroadcastError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAudience),
+		errors.Is(err, service.ErrInvalidBroadcastMessage),
+		errors.Is(err, service.ErrInvalidRecurrence):
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+	case errors.Is(err, service.ErrBroadcastTemplateNotFound):
+		RespondWithError(w, http.StatusBadRequest, "Broadcast template not found", h.logger)
+	case errors.Is(err, service.ErrScheduleNotFound):
+		RespondWithError(w, http.StatusBadRequest, "Audience schedule not found", h.logger)
+	case errors.Is(err, service.ErrBroadcastGroupNotFound):
+		RespondWithError(w, http.StatusBadRequest, "Audience group not found", h.logger)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, message, h.logger, "error", err)
+	}
+}
+
+// audienceFilterResponse decodes the audience filter stored with a broadcast.
+func audienceFilterResponse(stored sql.NullString) *service.AudienceFilter {
+	if !stored.Valid {
+		return nil
+	}
+	var filter service.AudienceFilter
+	if err := json.Unmarshal([]byte(stored.String), &filter); err != nil {
+		return nil
+	}
+	return &filter
+}
+
+// PreviewBroadcastAudienceRequest is the audience to preview
+type PreviewBroadcastAudienceRequest struct {
+	Audience       string                  `json:"audience"`
+	AudienceFilter *service.AudienceFilter `json:"audience_filter,omitempty"`
+}
+
+// AdminPreviewBroadcastAudience handles POST /api/admin/broadcasts/preview
+// @Summary Preview a broadcast audience
+// @Description Counts the users an audience currently covers, with a sample of them, without creating a broadcast.
+// @Tags admin-broadcasts
+// @Accept json
+// @Produce json
+// @Param request body PreviewBroadcastAudienceRequest true "Audience"
+// @Success 200 {object} service.AudiencePreview "Audience preview"
+// @Failure 400 {object} ErrorResponse "Invalid audience"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcasts/preview [post]
+func (h *AdminBroadcastHandler) AdminPreviewBroadcastAudience(w http.ResponseWriter, r *http.Request) {
+	var req PreviewBroadcastAudienceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload", h.logger, "error", err)
+		return
+	}
+	var filter service.AudienceFilter
+	if req.AudienceFilter != nil {
+		filter = *req.AudienceFilter
+	}
+
+	preview, err := h.broadcastService.PreviewAudience(r.Context(), req.Audience, filter)
+	if err != nil {
+		h.respondWithBroadcastError(w, err, "Failed to preview audience")
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, preview, h.logger)
+}
+
+// AdminListBroadcastRecipients handles GET /api/admin/broadcasts/{id}/recipients
+// @Summary List a broadcast's recipients
+// @Description Returns the users the broadcast's audience resolved to when it was created.
+// @Tags admin-broadcasts
+// @Produce json
+// @Param id path int true "Broadcast ID"
+// @Success 200 {array} service.BroadcastRecipient "Recipients"
+// @Failure 400 {object} ErrorResponse "Invalid broadcast ID"
+// @Failure 404 {object} ErrorResponse "Broadcast not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcasts/{id}/recipients [get]
+func (h *AdminBroadcastHandler) AdminListBroadcastRecipients(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid broadcast ID", h.logger, "id", idStr)
+		return
+	}
+
+	recipients, err := h.broadcastService.ListRecipients(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrBroadcastNotFound) {
+			RespondWithError(w, http.StatusNotFound, "Broadcast not found", h.logger, "id", id)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to list broadcast recipients", h.logger, "error", err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, recipients, h.logger)
+}
+
+// ResendBroadcastResponse reports how many SMS re-sends were queued.
+type ResendBroadcastResponse struct {
+	Queued int `json:"queued"`
+}
+
+// AdminGetBroadcastReceipts handles GET /api/admin/broadcasts/{id}/receipts
+// @Summary Get a broadcast's delivery and read receipts
+// @Description Breaks a broadcast's recipients down into delivered, failed, pending and not sent, and read and unread, with the state of each recipient.
+// @Tags admin-broadcasts
+// @Produce json
+// @Param id path int true "Broadcast ID"
+// @Success 200 {object} service.BroadcastReceipts "Receipts"
+// @Failure 400 {object} ErrorResponse "Invalid broadcast ID"
+// @Failure 404 {object} ErrorResponse "Broadcast not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcasts/{id}/receipts [get]
+func (h *AdminBroadcastHandler) AdminGetBroadcastReceipts(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid broadcast ID", h.logger, "id", idStr)
+		return
+	}
+
+	receipts, err := h.broadcastService.Receipts(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrBroadcastNotFound) {
+			RespondWithError(w, http.StatusNotFound, "Broadcast not found", h.logger, "id", id)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to get broadcast receipts", h.logger, "error", err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, receipts, h.logger)
+}
+
+// AdminResendUnreadBroadcast handles POST /api/admin/broadcasts/{id}/resend-unread
+// @Summary Re-send a broadcast by SMS to recipients who have not read it
+// @Description Queues an SMS for each unread recipient, except those who already got it by SMS or were re-sent it before.
+// @Tags admin-broadcasts
+// @Produce json
+// @Param id path int true "Broadcast ID"
+// @Success 200 {object} ResendBroadcastResponse "Number of SMS queued"
+// @Failure 400 {object} ErrorResponse "Invalid broadcast ID"
+// @Failure 404 {object} ErrorResponse "Broadcast not found"
+// @Failure 409 {object} ErrorResponse "Broadcast not sent yet"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcasts/{id}/resend-unread [post]
+func (h *AdminBroadcastHandler) AdminResendUnreadBroadcast(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		return 0, err
-	}
-
-	switch audience {
-	case "all":
-		return int64(len(users)), nil
-	case "admins":
-		count := int64(0)
-		for _, user := range users {
-			if user.Role == "admin" {
-				count++
-			}
+		RespondWithError(w, http.StatusBadRequest, "Invalid broadcast ID", h.logger, "id", idStr)
+		return
+	}
+
+	queued, err := h.broadcastService.ResendUnreadBySMS(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBroadcastNotFound):
+			RespondWithError(w, http.StatusNotFound, "Broadcast not found", h.logger, "id", id)
+		case errors.Is(err, service.ErrBroadcastNotSent):
+			RespondWithError(w, http.StatusConflict, "Broadcast has not been sent yet", h.logger, "id", id)
+		default:
+			RespondWithError(w, http.StatusInternalServerError, "Failed to re-send broadcast", h.logger, "error", err)
 		}
-		return count, nil
-	case "owls":
-		count := int64(0)
-		for _, user := range users {
-			if user.Role == "owl" || user.Role == "" {
-				count++
-			}
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, ResendBroadcastResponse{Queued: queued}, h.logger)
+}
+
+// ScheduleBroadcastRequest is the new send time of a broadcast.
+type ScheduleBroadcastRequest struct {
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
+
+// AdminScheduleBroadcast handles PUT /api/admin/broadcasts/{id}/schedule
+// @Summary Reschedule a broadcast
+// @Description Moves a broadcast that has not been sent yet to a new time. For a recurring broadcast this sets its next run.
+// @Tags admin-broadcasts
+// @Accept json
+// @Produce json
+// @Param id path int true "Broadcast ID"
+// @Param request body ScheduleBroadcastRequest true "New send time"
+// @Success 200 {object} BroadcastResponse "Broadcast rescheduled"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 404 {object} ErrorResponse "Broadcast not found"
+// @Failure 409 {object} ErrorResponse "Broadcast already sent"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcasts/{id}/schedule [put]
+func (h *AdminBroadcastHandler) AdminScheduleBroadcast(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid broadcast ID", h.logger, "id", idStr)
+		return
+	}
+
+	var req ScheduleBroadcastRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload", h.logger, "error", err)
+		return
+	}
+	if req.ScheduledAt == nil {
+		RespondWithError(w, http.StatusBadRequest, "scheduled_at is required", h.logger)
+		return
+	}
+
+	broadcast, err := h.broadcastService.ScheduleBroadcast(r.Context(), id, *req.ScheduledAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBroadcastNotFound):
+			RespondWithError(w, http.StatusNotFound, "Broadcast not found", h.logger, "id", id)
+		case errors.Is(err, service.ErrBroadcastAlreadySent):
+			RespondWithError(w, http.StatusConflict, "Broadcast has already been sent", h.logger, "id", id)
+		default:
+			RespondWithError(w, http.StatusInternalServerError, "Failed to schedule broadcast", h.logger, "error", err)
 		}
-		return count, nil
-	case "active":
-		// For now, return all users. In future, filter by last activity
-		return int64(len(users)), nil
-	default:
-		return 0, nil
+		return
 	}
+
+	RespondWithJSON(w, http.StatusOK, BroadcastResponse{
+		BroadcastID:    broadcast.BroadcastID,
+		Title:          broadcast.Title,
+		Message:        broadcast.Message,
+		Audience:       broadcast.Audience,
+		AudienceFilter: audienceFilterResponse(broadcast.AudienceFilter),
+		SenderUserID:   broadcast.SenderUserID,
+		PushEnabled:    broadcast.PushEnabled,
+		Urgent:         broadcast.Urgent,
+		ScheduledAt:    nullTimeToPointer(broadcast.ScheduledAt),
+		Recurrence:     broadcast.RecurrenceCron.String,
+		ParentID:       nullInt64ToPointer(broadcast.ParentBroadcastID),
+		SentAt:         nullTimeToPointer(broadcast.SentAt),
+		Status:         broadcast.Status,
+		RecipientCount: nullInt64ToInt64(broadcast.RecipientCount),
+		SentCount:      nullInt64ToInt64(broadcast.SentCount),
+		FailedCount:    nullInt64ToInt64(broadcast.FailedCount),
+		CreatedAt:      broadcast.CreatedAt.Time,
+	}, h.logger)
 }
 
 // nullTimeToPointer converts sql.NullTime to *time.Time
@@ -123,6 +123,10 @@ func TestAdminBroadcastHandlers_CreateBroadcast_DifferentAudiences(t *testing.T)
 	})
 	require.NoError(t, err)
 
+	// Only owls have been active recently
+	_, err = app.DB.Exec(`UPDATE users SET last_activity_date = date('now') WHERE role = 'owl'`)
+	require.NoError(t, err)
+
 	testCases := []struct {
 		audience      string
 		expectedCount int64
@@ -131,7 +135,7 @@ func TestAdminBroadcastHandlers_CreateBroadcast_DifferentAudiences(t *testing.T)
 		{"all", 3, "all users (2 admins + 1 owl)"},
 		{"admins", 2, "admin users only"},
 		{"owls", 1, "owl users only"},
-		{"active", 3, "active users (currently all users)"},
+		{"active", 1, "users active in the last 30 days"},
 	}
 
 	for _, tc := range testCases {
@@ -232,6 +236,7 @@ func TestAdminBroadcastHandlers_ListBroadcasts_Success(t *testing.T) {
 		SenderUserID:   adminUser.UserID,
 		PushEnabled:    true,
 		RecipientCount: sql.NullInt64{Int64: 5, Valid: true},
+		Status:         "pending",
 	})
 	require.NoError(t, err)
 
@@ -241,6 +246,7 @@ func TestAdminBroadcastHandlers_ListBroadcasts_Success(t *testing.T) {
 		SenderUserID:   adminUser.UserID,
 		PushEnabled:    false,
 		RecipientCount: sql.NullInt64{Int64: 3, Valid: true},
+		Status:         "pending",
 	})
 	require.NoError(t, err)
 
@@ -316,6 +322,7 @@ func TestAdminBroadcastHandlers_GetBroadcast_Success(t *testing.T) {
 		PushEnabled:    true,
 		ScheduledAt:    sql.NullTime{Time: scheduledTime, Valid: true},
 		RecipientCount: sql.NullInt64{Int64: 2, Valid: true},
+		Status:         "pending",
 	})
 	require.NoError(t, err)
 
@@ -455,6 +462,10 @@ func TestAdminBroadcastHandlers_RecipientCountCalculation(t *testing.T) {
 		require.NoError(t, err)
 	}
 
+	// Only owls have been active recently
+	_, err = app.DB.Exec(`UPDATE users SET last_activity_date = date('now') WHERE role = 'owl'`)
+	require.NoError(t, err)
+
 	// Test recipient count for different audiences
 	testCases := []struct {
 		audience      string
@@ -463,7 +474,7 @@ func TestAdminBroadcastHandlers_RecipientCountCalculation(t *testing.T) {
 		{"all", 5},    // 2 admins + 3 owls
 		{"admins", 2}, // 2 admins
 		{"owls", 3},   // 3 owls
-		{"active", 5}, // all users (for now)
+		{"active", 3}, // 3 recently active owls
 	}
 
 	for _, tc := range testCases {
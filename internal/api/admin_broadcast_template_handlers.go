@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"night-owls-go/internal/service"
+)
+
+// AdminBroadcastTemplateHandler handles reusable broadcast templates.
+type AdminBroadcastTemplateHandler struct {
+	templateService *service.BroadcastTemplateService
+	logger          *slog.Logger
+}
+
+// NewAdminBroadcastTemplateHandler creates a new AdminBroadcastTemplateHandler.
+func NewAdminBroadcastTemplateHandler(templateService *service.BroadcastTemplateService, logger *slog.Logger) *AdminBroadcastTemplateHandler {
+	return &AdminBroadcastTemplateHandler{
+		templateService: templateService,
+		logger:          logger.With("handler", "AdminBroadcastTemplateHandler"),
+	}
+}
+
+// BroadcastTemplateRequest is the body for creating or updating a broadcast
+// template. The message may use the same variables as a broadcast.
+type BroadcastTemplateRequest struct {
+	Name    string `json:"name"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+}
+
+func (h *AdminBroadcastTemplateHandler) respondWithTemplateError(w http.ResponseWriter, err error, templateID int64) {
+	switch {
+	case errors.Is(err, service.ErrBroadcastTemplateNotFound):
+		RespondWithError(w, http.StatusNotFound, "Broadcast template not found", h.logger, "template_id", templateID)
+	case errors.Is(err, service.ErrInvalidBroadcastTemplate), errors.Is(err, service.ErrInvalidBroadcastMessage):
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+	case errors.Is(err, service.ErrBroadcastTemplateNameTaken):
+		RespondWithError(w, http.StatusConflict, err.Error(), h.logger)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to process broadcast template", h.logger, "error", err)
+	}
+}
+
+func (h *AdminBroadcastTemplateHandler) templateID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid broadcast template ID", h.logger, "id", idStr)
+		return 0, false
+	}
+	return id, true
+}
+
+// AdminListBroadcastTemplates handles GET /api/admin/broadcast-templates
+// @Summary List broadcast templates
+// @Tags admin-broadcasts
+// @Produce json
+// @Success 200 {array} db.BroadcastTemplate "Templates"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-templates [get]
+func (h *AdminBroadcastTemplateHandler) AdminListBroadcastTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := h.templateService.ListTemplates(r.Context())
+	if err != nil {
+		h.respondWithTemplateError(w, err, 0)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, templates, h.logger)
+}
+
+// AdminGetBroadcastTemplate handles GET /api/admin/broadcast-templates/{id}
+// @Summary Get a broadcast template
+// @Tags admin-broadcasts
+// @Produce json
+// @Param id path int true "Template ID"
+// @Success 200 {object} db.BroadcastTemplate "Template"
+// @Failure 400 {object} ErrorResponse "Invalid template ID"
+// @Failure 404 {object} ErrorResponse "Template not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-templates/{id} [get]
+func (h *AdminBroadcastTemplateHandler) AdminGetBroadcastTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.templateID(w, r)
+	if !ok {
+		return
+	}
+	tmpl, err := h.templateService.GetTemplate(r.Context(), id)
+	if err != nil {
+		h.respondWithTemplateError(w, err, id)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, tmpl, h.logger)
+}
+
+// AdminCreateBroadcastTemplate handles POST /api/admin/broadcast-templates
+// @Summary Create a broadcast template
+// @Description The message may use {{.name}}, {{.first_name}}, {{.next_shift}} and {{.unfilled_slots}}, filled in for each recipient.
+// @Tags admin-broadcasts
+// @Accept json
+// @Produce json
+// @Param request body BroadcastTemplateRequest true "Template"
+// @Success 201 {object} db.BroadcastTemplate "Template created"
+// @Failure 400 {object} ErrorResponse "Invalid template"
+// @Failure 409 {object} ErrorResponse "Name already taken"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-templates [post]
+func (h *AdminBroadcastTemplateHandler) AdminCreateBroadcastTemplate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	var req BroadcastTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload", h.logger, "error", err)
+		return
+	}
+
+	tmpl, err := h.templateService.CreateTemplate(r.Context(), req.Name, req.Title, req.Message, userID)
+	if err != nil {
+		h.respondWithTemplateError(w, err, 0)
+		return
+	}
+	RespondWithJSON(w, http.StatusCreated, tmpl, h.logger)
+}
+
+// AdminUpdateBroadcastTemplate handles PUT /api/admin/broadcast-templates/{id}
+// @Summary Update a broadcast template
+// @Description Broadcasts already created from the template keep their wording.
+// @Tags admin-broadcasts
+// @Accept json
+// @Produce json
+// @Param id path int true "Template ID"
+// @Param request body BroadcastTemplateRequest true "Template"
+// @Success 200 {object} db.BroadcastTemplate "Template updated"
+// @Failure 400 {object} ErrorResponse "Invalid template"
+// @Failure 404 {object} ErrorResponse "Template not found"
+// @Failure 409 {object} ErrorResponse "Name already taken"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-templates/{id} [put]
+func (h *AdminBroadcastTemplateHandler) AdminUpdateBroadcastTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.templateID(w, r)
+	if !ok {
+		return
+	}
+
+	var req BroadcastTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request payload", h.logger, "error", err)
+		return
+	}
+
+	tmpl, err := h.templateService.UpdateTemplate(r.Context(), id, req.Name, req.Title, req.Message)
+	if err != nil {
+		h.respondWithTemplateError(w, err, id)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, tmpl, h.logger)
+}
+
+// AdminDeleteBroadcastTemplate handles DELETE /api/admin/broadcast-templates/{id}
+// @Summary Delete a broadcast template
+// @Tags admin-broadcasts
+// @Param id path int true "Template ID"
+// @Success 204 "Template deleted"
+// @Failure 400 {object} ErrorResponse "Invalid template ID"
+// @Failure 404 {object} ErrorResponse "Template not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/broadcast-templates/{id} [delete]
+func (h *AdminBroadcastTemplateHandler) AdminDeleteBroadcastTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.templateID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.templateService.DeleteTemplate(r.Context(), id); err != nil {
+		h.respondWithTemplateError(w, err, id)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
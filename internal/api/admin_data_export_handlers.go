@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"night-owls-go/internal/service"
+)
+
+// AdminDataExportHandler handles CSV and XLSX exports of reports, bookings and attendance.
+type AdminDataExportHandler struct {
+	exportService *service.DataExportService
+	logger        *slog.Logger
+}
+
+// NewAdminDataExportHandler creates a new AdminDataExportHandler.
+func NewAdminDataExportHandler(exportService *service.DataExportService, logger *slog.Logger) *AdminDataExportHandler {
+	return &AdminDataExportHandler{
+		exportService: exportService,
+		logger:        logger.With("handler", "AdminDataExportHandler"),
+	}
+}
+
+// ExportDatasetResponse describes an exportable dataset and its columns.
+type ExportDatasetResponse struct {
+	Dataset string   `json:"dataset"`
+	Columns []string `json:"columns"`
+}
+
+// exportContentTypes maps export formats to their MIME types.
+var exportContentTypes = map[string]string{
+	service.ExportFormatCSV:  "text/csv; charset=utf-8",
+	service.ExportFormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// AdminListExportDatasetsHandler handles GET /api/admin/exports
+// @Summary List export datasets (Admin)
+// @Description List the datasets that can be exported and the columns each one offers
+// @Tags admin/exports
+// @Produce json
+// @Success 200 {array} ExportDatasetResponse "Exportable datasets"
+// @Security BearerAuth
+// @Router /api/admin/exports [get]
+func (h *AdminDataExportHandler) AdminListExportDatasetsHandler(w http.ResponseWriter, r *http.Request) {
+	datasets := []string{service.ExportDatasetReports, service.ExportDatasetBookings, service.ExportDatasetAttendance}
+	response := make([]ExportDatasetResponse, 0, len(datasets))
+	for _, dataset := range datasets {
+		columns, err := service.ExportColumns(dataset)
+		if err != nil {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to list export columns", h.logger, "dataset", dataset, "error", err)
+			return
+		}
+		response = append(response, ExportDatasetResponse{Dataset: dataset, Columns: columns})
+	}
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminExportDatasetHandler handles GET /api/admin/exports/{dataset}
+// @Summary Export a dataset as CSV or XLSX (Admin)
+// @Description Stream reports, bookings or attendance for offline statistics. Names are masked. Bookings and attendance are filtered on shift start; attendance only covers shifts that have started. Every export is recorded in the audit trail with the filters used.
+// @Tags admin/exports
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param dataset path string true "Dataset: reports, bookings or attendance"
+// @Param format query string false "Export format: csv (default) or xlsx"
+// @Param columns query string false "Comma separated columns to include, in order (default all)"
+// @Param from query string false "Start date (YYYY-MM-DD or RFC3339)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD or RFC3339)"
+// @Param severity query int false "Filter reports by severity (0=info, 1=warning, 2=critical)"
+// @Param category_id query int false "Filter reports by incident category ID"
+// @Param schedule_id query int false "Filter by schedule ID (0 for off-shift reports)"
+// @Param user_id query int false "Filter by user ID"
+// @Success 200 {file} file "Export file"
+// @Failure 400 {object} ErrorResponse "Invalid format, columns or filters"
+// @Failure 404 {object} ErrorResponse "Unknown dataset"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/exports/{dataset} [get]
+func (h *AdminDataExportHandler) AdminExportDatasetHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	filter, err := parseAdminReportFilter(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+
+	q := r.URL.Query()
+	req := service.DataExportRequest{
+		Dataset: r.PathValue("dataset"),
+		Format:  q.Get("format"),
+		Filter:  filter,
+	}
+	if req.Format == "" {
+		req.Format = service.ExportFormatCSV
+	}
+	contentType, ok := exportContentTypes[req.Format]
+	if !ok {
+		RespondWithError(w, http.StatusBadRequest, "format must be 'csv' or 'xlsx'", h.logger, "format", req.Format)
+		return
+	}
+	if columns := q.Get("columns"); columns != "" {
+		req.Columns = strings.Split(columns, ",")
+	}
+
+	// Headers are only sent with the first body write, so validation errors
+	// returned before streaming starts can still be answered with a JSON error.
+	filename := fmt.Sprintf("night-owls-%s-%s.%s", req.Dataset, time.Now().UTC().Format("20060102-150405"), req.Format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	count, err := h.exportService.Export(r.Context(), w, req, userID, ipAddress, userAgent)
+	if err != nil {
+		w.Header().Del("Content-Disposition")
+		switch {
+		case errors.Is(err, service.ErrInvalidExportDataset):
+			RespondWithError(w, http.StatusNotFound, "Unknown export dataset", h.logger, "dataset", req.Dataset)
+		case errors.Is(err, service.ErrInvalidExportColumn), errors.Is(err, service.ErrExportFilterNotSupported):
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		default:
+			RespondWithError(w, http.StatusInternalServerError, "Failed to export data", h.logger, "error", err)
+		}
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Data exported", "dataset", req.Dataset, "format", req.Format, "row_count", count, "admin_user_id", userID)
+}
@@ -0,0 +1,219 @@
+package api_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readExportCSV(t *testing.T, body []byte) [][]string {
+	t.Helper()
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	require.NoError(t, err, "export is not valid CSV: %s", string(body))
+	return records
+}
+
+func TestAdminDataExport(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	_, adminToken := app.createTestUserAndLogin(t, "+15550004001", "Test Admin", "admin")
+	owl, owlToken := app.createTestUserAndLogin(t, "+15550004002", "Jane Smith", "owl")
+
+	schedule, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+		Name:            "Export Patrol",
+		CronExpr:        "0 0 * * *",
+		DurationMinutes: 120,
+		Timezone:        sql.NullString{String: "UTC", Valid: true},
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	attended, err := app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+		UserID:     owl.UserID,
+		ScheduleID: schedule.ScheduleID,
+		ShiftStart: now.Add(-48 * time.Hour),
+		ShiftEnd:   now.Add(-46 * time.Hour),
+		BuddyName:  sql.NullString{String: "Sam Jones", Valid: true},
+	})
+	require.NoError(t, err)
+	_, err = app.DB.Exec(`UPDATE bookings SET checked_in_at = ? WHERE booking_id = ?`, attended.ShiftStart.Add(12*time.Minute), attended.BookingID)
+	require.NoError(t, err)
+	missed, err := app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+		UserID:     owl.UserID,
+		ScheduleID: schedule.ScheduleID,
+		ShiftStart: now.Add(-24 * time.Hour),
+		ShiftEnd:   now.Add(-22 * time.Hour),
+	})
+	require.NoError(t, err)
+	upcoming, err := app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+		UserID:     owl.UserID,
+		ScheduleID: schedule.ScheduleID,
+		ShiftStart: now.Add(24 * time.Hour),
+		ShiftEnd:   now.Add(26 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = app.DB.Exec(`INSERT INTO reports (booking_id, user_id, severity, message) VALUES (?, ?, 2, '=HYPERLINK("http://evil.example")')`,
+		attended.BookingID, owl.UserID)
+	require.NoError(t, err)
+	_, err = app.DB.Exec(`INSERT INTO reports (user_id, severity, message, latitude, longitude) VALUES (?, 1, 'Gate left open', -33.9249, 18.4241)`, owl.UserID)
+	require.NoError(t, err)
+	_, err = app.DB.Exec(`INSERT INTO reports (user_id, severity, message, created_at) VALUES (?, 0, 'Last year', '2020-01-15 10:00:00')`, owl.UserID)
+	require.NoError(t, err)
+
+	t.Run("lists datasets and columns", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/exports", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var datasets []api.ExportDatasetResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &datasets))
+		require.Len(t, datasets, 3)
+		assert.Equal(t, "reports", datasets[0].Dataset)
+		assert.Contains(t, datasets[0].Columns, "severity_label")
+	})
+
+	t.Run("reports as CSV", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/exports/reports", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		assert.Equal(t, "text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "night-owls-reports-")
+
+		records := readExportCSV(t, rr.Body.Bytes())
+		require.Len(t, records, 4)
+		header := records[0]
+		assert.Equal(t, "report_id", header[0])
+		column := func(record []string, name string) string {
+			for i, h := range header {
+				if h == name {
+					return record[i]
+				}
+			}
+			t.Fatalf("no column %s", name)
+			return ""
+		}
+		assert.Equal(t, "Jane S.", column(records[1], "reporter"), "reporter names are masked")
+		assert.Equal(t, `'=HYPERLINK("http://evil.example")`, column(records[1], "message"), "formulas are neutralised")
+		assert.Equal(t, "Incident", column(records[1], "severity_label"))
+		assert.Equal(t, "Export Patrol", column(records[1], "schedule_name"))
+		assert.Equal(t, "-33.9249", column(records[2], "latitude"))
+		assert.Equal(t, "Off-Shift Report", column(records[2], "schedule_name"))
+		assert.Equal(t, "", column(records[1], "latitude"))
+	})
+
+	t.Run("columns and filters", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/exports/reports?columns=severity,message&severity=1", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		assert.Equal(t, [][]string{{"severity", "message"}, {"1", "Gate left open"}}, readExportCSV(t, rr.Body.Bytes()))
+
+		rr = app.makeRequest(t, "GET", "/api/admin/exports/reports?columns=message&from=2020-01-01&to=2020-01-31", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		assert.Equal(t, [][]string{{"message"}, {"Last year"}}, readExportCSV(t, rr.Body.Bytes()))
+	})
+
+	t.Run("bookings and attendance", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/exports/bookings?columns=booking_id,user_name,buddy_name,report_count", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		records := readExportCSV(t, rr.Body.Bytes())
+		require.Len(t, records, 4, "bookings include upcoming shifts")
+		assert.Equal(t, []string{"Jane S.", "Sam J.", "1"}, records[1][1:])
+		assert.Equal(t, "", records[2][2])
+
+		rr = app.makeRequest(t, "GET", "/api/admin/exports/attendance?columns=booking_id,attended,minutes_late", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		records = readExportCSV(t, rr.Body.Bytes())
+		require.Len(t, records, 3, "attendance only covers shifts that have started")
+		assert.Equal(t, []string{strconv.FormatInt(attended.BookingID, 10), "true", "12"}, records[1])
+		assert.Equal(t, []string{strconv.FormatInt(missed.BookingID, 10), "false", ""}, records[2])
+		for _, record := range records {
+			assert.NotEqual(t, strconv.FormatInt(upcoming.BookingID, 10), record[0])
+		}
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/exports/reports?format=xlsx&columns=report_id,message", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", rr.Header().Get("Content-Type"))
+
+		body := rr.Body.Bytes()
+		zr, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		require.NoError(t, err)
+		var sheet string
+		for _, f := range zr.File {
+			if f.Name == "xl/worksheets/sheet1.xml" {
+				rc, err := f.Open()
+				require.NoError(t, err)
+				content, _ := io.ReadAll(rc)
+				rc.Close()
+				sheet = string(content)
+			}
+		}
+		assert.Equal(t, 4, strings.Count(sheet, "<row>"))
+		assert.Contains(t, sheet, "Gate left open")
+	})
+
+	t.Run("large exports are read in pages", func(t *testing.T) {
+		tx, err := app.DB.Begin()
+		require.NoError(t, err)
+		for i := 0; i < 1100; i++ {
+			_, err := tx.Exec(`INSERT INTO reports (user_id, severity, message, created_at) VALUES (?, 0, 'Bulk', '2021-06-01 12:00:00')`, owl.UserID)
+			require.NoError(t, err)
+		}
+		require.NoError(t, tx.Commit())
+
+		rr := app.makeRequest(t, "GET", "/api/admin/exports/reports?columns=report_id&from=2021-06-01&to=2021-06-01", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		records := readExportCSV(t, rr.Body.Bytes())
+		require.Len(t, records, 1101)
+		seen := map[string]bool{}
+		for _, record := range records[1:] {
+			assert.False(t, seen[record[0]], "report %s exported twice", record[0])
+			seen[record[0]] = true
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for path, want := range map[string]int{
+			"/api/admin/exports/users":                    http.StatusNotFound,
+			"/api/admin/exports/reports?format=pdf":       http.StatusBadRequest,
+			"/api/admin/exports/reports?columns=password": http.StatusBadRequest,
+			"/api/admin/exports/bookings?severity=2":      http.StatusBadRequest,
+			"/api/admin/exports/reports?from=yesterday":   http.StatusBadRequest,
+		} {
+			rr := app.makeRequest(t, "GET", path, nil, adminToken)
+			assert.Equal(t, want, rr.Code, "%s: %s", path, rr.Body.String())
+		}
+
+		rr := app.makeRequest(t, "GET", "/api/admin/exports/reports", nil, owlToken)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("every export is audited with its filters", func(t *testing.T) {
+		var count int
+		require.NoError(t, app.DB.QueryRow(`SELECT COUNT(*) FROM audit_events WHERE event_type = 'data.exported'`).Scan(&count))
+		assert.Equal(t, 7, count, "rejected requests are not audited")
+
+		var details string
+		require.NoError(t, app.DB.QueryRow(`SELECT details FROM audit_events WHERE event_type = 'data.exported' AND details LIKE '%"severity":1%'`).Scan(&details))
+		var parsed map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(details), &parsed))
+		assert.Equal(t, "reports", parsed["dataset"])
+		assert.Equal(t, "csv", parsed["format"])
+		assert.Equal(t, []interface{}{"severity", "message"}, parsed["columns"])
+	})
+}
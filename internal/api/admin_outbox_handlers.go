@@ -0,0 +1,243 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+)
+
+// AdminOutboxHandler handles the admin view of dead-lettered outbox items.
+type AdminOutboxHandler struct {
+	deadLetterService *service.OutboxDeadLetterService
+	auditService      *service.AuditService
+	logger            *slog.Logger
+}
+
+// NewAdminOutboxHandler creates a new AdminOutboxHandler.
+func NewAdminOutboxHandler(deadLetterService *service.OutboxDeadLetterService, auditService *service.AuditService, logger *slog.Logger) *AdminOutboxHandler {
+	return &AdminOutboxHandler{
+		deadLetterService: deadLetterService,
+		auditService:      auditService,
+		logger:            logger.With("handler", "AdminOutboxHandler"),
+	}
+}
+
+// OutboxItemResponse describes an outbox item in the dead-letter view
+type OutboxItemResponse struct {
+	OutboxID       int64      `json:"outbox_id"`
+	MessageType    string     `json:"message_type"`
+	Recipient      string     `json:"recipient"`
+	UserID         *int64     `json:"user_id,omitempty"`
+	Payload        string     `json:"payload,omitempty"`
+	Status         string     `json:"status"`
+	RetryCount     int64      `json:"retry_count"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	SendAt         time.Time  `json:"send_at"`
+	DeadLetteredAt *time.Time `json:"dead_lettered_at,omitempty"`
+}
+
+func toOutboxItemResponse(item db.Outbox) OutboxItemResponse {
+	payload := item.Payload.String
+	if item.MessageType == service.OutboxMessageOTPVerification || item.MessageType == service.OutboxMessageEmailVerification {
+		// Codes are secrets even once expired
+		payload = "[redacted]"
+	}
+	return OutboxItemResponse{
+		OutboxID:       item.OutboxID,
+		MessageType:    item.MessageType,
+		Recipient:      item.Recipient,
+		UserID:         nullInt64ToPointer(item.UserID),
+		Payload:        payload,
+		Status:         item.Status,
+		RetryCount:     item.RetryCount.Int64,
+		LastError:      item.LastError.String,
+		CreatedAt:      nullTimeToPointer(item.CreatedAt),
+		SendAt:         item.SendAt,
+		DeadLetteredAt: nullTimeToPointer(item.DeadLetteredAt),
+	}
+}
+
+func (h *AdminOutboxHandler) parseOutboxID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	outboxIDStr := r.PathValue("id")
+	outboxID, err := strconv.ParseInt(outboxIDStr, 10, 64)
+	if err != nil || outboxID <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "Invalid outbox item ID", h.logger, "outbox_id", outboxIDStr)
+		return 0, false
+	}
+	return outboxID, true
+}
+
+func (h *AdminOutboxHandler) respondWithDeadLetterError(w http.ResponseWriter, err error, outboxID int64) {
+	switch {
+	case errors.Is(err, service.ErrOutboxItemNotFound):
+		RespondWithError(w, http.StatusNotFound, "Outbox item not found", h.logger, "outbox_id", outboxID)
+	case errors.Is(err, service.ErrOutboxItemNotDeadLettered):
+		RespondWithError(w, http.StatusConflict, "Outbox item is not dead-lettered", h.logger, "outbox_id", outboxID)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to process outbox item", h.logger, "error", err.Error())
+	}
+}
+
+// ListDeadLettersHandler handles GET /api/admin/outbox/dead-letters
+// @Summary List dead-lettered outbox items (Admin)
+// @Description Returns outbox items that failed on every attempt, most recently dead-lettered first
+// @Tags admin/outbox
+// @Produce json
+// @Param message_type query string false "Only items of this message type"
+// @Param limit query int false "Maximum items to return (default 50, max 500)"
+// @Param offset query int false "Number of items to skip (default 0)"
+// @Success 200 {array} OutboxItemResponse "Dead-lettered items"
+// @Failure 400 {object} ErrorResponse "Invalid limit or offset"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/outbox/dead-letters [get]
+func (h *AdminOutboxHandler) ListDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	limit := int64(50)
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || parsed <= 0 || parsed > 500 {
+			RespondWithError(w, http.StatusBadRequest, "limit must be between 1 and 500", h.logger, "limit", limitStr)
+			return
+		}
+		limit = parsed
+	}
+	offset := int64(0)
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		parsed, err := strconv.ParseInt(offsetStr, 10, 64)
+		if err != nil || parsed < 0 {
+			RespondWithError(w, http.StatusBadRequest, "offset must be zero or more", h.logger, "offset", offsetStr)
+			return
+		}
+		offset = parsed
+	}
+
+	items, err := h.deadLetterService.ListDeadLetters(r.Context(), r.URL.Query().Get("message_type"), limit, offset)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to list dead-lettered outbox items", h.logger, "error", err.Error())
+		return
+	}
+
+	response := make([]OutboxItemResponse, 0, len(items))
+	for _, item := range items {
+		response = append(response, toOutboxItemResponse(item))
+	}
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// GetDeadLetterHandler handles GET /api/admin/outbox/dead-letters/{id}
+// @Summary Get a dead-lettered outbox item (Admin)
+// @Tags admin/outbox
+// @Produce json
+// @Param id path int true "Outbox item ID"
+// @Success 200 {object} OutboxItemResponse "Dead-lettered item"
+// @Failure 400 {object} ErrorResponse "Invalid outbox item ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Outbox item not found"
+// @Failure 409 {object} ErrorResponse "Outbox item is not dead-lettered"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/outbox/dead-letters/{id} [get]
+func (h *AdminOutboxHandler) GetDeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	outboxID, ok := h.parseOutboxID(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.deadLetterService.GetDeadLetter(r.Context(), outboxID)
+	if err != nil {
+		h.respondWithDeadLetterError(w, err, outboxID)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, toOutboxItemResponse(item), h.logger)
+}
+
+// RequeueDeadLetterHandler handles POST /api/admin/outbox/dead-letters/{id}/requeue
+// @Summary Requeue a dead-lettered outbox item (Admin)
+// @Description Returns the item to the outbox with its retry count reset. It is sent on the next dispatcher run.
+// @Tags admin/outbox
+// @Produce json
+// @Param id path int true "Outbox item ID"
+// @Success 200 {object} OutboxItemResponse "Item requeued"
+// @Failure 400 {object} ErrorResponse "Invalid outbox item ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Outbox item not found"
+// @Failure 409 {object} ErrorResponse "Outbox item is not dead-lettered"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/outbox/dead-letters/{id}/requeue [post]
+func (h *AdminOutboxHandler) RequeueDeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	outboxID, ok := h.parseOutboxID(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.deadLetterService.Requeue(r.Context(), outboxID)
+	if err != nil {
+		h.respondWithDeadLetterError(w, err, outboxID)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if auditErr := h.auditService.LogOutboxItemRequeued(r.Context(), adminUserID, outboxID, item.MessageType, item.LastError.String, ipAddress, userAgent); auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log outbox requeue audit event", "outbox_id", outboxID, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusOK, toOutboxItemResponse(item), h.logger)
+}
+
+// DiscardDeadLetterHandler handles POST /api/admin/outbox/dead-letters/{id}/discard
+// @Summary Discard a dead-lettered outbox item (Admin)
+// @Description Closes the item without sending it. The row is kept for history.
+// @Tags admin/outbox
+// @Produce json
+// @Param id path int true "Outbox item ID"
+// @Success 200 {object} OutboxItemResponse "Item discarded"
+// @Failure 400 {object} ErrorResponse "Invalid outbox item ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Outbox item not found"
+// @Failure 409 {object} ErrorResponse "Outbox item is not dead-lettered"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/outbox/dead-letters/{id}/discard [post]
+func (h *AdminOutboxHandler) DiscardDeadLetterHandler(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	outboxID, ok := h.parseOutboxID(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := h.deadLetterService.Discard(r.Context(), outboxID)
+	if err != nil {
+		h.respondWithDeadLetterError(w, err, outboxID)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if auditErr := h.auditService.LogOutboxItemDiscarded(r.Context(), adminUserID, outboxID, item.MessageType, item.LastError.String, ipAddress, userAgent); auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log outbox discard audit event", "outbox_id", outboxID, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusOK, toOutboxItemResponse(item), h.logger)
+}
@@ -0,0 +1,143 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// deadLetter enqueues an outbox item and dead-letters it as the dispatcher
+// would after its last failed attempt.
+func deadLetter(t *testing.T, app *adminTestApp, messageType, payload string) db.Outbox {
+	t.Helper()
+	ctx := context.Background()
+	item, err := app.Querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
+		MessageType: messageType,
+		Recipient:   "+27820000099",
+		Payload:     sql.NullString{String: payload, Valid: true},
+		SendAt:      time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	leaseToken := sql.NullString{String: "test-lease", Valid: true}
+	claimed, err := app.Querier.ClaimOutboxItemsByID(ctx, db.ClaimOutboxItemsByIDParams{
+		LeaseToken:  leaseToken,
+		LeasedUntil: sql.NullTime{Time: time.Now().UTC().Add(time.Minute), Valid: true},
+		OutboxIds:   []int64{item.OutboxID},
+	})
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	rows, err := app.Querier.DeadLetterOutboxItem(ctx, db.DeadLetterOutboxItemParams{
+		RetryCount: sql.NullInt64{Int64: 3, Valid: true},
+		LastError:  sql.NullString{String: "gateway timeout", Valid: true},
+		OutboxID:   item.OutboxID,
+		LeaseToken: leaseToken,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rows)
+	return item
+}
+
+func TestAdminOutboxDeadLetters(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	_, adminToken := app.createTestUserAndLogin(t, "+27820000098", "Outbox Admin", "admin")
+	_, owlToken := app.createTestUserAndLogin(t, "+27820000097", "Outbox Owl", "owl")
+
+	sms := deadLetter(t, app, service.OutboxMessageSMS, "Escalation: please check the report")
+	otp := deadLetter(t, app, service.OutboxMessageOTPVerification, `{"otp": "123456"}`)
+	pending, err := app.Querier.CreateOutboxItem(context.Background(), db.CreateOutboxItemParams{
+		MessageType: service.OutboxMessageSMS,
+		Recipient:   "+27820000099",
+		SendAt:      time.Now().UTC().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	itemPath := func(item db.Outbox, action string) string {
+		return "/api/admin/outbox/dead-letters/" + strconv.FormatInt(item.OutboxID, 10) + action
+	}
+	emptyBody := func() *bytes.Buffer { return bytes.NewBufferString("{}") }
+
+	t.Run("owls cannot see dead letters", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/outbox/dead-letters", nil, owlToken)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("lists dead letters with OTPs redacted", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/outbox/dead-letters", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var items []api.OutboxItemResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &items))
+		require.Len(t, items, 2)
+		for _, item := range items {
+			assert.Equal(t, service.OutboxStatusDeadLettered, item.Status)
+			assert.Equal(t, int64(3), item.RetryCount)
+			assert.Equal(t, "gateway timeout", item.LastError)
+			assert.NotNil(t, item.DeadLetteredAt)
+			if item.OutboxID == otp.OutboxID {
+				assert.NotContains(t, item.Payload, "123456")
+			}
+		}
+
+		rr = app.makeRequest(t, "GET", "/api/admin/outbox/dead-letters?message_type=sms", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &items))
+		require.Len(t, items, 1)
+		assert.Equal(t, sms.OutboxID, items[0].OutboxID)
+	})
+
+	t.Run("items that are not dead-lettered are refused", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", itemPath(pending, ""), nil, adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		rr = app.makeRequest(t, "POST", itemPath(pending, "/requeue"), emptyBody(), adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		rr = app.makeRequest(t, "GET", "/api/admin/outbox/dead-letters/999999", nil, adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("requeue resets retries", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", itemPath(sms, "/requeue"), emptyBody(), adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		item, err := app.Querier.GetOutboxItemByID(context.Background(), sms.OutboxID)
+		require.NoError(t, err)
+		assert.Equal(t, "pending", item.Status)
+		assert.Equal(t, int64(0), item.RetryCount.Int64)
+		assert.False(t, item.DeadLetteredAt.Valid)
+
+		rr = app.makeRequest(t, "POST", itemPath(sms, "/requeue"), emptyBody(), adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code, "a requeued item is no longer dead-lettered")
+	})
+
+	t.Run("discard keeps the row", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", itemPath(otp, "/discard"), emptyBody(), adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		item, err := app.Querier.GetOutboxItemByID(context.Background(), otp.OutboxID)
+		require.NoError(t, err)
+		assert.Equal(t, "discarded", item.Status)
+
+		rr = app.makeRequest(t, "GET", "/api/admin/outbox/dead-letters", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, "[]", rr.Body.String(), "nothing left in the dead-letter view")
+	})
+
+	t.Run("requeue and discard are audited", func(t *testing.T) {
+		var count int
+		require.NoError(t, app.DB.QueryRow(`SELECT COUNT(*) FROM audit_events WHERE event_type IN ('outbox.requeued', 'outbox.discarded')`).Scan(&count))
+		assert.Equal(t, 2, count)
+	})
+}
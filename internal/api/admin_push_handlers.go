@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"night-owls-go/internal/service"
+)
+
+// AdminPushHandler handles push notification diagnostics for admins.
+type AdminPushHandler struct {
+	pushSender *service.PushSender
+	logger     *slog.Logger
+}
+
+// NewAdminPushHandler creates a new AdminPushHandler.
+func NewAdminPushHandler(pushSender *service.PushSender, logger *slog.Logger) *AdminPushHandler {
+	return &AdminPushHandler{
+		pushSender: pushSender,
+		logger:     logger.With("handler", "AdminPushHandler"),
+	}
+}
+
+// AdminListUserPushDevices handles GET /api/admin/users/{id}/push-devices
+// @Summary List a user's push devices
+// @Description Lists the user's push subscriptions with their device label and delivery stats.
+// @Tags admin-push
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {array} service.PushDevice "Devices, newest first"
+// @Failure 400 {object} ErrorResponse "Invalid user ID"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/push-devices [get]
+func (h *AdminPushHandler) AdminListUserPushDevices(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	userID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid user ID", h.logger, "id", idStr)
+		return
+	}
+
+	devices, err := h.pushSender.ListDevices(r.Context(), userID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to list push devices", h.logger, "error", err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, devices, h.logger)
+}
+
+// AdminTestUserPushDevice handles POST /api/admin/users/{id}/push-devices/{deviceId}/test
+// @Summary Send a test push to one of a user's devices
+// @Description Sends a test notification to the device and returns the push service's response. A device the push service reports as gone is removed.
+// @Tags admin-push
+// @Produce json
+// @Param id path int true "User ID"
+// @Param deviceId path int true "Device ID"
+// @Success 200 {object} service.PushResult "Outcome of the test push"
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 404 {object} ErrorResponse "Device not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/push-devices/{deviceId}/test [post]
+func (h *AdminPushHandler) AdminTestUserPushDevice(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	userID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid user ID", h.logger, "id", idStr)
+		return
+	}
+	deviceIDStr := r.PathValue("deviceId")
+	deviceID, err := strconv.ParseInt(deviceIDStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid device ID", h.logger, "device_id", deviceIDStr)
+		return
+	}
+
+	result, err := h.pushSender.SendTest(r.Context(), userID, deviceID)
+	if err != nil {
+		if errors.Is(err, service.ErrPushDeviceNotFound) {
+			RespondWithError(w, http.StatusNotFound, "Push device not found", h.logger, "user_id", userID, "device_id", deviceID)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to send test push", h.logger, "error", err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, result, h.logger)
+}
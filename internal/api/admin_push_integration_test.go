@@ -0,0 +1,148 @@
+package api_test
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"night-owls-go/internal/service"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminPushDiagnostics(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	vapidPrivate, vapidPublic, err := webpush.GenerateVAPIDKeys()
+	require.NoError(t, err)
+	app.Config.VAPIDPrivate = vapidPrivate
+	app.Config.VAPIDPublic = vapidPublic
+
+	// A push service that accepts, has lost, or fails on the endpoint
+	pushService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.WriteHeader(http.StatusCreated)
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer pushService.Close()
+	pushServiceURL, err := url.Parse(pushService.URL)
+	require.NoError(t, err)
+
+	_, adminToken := app.createTestUserAndLogin(t, "+15550010001", "Test Admin", "admin")
+	owl, owlToken := app.createTestUserAndLogin(t, "+15550010002", "Push Owl", "owl")
+
+	subscribe := func(t *testing.T, path, userAgent, label string) {
+		t.Helper()
+		key, err := ecdh.P256().GenerateKey(rand.Reader)
+		require.NoError(t, err)
+		auth := make([]byte, 16)
+		_, err = rand.Read(auth)
+		require.NoError(t, err)
+		body, err := json.Marshal(map[string]string{
+			"endpoint":     pushService.URL + path,
+			"p256dh_key":   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+			"auth_key":     base64.RawURLEncoding.EncodeToString(auth),
+			"user_agent":   userAgent,
+			"platform":     "Linux armv8l",
+			"device_label": label,
+		})
+		require.NoError(t, err)
+		rr := app.makeRequest(t, "POST", "/api/push/subscribe", bytes.NewBuffer(body), owlToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+	}
+	const androidChrome = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36"
+	subscribe(t, "/ok", androidChrome, "")
+	subscribe(t, "/broken", androidChrome, "Work phone")
+	subscribe(t, "/gone", "", "")
+
+	devicesPath := fmt.Sprintf("/api/admin/users/%d/push-devices", owl.UserID)
+	listDevices := func(t *testing.T) map[string]service.PushDevice {
+		t.Helper()
+		rr := app.makeRequest(t, "GET", devicesPath, nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var devices []service.PushDevice
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &devices))
+		byLabel := make(map[string]service.PushDevice, len(devices))
+		for _, device := range devices {
+			byLabel[device.Label] = device
+		}
+		return byLabel
+	}
+	testDevice := func(t *testing.T, deviceID int64) service.PushResult {
+		t.Helper()
+		rr := app.makeRequest(t, "POST", fmt.Sprintf("%s/%d/test", devicesPath, deviceID), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var result service.PushResult
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		return result
+	}
+
+	devices := listDevices(t)
+	require.Len(t, devices, 3)
+	healthy, ok := devices["Chrome on Android"]
+	require.True(t, ok, "the label is derived from the user agent")
+	broken, ok := devices["Work phone"]
+	require.True(t, ok, "a label sent by the app is kept")
+	gone, ok := devices["Linux armv8l"]
+	require.True(t, ok, "the platform labels a device without a user agent")
+	assert.Equal(t, pushServiceURL.Host, healthy.PushService)
+
+	t.Run("a delivered test push is counted", func(t *testing.T) {
+		result := testDevice(t, healthy.ID)
+		assert.True(t, result.Delivered)
+		assert.Equal(t, http.StatusCreated, result.StatusCode)
+
+		device := listDevices(t)["Chrome on Android"]
+		assert.Equal(t, int64(1), device.SuccessCount)
+		assert.NotNil(t, device.LastSuccessAt)
+		assert.True(t, device.Healthy)
+	})
+
+	t.Run("a failed test push is recorded", func(t *testing.T) {
+		result := testDevice(t, broken.ID)
+		assert.False(t, result.Delivered)
+		assert.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		assert.False(t, result.Removed)
+
+		device := listDevices(t)["Work phone"]
+		assert.Equal(t, int64(1), device.FailureCount)
+		assert.Equal(t, int64(1), device.ConsecutiveFailures)
+		assert.NotEmpty(t, device.LastError)
+		assert.False(t, device.Healthy)
+	})
+
+	t.Run("devices the push service has lost are removed", func(t *testing.T) {
+		result := testDevice(t, gone.ID)
+		assert.Equal(t, http.StatusGone, result.StatusCode)
+		assert.True(t, result.Removed)
+		assert.Len(t, listDevices(t), 2)
+	})
+
+	t.Run("devices that keep failing are pruned", func(t *testing.T) {
+		_, err := app.DB.Exec(`UPDATE push_subscriptions SET consecutive_failures = 4, created_at = datetime('now', '-4 days') WHERE id = ?`, broken.ID)
+		require.NoError(t, err)
+		result := testDevice(t, broken.ID)
+		assert.True(t, result.Removed)
+		assert.Len(t, listDevices(t), 1)
+	})
+
+	t.Run("devices of other users are not found", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", fmt.Sprintf("/api/admin/users/%d/push-devices/%d/test", owl.UserID+100, healthy.ID), nil, adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
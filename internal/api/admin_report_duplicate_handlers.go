@@ -0,0 +1,233 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"night-owls-go/internal/service"
+)
+
+// AdminReportDuplicateHandler handles reviewing and merging duplicate reports.
+type AdminReportDuplicateHandler struct {
+	duplicateService *service.ReportDuplicateService
+	auditService     *service.AuditService
+	logger           *slog.Logger
+}
+
+// NewAdminReportDuplicateHandler creates a new AdminReportDuplicateHandler.
+func NewAdminReportDuplicateHandler(duplicateService *service.ReportDuplicateService, auditService *service.AuditService, logger *slog.Logger) *AdminReportDuplicateHandler {
+	return &AdminReportDuplicateHandler{
+		duplicateService: duplicateService,
+		auditService:     auditService,
+		logger:           logger.With("handler", "AdminReportDuplicateHandler"),
+	}
+}
+
+// MergeReportsRequest lists the reports to merge into the primary report
+type MergeReportsRequest struct {
+	ReportIDs []int64 `json:"report_ids"`
+}
+
+// DuplicateReportResponse is a report related to the one being viewed, either
+// detected as a likely duplicate of it or merged into it.
+type DuplicateReportResponse struct {
+	ReportID       int64      `json:"report_id"`
+	UserID         *int64     `json:"user_id,omitempty"`
+	UserName       string     `json:"user_name,omitempty"`
+	BookingID      *int64     `json:"booking_id,omitempty"`
+	Severity       int64      `json:"severity"`
+	Message        string     `json:"message,omitempty"`
+	Latitude       *float64   `json:"latitude,omitempty"`
+	Longitude      *float64   `json:"longitude,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	Score          *float64   `json:"score,omitempty"`
+	DistanceMeters *float64   `json:"distance_meters,omitempty"`
+	MergedAt       *time.Time `json:"merged_at,omitempty"`
+	MergedByUserID *int64     `json:"merged_by_user_id,omitempty"`
+}
+
+// DuplicateMatchResponse is the earlier report this one was detected as likely duplicating
+type DuplicateMatchResponse struct {
+	ReportID       int64      `json:"report_id"`
+	Score          float64    `json:"score"`
+	DistanceMeters *float64   `json:"distance_meters,omitempty"`
+	DetectedAt     *time.Time `json:"detected_at,omitempty"`
+}
+
+// ReportDuplicatesResponse describes a report's place in an incident
+type ReportDuplicatesResponse struct {
+	ReportID            int64                     `json:"report_id"`
+	DuplicateOf         *DuplicateMatchResponse   `json:"duplicate_of,omitempty"`
+	MergedIntoReportID  *int64                    `json:"merged_into_report_id,omitempty"`
+	SuspectedDuplicates []DuplicateReportResponse `json:"suspected_duplicates"`
+	MergedReports       []DuplicateReportResponse `json:"merged_reports"`
+}
+
+func toReportDuplicatesResponse(reportID int64, incident service.ReportIncident) ReportDuplicatesResponse {
+	response := ReportDuplicatesResponse{
+		ReportID:            reportID,
+		SuspectedDuplicates: make([]DuplicateReportResponse, 0, len(incident.SuspectedDuplicates)),
+		MergedReports:       make([]DuplicateReportResponse, 0, len(incident.MergedReports)),
+	}
+	if match := incident.DuplicateOf; match != nil {
+		response.DuplicateOf = &DuplicateMatchResponse{
+			ReportID:       match.DuplicateOfReportID,
+			Score:          match.Score,
+			DistanceMeters: nullFloat64ToPointer(match.DistanceMeters),
+			DetectedAt:     nullTimeToPointer(match.DetectedAt),
+		}
+	}
+	if incident.MergedInto != nil {
+		response.MergedIntoReportID = &incident.MergedInto.PrimaryReportID
+	}
+
+	for _, row := range incident.SuspectedDuplicates {
+		score := row.Score
+		response.SuspectedDuplicates = append(response.SuspectedDuplicates, DuplicateReportResponse{
+			ReportID:       row.ReportID,
+			UserID:         nullInt64ToPointer(row.UserID),
+			UserName:       row.UserName,
+			Severity:       row.Severity,
+			Message:        row.Message.String,
+			CreatedAt:      nullTimeToPointer(row.CreatedAt),
+			Score:          &score,
+			DistanceMeters: nullFloat64ToPointer(row.DistanceMeters),
+		})
+	}
+	for _, row := range incident.MergedReports {
+		response.MergedReports = append(response.MergedReports, DuplicateReportResponse{
+			ReportID:       row.ReportID,
+			UserID:         nullInt64ToPointer(row.UserID),
+			UserName:       row.UserName,
+			BookingID:      nullInt64ToPointer(row.BookingID),
+			Severity:       row.Severity,
+			Message:        row.Message.String,
+			Latitude:       nullFloat64ToPointer(row.Latitude),
+			Longitude:      nullFloat64ToPointer(row.Longitude),
+			CreatedAt:      nullTimeToPointer(row.CreatedAt),
+			MergedAt:       nullTimeToPointer(row.MergedAt),
+			MergedByUserID: nullInt64ToPointer(row.MergedByUserID),
+		})
+	}
+	return response
+}
+
+func (h *AdminReportDuplicateHandler) respondWithDuplicateError(w http.ResponseWriter, err error, reportID int64) {
+	switch {
+	case errors.Is(err, service.ErrReportNotFound):
+		RespondWithError(w, http.StatusNotFound, "Report not found", h.logger, "report_id", reportID)
+	case errors.Is(err, service.ErrNoReportsToMerge), errors.Is(err, service.ErrMergeIntoSelf):
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger, "report_id", reportID)
+	case errors.Is(err, service.ErrReportAlreadyMerged):
+		RespondWithError(w, http.StatusConflict, err.Error(), h.logger, "report_id", reportID)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to process duplicate reports", h.logger, "error", err.Error())
+	}
+}
+
+// AdminGetReportDuplicatesHandler handles GET /api/admin/reports/{id}/duplicates
+// @Summary Get a report's duplicates (Admin)
+// @Description Returns the earlier report this one likely duplicates, the reports detected as likely duplicates of it, and the reports merged into it with their messages and reporters
+// @Tags admin/reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} ReportDuplicatesResponse "Duplicate and merged reports"
+// @Failure 400 {object} ErrorResponse "Invalid report ID"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/reports/{id}/duplicates [get]
+func (h *AdminReportDuplicateHandler) AdminGetReportDuplicatesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid report ID", h.logger)
+		return
+	}
+
+	incident, err := h.duplicateService.GetIncident(r.Context(), id)
+	if err != nil {
+		h.respondWithDuplicateError(w, err, id)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, toReportDuplicatesResponse(id, incident), h.logger)
+}
+
+// AdminMergeReportsHandler handles POST /api/admin/reports/{id}/merge
+// @Summary Merge duplicate reports into a report (Admin)
+// @Description Merges the listed reports into this primary report. Merged reports are archived and their photos moved to the primary; their messages and reporters stay listed under the primary's merged reports. Reporters of merged serious reports lose the serious report bonus, so the incident only earns it once.
+// @Tags admin/reports
+// @Accept json
+// @Produce json
+// @Param id path int true "Primary report ID"
+// @Param request body MergeReportsRequest true "Reports to merge"
+// @Success 200 {object} service.MergeResult "Reports merged"
+// @Failure 400 {object} ErrorResponse "Invalid report ID or no reports to merge"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 409 {object} ErrorResponse "A report has already been merged"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/reports/{id}/merge [post]
+func (h *AdminReportDuplicateHandler) AdminMergeReportsHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid report ID", h.logger)
+		return
+	}
+
+	var req MergeReportsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	result, err := h.duplicateService.MergeReports(r.Context(), id, req.ReportIDs, adminID)
+	if err != nil {
+		h.respondWithDuplicateError(w, err, id)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	auditErr := h.auditService.LogReportsMerged(
+		r.Context(),
+		adminID,
+		id,
+		result.MergedReportIDs,
+		result.PhotosMoved,
+		result.PointsRevoked,
+		ipAddress,
+		userAgent,
+	)
+	if auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log report merge audit event", "report_id", id, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusOK, result, h.logger)
+}
+
+// nullInt64ToPointer converts sql.NullInt64 to *int64
+func nullInt64ToPointer(ni sql.NullInt64) *int64 {
+	if ni.Valid {
+		return &ni.Int64
+	}
+	return nil
+}
+
+// nullFloat64ToPointer converts sql.NullFloat64 to *float64
+func nullFloat64ToPointer(nf sql.NullFloat64) *float64 {
+	if nf.Valid {
+		return &nf.Float64
+	}
+	return nil
+}
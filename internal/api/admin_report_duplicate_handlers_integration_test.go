@@ -0,0 +1,156 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportDuplicates_DetectAndMerge(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	_, adminToken := app.createTestUserAndLogin(t, "+15550003601", "Test Admin", "admin")
+
+	schedule, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+		Name:            "Duplicate Patrol",
+		CronExpr:        "0 0 * * *",
+		DurationMinutes: 120,
+		Timezone:        sql.NullString{String: "UTC", Valid: true},
+	})
+	require.NoError(t, err)
+
+	// Each owl gets their own slot since a schedule holds one booking per shift start
+	shiftStart := time.Now().UTC().Add(-time.Hour)
+	fileReport := func(phone, name, message string, lat, lon float64) (int64, int64, int64) {
+		owl, _ := app.createTestUserAndLogin(t, phone, name, "owl")
+		shiftStart = shiftStart.Add(-time.Minute)
+		booking, err := app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+			UserID:     owl.UserID,
+			ScheduleID: schedule.ScheduleID,
+			ShiftStart: shiftStart,
+			ShiftEnd:   shiftStart.Add(2 * time.Hour),
+		})
+		require.NoError(t, err)
+		report, err := app.ReportService.CreateReport(ctx, owl.UserID, booking.BookingID, 2, message, &service.GPSLocation{Latitude: &lat, Longitude: &lon}, nil)
+		require.NoError(t, err)
+		return report.ReportID, owl.UserID, booking.BookingID
+	}
+	level2Bonuses := func(userID, bookingID int64) int64 {
+		count, err := app.Querier.CountUserBookingPointsByReason(ctx, db.CountUserBookingPointsByReasonParams{
+			UserID:    userID,
+			BookingID: sql.NullInt64{Int64: bookingID, Valid: true},
+			Reason:    string(service.ReasonLevel2Report),
+		})
+		require.NoError(t, err)
+		return count
+	}
+	getDuplicates := func(reportID int64) api.ReportDuplicatesResponse {
+		rr := app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/reports/%d/duplicates", reportID), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var response api.ReportDuplicatesResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		return response
+	}
+	merge := func(primaryID int64, reportIDs ...int64) (int, []byte) {
+		body, err := json.Marshal(api.MergeReportsRequest{ReportIDs: reportIDs})
+		require.NoError(t, err)
+		rr := app.makeRequest(t, "POST", fmt.Sprintf("/api/admin/reports/%d/merge", primaryID), bytes.NewReader(body), adminToken)
+		return rr.Code, rr.Body.Bytes()
+	}
+
+	firstID, firstOwl, firstBooking := fileReport("+15550003602", "First Owl", "Suspicious white van parked outside number 12", -33.9249, 18.4241)
+	secondID, secondOwl, secondBooking := fileReport("+15550003603", "Second Owl", "White van parked outside no 12, looks suspicious", -33.9253, 18.4243)
+	otherID, otherOwl, otherBooking := fileReport("+15550003604", "Other Owl", "Burst water pipe on Main Road", -33.9400, 18.4400)
+
+	t.Run("nearby reports of the same incident are detected", func(t *testing.T) {
+		first := getDuplicates(firstID)
+		assert.Nil(t, first.DuplicateOf)
+		require.Len(t, first.SuspectedDuplicates, 1)
+		assert.Equal(t, secondID, first.SuspectedDuplicates[0].ReportID)
+		assert.Equal(t, "Second Owl", first.SuspectedDuplicates[0].UserName)
+		require.NotNil(t, first.SuspectedDuplicates[0].DistanceMeters)
+		assert.Less(t, *first.SuspectedDuplicates[0].DistanceMeters, 100.0)
+
+		second := getDuplicates(secondID)
+		require.NotNil(t, second.DuplicateOf)
+		assert.Equal(t, firstID, second.DuplicateOf.ReportID)
+		assert.GreaterOrEqual(t, second.DuplicateOf.Score, service.DuplicateScoreThreshold)
+
+		assert.Nil(t, getDuplicates(otherID).DuplicateOf, "a report 2km away is a different incident")
+	})
+
+	t.Run("only the first serious report earns the bonus", func(t *testing.T) {
+		assert.EqualValues(t, 1, level2Bonuses(firstOwl, firstBooking))
+		assert.EqualValues(t, 0, level2Bonuses(secondOwl, secondBooking))
+		assert.EqualValues(t, 1, level2Bonuses(otherOwl, otherBooking))
+	})
+
+	_, err = app.Querier.CreateReportPhoto(ctx, db.CreateReportPhotoParams{
+		ReportID:       secondID,
+		Filename:       "van.jpg",
+		FileSizeBytes:  4,
+		MimeType:       "image/jpeg",
+		StoragePath:    "/tmp/van.jpg",
+		ChecksumSha256: "abc",
+	})
+	require.NoError(t, err)
+
+	t.Run("merging keeps photos, messages and reporters on the primary", func(t *testing.T) {
+		code, body := merge(firstID, secondID, otherID)
+		require.Equal(t, http.StatusOK, code, "Response: %s", body)
+		var result service.MergeResult
+		require.NoError(t, json.Unmarshal(body, &result))
+		assert.Equal(t, []int64{secondID, otherID}, result.MergedReportIDs)
+		assert.EqualValues(t, 1, result.PhotosMoved)
+		assert.Equal(t, []int64{otherOwl}, result.PointsRevoked, "only the merged report that earned the bonus loses it")
+
+		photos, err := app.Querier.GetReportPhotos(ctx, firstID)
+		require.NoError(t, err)
+		assert.Len(t, photos, 1)
+
+		first := getDuplicates(firstID)
+		assert.Empty(t, first.SuspectedDuplicates)
+		require.Len(t, first.MergedReports, 2)
+		assert.Equal(t, "White van parked outside no 12, looks suspicious", first.MergedReports[0].Message)
+		assert.Equal(t, "Other Owl", first.MergedReports[1].UserName)
+
+		second := getDuplicates(secondID)
+		require.NotNil(t, second.MergedIntoReportID)
+		assert.Equal(t, firstID, *second.MergedIntoReportID)
+
+		merged, err := app.Querier.AdminGetReportWithContext(ctx, secondID)
+		require.NoError(t, err)
+		assert.True(t, merged.ArchivedAt.Valid, "merged reports are archived")
+
+		var auditCount int
+		require.NoError(t, app.DB.QueryRow(`SELECT COUNT(*) FROM audit_events WHERE event_type = 'report.merged'`).Scan(&auditCount))
+		assert.Equal(t, 1, auditCount)
+	})
+
+	t.Run("invalid merges are rejected", func(t *testing.T) {
+		code, _ := merge(firstID, secondID)
+		assert.Equal(t, http.StatusConflict, code)
+		code, _ = merge(secondID, firstID)
+		assert.Equal(t, http.StatusConflict, code)
+		code, _ = merge(firstID, firstID)
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = merge(firstID)
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = merge(firstID, 999999)
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
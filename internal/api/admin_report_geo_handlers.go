@@ -0,0 +1,223 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"night-owls-go/internal/service"
+)
+
+// AdminReportGeoHandler handles geospatial views and exports of reports for admins.
+type AdminReportGeoHandler struct {
+	geoService    *service.ReportGeoService
+	exportService *service.ReportExportService
+	logger        *slog.Logger
+}
+
+// NewAdminReportGeoHandler creates a new AdminReportGeoHandler.
+func NewAdminReportGeoHandler(geoService *service.ReportGeoService, exportService *service.ReportExportService, logger *slog.Logger) *AdminReportGeoHandler {
+	return &AdminReportGeoHandler{
+		geoService:    geoService,
+		exportService: exportService,
+		logger:        logger.With("handler", "AdminReportGeoHandler"),
+	}
+}
+
+// parseReportFilterTime accepts either a date (YYYY-MM-DD) or an RFC3339 timestamp.
+// Dates used as an upper bound are inclusive, so they are moved to the start of the next day.
+func parseReportFilterTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// parseReportFilterInt parses an optional integer query parameter.
+func parseReportFilterInt(value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// parseAdminReportFilter reads the from/to/severity/schedule_id/user_id query
+// parameters shared by the admin report endpoints.
+func parseAdminReportFilter(r *http.Request) (service.ReportFilter, error) {
+	var filter service.ReportFilter
+	var err error
+	q := r.URL.Query()
+
+	if filter.From, err = parseReportFilterTime(q.Get("from"), false); err != nil {
+		return filter, errors.New("invalid from: use YYYY-MM-DD or RFC3339")
+	}
+	if filter.To, err = parseReportFilterTime(q.Get("to"), true); err != nil {
+		return filter, errors.New("invalid to: use YYYY-MM-DD or RFC3339")
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, errors.New("from must be before to")
+	}
+	if filter.Severity, err = parseReportFilterInt(q.Get("severity")); err != nil {
+		return filter, errors.New("invalid severity")
+	}
+	if filter.Severity != nil && (*filter.Severity < 0 || *filter.Severity > 2) {
+		return filter, errors.New("severity must be between 0 and 2")
+	}
+	if filter.ScheduleID, err = parseReportFilterInt(q.Get("schedule_id")); err != nil {
+		return filter, errors.New("invalid schedule_id")
+	}
+	if filter.UserID, err = parseReportFilterInt(q.Get("user_id")); err != nil {
+		return filter, errors.New("invalid user_id")
+	}
+	if filter.CategoryID, err = parseReportFilterInt(q.Get("category_id")); err != nil {
+		return filter, errors.New("invalid category_id")
+	}
+
+	return filter, nil
+}
+
+// AdminReportHeatmapHandler handles GET /api/admin/reports/heatmap
+// @Summary Report heatmap (Admin)
+// @Description Bin located reports into grid or geohash cells and return a GeoJSON FeatureCollection with per-cell counts and report IDs for drill-down
+// @Tags admin/reports
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD or RFC3339)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD or RFC3339)"
+// @Param severity query int false "Filter by severity (0=info, 1=warning, 2=critical)"
+// @Param schedule_id query int false "Filter by schedule ID (0 for off-shift reports)"
+// @Param user_id query int false "Filter by reporter user ID"
+// @Param category_id query int false "Filter by incident category ID"
+// @Param binning query string false "Binning method: grid (default) or geohash"
+// @Param cell_size query number false "Grid cell size in metres (default 250)"
+// @Param precision query int false "Geohash length (default 7)"
+// @Success 200 {object} service.GeoJSONFeatureCollection "GeoJSON FeatureCollection of cells"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/reports/heatmap [get]
+func (h *AdminReportGeoHandler) AdminReportHeatmapHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAdminReportFilter(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+
+	q := r.URL.Query()
+	opts := service.HeatmapOptions{Binning: q.Get("binning")}
+	if cellSize := q.Get("cell_size"); cellSize != "" {
+		opts.CellSizeMeters, err = strconv.ParseFloat(cellSize, 64)
+		if err != nil {
+			RespondWithError(w, http.StatusBadRequest, "Invalid cell_size", h.logger, "cell_size", cellSize)
+			return
+		}
+	}
+	if precision := q.Get("precision"); precision != "" {
+		opts.GeohashLength, err = strconv.Atoi(precision)
+		if err != nil {
+			RespondWithError(w, http.StatusBadRequest, "Invalid precision", h.logger, "precision", precision)
+			return
+		}
+	}
+
+	collection, err := h.geoService.BuildHeatmap(r.Context(), filter, opts)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidHeatmapBinning):
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		case errors.Is(err, service.ErrInvalidHeatmapResolution):
+			RespondWithError(w, http.StatusBadRequest,
+				fmt.Sprintf("cell_size must be %.0f-%.0f metres and precision %d-%d",
+					service.MinHeatmapCellSizeMeters, service.MaxHeatmapCellSizeMeters,
+					service.MinHeatmapGeohashLength, service.MaxHeatmapGeohashLength), h.logger)
+		default:
+			RespondWithError(w, http.StatusInternalServerError, "Failed to build report heatmap", h.logger, "error", err)
+		}
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, collection, h.logger)
+}
+
+// AdminExportReportsHandler handles GET /api/admin/reports/export
+// @Summary Export reports as GeoJSON or KML (Admin)
+// @Description Download the reports matching the admin report list filters. Reporter names are masked. Every export is recorded in the audit trail.
+// @Tags admin/reports
+// @Produce application/geo+json
+// @Produce application/vnd.google-earth.kml+xml
+// @Param format query string false "Export format: geojson (default) or kml"
+// @Param from query string false "Start date (YYYY-MM-DD or RFC3339)"
+// @Param to query string false "End date, inclusive (YYYY-MM-DD or RFC3339)"
+// @Param severity query int false "Filter by severity (0=info, 1=warning, 2=critical)"
+// @Param schedule_id query int false "Filter by schedule ID (0 for off-shift reports)"
+// @Param user_id query int false "Filter by reporter user ID"
+// @Param category_id query int false "Filter by incident category ID"
+// @Success 200 {file} file "Export file"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/reports/export [get]
+func (h *AdminReportGeoHandler) AdminExportReportsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	filter, err := parseAdminReportFilter(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = service.ReportExportFormatGeoJSON
+	}
+
+	var contentType string
+	switch format {
+	case service.ReportExportFormatGeoJSON:
+		contentType = "application/geo+json"
+	case service.ReportExportFormatKML:
+		contentType = "application/vnd.google-earth.kml+xml"
+	default:
+		RespondWithError(w, http.StatusBadRequest, "format must be 'geojson' or 'kml'", h.logger, "format", format)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	export, count, err := h.exportService.ExportReports(r.Context(), format, filter, userID, ipAddress, userAgent)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to export reports", h.logger, "error", err)
+		return
+	}
+
+	// The export is complete and audited, so from here on errors can only be logged
+	filename := fmt.Sprintf("night-owls-reports-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(export); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to write report export", "format", format, "admin_user_id", userID, "error", err)
+		return
+	}
+
+	h.logger.InfoContext(r.Context(), "Reports exported", "format", format, "report_count", count, "admin_user_id", userID)
+}
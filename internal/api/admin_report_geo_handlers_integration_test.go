@@ -0,0 +1,238 @@
+package api_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminReportGeoHandlers_Heatmap(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	_, adminToken := app.createTestUserAndLogin(t, "+15550002001", "Test Admin", "admin")
+
+	ctx := context.Background()
+	reporter, err := app.Querier.CreateUser(ctx, db.CreateUserParams{
+		Phone: "+15550002002",
+		Name:  sql.NullString{String: "Reporter Owl", Valid: true},
+		Role:  sql.NullString{String: "owl", Valid: true},
+	})
+	require.NoError(t, err)
+
+	createLocatedReport := func(severity int64, lat, lon float64) int64 {
+		report, err := app.Querier.CreateReport(ctx, db.CreateReportParams{
+			UserID:    newNullInt64(reporter.UserID),
+			Severity:  severity,
+			Message:   newNullString("Located report"),
+			Latitude:  sql.NullFloat64{Float64: lat, Valid: true},
+			Longitude: sql.NullFloat64{Float64: lon, Valid: true},
+		})
+		require.NoError(t, err)
+		return report.ReportID
+	}
+
+	// Two reports a few metres apart and one about 2km away
+	nearA := createLocatedReport(0, -33.92490, 18.42410)
+	nearB := createLocatedReport(2, -33.92495, 18.42415)
+	far := createLocatedReport(1, -33.94300, 18.42410)
+
+	// Report without GPS is counted but not binned
+	_, err = app.Querier.CreateReport(ctx, db.CreateReportParams{
+		UserID:   newNullInt64(reporter.UserID),
+		Severity: 0,
+		Message:  newNullString("No location"),
+	})
+	require.NoError(t, err)
+
+	t.Run("grid binning", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/reports/heatmap?cell_size=100", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var collection service.GeoJSONFeatureCollection
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &collection))
+
+		assert.Equal(t, "FeatureCollection", collection.Type)
+		require.Len(t, collection.Features, 2)
+		assert.EqualValues(t, 4, collection.Metadata["total_reports"])
+		assert.EqualValues(t, 1, collection.Metadata["unlocated_reports"])
+
+		busiest := collection.Features[0]
+		assert.Equal(t, "Point", busiest.Geometry.Type)
+		assert.EqualValues(t, 2, busiest.Properties["count"])
+		assert.EqualValues(t, 2, busiest.Properties["max_severity"])
+		assert.ElementsMatch(t, []interface{}{float64(nearA), float64(nearB)}, busiest.Properties["report_ids"])
+
+		assert.ElementsMatch(t, []interface{}{float64(far)}, collection.Features[1].Properties["report_ids"])
+	})
+
+	t.Run("geohash binning with severity filter", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/reports/heatmap?binning=geohash&precision=5&severity=2", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var collection service.GeoJSONFeatureCollection
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &collection))
+
+		require.Len(t, collection.Features, 1)
+		assert.Len(t, collection.Features[0].Properties["cell"], 5)
+		assert.ElementsMatch(t, []interface{}{float64(nearB)}, collection.Features[0].Properties["report_ids"])
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"binning=hexagon", "cell_size=1", "precision=20&binning=geohash", "severity=5", "from=yesterday"} {
+			rr := app.makeRequest(t, "GET", "/api/admin/reports/heatmap?"+query, nil, adminToken)
+			assert.Equal(t, http.StatusBadRequest, rr.Code, "query %s: %s", query, rr.Body.String())
+		}
+	})
+}
+
+func TestAdminReportGeoHandlers_Export(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	admin, adminToken := app.createTestUserAndLogin(t, "+15550002101", "Test Admin", "admin")
+
+	ctx := context.Background()
+	reporter, err := app.Querier.CreateUser(ctx, db.CreateUserParams{
+		Phone: "+15550002102",
+		Name:  sql.NullString{String: "Jane Reporter", Valid: true},
+		Role:  sql.NullString{String: "owl", Valid: true},
+	})
+	require.NoError(t, err)
+
+	located, err := app.Querier.CreateReport(ctx, db.CreateReportParams{
+		UserID:    newNullInt64(reporter.UserID),
+		Severity:  2,
+		Message:   newNullString("Suspicious <vehicle> & driver"),
+		Latitude:  sql.NullFloat64{Float64: -33.9249, Valid: true},
+		Longitude: sql.NullFloat64{Float64: 18.4241, Valid: true},
+	})
+	require.NoError(t, err)
+
+	unlocated, err := app.Querier.CreateReport(ctx, db.CreateReportParams{
+		UserID:   newNullInt64(reporter.UserID),
+		Severity: 0,
+		Message:  newNullString("All quiet"),
+	})
+	require.NoError(t, err)
+
+	t.Run("geojson", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/reports/export", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		assert.Equal(t, "application/geo+json", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), ".geojson")
+
+		var collection service.GeoJSONFeatureCollection
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &collection))
+		require.Len(t, collection.Features, 2)
+
+		for _, feature := range collection.Features {
+			assert.Equal(t, "Jane R.", feature.Properties["reporter"])
+			assert.NotContains(t, feature.Properties, "message")
+			switch int64(feature.Properties["report_id"].(float64)) {
+			case located.ReportID:
+				require.NotNil(t, feature.Geometry)
+				assert.Equal(t, []interface{}{18.4241, -33.9249}, feature.Geometry.Coordinates)
+				assert.Equal(t, "Incident", feature.Properties["severity_label"])
+			case unlocated.ReportID:
+				assert.Nil(t, feature.Geometry)
+			}
+		}
+	})
+
+	t.Run("kml with severity filter", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/reports/export?format=kml&severity=2", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		assert.Equal(t, "application/vnd.google-earth.kml+xml", rr.Header().Get("Content-Type"))
+
+		body := rr.Body.String()
+		assert.Equal(t, 1, strings.Count(body, "<Placemark>"))
+		assert.Contains(t, body, "<coordinates>18.424100,-33.924900</coordinates>")
+		assert.Contains(t, body, "<value>Jane R.</value>")
+		assert.NotContains(t, body, "Reporter")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/reports/export?format=shp", nil, adminToken)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Empty(t, rr.Header().Get("Content-Disposition"))
+	})
+
+	t.Run("exports are audited", func(t *testing.T) {
+		events, err := app.Querier.ListAuditEventsByType(ctx, db.ListAuditEventsByTypeParams{
+			EventType: "report.exported",
+			Limit:     10,
+		})
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+
+		formats := []string{}
+		for _, event := range events {
+			assert.Equal(t, admin.UserID, event.ActorUserID.Int64)
+
+			var details map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(event.Details.String), &details))
+			formats = append(formats, details["format"].(string))
+			if details["format"] == "kml" {
+				assert.Equal(t, map[string]interface{}{"severity": float64(2)}, details["filters"])
+				assert.EqualValues(t, 1, details["report_count"])
+			}
+		}
+		assert.ElementsMatch(t, []string{"geojson", "kml"}, formats)
+	})
+}
+
+func TestAdminReportHandlers_ListReports_Filters(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	_, adminToken := app.createTestUserAndLogin(t, "+15550002201", "Test Admin", "admin")
+
+	ctx := context.Background()
+	reporter, err := app.Querier.CreateUser(ctx, db.CreateUserParams{
+		Phone: "+15550002202",
+		Name:  sql.NullString{String: "Filter Owl", Valid: true},
+		Role:  sql.NullString{String: "owl", Valid: true},
+	})
+	require.NoError(t, err)
+
+	for _, severity := range []int64{0, 1, 2, 2} {
+		_, err := app.Querier.CreateReport(ctx, db.CreateReportParams{
+			UserID:   newNullInt64(reporter.UserID),
+			Severity: severity,
+			Message:  newNullString("Filter test"),
+		})
+		require.NoError(t, err)
+	}
+
+	rr := app.makeRequest(t, "GET", "/api/admin/reports?severity=2", nil, adminToken)
+	require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+	var reports []api.AdminReportResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &reports))
+	require.Len(t, reports, 2)
+	for _, report := range reports {
+		assert.Equal(t, int64(2), report.Severity)
+	}
+
+	// Reports created today fall outside a window that ended yesterday
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+	rr = app.makeRequest(t, "GET", "/api/admin/reports?to="+yesterday, nil, adminToken)
+	require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &reports))
+	assert.Empty(t, reports)
+
+	rr = app.makeRequest(t, "GET", "/api/admin/reports?schedule_id=abc", nil, adminToken)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
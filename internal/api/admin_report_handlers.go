@@ -1,6 +1,8 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -17,16 +19,18 @@ import (
 type AdminReportHandler struct {
 	reportService   *service.ReportService
 	scheduleService *service.ScheduleService
+	handoverService *service.HandoverService
 	querier         db.Querier
 	auditService    *service.AuditService
 	logger          *slog.Logger
 }
 
 // NewAdminReportHandler creates a new AdminReportHandler.
-func NewAdminReportHandler(reportService *service.ReportService, scheduleService *service.ScheduleService, querier db.Querier, auditService *service.AuditService, logger *slog.Logger) *AdminReportHandler {
+func NewAdminReportHandler(reportService *service.ReportService, scheduleService *service.ScheduleService, handoverService *service.HandoverService, querier db.Querier, auditService *service.AuditService, logger *slog.Logger) *AdminReportHandler {
 	return &AdminReportHandler{
 		reportService:   reportService,
 		scheduleService: scheduleService,
+		handoverService: handoverService,
 		querier:         querier,
 		auditService:    auditService,
 		logger:          logger.With("handler", "AdminReportHandler"),
@@ -52,6 +56,53 @@ type AdminReportResponse struct {
 	Longitude    *float64   `json:"longitude,omitempty"`
 	GPSAccuracy  *float64   `json:"gps_accuracy,omitempty"`
 	GPSTimestamp *time.Time `json:"gps_timestamp,omitempty"`
+
+	CategoryID     *int64                 `json:"category_id,omitempty"`
+	CategoryName   string                 `json:"category_name,omitempty"`
+	CategoryFields map[string]interface{} `json:"category_fields,omitempty"`
+
+	// Handover notes, only included when fetching a single report
+	HandoverNote          *HandoverNoteResponse  `json:"handover_note,omitempty"`           // Left by this patrol
+	ReceivedHandoverNotes []HandoverNoteResponse `json:"received_handover_notes,omitempty"` // Left for this patrol by earlier ones
+
+	// Spam or false alarm flag, only included when fetching a single report
+	Flag *ReportFlagResponse `json:"flag,omitempty"`
+}
+
+// FlagReportRequest is the admin's verdict on a report
+type FlagReportRequest struct {
+	Flag string `json:"flag"` // "spam" or "false_alarm"
+	Note string `json:"note,omitempty"`
+}
+
+// ReportFlagResponse is a report's flag and the reporter's resulting trust score
+type ReportFlagResponse struct {
+	ReportID        int64                  `json:"report_id"`
+	Flag            string                 `json:"flag"`
+	Note            string                 `json:"note,omitempty"`
+	FlaggedByUserID *int64                 `json:"flagged_by_user_id,omitempty"`
+	FlaggedAt       *time.Time             `json:"flagged_at,omitempty"`
+	ReporterUserID  *int64                 `json:"reporter_user_id,omitempty"`
+	ReporterTrust   *service.ReporterTrust `json:"reporter_trust,omitempty"`
+}
+
+func toReportFlagResponse(flag db.ReportFlag, trust *service.ReporterTrust) ReportFlagResponse {
+	response := ReportFlagResponse{
+		ReportID:      flag.ReportID,
+		Flag:          flag.Flag,
+		Note:          flag.Note.String,
+		ReporterTrust: trust,
+	}
+	if flag.FlaggedByUserID.Valid {
+		response.FlaggedByUserID = &flag.FlaggedByUserID.Int64
+	}
+	if flag.FlaggedAt.Valid {
+		response.FlaggedAt = &flag.FlaggedAt.Time
+	}
+	if flag.ReporterUserID.Valid {
+		response.ReporterUserID = &flag.ReporterUserID.Int64
+	}
+	return response
 }
 
 // AdminListReportsHandler handles GET /api/admin/reports
@@ -64,13 +115,19 @@ type AdminReportResponse struct {
 // @Param severity query int false "Filter by severity (0=info, 1=warning, 2=critical)"
 // @Param schedule_id query int false "Filter by schedule ID"
 // @Param user_id query int false "Filter by user ID"
+// @Param category_id query int false "Filter by incident category ID"
 // @Success 200 {array} AdminReportResponse "List of reports with full context"
 // @Failure 400 {object} ErrorResponse "Invalid query parameters"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Router /api/admin/reports [get]
 func (h *AdminReportHandler) AdminListReportsHandler(w http.ResponseWriter, r *http.Request) {
-	// For now, get all reports. Filtering can be added later with additional SQL queries
+	filter, err := parseAdminReportFilter(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+
 	reports, err := h.querier.AdminListReportsWithContext(r.Context())
 	if err != nil {
 		h.logger.Error("Failed to fetch reports with context", "error", err)
@@ -81,6 +138,10 @@ func (h *AdminReportHandler) AdminListReportsHandler(w http.ResponseWriter, r *h
 	// Convert to API response format
 	apiReports := make([]AdminReportResponse, 0, len(reports))
 	for _, report := range reports {
+		if !filter.Matches(report) {
+			continue
+		}
+
 		apiReport := AdminReportResponse{
 			ReportID:     report.ReportID,
 			Severity:     report.Severity,
@@ -88,8 +149,15 @@ func (h *AdminReportHandler) AdminListReportsHandler(w http.ResponseWriter, r *h
 			UserPhone:    report.UserPhone,
 			ScheduleID:   report.ScheduleID,
 			ScheduleName: report.ScheduleName,
+			CategoryName: report.CategoryName,
 		}
 
+		// Handle nullable category fields
+		if report.CategoryID.Valid {
+			apiReport.CategoryID = &report.CategoryID.Int64
+		}
+		apiReport.CategoryFields = ToCategoryFieldValues(report.CategoryFields)
+
 		// Handle nullable BookingID field
 		if report.BookingID.Valid {
 			apiReport.BookingID = report.BookingID.Int64
@@ -151,7 +219,7 @@ func (h *AdminReportHandler) AdminListReportsHandler(w http.ResponseWriter, r *h
 
 // AdminGetReportHandler handles GET /api/admin/reports/{id}
 // @Summary Get a specific report (Admin)
-// @Description Get a specific report with full context by ID
+// @Description Get a specific report with full context by ID, including the handover note left by the patrol, the notes handed over to it and any spam or false alarm flag
 // @Tags admin/reports
 // @Produce json
 // @Param id path int true "Report ID"
@@ -201,7 +269,14 @@ func (h *AdminReportHandler) AdminGetReportHandler(w http.ResponseWriter, r *htt
 		UserPhone:    report.UserPhone,
 		ScheduleID:   report.ScheduleID,
 		ScheduleName: report.ScheduleName,
+		CategoryName: report.CategoryName,
+	}
+
+	// Handle nullable category fields
+	if report.CategoryID.Valid {
+		apiReport.CategoryID = &report.CategoryID.Int64
 	}
+	apiReport.CategoryFields = ToCategoryFieldValues(report.CategoryFields)
 
 	// Handle nullable BookingID field
 	if report.BookingID.Valid {
@@ -256,6 +331,31 @@ func (h *AdminReportHandler) AdminGetReportHandler(w http.ResponseWriter, r *htt
 		apiReport.GPSTimestamp = &report.GpsTimestamp.Time
 	}
 
+	// Handover notes are context only, so a failure here doesn't fail the request
+	if report.BookingID.Valid && h.handoverService != nil {
+		left, received, err := h.handoverService.GetBookingHandovers(r.Context(), report.BookingID.Int64)
+		if err != nil {
+			h.logger.WarnContext(r.Context(), "Failed to get handover notes for report", "report_id", id, "booking_id", report.BookingID.Int64, "error", err)
+		} else {
+			if left != nil {
+				note := toHandoverNoteResponse(*left)
+				apiReport.HandoverNote = &note
+			}
+			if len(received) > 0 {
+				apiReport.ReceivedHandoverNotes = toReceivedHandoverNoteResponses(received)
+			}
+		}
+	}
+
+	// The flag is context only as well
+	flag, err := h.reportService.GetReportFlag(r.Context(), id)
+	if err == nil {
+		flagResponse := toReportFlagResponse(flag, nil)
+		apiReport.Flag = &flagResponse
+	} else if !errors.Is(err, service.ErrReportFlagNotFound) {
+		h.logger.WarnContext(r.Context(), "Failed to get flag for report", "report_id", id, "error", err)
+	}
+
 	RespondWithJSON(w, http.StatusOK, apiReport, h.logger)
 }
 
@@ -413,8 +513,15 @@ func (h *AdminReportHandler) AdminListArchivedReportsHandler(w http.ResponseWrit
 			UserPhone:    report.UserPhone,
 			ScheduleID:   report.ScheduleID,
 			ScheduleName: report.ScheduleName,
+			CategoryName: report.CategoryName,
 		}
 
+		// Handle nullable category fields
+		if report.CategoryID.Valid {
+			apiReport.CategoryID = &report.CategoryID.Int64
+		}
+		apiReport.CategoryFields = ToCategoryFieldValues(report.CategoryFields)
+
 		// Handle nullable BookingID field
 		if report.BookingID.Valid {
 			apiReport.BookingID = report.BookingID.Int64
@@ -551,3 +658,162 @@ func (h *AdminReportHandler) AdminDeleteReportHandler(w http.ResponseWriter, r *
 
 	RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Report deleted successfully"}, h.logger)
 }
+
+func (h *AdminReportHandler) respondWithReportFlagError(w http.ResponseWriter, err error, reportID int64) {
+	switch {
+	case errors.Is(err, service.ErrInvalidReportFlag):
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger, "report_id", reportID)
+	case errors.Is(err, service.ErrReportNotFound):
+		RespondWithError(w, http.StatusNotFound, "Report not found", h.logger, "report_id", reportID)
+	case errors.Is(err, service.ErrReportFlagNotFound):
+		RespondWithError(w, http.StatusNotFound, "Report is not flagged", h.logger, "report_id", reportID)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update report flag", h.logger, "error", err.Error())
+	}
+}
+
+// AdminFlagReportHandler handles PUT /api/admin/reports/{id}/flag
+// @Summary Flag a report as spam or a false alarm (Admin)
+// @Description Flags a report as spam or a false alarm, replacing any earlier flag. Flags lower the reporter's trust score, and reporters whose score falls too low can no longer file off-shift reports.
+// @Tags admin/reports
+// @Accept json
+// @Produce json
+// @Param id path int true "Report ID"
+// @Param request body FlagReportRequest true "Flag"
+// @Success 200 {object} ReportFlagResponse "Report flagged"
+// @Failure 400 {object} ErrorResponse "Invalid report ID or flag"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/reports/{id}/flag [put]
+func (h *AdminReportHandler) AdminFlagReportHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid report ID", h.logger)
+		return
+	}
+
+	var req FlagReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	flag, trust, err := h.reportService.FlagReport(r.Context(), id, adminID, req.Flag, req.Note)
+	if err != nil {
+		h.respondWithReportFlagError(w, err, id)
+		return
+	}
+
+	response := toReportFlagResponse(flag, trust)
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	auditErr := h.auditService.LogReportFlagged(
+		r.Context(),
+		adminID,
+		id,
+		response.ReporterUserID,
+		flag.Flag,
+		flag.Note.String,
+		trust,
+		ipAddress,
+		userAgent,
+	)
+	if auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log report flag audit event", "report_id", id, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminUnflagReportHandler handles DELETE /api/admin/reports/{id}/flag
+// @Summary Clear a report's flag (Admin)
+// @Description Removes a spam or false alarm flag from a report, restoring the reporter's trust score
+// @Tags admin/reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} ReportFlagResponse "The removed flag and the reporter's updated trust score"
+// @Failure 400 {object} ErrorResponse "Invalid report ID"
+// @Failure 404 {object} ErrorResponse "Report is not flagged"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/reports/{id}/flag [delete]
+func (h *AdminReportHandler) AdminUnflagReportHandler(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid report ID", h.logger)
+		return
+	}
+
+	flag, trust, err := h.reportService.ClearReportFlag(r.Context(), id)
+	if err != nil {
+		h.respondWithReportFlagError(w, err, id)
+		return
+	}
+
+	response := toReportFlagResponse(flag, trust)
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	auditErr := h.auditService.LogReportUnflagged(
+		r.Context(),
+		adminID,
+		id,
+		response.ReporterUserID,
+		flag.Flag,
+		trust,
+		ipAddress,
+		userAgent,
+	)
+	if auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log report unflag audit event", "report_id", id, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminGetReporterTrustHandler handles GET /api/admin/users/{id}/trust-score
+// @Summary Get a user's reporter trust score (Admin)
+// @Description Returns the user's trust score as a reporter, based on how many of their reports were flagged as spam or false alarms recently, and whether off-shift reporting is suspended
+// @Tags admin/reports
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} service.ReporterTrust "Reporter trust score"
+// @Failure 400 {object} ErrorResponse "Invalid user ID"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/users/{id}/trust-score [get]
+func (h *AdminReportHandler) AdminGetReporterTrustHandler(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid user ID", h.logger)
+		return
+	}
+
+	if _, err := h.querier.GetUserByID(r.Context(), userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			RespondWithError(w, http.StatusNotFound, "User not found", h.logger, "user_id", userID)
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to get user", h.logger, "error", err.Error())
+		}
+		return
+	}
+
+	trust, err := h.reportService.GetReporterTrust(r.Context(), userID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to get trust score", h.logger, "error", err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, trust, h.logger)
+}
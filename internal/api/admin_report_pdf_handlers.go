@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"night-owls-go/internal/service"
+)
+
+// AdminReportPDFHandler renders printable PDF reports for admins.
+type AdminReportPDFHandler struct {
+	pdfService *service.ReportPDFService
+	logger     *slog.Logger
+}
+
+// NewAdminReportPDFHandler creates a new AdminReportPDFHandler.
+func NewAdminReportPDFHandler(pdfService *service.ReportPDFService, logger *slog.Logger) *AdminReportPDFHandler {
+	return &AdminReportPDFHandler{
+		pdfService: pdfService,
+		logger:     logger.With("handler", "AdminReportPDFHandler"),
+	}
+}
+
+// parseReportPDFOptions reads the full_reporter query parameter.
+func parseReportPDFOptions(r *http.Request) (service.ReportPDFOptions, error) {
+	var opts service.ReportPDFOptions
+	if value := r.URL.Query().Get("full_reporter"); value != "" {
+		full, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, errors.New("invalid full_reporter: use true or false")
+		}
+		opts.FullReporter = full
+	}
+	return opts, nil
+}
+
+// writePDF sends a rendered document as a download.
+func (h *AdminReportPDFHandler) writePDF(w http.ResponseWriter, filename string, pdf []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(pdf); err != nil {
+		h.logger.Warn("Failed to write PDF response", "error", err)
+	}
+}
+
+// AdminReportPDFHandler handles GET /api/admin/reports/{id}/pdf
+// @Summary Print a report as PDF (Admin)
+// @Description Render a single report, including archived ones, to a printable PDF with the emergency contacts, reporter, schedule and shift, a map or coordinates, photos and the report's audit history. Reporter names are masked unless full_reporter is set. Every print is recorded in the audit trail.
+// @Tags admin/reports
+// @Produce application/pdf
+// @Param id path int true "Report ID"
+// @Param full_reporter query bool false "Show reporter names and phone numbers in full (default false)"
+// @Success 200 {file} file "PDF document"
+// @Failure 400 {object} ErrorResponse "Invalid report ID or query parameters"
+// @Failure 404 {object} ErrorResponse "Report not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/reports/{id}/pdf [get]
+func (h *AdminReportPDFHandler) AdminReportPDFHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	reportID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid report ID", h.logger)
+		return
+	}
+
+	opts, err := parseReportPDFOptions(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	pdf, err := h.pdfService.RenderReport(r.Context(), reportID, opts, userID, ipAddress, userAgent)
+	if err != nil {
+		if errors.Is(err, service.ErrReportNotFound) {
+			RespondWithError(w, http.StatusNotFound, "Report not found", h.logger, "report_id", reportID)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to render report PDF", h.logger, "error", err)
+		return
+	}
+
+	h.writePDF(w, fmt.Sprintf("night-owls-report-%d.pdf", reportID), pdf)
+	h.logger.InfoContext(r.Context(), "Report printed", "report_id", reportID, "full_reporter", opts.FullReporter, "admin_user_id", userID)
+}
+
+// AdminReportsPDFHandler handles GET /api/admin/reports/pdf
+// @Summary Print reports in a date range as PDF (Admin)
+// @Description Render the active reports in a date range, oldest first, to a single printable PDF with a summary page. Accepts the admin report list filters and requires both from and to. Reporter names are masked unless full_reporter is set. Every print is recorded in the audit trail.
+// @Tags admin/reports
+// @Produce application/pdf
+// @Param from query string true "Start date (YYYY-MM-DD or RFC3339)"
+// @Param to query string true "End date, inclusive (YYYY-MM-DD or RFC3339)"
+// @Param severity query int false "Filter by severity (0=info, 1=warning, 2=critical)"
+// @Param schedule_id query int false "Filter by schedule ID (0 for off-shift reports)"
+// @Param user_id query int false "Filter by reporter user ID"
+// @Param category_id query int false "Filter by incident category ID"
+// @Param full_reporter query bool false "Show reporter names and phone numbers in full (default false)"
+// @Success 200 {file} file "PDF document"
+// @Failure 400 {object} ErrorResponse "Invalid query parameters or too many reports"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/reports/pdf [get]
+func (h *AdminReportPDFHandler) AdminReportsPDFHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	filter, err := parseAdminReportFilter(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+	if filter.From == nil || filter.To == nil {
+		RespondWithError(w, http.StatusBadRequest, "from and to are required", h.logger)
+		return
+	}
+
+	opts, err := parseReportPDFOptions(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	pdf, count, err := h.pdfService.RenderReports(r.Context(), filter, opts, userID, ipAddress, userAgent)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyReportsForPDF) {
+			RespondWithError(w, http.StatusBadRequest,
+				fmt.Sprintf("More than %d reports match, narrow the date range or filters", service.MaxReportsPerPDF), h.logger)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to render reports PDF", h.logger, "error", err)
+		return
+	}
+
+	filename := fmt.Sprintf("night-owls-reports-%s.pdf", time.Now().UTC().Format("20060102-150405"))
+	h.writePDF(w, filename, pdf)
+	h.logger.InfoContext(r.Context(), "Reports printed", "report_count", count, "full_reporter", opts.FullReporter, "admin_user_id", userID)
+}
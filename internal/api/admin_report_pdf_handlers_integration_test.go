@@ -0,0 +1,162 @@
+package api_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"database/sql"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pdfText inflates the content streams of a PDF so drawn text can be searched.
+func pdfText(t *testing.T, pdf []byte) string {
+	var text strings.Builder
+	rest := pdf
+	for {
+		start := bytes.Index(rest, []byte("/Filter /FlateDecode /Length"))
+		if start < 0 {
+			break
+		}
+		rest = rest[start:]
+		begin := bytes.Index(rest, []byte("stream\n")) + len("stream\n")
+		end := bytes.Index(rest, []byte("\nendstream"))
+		zr, err := zlib.NewReader(bytes.NewReader(rest[begin:end]))
+		require.NoError(t, err)
+		content, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		text.Write(content)
+		rest = rest[end:]
+	}
+	return text.String()
+}
+
+func TestAdminReportPDF(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	_, adminToken := app.createTestUserAndLogin(t, "+15550003701", "Test Admin", "admin")
+	reporter, _ := app.createTestUserAndLogin(t, "+15550003702", "Pat Reporter", "owl")
+
+	_, err := app.Querier.CreateEmergencyContact(ctx, db.CreateEmergencyContactParams{
+		Name:         "Neighbourhood Patrol Control",
+		Number:       "021 555 0100",
+		Description:  sql.NullString{String: "24 hour control room", Valid: true},
+		DisplayOrder: 1,
+	})
+	require.NoError(t, err)
+
+	report, err := app.Querier.CreateReport(ctx, db.CreateReportParams{
+		UserID:      sql.NullInt64{Int64: reporter.UserID, Valid: true},
+		Severity:    2,
+		Message:     sql.NullString{String: "Gate forced open at number 7", Valid: true},
+		Latitude:    sql.NullFloat64{Float64: -33.9249, Valid: true},
+		Longitude:   sql.NullFloat64{Float64: 18.4241, Valid: true},
+		GpsAccuracy: sql.NullFloat64{Float64: 12, Valid: true},
+	})
+	require.NoError(t, err)
+
+	photo := image.NewRGBA(image.Rect(0, 0, 8, 6))
+	photo.Set(2, 2, color.RGBA{0xff, 0, 0, 0xff})
+	photoPath := filepath.Join(t.TempDir(), "gate.png")
+	f, err := os.Create(photoPath)
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(f, photo))
+	require.NoError(t, f.Close())
+	_, err = app.Querier.CreateReportPhoto(ctx, db.CreateReportPhotoParams{
+		ReportID:       report.ReportID,
+		Filename:       "gate.png",
+		FileSizeBytes:  100,
+		MimeType:       "image/png",
+		StoragePath:    photoPath,
+		ChecksumSha256: "abc",
+	})
+	require.NoError(t, err)
+	_, err = app.Querier.CreateReportPhoto(ctx, db.CreateReportPhotoParams{
+		ReportID:       report.ReportID,
+		Filename:       "missing.jpg",
+		FileSizeBytes:  100,
+		MimeType:       "image/jpeg",
+		StoragePath:    filepath.Join(t.TempDir(), "missing.jpg"),
+		ChecksumSha256: "def",
+	})
+	require.NoError(t, err)
+
+	reportPath := fmt.Sprintf("/api/admin/reports/%d/pdf", report.ReportID)
+
+	t.Run("single report masks the reporter by default", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", reportPath, nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		assert.Equal(t, "application/pdf", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), fmt.Sprintf("night-owls-report-%d.pdf", report.ReportID))
+
+		body := rr.Body.Bytes()
+		require.True(t, bytes.HasPrefix(body, []byte("%PDF-")))
+		assert.Contains(t, string(body), "/Subtype /Image", "the photo should be embedded")
+
+		text := pdfText(t, body)
+		assert.Contains(t, text, "Neighbourhood Patrol Control: 021 555 0100")
+		assert.Contains(t, text, "Gate forced open at number 7")
+		assert.Contains(t, text, "Off-shift report")
+		assert.Contains(t, text, "-33.924900, 18.424100")
+		assert.Contains(t, text, "Pat R.")
+		assert.NotContains(t, text, "Pat Reporter")
+		assert.Contains(t, text, "could not be included \\(image/jpeg\\)")
+		assert.Contains(t, text, "report.printed by Test Admin", "the print itself is part of the audit history")
+	})
+
+	t.Run("full reporter shows name and phone", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", reportPath+"?full_reporter=true", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Contains(t, pdfText(t, rr.Body.Bytes()), "Pat Reporter \\(+15550003702\\)")
+
+		var details string
+		require.NoError(t, app.DB.QueryRow(`SELECT details FROM audit_events WHERE event_type = 'report.printed' ORDER BY event_id DESC LIMIT 1`).Scan(&details))
+		assert.Contains(t, details, `"full_reporter":true`)
+	})
+
+	t.Run("single report errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, app.makeRequest(t, "GET", "/api/admin/reports/999999/pdf", nil, adminToken).Code)
+		assert.Equal(t, http.StatusBadRequest, app.makeRequest(t, "GET", reportPath+"?full_reporter=maybe", nil, adminToken).Code)
+	})
+
+	t.Run("date range", func(t *testing.T) {
+		today := time.Now().UTC().Format("2006-01-02")
+		rr := app.makeRequest(t, "GET", "/api/admin/reports/pdf?from="+today, nil, adminToken)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "a range needs both ends")
+
+		second, err := app.Querier.CreateReport(ctx, db.CreateReportParams{
+			UserID:   sql.NullInt64{Int64: reporter.UserID, Valid: true},
+			Severity: 1,
+			Message:  sql.NullString{String: "Loud bang heard", Valid: true},
+		})
+		require.NoError(t, err)
+
+		rr = app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/reports/pdf?from=%s&to=%s", today, today), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		text := pdfText(t, rr.Body.Bytes())
+		assert.Contains(t, text, "Summary")
+		assert.Contains(t, text, fmt.Sprintf("Report #%d", report.ReportID))
+		assert.Contains(t, text, fmt.Sprintf("Report #%d", second.ReportID))
+		assert.Contains(t, text, "No location recorded")
+
+		var auditCount int
+		require.NoError(t, app.DB.QueryRow(`SELECT COUNT(*) FROM audit_events WHERE event_type = 'report.printed'`).Scan(&auditCount))
+		assert.Equal(t, 3, auditCount)
+	})
+}
@@ -0,0 +1,313 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+)
+
+// AdminRetentionHandler handles report retention policies and purge runs
+type AdminRetentionHandler struct {
+	archivingService *service.ReportArchivingService
+	auditService     *service.AuditService
+	logger           *slog.Logger
+}
+
+// NewAdminRetentionHandler creates a new AdminRetentionHandler
+func NewAdminRetentionHandler(archivingService *service.ReportArchivingService, auditService *service.AuditService, logger *slog.Logger) *AdminRetentionHandler {
+	return &AdminRetentionHandler{
+		archivingService: archivingService,
+		auditService:     auditService,
+		logger:           logger.With("handler", "AdminRetentionHandler"),
+	}
+}
+
+// RetentionPolicyResponse represents a report retention policy.
+// A missing severity or category_id means the policy applies to all of them.
+type RetentionPolicyResponse struct {
+	ID               int64     `json:"id"`
+	Severity         *int64    `json:"severity,omitempty"`
+	CategoryID       *int64    `json:"category_id,omitempty"`
+	ArchiveAfterDays *int64    `json:"archive_after_days"` // null means never archive
+	PurgeAfterDays   *int64    `json:"purge_after_days"`   // null means never purge, counted from archiving
+	PurgeAction      string    `json:"purge_action"`       // delete or anonymise
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// RetentionPolicyRequest represents the request to create or update a retention policy.
+// severity and category_id are ignored on update.
+type RetentionPolicyRequest struct {
+	Severity         *int64 `json:"severity,omitempty"`
+	CategoryID       *int64 `json:"category_id,omitempty"`
+	ArchiveAfterDays *int64 `json:"archive_after_days"`
+	PurgeAfterDays   *int64 `json:"purge_after_days"`
+	PurgeAction      string `json:"purge_action"`
+}
+
+func (req RetentionPolicyRequest) toInput() service.RetentionPolicyInput {
+	return service.RetentionPolicyInput{
+		Severity:         req.Severity,
+		CategoryID:       req.CategoryID,
+		ArchiveAfterDays: req.ArchiveAfterDays,
+		PurgeAfterDays:   req.PurgeAfterDays,
+		PurgeAction:      req.PurgeAction,
+	}
+}
+
+func toRetentionPolicyResponse(policy db.ReportRetentionPolicy) RetentionPolicyResponse {
+	response := RetentionPolicyResponse{
+		ID:          policy.PolicyID,
+		PurgeAction: policy.PurgeAction,
+		UpdatedAt:   policy.UpdatedAt.Time,
+	}
+	if policy.Severity.Valid {
+		response.Severity = &policy.Severity.Int64
+	}
+	if policy.CategoryID.Valid {
+		response.CategoryID = &policy.CategoryID.Int64
+	}
+	if policy.ArchiveAfterDays.Valid {
+		response.ArchiveAfterDays = &policy.ArchiveAfterDays.Int64
+	}
+	if policy.PurgeAfterDays.Valid {
+		response.PurgeAfterDays = &policy.PurgeAfterDays.Int64
+	}
+	return response
+}
+
+func retentionPolicyAuditDetails(policy RetentionPolicyResponse) map[string]interface{} {
+	return map[string]interface{}{
+		"severity":           policy.Severity,
+		"category_id":        policy.CategoryID,
+		"archive_after_days": policy.ArchiveAfterDays,
+		"purge_after_days":   policy.PurgeAfterDays,
+		"purge_action":       policy.PurgeAction,
+	}
+}
+
+func (h *AdminRetentionHandler) logPolicyChange(r *http.Request, action string, policy RetentionPolicyResponse) {
+	adminUserID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		return
+	}
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if err := h.auditService.LogRetentionPolicyChanged(r.Context(), adminUserID, policy.ID, action, retentionPolicyAuditDetails(policy), ipAddress, userAgent); err != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log retention policy audit event", "policy_id", policy.ID, "error", err)
+	}
+}
+
+// AdminListRetentionPoliciesHandler handles GET /api/admin/retention-policies
+// @Summary Admin: Get report retention policies
+// @Description Returns all report retention policies. The most specific policy for a report wins: severity and category, then category, then severity, then the fallback policy.
+// @Tags admin/reports
+// @Produce json
+// @Success 200 {array} RetentionPolicyResponse "List of retention policies"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/retention-policies [get]
+func (h *AdminRetentionHandler) AdminListRetentionPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.archivingService.ListPolicies(r.Context())
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to get retention policies", h.logger, "error", err.Error())
+		return
+	}
+
+	response := make([]RetentionPolicyResponse, 0, len(policies))
+	for _, policy := range policies {
+		response = append(response, toRetentionPolicyResponse(policy))
+	}
+
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminCreateRetentionPolicyHandler handles POST /api/admin/retention-policies
+// @Summary Admin: Create report retention policy
+// @Description Creates a retention policy for a severity, a category, both, or neither (the fallback)
+// @Tags admin/reports
+// @Accept json
+// @Produce json
+// @Param request body RetentionPolicyRequest true "Retention policy"
+// @Success 201 {object} RetentionPolicyResponse "Created retention policy"
+// @Failure 400 {object} ErrorResponse "Invalid request data"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 409 {object} ErrorResponse "A policy already exists for this severity and category"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/retention-policies [post]
+func (h *AdminRetentionHandler) AdminCreateRetentionPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var req RetentionPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	policy, err := h.archivingService.CreatePolicy(r.Context(), req.toInput())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRetentionPolicy):
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		case errors.Is(err, service.ErrRetentionPolicyExists):
+			RespondWithError(w, http.StatusConflict, err.Error(), h.logger)
+		default:
+			RespondWithError(w, http.StatusInternalServerError, "Failed to create retention policy", h.logger, "error", err.Error())
+		}
+		return
+	}
+
+	response := toRetentionPolicyResponse(policy)
+	h.logPolicyChange(r, "created", response)
+	RespondWithJSON(w, http.StatusCreated, response, h.logger)
+}
+
+// AdminUpdateRetentionPolicyHandler handles PUT /api/admin/retention-policies/{id}
+// @Summary Admin: Update report retention policy
+// @Description Updates the archive and purge periods of a retention policy. The severity and category of a policy cannot be changed.
+// @Tags admin/reports
+// @Accept json
+// @Produce json
+// @Param id path int true "Retention Policy ID"
+// @Param request body RetentionPolicyRequest true "Retention policy"
+// @Success 200 {object} RetentionPolicyResponse "Updated retention policy"
+// @Failure 400 {object} ErrorResponse "Invalid request data"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Retention policy not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/retention-policies/{id} [put]
+func (h *AdminRetentionHandler) AdminUpdateRetentionPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	policyIDStr := r.PathValue("id")
+	policyID, err := strconv.ParseInt(policyIDStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid policy ID", h.logger, "policy_id", policyIDStr)
+		return
+	}
+
+	var req RetentionPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	policy, err := h.archivingService.UpdatePolicy(r.Context(), policyID, req.toInput())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrRetentionPolicyNotFound):
+			RespondWithError(w, http.StatusNotFound, "Retention policy not found", h.logger, "policy_id", policyID)
+		case errors.Is(err, service.ErrInvalidRetentionPolicy):
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		default:
+			RespondWithError(w, http.StatusInternalServerError, "Failed to update retention policy", h.logger, "error", err.Error())
+		}
+		return
+	}
+
+	response := toRetentionPolicyResponse(policy)
+	h.logPolicyChange(r, "updated", response)
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminDeleteRetentionPolicyHandler handles DELETE /api/admin/retention-policies/{id}
+// @Summary Admin: Delete report retention policy
+// @Description Deletes a retention policy. Reports it covered fall back to the next most specific policy.
+// @Tags admin/reports
+// @Param id path int true "Retention Policy ID"
+// @Success 204 "Retention policy deleted successfully"
+// @Failure 400 {object} ErrorResponse "Invalid policy ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Retention policy not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/retention-policies/{id} [delete]
+func (h *AdminRetentionHandler) AdminDeleteRetentionPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	policyIDStr := r.PathValue("id")
+	policyID, err := strconv.ParseInt(policyIDStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid policy ID", h.logger, "policy_id", policyIDStr)
+		return
+	}
+
+	policy, err := h.archivingService.DeletePolicy(r.Context(), policyID)
+	if err != nil {
+		if errors.Is(err, service.ErrRetentionPolicyNotFound) {
+			RespondWithError(w, http.StatusNotFound, "Retention policy not found", h.logger, "policy_id", policyID)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to delete retention policy", h.logger, "error", err.Error())
+		return
+	}
+
+	h.logPolicyChange(r, "deleted", toRetentionPolicyResponse(policy))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminRetentionPreviewHandler handles GET /api/admin/retention/preview
+// @Summary Admin: Preview report retention
+// @Description Lists the reports the next retention run would archive and the archived reports it would delete or anonymise. Nothing is changed.
+// @Tags admin/reports
+// @Produce json
+// @Success 200 {object} service.RetentionPlan "Reports due for archiving and purging"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/retention/preview [get]
+func (h *AdminRetentionHandler) AdminRetentionPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	plan, err := h.archivingService.PlanRetention(r.Context(), time.Now().UTC())
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to preview retention", h.logger, "error", err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, plan, h.logger)
+}
+
+// AdminPurgeReportsHandler handles POST /api/admin/retention/purge
+// @Summary Admin: Purge archived reports
+// @Description Permanently deletes or anonymises archived reports whose retention period has passed, including their photo files. Use dry_run=true to see what would be purged without changing anything.
+// @Tags admin/reports
+// @Produce json
+// @Param dry_run query bool false "Only report what would be purged"
+// @Success 200 {object} service.PurgeResult "Purge summary"
+// @Failure 400 {object} ErrorResponse "Invalid dry_run value"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/retention/purge [post]
+func (h *AdminRetentionHandler) AdminPurgeReportsHandler(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "Admin user ID not found in context", h.logger)
+		return
+	}
+
+	dryRun := false
+	if dryRunStr := r.URL.Query().Get("dry_run"); dryRunStr != "" {
+		parsed, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			RespondWithError(w, http.StatusBadRequest, "Invalid dry_run value", h.logger, "dry_run", dryRunStr)
+			return
+		}
+		dryRun = parsed
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	result, err := h.archivingService.PurgeArchivedReports(r.Context(), dryRun, &adminUserID, ipAddress, userAgent)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to purge reports", h.logger, "error", err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, result, h.logger)
+}
@@ -0,0 +1,228 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminRetention_PoliciesPreviewAndPurge(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	_, adminToken := app.createTestUserAndLogin(t, "+15550003001", "Test Admin", "admin")
+	reporter, _ := app.createTestUserAndLogin(t, "+15550003002", "Retention Owl", "owl")
+
+	jsonBody := func(v interface{}) *bytes.Reader {
+		body, err := json.Marshal(v)
+		require.NoError(t, err)
+		return bytes.NewReader(body)
+	}
+
+	var vehicleCategoryID int64
+	require.NoError(t, app.DB.QueryRow(`SELECT category_id FROM incident_categories WHERE name = 'Suspicious vehicle'`).Scan(&vehicleCategoryID))
+
+	// createReport inserts a report and backdates it. archivedDaysAgo < 0 leaves it unarchived.
+	createReport := func(severity int64, categoryID int64, createdDaysAgo, archivedDaysAgo int) int64 {
+		params := db.CreateOffShiftReportParams{
+			UserID:    newNullInt64(reporter.UserID),
+			Severity:  severity,
+			Message:   newNullString("Man with a crowbar at number 12"),
+			Latitude:  sql.NullFloat64{Float64: -33.92, Valid: true},
+			Longitude: sql.NullFloat64{Float64: 18.42, Valid: true},
+		}
+		if categoryID > 0 {
+			params.CategoryID = newNullInt64(categoryID)
+			params.CategoryFields = newNullString(`{"vehicle_registration":"CA 123-456"}`)
+		}
+		report, err := app.Querier.CreateOffShiftReport(ctx, params)
+		require.NoError(t, err)
+
+		_, err = app.DB.Exec(`UPDATE reports SET created_at = datetime('now', ?) WHERE report_id = ?`, fmt.Sprintf("-%d days", createdDaysAgo), report.ReportID)
+		require.NoError(t, err)
+		if archivedDaysAgo >= 0 {
+			_, err = app.DB.Exec(`UPDATE reports SET archived_at = datetime('now', ?) WHERE report_id = ?`, fmt.Sprintf("-%d days", archivedDaysAgo), report.ReportID)
+			require.NoError(t, err)
+		}
+		return report.ReportID
+	}
+
+	t.Run("seeded policies preserve the previous archiving rules", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/retention-policies", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var policies []api.RetentionPolicyResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &policies))
+		require.Len(t, policies, 3)
+		assert.EqualValues(t, 30, *policies[0].ArchiveAfterDays)
+		assert.EqualValues(t, 365, *policies[1].ArchiveAfterDays)
+		assert.Nil(t, policies[2].ArchiveAfterDays)
+		assert.Nil(t, policies[2].PurgeAfterDays)
+	})
+
+	t.Run("invalid and duplicate policies are rejected", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", "/api/admin/retention-policies", jsonBody(map[string]interface{}{
+			"severity": 0, "archive_after_days": 10,
+		}), adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code, "Response: %s", rr.Body.String())
+
+		rr = app.makeRequest(t, "POST", "/api/admin/retention-policies", jsonBody(map[string]interface{}{
+			"purge_after_days": 10, "purge_action": "shred",
+		}), adminToken)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Response: %s", rr.Body.String())
+
+		rr = app.makeRequest(t, "POST", "/api/admin/retention-policies", jsonBody(map[string]interface{}{
+			"category_id": 99999, "archive_after_days": 10,
+		}), adminToken)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Response: %s", rr.Body.String())
+	})
+
+	// Vehicle reports are archived after a week and anonymised a month later, whatever their severity
+	rr := app.makeRequest(t, "POST", "/api/admin/retention-policies", jsonBody(map[string]interface{}{
+		"category_id":        vehicleCategoryID,
+		"archive_after_days": 7,
+		"purge_after_days":   30,
+		"purge_action":       service.PurgeActionAnonymise,
+	}), adminToken)
+	require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+
+	// Normal reports are deleted 90 days after archiving
+	rr = app.makeRequest(t, "GET", "/api/admin/retention-policies", nil, adminToken)
+	var policies []api.RetentionPolicyResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &policies))
+	rr = app.makeRequest(t, "PUT", fmt.Sprintf("/api/admin/retention-policies/%d", policies[0].ID), jsonBody(map[string]interface{}{
+		"archive_after_days": 30,
+		"purge_after_days":   90,
+	}), adminToken)
+	require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+	oldNormal := createReport(0, 0, 40, -1)
+	oldSuspicion := createReport(1, 0, 40, -1)
+	recentVehicle := createReport(1, vehicleCategoryID, 10, -1)
+	oldIncident := createReport(2, 0, 400, -1)
+	expiredNormal := createReport(0, 0, 200, 100)
+	expiredVehicle := createReport(1, vehicleCategoryID, 60, 40)
+	keptNormal := createReport(0, 0, 100, 60)
+
+	// Give the expired normal report a photo on disk
+	photoPath := filepath.Join(t.TempDir(), "evidence.jpg")
+	require.NoError(t, os.WriteFile(photoPath, []byte("jpeg"), 0600))
+	_, err := app.Querier.CreateReportPhoto(ctx, db.CreateReportPhotoParams{
+		ReportID:       expiredNormal,
+		Filename:       "evidence.jpg",
+		FileSizeBytes:  4,
+		MimeType:       "image/jpeg",
+		StoragePath:    photoPath,
+		ChecksumSha256: "abc",
+	})
+	require.NoError(t, err)
+
+	t.Run("preview lists reports due for archiving and purging", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/retention/preview", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var plan service.RetentionPlan
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &plan))
+
+		archiveIDs := []int64{}
+		for _, c := range plan.ToArchive {
+			archiveIDs = append(archiveIDs, c.ReportID)
+		}
+		assert.ElementsMatch(t, []int64{oldNormal, recentVehicle}, archiveIDs)
+		assert.NotContains(t, archiveIDs, oldSuspicion)
+		assert.NotContains(t, archiveIDs, oldIncident)
+
+		purge := map[int64]string{}
+		for _, c := range plan.ToPurge {
+			purge[c.ReportID] = c.Action
+		}
+		assert.Equal(t, map[int64]string{
+			expiredNormal:  service.PurgeActionDelete,
+			expiredVehicle: service.PurgeActionAnonymise,
+		}, purge)
+		assert.NotContains(t, purge, keptNormal)
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", "/api/admin/retention/purge?dry_run=true", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var result service.PurgeResult
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.True(t, result.DryRun)
+		assert.Equal(t, 1, result.Deleted)
+		assert.Equal(t, 1, result.Anonymised)
+		assert.Equal(t, 1, result.PhotoFilesRemoved)
+
+		assert.FileExists(t, photoPath)
+		_, err := app.Querier.AdminGetReportWithContext(ctx, expiredNormal)
+		assert.NoError(t, err)
+
+		events, err := app.Querier.ListAuditEventsByType(ctx, db.ListAuditEventsByTypeParams{EventType: "report.purged", Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+
+	t.Run("purge deletes, anonymises and audits", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", "/api/admin/retention/purge", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var result service.PurgeResult
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.False(t, result.DryRun)
+		assert.Equal(t, 1, result.Deleted)
+		assert.Equal(t, 1, result.Anonymised)
+		assert.Equal(t, 1, result.PhotoFilesRemoved)
+
+		assert.NoFileExists(t, photoPath)
+		_, err := app.Querier.AdminGetReportWithContext(ctx, expiredNormal)
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+		photos, err := app.Querier.GetReportPhotos(ctx, expiredNormal)
+		require.NoError(t, err)
+		assert.Empty(t, photos)
+
+		anonymised, err := app.Querier.AdminGetReportWithContext(ctx, expiredVehicle)
+		require.NoError(t, err)
+		assert.False(t, anonymised.Message.Valid)
+		assert.False(t, anonymised.Latitude.Valid)
+		assert.False(t, anonymised.CategoryFields.Valid)
+		assert.EqualValues(t, 1, anonymised.Severity)
+		assert.Equal(t, vehicleCategoryID, anonymised.CategoryID.Int64)
+
+		_, err = app.Querier.AdminGetReportWithContext(ctx, keptNormal)
+		assert.NoError(t, err)
+
+		events, err := app.Querier.ListAuditEventsByType(ctx, db.ListAuditEventsByTypeParams{EventType: "report.purged", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Contains(t, events[0].Details.String, fmt.Sprintf(`"deleted_report_ids":[%d]`, expiredNormal))
+		assert.Contains(t, events[0].Details.String, fmt.Sprintf(`"anonymised_report_ids":[%d]`, expiredVehicle))
+
+		// Anonymised reports are not purged again
+		rr = app.makeRequest(t, "POST", "/api/admin/retention/purge?dry_run=true", nil, adminToken)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &result))
+		assert.Empty(t, result.Reports)
+	})
+
+	t.Run("policy changes are audited", func(t *testing.T) {
+		for _, eventType := range []string{"retention_policy.created", "retention_policy.updated"} {
+			events, err := app.Querier.ListAuditEventsByType(ctx, db.ListAuditEventsByTypeParams{EventType: eventType, Limit: 10})
+			require.NoError(t, err)
+			assert.Len(t, events, 1, eventType)
+		}
+	})
+}
@@ -0,0 +1,515 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+)
+
+// AdminWatchlistHandler handles the admin watchlist of vehicles and people of interest.
+type AdminWatchlistHandler struct {
+	watchlistService *service.WatchlistService
+	auditService     *service.AuditService
+	logger           *slog.Logger
+}
+
+// NewAdminWatchlistHandler creates a new AdminWatchlistHandler.
+func NewAdminWatchlistHandler(watchlistService *service.WatchlistService, auditService *service.AuditService, logger *slog.Logger) *AdminWatchlistHandler {
+	return &AdminWatchlistHandler{
+		watchlistService: watchlistService,
+		auditService:     auditService,
+		logger:           logger.With("handler", "AdminWatchlistHandler"),
+	}
+}
+
+// WatchlistEntryRequest is the body for creating or replacing a watchlist entry
+type WatchlistEntryRequest struct {
+	Kind         string   `json:"kind"`
+	Label        string   `json:"label"`
+	Registration string   `json:"registration,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Notes        string   `json:"notes,omitempty"`
+	Keywords     []string `json:"keywords,omitempty"`
+	IsActive     *bool    `json:"is_active,omitempty"` // Defaults to true
+}
+
+// WatchlistEntryResponse describes a watchlist entry
+type WatchlistEntryResponse struct {
+	EntryID         int64      `json:"entry_id"`
+	Kind            string     `json:"kind"`
+	Label           string     `json:"label"`
+	Registration    string     `json:"registration,omitempty"`
+	Description     string     `json:"description,omitempty"`
+	Notes           string     `json:"notes,omitempty"`
+	Keywords        []string   `json:"keywords"`
+	IsActive        bool       `json:"is_active"`
+	CreatedByUserID *int64     `json:"created_by_user_id,omitempty"`
+	CreatedAt       *time.Time `json:"created_at,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+	MatchCount      *int64     `json:"match_count,omitempty"`
+	PhotoCount      *int64     `json:"photo_count,omitempty"`
+}
+
+// WatchlistPhotoResponse describes a photo on a watchlist entry
+type WatchlistPhotoResponse struct {
+	PhotoID       int64      `json:"photo_id"`
+	MimeType      string     `json:"mime_type"`
+	FileSizeBytes int64      `json:"file_size_bytes"`
+	PhotoURL      string     `json:"photo_url"`
+	CreatedAt     *time.Time `json:"created_at,omitempty"`
+}
+
+// WatchlistTimelineItem is a report linked to a watchlist entry
+type WatchlistTimelineItem struct {
+	ReportID     int64      `json:"report_id"`
+	Severity     int64      `json:"severity"`
+	Message      string     `json:"message,omitempty"`
+	Latitude     *float64   `json:"latitude,omitempty"`
+	Longitude    *float64   `json:"longitude,omitempty"`
+	ReporterName string     `json:"reporter_name,omitempty"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	Archived     bool       `json:"archived"`
+	MatchedOn    string     `json:"matched_on"`
+	MatchedTerm  string     `json:"matched_term"`
+	MatchedAt    *time.Time `json:"matched_at,omitempty"`
+}
+
+// WatchlistEntryDetailResponse is an entry with its photos and linked reports
+type WatchlistEntryDetailResponse struct {
+	WatchlistEntryResponse
+	Photos   []WatchlistPhotoResponse `json:"photos"`
+	Timeline []WatchlistTimelineItem  `json:"timeline"`
+}
+
+func toWatchlistEntryResponse(entry db.WatchlistEntry) WatchlistEntryResponse {
+	return WatchlistEntryResponse{
+		EntryID:         entry.EntryID,
+		Kind:            entry.Kind,
+		Label:           entry.Label,
+		Registration:    entry.Registration.String,
+		Description:     entry.Description.String,
+		Notes:           entry.Notes.String,
+		Keywords:        service.DecodeWatchlistKeywords(entry),
+		IsActive:        entry.IsActive,
+		CreatedByUserID: nullInt64ToPointer(entry.CreatedByUserID),
+		CreatedAt:       nullTimeToPointer(entry.CreatedAt),
+		UpdatedAt:       nullTimeToPointer(entry.UpdatedAt),
+	}
+}
+
+func toWatchlistPhotoResponse(photo db.WatchlistPhoto) WatchlistPhotoResponse {
+	return WatchlistPhotoResponse{
+		PhotoID:       photo.PhotoID,
+		MimeType:      photo.MimeType,
+		FileSizeBytes: photo.FileSizeBytes,
+		PhotoURL:      fmt.Sprintf("/api/admin/watchlist/%d/photos/%d", photo.EntryID, photo.PhotoID),
+		CreatedAt:     nullTimeToPointer(photo.CreatedAt),
+	}
+}
+
+func (h *AdminWatchlistHandler) parseEntryID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	entryIDStr := r.PathValue("id")
+	entryID, err := strconv.ParseInt(entryIDStr, 10, 64)
+	if err != nil || entryID <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "Invalid watchlist entry ID", h.logger, "entry_id", entryIDStr)
+		return 0, false
+	}
+	return entryID, true
+}
+
+func (h *AdminWatchlistHandler) parsePhotoID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	photoIDStr := r.PathValue("photoId")
+	photoID, err := strconv.ParseInt(photoIDStr, 10, 64)
+	if err != nil || photoID <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "Invalid photo ID", h.logger, "photo_id", photoIDStr)
+		return 0, false
+	}
+	return photoID, true
+}
+
+func (h *AdminWatchlistHandler) parseEntryRequest(w http.ResponseWriter, r *http.Request) (service.WatchlistEntryInput, bool) {
+	var req WatchlistEntryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return service.WatchlistEntryInput{}, false
+	}
+	input := service.WatchlistEntryInput{
+		Kind:         req.Kind,
+		Label:        req.Label,
+		Registration: req.Registration,
+		Description:  req.Description,
+		Notes:        req.Notes,
+		Keywords:     req.Keywords,
+		IsActive:     true,
+	}
+	if req.IsActive != nil {
+		input.IsActive = *req.IsActive
+	}
+	return input, true
+}
+
+func (h *AdminWatchlistHandler) respondWithWatchlistError(w http.ResponseWriter, err error, entryID int64) {
+	switch {
+	case errors.Is(err, service.ErrWatchlistEntryNotFound):
+		RespondWithError(w, http.StatusNotFound, "Watchlist entry not found", h.logger, "entry_id", entryID)
+	case errors.Is(err, service.ErrWatchlistPhotoNotFound):
+		RespondWithError(w, http.StatusNotFound, "Photo not found", h.logger, "entry_id", entryID)
+	case errors.Is(err, service.ErrInvalidWatchlistEntry), errors.Is(err, service.ErrInvalidWatchlistPhoto):
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to process watchlist request", h.logger, "error", err.Error())
+	}
+}
+
+// ListWatchlistEntriesHandler handles GET /api/admin/watchlist
+// @Summary List watchlist entries (Admin)
+// @Description Returns every watchlist entry, active entries first, with how many reports and photos each has
+// @Tags admin/watchlist
+// @Produce json
+// @Success 200 {array} WatchlistEntryResponse "Watchlist entries"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/watchlist [get]
+func (h *AdminWatchlistHandler) ListWatchlistEntriesHandler(w http.ResponseWriter, r *http.Request) {
+	entries, err := h.watchlistService.ListEntries(r.Context())
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to list watchlist entries", h.logger, "error", err.Error())
+		return
+	}
+
+	response := make([]WatchlistEntryResponse, 0, len(entries))
+	for _, row := range entries {
+		item := toWatchlistEntryResponse(db.WatchlistEntry{
+			EntryID:         row.EntryID,
+			Kind:            row.Kind,
+			Label:           row.Label,
+			Registration:    row.Registration,
+			Description:     row.Description,
+			Notes:           row.Notes,
+			Keywords:        row.Keywords,
+			IsActive:        row.IsActive,
+			CreatedByUserID: row.CreatedByUserID,
+			CreatedAt:       row.CreatedAt,
+			UpdatedAt:       row.UpdatedAt,
+		})
+		matchCount, photoCount := row.MatchCount, row.PhotoCount
+		item.MatchCount = &matchCount
+		item.PhotoCount = &photoCount
+		response = append(response, item)
+	}
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// CreateWatchlistEntryHandler handles POST /api/admin/watchlist
+// @Summary Add a watchlist entry (Admin)
+// @Description Adds a vehicle or person of interest. New reports mentioning the registration (ignoring spaces and punctuation) or any of the keywords are linked to the entry and alert on-duty owls and admins.
+// @Tags admin/watchlist
+// @Accept json
+// @Produce json
+// @Param request body WatchlistEntryRequest true "Watchlist entry"
+// @Success 201 {object} WatchlistEntryResponse "Entry created"
+// @Failure 400 {object} ErrorResponse "Invalid entry"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/watchlist [post]
+func (h *AdminWatchlistHandler) CreateWatchlistEntryHandler(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	input, ok := h.parseEntryRequest(w, r)
+	if !ok {
+		return
+	}
+
+	entry, err := h.watchlistService.CreateEntry(r.Context(), input, adminUserID)
+	if err != nil {
+		h.respondWithWatchlistError(w, err, 0)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if auditErr := h.auditService.LogWatchlistEntryCreated(r.Context(), adminUserID, entry.EntryID, entry.Kind, entry.Label, entry.Registration.String, ipAddress, userAgent); auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log watchlist entry creation audit event", "entry_id", entry.EntryID, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusCreated, toWatchlistEntryResponse(entry), h.logger)
+}
+
+// GetWatchlistEntryHandler handles GET /api/admin/watchlist/{id}
+// @Summary Get a watchlist entry (Admin)
+// @Description Returns an entry with its photos and a timeline of linked reports, newest first
+// @Tags admin/watchlist
+// @Produce json
+// @Param id path int true "Watchlist entry ID"
+// @Success 200 {object} WatchlistEntryDetailResponse "Entry with photos and timeline"
+// @Failure 400 {object} ErrorResponse "Invalid entry ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Entry not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/watchlist/{id} [get]
+func (h *AdminWatchlistHandler) GetWatchlistEntryHandler(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := h.parseEntryID(w, r)
+	if !ok {
+		return
+	}
+
+	entry, err := h.watchlistService.GetEntry(r.Context(), entryID)
+	if err != nil {
+		h.respondWithWatchlistError(w, err, entryID)
+		return
+	}
+	matches, photos, err := h.watchlistService.GetTimeline(r.Context(), entryID)
+	if err != nil {
+		h.respondWithWatchlistError(w, err, entryID)
+		return
+	}
+
+	response := WatchlistEntryDetailResponse{
+		WatchlistEntryResponse: toWatchlistEntryResponse(entry),
+		Photos:                 make([]WatchlistPhotoResponse, 0, len(photos)),
+		Timeline:               make([]WatchlistTimelineItem, 0, len(matches)),
+	}
+	for _, photo := range photos {
+		response.Photos = append(response.Photos, toWatchlistPhotoResponse(photo))
+	}
+	for _, match := range matches {
+		response.Timeline = append(response.Timeline, WatchlistTimelineItem{
+			ReportID:     match.ReportID,
+			Severity:     match.Severity,
+			Message:      match.Message.String,
+			Latitude:     nullFloat64ToPointer(match.Latitude),
+			Longitude:    nullFloat64ToPointer(match.Longitude),
+			ReporterName: match.ReporterName,
+			CreatedAt:    nullTimeToPointer(match.CreatedAt),
+			Archived:     match.ArchivedAt.Valid,
+			MatchedOn:    match.MatchedOn,
+			MatchedTerm:  match.MatchedTerm,
+			MatchedAt:    nullTimeToPointer(match.MatchedAt),
+		})
+	}
+	matchCount, photoCount := int64(len(matches)), int64(len(photos))
+	response.MatchCount = &matchCount
+	response.PhotoCount = &photoCount
+
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// UpdateWatchlistEntryHandler handles PUT /api/admin/watchlist/{id}
+// @Summary Update a watchlist entry (Admin)
+// @Description Replaces an entry's details. Set is_active to false to stop matching new reports while keeping the entry's history.
+// @Tags admin/watchlist
+// @Accept json
+// @Produce json
+// @Param id path int true "Watchlist entry ID"
+// @Param request body WatchlistEntryRequest true "Watchlist entry"
+// @Success 200 {object} WatchlistEntryResponse "Entry updated"
+// @Failure 400 {object} ErrorResponse "Invalid entry ID or entry"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Entry not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/watchlist/{id} [put]
+func (h *AdminWatchlistHandler) UpdateWatchlistEntryHandler(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	entryID, ok := h.parseEntryID(w, r)
+	if !ok {
+		return
+	}
+	input, ok := h.parseEntryRequest(w, r)
+	if !ok {
+		return
+	}
+
+	entry, err := h.watchlistService.UpdateEntry(r.Context(), entryID, input)
+	if err != nil {
+		h.respondWithWatchlistError(w, err, entryID)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if auditErr := h.auditService.LogWatchlistEntryUpdated(r.Context(), adminUserID, entryID, entry.Label, entry.IsActive, ipAddress, userAgent); auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log watchlist entry update audit event", "entry_id", entryID, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusOK, toWatchlistEntryResponse(entry), h.logger)
+}
+
+// DeleteWatchlistEntryHandler handles DELETE /api/admin/watchlist/{id}
+// @Summary Delete a watchlist entry (Admin)
+// @Description Removes an entry, its photos and its links to reports. The reports themselves are kept.
+// @Tags admin/watchlist
+// @Produce json
+// @Param id path int true "Watchlist entry ID"
+// @Success 204 "Entry deleted"
+// @Failure 400 {object} ErrorResponse "Invalid entry ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Entry not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/watchlist/{id} [delete]
+func (h *AdminWatchlistHandler) DeleteWatchlistEntryHandler(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	entryID, ok := h.parseEntryID(w, r)
+	if !ok {
+		return
+	}
+
+	entry, err := h.watchlistService.DeleteEntry(r.Context(), entryID)
+	if err != nil {
+		h.respondWithWatchlistError(w, err, entryID)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if auditErr := h.auditService.LogWatchlistEntryDeleted(r.Context(), adminUserID, entryID, entry.Label, ipAddress, userAgent); auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log watchlist entry deletion audit event", "entry_id", entryID, "error", auditErr)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UploadWatchlistPhotoHandler handles POST /api/admin/watchlist/{id}/photos
+// @Summary Add a photo to a watchlist entry (Admin)
+// @Tags admin/watchlist
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Watchlist entry ID"
+// @Param photo formData file true "JPEG, PNG or WebP image, at most 10MB"
+// @Success 201 {object} WatchlistPhotoResponse "Photo added"
+// @Failure 400 {object} ErrorResponse "Invalid entry ID or photo"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Entry not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/watchlist/{id}/photos [post]
+func (h *AdminWatchlistHandler) UploadWatchlistPhotoHandler(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	entryID, ok := h.parseEntryID(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxWatchlistPhotoSize+1<<20)
+	file, _, err := r.FormFile("photo")
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "A photo file is required", h.logger, "error", err.Error())
+		return
+	}
+	defer file.Close()
+
+	photo, err := h.watchlistService.AddPhoto(r.Context(), entryID, adminUserID, file)
+	if err != nil {
+		h.respondWithWatchlistError(w, err, entryID)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusCreated, toWatchlistPhotoResponse(photo), h.logger)
+}
+
+// GetWatchlistPhotoHandler handles GET /api/admin/watchlist/{id}/photos/{photoId}
+// @Summary Get a watchlist photo (Admin)
+// @Tags admin/watchlist
+// @Produce image/jpeg,image/png,image/webp
+// @Param id path int true "Watchlist entry ID"
+// @Param photoId path int true "Photo ID"
+// @Success 200 {file} file "Image"
+// @Failure 400 {object} ErrorResponse "Invalid entry or photo ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Photo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/watchlist/{id}/photos/{photoId} [get]
+func (h *AdminWatchlistHandler) GetWatchlistPhotoHandler(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := h.parseEntryID(w, r)
+	if !ok {
+		return
+	}
+	photoID, ok := h.parsePhotoID(w, r)
+	if !ok {
+		return
+	}
+
+	photo, err := h.watchlistService.GetPhoto(r.Context(), entryID, photoID)
+	if err != nil {
+		h.respondWithWatchlistError(w, err, entryID)
+		return
+	}
+
+	f, err := os.Open(photo.StoragePath)
+	if err != nil {
+		RespondWithError(w, http.StatusNotFound, "Photo file not found", h.logger, "photo_id", photoID, "error", err.Error())
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", photo.MimeType)
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	http.ServeContent(w, r, photo.Filename, photo.CreatedAt.Time, f)
+}
+
+// DeleteWatchlistPhotoHandler handles DELETE /api/admin/watchlist/{id}/photos/{photoId}
+// @Summary Delete a watchlist photo (Admin)
+// @Tags admin/watchlist
+// @Param id path int true "Watchlist entry ID"
+// @Param photoId path int true "Photo ID"
+// @Success 204 "Photo deleted"
+// @Failure 400 {object} ErrorResponse "Invalid entry or photo ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Photo not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/watchlist/{id}/photos/{photoId} [delete]
+func (h *AdminWatchlistHandler) DeleteWatchlistPhotoHandler(w http.ResponseWriter, r *http.Request) {
+	entryID, ok := h.parseEntryID(w, r)
+	if !ok {
+		return
+	}
+	photoID, ok := h.parsePhotoID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.watchlistService.DeletePhoto(r.Context(), entryID, photoID); err != nil {
+		h.respondWithWatchlistError(w, err, entryID)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,258 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminWatchlist(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	admin, adminToken := app.createTestUserAndLogin(t, "+15550003901", "Test Admin", "admin")
+	_, reporterToken := app.createTestUserAndLogin(t, "+15550003902", "Reporting Owl", "owl")
+	onDuty, _ := app.createTestUserAndLogin(t, "+15550003903", "On Duty Owl", "owl")
+	_, _ = app.createTestUserAndLogin(t, "+15550003904", "Off Duty Owl", "owl")
+
+	schedule, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+		Name:            "Watchlist Patrol",
+		CronExpr:        "0 0 * * *",
+		DurationMinutes: 120,
+		Timezone:        sql.NullString{String: "UTC", Valid: true},
+	})
+	require.NoError(t, err)
+	now := time.Now().UTC()
+	_, err = app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+		UserID:     onDuty.UserID,
+		ScheduleID: schedule.ScheduleID,
+		ShiftStart: now.Add(-30 * time.Minute),
+		ShiftEnd:   now.Add(90 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	jsonBody := func(v interface{}) *bytes.Reader {
+		body, err := json.Marshal(v)
+		require.NoError(t, err)
+		return bytes.NewReader(body)
+	}
+	createEntry := func(payload map[string]interface{}) api.WatchlistEntryResponse {
+		rr := app.makeRequest(t, "POST", "/api/admin/watchlist", jsonBody(payload), adminToken)
+		require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+		var entry api.WatchlistEntryResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entry))
+		return entry
+	}
+	fileReport := func(payload map[string]interface{}) int64 {
+		rr := app.makeRequest(t, "POST", "/api/reports/off-shift", jsonBody(payload), reporterToken)
+		require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+		var report api.ReportResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		return report.ReportID
+	}
+	alertedUsers := func(entryID int64) []int64 {
+		rows, err := app.DB.Query(`SELECT user_id FROM outbox WHERE message_type = 'push' AND payload LIKE ?`,
+			fmt.Sprintf(`%%"entry_id":%d,%%`, entryID))
+		require.NoError(t, err)
+		defer rows.Close()
+		var userIDs []int64
+		for rows.Next() {
+			var userID int64
+			require.NoError(t, rows.Scan(&userID))
+			userIDs = append(userIDs, userID)
+		}
+		sort.Slice(userIDs, func(i, j int) bool { return userIDs[i] < userIDs[j] })
+		return userIDs
+	}
+
+	t.Run("invalid entries are rejected", func(t *testing.T) {
+		for name, payload := range map[string]map[string]interface{}{
+			"unknown kind":        {"kind": "boat", "label": "Speedboat"},
+			"missing label":       {"kind": "vehicle"},
+			"person registration": {"kind": "person", "label": "Tall man", "registration": "CA 123 456"},
+			"short registration":  {"kind": "vehicle", "label": "Bakkie", "registration": "CA1"},
+			"short keyword":       {"kind": "person", "label": "Tall man", "keywords": []string{"ab"}},
+		} {
+			rr := app.makeRequest(t, "POST", "/api/admin/watchlist", jsonBody(payload), adminToken)
+			assert.Equal(t, http.StatusBadRequest, rr.Code, "%s: %s", name, rr.Body.String())
+		}
+	})
+
+	bakkie := createEntry(map[string]interface{}{
+		"kind":         "vehicle",
+		"label":        "White Toyota bakkie",
+		"registration": "ca 123-456",
+		"description":  "White Hilux with a canopy, seen casing driveways",
+		"keywords":     []string{"White Bakkie", "white bakkie", " hilux canopy "},
+	})
+	assert.Equal(t, "CA123456", bakkie.Registration)
+	assert.Equal(t, []string{"white bakkie", "hilux canopy"}, bakkie.Keywords)
+	assert.True(t, bakkie.IsActive)
+
+	redCap := createEntry(map[string]interface{}{
+		"kind":     "person",
+		"label":    "Man in red cap",
+		"keywords": []string{"red cap"},
+	})
+	dormant := createEntry(map[string]interface{}{
+		"kind":      "person",
+		"label":     "Old entry",
+		"keywords":  []string{"school"},
+		"is_active": false,
+	})
+
+	t.Run("report text matches keywords and alerts on-duty owls and admins", func(t *testing.T) {
+		reportID := fileReport(map[string]interface{}{
+			"severity": 1,
+			"message":  "That white bakkie is back outside the school",
+		})
+
+		assert.Equal(t, []int64{admin.UserID, onDuty.UserID}, alertedUsers(bakkie.EntryID),
+			"the reporter and off-duty owls are not alerted")
+		assert.Empty(t, alertedUsers(redCap.EntryID))
+		assert.Empty(t, alertedUsers(dormant.EntryID), "inactive entries never match")
+
+		var matchedOn, term string
+		require.NoError(t, app.DB.QueryRow(`SELECT matched_on, matched_term FROM watchlist_matches WHERE entry_id = ? AND report_id = ?`,
+			bakkie.EntryID, reportID).Scan(&matchedOn, &term))
+		assert.Equal(t, service.WatchlistMatchKeyword, matchedOn)
+		assert.Equal(t, "white bakkie", term)
+	})
+
+	t.Run("keywords match whole words only", func(t *testing.T) {
+		fileReport(map[string]interface{}{"severity": 0, "message": "Someone with a scarred cap"})
+		assert.Empty(t, alertedUsers(redCap.EntryID))
+	})
+
+	var structuredReportID int64
+	t.Run("registration in structured fields matches", func(t *testing.T) {
+		var categoryID int64
+		require.NoError(t, app.DB.QueryRow(`SELECT category_id FROM incident_categories WHERE name = 'Suspicious vehicle'`).Scan(&categoryID))
+
+		structuredReportID = fileReport(map[string]interface{}{
+			"severity":    1,
+			"message":     "Parked with lights off",
+			"category_id": categoryID,
+			"category_fields": map[string]interface{}{
+				"vehicle_registration": "CA123 456",
+			},
+		})
+
+		var matchedOn string
+		require.NoError(t, app.DB.QueryRow(`SELECT matched_on FROM watchlist_matches WHERE entry_id = ? AND report_id = ?`,
+			bakkie.EntryID, structuredReportID).Scan(&matchedOn))
+		assert.Equal(t, service.WatchlistMatchRegistration, matchedOn)
+	})
+
+	t.Run("photos", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+		upload := func(filename string, content []byte) *httptest.ResponseRecorder {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			part, err := writer.CreateFormFile("photo", filename)
+			require.NoError(t, err)
+			_, err = part.Write(content)
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+
+			req, err := http.NewRequest("POST", fmt.Sprintf("/api/admin/watchlist/%d/photos", bakkie.EntryID), &body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req.Header.Set("Authorization", "Bearer "+adminToken)
+			rr := httptest.NewRecorder()
+			app.Router.ServeHTTP(rr, req)
+			return rr
+		}
+
+		rr := upload("notes.png", []byte("not really an image"))
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "content is checked, not the file name")
+
+		rr = upload("bakkie.png", buf.Bytes())
+		require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+		var photo api.WatchlistPhotoResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &photo))
+		assert.Equal(t, "image/png", photo.MimeType)
+
+		rr = app.makeRequest(t, "GET", photo.PhotoURL, nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+		assert.Equal(t, buf.Bytes(), rr.Body.Bytes())
+
+		rr = app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/watchlist/%d/photos/%d", redCap.EntryID, photo.PhotoID), nil, adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "photos are only served from their own entry")
+	})
+
+	t.Run("entry detail has a timeline of linked reports", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/watchlist/%d", bakkie.EntryID), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var detail api.WatchlistEntryDetailResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &detail))
+		require.Len(t, detail.Timeline, 2)
+		assert.Equal(t, structuredReportID, detail.Timeline[0].ReportID, "newest first")
+		assert.Equal(t, "Reporting Owl", detail.Timeline[0].ReporterName)
+		assert.Len(t, detail.Photos, 1)
+
+		rr = app.makeRequest(t, "GET", "/api/admin/watchlist", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var entries []api.WatchlistEntryResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+		require.Len(t, entries, 3)
+		assert.Equal(t, dormant.EntryID, entries[2].EntryID, "inactive entries are listed last")
+		for _, entry := range entries {
+			if entry.EntryID == bakkie.EntryID {
+				assert.Equal(t, int64(2), *entry.MatchCount)
+				assert.Equal(t, int64(1), *entry.PhotoCount)
+			}
+		}
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		rr := app.makeRequest(t, "PUT", fmt.Sprintf("/api/admin/watchlist/%d", redCap.EntryID), jsonBody(map[string]interface{}{
+			"kind":      "person",
+			"label":     "Man in red cap",
+			"keywords":  []string{"red cap"},
+			"is_active": false,
+		}), adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		fileReport(map[string]interface{}{"severity": 0, "message": "Guy in a red cap at the shop"})
+		assert.Empty(t, alertedUsers(redCap.EntryID), "deactivated entries stop matching")
+
+		rr = app.makeRequest(t, "DELETE", fmt.Sprintf("/api/admin/watchlist/%d", bakkie.EntryID), nil, adminToken)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		rr = app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/watchlist/%d", bakkie.EntryID), nil, adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		var reportCount int
+		require.NoError(t, app.DB.QueryRow(`SELECT COUNT(*) FROM reports WHERE report_id = ?`, structuredReportID).Scan(&reportCount))
+		assert.Equal(t, 1, reportCount, "linked reports are kept")
+
+		var auditCount int
+		require.NoError(t, app.DB.QueryRow(`SELECT COUNT(*) FROM audit_events WHERE entity_type = 'watchlist_entry'`).Scan(&auditCount))
+		assert.Equal(t, 5, auditCount)
+	})
+
+	t.Run("owls cannot manage the watchlist", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/watchlist", nil, reporterToken)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+}
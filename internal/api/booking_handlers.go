@@ -78,6 +78,11 @@ func toBookingResponse(booking db.Booking) *BookingResponse {
 		resp.CheckedInAt = &booking.CheckedInAt.Time
 	}
 
+	// Handle checked out at
+	if booking.CheckedOutAt.Valid {
+		resp.CheckedOutAt = &booking.CheckedOutAt.Time
+	}
+
 	// Handle created at
 	if booking.CreatedAt.Valid {
 		resp.CreatedAt = booking.CreatedAt.Time
@@ -105,6 +110,10 @@ func mapServiceErrorToHTTP(err error) (int, string) {
 		return http.StatusBadRequest, err.Error()
 	case errors.Is(err, service.ErrBookingCannotBeCancelled):
 		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, service.ErrNotCheckedIn):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, service.ErrAlreadyCheckedOut):
+		return http.StatusConflict, err.Error()
 	case errors.Is(err, service.ErrInternalServer):
 		return http.StatusInternalServerError, "Internal server error"
 	default:
@@ -600,6 +609,50 @@ func (h *BookingHandler) MarkCheckInHandler(w http.ResponseWriter, r *http.Reque
 	RespondWithJSON(w, http.StatusOK, toBookingResponse(updatedBooking), h.logger)
 }
 
+// MarkCheckOutHandler handles POST /bookings/{id}/checkout
+// @Summary Check out of a booking
+// @Description Marks the end of a user's patrol for a checked-in booking and stops any live location sharing
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} BookingResponse "Check-out recorded successfully"
+// @Failure 400 {object} ErrorResponse "Invalid booking ID or booking not checked in"
+// @Failure 401 {object} ErrorResponse "Unauthorized - authentication required"
+// @Failure 403 {object} ErrorResponse "Not authorized to check out of this booking"
+// @Failure 404 {object} ErrorResponse "Booking not found"
+// @Failure 409 {object} ErrorResponse "Booking already checked out"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /bookings/{id}/checkout [post]
+func (h *BookingHandler) MarkCheckOutHandler(w http.ResponseWriter, r *http.Request) {
+	bookingID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid booking ID", h.logger)
+		return
+	}
+
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User authentication required", h.logger)
+		return
+	}
+
+	updatedBooking, err := h.service.MarkCheckOut(r.Context(), bookingID, userID)
+	if err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to mark check-out", "booking_id", bookingID, "user_id", userID, "error", err)
+		status, detail := mapServiceErrorToHTTP(err)
+		RespondWithError(w, status, detail, h.logger)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if auditErr := h.auditService.LogBookingCheckedOut(r.Context(), userID, bookingID, updatedBooking.ScheduleID, updatedBooking.ShiftEnd.Format(time.RFC3339), ipAddress, userAgent); auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log booking check-out audit event", "booking_id", bookingID, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusOK, toBookingResponse(updatedBooking), h.logger)
+}
+
 // CreateBookingHandler handles POST /bookings (legacy chi handler for tests)
 // @Summary Create a new booking
 // @Description Create a new booking for a shift
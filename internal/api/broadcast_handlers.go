@@ -1,34 +1,42 @@
 package api
 
 import (
+	"database/sql"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
 	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
 )
 
 // BroadcastHandler handles user-facing broadcast operations.
 type BroadcastHandler struct {
-	querier db.Querier
-	logger  *slog.Logger
+	querier          db.Querier
+	broadcastService *service.BroadcastService
+	logger           *slog.Logger
 }
 
 // NewBroadcastHandler creates a new BroadcastHandler.
-func NewBroadcastHandler(querier db.Querier, logger *slog.Logger) *BroadcastHandler {
+func NewBroadcastHandler(querier db.Querier, broadcastService *service.BroadcastService, logger *slog.Logger) *BroadcastHandler {
 	return &BroadcastHandler{
-		querier: querier,
-		logger:  logger.With("handler", "BroadcastHandler"),
+		querier:          querier,
+		broadcastService: broadcastService,
+		logger:           logger.With("handler", "BroadcastHandler"),
 	}
 }
 
 // UserBroadcastResponse represents a broadcast for user consumption.
 type UserBroadcastResponse struct {
-	ID        int64     `json:"id"`
-	Title     string    `json:"title"`
-	Message   string    `json:"message"`
-	Audience  string    `json:"audience"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int64      `json:"id"`
+	Title     string     `json:"title"`
+	Message   string     `json:"message"`
+	Audience  string     `json:"audience"`
+	CreatedAt time.Time  `json:"created_at"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
 }
 
 // ListUserBroadcasts handles GET /api/broadcasts
@@ -56,10 +64,27 @@ func (h *BroadcastHandler) ListUserBroadcasts(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	// Broadcasts addressed to the user, with whether they have been read
+	receipts, err := h.querier.ListUserBroadcastReceipts(r.Context(), userID)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to list broadcast receipts", h.logger, "error", err)
+		return
+	}
+	readAt := make(map[int64]sql.NullTime, len(receipts))
+	messages := make(map[int64]string)
+	for _, receipt := range receipts {
+		readAt[receipt.BroadcastID] = receipt.ReadAt
+		if receipt.Message.Valid {
+			// The message as rendered for this user
+			messages[receipt.BroadcastID] = receipt.Message.String
+		}
+	}
+
 	// Filter broadcasts based on user role and audience
 	var userBroadcasts []UserBroadcastResponse
 	for _, broadcast := range broadcasts {
-		if h.shouldUserSeeBroadcast(user, broadcast) {
+		read, isRecipient := readAt[broadcast.BroadcastID]
+		if (broadcast.Status == "sent" && isRecipient) || h.shouldUserSeeBroadcast(user, broadcast) {
 			// Handle sql.NullTime properly
 			var createdAt time.Time
 			if broadcast.CreatedAt.Valid {
@@ -67,13 +92,19 @@ func (h *BroadcastHandler) ListUserBroadcasts(w http.ResponseWriter, r *http.Req
 			} else {
 				createdAt = time.Now() // Fallback to current time if null
 			}
+			message, ok := messages[broadcast.BroadcastID]
+			if !ok {
+				message = broadcast.Message
+			}
 
 			userBroadcasts = append(userBroadcasts, UserBroadcastResponse{
 				ID:        broadcast.BroadcastID,
 				Title:     broadcast.Title,
-				Message:   broadcast.Message,
+				Message:   message,
 				Audience:  broadcast.Audience,
 				CreatedAt: createdAt,
+				Read:      read.Valid,
+				ReadAt:    nullTimeToPointer(read),
 			})
 		}
 	}
@@ -95,10 +126,44 @@ func (h *BroadcastHandler) shouldUserSeeBroadcast(user db.GetUserByIDRow, broadc
 		return user.Role == "admin"
 	case "owls":
 		return user.Role == "owl" || user.Role == ""
-	case "active":
-		// For now, show to all users. In future, check last activity
-		return true
 	default:
+		// Active and custom audiences are only seen by their stored recipients
 		return false
 	}
 }
+
+// AcknowledgeBroadcast handles POST /api/broadcasts/{id}/read
+// @Summary Mark a broadcast as read
+// @Description Records that the caller has read a broadcast they were sent. Acknowledging it again keeps the first time.
+// @Tags broadcasts
+// @Param id path int true "Broadcast ID"
+// @Success 204 "Broadcast marked as read"
+// @Failure 400 {object} ErrorResponse "Invalid broadcast ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Broadcast not sent to the caller"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/broadcasts/{id}/read [post]
+func (h *BroadcastHandler) AcknowledgeBroadcast(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+	idStr := r.PathValue("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid broadcast ID", h.logger, "id", idStr)
+		return
+	}
+
+	if err := h.broadcastService.MarkRead(r.Context(), id, userID); err != nil {
+		if errors.Is(err, service.ErrBroadcastNotFound) {
+			RespondWithError(w, http.StatusNotFound, "Broadcast not found", h.logger, "id", id, "user_id", userID)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to mark broadcast as read", h.logger, "error", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,124 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"night-owls-go/internal/api"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcastReceipts(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	_, adminToken := app.createTestUserAndLogin(t, "+15550008001", "Test Admin", "admin")
+	reader, readerToken := app.createTestUserAndLogin(t, "+15550008002", "Reader Owl", "owl")
+	smsOwl, smsToken := app.createTestUserAndLogin(t, "+15550008003", "SMS Owl", "owl")
+	broadcastService := app.BroadcastService
+
+	rr := app.makeRequest(t, "PUT", "/api/user/notification-preferences", bytes.NewBufferString(`{"channel": "sms", "reminders": true, "broadcasts": true, "coverage_alerts": true, "report_updates": true}`), smsToken)
+	require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+	rr = app.makeRequest(t, "POST", "/api/admin/broadcasts", bytes.NewBufferString(`{"title": "Road closed", "message": "Use the north gate tonight", "audience": "owls", "push_enabled": true, "urgent": true}`), adminToken)
+	require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+	var broadcast api.BroadcastResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &broadcast))
+	broadcastPath := fmt.Sprintf("/api/admin/broadcasts/%d", broadcast.BroadcastID)
+
+	rr = app.makeRequest(t, "POST", broadcastPath+"/resend-unread", nil, adminToken)
+	assert.Equal(t, http.StatusConflict, rr.Code, "a broadcast cannot be re-sent before it is sent")
+
+	_, err := broadcastService.ProcessPendingBroadcasts(ctx)
+	require.NoError(t, err)
+
+	// The push to the reader goes through; the SMS owl's message fails for good
+	_, err = app.DB.Exec(`UPDATE outbox SET status = 'sent', sent_at = CURRENT_TIMESTAMP WHERE user_id = ? AND message_type = 'push'`, reader.UserID)
+	require.NoError(t, err)
+	_, err = app.DB.Exec(`UPDATE outbox SET status = 'permanently_failed' WHERE user_id = ? AND message_type = 'sms'`, smsOwl.UserID)
+	require.NoError(t, err)
+
+	getReceipts := func(t *testing.T) service.BroadcastReceipts {
+		t.Helper()
+		rr := app.makeRequest(t, "GET", broadcastPath+"/receipts", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var receipts service.BroadcastReceipts
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &receipts))
+		return receipts
+	}
+
+	t.Run("recipients acknowledge reading", func(t *testing.T) {
+		readPath := fmt.Sprintf("/api/broadcasts/%d/read", broadcast.BroadcastID)
+		rr := app.makeRequest(t, "POST", readPath, nil, readerToken)
+		require.Equal(t, http.StatusNoContent, rr.Code, "Response: %s", rr.Body.String())
+		rr = app.makeRequest(t, "POST", readPath, nil, readerToken)
+		assert.Equal(t, http.StatusNoContent, rr.Code, "acknowledging twice is harmless")
+		rr = app.makeRequest(t, "POST", readPath, nil, adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "the admin was not a recipient")
+
+		rr = app.makeRequest(t, "GET", "/api/broadcasts", nil, readerToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var broadcasts []api.UserBroadcastResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &broadcasts))
+		require.Len(t, broadcasts, 1)
+		assert.True(t, broadcasts[0].Read)
+		assert.NotNil(t, broadcasts[0].ReadAt)
+	})
+
+	t.Run("receipts break down delivery and reads", func(t *testing.T) {
+		receipts := getReceipts(t)
+		assert.Equal(t, 2, receipts.Recipients)
+		assert.Equal(t, 1, receipts.Delivered)
+		assert.Equal(t, 1, receipts.Failed)
+		assert.Equal(t, 1, receipts.Read)
+		assert.Equal(t, 1, receipts.Unread)
+		require.Len(t, receipts.Receipts, 2)
+		assert.Equal(t, service.BroadcastDeliveryDelivered, receipts.Receipts[0].Delivery)
+		assert.Equal(t, service.OutboxMessagePush, receipts.Receipts[0].Channel)
+		assert.NotNil(t, receipts.Receipts[0].ReadAt)
+		assert.Equal(t, service.BroadcastDeliveryFailed, receipts.Receipts[1].Delivery)
+		assert.Nil(t, receipts.Receipts[1].ReadAt)
+	})
+
+	t.Run("broadcast counts follow deliveries", func(t *testing.T) {
+		_, err := broadcastService.ProcessPendingBroadcasts(ctx)
+		require.NoError(t, err)
+
+		rr := app.makeRequest(t, "GET", broadcastPath, nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var refreshed api.BroadcastResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &refreshed))
+		assert.Equal(t, int64(1), refreshed.SentCount)
+		assert.Equal(t, int64(1), refreshed.FailedCount)
+	})
+
+	t.Run("unread recipients are re-sent by SMS once", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", broadcastPath+"/resend-unread", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var resend api.ResendBroadcastResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resend))
+		assert.Equal(t, 1, resend.Queued)
+
+		var recipient, category string
+		require.NoError(t, app.DB.QueryRow(`SELECT recipient, category FROM outbox WHERE user_id = ? AND status = 'pending' AND message_type = 'sms'`, smsOwl.UserID).Scan(&recipient, &category))
+		assert.Equal(t, "+15550008003", recipient)
+		assert.Equal(t, service.NotificationCategoryUrgent, category)
+
+		receipts := getReceipts(t)
+		assert.NotNil(t, receipts.Receipts[1].ResentAt)
+		assert.Equal(t, service.BroadcastDeliveryPending, receipts.Receipts[1].ResendDelivery)
+
+		rr = app.makeRequest(t, "POST", broadcastPath+"/resend-unread", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resend))
+		assert.Zero(t, resend.Queued)
+	})
+}
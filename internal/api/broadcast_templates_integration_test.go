@@ -0,0 +1,206 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcastTemplates(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	_, adminToken := app.createTestUserAndLogin(t, "+15550009001", "Test Admin", "admin")
+	booked, bookedToken := app.createTestUserAndLogin(t, "+15550009002", "Thandi Mokoena", "owl")
+	idle, _ := app.createTestUserAndLogin(t, "+15550009003", "Pieter", "owl")
+	broadcastService := app.BroadcastService
+
+	// Three noon shifts from tomorrow, one of them booked
+	_, err := app.DB.Exec(`DELETE FROM schedules`)
+	require.NoError(t, err)
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Truncate(24 * time.Hour)
+	schedule, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+		Name:            "Noon Patrol",
+		CronExpr:        "0 12 * * *",
+		StartDate:       sql.NullTime{Time: tomorrow, Valid: true},
+		EndDate:         sql.NullTime{Time: tomorrow.AddDate(0, 0, 2), Valid: true},
+		DurationMinutes: 60,
+		Timezone:        sql.NullString{String: "UTC", Valid: true},
+	})
+	require.NoError(t, err)
+	shiftStart := tomorrow.Add(12 * time.Hour)
+	_, err = app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+		UserID:     booked.UserID,
+		ScheduleID: schedule.ScheduleID,
+		ShiftStart: shiftStart,
+		ShiftEnd:   shiftStart.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	createBroadcast := func(t *testing.T, body string) (int, api.BroadcastResponse) {
+		t.Helper()
+		rr := app.makeRequest(t, "POST", "/api/admin/broadcasts", bytes.NewBufferString(body), adminToken)
+		var broadcast api.BroadcastResponse
+		if rr.Code == http.StatusCreated {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &broadcast))
+		}
+		return rr.Code, broadcast
+	}
+
+	var template db.BroadcastTemplate
+	t.Run("templates are managed by admins", func(t *testing.T) {
+		body := `{"name": "Shift nudge", "title": "Shifts need owls", "message": "Hi {{.first_name}}, your next shift: {{.next_shift}}. {{.unfilled_slots}} shifts are open this week."}`
+		rr := app.makeRequest(t, "POST", "/api/admin/broadcast-templates", bytes.NewBufferString(body), adminToken)
+		require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &template))
+
+		rr = app.makeRequest(t, "POST", "/api/admin/broadcast-templates", bytes.NewBufferString(body), adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = app.makeRequest(t, "POST", "/api/admin/broadcast-templates", bytes.NewBufferString(`{"name": "Typo", "title": "Hi", "message": "Hi {{.nickname}}"}`), adminToken)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "unknown variables are refused")
+
+		templatePath := fmt.Sprintf("/api/admin/broadcast-templates/%d", template.TemplateID)
+		rr = app.makeRequest(t, "GET", templatePath, nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = app.makeRequest(t, "GET", "/api/admin/broadcast-templates", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var templates []db.BroadcastTemplate
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &templates))
+		assert.Len(t, templates, 1)
+
+		rr = app.makeRequest(t, "POST", "/api/admin/broadcast-templates", bytes.NewBufferString(`{"name": "Spare", "title": "Spare", "message": "Spare"}`), adminToken)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var spare db.BroadcastTemplate
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &spare))
+		sparePath := fmt.Sprintf("/api/admin/broadcast-templates/%d", spare.TemplateID)
+		rr = app.makeRequest(t, "PUT", sparePath, bytes.NewBufferString(`{"name": "Shift nudge", "title": "Spare", "message": "Spare"}`), adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		rr = app.makeRequest(t, "DELETE", sparePath, nil, adminToken)
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		rr = app.makeRequest(t, "GET", sparePath, nil, adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("variables are filled in for each recipient", func(t *testing.T) {
+		code, broadcast := createBroadcast(t, fmt.Sprintf(`{"template_id": %d, "audience": "owls", "push_enabled": true}`, template.TemplateID))
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, template.Title, broadcast.Title)
+
+		_, err := broadcastService.ProcessPendingBroadcasts(ctx)
+		require.NoError(t, err)
+
+		payloadFor := func(userID int64) string {
+			var payload string
+			require.NoError(t, app.DB.QueryRow(`SELECT payload FROM outbox WHERE user_id = ? AND message_type = 'push'`, userID).Scan(&payload))
+			var push map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(payload), &push))
+			return push["body"].(string)
+		}
+		bookedMessage := fmt.Sprintf("Hi Thandi, your next shift: %s (Noon Patrol). 2 shifts are open this week.", shiftStart.Format("Mon 2 Jan at 15:04"))
+		assert.Equal(t, bookedMessage, payloadFor(booked.UserID))
+		assert.Equal(t, "Hi Pieter, your next shift: none booked. 2 shifts are open this week.", payloadFor(idle.UserID))
+
+		rr := app.makeRequest(t, "GET", "/api/broadcasts", nil, bookedToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var broadcasts []api.UserBroadcastResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &broadcasts))
+		require.Len(t, broadcasts, 1)
+		assert.Equal(t, bookedMessage, broadcasts[0].Message)
+	})
+
+	t.Run("invalid broadcasts are refused", func(t *testing.T) {
+		for _, body := range []string{
+			`{"title": "Hi", "message": "Hi {{.nickname}}", "audience": "owls"}`,
+			`{"title": "Hi", "message": "Hi {{.name", "audience": "owls"}`,
+			`{"template_id": 9999, "audience": "owls"}`,
+			`{"title": "Weekly", "message": "Sign up", "audience": "owls", "recurrence": "every sunday"}`,
+		} {
+			code, _ := createBroadcast(t, body)
+			assert.Equal(t, http.StatusBadRequest, code, "body %s", body)
+		}
+	})
+
+	t.Run("braces that are not variables are sent as written", func(t *testing.T) {
+		code, broadcast := createBroadcast(t, `{"title": "Braai", "message": "Braai at the hall {{ bring a chair }}", "audience": "owls"}`)
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, "Braai at the hall {{ bring a chair }}", broadcast.Message)
+	})
+
+	t.Run("recurring broadcasts send a run on each occurrence", func(t *testing.T) {
+		code, series := createBroadcast(t, `{"title": "Weekly roster", "message": "Hi {{.first_name}}, book your shifts", "audience": "owls", "push_enabled": true, "recurrence": "0 18 * * 0"}`)
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, service.BroadcastStatusRecurring, series.Status)
+		assert.Equal(t, "0 18 * * 0", series.Recurrence)
+		require.NotNil(t, series.ScheduledAt)
+		assert.Equal(t, time.Sunday, series.ScheduledAt.Weekday())
+		assert.Equal(t, 18, series.ScheduledAt.Hour())
+
+		// Not due yet: nothing is sent
+		_, err := broadcastService.ProcessPendingBroadcasts(ctx)
+		require.NoError(t, err)
+		var runs int
+		require.NoError(t, app.DB.QueryRow(`SELECT COUNT(*) FROM broadcasts WHERE parent_broadcast_id = ?`, series.BroadcastID).Scan(&runs))
+		assert.Zero(t, runs)
+
+		_, err = app.DB.Exec(`UPDATE broadcasts SET scheduled_at = ? WHERE broadcast_id = ?`, time.Now().UTC().Add(-time.Minute), series.BroadcastID)
+		require.NoError(t, err)
+		_, err = broadcastService.ProcessPendingBroadcasts(ctx)
+		require.NoError(t, err)
+
+		var runID int64
+		var runStatus string
+		require.NoError(t, app.DB.QueryRow(`SELECT broadcast_id, status FROM broadcasts WHERE parent_broadcast_id = ?`, series.BroadcastID).Scan(&runID, &runStatus))
+		assert.Equal(t, "sent", runStatus)
+
+		rr := app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/broadcasts/%d", runID), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var run api.BroadcastResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &run))
+		require.NotNil(t, run.ParentID)
+		assert.Equal(t, series.BroadcastID, *run.ParentID)
+		assert.Equal(t, int64(2), run.RecipientCount)
+
+		rr = app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/broadcasts/%d", series.BroadcastID), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var advanced api.BroadcastResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &advanced))
+		assert.Equal(t, service.BroadcastStatusRecurring, advanced.Status)
+		assert.True(t, advanced.ScheduledAt.After(time.Now()), "the series moves on to its next run")
+	})
+
+	t.Run("unsent broadcasts can be rescheduled", func(t *testing.T) {
+		later := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+		code, broadcast := createBroadcast(t, fmt.Sprintf(`{"title": "Meeting", "message": "Hall at 7", "audience": "owls", "scheduled_at": %q}`, time.Now().UTC().Add(time.Hour).Format(time.RFC3339)))
+		require.Equal(t, http.StatusCreated, code)
+
+		schedulePath := fmt.Sprintf("/api/admin/broadcasts/%d/schedule", broadcast.BroadcastID)
+		rr := app.makeRequest(t, "PUT", schedulePath, bytes.NewBufferString(fmt.Sprintf(`{"scheduled_at": %q}`, later.Format(time.RFC3339))), adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var rescheduled api.BroadcastResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rescheduled))
+		assert.True(t, later.Equal(*rescheduled.ScheduledAt))
+
+		rr = app.makeRequest(t, "PUT", "/api/admin/broadcasts/9999/schedule", bytes.NewBufferString(`{"scheduled_at": "2030-01-01T00:00:00Z"}`), adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		_, err := app.DB.Exec(`UPDATE broadcasts SET status = 'sent' WHERE broadcast_id = ?`, broadcast.BroadcastID)
+		require.NoError(t, err)
+		rr = app.makeRequest(t, "PUT", schedulePath, bytes.NewBufferString(fmt.Sprintf(`{"scheduled_at": %q}`, later.Format(time.RFC3339))), adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+}
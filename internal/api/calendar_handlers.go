@@ -19,17 +19,19 @@ import (
 
 // CalendarHandler handles calendar-related operations
 type CalendarHandler struct {
-	bookingService *service.BookingService
-	querier        db.Querier
-	logger         *slog.Logger
+	bookingService  *service.BookingService
+	handoverService *service.HandoverService
+	querier         db.Querier
+	logger          *slog.Logger
 }
 
 // NewCalendarHandler creates a new CalendarHandler
-func NewCalendarHandler(bookingService *service.BookingService, querier db.Querier, logger *slog.Logger) *CalendarHandler {
+func NewCalendarHandler(bookingService *service.BookingService, handoverService *service.HandoverService, querier db.Querier, logger *slog.Logger) *CalendarHandler {
 	return &CalendarHandler{
-		bookingService: bookingService,
-		querier:        querier,
-		logger:         logger.With("handler", "CalendarHandler"),
+		bookingService:  bookingService,
+		handoverService: handoverService,
+		querier:         querier,
+		logger:          logger.With("handler", "CalendarHandler"),
 	}
 }
 
@@ -151,8 +153,25 @@ func (h *CalendarHandler) ServeCalendarFeed(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// Handover notes left for upcoming shifts go into the event descriptions
+	handovers := make(map[int64][]db.ListIncomingHandoverNotesRow)
+	now := time.Now()
+	for _, booking := range bookings {
+		if !booking.ShiftStart.After(now) {
+			continue
+		}
+		notes, err := h.handoverService.ListReceivedNotes(r.Context(), booking.BookingID, booking.ShiftStart)
+		if err != nil {
+			h.logger.WarnContext(r.Context(), "Failed to get handover notes for calendar feed", "booking_id", booking.BookingID, "error", err)
+			continue
+		}
+		if len(notes) > 0 {
+			handovers[booking.BookingID] = notes
+		}
+	}
+
 	// Generate calendar feed
-	calendarData := utils.GenerateUserCalendarFeed(bookings, userID)
+	calendarData := utils.GenerateUserCalendarFeed(bookings, handovers, userID)
 
 	// Set proper headers for calendar feed
 	w.Header().Set("Content-Type", calendarData.MIME)
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"database/sql"
+	"encoding/json"
 	db "night-owls-go/internal/db/sqlc_generated"
 	"time"
 )
@@ -70,13 +72,32 @@ func ToReportResponse(report db.Report) ReportResponse {
 		bookingID = report.BookingID.Int64
 	}
 
+	var categoryID *int64
+	if report.CategoryID.Valid {
+		categoryID = &report.CategoryID.Int64
+	}
+
 	return ReportResponse{
-		ReportID:  report.ReportID,
-		BookingID: bookingID,
-		Severity:  report.Severity,
-		Message:   message,
-		CreatedAt: createdAt,
+		ReportID:       report.ReportID,
+		BookingID:      bookingID,
+		Severity:       report.Severity,
+		Message:        message,
+		CategoryID:     categoryID,
+		CategoryFields: ToCategoryFieldValues(report.CategoryFields),
+		CreatedAt:      createdAt,
+	}
+}
+
+// ToCategoryFieldValues decodes the structured category field values stored with a report
+func ToCategoryFieldValues(fields sql.NullString) map[string]interface{} {
+	if !fields.Valid || fields.String == "" {
+		return nil
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(fields.String), &values); err != nil {
+		return nil
 	}
+	return values
 }
 
 // ToScheduleResponse converts a database Schedule to an API-friendly response
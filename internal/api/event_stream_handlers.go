@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"night-owls-go/internal/service"
+)
+
+// eventStreamHeartbeat is how often a comment is written to keep idle
+// connections open through proxies.
+const eventStreamHeartbeat = 25 * time.Second
+
+// EventStreamHandler streams domain events to connected clients using Server-Sent Events.
+type EventStreamHandler struct {
+	broker *service.EventBroker
+	logger *slog.Logger
+}
+
+// NewEventStreamHandler creates a new EventStreamHandler.
+func NewEventStreamHandler(broker *service.EventBroker, logger *slog.Logger) *EventStreamHandler {
+	return &EventStreamHandler{
+		broker: broker,
+		logger: logger.With("handler", "EventStreamHandler"),
+	}
+}
+
+// StreamEventsHandler handles GET /api/events
+// @Summary Stream live events
+// @Description Server-Sent Events stream of booking, check-in, report, broadcast and SOS events. Admins receive every event. Owls receive events about their own bookings, reports, broadcasts sent to them and SOS alerts they were sent, plus anonymous shift slot changes. Reconnecting clients send the Last-Event-ID header (or the last_event_id query parameter) to receive events they missed; a stream.reset event means some were lost and data should be refetched.
+// @Tags events
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "ID of the last event received"
+// @Param last_event_id query int false "ID of the last event received, for clients that cannot set headers"
+// @Success 200 {string} string "Event stream"
+// @Failure 400 {object} ErrorResponse "Invalid last event ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Streaming not supported"
+// @Security BearerAuth
+// @Router /api/events [get]
+func (h *EventStreamHandler) StreamEventsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+	role, _ := r.Context().Value(UserRoleKey).(string)
+
+	lastEventIDStr := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if lastEventIDStr == "" {
+		lastEventIDStr = r.URL.Query().Get("last_event_id")
+	}
+	var lastEventID int64
+	if lastEventIDStr != "" {
+		parsed, err := strconv.ParseInt(lastEventIDStr, 10, 64)
+		if err != nil || parsed < 0 {
+			RespondWithError(w, http.StatusBadRequest, "Invalid last event ID", h.logger, "last_event_id", lastEventIDStr)
+			return
+		}
+		lastEventID = parsed
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		RespondWithError(w, http.StatusInternalServerError, "Streaming not supported", h.logger)
+		return
+	}
+
+	// The server write timeout would otherwise cut every stream off
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.DebugContext(r.Context(), "Could not clear write deadline for event stream", "error", err)
+	}
+
+	replay, events := h.broker.Subscribe(r.Context(), userID, role == "admin", lastEventID)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, event := range replay {
+		if err := h.writeEvent(w, event); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	h.logger.InfoContext(r.Context(), "Event stream opened", "user_id", userID, "last_event_id", lastEventID, "replayed", len(replay))
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := h.writeEvent(w, event); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeEvent writes one event in the SSE wire format.
+func (h *EventStreamHandler) writeEvent(w http.ResponseWriter, event service.StreamEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		h.logger.Error("Failed to marshal stream event", "event_id", event.ID, "type", event.Type, "error", err)
+		return nil
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
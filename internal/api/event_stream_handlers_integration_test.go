@@ -0,0 +1,170 @@
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openEventStream connects to /api/events and returns a channel of parsed events
+// and a function that disconnects.
+func openEventStream(t *testing.T, server *httptest.Server, token string, lastEventID int64) (<-chan service.StreamEvent, context.CancelFunc) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if lastEventID > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatInt(lastEventID, 10))
+	}
+
+	resp, err := server.Client().Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan service.StreamEvent, 32)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var event service.StreamEvent
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err == nil {
+				events <- event
+			}
+		}
+	}()
+	return events, cancel
+}
+
+func nextStreamEvent(t *testing.T, events <-chan service.StreamEvent) service.StreamEvent {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "event stream closed")
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for stream event")
+		return service.StreamEvent{}
+	}
+}
+
+func assertNoStreamEvent(t *testing.T, events <-chan service.StreamEvent) {
+	t.Helper()
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected stream event %s", event.Type)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestEventStream_RoleFilteringAndResume(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	server := httptest.NewServer(app.Router)
+	defer server.Close()
+
+	ctx := context.Background()
+	_, adminToken := app.createTestUserAndLogin(t, "+15550003301", "Test Admin", "admin")
+	owl, owlToken := app.createTestUserAndLogin(t, "+15550003302", "Booking Owl", "owl")
+	_, otherToken := app.createTestUserAndLogin(t, "+15550003303", "Other Owl", "owl")
+
+	schedule, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+		Name:            "Stream Patrol",
+		CronExpr:        "0 0 * * *",
+		DurationMinutes: 120,
+		Timezone:        sql.NullString{String: "UTC", Valid: true},
+	})
+	require.NoError(t, err)
+
+	adminEvents, closeAdmin := openEventStream(t, server, adminToken, 0)
+	defer closeAdmin()
+	owlEvents, closeOwl := openEventStream(t, server, owlToken, 0)
+	defer closeOwl()
+	otherEvents, closeOther := openEventStream(t, server, otherToken, 0)
+
+	shiftStart := time.Now().UTC().Truncate(24 * time.Hour).Add(48 * time.Hour)
+	booking, err := app.BookingService.CreateBooking(ctx, owl.UserID, schedule.ScheduleID, shiftStart, sql.NullString{}, sql.NullString{})
+	require.NoError(t, err)
+
+	var lastSeen int64
+	t.Run("bookings are shared without revealing who booked", func(t *testing.T) {
+		event := nextStreamEvent(t, adminEvents)
+		assert.Equal(t, service.EventBookingCreated, event.Type)
+		data := event.Data.(map[string]interface{})
+		assert.EqualValues(t, booking.BookingID, data["booking_id"])
+		assert.EqualValues(t, owl.UserID, data["user_id"])
+
+		event = nextStreamEvent(t, owlEvents)
+		assert.EqualValues(t, booking.BookingID, event.Data.(map[string]interface{})["booking_id"])
+
+		event = nextStreamEvent(t, otherEvents)
+		assert.Equal(t, service.EventBookingCreated, event.Type)
+		data = event.Data.(map[string]interface{})
+		assert.NotContains(t, data, "booking_id")
+		assert.NotContains(t, data, "user_id")
+		assert.EqualValues(t, schedule.ScheduleID, data["schedule_id"])
+		lastSeen = event.ID
+	})
+
+	// The other owl drops off and misses the next events
+	closeOther()
+
+	t.Run("reports reach admins and the reporter only", func(t *testing.T) {
+		body, err := json.Marshal(map[string]interface{}{"severity": 0, "message": "Gate left open"})
+		require.NoError(t, err)
+		rr := app.makeRequest(t, "POST", "/api/reports/off-shift", bytes.NewReader(body), owlToken)
+		require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+
+		event := nextStreamEvent(t, adminEvents)
+		assert.Equal(t, service.EventReportCreated, event.Type)
+		assert.Equal(t, "Gate left open", event.Data.(map[string]interface{})["message"])
+		assert.Equal(t, service.EventReportCreated, nextStreamEvent(t, owlEvents).Type)
+	})
+
+	require.NoError(t, app.BookingService.CancelBooking(ctx, booking.BookingID, owl.UserID))
+	assert.Equal(t, service.EventBookingCancelled, nextStreamEvent(t, adminEvents).Type)
+
+	t.Run("a reconnecting owl resumes from its last event", func(t *testing.T) {
+		resumed, closeResumed := openEventStream(t, server, otherToken, lastSeen)
+		defer closeResumed()
+
+		event := nextStreamEvent(t, resumed)
+		assert.Equal(t, service.EventBookingCancelled, event.Type, "the report should be filtered out of the replay")
+		assert.Greater(t, event.ID, lastSeen)
+		assertNoStreamEvent(t, resumed)
+	})
+
+	t.Run("a reconnect from before the buffer gets a reset", func(t *testing.T) {
+		resumed, closeResumed := openEventStream(t, server, otherToken, 1)
+		defer closeResumed()
+
+		assert.Equal(t, service.EventStreamReset, nextStreamEvent(t, resumed).Type)
+		assert.Equal(t, service.EventBookingCreated, nextStreamEvent(t, resumed).Type)
+	})
+
+	t.Run("the last event ID must be a number", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/events?last_event_id=abc", nil, owlToken)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
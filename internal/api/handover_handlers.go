@@ -0,0 +1,238 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+)
+
+// HandoverHandler handles shift handover notes between consecutive patrols.
+type HandoverHandler struct {
+	handoverService *service.HandoverService
+	logger          *slog.Logger
+}
+
+// NewHandoverHandler creates a new HandoverHandler.
+func NewHandoverHandler(handoverService *service.HandoverService, logger *slog.Logger) *HandoverHandler {
+	return &HandoverHandler{
+		handoverService: handoverService,
+		logger:          logger.With("handler", "HandoverHandler"),
+	}
+}
+
+// HandoverNoteRequest is the structured note the outgoing owl leaves. At least one field is required.
+type HandoverNoteRequest struct {
+	OpenIssues   string `json:"open_issues,omitempty"`
+	VehiclesSeen string `json:"vehicles_seen,omitempty"`
+	GatesOpen    string `json:"gates_open,omitempty"`
+	Notes        string `json:"notes,omitempty"`
+}
+
+// HandoverNoteResponse is a handover note. The author and shift details are
+// included when the note is shown to a later patrol.
+type HandoverNoteResponse struct {
+	HandoverID   int64      `json:"handover_id"`
+	BookingID    int64      `json:"booking_id"`
+	UserID       int64      `json:"user_id"`
+	UserName     string     `json:"user_name,omitempty"`
+	ScheduleID   int64      `json:"schedule_id,omitempty"`
+	ScheduleName string     `json:"schedule_name,omitempty"`
+	ShiftStart   *time.Time `json:"shift_start,omitempty"`
+	ShiftEnd     *time.Time `json:"shift_end,omitempty"`
+	OpenIssues   string     `json:"open_issues"`
+	VehiclesSeen string     `json:"vehicles_seen"`
+	GatesOpen    string     `json:"gates_open"`
+	Notes        string     `json:"notes"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+func toHandoverNoteResponse(note db.HandoverNote) HandoverNoteResponse {
+	response := HandoverNoteResponse{
+		HandoverID:   note.HandoverID,
+		BookingID:    note.BookingID,
+		UserID:       note.UserID,
+		OpenIssues:   note.OpenIssues,
+		VehiclesSeen: note.VehiclesSeen,
+		GatesOpen:    note.GatesOpen,
+		Notes:        note.Notes,
+	}
+	if note.CreatedAt.Valid {
+		response.CreatedAt = &note.CreatedAt.Time
+	}
+	if note.UpdatedAt.Valid {
+		response.UpdatedAt = &note.UpdatedAt.Time
+	}
+	return response
+}
+
+func toReceivedHandoverNoteResponses(notes []db.ListIncomingHandoverNotesRow) []HandoverNoteResponse {
+	responses := make([]HandoverNoteResponse, 0, len(notes))
+	for _, note := range notes {
+		response := HandoverNoteResponse{
+			HandoverID:   note.HandoverID,
+			BookingID:    note.BookingID,
+			UserID:       note.UserID,
+			UserName:     note.UserName,
+			ScheduleID:   note.ScheduleID,
+			ScheduleName: note.ScheduleName,
+			ShiftStart:   &note.ShiftStart,
+			ShiftEnd:     &note.ShiftEnd,
+			OpenIssues:   note.OpenIssues,
+			VehiclesSeen: note.VehiclesSeen,
+			GatesOpen:    note.GatesOpen,
+			Notes:        note.Notes,
+		}
+		if note.CreatedAt.Valid {
+			response.CreatedAt = &note.CreatedAt.Time
+		}
+		if note.UpdatedAt.Valid {
+			response.UpdatedAt = &note.UpdatedAt.Time
+		}
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+// parseBookingRequest extracts the authenticated user and booking ID from the request.
+func (h *HandoverHandler) parseBookingRequest(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return 0, 0, false
+	}
+
+	bookingIDStr := r.PathValue("id")
+	bookingID, err := strconv.ParseInt(bookingIDStr, 10, 64)
+	if err != nil || bookingID <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "Invalid booking ID", h.logger, "booking_id", bookingIDStr)
+		return 0, 0, false
+	}
+	return userID, bookingID, true
+}
+
+func (h *HandoverHandler) respondWithHandoverError(w http.ResponseWriter, err error, bookingID int64) {
+	switch {
+	case errors.Is(err, service.ErrBookingNotFound):
+		RespondWithError(w, http.StatusNotFound, "Booking not found", h.logger, "booking_id", bookingID)
+	case errors.Is(err, service.ErrHandoverNoteNotFound):
+		RespondWithError(w, http.StatusNotFound, "No handover note for this booking", h.logger, "booking_id", bookingID)
+	case errors.Is(err, service.ErrForbiddenUpdate):
+		RespondWithError(w, http.StatusForbidden, "You can only access handovers for your own bookings", h.logger, "booking_id", bookingID)
+	case errors.Is(err, service.ErrNotCheckedIn):
+		RespondWithError(w, http.StatusConflict, "Check in to the shift first", h.logger, "booking_id", bookingID)
+	case errors.Is(err, service.ErrHandoverNoteEmpty):
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger, "booking_id", bookingID)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to process handover request", h.logger, "error", err.Error())
+	}
+}
+
+// SaveHandoverNoteHandler handles PUT /api/bookings/{id}/handover
+// @Summary Leave a handover note
+// @Description Creates or replaces the handover note for the caller's checked-in booking. The note is shown to owls on the next slot of the same schedule and on overlapping schedules when they check in.
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param request body HandoverNoteRequest true "Handover note"
+// @Success 200 {object} HandoverNoteResponse "Handover note saved"
+// @Failure 400 {object} ErrorResponse "Invalid booking ID or empty note"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not your booking"
+// @Failure 404 {object} ErrorResponse "Booking not found"
+// @Failure 409 {object} ErrorResponse "Booking is not checked in"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/bookings/{id}/handover [put]
+func (h *HandoverHandler) SaveHandoverNoteHandler(w http.ResponseWriter, r *http.Request) {
+	userID, bookingID, ok := h.parseBookingRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req HandoverNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	note, err := h.handoverService.SaveHandoverNote(r.Context(), bookingID, userID, service.HandoverNoteInput{
+		OpenIssues:   req.OpenIssues,
+		VehiclesSeen: req.VehiclesSeen,
+		GatesOpen:    req.GatesOpen,
+		Notes:        req.Notes,
+	})
+	if err != nil {
+		h.respondWithHandoverError(w, err, bookingID)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, toHandoverNoteResponse(note), h.logger)
+}
+
+// GetHandoverNoteHandler handles GET /api/bookings/{id}/handover
+// @Summary Get the handover note left on a booking
+// @Description Returns the handover note the caller left on their booking. Admins may read any booking's note.
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} HandoverNoteResponse "Handover note"
+// @Failure 400 {object} ErrorResponse "Invalid booking ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not your booking"
+// @Failure 404 {object} ErrorResponse "Booking or handover note not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/bookings/{id}/handover [get]
+func (h *HandoverHandler) GetHandoverNoteHandler(w http.ResponseWriter, r *http.Request) {
+	userID, bookingID, ok := h.parseBookingRequest(w, r)
+	if !ok {
+		return
+	}
+	role, _ := r.Context().Value(UserRoleKey).(string)
+
+	note, err := h.handoverService.GetHandoverNote(r.Context(), bookingID, userID, role == "admin")
+	if err != nil {
+		h.respondWithHandoverError(w, err, bookingID)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, toHandoverNoteResponse(note), h.logger)
+}
+
+// ListReceivedHandoverNotesHandler handles GET /api/bookings/{id}/handover/received
+// @Summary Get handover notes for a shift
+// @Description Returns the notes left by earlier patrols on the same schedule or an overlapping one, most recent first. Available once the caller has checked in.
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {array} HandoverNoteResponse "Handover notes from earlier patrols"
+// @Failure 400 {object} ErrorResponse "Invalid booking ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not your booking"
+// @Failure 404 {object} ErrorResponse "Booking not found"
+// @Failure 409 {object} ErrorResponse "Booking is not checked in"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/bookings/{id}/handover/received [get]
+func (h *HandoverHandler) ListReceivedHandoverNotesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, bookingID, ok := h.parseBookingRequest(w, r)
+	if !ok {
+		return
+	}
+
+	notes, err := h.handoverService.ListReceivedNotesForOwl(r.Context(), bookingID, userID)
+	if err != nil {
+		h.respondWithHandoverError(w, err, bookingID)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, toReceivedHandoverNoteResponses(notes), h.logger)
+}
@@ -0,0 +1,153 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandover_LeaveAndReceiveNotes(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+	app.Config.HandoverLookback = 24 * time.Hour
+
+	ctx := context.Background()
+	_, adminToken := app.createTestUserAndLogin(t, "+15550003401", "Test Admin", "admin")
+	outgoing, outgoingToken := app.createTestUserAndLogin(t, "+15550003402", "Outgoing Owl", "owl")
+	incoming, incomingToken := app.createTestUserAndLogin(t, "+15550003403", "Incoming Owl", "owl")
+	_, otherToken := app.createTestUserAndLogin(t, "+15550003404", "Other Owl", "owl")
+
+	createSchedule := func(name string) int64 {
+		schedule, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+			Name:            name,
+			CronExpr:        "0 0 * * *",
+			DurationMinutes: 120,
+			Timezone:        sql.NullString{String: "UTC", Valid: true},
+		})
+		require.NoError(t, err)
+		return schedule.ScheduleID
+	}
+	createBooking := func(userID, scheduleID int64, start, end time.Time, checkedIn bool) int64 {
+		booking, err := app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+			UserID:     userID,
+			ScheduleID: scheduleID,
+			ShiftStart: start,
+			ShiftEnd:   end,
+		})
+		require.NoError(t, err)
+		if checkedIn {
+			_, err = app.Querier.UpdateBookingCheckIn(ctx, db.UpdateBookingCheckInParams{
+				CheckedInAt: sql.NullTime{Time: start, Valid: true},
+				BookingID:   booking.BookingID,
+			})
+			require.NoError(t, err)
+		}
+		return booking.BookingID
+	}
+	saveNote := func(bookingID int64, token string, note map[string]string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(note)
+		require.NoError(t, err)
+		return app.makeRequest(t, "PUT", fmt.Sprintf("/api/bookings/%d/handover", bookingID), bytes.NewReader(body), token)
+	}
+
+	patrol := createSchedule("Handover Patrol")
+	overlapping := createSchedule("Late Patrol")
+
+	now := time.Now().UTC()
+	outgoingID := createBooking(outgoing.UserID, patrol, now.Add(-150*time.Minute), now.Add(-30*time.Minute), true)
+	overlappingID := createBooking(outgoing.UserID, overlapping, now.Add(-60*time.Minute), now.Add(60*time.Minute), true)
+	staleID := createBooking(outgoing.UserID, patrol, now.Add(-72*time.Hour), now.Add(-70*time.Hour), true)
+	incomingID := createBooking(incoming.UserID, patrol, now.Add(-30*time.Minute), now.Add(90*time.Minute), false)
+
+	t.Run("the outgoing owl leaves a structured note", func(t *testing.T) {
+		rr := saveNote(incomingID, incomingToken, map[string]string{"notes": "Too soon"})
+		assert.Equal(t, http.StatusConflict, rr.Code, "notes need a checked-in booking: %s", rr.Body.String())
+
+		rr = saveNote(outgoingID, otherToken, map[string]string{"notes": "Not mine"})
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+
+		rr = saveNote(outgoingID, outgoingToken, map[string]string{"notes": "   "})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = saveNote(outgoingID, outgoingToken, map[string]string{"open_issues": "Streetlight out"})
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		rr = saveNote(outgoingID, outgoingToken, map[string]string{
+			"open_issues":   "Streetlight out on Main Rd",
+			"vehicles_seen": "White bakkie, CA 123-456",
+			"gates_open":    "North gate",
+		})
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var note api.HandoverNoteResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &note))
+		assert.Equal(t, outgoingID, note.BookingID)
+		assert.Equal(t, "Streetlight out on Main Rd", note.OpenIssues)
+
+		require.Equal(t, http.StatusOK, saveNote(overlappingID, outgoingToken, map[string]string{"notes": "Dog loose on Hill St"}).Code)
+		require.Equal(t, http.StatusOK, saveNote(staleID, outgoingToken, map[string]string{"notes": "Old news"}).Code)
+
+		rr = app.makeRequest(t, "GET", fmt.Sprintf("/api/bookings/%d/handover", outgoingID), nil, otherToken)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+		rr = app.makeRequest(t, "GET", fmt.Sprintf("/api/bookings/%d/handover", outgoingID), nil, adminToken)
+		assert.Equal(t, http.StatusOK, rr.Code)
+		rr = app.makeRequest(t, "GET", fmt.Sprintf("/api/bookings/%d/handover", incomingID), nil, incomingToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	receivedPath := fmt.Sprintf("/api/bookings/%d/handover/received", incomingID)
+
+	t.Run("the incoming owl sees notes once checked in", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", receivedPath, nil, incomingToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		_, err := app.Querier.UpdateBookingCheckIn(ctx, db.UpdateBookingCheckInParams{
+			CheckedInAt: sql.NullTime{Time: now, Valid: true},
+			BookingID:   incomingID,
+		})
+		require.NoError(t, err)
+
+		rr = app.makeRequest(t, "GET", receivedPath, nil, incomingToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var notes []api.HandoverNoteResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &notes))
+		require.Len(t, notes, 2, "the same-schedule and overlapping notes, but not the stale one")
+		assert.Equal(t, overlappingID, notes[0].BookingID)
+		assert.Equal(t, "Late Patrol", notes[0].ScheduleName)
+		assert.Equal(t, outgoingID, notes[1].BookingID)
+		assert.Equal(t, "Outgoing Owl", notes[1].UserName)
+		assert.Equal(t, "White bakkie, CA 123-456", notes[1].VehiclesSeen)
+
+		rr = app.makeRequest(t, "GET", receivedPath, nil, otherToken)
+		assert.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("admins see handovers alongside reports", func(t *testing.T) {
+		report, err := app.Querier.CreateReport(ctx, db.CreateReportParams{
+			BookingID: sql.NullInt64{Int64: incomingID, Valid: true},
+			UserID:    sql.NullInt64{Int64: incoming.UserID, Valid: true},
+			Severity:  1,
+			Message:   sql.NullString{String: "Checked the north gate", Valid: true},
+		})
+		require.NoError(t, err)
+
+		rr := app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/reports/%d", report.ReportID), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var adminReport api.AdminReportResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &adminReport))
+		assert.Nil(t, adminReport.HandoverNote)
+		require.Len(t, adminReport.ReceivedHandoverNotes, 2)
+		assert.Equal(t, "Streetlight out on Main Rd", adminReport.ReceivedHandoverNotes[1].OpenIssues)
+	})
+}
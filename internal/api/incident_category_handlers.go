@@ -0,0 +1,270 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+)
+
+type IncidentCategoryHandler struct {
+	categoryService *service.IncidentCategoryService
+	logger          *slog.Logger
+}
+
+func NewIncidentCategoryHandler(categoryService *service.IncidentCategoryService, logger *slog.Logger) *IncidentCategoryHandler {
+	return &IncidentCategoryHandler{
+		categoryService: categoryService,
+		logger:          logger.With("handler", "IncidentCategoryHandler"),
+	}
+}
+
+// IncidentCategoryResponse represents an incident category and its structured fields
+type IncidentCategoryResponse struct {
+	ID           int64                   `json:"id"`
+	Name         string                  `json:"name"`
+	Description  string                  `json:"description"`
+	Fields       []service.CategoryField `json:"fields"`
+	DisplayOrder int64                   `json:"display_order"`
+	UpdatedAt    time.Time               `json:"updated_at"`
+}
+
+// IncidentCategoryRequest represents the request to create or update an incident category
+type IncidentCategoryRequest struct {
+	Name         string                  `json:"name"`
+	Description  string                  `json:"description"`
+	Fields       []service.CategoryField `json:"fields"`
+	DisplayOrder int64                   `json:"display_order"`
+}
+
+func toIncidentCategoryResponse(category db.IncidentCategory) (IncidentCategoryResponse, error) {
+	fields, err := service.ParseCategoryFields(category)
+	if err != nil {
+		return IncidentCategoryResponse{}, err
+	}
+	return IncidentCategoryResponse{
+		ID:           category.CategoryID,
+		Name:         category.Name,
+		Description:  category.Description.String,
+		Fields:       fields,
+		DisplayOrder: category.DisplayOrder,
+		UpdatedAt:    category.UpdatedAt.Time,
+	}, nil
+}
+
+// ListIncidentCategoriesHandler handles GET /api/incident-categories
+// @Summary Get incident categories
+// @Description Returns the active incident categories and their structured fields for the report form
+// @Tags reports
+// @Produce json
+// @Success 200 {array} IncidentCategoryResponse "List of incident categories"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/incident-categories [get]
+func (h *IncidentCategoryHandler) ListIncidentCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.categoryService.ListCategories(r.Context())
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to get incident categories", h.logger, "error", err.Error())
+		return
+	}
+
+	response := make([]IncidentCategoryResponse, 0, len(categories))
+	for _, category := range categories {
+		item, err := toIncidentCategoryResponse(category)
+		if err != nil {
+			RespondWithError(w, http.StatusInternalServerError, "Failed to get incident categories", h.logger, "category_id", category.CategoryID, "error", err.Error())
+			return
+		}
+		response = append(response, item)
+	}
+
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminListIncidentCategoriesHandler handles GET /api/admin/incident-categories
+// @Summary Admin: Get incident categories
+// @Description Returns all active incident categories for admin management
+// @Tags admin-incident-categories
+// @Produce json
+// @Success 200 {array} IncidentCategoryResponse "List of incident categories"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/incident-categories [get]
+func (h *IncidentCategoryHandler) AdminListIncidentCategoriesHandler(w http.ResponseWriter, r *http.Request) {
+	h.ListIncidentCategoriesHandler(w, r) // Same logic as the owl-facing endpoint
+}
+
+// AdminGetIncidentCategoryHandler handles GET /api/admin/incident-categories/{id}
+// @Summary Admin: Get incident category by ID
+// @Description Returns a specific incident category by ID
+// @Tags admin-incident-categories
+// @Produce json
+// @Param id path int true "Incident Category ID"
+// @Success 200 {object} IncidentCategoryResponse "Incident category details"
+// @Failure 400 {object} ErrorResponse "Invalid category ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Incident category not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/incident-categories/{id} [get]
+func (h *IncidentCategoryHandler) AdminGetIncidentCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	categoryIDStr := r.PathValue("id")
+	categoryID, err := strconv.ParseInt(categoryIDStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid category ID", h.logger, "category_id", categoryIDStr)
+		return
+	}
+
+	category, err := h.categoryService.GetCategory(r.Context(), categoryID)
+	if err != nil {
+		if errors.Is(err, service.ErrIncidentCategoryNotFound) {
+			RespondWithError(w, http.StatusNotFound, "Incident category not found", h.logger, "category_id", categoryID)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to get incident category", h.logger, "error", err.Error())
+		return
+	}
+
+	response, err := toIncidentCategoryResponse(category)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to get incident category", h.logger, "error", err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminCreateIncidentCategoryHandler handles POST /api/admin/incident-categories
+// @Summary Admin: Create incident category
+// @Description Creates a new incident category with optional structured fields
+// @Tags admin-incident-categories
+// @Accept json
+// @Produce json
+// @Param request body IncidentCategoryRequest true "Incident category data"
+// @Success 201 {object} IncidentCategoryResponse "Created incident category"
+// @Failure 400 {object} ErrorResponse "Invalid request data"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/incident-categories [post]
+func (h *IncidentCategoryHandler) AdminCreateIncidentCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	var req IncidentCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(r.Context(), req.Name, req.Description, req.Fields, req.DisplayOrder)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCategoryData) {
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create incident category", h.logger, "error", err.Error())
+		return
+	}
+
+	response, err := toIncidentCategoryResponse(category)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create incident category", h.logger, "error", err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusCreated, response, h.logger)
+}
+
+// AdminUpdateIncidentCategoryHandler handles PUT /api/admin/incident-categories/{id}
+// @Summary Admin: Update incident category
+// @Description Updates an existing incident category. Existing reports keep the values they were submitted with.
+// @Tags admin-incident-categories
+// @Accept json
+// @Produce json
+// @Param id path int true "Incident Category ID"
+// @Param request body IncidentCategoryRequest true "Incident category data"
+// @Success 200 {object} IncidentCategoryResponse "Updated incident category"
+// @Failure 400 {object} ErrorResponse "Invalid request data"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Incident category not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/incident-categories/{id} [put]
+func (h *IncidentCategoryHandler) AdminUpdateIncidentCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	categoryIDStr := r.PathValue("id")
+	categoryID, err := strconv.ParseInt(categoryIDStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid category ID", h.logger, "category_id", categoryIDStr)
+		return
+	}
+
+	var req IncidentCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(r.Context(), categoryID, req.Name, req.Description, req.Fields, req.DisplayOrder)
+	if err != nil {
+		if errors.Is(err, service.ErrIncidentCategoryNotFound) {
+			RespondWithError(w, http.StatusNotFound, "Incident category not found", h.logger, "category_id", categoryID)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidCategoryData) {
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update incident category", h.logger, "error", err.Error())
+		return
+	}
+
+	response, err := toIncidentCategoryResponse(category)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to update incident category", h.logger, "error", err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminDeleteIncidentCategoryHandler handles DELETE /api/admin/incident-categories/{id}
+// @Summary Admin: Delete incident category
+// @Description Deactivates an incident category. Reports already filed under it keep their category.
+// @Tags admin-incident-categories
+// @Param id path int true "Incident Category ID"
+// @Success 204 "Incident category deleted successfully"
+// @Failure 400 {object} ErrorResponse "Invalid category ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Incident category not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/incident-categories/{id} [delete]
+func (h *IncidentCategoryHandler) AdminDeleteIncidentCategoryHandler(w http.ResponseWriter, r *http.Request) {
+	categoryIDStr := r.PathValue("id")
+	categoryID, err := strconv.ParseInt(categoryIDStr, 10, 64)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid category ID", h.logger, "category_id", categoryIDStr)
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(r.Context(), categoryID); err != nil {
+		if errors.Is(err, service.ErrIncidentCategoryNotFound) {
+			RespondWithError(w, http.StatusNotFound, "Incident category not found", h.logger, "category_id", categoryID)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to delete incident category", h.logger, "error", err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,165 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"night-owls-go/internal/api"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncidentCategoryHandlers_CRUDAndReportValidation(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	_, adminToken := app.createTestUserAndLogin(t, "+15550002801", "Test Admin", "admin")
+	_, owlToken := app.createTestUserAndLogin(t, "+15550002802", "Category Owl", "owl")
+
+	jsonBody := func(v interface{}) *bytes.Reader {
+		body, err := json.Marshal(v)
+		require.NoError(t, err)
+		return bytes.NewReader(body)
+	}
+
+	t.Run("seeded categories are listed for owls", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/incident-categories", nil, owlToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var categories []api.IncidentCategoryResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &categories))
+		require.NotEmpty(t, categories)
+		assert.Equal(t, "Suspicious vehicle", categories[0].Name)
+		assert.NotEmpty(t, categories[0].Fields)
+	})
+
+	t.Run("invalid field definitions are rejected", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", "/api/admin/incident-categories", jsonBody(map[string]interface{}{
+			"name":   "Broken",
+			"fields": []map[string]interface{}{{"key": "colour", "label": "Colour", "type": "select"}},
+		}), adminToken)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Response: %s", rr.Body.String())
+
+		rr = app.makeRequest(t, "POST", "/api/admin/incident-categories", jsonBody(map[string]interface{}{
+			"name":   "Broken",
+			"fields": []map[string]interface{}{{"key": "Bad Key", "label": "Bad", "type": "text"}},
+		}), adminToken)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, "Response: %s", rr.Body.String())
+	})
+
+	// Admin defines a category with structured fields
+	rr := app.makeRequest(t, "POST", "/api/admin/incident-categories", jsonBody(map[string]interface{}{
+		"name":          "Loitering vehicle",
+		"description":   "Vehicle parked with occupants",
+		"display_order": 10,
+		"fields": []map[string]interface{}{
+			{"key": "registration", "label": "Registration", "type": "text", "required": true, "max_length": 10},
+			{"key": "colour", "label": "Colour", "type": "select", "options": []string{"White", "Black", "Silver"}},
+			{"key": "occupants", "label": "Occupants", "type": "integer", "min": 0, "max": 10},
+		},
+	}), adminToken)
+	require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+
+	var category api.IncidentCategoryResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &category))
+	require.Len(t, category.Fields, 3)
+	assert.Equal(t, service.CategoryFieldSelect, category.Fields[1].Type)
+
+	createReport := func(payload map[string]interface{}) (int, api.ReportResponse) {
+		rr := app.makeRequest(t, "POST", "/api/reports/off-shift", jsonBody(payload), owlToken)
+		var report api.ReportResponse
+		if rr.Code == http.StatusCreated {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		}
+		return rr.Code, report
+	}
+
+	t.Run("valid structured fields are normalised and stored", func(t *testing.T) {
+		code, report := createReport(map[string]interface{}{
+			"severity":    1,
+			"message":     "Car idling near the park",
+			"category_id": category.ID,
+			"category_fields": map[string]interface{}{
+				"registration": " CA 123-456 ",
+				"colour":       "white",
+				"occupants":    2,
+			},
+		})
+		require.Equal(t, http.StatusCreated, code)
+		require.NotNil(t, report.CategoryID)
+		assert.Equal(t, category.ID, *report.CategoryID)
+		assert.Equal(t, map[string]interface{}{
+			"registration": "CA 123-456",
+			"colour":       "White",
+			"occupants":    float64(2),
+		}, report.CategoryFields)
+	})
+
+	t.Run("invalid structured fields are rejected", func(t *testing.T) {
+		cases := map[string]map[string]interface{}{
+			"missing required": {"category_id": category.ID, "category_fields": map[string]interface{}{"colour": "Black"}},
+			"unknown key":      {"category_id": category.ID, "category_fields": map[string]interface{}{"registration": "ABC", "wheels": 4}},
+			"out of range":     {"category_id": category.ID, "category_fields": map[string]interface{}{"registration": "ABC", "occupants": 11}},
+			"not an integer":   {"category_id": category.ID, "category_fields": map[string]interface{}{"registration": "ABC", "occupants": 1.5}},
+			"bad option":       {"category_id": category.ID, "category_fields": map[string]interface{}{"registration": "ABC", "colour": "Purple"}},
+			"too long":         {"category_id": category.ID, "category_fields": map[string]interface{}{"registration": "ABCDEFGHIJKL"}},
+			"no category":      {"category_fields": map[string]interface{}{"registration": "ABC"}},
+			"unknown category": {"category_id": 99999},
+		}
+		for name, payload := range cases {
+			payload["severity"] = 1
+			code, _ := createReport(payload)
+			assert.Equal(t, http.StatusBadRequest, code, name)
+		}
+	})
+
+	t.Run("admin report list shows and filters by category", func(t *testing.T) {
+		code, _ := createReport(map[string]interface{}{"severity": 0, "message": "Uncategorised"})
+		require.Equal(t, http.StatusCreated, code)
+
+		rr := app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/reports?category_id=%d", category.ID), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var reports []api.AdminReportResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &reports))
+		require.Len(t, reports, 1)
+		assert.Equal(t, "Loitering vehicle", reports[0].CategoryName)
+		assert.Equal(t, "White", reports[0].CategoryFields["colour"])
+	})
+
+	t.Run("dashboard includes category breakdown", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/dashboard", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var dashboard service.AdminDashboard
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &dashboard))
+
+		counts := map[string]int{}
+		for _, row := range dashboard.CategoryBreakdown {
+			counts[row.CategoryName] = row.ReportCount
+		}
+		assert.Equal(t, 1, counts["Loitering vehicle"])
+		assert.Equal(t, 1, counts["Uncategorised"])
+	})
+
+	t.Run("deleted categories can no longer be used", func(t *testing.T) {
+		path := fmt.Sprintf("/api/admin/incident-categories/%d", category.ID)
+		rr := app.makeRequest(t, "DELETE", path, nil, adminToken)
+		require.Equal(t, http.StatusNoContent, rr.Code, "Response: %s", rr.Body.String())
+
+		rr = app.makeRequest(t, "GET", path, nil, adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+
+		code, _ := createReport(map[string]interface{}{
+			"severity":        1,
+			"category_id":     category.ID,
+			"category_fields": map[string]interface{}{"registration": "ABC"},
+		})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+)
+
+// IncidentEscalationHandler handles acknowledgement and inspection of severity-2 escalations.
+type IncidentEscalationHandler struct {
+	escalationService *service.IncidentEscalationService
+	auditService      *service.AuditService
+	logger            *slog.Logger
+}
+
+// NewIncidentEscalationHandler creates a new IncidentEscalationHandler.
+func NewIncidentEscalationHandler(escalationService *service.IncidentEscalationService, auditService *service.AuditService, logger *slog.Logger) *IncidentEscalationHandler {
+	return &IncidentEscalationHandler{
+		escalationService: escalationService,
+		auditService:      auditService,
+		logger:            logger.With("handler", "IncidentEscalationHandler"),
+	}
+}
+
+// EscalationMessageResponse describes one outbox message sent as part of an escalation
+type EscalationMessageResponse struct {
+	OutboxID    int64      `json:"outbox_id"`
+	Stage       string     `json:"stage"`
+	MessageType string     `json:"message_type"`
+	Recipient   string     `json:"recipient,omitempty"`
+	UserID      *int64     `json:"user_id,omitempty"`
+	Status      string     `json:"status"`
+	SendAt      time.Time  `json:"send_at"`
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+}
+
+// IncidentEscalationResponse describes the escalation state of a severity-2 report
+type IncidentEscalationResponse struct {
+	EscalationID         int64                       `json:"escalation_id"`
+	ReportID             int64                       `json:"report_id"`
+	Status               string                      `json:"status"` // pending, escalated or acknowledged
+	EscalateAt           time.Time                   `json:"escalate_at"`
+	AcknowledgedByUserID *int64                      `json:"acknowledged_by_user_id,omitempty"`
+	AcknowledgedAt       *time.Time                  `json:"acknowledged_at,omitempty"`
+	CancelledSMS         int64                       `json:"cancelled_sms,omitempty"`
+	Messages             []EscalationMessageResponse `json:"messages,omitempty"`
+}
+
+func toIncidentEscalationResponse(escalation db.IncidentEscalation) IncidentEscalationResponse {
+	response := IncidentEscalationResponse{
+		EscalationID: escalation.EscalationID,
+		ReportID:     escalation.ReportID,
+		Status:       service.EscalationState(escalation, time.Now().UTC()),
+		EscalateAt:   escalation.EscalateAt,
+	}
+	if escalation.AcknowledgedByUserID.Valid {
+		response.AcknowledgedByUserID = &escalation.AcknowledgedByUserID.Int64
+	}
+	if escalation.AcknowledgedAt.Valid {
+		response.AcknowledgedAt = &escalation.AcknowledgedAt.Time
+	}
+	return response
+}
+
+// AcknowledgeEscalationHandler handles POST /api/reports/{id}/acknowledge
+// @Summary Acknowledge a serious incident alert
+// @Description Acknowledges the escalation for a severity-2 report and cancels any SMS not yet sent to responders. Admins and owls who received the alert may acknowledge.
+// @Tags reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} IncidentEscalationResponse "Acknowledged escalation"
+// @Failure 400 {object} ErrorResponse "Invalid report ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "User was not alerted for this incident"
+// @Failure 404 {object} ErrorResponse "No escalation for this report"
+// @Failure 409 {object} ErrorResponse "Already acknowledged"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/reports/{id}/acknowledge [post]
+func (h *IncidentEscalationHandler) AcknowledgeEscalationHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+	role, _ := r.Context().Value(UserRoleKey).(string)
+
+	reportIDStr := r.PathValue("id")
+	reportID, err := strconv.ParseInt(reportIDStr, 10, 64)
+	if err != nil || reportID <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "Invalid report ID", h.logger, "report_id", reportIDStr)
+		return
+	}
+
+	escalation, cancelled, err := h.escalationService.AcknowledgeEscalation(r.Context(), reportID, userID, role == "admin")
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrEscalationNotFound):
+			RespondWithError(w, http.StatusNotFound, "No escalation for this report", h.logger, "report_id", reportID)
+		case errors.Is(err, service.ErrEscalationAckForbidden):
+			RespondWithError(w, http.StatusForbidden, "You were not alerted for this incident", h.logger, "report_id", reportID)
+		case errors.Is(err, service.ErrEscalationAlreadyAcknowledged):
+			RespondWithError(w, http.StatusConflict, "Incident has already been acknowledged", h.logger, "report_id", reportID)
+		default:
+			RespondWithError(w, http.StatusInternalServerError, "Failed to acknowledge incident", h.logger, "error", err.Error())
+		}
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if auditErr := h.auditService.LogReportEscalationAcknowledged(r.Context(), userID, reportID, escalation.EscalationID, cancelled, ipAddress, userAgent); auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log escalation acknowledgement audit event", "report_id", reportID, "error", auditErr)
+	}
+
+	response := toIncidentEscalationResponse(escalation)
+	response.CancelledSMS = cancelled
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminGetEscalationHandler handles GET /api/admin/reports/{id}/escalation
+// @Summary Get escalation status for a report (Admin)
+// @Description Returns the escalation state of a severity-2 report with every push and SMS message and its outbox delivery status
+// @Tags admin/reports
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} IncidentEscalationResponse "Escalation with messages"
+// @Failure 400 {object} ErrorResponse "Invalid report ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "No escalation for this report"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/reports/{id}/escalation [get]
+func (h *IncidentEscalationHandler) AdminGetEscalationHandler(w http.ResponseWriter, r *http.Request) {
+	reportIDStr := r.PathValue("id")
+	reportID, err := strconv.ParseInt(reportIDStr, 10, 64)
+	if err != nil || reportID <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "Invalid report ID", h.logger, "report_id", reportIDStr)
+		return
+	}
+
+	escalation, messages, err := h.escalationService.GetEscalation(r.Context(), reportID)
+	if err != nil {
+		if errors.Is(err, service.ErrEscalationNotFound) {
+			RespondWithError(w, http.StatusNotFound, "No escalation for this report", h.logger, "report_id", reportID)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to get escalation", h.logger, "error", err.Error())
+		return
+	}
+
+	response := toIncidentEscalationResponse(escalation)
+	response.Messages = make([]EscalationMessageResponse, 0, len(messages))
+	for _, message := range messages {
+		item := EscalationMessageResponse{
+			OutboxID:    message.OutboxID,
+			Stage:       message.Stage,
+			MessageType: message.MessageType,
+			Recipient:   message.Recipient,
+			Status:      message.Status,
+			SendAt:      message.SendAt,
+		}
+		if message.UserID.Valid {
+			item.UserID = &message.UserID.Int64
+		}
+		if message.SentAt.Valid {
+			item.SentAt = &message.SentAt.Time
+		}
+		response.Messages = append(response.Messages, item)
+	}
+
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
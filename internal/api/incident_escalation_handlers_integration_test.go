@@ -0,0 +1,213 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIncidentEscalation_PushThenSMSWithAcknowledgement(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	app.Config.EscalationSMSDelay = 10 * time.Minute
+	app.Config.EscalationResponderPhones = []string{"+15550002999"}
+
+	ctx := context.Background()
+	admin, adminToken := app.createTestUserAndLogin(t, "+15550002901", "Test Admin", "admin")
+	onDuty, onDutyToken := app.createTestUserAndLogin(t, "+15550002902", "On Duty Owl", "owl")
+	_, offDutyToken := app.createTestUserAndLogin(t, "+15550002903", "Off Duty Owl", "owl")
+	reporter, reporterToken := app.createTestUserAndLogin(t, "+15550002904", "Reporter Owl", "owl")
+
+	schedule, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+		Name:            "Escalation Patrol",
+		CronExpr:        "0 0 * * *",
+		DurationMinutes: 120,
+		Timezone:        sql.NullString{String: "UTC", Valid: true},
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	_, err = app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+		UserID:     onDuty.UserID,
+		ScheduleID: schedule.ScheduleID,
+		ShiftStart: now.Add(-30 * time.Minute),
+		ShiftEnd:   now.Add(90 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	createReport := func(severity int) int64 {
+		body, err := json.Marshal(map[string]interface{}{
+			"severity":  severity,
+			"message":   "Armed suspects at the north gate",
+			"latitude":  -33.9249,
+			"longitude": 18.4241,
+		})
+		require.NoError(t, err)
+		rr := app.makeRequest(t, "POST", "/api/reports/off-shift", bytes.NewReader(body), reporterToken)
+		require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+
+		var report api.ReportResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &report))
+		return report.ReportID
+	}
+
+	getEscalation := func(reportID int64) (int, api.IncidentEscalationResponse) {
+		rr := app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/reports/%d/escalation", reportID), nil, adminToken)
+		var escalation api.IncidentEscalationResponse
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &escalation))
+		}
+		return rr.Code, escalation
+	}
+
+	reportID := createReport(2)
+
+	t.Run("push goes to on-duty owls and admins, SMS is scheduled", func(t *testing.T) {
+		code, escalation := getEscalation(reportID)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, service.EscalationStatePending, escalation.Status)
+
+		pushUsers := []int64{}
+		smsRecipients := []string{}
+		for _, message := range escalation.Messages {
+			assert.Equal(t, "pending", message.Status)
+			switch message.Stage {
+			case service.EscalationStagePush:
+				require.NotNil(t, message.UserID)
+				pushUsers = append(pushUsers, *message.UserID)
+			case service.EscalationStageSMS:
+				smsRecipients = append(smsRecipients, message.Recipient)
+				assert.WithinDuration(t, escalation.EscalateAt, message.SendAt, time.Second)
+				assert.True(t, message.SendAt.After(time.Now().Add(9*time.Minute)))
+			}
+		}
+		assert.ElementsMatch(t, []int64{admin.UserID, onDuty.UserID}, pushUsers)
+		assert.NotContains(t, pushUsers, reporter.UserID)
+		assert.Equal(t, []string{"+15550002999"}, smsRecipients)
+	})
+
+	t.Run("alert payload includes the default emergency contact", func(t *testing.T) {
+		items, err := app.Querier.GetRecentOutboxItemsByRecipient(ctx, db.GetRecentOutboxItemsByRecipientParams{
+			Recipient: "",
+			Limit:     10,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, items)
+
+		var payload map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(items[0].Payload.String), &payload))
+		assert.Equal(t, "incident_escalation", payload["type"])
+		data := payload["data"].(map[string]interface{})
+		assert.EqualValues(t, reportID, data["report_id"])
+		assert.Equal(t, map[string]interface{}{"name": "RUSA", "number": "086 123 4333"}, data["emergency_contact"])
+
+		sms, err := app.Querier.GetRecentOutboxItemsByRecipient(ctx, db.GetRecentOutboxItemsByRecipientParams{
+			Recipient: "+15550002999",
+			Limit:     1,
+		})
+		require.NoError(t, err)
+		require.Len(t, sms, 1)
+		assert.Contains(t, sms[0].Payload.String, "RUSA 086 123 4333")
+		assert.Contains(t, sms[0].Payload.String, "-33.92490,18.42410")
+	})
+
+	ackPath := fmt.Sprintf("/api/reports/%d/acknowledge", reportID)
+
+	t.Run("owls who were not alerted cannot acknowledge", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", ackPath, nil, offDutyToken)
+		assert.Equal(t, http.StatusForbidden, rr.Code, "Response: %s", rr.Body.String())
+	})
+
+	t.Run("acknowledgement cancels the pending SMS", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", ackPath, nil, onDutyToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var ack api.IncidentEscalationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ack))
+		assert.Equal(t, service.EscalationStateAcknowledged, ack.Status)
+		assert.EqualValues(t, 1, ack.CancelledSMS)
+		require.NotNil(t, ack.AcknowledgedByUserID)
+		assert.Equal(t, onDuty.UserID, *ack.AcknowledgedByUserID)
+
+		_, escalation := getEscalation(reportID)
+		for _, message := range escalation.Messages {
+			if message.Stage == service.EscalationStageSMS {
+				assert.Equal(t, "cancelled", message.Status)
+			}
+		}
+
+		rr = app.makeRequest(t, "POST", ackPath, nil, adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		events, err := app.Querier.ListAuditEventsByType(ctx, db.ListAuditEventsByTypeParams{
+			EventType: "report.escalation_acknowledged",
+			Limit:     10,
+		})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, onDuty.UserID, events[0].ActorUserID.Int64)
+	})
+
+	t.Run("unacknowledged escalations past the delay are escalated", func(t *testing.T) {
+		app.Config.EscalationSMSDelay = 0
+		overdueID := createReport(2)
+
+		_, escalation := getEscalation(overdueID)
+		assert.Equal(t, service.EscalationStateEscalated, escalation.Status)
+
+		// Admins can always acknowledge
+		rr := app.makeRequest(t, "POST", fmt.Sprintf("/api/reports/%d/acknowledge", overdueID), nil, adminToken)
+		assert.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+	})
+
+	t.Run("a failed SMS is not retried after acknowledgement", func(t *testing.T) {
+		app.Config.EscalationSMSDelay = 0
+		app.mockSMSSender.On("Send", "+15550002999", "sms", mock.Anything).Return(errors.New("gateway unavailable"))
+		failedID := createReport(2)
+		past := time.Now().UTC().Add(-time.Minute)
+		_, err := app.DB.Exec(`UPDATE outbox SET send_at = ? WHERE message_type = 'sms' AND status = 'pending'`, past)
+		require.NoError(t, err)
+
+		app.OutboxService.ProcessPendingOutboxItems(ctx)
+		app.mockSMSSender.AssertNumberOfCalls(t, "Send", 1)
+
+		// The retry is due, but the escalation is acknowledged first
+		_, err = app.DB.Exec(`UPDATE outbox SET send_at = ? WHERE status = 'failed'`, past)
+		require.NoError(t, err)
+		rr := app.makeRequest(t, "POST", fmt.Sprintf("/api/reports/%d/acknowledge", failedID), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var ack api.IncidentEscalationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &ack))
+		assert.EqualValues(t, 1, ack.CancelledSMS)
+
+		app.OutboxService.ProcessPendingOutboxItems(ctx)
+		app.mockSMSSender.AssertNumberOfCalls(t, "Send", 1)
+
+		_, escalation := getEscalation(failedID)
+		for _, message := range escalation.Messages {
+			if message.Stage == service.EscalationStageSMS {
+				assert.Equal(t, "cancelled", message.Status)
+			}
+		}
+	})
+
+	t.Run("lower severity reports are not escalated", func(t *testing.T) {
+		code, _ := getEscalation(createReport(1))
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+}
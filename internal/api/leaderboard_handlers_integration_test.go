@@ -119,10 +119,11 @@ func newLeaderboardTestApp(t *testing.T) *leaderboardTestApp {
 	userService := service.NewUserService(querier, otpStore, cfg, logger)
 	pointsService := service.NewPointsService(querier, logger)
 	bookingService := service.NewBookingService(querier, cfg, logger, pointsService)
-	reportService := service.NewReportService(querier, logger, pointsService)
+	reportAbuseService := service.NewReportAbuseService(querier, logger)
+	reportService := service.NewReportService(querier, logger, pointsService, reportAbuseService)
 	auditService := service.NewAuditService(querier, logger)
 	pushService := service.NewPushSender(querier, cfg, logger)
-	outboxService := outbox.NewDispatcherService(querier, mockSender, pushService, logger, cfg)
+	outboxService := outbox.NewDispatcherService(querier, mockSender, pushService, nil, service.NewNotificationPreferencesService(querier, cfg, logger), logger, cfg)
 
 	cronScheduler := cron.New()
 
@@ -9,15 +9,16 @@ import (
 
 // BookingResponse represents a booking in the API
 type BookingResponse struct {
-	BookingID   int64      `json:"booking_id"`
-	UserID      int64      `json:"user_id"`
-	ScheduleID  int64      `json:"schedule_id"`
-	ShiftStart  time.Time  `json:"shift_start"`
-	ShiftEnd    time.Time  `json:"shift_end"`
-	BuddyUserID *int64     `json:"buddy_user_id,omitempty"`
-	BuddyName   string     `json:"buddy_name,omitempty"`
-	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
+	BookingID    int64      `json:"booking_id"`
+	UserID       int64      `json:"user_id"`
+	ScheduleID   int64      `json:"schedule_id"`
+	ShiftStart   time.Time  `json:"shift_start"`
+	ShiftEnd     time.Time  `json:"shift_end"`
+	BuddyUserID  *int64     `json:"buddy_user_id,omitempty"`
+	BuddyName    string     `json:"buddy_name,omitempty"`
+	CheckedInAt  *time.Time `json:"checked_in_at,omitempty"`
+	CheckedOutAt *time.Time `json:"checked_out_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
 }
 
 // CalendarData represents calendar file information for downloads
@@ -74,11 +75,13 @@ type BookingWithScheduleResponse struct {
 
 // ReportResponse represents a report in the API
 type ReportResponse struct {
-	ReportID  int64     `json:"report_id"`
-	BookingID int64     `json:"booking_id"`
-	Severity  int64     `json:"severity"`
-	Message   string    `json:"message,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	ReportID       int64                  `json:"report_id"`
+	BookingID      int64                  `json:"booking_id"`
+	Severity       int64                  `json:"severity"`
+	Message        string                 `json:"message,omitempty"`
+	CategoryID     *int64                 `json:"category_id,omitempty"`
+	CategoryFields map[string]interface{} `json:"category_fields,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
 }
 
 // ScheduleResponse represents a schedule in the API
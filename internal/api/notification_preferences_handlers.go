@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"night-owls-go/internal/service"
+)
+
+// NotificationPreferencesHandler handles the caller's notification preferences.
+type NotificationPreferencesHandler struct {
+	preferencesService *service.NotificationPreferencesService
+	logger             *slog.Logger
+}
+
+// NewNotificationPreferencesHandler creates a new NotificationPreferencesHandler.
+func NewNotificationPreferencesHandler(preferencesService *service.NotificationPreferencesService, logger *slog.Logger) *NotificationPreferencesHandler {
+	return &NotificationPreferencesHandler{
+		preferencesService: preferencesService,
+		logger:             logger.With("handler", "NotificationPreferencesHandler"),
+	}
+}
+
+func (h *NotificationPreferencesHandler) respondWithPreferencesError(w http.ResponseWriter, err error, userID int64) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		RespondWithError(w, http.StatusNotFound, "User not found", h.logger, "user_id", userID)
+	case errors.Is(err, service.ErrInvalidNotificationChannel):
+		RespondWithError(w, http.StatusBadRequest, "Channel must be push, sms or email", h.logger, "user_id", userID)
+	case errors.Is(err, service.ErrInvalidQuietHours):
+		RespondWithError(w, http.StatusBadRequest, "Quiet hours need a different HH:MM start and end, or neither", h.logger, "user_id", userID)
+	case errors.Is(err, service.ErrInvalidTimezone):
+		RespondWithError(w, http.StatusBadRequest, "Unknown time zone", h.logger, "user_id", userID)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to process notification preferences", h.logger, "error", err.Error())
+	}
+}
+
+// GetPreferencesHandler handles GET /api/user/notification-preferences
+// @Summary Get my notification preferences
+// @Tags user
+// @Produce json
+// @Success 200 {object} service.NotificationPreferences "Notification preferences"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/user/notification-preferences [get]
+func (h *NotificationPreferencesHandler) GetPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	prefs, err := h.preferencesService.Get(r.Context(), userID)
+	if err != nil {
+		h.respondWithPreferencesError(w, err, userID)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, prefs, h.logger)
+}
+
+// UpdatePreferencesHandler handles PUT /api/user/notification-preferences
+// @Summary Update my notification preferences
+// @Description Replaces the caller's preferences. Quiet hours hold back everything but urgent and transactional messages; they may run over midnight and are cleared by leaving both ends empty.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body service.UpdateNotificationPreferencesParams true "Notification preferences"
+// @Success 200 {object} service.NotificationPreferences "Preferences saved"
+// @Failure 400 {object} ErrorResponse "Invalid channel, quiet hours or time zone"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/user/notification-preferences [put]
+func (h *NotificationPreferencesHandler) UpdatePreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	var req service.UpdateNotificationPreferencesParams
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	prefs, err := h.preferencesService.Update(r.Context(), userID, req)
+	if err != nil {
+		h.respondWithPreferencesError(w, err, userID)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, prefs, h.logger)
+}
@@ -0,0 +1,210 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationPreferences(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	owl, owlToken := app.createTestUserAndLogin(t, "+27820000301", "Quiet Owl", "owl")
+	smsOwl, smsToken := app.createTestUserAndLogin(t, "+27820000302", "SMS Owl", "owl")
+	admin, _ := app.createTestUserAndLogin(t, "+27820000303", "Admin", "admin")
+
+	decode := func(t *testing.T, body []byte) service.NotificationPreferences {
+		t.Helper()
+		var prefs service.NotificationPreferences
+		require.NoError(t, json.Unmarshal(body, &prefs))
+		return prefs
+	}
+
+	t.Run("everything is on by default", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/user/notification-preferences", nil, owlToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		prefs := decode(t, rr.Body.Bytes())
+		assert.Equal(t, "push", prefs.Channel)
+		assert.True(t, prefs.Reminders && prefs.Broadcasts && prefs.CoverageAlerts && prefs.ReportUpdates)
+		assert.Empty(t, prefs.QuietHoursStart)
+	})
+
+	t.Run("invalid preferences are refused", func(t *testing.T) {
+		for _, body := range []string{
+			`{"channel": "pigeon"}`,
+			`{"channel": "push", "quiet_hours_start": "22:00"}`,
+			`{"channel": "push", "quiet_hours_start": "25:00", "quiet_hours_end": "06:00"}`,
+			`{"channel": "push", "quiet_hours_start": "06:00", "quiet_hours_end": "06:00"}`,
+			`{"channel": "push", "timezone": "Mars/Olympus_Mons"}`,
+		} {
+			rr := app.makeRequest(t, "PUT", "/api/user/notification-preferences", bytes.NewBufferString(body), owlToken)
+			assert.Equal(t, http.StatusBadRequest, rr.Code, "body %s", body)
+		}
+	})
+
+	t.Run("preferences are saved", func(t *testing.T) {
+		body := `{"channel": "push", "reminders": true, "broadcasts": false, "coverage_alerts": true, "report_updates": false,
+			"quiet_hours_start": "22:00", "quiet_hours_end": "6:30", "timezone": "Africa/Johannesburg"}`
+		rr := app.makeRequest(t, "PUT", "/api/user/notification-preferences", bytes.NewBufferString(body), owlToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		rr = app.makeRequest(t, "GET", "/api/user/notification-preferences", nil, owlToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		prefs := decode(t, rr.Body.Bytes())
+		assert.False(t, prefs.Broadcasts)
+		assert.False(t, prefs.ReportUpdates)
+		assert.True(t, prefs.Reminders)
+		assert.Equal(t, "22:00", prefs.QuietHoursStart)
+		assert.Equal(t, "06:30", prefs.QuietHoursEnd)
+		assert.Equal(t, "Africa/Johannesburg", prefs.Timezone)
+
+		rr = app.makeRequest(t, "PUT", "/api/user/notification-preferences", bytes.NewBufferString(`{"channel": "sms", "reminders": true, "broadcasts": true, "coverage_alerts": true, "report_updates": true}`), smsToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		assert.Equal(t, "sms", decode(t, rr.Body.Bytes()).Channel)
+	})
+
+	t.Run("broadcasts respect opt-outs and channels", func(t *testing.T) {
+		ctx := context.Background()
+		broadcastService := app.BroadcastService
+		create := func(urgent bool) db.Broadcast {
+			broadcast, err := app.Querier.CreateBroadcast(ctx, db.CreateBroadcastParams{
+				Title:        "Patrol update",
+				Message:      "Meet at the gate",
+				Audience:     "owls",
+				SenderUserID: admin.UserID,
+				PushEnabled:  true,
+				Urgent:       urgent,
+				Status:       "pending",
+			})
+			require.NoError(t, err)
+			return broadcast
+		}
+		// recipients returns the outbox items queued for a broadcast, by user
+		recipients := func(t *testing.T) map[int64]db.Outbox {
+			t.Helper()
+			rows, err := app.DB.Query(`SELECT user_id, message_type, recipient, category FROM outbox WHERE category IS NOT NULL`)
+			require.NoError(t, err)
+			defer rows.Close()
+			items := map[int64]db.Outbox{}
+			for rows.Next() {
+				var item db.Outbox
+				require.NoError(t, rows.Scan(&item.UserID, &item.MessageType, &item.Recipient, &item.Category))
+				items[item.UserID.Int64] = item
+			}
+			require.NoError(t, rows.Err())
+			_, err = app.DB.Exec(`DELETE FROM outbox`)
+			require.NoError(t, err)
+			return items
+		}
+
+		create(false)
+		_, err := broadcastService.ProcessPendingBroadcasts(ctx)
+		require.NoError(t, err)
+		items := recipients(t)
+		assert.NotContains(t, items, owl.UserID, "opted out of broadcasts")
+		require.Contains(t, items, smsOwl.UserID)
+		assert.Equal(t, service.OutboxMessageSMS, items[smsOwl.UserID].MessageType)
+		assert.Equal(t, "+27820000302", items[smsOwl.UserID].Recipient)
+		assert.Equal(t, service.NotificationCategoryBroadcasts, items[smsOwl.UserID].Category.String)
+
+		create(true)
+		_, err = broadcastService.ProcessPendingBroadcasts(ctx)
+		require.NoError(t, err)
+		items = recipients(t)
+		require.Contains(t, items, owl.UserID, "urgent broadcasts reach everyone")
+		assert.Equal(t, service.NotificationCategoryUrgent, items[owl.UserID].Category.String)
+		assert.Equal(t, service.OutboxMessagePush, items[owl.UserID].MessageType)
+	})
+
+	t.Run("coverage gaps are alerted as coverage alerts", func(t *testing.T) {
+		ctx := context.Background()
+		_, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+			Name:            "Hourly Patrol",
+			CronExpr:        "0 * * * *",
+			DurationMinutes: 60,
+			Timezone:        sql.NullString{String: "UTC", Valid: true},
+		})
+		require.NoError(t, err)
+
+		dashboardService := service.NewAdminDashboardService(app.Querier, app.ScheduleService, app.Logger)
+		queued, err := dashboardService.NotifyCoverageGaps(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 3, queued, "owls and admins are alerted")
+
+		rows, err := app.DB.Query(`SELECT user_id, category FROM outbox WHERE message_type = 'push'`)
+		require.NoError(t, err)
+		defer rows.Close()
+		alerted := []int64{}
+		for rows.Next() {
+			var userID int64
+			var category string
+			require.NoError(t, rows.Scan(&userID, &category))
+			assert.Equal(t, service.NotificationCategoryCoverageAlerts, category)
+			alerted = append(alerted, userID)
+		}
+		require.NoError(t, rows.Err())
+		assert.ElementsMatch(t, []int64{owl.UserID, smsOwl.UserID, admin.UserID}, alerted)
+	})
+}
+
+func TestShiftReminders(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	owl, _ := app.createTestUserAndLogin(t, "+27820000311", "Reminded Owl", "owl")
+	optedOut, optedOutToken := app.createTestUserAndLogin(t, "+27820000312", "Unreminded Owl", "owl")
+
+	rr := app.makeRequest(t, "PUT", "/api/user/notification-preferences", bytes.NewBufferString(`{"channel": "push", "reminders": false, "broadcasts": true, "coverage_alerts": true, "report_updates": true}`), optedOutToken)
+	require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+	schedule, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+		Name:            "Reminder Patrol",
+		CronExpr:        "0 0 * * *",
+		DurationMinutes: 120,
+		Timezone:        sql.NullString{String: "UTC", Valid: true},
+	})
+	require.NoError(t, err)
+	shiftStart := time.Now().UTC().Truncate(24 * time.Hour).Add(48 * time.Hour)
+
+	reminderStatuses := func(t *testing.T, userID int64) []string {
+		t.Helper()
+		rows, err := app.DB.Query(`SELECT status FROM outbox WHERE category = ? AND user_id = ?`, service.NotificationCategoryReminders, userID)
+		require.NoError(t, err)
+		defer rows.Close()
+		statuses := []string{}
+		for rows.Next() {
+			var status string
+			require.NoError(t, rows.Scan(&status))
+			statuses = append(statuses, status)
+		}
+		require.NoError(t, rows.Err())
+		return statuses
+	}
+
+	t.Run("booking a shift queues its reminders", func(t *testing.T) {
+		booking, err := app.BookingService.CreateBooking(ctx, owl.UserID, schedule.ScheduleID, shiftStart, sql.NullString{}, sql.NullString{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pending", "pending"}, reminderStatuses(t, owl.UserID), "24 hour and 1 hour reminders")
+
+		require.NoError(t, app.BookingService.CancelBooking(ctx, booking.BookingID, owl.UserID))
+		assert.Equal(t, []string{"cancelled", "cancelled"}, reminderStatuses(t, owl.UserID), "a cancelled booking is not reminded")
+	})
+
+	t.Run("no reminders are queued for owls who turned them off", func(t *testing.T) {
+		_, err := app.BookingService.CreateBooking(ctx, optedOut.UserID, schedule.ScheduleID, shiftStart.Add(24*time.Hour), sql.NullString{}, sql.NullString{})
+		require.NoError(t, err)
+		assert.Empty(t, reminderStatuses(t, optedOut.UserID))
+	})
+}
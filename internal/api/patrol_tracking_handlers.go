@@ -0,0 +1,284 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"night-owls-go/internal/service"
+)
+
+// PatrolTrackingHandler handles live location sharing for active patrols.
+type PatrolTrackingHandler struct {
+	trackingService *service.PatrolTrackingService
+	logger          *slog.Logger
+}
+
+// NewPatrolTrackingHandler creates a new PatrolTrackingHandler.
+func NewPatrolTrackingHandler(trackingService *service.PatrolTrackingService, logger *slog.Logger) *PatrolTrackingHandler {
+	return &PatrolTrackingHandler{
+		trackingService: trackingService,
+		logger:          logger.With("handler", "PatrolTrackingHandler"),
+	}
+}
+
+// LocationSharingResponse describes the sharing state of a booking
+type LocationSharingResponse struct {
+	BookingID  int64      `json:"booking_id"`
+	Active     bool       `json:"active"`
+	StartedAt  time.Time  `json:"started_at"`
+	StoppedAt  *time.Time `json:"stopped_at,omitempty"`
+	StopReason string     `json:"stop_reason,omitempty"`
+}
+
+// PatrolLocationRequest is a single GPS breadcrumb
+type PatrolLocationRequest struct {
+	Latitude   float64    `json:"latitude"`
+	Longitude  float64    `json:"longitude"`
+	Accuracy   *float64   `json:"accuracy,omitempty"`    // metres
+	RecordedAt *time.Time `json:"recorded_at,omitempty"` // defaults to the time of upload
+}
+
+// RecordLocationsRequest is a batch of breadcrumbs for one booking
+type RecordLocationsRequest struct {
+	Locations []PatrolLocationRequest `json:"locations"`
+}
+
+// RecordLocationsResponse reports how many breadcrumbs were stored
+type RecordLocationsResponse struct {
+	Recorded int `json:"recorded"`
+}
+
+// LivePatrolPositionResponse is the latest known position of an owl on patrol
+type LivePatrolPositionResponse struct {
+	BookingID    int64     `json:"booking_id"`
+	UserID       int64     `json:"user_id"`
+	UserName     string    `json:"user_name"`
+	ScheduleName string    `json:"schedule_name"`
+	ShiftEnd     time.Time `json:"shift_end"`
+	Latitude     float64   `json:"latitude"`
+	Longitude    float64   `json:"longitude"`
+	Accuracy     *float64  `json:"accuracy,omitempty"`
+	RecordedAt   time.Time `json:"recorded_at"`
+}
+
+// parseOwnBookingRequest extracts the authenticated user and booking ID from the request.
+func (h *PatrolTrackingHandler) parseOwnBookingRequest(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return 0, 0, false
+	}
+
+	bookingIDStr := r.PathValue("id")
+	bookingID, err := strconv.ParseInt(bookingIDStr, 10, 64)
+	if err != nil || bookingID <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "Invalid booking ID", h.logger, "booking_id", bookingIDStr)
+		return 0, 0, false
+	}
+	return userID, bookingID, true
+}
+
+func (h *PatrolTrackingHandler) respondWithTrackingError(w http.ResponseWriter, err error, bookingID int64) {
+	switch {
+	case errors.Is(err, service.ErrBookingNotFound):
+		RespondWithError(w, http.StatusNotFound, "Booking not found", h.logger, "booking_id", bookingID)
+	case errors.Is(err, service.ErrForbiddenUpdate):
+		RespondWithError(w, http.StatusForbidden, "You can only share your own patrol location", h.logger, "booking_id", bookingID)
+	case errors.Is(err, service.ErrBookingNotActive), errors.Is(err, service.ErrLocationSharingInactive):
+		RespondWithError(w, http.StatusConflict, err.Error(), h.logger, "booking_id", bookingID)
+	case errors.Is(err, service.ErrNoLocations), errors.Is(err, service.ErrTooManyLocations), errors.Is(err, service.ErrInvalidLocation):
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger, "booking_id", bookingID)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to process location sharing request", h.logger, "error", err.Error())
+	}
+}
+
+// StartSharingHandler handles POST /api/bookings/{id}/location-sharing
+// @Summary Start sharing patrol location
+// @Description Opts a checked-in booking into live location sharing. Sharing stops automatically at check-out or shift end.
+// @Tags bookings
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} LocationSharingResponse "Sharing started"
+// @Failure 400 {object} ErrorResponse "Invalid booking ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not your booking"
+// @Failure 404 {object} ErrorResponse "Booking not found"
+// @Failure 409 {object} ErrorResponse "Booking is not checked in, already checked out or the shift has ended"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/bookings/{id}/location-sharing [post]
+func (h *PatrolTrackingHandler) StartSharingHandler(w http.ResponseWriter, r *http.Request) {
+	userID, bookingID, ok := h.parseOwnBookingRequest(w, r)
+	if !ok {
+		return
+	}
+
+	sharing, err := h.trackingService.StartSharing(r.Context(), bookingID, userID)
+	if err != nil {
+		h.respondWithTrackingError(w, err, bookingID)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, LocationSharingResponse{
+		BookingID: sharing.BookingID,
+		Active:    true,
+		StartedAt: sharing.StartedAt,
+	}, h.logger)
+}
+
+// StopSharingHandler handles DELETE /api/bookings/{id}/location-sharing
+// @Summary Stop sharing patrol location
+// @Description Stops live location sharing for a booking. Breadcrumbs already sent are kept for the retention period.
+// @Tags bookings
+// @Param id path int true "Booking ID"
+// @Success 204 "Sharing stopped"
+// @Failure 400 {object} ErrorResponse "Invalid booking ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not your booking"
+// @Failure 404 {object} ErrorResponse "Booking not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/bookings/{id}/location-sharing [delete]
+func (h *PatrolTrackingHandler) StopSharingHandler(w http.ResponseWriter, r *http.Request) {
+	userID, bookingID, ok := h.parseOwnBookingRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.trackingService.StopSharing(r.Context(), bookingID, userID); err != nil {
+		h.respondWithTrackingError(w, err, bookingID)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RecordLocationsHandler handles POST /api/bookings/{id}/locations
+// @Summary Upload patrol breadcrumbs
+// @Description Stores a batch of GPS breadcrumbs for a booking that is sharing its location. Returns 409 once sharing has stopped, which tells the client to stop sending.
+// @Tags bookings
+// @Accept json
+// @Produce json
+// @Param id path int true "Booking ID"
+// @Param request body RecordLocationsRequest true "Breadcrumbs, at most 100 per request"
+// @Success 200 {object} RecordLocationsResponse "Breadcrumbs stored"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Not your booking"
+// @Failure 404 {object} ErrorResponse "Booking not found"
+// @Failure 409 {object} ErrorResponse "Location sharing is not active"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/bookings/{id}/locations [post]
+func (h *PatrolTrackingHandler) RecordLocationsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, bookingID, ok := h.parseOwnBookingRequest(w, r)
+	if !ok {
+		return
+	}
+
+	var req RecordLocationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	points := make([]service.PatrolLocationPoint, 0, len(req.Locations))
+	for _, loc := range req.Locations {
+		point := service.PatrolLocationPoint{
+			Latitude:  loc.Latitude,
+			Longitude: loc.Longitude,
+			Accuracy:  loc.Accuracy,
+		}
+		if loc.RecordedAt != nil {
+			point.RecordedAt = *loc.RecordedAt
+		}
+		points = append(points, point)
+	}
+
+	recorded, err := h.trackingService.RecordLocations(r.Context(), bookingID, userID, points)
+	if err != nil {
+		h.respondWithTrackingError(w, err, bookingID)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, RecordLocationsResponse{Recorded: recorded}, h.logger)
+}
+
+// AdminListLivePositionsHandler handles GET /api/admin/patrols/live
+// @Summary Live positions of owls on patrol (Admin)
+// @Description Returns the latest breadcrumb of every checked-in owl who is currently sharing their location
+// @Tags admin/patrols
+// @Produce json
+// @Success 200 {array} LivePatrolPositionResponse "Current positions"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/patrols/live [get]
+func (h *PatrolTrackingHandler) AdminListLivePositionsHandler(w http.ResponseWriter, r *http.Request) {
+	positions, err := h.trackingService.ListLivePositions(r.Context(), time.Now())
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to list live positions", h.logger, "error", err.Error())
+		return
+	}
+
+	response := make([]LivePatrolPositionResponse, 0, len(positions))
+	for _, p := range positions {
+		position := LivePatrolPositionResponse{
+			BookingID:    p.BookingID,
+			UserID:       p.UserID,
+			UserName:     p.UserName,
+			ScheduleName: p.ScheduleName,
+			ShiftEnd:     p.ShiftEnd,
+			Latitude:     p.Latitude,
+			Longitude:    p.Longitude,
+			RecordedAt:   p.RecordedAt,
+		}
+		if p.Accuracy.Valid {
+			position.Accuracy = &p.Accuracy.Float64
+		}
+		response = append(response, position)
+	}
+
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminGetTrackHandler handles GET /api/admin/bookings/{id}/track
+// @Summary Patrol track replay (Admin)
+// @Description Returns the breadcrumbs of a shift as a GeoJSON FeatureCollection: a LineString of the route followed by a timestamped Point per breadcrumb
+// @Tags admin/patrols
+// @Produce application/geo+json
+// @Param id path int true "Booking ID"
+// @Success 200 {object} service.GeoJSONFeatureCollection "Patrol track"
+// @Failure 400 {object} ErrorResponse "Invalid booking ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Booking not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/bookings/{id}/track [get]
+func (h *PatrolTrackingHandler) AdminGetTrackHandler(w http.ResponseWriter, r *http.Request) {
+	bookingIDStr := r.PathValue("id")
+	bookingID, err := strconv.ParseInt(bookingIDStr, 10, 64)
+	if err != nil || bookingID <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "Invalid booking ID", h.logger, "booking_id", bookingIDStr)
+		return
+	}
+
+	track, err := h.trackingService.BuildTrack(r.Context(), bookingID)
+	if err != nil {
+		if errors.Is(err, service.ErrBookingNotFound) {
+			RespondWithError(w, http.StatusNotFound, "Booking not found", h.logger, "booking_id", bookingID)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to build patrol track", h.logger, "error", err.Error())
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, track, h.logger)
+}
@@ -0,0 +1,183 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatrolTracking_ShareRecordReplayAndStop(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	_, adminToken := app.createTestUserAndLogin(t, "+15550003101", "Test Admin", "admin")
+	owl, owlToken := app.createTestUserAndLogin(t, "+15550003102", "Tracking Owl", "owl")
+	_, otherToken := app.createTestUserAndLogin(t, "+15550003103", "Other Owl", "owl")
+
+	schedule, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+		Name:            "Tracking Patrol",
+		CronExpr:        "0 0 * * *",
+		DurationMinutes: 120,
+		Timezone:        sql.NullString{String: "UTC", Valid: true},
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	createBooking := func(start, end time.Time, checkedIn bool) int64 {
+		booking, err := app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+			UserID:     owl.UserID,
+			ScheduleID: schedule.ScheduleID,
+			ShiftStart: start,
+			ShiftEnd:   end,
+		})
+		require.NoError(t, err)
+		if checkedIn {
+			_, err = app.Querier.UpdateBookingCheckIn(ctx, db.UpdateBookingCheckInParams{
+				CheckedInAt: sql.NullTime{Time: start, Valid: true},
+				BookingID:   booking.BookingID,
+			})
+			require.NoError(t, err)
+		}
+		return booking.BookingID
+	}
+
+	jsonBody := func(v interface{}) *bytes.Reader {
+		body, err := json.Marshal(v)
+		require.NoError(t, err)
+		return bytes.NewReader(body)
+	}
+
+	sendLocations := func(bookingID int64, token string, locations ...map[string]interface{}) *httptest.ResponseRecorder {
+		return app.makeRequest(t, "POST", fmt.Sprintf("/api/bookings/%d/locations", bookingID), jsonBody(map[string]interface{}{
+			"locations": locations,
+		}), token)
+	}
+
+	activeID := createBooking(now.Add(-30*time.Minute), now.Add(90*time.Minute), true)
+	sharingPath := fmt.Sprintf("/api/bookings/%d/location-sharing", activeID)
+
+	t.Run("sharing is opt-in", func(t *testing.T) {
+		res := sendLocations(activeID, owlToken, map[string]interface{}{"latitude": -33.92, "longitude": 18.42})
+		assert.Equal(t, http.StatusConflict, res.Code, "Response: %s", res.Body.String())
+	})
+
+	t.Run("sharing requires a checked-in booking owned by the caller", func(t *testing.T) {
+		notCheckedIn := createBooking(now.Add(-10*time.Minute), now.Add(110*time.Minute), false)
+		rr := app.makeRequest(t, "POST", fmt.Sprintf("/api/bookings/%d/location-sharing", notCheckedIn), nil, owlToken)
+		assert.Equal(t, http.StatusConflict, rr.Code, "Response: %s", rr.Body.String())
+
+		rr = app.makeRequest(t, "POST", sharingPath, nil, otherToken)
+		assert.Equal(t, http.StatusForbidden, rr.Code, "Response: %s", rr.Body.String())
+	})
+
+	t.Run("breadcrumbs are recorded and shown in the live view", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", sharingPath, nil, owlToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		res := sendLocations(activeID, owlToken,
+			map[string]interface{}{"latitude": -33.9200, "longitude": 18.4200, "accuracy": 8.5},
+			map[string]interface{}{"latitude": -33.9210, "longitude": 18.4210},
+		)
+		require.Equal(t, http.StatusOK, res.Code, "Response: %s", res.Body.String())
+		var recorded api.RecordLocationsResponse
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &recorded))
+		assert.Equal(t, 2, recorded.Recorded)
+
+		res = sendLocations(activeID, owlToken, map[string]interface{}{"latitude": 91.0, "longitude": 18.42})
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+
+		rr = app.makeRequest(t, "GET", "/api/admin/patrols/live", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var positions []api.LivePatrolPositionResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &positions))
+		require.Len(t, positions, 1)
+		assert.Equal(t, activeID, positions[0].BookingID)
+		assert.Equal(t, "Tracking Owl", positions[0].UserName)
+		assert.InDelta(t, -33.9210, positions[0].Latitude, 1e-9)
+	})
+
+	t.Run("track replay is GeoJSON", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/bookings/%d/track", activeID), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var track service.GeoJSONFeatureCollection
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &track))
+		assert.Equal(t, "FeatureCollection", track.Type)
+		require.Len(t, track.Features, 3)
+		assert.Equal(t, "LineString", track.Features[0].Geometry.Type)
+		assert.Equal(t, "Point", track.Features[1].Geometry.Type)
+		assert.Equal(t, []interface{}{18.42, -33.92}, track.Features[1].Geometry.Coordinates)
+		assert.Contains(t, track.Features[1].Properties, "recorded_at")
+		assert.EqualValues(t, 8.5, track.Features[1].Properties["accuracy"])
+
+		rr = app.makeRequest(t, "GET", "/api/admin/bookings/99999/track", nil, adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("check-out stops sharing", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", fmt.Sprintf("/api/bookings/%d/checkout", activeID), nil, owlToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var booking api.BookingResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &booking))
+		assert.NotNil(t, booking.CheckedOutAt)
+
+		rr = app.makeRequest(t, "POST", fmt.Sprintf("/api/bookings/%d/checkout", activeID), nil, owlToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		sharing, err := app.Querier.GetPatrolLocationSharing(ctx, activeID)
+		require.NoError(t, err)
+		assert.True(t, sharing.StoppedAt.Valid)
+		assert.Equal(t, service.PatrolSharingStopCheckOut, sharing.StopReason.String)
+
+		res := sendLocations(activeID, owlToken, map[string]interface{}{"latitude": -33.92, "longitude": 18.42})
+		assert.Equal(t, http.StatusConflict, res.Code)
+
+		rr = app.makeRequest(t, "GET", "/api/admin/patrols/live", nil, adminToken)
+		assert.JSONEq(t, "[]", rr.Body.String())
+	})
+
+	t.Run("sharing stops at shift end", func(t *testing.T) {
+		shortID := createBooking(now.Add(-40*time.Minute), now.Add(30*time.Minute), true)
+		rr := app.makeRequest(t, "POST", fmt.Sprintf("/api/bookings/%d/location-sharing", shortID), nil, owlToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		trackingService := service.NewPatrolTrackingService(app.Querier, app.Config, app.Logger)
+		stopped, err := trackingService.StopEndedSharing(ctx, now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, stopped)
+
+		sharing, err := app.Querier.GetPatrolLocationSharing(ctx, shortID)
+		require.NoError(t, err)
+		assert.Equal(t, service.PatrolSharingStopShiftEnd, sharing.StopReason.String)
+	})
+
+	t.Run("old breadcrumbs are purged after the retention period", func(t *testing.T) {
+		app.Config.PatrolLocationRetention = 7 * 24 * time.Hour
+		trackingService := service.NewPatrolTrackingService(app.Querier, app.Config, app.Logger)
+		deleted, err := trackingService.PurgeOldLocations(ctx, now)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, deleted)
+
+		deleted, err = trackingService.PurgeOldLocations(ctx, now.Add(8*24*time.Hour))
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, deleted)
+
+		locations, err := app.Querier.ListPatrolLocationsByBooking(ctx, activeID)
+		require.NoError(t, err)
+		assert.Empty(t, locations)
+	})
+}
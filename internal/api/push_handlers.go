@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"night-owls-go/internal/config"
 	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
 )
 
 // PushHandler handles push notification related HTTP requests.
@@ -35,11 +37,12 @@ func NewPushHandler(querier db.Querier, cfg *config.Config, logger *slog.Logger)
 //	500: Internal Server Error
 func (h *PushHandler) SubscribePush(w http.ResponseWriter, r *http.Request) {
 	var req struct {
-		Endpoint  string `json:"endpoint" validate:"required"`
-		P256dhKey string `json:"p256dh_key" validate:"required"`
-		AuthKey   string `json:"auth_key" validate:"required"`
-		UserAgent string `json:"user_agent"`
-		Platform  string `json:"platform"`
+		Endpoint    string `json:"endpoint" validate:"required"`
+		P256dhKey   string `json:"p256dh_key" validate:"required"`
+		AuthKey     string `json:"auth_key" validate:"required"`
+		UserAgent   string `json:"user_agent"`
+		Platform    string `json:"platform"`
+		DeviceLabel string `json:"device_label"` // Defaults to a label derived from the user agent
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		RespondWithError(w, http.StatusBadRequest, "invalid body", h.Logger, "error", err)
@@ -54,13 +57,19 @@ func (h *PushHandler) SubscribePush(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	label := strings.TrimSpace(req.DeviceLabel)
+	if label == "" {
+		label = service.PushDeviceLabel(req.UserAgent, req.Platform)
+	}
+
 	params := db.UpsertSubscriptionParams{
-		UserID:    userID,
-		Endpoint:  req.Endpoint,
-		P256dhKey: req.P256dhKey,
-		AuthKey:   req.AuthKey,
-		UserAgent: sql.NullString{String: req.UserAgent, Valid: req.UserAgent != ""},
-		Platform:  sql.NullString{String: req.Platform, Valid: req.Platform != ""},
+		UserID:      userID,
+		Endpoint:    req.Endpoint,
+		P256dhKey:   req.P256dhKey,
+		AuthKey:     req.AuthKey,
+		UserAgent:   sql.NullString{String: req.UserAgent, Valid: req.UserAgent != ""},
+		Platform:    sql.NullString{String: req.Platform, Valid: req.Platform != ""},
+		DeviceLabel: sql.NullString{String: label, Valid: true},
 	}
 
 	if err := h.DB.UpsertSubscription(r.Context(), params); err != nil {
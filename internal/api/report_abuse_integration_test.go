@@ -0,0 +1,162 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffShiftReports_AbuseControls(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	ctx := context.Background()
+	_, adminToken := app.createTestUserAndLogin(t, "+15550003501", "Test Admin", "admin")
+
+	fileReport := func(token, clientIP string, report map[string]interface{}) *httptest.ResponseRecorder {
+		body, err := json.Marshal(report)
+		require.NoError(t, err)
+		req, err := http.NewRequest("POST", "/api/reports/off-shift", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("X-Forwarded-For", clientIP)
+		rr := httptest.NewRecorder()
+		app.Router.ServeHTTP(rr, req)
+		return rr
+	}
+	simpleReport := map[string]interface{}{"severity": 0, "message": "Suspicious vehicle"}
+
+	t.Run("guests cannot file off-shift reports", func(t *testing.T) {
+		_, guestToken := app.createTestUserAndLogin(t, "+15550003502", "Guest", "guest")
+		rr := fileReport(guestToken, "198.51.100.1", simpleReport)
+		assert.Equal(t, http.StatusForbidden, rr.Code, "Response: %s", rr.Body.String())
+	})
+
+	t.Run("location data is validated", func(t *testing.T) {
+		_, owlToken := app.createTestUserAndLogin(t, "+15550003503", "Lost Owl", "owl")
+		rr := fileReport(owlToken, "198.51.100.2", map[string]interface{}{"severity": 1, "latitude": 123.4, "longitude": 18.4})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+		rr = fileReport(owlToken, "198.51.100.2", map[string]interface{}{"severity": 1, "latitude": -33.9, "longitude": 18.4, "location_timestamp": future})
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = fileReport(owlToken, "198.51.100.2", map[string]interface{}{"severity": 1, "latitude": -33.9, "longitude": 18.4})
+		assert.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+	})
+
+	t.Run("each user and IP is rate limited", func(t *testing.T) {
+		const sharedIP = "203.0.113.7"
+		var tokens []string
+		for i := 0; i < 4; i++ {
+			_, token := app.createTestUserAndLogin(t, fmt.Sprintf("+1555000351%d", i), fmt.Sprintf("Busy Owl %d", i), "owl")
+			tokens = append(tokens, token)
+		}
+
+		for i := 0; i < service.MaxOffShiftReportsPerUser; i++ {
+			require.Equal(t, http.StatusCreated, fileReport(tokens[0], sharedIP, simpleReport).Code)
+		}
+		rr := fileReport(tokens[0], sharedIP, simpleReport)
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code, "the user limit should be reached")
+		rr = fileReport(tokens[0], "198.51.100.3", simpleReport)
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code, "switching IP should not reset the user limit")
+
+		for _, token := range tokens[1:3] {
+			for i := 0; i < service.MaxOffShiftReportsPerUser; i++ {
+				require.Equal(t, http.StatusCreated, fileReport(token, sharedIP, simpleReport).Code)
+			}
+		}
+		rr = fileReport(tokens[3], sharedIP, simpleReport)
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code, "the IP limit should be reached")
+		rr = fileReport(tokens[3], "198.51.100.4", simpleReport)
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("flags lower the reporter's trust score", func(t *testing.T) {
+		reporter, reporterToken := app.createTestUserAndLogin(t, "+15550003520", "Noisy Owl", "owl")
+		var reportIDs []int64
+		for i := 0; i < 3; i++ {
+			report, err := app.Querier.CreateOffShiftReport(ctx, db.CreateOffShiftReportParams{
+				UserID:   sql.NullInt64{Int64: reporter.UserID, Valid: true},
+				Severity: 2,
+				Message:  sql.NullString{String: "Intruder!", Valid: true},
+			})
+			require.NoError(t, err)
+			reportIDs = append(reportIDs, report.ReportID)
+		}
+		flagPath := func(reportID int64) string { return fmt.Sprintf("/api/admin/reports/%d/flag", reportID) }
+		flag := func(reportID int64, flag string) *httptest.ResponseRecorder {
+			body, err := json.Marshal(api.FlagReportRequest{Flag: flag, Note: "Neighbour's cat"})
+			require.NoError(t, err)
+			return app.makeRequest(t, "PUT", flagPath(reportID), bytes.NewReader(body), adminToken)
+		}
+
+		assert.Equal(t, http.StatusBadRequest, flag(reportIDs[0], "boring").Code)
+		assert.Equal(t, http.StatusNotFound, flag(999999, service.ReportFlagSpam).Code)
+		assert.Equal(t, http.StatusNotFound, app.makeRequest(t, "DELETE", flagPath(reportIDs[0]), nil, adminToken).Code)
+
+		rr := flag(reportIDs[0], service.ReportFlagFalseAlarm)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var flagged api.ReportFlagResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &flagged))
+		assert.Equal(t, service.ReportFlagFalseAlarm, flagged.Flag)
+		require.NotNil(t, flagged.ReporterTrust)
+		assert.EqualValues(t, service.MaxReporterTrustScore-service.FalseAlarmFlagPenalty, flagged.ReporterTrust.Score)
+
+		// Re-flagging replaces the earlier verdict
+		for _, reportID := range reportIDs {
+			require.Equal(t, http.StatusOK, flag(reportID, service.ReportFlagSpam).Code)
+		}
+
+		rr = app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/users/%d/trust-score", reporter.UserID), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var trust service.ReporterTrust
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &trust))
+		assert.EqualValues(t, 3, trust.SpamFlags)
+		assert.EqualValues(t, 0, trust.FalseAlarmFlags)
+		assert.EqualValues(t, service.MaxReporterTrustScore-3*service.SpamFlagPenalty, trust.Score)
+		assert.True(t, trust.Suspended)
+
+		rr = fileReport(reporterToken, "198.51.100.5", simpleReport)
+		assert.Equal(t, http.StatusForbidden, rr.Code, "a suspended reporter cannot file off-shift reports")
+
+		rr = app.makeRequest(t, "GET", fmt.Sprintf("/api/admin/reports/%d", reportIDs[0]), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var adminReport api.AdminReportResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &adminReport))
+		require.NotNil(t, adminReport.Flag)
+		assert.Equal(t, service.ReportFlagSpam, adminReport.Flag.Flag)
+		assert.Equal(t, "Neighbour's cat", adminReport.Flag.Note)
+
+		rr = app.makeRequest(t, "DELETE", flagPath(reportIDs[0]), nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &flagged))
+		assert.False(t, flagged.ReporterTrust.Suspended)
+
+		rr = fileReport(reporterToken, "198.51.100.5", simpleReport)
+		assert.Equal(t, http.StatusCreated, rr.Code, "clearing a flag restores reporting")
+
+		var auditCount int
+		require.NoError(t, app.DB.QueryRow(`SELECT COUNT(*) FROM audit_events WHERE event_type IN ('report.flagged', 'report.unflagged')`).Scan(&auditCount))
+		assert.Equal(t, 5, auditCount)
+	})
+
+	t.Run("trust scores need a real user", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/users/999999/trust-score", nil, adminToken)
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
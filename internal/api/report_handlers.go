@@ -31,22 +31,26 @@ func NewReportHandler(reportService *service.ReportService, auditService *servic
 
 // CreateReportRequest is the expected JSON for POST /bookings/{id}/report.
 type CreateReportRequest struct {
-	Severity          int32    `json:"severity"` // 0, 1, or 2
-	Message           string   `json:"message,omitempty"`
-	Latitude          *float64 `json:"latitude,omitempty"`
-	Longitude         *float64 `json:"longitude,omitempty"`
-	Accuracy          *float64 `json:"accuracy,omitempty"`
-	LocationTimestamp *string  `json:"location_timestamp,omitempty"`
+	Severity          int32                  `json:"severity"` // 0, 1, or 2
+	Message           string                 `json:"message,omitempty"`
+	Latitude          *float64               `json:"latitude,omitempty"`
+	Longitude         *float64               `json:"longitude,omitempty"`
+	Accuracy          *float64               `json:"accuracy,omitempty"`
+	LocationTimestamp *string                `json:"location_timestamp,omitempty"`
+	CategoryID        *int64                 `json:"category_id,omitempty"`
+	CategoryFields    map[string]interface{} `json:"category_fields,omitempty"`
 }
 
 // CreateOffShiftReportRequest is the expected JSON for POST /reports/off-shift.
 type CreateOffShiftReportRequest struct {
-	Severity          int32    `json:"severity"` // 0, 1, or 2
-	Message           string   `json:"message,omitempty"`
-	Latitude          *float64 `json:"latitude,omitempty"`
-	Longitude         *float64 `json:"longitude,omitempty"`
-	Accuracy          *float64 `json:"accuracy,omitempty"`
-	LocationTimestamp *string  `json:"location_timestamp,omitempty"`
+	Severity          int32                  `json:"severity"` // 0, 1, or 2
+	Message           string                 `json:"message,omitempty"`
+	Latitude          *float64               `json:"latitude,omitempty"`
+	Longitude         *float64               `json:"longitude,omitempty"`
+	Accuracy          *float64               `json:"accuracy,omitempty"`
+	LocationTimestamp *string                `json:"location_timestamp,omitempty"`
+	CategoryID        *int64                 `json:"category_id,omitempty"`
+	CategoryFields    map[string]interface{} `json:"category_fields,omitempty"`
 }
 
 // UserReportResponse is the user-facing report response (fewer fields than admin)
@@ -63,6 +67,24 @@ type UserReportResponse struct {
 	ScheduleName *string    `json:"schedule_name,omitempty"`
 	ShiftStart   *time.Time `json:"shift_start,omitempty"`
 	ShiftEnd     *time.Time `json:"shift_end,omitempty"`
+
+	CategoryID     *int64                 `json:"category_id,omitempty"`
+	CategoryFields map[string]interface{} `json:"category_fields,omitempty"`
+}
+
+// toReportCategoryInput builds the category input for a report request.
+// Structured fields are only accepted together with a category.
+func toReportCategoryInput(categoryID *int64, fields map[string]interface{}) (*service.ReportCategoryInput, error) {
+	if categoryID == nil {
+		if len(fields) > 0 {
+			return nil, errors.New("category_fields requires a category_id")
+		}
+		return nil, nil
+	}
+	if *categoryID <= 0 {
+		return nil, errors.New("invalid category_id")
+	}
+	return &service.ReportCategoryInput{CategoryID: *categoryID, Fields: fields}, nil
 }
 
 // CreateReportHandler handles POST /bookings/{id}/report
@@ -140,7 +162,13 @@ func (h *ReportHandler) CreateReportHandler(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	report, err := h.reportService.CreateReport(r.Context(), userID, bookingID, req.Severity, messageSQL.String, gpsLocation)
+	category, err := toReportCategoryInput(req.CategoryID, req.CategoryFields)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+
+	report, err := h.reportService.CreateReport(r.Context(), userID, bookingID, req.Severity, messageSQL.String, gpsLocation, category)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrReportBookingAuth):
@@ -148,6 +176,10 @@ func (h *ReportHandler) CreateReportHandler(w http.ResponseWriter, r *http.Reque
 			RespondWithError(w, http.StatusForbidden, "Not authorized to create report for this booking", h.logger, "booking_id", bookingID)
 		case errors.Is(err, service.ErrSeverityOutOfRange):
 			RespondWithError(w, http.StatusBadRequest, "Severity must be 0, 1, or 2", h.logger, "severity", req.Severity)
+		case errors.Is(err, service.ErrIncidentCategoryNotFound):
+			RespondWithError(w, http.StatusBadRequest, "Unknown incident category", h.logger, "category_id", *req.CategoryID)
+		case errors.Is(err, service.ErrInvalidCategoryFields):
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
 		default:
 			RespondWithError(w, http.StatusInternalServerError, "Failed to create report", h.logger, "error", err.Error())
 		}
@@ -235,6 +267,12 @@ func (h *ReportHandler) ListReportsHandler(w http.ResponseWriter, r *http.Reques
 			userReport.GPSTimestamp = &report.GpsTimestamp.Time
 		}
 
+		// Handle category fields
+		if report.CategoryID.Valid {
+			userReport.CategoryID = &report.CategoryID.Int64
+		}
+		userReport.CategoryFields = ToCategoryFieldValues(report.CategoryFields)
+
 		// Get schedule info if this is a shift report
 		if report.BookingID.Valid {
 			booking, err := h.reportService.GetBookingDetails(r.Context(), report.BookingID.Int64)
@@ -253,14 +291,16 @@ func (h *ReportHandler) ListReportsHandler(w http.ResponseWriter, r *http.Reques
 
 // CreateOffShiftReportHandler handles POST /reports/off-shift
 // @Summary Create an off-shift report
-// @Description Submits an incident report when not on a scheduled shift
+// @Description Submits an incident report when not on a scheduled shift. Only owls and admins may file off-shift reports, each user and client IP is rate limited, and reporting is suspended for users whose reports are repeatedly flagged as spam or false alarms.
 // @Tags reports
 // @Accept json
 // @Produce json
 // @Param request body CreateOffShiftReportRequest true "Report details"
 // @Success 201 {object} ReportResponse "Report created successfully"
-// @Failure 400 {object} ErrorResponse "Invalid request format or severity out of range"
+// @Failure 400 {object} ErrorResponse "Invalid request format, severity out of range or invalid location"
 // @Failure 401 {object} ErrorResponse "Unauthorized - authentication required"
+// @Failure 403 {object} ErrorResponse "Role not allowed or reporting suspended"
+// @Failure 429 {object} ErrorResponse "Too many off-shift reports"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Security BearerAuth
 // @Router /reports/off-shift [post]
@@ -303,11 +343,28 @@ func (h *ReportHandler) CreateOffShiftReportHandler(w http.ResponseWriter, r *ht
 		}
 	}
 
-	report, err := h.reportService.CreateOffShiftReport(r.Context(), userID, req.Severity, messageSQL.String, gpsLocation)
+	category, err := toReportCategoryInput(req.CategoryID, req.CategoryFields)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		return
+	}
+
+	clientIP, _ := extractClientInfo(r)
+	report, err := h.reportService.CreateOffShiftReport(r.Context(), userID, clientIP, req.Severity, messageSQL.String, gpsLocation, category)
 	if err != nil {
 		switch {
+		case errors.Is(err, service.ErrOffShiftReportForbidden), errors.Is(err, service.ErrReporterTrustTooLow):
+			RespondWithError(w, http.StatusForbidden, err.Error(), h.logger, "user_id", userID)
+		case errors.Is(err, service.ErrOffShiftReportRateLimited):
+			RespondWithError(w, http.StatusTooManyRequests, err.Error(), h.logger, "user_id", userID, "client_ip", clientIP)
 		case errors.Is(err, service.ErrSeverityOutOfRange):
 			RespondWithError(w, http.StatusBadRequest, "Severity must be 0, 1, or 2", h.logger, "severity", req.Severity)
+		case errors.Is(err, service.ErrInvalidLocation), errors.Is(err, service.ErrInvalidGPSTimestamp):
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		case errors.Is(err, service.ErrIncidentCategoryNotFound):
+			RespondWithError(w, http.StatusBadRequest, "Unknown incident category", h.logger, "category_id", *req.CategoryID)
+		case errors.Is(err, service.ErrInvalidCategoryFields):
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
 		default:
 			RespondWithError(w, http.StatusInternalServerError, "Failed to create off-shift report", h.logger, "error", err.Error())
 		}
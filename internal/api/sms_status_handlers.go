@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"night-owls-go/internal/outbox"
+)
+
+// SMSStatusHandler receives delivery report callbacks from the SMS provider.
+type SMSStatusHandler struct {
+	provider   outbox.SMSProvider
+	dispatcher *outbox.DispatcherService
+	logger     *slog.Logger
+}
+
+// NewSMSStatusHandler creates a new SMSStatusHandler. Callbacks are refused
+// unless smsSender is an SMS gateway provider.
+func NewSMSStatusHandler(smsSender outbox.MessageSender, dispatcher *outbox.DispatcherService, logger *slog.Logger) *SMSStatusHandler {
+	provider, _ := smsSender.(outbox.SMSProvider)
+	return &SMSStatusHandler{
+		provider:   provider,
+		dispatcher: dispatcher,
+		logger:     logger.With("handler", "SMSStatusHandler"),
+	}
+}
+
+// SMSStatusCallbackHandler handles POST /api/sms/status/{provider}
+// @Summary SMS delivery report callback
+// @Description Receives delivery reports from the configured SMS provider and records them on the matching outbox items. Twilio callbacks are verified by signature; other providers must pass the shared callback token.
+// @Tags sms
+// @Accept json
+// @Accept x-www-form-urlencoded
+// @Param provider path string true "Provider name: twilio, clickatell or bulksms"
+// @Param token query string false "Shared callback token"
+// @Success 204 "Report recorded"
+// @Failure 400 {object} ErrorResponse "Malformed report"
+// @Failure 403 {object} ErrorResponse "Callback failed authentication"
+// @Failure 404 {object} ErrorResponse "Provider is not in use"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/sms/status/{provider} [post]
+func (h *SMSStatusHandler) SMSStatusCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("provider")
+	if h.provider == nil || h.provider.Name() != name {
+		RespondWithError(w, http.StatusNotFound, "SMS provider not in use", h.logger, "provider", name)
+		return
+	}
+
+	reports, err := h.provider.ParseDeliveryReports(r)
+	if err != nil {
+		switch {
+		case errors.Is(err, outbox.ErrUnauthorizedDeliveryReport):
+			RespondWithError(w, http.StatusForbidden, "Invalid callback credentials", h.logger, "provider", name)
+		default:
+			RespondWithError(w, http.StatusBadRequest, "Invalid delivery report", h.logger, "provider", name, "error", err)
+		}
+		return
+	}
+
+	if _, err := h.dispatcher.ApplyDeliveryReports(r.Context(), name, reports); err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to record delivery report", h.logger, "error", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
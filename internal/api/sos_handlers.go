@@ -0,0 +1,372 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+)
+
+// SOSHandler handles raising, acknowledging and standing down SOS alerts.
+type SOSHandler struct {
+	sosService   *service.SOSService
+	auditService *service.AuditService
+	logger       *slog.Logger
+}
+
+// NewSOSHandler creates a new SOSHandler.
+func NewSOSHandler(sosService *service.SOSService, auditService *service.AuditService, logger *slog.Logger) *SOSHandler {
+	return &SOSHandler{
+		sosService:   sosService,
+		auditService: auditService,
+		logger:       logger.With("handler", "SOSHandler"),
+	}
+}
+
+// RaiseSOSRequest is the body of an SOS. Every field is optional so the
+// client can send it with a single tap, even without a GPS fix.
+type RaiseSOSRequest struct {
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Accuracy  *float64 `json:"accuracy,omitempty"`
+	Message   string   `json:"message,omitempty"`
+}
+
+// StandDownSOSRequest is the body for standing an SOS down
+type StandDownSOSRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// SOSAcknowledgementResponse is a responder who has acknowledged an SOS
+type SOSAcknowledgementResponse struct {
+	UserID         int64      `json:"user_id"`
+	UserName       string     `json:"user_name"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+}
+
+// SOSAlertResponse describes an SOS alert
+type SOSAlertResponse struct {
+	AlertID              int64                        `json:"alert_id"`
+	UserID               int64                        `json:"user_id"`
+	UserName             string                       `json:"user_name,omitempty"`
+	UserPhone            string                       `json:"user_phone,omitempty"`
+	BookingID            *int64                       `json:"booking_id,omitempty"`
+	ReportID             *int64                       `json:"report_id,omitempty"`
+	Latitude             *float64                     `json:"latitude,omitempty"`
+	Longitude            *float64                     `json:"longitude,omitempty"`
+	Accuracy             *float64                     `json:"accuracy,omitempty"`
+	Status               string                       `json:"status"` // active or stood_down
+	StoodDownByUserID    *int64                       `json:"stood_down_by_user_id,omitempty"`
+	StoodDownAt          *time.Time                   `json:"stood_down_at,omitempty"`
+	StandDownNote        string                       `json:"stand_down_note,omitempty"`
+	CreatedAt            *time.Time                   `json:"created_at,omitempty"`
+	AcknowledgementCount int64                        `json:"acknowledgement_count"`
+	Acknowledgements     []SOSAcknowledgementResponse `json:"acknowledgements,omitempty"`
+}
+
+func toSOSAlertResponse(alert db.SosAlert, acks []db.ListSOSAcknowledgementsRow) SOSAlertResponse {
+	response := SOSAlertResponse{
+		AlertID:              alert.AlertID,
+		UserID:               alert.UserID,
+		Status:               alert.Status,
+		StandDownNote:        alert.StandDownNote.String,
+		AcknowledgementCount: int64(len(acks)),
+	}
+	if alert.BookingID.Valid {
+		response.BookingID = &alert.BookingID.Int64
+	}
+	if alert.ReportID.Valid {
+		response.ReportID = &alert.ReportID.Int64
+	}
+	if alert.Latitude.Valid && alert.Longitude.Valid {
+		response.Latitude = &alert.Latitude.Float64
+		response.Longitude = &alert.Longitude.Float64
+	}
+	if alert.Accuracy.Valid {
+		response.Accuracy = &alert.Accuracy.Float64
+	}
+	if alert.StoodDownByUserID.Valid {
+		response.StoodDownByUserID = &alert.StoodDownByUserID.Int64
+	}
+	if alert.StoodDownAt.Valid {
+		response.StoodDownAt = &alert.StoodDownAt.Time
+	}
+	if alert.CreatedAt.Valid {
+		response.CreatedAt = &alert.CreatedAt.Time
+	}
+	for _, ack := range acks {
+		ackResponse := SOSAcknowledgementResponse{UserID: ack.UserID, UserName: ack.UserName}
+		if ack.AcknowledgedAt.Valid {
+			ackResponse.AcknowledgedAt = &ack.AcknowledgedAt.Time
+		}
+		response.Acknowledgements = append(response.Acknowledgements, ackResponse)
+	}
+	return response
+}
+
+func (h *SOSHandler) parseAlertID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	alertIDStr := r.PathValue("id")
+	alertID, err := strconv.ParseInt(alertIDStr, 10, 64)
+	if err != nil || alertID <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "Invalid SOS alert ID", h.logger, "alert_id", alertIDStr)
+		return 0, false
+	}
+	return alertID, true
+}
+
+func (h *SOSHandler) respondWithSOSError(w http.ResponseWriter, err error, alertID int64) {
+	switch {
+	case errors.Is(err, service.ErrSOSAlertNotFound):
+		RespondWithError(w, http.StatusNotFound, "SOS alert not found", h.logger, "alert_id", alertID)
+	case errors.Is(err, service.ErrSOSAckForbidden):
+		RespondWithError(w, http.StatusForbidden, "You were not alerted for this SOS", h.logger, "alert_id", alertID)
+	case errors.Is(err, service.ErrSOSAlertStoodDown):
+		RespondWithError(w, http.StatusConflict, "SOS alert has already been stood down", h.logger, "alert_id", alertID)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to process SOS alert", h.logger, "error", err.Error())
+	}
+}
+
+// RaiseSOSHandler handles POST /api/sos
+// @Summary Raise an SOS
+// @Description One-tap panic alert. Files a severity-2 report, pushes to every on-duty owl and admin and texts the designated responders immediately. If the caller already has an active SOS it is returned with 200 and nobody is alerted again.
+// @Tags sos
+// @Accept json
+// @Produce json
+// @Param request body RaiseSOSRequest false "Current location and optional message"
+// @Success 201 {object} SOSAlertResponse "SOS raised"
+// @Success 200 {object} SOSAlertResponse "Existing active SOS"
+// @Failure 400 {object} ErrorResponse "Invalid location"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/sos [post]
+func (h *SOSHandler) RaiseSOSHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	var req RaiseSOSRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	alert, created, err := h.sosService.RaiseSOS(r.Context(), userID, service.SOSInput{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Accuracy:  req.Accuracy,
+		Message:   req.Message,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidLocation) {
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to raise SOS", h.logger, "error", err.Error())
+		return
+	}
+
+	response := toSOSAlertResponse(alert, nil)
+	if !created {
+		RespondWithJSON(w, http.StatusOK, response, h.logger)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if auditErr := h.auditService.LogSOSRaised(r.Context(), userID, alert.AlertID, response.ReportID, response.BookingID, response.Latitude, response.Longitude, ipAddress, userAgent); auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log SOS raised audit event", "alert_id", alert.AlertID, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusCreated, response, h.logger)
+}
+
+// AcknowledgeSOSHandler handles POST /api/sos/{id}/acknowledge
+// @Summary Acknowledge an SOS
+// @Description Records that the caller is responding to an active SOS. Admins and owls who received the alert may acknowledge. Acknowledging twice is harmless.
+// @Tags sos
+// @Produce json
+// @Param id path int true "SOS alert ID"
+// @Success 200 {object} SOSAlertResponse "Alert with acknowledgements"
+// @Failure 400 {object} ErrorResponse "Invalid SOS alert ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "User was not alerted for this SOS"
+// @Failure 404 {object} ErrorResponse "SOS alert not found"
+// @Failure 409 {object} ErrorResponse "SOS alert already stood down"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/sos/{id}/acknowledge [post]
+func (h *SOSHandler) AcknowledgeSOSHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+	role, _ := r.Context().Value(UserRoleKey).(string)
+
+	alertID, ok := h.parseAlertID(w, r)
+	if !ok {
+		return
+	}
+
+	alert, acks, added, err := h.sosService.Acknowledge(r.Context(), alertID, userID, role == "admin")
+	if err != nil {
+		h.respondWithSOSError(w, err, alertID)
+		return
+	}
+
+	if added {
+		ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+		if auditErr := h.auditService.LogSOSAcknowledged(r.Context(), userID, alertID, ipAddress, userAgent); auditErr != nil {
+			h.logger.WarnContext(r.Context(), "Failed to log SOS acknowledgement audit event", "alert_id", alertID, "error", auditErr)
+		}
+	}
+
+	RespondWithJSON(w, http.StatusOK, toSOSAlertResponse(alert, acks), h.logger)
+}
+
+// AdminListSOSAlertsHandler handles GET /api/admin/sos
+// @Summary List SOS alerts (Admin)
+// @Description Returns recent SOS alerts, newest first
+// @Tags admin/sos
+// @Produce json
+// @Param status query string false "Filter by status (active or stood_down)"
+// @Param limit query int false "Maximum alerts to return (default 50)"
+// @Success 200 {array} SOSAlertResponse "SOS alerts"
+// @Failure 400 {object} ErrorResponse "Invalid status or limit"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/sos [get]
+func (h *SOSHandler) AdminListSOSAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	limit := int64(50)
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || parsed <= 0 || parsed > 500 {
+			RespondWithError(w, http.StatusBadRequest, "limit must be between 1 and 500", h.logger, "limit", limitStr)
+			return
+		}
+		limit = parsed
+	}
+
+	alerts, err := h.sosService.ListAlerts(r.Context(), r.URL.Query().Get("status"), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSOSStatus) {
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to list SOS alerts", h.logger, "error", err.Error())
+		return
+	}
+
+	response := make([]SOSAlertResponse, 0, len(alerts))
+	for _, row := range alerts {
+		item := toSOSAlertResponse(db.SosAlert{
+			AlertID:           row.AlertID,
+			UserID:            row.UserID,
+			BookingID:         row.BookingID,
+			ReportID:          row.ReportID,
+			Latitude:          row.Latitude,
+			Longitude:         row.Longitude,
+			Accuracy:          row.Accuracy,
+			Status:            row.Status,
+			StoodDownByUserID: row.StoodDownByUserID,
+			StoodDownAt:       row.StoodDownAt,
+			StandDownNote:     row.StandDownNote,
+			CreatedAt:         row.CreatedAt,
+		}, nil)
+		item.UserName = row.UserName
+		item.UserPhone = row.UserPhone
+		item.AcknowledgementCount = row.AcknowledgementCount
+		response = append(response, item)
+	}
+
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminGetSOSAlertHandler handles GET /api/admin/sos/{id}
+// @Summary Get an SOS alert (Admin)
+// @Description Returns an SOS alert with everyone who has acknowledged it
+// @Tags admin/sos
+// @Produce json
+// @Param id path int true "SOS alert ID"
+// @Success 200 {object} SOSAlertResponse "Alert with acknowledgements"
+// @Failure 400 {object} ErrorResponse "Invalid SOS alert ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "SOS alert not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/sos/{id} [get]
+func (h *SOSHandler) AdminGetSOSAlertHandler(w http.ResponseWriter, r *http.Request) {
+	alertID, ok := h.parseAlertID(w, r)
+	if !ok {
+		return
+	}
+
+	alert, acks, err := h.sosService.GetAlert(r.Context(), alertID)
+	if err != nil {
+		h.respondWithSOSError(w, err, alertID)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, toSOSAlertResponse(alert, acks), h.logger)
+}
+
+// AdminStandDownSOSHandler handles POST /api/admin/sos/{id}/stand-down
+// @Summary Stand down an SOS (Admin)
+// @Description Closes an active SOS, acknowledges its escalation and notifies everyone who was alerted
+// @Tags admin/sos
+// @Accept json
+// @Produce json
+// @Param id path int true "SOS alert ID"
+// @Param request body StandDownSOSRequest false "Optional note"
+// @Success 200 {object} SOSAlertResponse "Alert stood down"
+// @Failure 400 {object} ErrorResponse "Invalid SOS alert ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "SOS alert not found"
+// @Failure 409 {object} ErrorResponse "SOS alert already stood down"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/sos/{id}/stand-down [post]
+func (h *SOSHandler) AdminStandDownSOSHandler(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	alertID, ok := h.parseAlertID(w, r)
+	if !ok {
+		return
+	}
+
+	var req StandDownSOSRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	alert, acks, err := h.sosService.StandDown(r.Context(), alertID, adminUserID, req.Note)
+	if err != nil {
+		h.respondWithSOSError(w, err, alertID)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if auditErr := h.auditService.LogSOSStoodDown(r.Context(), adminUserID, alertID, alert.StandDownNote.String, len(acks), ipAddress, userAgent); auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log SOS stand-down audit event", "alert_id", alertID, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusOK, toSOSAlertResponse(alert, acks), h.logger)
+}
@@ -0,0 +1,199 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/api"
+	db "night-owls-go/internal/db/sqlc_generated"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSOS_RaiseAcknowledgeAndStandDown(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	app.Config.EscalationSMSDelay = 10 * time.Minute
+	app.Config.EscalationResponderPhones = []string{"+15550003299"}
+	app.mockSMSSender.On("Send", "+15550003299", "sms", mock.Anything).Return(nil)
+
+	ctx := context.Background()
+	admin, adminToken := app.createTestUserAndLogin(t, "+15550003201", "Test Admin", "admin")
+	owl, owlToken := app.createTestUserAndLogin(t, "+15550003202", "Panicked Owl", "owl")
+	responder, responderToken := app.createTestUserAndLogin(t, "+15550003203", "Responding Owl", "owl")
+	_, offDutyToken := app.createTestUserAndLogin(t, "+15550003204", "Off Duty Owl", "owl")
+
+	schedule, err := app.Querier.CreateSchedule(ctx, db.CreateScheduleParams{
+		Name:            "SOS Patrol",
+		CronExpr:        "0 0 * * *",
+		DurationMinutes: 120,
+		Timezone:        sql.NullString{String: "UTC", Valid: true},
+	})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	booking, err := app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+		UserID:     owl.UserID,
+		ScheduleID: schedule.ScheduleID,
+		ShiftStart: now.Add(-30 * time.Minute),
+		ShiftEnd:   now.Add(90 * time.Minute),
+	})
+	require.NoError(t, err)
+	_, err = app.Querier.CreateBooking(ctx, db.CreateBookingParams{
+		UserID:     responder.UserID,
+		ScheduleID: schedule.ScheduleID,
+		ShiftStart: now.Add(-20 * time.Minute),
+		ShiftEnd:   now.Add(100 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"latitude":  -33.9249,
+		"longitude": 18.4241,
+		"accuracy":  12.0,
+	})
+	require.NoError(t, err)
+	rr := app.makeRequest(t, "POST", "/api/sos", bytes.NewReader(body), owlToken)
+	require.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+
+	var alert api.SOSAlertResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &alert))
+	assert.Equal(t, "active", alert.Status)
+	require.NotNil(t, alert.ReportID)
+	require.NotNil(t, alert.BookingID)
+	assert.Equal(t, booking.BookingID, *alert.BookingID)
+
+	t.Run("a severity-2 report is filed for the active booking", func(t *testing.T) {
+		report, err := app.Querier.AdminGetReportWithContext(ctx, *alert.ReportID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, report.Severity)
+		assert.Equal(t, booking.BookingID, report.BookingID.Int64)
+		assert.Contains(t, report.Message.String, "Panicked Owl")
+		assert.InDelta(t, -33.9249, report.Latitude.Float64, 1e-9)
+	})
+
+	t.Run("push and SMS are dispatched without waiting for the outbox run", func(t *testing.T) {
+		escalation, err := app.Querier.GetIncidentEscalationByReportID(ctx, *alert.ReportID)
+		require.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			messages, err := app.Querier.ListEscalationMessages(ctx, escalation.EscalationID)
+			if err != nil || len(messages) == 0 {
+				return false
+			}
+			for _, message := range messages {
+				if message.Status != "sent" {
+					return false
+				}
+			}
+			return true
+		}, 5*time.Second, 20*time.Millisecond)
+
+		messages, err := app.Querier.ListEscalationMessages(ctx, escalation.EscalationID)
+		require.NoError(t, err)
+		pushUsers := []int64{}
+		smsRecipients := []string{}
+		for _, message := range messages {
+			if message.Stage == "push" {
+				pushUsers = append(pushUsers, message.UserID.Int64)
+			} else {
+				smsRecipients = append(smsRecipients, message.Recipient)
+			}
+		}
+		assert.ElementsMatch(t, []int64{admin.UserID, responder.UserID}, pushUsers)
+		assert.Equal(t, []string{"+15550003299"}, smsRecipients)
+
+		sms, err := app.Querier.GetRecentOutboxItemsByRecipient(ctx, db.GetRecentOutboxItemsByRecipientParams{Recipient: "+15550003299", Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, sms, 1)
+		assert.Contains(t, sms[0].Payload.String, "SOS")
+		assert.Contains(t, sms[0].Payload.String, "Panicked Owl")
+	})
+
+	t.Run("a second tap returns the active alert", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", "/api/sos", http.NoBody, owlToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var again api.SOSAlertResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &again))
+		assert.Equal(t, alert.AlertID, again.AlertID)
+	})
+
+	t.Run("a user can have only one active alert", func(t *testing.T) {
+		_, err := app.Querier.CreateSOSAlert(ctx, db.CreateSOSAlertParams{UserID: owl.UserID})
+		assert.ErrorContains(t, err, "UNIQUE constraint failed")
+	})
+
+	ackPath := fmt.Sprintf("/api/sos/%d/acknowledge", alert.AlertID)
+
+	t.Run("alerted owls and admins acknowledge", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", ackPath, nil, offDutyToken)
+		assert.Equal(t, http.StatusForbidden, rr.Code, "Response: %s", rr.Body.String())
+
+		rr = app.makeRequest(t, "POST", ackPath, nil, responderToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		rr = app.makeRequest(t, "POST", ackPath, nil, responderToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		rr = app.makeRequest(t, "POST", ackPath, nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var acked api.SOSAlertResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &acked))
+		assert.EqualValues(t, 2, acked.AcknowledgementCount)
+		assert.Equal(t, "active", acked.Status)
+
+		events, err := app.Querier.ListAuditEventsByType(ctx, db.ListAuditEventsByTypeParams{EventType: "sos.acknowledged", Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, events, 2)
+	})
+
+	t.Run("admin lists active alerts", func(t *testing.T) {
+		rr := app.makeRequest(t, "GET", "/api/admin/sos?status=active", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		var alerts []api.SOSAlertResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &alerts))
+		require.Len(t, alerts, 1)
+		assert.Equal(t, "Panicked Owl", alerts[0].UserName)
+		assert.EqualValues(t, 2, alerts[0].AcknowledgementCount)
+
+		rr = app.makeRequest(t, "GET", "/api/admin/sos?status=resolved", nil, adminToken)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("admin stands the alert down", func(t *testing.T) {
+		body, err := json.Marshal(map[string]string{"note": "Owl is safe, false alarm"})
+		require.NoError(t, err)
+		standDownPath := fmt.Sprintf("/api/admin/sos/%d/stand-down", alert.AlertID)
+		rr := app.makeRequest(t, "POST", standDownPath, bytes.NewReader(body), adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var stood api.SOSAlertResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stood))
+		assert.Equal(t, "stood_down", stood.Status)
+		assert.Equal(t, "Owl is safe, false alarm", stood.StandDownNote)
+		require.NotNil(t, stood.StoodDownByUserID)
+		assert.Equal(t, admin.UserID, *stood.StoodDownByUserID)
+		assert.Len(t, stood.Acknowledgements, 2)
+
+		escalation, err := app.Querier.GetIncidentEscalationByReportID(ctx, *alert.ReportID)
+		require.NoError(t, err)
+		assert.Equal(t, "acknowledged", escalation.Status)
+
+		rr = app.makeRequest(t, "POST", standDownPath, http.NoBody, adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		rr = app.makeRequest(t, "POST", ackPath, nil, responderToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		// A new tap raises a fresh alert
+		rr = app.makeRequest(t, "POST", "/api/sos", http.NoBody, owlToken)
+		assert.Equal(t, http.StatusCreated, rr.Code, "Response: %s", rr.Body.String())
+	})
+}
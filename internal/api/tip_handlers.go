@@ -0,0 +1,359 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+)
+
+// TipHandler handles the public anonymous tip line and its admin review queue.
+type TipHandler struct {
+	tipService   *service.TipService
+	auditService *service.AuditService
+	logger       *slog.Logger
+}
+
+// NewTipHandler creates a new TipHandler.
+func NewTipHandler(tipService *service.TipService, auditService *service.AuditService, logger *slog.Logger) *TipHandler {
+	return &TipHandler{
+		tipService:   tipService,
+		auditService: auditService,
+		logger:       logger.With("handler", "TipHandler"),
+	}
+}
+
+// SubmitTipRequest is an anonymous tip. Only the message and the solved
+// challenge are required.
+type SubmitTipRequest struct {
+	Message   string   `json:"message"`
+	Severity  int64    `json:"severity"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Contact   string   `json:"contact,omitempty"`
+	Challenge string   `json:"challenge"`
+	Nonce     string   `json:"nonce"`
+	// Website is a honeypot: the form hides it, so only bots fill it in
+	Website string `json:"website,omitempty"`
+}
+
+// ReviewTipRequest is the body for promoting or discarding a tip
+type ReviewTipRequest struct {
+	Severity *int64 `json:"severity,omitempty"`
+	Note     string `json:"note,omitempty"`
+}
+
+// TipResponse describes a tip in the review queue
+type TipResponse struct {
+	TipID            int64      `json:"tip_id"`
+	Message          string     `json:"message"`
+	Severity         int64      `json:"severity"`
+	Latitude         *float64   `json:"latitude,omitempty"`
+	Longitude        *float64   `json:"longitude,omitempty"`
+	Contact          string     `json:"contact,omitempty"`
+	Status           string     `json:"status"`
+	ReportID         *int64     `json:"report_id,omitempty"`
+	ReviewNote       string     `json:"review_note,omitempty"`
+	ReviewedByUserID *int64     `json:"reviewed_by_user_id,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt        *time.Time `json:"created_at,omitempty"`
+}
+
+// PromoteTipResponse is the reviewed tip and the report created from it
+type PromoteTipResponse struct {
+	Tip      TipResponse `json:"tip"`
+	ReportID int64       `json:"report_id"`
+}
+
+func toTipResponse(tip db.Tip) TipResponse {
+	return TipResponse{
+		TipID:            tip.TipID,
+		Message:          tip.Message,
+		Severity:         tip.Severity,
+		Latitude:         nullFloat64ToPointer(tip.Latitude),
+		Longitude:        nullFloat64ToPointer(tip.Longitude),
+		Contact:          tip.Contact.String,
+		Status:           tip.Status,
+		ReportID:         nullInt64ToPointer(tip.ReportID),
+		ReviewNote:       tip.ReviewNote.String,
+		ReviewedByUserID: nullInt64ToPointer(tip.ReviewedByUserID),
+		ReviewedAt:       nullTimeToPointer(tip.ReviewedAt),
+		CreatedAt:        nullTimeToPointer(tip.CreatedAt),
+	}
+}
+
+func (h *TipHandler) parseTipID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	tipIDStr := r.PathValue("id")
+	tipID, err := strconv.ParseInt(tipIDStr, 10, 64)
+	if err != nil || tipID <= 0 {
+		RespondWithError(w, http.StatusBadRequest, "Invalid tip ID", h.logger, "tip_id", tipIDStr)
+		return 0, false
+	}
+	return tipID, true
+}
+
+func (h *TipHandler) respondWithReviewError(w http.ResponseWriter, err error, tipID int64) {
+	switch {
+	case errors.Is(err, service.ErrTipNotFound):
+		RespondWithError(w, http.StatusNotFound, "Tip not found", h.logger, "tip_id", tipID)
+	case errors.Is(err, service.ErrTipAlreadyReviewed):
+		RespondWithError(w, http.StatusConflict, "Tip has already been reviewed", h.logger, "tip_id", tipID)
+	case errors.Is(err, service.ErrSeverityOutOfRange):
+		RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to review tip", h.logger, "error", err.Error())
+	}
+}
+
+// GetTipChallengeHandler handles GET /api/tips/challenge
+// @Summary Get a tip line challenge
+// @Description Issues a proof of work challenge. The client must find a nonce such that SHA-256(challenge + ":" + nonce) starts with `difficulty` zero bits and send both with the tip before the challenge expires. Each challenge can be used once.
+// @Tags tips
+// @Produce json
+// @Success 200 {object} service.TipChallenge "Proof of work challenge"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/tips/challenge [get]
+func (h *TipHandler) GetTipChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	challenge, err := h.tipService.NewChallenge(time.Now())
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Failed to create challenge", h.logger, "error", err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, challenge, h.logger)
+}
+
+// SubmitTipHandler handles POST /api/tips
+// @Summary Submit an anonymous tip
+// @Description Anyone can report something suspicious without an account. Tips wait for an admin to promote them to a report or discard them. Nothing is stored about the sender beyond what they choose to put in the tip.
+// @Tags tips
+// @Accept json
+// @Produce json
+// @Param request body SubmitTipRequest true "Tip with solved challenge"
+// @Success 202 {object} map[string]string "Tip received"
+// @Failure 400 {object} ErrorResponse "Invalid tip or challenge"
+// @Failure 429 {object} ErrorResponse "Too many tips"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/tips [post]
+func (h *TipHandler) SubmitTipHandler(w http.ResponseWriter, r *http.Request) {
+	var req SubmitTipRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 16<<10)).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger)
+		return
+	}
+
+	clientIP, _ := extractClientInfo(r)
+	err := h.tipService.SubmitTip(r.Context(), service.TipSubmission{
+		Message:   req.Message,
+		Severity:  req.Severity,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Contact:   req.Contact,
+		Challenge: req.Challenge,
+		Nonce:     req.Nonce,
+		Honeypot:  req.Website,
+	}, clientIP, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTip),
+			errors.Is(err, service.ErrSeverityOutOfRange),
+			errors.Is(err, service.ErrInvalidLocation),
+			errors.Is(err, service.ErrInvalidTipChallenge):
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+		case errors.Is(err, service.ErrTipRateLimited):
+			RespondWithError(w, http.StatusTooManyRequests, err.Error(), h.logger)
+		default:
+			RespondWithError(w, http.StatusInternalServerError, "Failed to submit tip", h.logger, "error", err.Error())
+		}
+		return
+	}
+
+	RespondWithJSON(w, http.StatusAccepted, map[string]string{"status": "received"}, h.logger)
+}
+
+// AdminListTipsHandler handles GET /api/admin/tips
+// @Summary List tips (Admin)
+// @Description Returns tips in a review state, newest first
+// @Tags admin/tips
+// @Produce json
+// @Param status query string false "pending (default), promoted or discarded"
+// @Param limit query int false "Maximum tips to return (default 50, max 500)"
+// @Param offset query int false "Number of tips to skip (default 0)"
+// @Success 200 {array} TipResponse "Tips"
+// @Failure 400 {object} ErrorResponse "Invalid status, limit or offset"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/tips [get]
+func (h *TipHandler) AdminListTipsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = service.TipStatusPending
+	}
+
+	limit := int64(50)
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || parsed <= 0 || parsed > 500 {
+			RespondWithError(w, http.StatusBadRequest, "limit must be between 1 and 500", h.logger, "limit", limitStr)
+			return
+		}
+		limit = parsed
+	}
+	offset := int64(0)
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		parsed, err := strconv.ParseInt(offsetStr, 10, 64)
+		if err != nil || parsed < 0 {
+			RespondWithError(w, http.StatusBadRequest, "offset must be zero or more", h.logger, "offset", offsetStr)
+			return
+		}
+		offset = parsed
+	}
+
+	tips, err := h.tipService.ListTips(r.Context(), status, limit, offset)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidTipStatus) {
+			RespondWithError(w, http.StatusBadRequest, err.Error(), h.logger)
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, "Failed to list tips", h.logger, "error", err.Error())
+		return
+	}
+
+	response := make([]TipResponse, 0, len(tips))
+	for _, tip := range tips {
+		response = append(response, toTipResponse(tip))
+	}
+	RespondWithJSON(w, http.StatusOK, response, h.logger)
+}
+
+// AdminGetTipHandler handles GET /api/admin/tips/{id}
+// @Summary Get a tip (Admin)
+// @Tags admin/tips
+// @Produce json
+// @Param id path int true "Tip ID"
+// @Success 200 {object} TipResponse "Tip"
+// @Failure 400 {object} ErrorResponse "Invalid tip ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Tip not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/tips/{id} [get]
+func (h *TipHandler) AdminGetTipHandler(w http.ResponseWriter, r *http.Request) {
+	tipID, ok := h.parseTipID(w, r)
+	if !ok {
+		return
+	}
+
+	tip, err := h.tipService.GetTip(r.Context(), tipID)
+	if err != nil {
+		h.respondWithReviewError(w, err, tipID)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, toTipResponse(tip), h.logger)
+}
+
+// AdminPromoteTipHandler handles POST /api/admin/tips/{id}/promote
+// @Summary Promote a tip to a report (Admin)
+// @Description Creates an off-shift report from a pending tip, optionally with a corrected severity. The tipster's contact details are not copied to the report.
+// @Tags admin/tips
+// @Accept json
+// @Produce json
+// @Param id path int true "Tip ID"
+// @Param request body ReviewTipRequest false "Optional severity override and note"
+// @Success 200 {object} PromoteTipResponse "Tip promoted"
+// @Failure 400 {object} ErrorResponse "Invalid tip ID or severity"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Tip not found"
+// @Failure 409 {object} ErrorResponse "Tip already reviewed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/tips/{id}/promote [post]
+func (h *TipHandler) AdminPromoteTipHandler(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	tipID, ok := h.parseTipID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ReviewTipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	tip, report, err := h.tipService.PromoteTip(r.Context(), tipID, adminUserID, req.Severity, req.Note)
+	if err != nil {
+		h.respondWithReviewError(w, err, tipID)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if auditErr := h.auditService.LogTipPromoted(r.Context(), adminUserID, tipID, report.ReportID, ipAddress, userAgent); auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log tip promotion audit event", "tip_id", tipID, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusOK, PromoteTipResponse{Tip: toTipResponse(tip), ReportID: report.ReportID}, h.logger)
+}
+
+// AdminDiscardTipHandler handles POST /api/admin/tips/{id}/discard
+// @Summary Discard a tip (Admin)
+// @Description Closes a pending tip without creating a report
+// @Tags admin/tips
+// @Accept json
+// @Produce json
+// @Param id path int true "Tip ID"
+// @Param request body ReviewTipRequest false "Optional note"
+// @Success 200 {object} TipResponse "Tip discarded"
+// @Failure 400 {object} ErrorResponse "Invalid tip ID"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Forbidden - admin access required"
+// @Failure 404 {object} ErrorResponse "Tip not found"
+// @Failure 409 {object} ErrorResponse "Tip already reviewed"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/admin/tips/{id}/discard [post]
+func (h *TipHandler) AdminDiscardTipHandler(w http.ResponseWriter, r *http.Request) {
+	adminUserID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	tipID, ok := h.parseTipID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ReviewTipRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	tip, err := h.tipService.DiscardTip(r.Context(), tipID, adminUserID, req.Note)
+	if err != nil {
+		h.respondWithReviewError(w, err, tipID)
+		return
+	}
+
+	ipAddress, userAgent := GetAuditInfoFromContext(r.Context())
+	if auditErr := h.auditService.LogTipDiscarded(r.Context(), adminUserID, tipID, tip.ReviewNote.String, ipAddress, userAgent); auditErr != nil {
+		h.logger.WarnContext(r.Context(), "Failed to log tip discard audit event", "tip_id", tipID, "error", auditErr)
+	}
+
+	RespondWithJSON(w, http.StatusOK, toTipResponse(tip), h.logger)
+}
@@ -0,0 +1,236 @@
+package api_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"night-owls-go/internal/api"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// solveTipChallenge fetches a challenge and brute forces its proof of work,
+// as the tip line's browser client does.
+func solveTipChallenge(t *testing.T, app *adminTestApp) (string, string) {
+	t.Helper()
+	rr := app.makeRequest(t, "GET", "/api/tips/challenge", nil, "")
+	require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+	var challenge service.TipChallenge
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &challenge))
+	require.Equal(t, 8, challenge.Difficulty)
+
+	for nonce := 0; ; nonce++ {
+		candidate := strconv.Itoa(nonce)
+		if service.LeadingZeroBits(sha256.Sum256([]byte(challenge.Challenge+":"+candidate))) >= challenge.Difficulty {
+			return challenge.Challenge, candidate
+		}
+	}
+}
+
+func submitTip(t *testing.T, app *adminTestApp, tip map[string]interface{}, clientIP string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(tip)
+	require.NoError(t, err)
+	req, err := http.NewRequest("POST", "/api/tips", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", clientIP)
+	rr := httptest.NewRecorder()
+	app.Router.ServeHTTP(rr, req)
+	return rr
+}
+
+func solvedTip(t *testing.T, app *adminTestApp, message string) map[string]interface{} {
+	challenge, nonce := solveTipChallenge(t, app)
+	return map[string]interface{}{
+		"message":   message,
+		"severity":  1,
+		"challenge": challenge,
+		"nonce":     nonce,
+	}
+}
+
+func countTips(t *testing.T, app *adminTestApp) int {
+	t.Helper()
+	var count int
+	require.NoError(t, app.DB.QueryRow(`SELECT COUNT(*) FROM tips`).Scan(&count))
+	return count
+}
+
+func TestTipLine_Submit(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	t.Run("valid tip is queued without identifying details", func(t *testing.T) {
+		tip := solvedTip(t, app, "Two men checking car doors on Oak Street")
+		tip["latitude"] = -33.9249
+		tip["longitude"] = 18.4241
+		tip["contact"] = "leave a note at number 4"
+
+		rr := submitTip(t, app, tip, "203.0.113.10")
+		require.Equal(t, http.StatusAccepted, rr.Code, "Response: %s", rr.Body.String())
+		assert.Equal(t, 1, countTips(t, app))
+
+		var status, contact string
+		require.NoError(t, app.DB.QueryRow(`SELECT status, contact FROM tips`).Scan(&status, &contact))
+		assert.Equal(t, service.TipStatusPending, status)
+		assert.Equal(t, "leave a note at number 4", contact)
+
+		var clientHash string
+		require.NoError(t, app.DB.QueryRow(`SELECT client_hash FROM tip_submissions`).Scan(&clientHash))
+		assert.NotContains(t, clientHash, "203.0.113.10", "the client IP must never be stored")
+
+		// A solved challenge cannot be replayed
+		rr = submitTip(t, app, tip, "203.0.113.10")
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Equal(t, 1, countTips(t, app))
+	})
+
+	t.Run("rejects missing or wrong proof of work", func(t *testing.T) {
+		tip := solvedTip(t, app, "Suspicious van")
+		challenge := tip["challenge"].(string)
+		for nonce := 0; ; nonce++ {
+			candidate := strconv.Itoa(nonce)
+			if service.LeadingZeroBits(sha256.Sum256([]byte(challenge+":"+candidate))) < 8 {
+				tip["nonce"] = candidate
+				break
+			}
+		}
+		assert.Equal(t, http.StatusBadRequest, submitTip(t, app, tip, "203.0.113.11").Code)
+
+		tip = solvedTip(t, app, "Suspicious van")
+		tip["challenge"] = "forged.9999999999.deadbeef"
+		assert.Equal(t, http.StatusBadRequest, submitTip(t, app, tip, "203.0.113.11").Code)
+	})
+
+	t.Run("rejects invalid content", func(t *testing.T) {
+		tip := solvedTip(t, app, "   ")
+		assert.Equal(t, http.StatusBadRequest, submitTip(t, app, tip, "203.0.113.12").Code)
+
+		tip = solvedTip(t, app, "Fire in the park")
+		tip["severity"] = 5
+		assert.Equal(t, http.StatusBadRequest, submitTip(t, app, tip, "203.0.113.12").Code)
+
+		tip = solvedTip(t, app, "Fire in the park")
+		tip["latitude"] = -33.9
+		assert.Equal(t, http.StatusBadRequest, submitTip(t, app, tip, "203.0.113.12").Code, "latitude without longitude")
+	})
+
+	t.Run("honeypot is accepted but dropped", func(t *testing.T) {
+		before := countTips(t, app)
+		tip := solvedTip(t, app, "Buy cheap watches")
+		tip["website"] = "http://spam.example.com"
+		assert.Equal(t, http.StatusAccepted, submitTip(t, app, tip, "203.0.113.13").Code)
+		assert.Equal(t, before, countTips(t, app))
+	})
+
+	t.Run("per-client rate limit", func(t *testing.T) {
+		for i := 0; i < service.MaxTipsPerClient; i++ {
+			rr := submitTip(t, app, solvedTip(t, app, fmt.Sprintf("Noise complaint %d", i)), "198.51.100.7")
+			require.Equal(t, http.StatusAccepted, rr.Code, "tip %d: %s", i, rr.Body.String())
+		}
+		rr := submitTip(t, app, solvedTip(t, app, "One too many"), "198.51.100.7")
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+		rr = submitTip(t, app, solvedTip(t, app, "Someone else"), "198.51.100.8")
+		assert.Equal(t, http.StatusAccepted, rr.Code, "other clients are not affected")
+	})
+}
+
+func TestTipLine_AdminReview(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	admin, adminToken := app.createTestUserAndLogin(t, "+15550003801", "Test Admin", "admin")
+	_, owlToken := app.createTestUserAndLogin(t, "+15550003802", "Test Owl", "owl")
+
+	tip := solvedTip(t, app, "Broken street light used as cover")
+	tip["severity"] = 0
+	tip["latitude"] = -33.9249
+	tip["longitude"] = 18.4241
+	tip["contact"] = "082 555 0199"
+	require.Equal(t, http.StatusAccepted, submitTip(t, app, tip, "203.0.113.20").Code)
+	require.Equal(t, http.StatusAccepted, submitTip(t, app, solvedTip(t, app, "Prank message"), "203.0.113.21").Code)
+
+	rr := app.makeRequest(t, "GET", "/api/admin/tips", nil, owlToken)
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+
+	rr = app.makeRequest(t, "GET", "/api/admin/tips", nil, adminToken)
+	require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+	var pending []api.TipResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &pending))
+	require.Len(t, pending, 2)
+	assert.Equal(t, "Prank message", pending[0].Message, "newest first")
+	promoteID, discardID := pending[1].TipID, pending[0].TipID
+	assert.Equal(t, "082 555 0199", pending[1].Contact)
+
+	rr = app.makeRequest(t, "GET", "/api/admin/tips?status=bogus", nil, adminToken)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	t.Run("promote files a report as the reviewing admin", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"severity": 1, "note": "Confirmed by patrol"}`)
+		rr := app.makeRequest(t, "POST", fmt.Sprintf("/api/admin/tips/%d/promote", promoteID), body, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var resp api.PromoteTipResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, service.TipStatusPromoted, resp.Tip.Status)
+		require.NotNil(t, resp.Tip.ReportID)
+		assert.Equal(t, resp.ReportID, *resp.Tip.ReportID)
+		assert.Equal(t, "Confirmed by patrol", resp.Tip.ReviewNote)
+
+		var userID, bookingID sql.NullInt64
+		var severity int64
+		var message string
+		require.NoError(t, app.DB.QueryRow(`SELECT user_id, booking_id, severity, message FROM reports WHERE report_id = ?`, resp.ReportID).
+			Scan(&userID, &bookingID, &severity, &message))
+		assert.Equal(t, admin.UserID, userID.Int64, "the reviewing admin files the report")
+		assert.False(t, bookingID.Valid)
+		assert.Equal(t, int64(1), severity)
+		assert.Equal(t, "Broken street light used as cover", message)
+
+		var auditCount int
+		require.NoError(t, app.DB.QueryRow(`SELECT COUNT(*) FROM audit_events WHERE event_type = 'tip.promoted' AND entity_id = ?`, promoteID).Scan(&auditCount))
+		assert.Equal(t, 1, auditCount)
+
+		rr = app.makeRequest(t, "POST", fmt.Sprintf("/api/admin/tips/%d/promote", promoteID), bytes.NewBufferString(`{}`), adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("discard", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"note": "Spam"}`)
+		rr := app.makeRequest(t, "POST", fmt.Sprintf("/api/admin/tips/%d/discard", discardID), body, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+
+		var resp api.TipResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, service.TipStatusDiscarded, resp.Status)
+		assert.Nil(t, resp.ReportID)
+
+		rr = app.makeRequest(t, "POST", fmt.Sprintf("/api/admin/tips/%d/promote", discardID), bytes.NewBufferString(`{}`), adminToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = app.makeRequest(t, "GET", "/api/admin/tips?status=discarded", nil, adminToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var discarded []api.TipResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &discarded))
+		require.Len(t, discarded, 1)
+		assert.Equal(t, discardID, discarded[0].TipID)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, app.makeRequest(t, "GET", "/api/admin/tips/999999", nil, adminToken).Code)
+		assert.Equal(t, http.StatusNotFound, app.makeRequest(t, "POST", "/api/admin/tips/999999/discard", bytes.NewBufferString(`{}`), adminToken).Code)
+		assert.Equal(t, http.StatusBadRequest, app.makeRequest(t, "GET", "/api/admin/tips/abc", nil, adminToken).Code)
+	})
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+)
+
+// UserEmailHandler handles the caller's notification email address.
+type UserEmailHandler struct {
+	userEmailService *service.UserEmailService
+	logger           *slog.Logger
+}
+
+// NewUserEmailHandler creates a new UserEmailHandler.
+func NewUserEmailHandler(userEmailService *service.UserEmailService, logger *slog.Logger) *UserEmailHandler {
+	return &UserEmailHandler{
+		userEmailService: userEmailService,
+		logger:           logger.With("handler", "UserEmailHandler"),
+	}
+}
+
+// SetUserEmailRequest is the address to send a confirmation code to
+type SetUserEmailRequest struct {
+	Email string `json:"email"`
+}
+
+// VerifyUserEmailRequest is the confirmation code that was emailed to the pending address
+type VerifyUserEmailRequest struct {
+	Code string `json:"code"`
+}
+
+// UserEmailResponse describes the caller's email addresses. Email is only set
+// once confirmed; an address awaiting confirmation is in PendingEmail.
+type UserEmailResponse struct {
+	Email           string     `json:"email,omitempty"`
+	Verified        bool       `json:"verified"`
+	VerifiedAt      *time.Time `json:"verified_at,omitempty"`
+	PendingEmail    string     `json:"pending_email,omitempty"`
+	PendingExpireAt *time.Time `json:"pending_expires_at,omitempty"`
+}
+
+func toUserEmailResponse(row db.GetUserEmailRow) UserEmailResponse {
+	response := UserEmailResponse{
+		Email:        row.Email.String,
+		Verified:     row.Email.Valid,
+		VerifiedAt:   nullTimeToPointer(row.EmailVerifiedAt),
+		PendingEmail: row.PendingEmail.String,
+	}
+	if row.PendingEmail.Valid {
+		response.PendingExpireAt = nullTimeToPointer(row.EmailVerificationExpiresAt)
+	}
+	return response
+}
+
+func (h *UserEmailHandler) respondWithEmailError(w http.ResponseWriter, err error, userID int64) {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound):
+		RespondWithError(w, http.StatusNotFound, "User not found", h.logger, "user_id", userID)
+	case errors.Is(err, service.ErrInvalidEmail):
+		RespondWithError(w, http.StatusBadRequest, "Invalid email address", h.logger, "user_id", userID)
+	case errors.Is(err, service.ErrEmailVerificationThrottled):
+		RespondWithError(w, http.StatusTooManyRequests, "A confirmation code was sent recently; please wait a minute", h.logger, "user_id", userID)
+	case errors.Is(err, service.ErrNoPendingEmail):
+		RespondWithError(w, http.StatusConflict, "No email address is awaiting confirmation", h.logger, "user_id", userID)
+	case errors.Is(err, service.ErrEmailVerificationFailed):
+		RespondWithError(w, http.StatusBadRequest, "Invalid or expired confirmation code", h.logger, "user_id", userID)
+	case errors.Is(err, service.ErrEmailInUse):
+		RespondWithError(w, http.StatusConflict, "Email address is already in use", h.logger, "user_id", userID)
+	default:
+		RespondWithError(w, http.StatusInternalServerError, "Failed to process email request", h.logger, "error", err.Error())
+	}
+}
+
+// GetEmailHandler handles GET /api/user/email
+// @Summary Get my notification email
+// @Tags user
+// @Produce json
+// @Success 200 {object} UserEmailResponse "Email addresses"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/user/email [get]
+func (h *UserEmailHandler) GetEmailHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	row, err := h.userEmailService.GetEmail(r.Context(), userID)
+	if err != nil {
+		h.respondWithEmailError(w, err, userID)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, toUserEmailResponse(row), h.logger)
+}
+
+// SetEmailHandler handles PUT /api/user/email
+// @Summary Add or change my notification email
+// @Description Sends a confirmation code to the address. It is used for notifications once confirmed; until then any previously confirmed address stays in use.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body SetUserEmailRequest true "Email address"
+// @Success 202 {object} UserEmailResponse "Confirmation code sent"
+// @Failure 400 {object} ErrorResponse "Invalid email address"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 429 {object} ErrorResponse "A code was sent less than a minute ago"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/user/email [put]
+func (h *UserEmailHandler) SetEmailHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	var req SetUserEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	row, err := h.userEmailService.RequestVerification(r.Context(), userID, req.Email)
+	if err != nil {
+		h.respondWithEmailError(w, err, userID)
+		return
+	}
+	RespondWithJSON(w, http.StatusAccepted, toUserEmailResponse(row), h.logger)
+}
+
+// VerifyEmailHandler handles POST /api/user/email/verify
+// @Summary Confirm my notification email
+// @Description Confirms the pending address with the code that was emailed to it. The pending address is dropped after five wrong codes.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body VerifyUserEmailRequest true "Confirmation code"
+// @Success 200 {object} UserEmailResponse "Email confirmed"
+// @Failure 400 {object} ErrorResponse "Invalid or expired code"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Nothing awaiting confirmation, or the address belongs to another user"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/user/email/verify [post]
+func (h *UserEmailHandler) VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	var req VerifyUserEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request body", h.logger, "error", err.Error())
+		return
+	}
+
+	row, err := h.userEmailService.Verify(r.Context(), userID, req.Code)
+	if err != nil {
+		h.respondWithEmailError(w, err, userID)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, toUserEmailResponse(row), h.logger)
+}
+
+// DeleteEmailHandler handles DELETE /api/user/email
+// @Summary Remove my notification email
+// @Tags user
+// @Success 204 "Email removed"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api/user/email [delete]
+func (h *UserEmailHandler) DeleteEmailHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(UserIDKey).(int64)
+	if !ok {
+		RespondWithError(w, http.StatusUnauthorized, "User ID not found in context", h.logger)
+		return
+	}
+
+	if err := h.userEmailService.RemoveEmail(r.Context(), userID); err != nil {
+		h.respondWithEmailError(w, err, userID)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
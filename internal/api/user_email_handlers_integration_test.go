@@ -0,0 +1,131 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"night-owls-go/internal/api"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserEmail(t *testing.T) {
+	app := newAdminTestApp(t)
+	defer app.DB.Close()
+
+	owl, owlToken := app.createTestUserAndLogin(t, "+27820000201", "Email Owl", "owl")
+	_, otherToken := app.createTestUserAndLogin(t, "+27820000202", "Other Owl", "owl")
+
+	// sentCode returns the latest confirmation code queued for a user
+	sentCode := func(t *testing.T, userID int64) string {
+		t.Helper()
+		var payload string
+		require.NoError(t, app.DB.QueryRow(`SELECT payload FROM outbox WHERE user_id = ? AND message_type = ? ORDER BY outbox_id DESC LIMIT 1`,
+			userID, service.OutboxMessageEmailVerification).Scan(&payload))
+		var data struct {
+			Code string `json:"code"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(payload), &data))
+		return data.Code
+	}
+	decode := func(t *testing.T, body []byte) api.UserEmailResponse {
+		t.Helper()
+		var response api.UserEmailResponse
+		require.NoError(t, json.Unmarshal(body, &response))
+		return response
+	}
+	// resetThrottle lets a test request another code straight away
+	resetThrottle := func(t *testing.T) {
+		t.Helper()
+		_, err := app.DB.Exec(`UPDATE users SET email_verification_expires_at = datetime('now', '+1 minute') WHERE pending_email IS NOT NULL`)
+		require.NoError(t, err)
+	}
+
+	t.Run("invalid addresses are refused", func(t *testing.T) {
+		for _, address := range []string{"", "not-an-email", "Owl <owl@example.com>", "owl@localhost"} {
+			rr := app.makeRequest(t, "PUT", "/api/user/email", bytes.NewBufferString(`{"email": "`+address+`"}`), owlToken)
+			assert.Equal(t, http.StatusBadRequest, rr.Code, "address %q", address)
+		}
+	})
+
+	t.Run("setting an email sends a code to it", func(t *testing.T) {
+		rr := app.makeRequest(t, "PUT", "/api/user/email", bytes.NewBufferString(`{"email": "Owl@Example.com"}`), owlToken)
+		require.Equal(t, http.StatusAccepted, rr.Code, "Response: %s", rr.Body.String())
+		response := decode(t, rr.Body.Bytes())
+		assert.False(t, response.Verified)
+		assert.Equal(t, "owl@example.com", response.PendingEmail)
+		assert.NotNil(t, response.PendingExpireAt)
+
+		var recipient string
+		require.NoError(t, app.DB.QueryRow(`SELECT recipient FROM outbox WHERE message_type = ?`, service.OutboxMessageEmailVerification).Scan(&recipient))
+		assert.Equal(t, "owl@example.com", recipient)
+
+		rr = app.makeRequest(t, "PUT", "/api/user/email", bytes.NewBufferString(`{"email": "owl@example.com"}`), owlToken)
+		assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	})
+
+	t.Run("a wrong code does not confirm", func(t *testing.T) {
+		rr := app.makeRequest(t, "POST", "/api/user/email/verify", bytes.NewBufferString(`{"code": "000000x"}`), owlToken)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+		rr = app.makeRequest(t, "GET", "/api/user/email", nil, owlToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		assert.False(t, decode(t, rr.Body.Bytes()).Verified)
+	})
+
+	t.Run("the emailed code confirms the address", func(t *testing.T) {
+		body := `{"code": "` + sentCode(t, owl.UserID) + `"}`
+		rr := app.makeRequest(t, "POST", "/api/user/email/verify", bytes.NewBufferString(body), owlToken)
+		require.Equal(t, http.StatusOK, rr.Code, "Response: %s", rr.Body.String())
+		response := decode(t, rr.Body.Bytes())
+		assert.True(t, response.Verified)
+		assert.Equal(t, "owl@example.com", response.Email)
+		assert.Empty(t, response.PendingEmail)
+		assert.NotNil(t, response.VerifiedAt)
+
+		rr = app.makeRequest(t, "POST", "/api/user/email/verify", bytes.NewBufferString(body), owlToken)
+		assert.Equal(t, http.StatusConflict, rr.Code, "nothing is awaiting confirmation")
+	})
+
+	t.Run("another user cannot confirm the same address", func(t *testing.T) {
+		rr := app.makeRequest(t, "PUT", "/api/user/email", bytes.NewBufferString(`{"email": "owl@example.com"}`), otherToken)
+		require.Equal(t, http.StatusAccepted, rr.Code)
+
+		var otherID int64
+		require.NoError(t, app.DB.QueryRow(`SELECT user_id FROM users WHERE phone = '+27820000202'`).Scan(&otherID))
+		rr = app.makeRequest(t, "POST", "/api/user/email/verify", bytes.NewBufferString(`{"code": "`+sentCode(t, otherID)+`"}`), otherToken)
+		assert.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("too many wrong codes drop the pending address", func(t *testing.T) {
+		resetThrottle(t)
+		rr := app.makeRequest(t, "PUT", "/api/user/email", bytes.NewBufferString(`{"email": "new@example.com"}`), owlToken)
+		require.Equal(t, http.StatusAccepted, rr.Code, "Response: %s", rr.Body.String())
+
+		for i := 0; i < 5; i++ {
+			rr = app.makeRequest(t, "POST", "/api/user/email/verify", bytes.NewBufferString(`{"code": "wrong"}`), owlToken)
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		}
+
+		rr = app.makeRequest(t, "GET", "/api/user/email", nil, owlToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		response := decode(t, rr.Body.Bytes())
+		assert.Empty(t, response.PendingEmail)
+		assert.Equal(t, "owl@example.com", response.Email, "the confirmed address stays in use")
+	})
+
+	t.Run("removing the email clears it", func(t *testing.T) {
+		rr := app.makeRequest(t, "DELETE", "/api/user/email", nil, owlToken)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		rr = app.makeRequest(t, "GET", "/api/user/email", nil, owlToken)
+		require.Equal(t, http.StatusOK, rr.Code)
+		response := decode(t, rr.Body.Bytes())
+		assert.Empty(t, response.Email)
+		assert.False(t, response.Verified)
+	})
+}
@@ -25,8 +25,13 @@ type Config struct {
 	JWTExpirationHours int
 	OTPValidityMinutes int
 	// OTPLength          int // Usually fixed by implementation, less often configured
-	OutboxBatchSize  int
-	OutboxMaxRetries int
+	OutboxBatchSize      int
+	OutboxMaxRetries     int
+	OutboxRetryBaseDelay time.Duration  // Delay before the first retry; doubles with each further attempt
+	OutboxRetryMaxDelay  time.Duration  // Upper bound on the delay between retries
+	OutboxRetryLimits    map[string]int // Per message type overrides of OutboxMaxRetries
+	OutboxWorkers        int            // Number of items sent in parallel by each dispatcher run
+	OutboxLeaseDuration  time.Duration  // How long a claimed item is held before another run may take it over
 
 	// Development mode - enables dev features like OTP in responses
 	DevMode bool
@@ -41,6 +46,48 @@ type Config struct {
 	TwilioAuthToken  string
 	TwilioVerifySID  string
 	TwilioFromNumber string
+
+	// SMS delivery
+	SMSProvider               string        // log (default), twilio, clickatell or bulksms
+	SMSSenderID               string        // Sender ID or number for Clickatell and BulkSMS; Twilio uses TwilioFromNumber
+	SMSHTTPTimeout            time.Duration // Timeout for each call to the SMS provider
+	SMSCallbackBaseURL        string        // Public base URL providers send delivery reports to, e.g. https://owls.example.com
+	SMSCallbackToken          string        // Shared secret expected on delivery report callbacks
+	TwilioMessagingServiceSID string        // Optional; used instead of TwilioFromNumber when set
+	ClickatellAPIKey          string
+	BulkSMSTokenID            string
+	BulkSMSTokenSecret        string
+
+	// Email delivery over SMTP; email is disabled while SMTPHost is empty
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string        // From address, e.g. "Night Owls <owls@example.com>"
+	SMTPTLS      string        // starttls (default), tls for implicit TLS, or none for local sinks such as Mailpit
+	SMTPTimeout  time.Duration // Timeout for each SMTP session
+
+	// Notifications
+	NotificationTimezone string // IANA zone used for times in rendered messages
+
+	// Severity-2 incident escalation
+	EscalationSMSDelay        time.Duration // How long to wait for an acknowledgement before SMSing responders
+	EscalationResponderPhones []string      // Designated responders; admins are used when empty
+
+	// Live patrol tracking
+	PatrolLocationRetention time.Duration // How long GPS breadcrumbs are kept
+
+	// Shift handover
+	HandoverLookback time.Duration // How long before a shift starts a finished patrol's handover note is still shown
+
+	// Printable reports
+	MapTileCacheDir string // Offline slippy map tiles ({z}/{x}/{y}.png) for report maps; coordinates only when empty
+
+	// Anonymous tip line
+	TipProofOfWorkBits int // Leading zero bits a tip's proof of work hash needs
+
+	// Watchlist
+	WatchlistPhotoDir string // Where photos of watchlist vehicles and people are stored
 }
 
 // Security validation constants
@@ -110,14 +157,37 @@ func LoadConfig() (*Config, error) {
 		JWTExpirationHours: 336, // Default 2 weeks (336 hours) - configurable via JWT_EXPIRATION_HOURS env var
 		OTPValidityMinutes: 5,   // Default 5 minutes
 		// OTPLength:          6,     // Default 6 digits (if we make it configurable)
-		OutboxBatchSize:  10, // Default 10 messages per batch
-		OutboxMaxRetries: 3,  // Default 3 retries
+		OutboxBatchSize:      10, // Default 10 messages per batch
+		OutboxMaxRetries:     3,  // Default 3 retries
+		OutboxRetryBaseDelay: 30 * time.Second,
+		OutboxRetryMaxDelay:  time.Hour,
+		OutboxWorkers:        4,
+		OutboxLeaseDuration:  5 * time.Minute,
 
 		// Development mode - enables dev features like OTP in responses
 		DevMode: false,
 
 		// PWA / WebPush defaults
 		VAPIDSubject: "mailto:admin@example.com", // Default VAPID subject
+
+		SMSProvider:    "log",
+		SMSHTTPTimeout: 10 * time.Second,
+
+		SMTPPort:    587,
+		SMTPTLS:     "starttls",
+		SMTPTimeout: 10 * time.Second,
+
+		NotificationTimezone: "Africa/Johannesburg",
+
+		EscalationSMSDelay: 10 * time.Minute, // Default 10 minutes before SMS escalation
+
+		PatrolLocationRetention: 30 * 24 * time.Hour, // Default 30 days of patrol tracks
+
+		HandoverLookback: 24 * time.Hour, // Default covers the previous slot of a nightly schedule
+
+		TipProofOfWorkBits: 18, // Default takes around a second in a phone browser
+
+		WatchlistPhotoDir: "./data/watchlist-photos",
 	}
 
 	if port := os.Getenv("SERVER_PORT"); port != "" {
@@ -174,6 +244,29 @@ func LoadConfig() (*Config, error) {
 			cfg.OutboxMaxRetries = intVal
 		}
 	}
+	if val := os.Getenv("OUTBOX_RETRY_BASE_SECONDS"); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal > 0 {
+			cfg.OutboxRetryBaseDelay = time.Duration(intVal) * time.Second
+		}
+	}
+	if val := os.Getenv("OUTBOX_RETRY_MAX_SECONDS"); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal > 0 {
+			cfg.OutboxRetryMaxDelay = time.Duration(intVal) * time.Second
+		}
+	}
+	if val := os.Getenv("OUTBOX_RETRY_LIMITS"); val != "" {
+		cfg.OutboxRetryLimits = parseRetryLimits(val)
+	}
+	if val := os.Getenv("OUTBOX_WORKERS"); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal > 0 {
+			cfg.OutboxWorkers = intVal
+		}
+	}
+	if val := os.Getenv("OUTBOX_LEASE_SECONDS"); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal > 0 {
+			cfg.OutboxLeaseDuration = time.Duration(intVal) * time.Second
+		}
+	}
 
 	// Load development mode
 	if val := os.Getenv("DEV_MODE"); val != "" {
@@ -207,5 +300,131 @@ func LoadConfig() (*Config, error) {
 		cfg.TwilioFromNumber = twilioFromNumber
 	}
 
+	// Load SMS delivery configuration
+	if val := os.Getenv("SMS_PROVIDER"); val != "" {
+		cfg.SMSProvider = strings.ToLower(val)
+	}
+	if val := os.Getenv("SMS_SENDER_ID"); val != "" {
+		cfg.SMSSenderID = val
+	}
+	if val := os.Getenv("SMS_HTTP_TIMEOUT_SECONDS"); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal > 0 {
+			cfg.SMSHTTPTimeout = time.Duration(intVal) * time.Second
+		}
+	}
+	if val := os.Getenv("SMS_CALLBACK_BASE_URL"); val != "" {
+		cfg.SMSCallbackBaseURL = strings.TrimRight(val, "/")
+	}
+	if val := os.Getenv("SMS_CALLBACK_TOKEN"); val != "" {
+		cfg.SMSCallbackToken = val
+	}
+	if val := os.Getenv("TWILIO_MESSAGING_SERVICE_SID"); val != "" {
+		cfg.TwilioMessagingServiceSID = val
+	}
+	if val := os.Getenv("CLICKATELL_API_KEY"); val != "" {
+		cfg.ClickatellAPIKey = val
+	}
+	if val := os.Getenv("BULKSMS_TOKEN_ID"); val != "" {
+		cfg.BulkSMSTokenID = val
+	}
+	if val := os.Getenv("BULKSMS_TOKEN_SECRET"); val != "" {
+		cfg.BulkSMSTokenSecret = val
+	}
+
+	// Load SMTP configuration
+	if val := os.Getenv("SMTP_HOST"); val != "" {
+		cfg.SMTPHost = val
+	}
+	if val := os.Getenv("SMTP_PORT"); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal > 0 {
+			cfg.SMTPPort = intVal
+		}
+	}
+	if val := os.Getenv("SMTP_USERNAME"); val != "" {
+		cfg.SMTPUsername = val
+	}
+	if val := os.Getenv("SMTP_PASSWORD"); val != "" {
+		cfg.SMTPPassword = val
+	}
+	if val := os.Getenv("SMTP_FROM"); val != "" {
+		cfg.SMTPFrom = val
+	}
+	if val := os.Getenv("SMTP_TLS"); val != "" {
+		cfg.SMTPTLS = strings.ToLower(val)
+	}
+	if val := os.Getenv("SMTP_TIMEOUT_SECONDS"); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal > 0 {
+			cfg.SMTPTimeout = time.Duration(intVal) * time.Second
+		}
+	}
+
+	// Load notification configuration
+	if val := os.Getenv("NOTIFICATION_TIMEZONE"); val != "" {
+		cfg.NotificationTimezone = val
+	}
+
+	// Load incident escalation configuration
+	if val := os.Getenv("ESCALATION_SMS_DELAY_MINUTES"); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal >= 0 {
+			cfg.EscalationSMSDelay = time.Duration(intVal) * time.Minute
+		}
+	}
+	if val := os.Getenv("ESCALATION_RESPONDER_PHONES"); val != "" {
+		for _, phone := range strings.Split(val, ",") {
+			if phone = strings.TrimSpace(phone); phone != "" {
+				cfg.EscalationResponderPhones = append(cfg.EscalationResponderPhones, phone)
+			}
+		}
+	}
+
+	// Load patrol tracking configuration
+	if val := os.Getenv("PATROL_LOCATION_RETENTION_DAYS"); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal > 0 {
+			cfg.PatrolLocationRetention = time.Duration(intVal) * 24 * time.Hour
+		}
+	}
+
+	// Load shift handover configuration
+	if val := os.Getenv("HANDOVER_LOOKBACK_HOURS"); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal >= 0 {
+			cfg.HandoverLookback = time.Duration(intVal) * time.Hour
+		}
+	}
+
+	// Load printable report configuration
+	if val := os.Getenv("MAP_TILE_CACHE_DIR"); val != "" {
+		cfg.MapTileCacheDir = val
+	}
+
+	// Load tip line configuration
+	if val := os.Getenv("TIP_POW_BITS"); val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal >= 0 && intVal <= 32 {
+			cfg.TipProofOfWorkBits = intVal
+		}
+	}
+
+	// Load watchlist configuration
+	if val := os.Getenv("WATCHLIST_PHOTO_DIR"); val != "" {
+		cfg.WatchlistPhotoDir = val
+	}
+
 	return cfg, nil
 }
+
+// parseRetryLimits parses per message type retry limits in the form
+// "OTP_VERIFICATION=1,push=5". Malformed entries are skipped.
+func parseRetryLimits(val string) map[string]int {
+	limits := make(map[string]int)
+	for _, entry := range strings.Split(val, ",") {
+		messageType, limit, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		intVal, err := strconv.Atoi(strings.TrimSpace(limit))
+		if err != nil || intVal < 0 {
+			continue
+		}
+		limits[strings.TrimSpace(messageType)] = intVal
+	}
+	return limits
+}
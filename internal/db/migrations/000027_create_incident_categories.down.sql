@@ -0,0 +1,5 @@
+-- Remove incident categories
+DROP INDEX IF EXISTS idx_reports_category_id;
+ALTER TABLE reports DROP COLUMN category_fields;
+ALTER TABLE reports DROP COLUMN category_id;
+DROP TABLE IF EXISTS incident_categories;
@@ -0,0 +1,35 @@
+-- Admin-managed incident categories with optional structured fields.
+-- fields holds a JSON array of field definitions, e.g.
+-- [{"key":"person_count","label":"Number of persons","type":"integer","required":true,"min":1,"max":50}]
+CREATE TABLE incident_categories (
+    category_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    fields TEXT NOT NULL DEFAULT '[]',
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Reports may carry a category and the validated field values as a JSON object
+ALTER TABLE reports ADD COLUMN category_id INTEGER REFERENCES incident_categories(category_id);
+ALTER TABLE reports ADD COLUMN category_fields TEXT;
+
+CREATE INDEX idx_reports_category_id ON reports(category_id);
+
+-- Seed the categories used for the monthly statistics
+INSERT INTO incident_categories (name, description, fields, display_order)
+VALUES ('Suspicious vehicle', 'Vehicle behaving suspiciously in the area', '[{"key":"vehicle_registration","label":"Vehicle registration","type":"text","max_length":15},{"key":"vehicle_description","label":"Make, model and colour","type":"text","max_length":200},{"key":"person_count","label":"Number of occupants","type":"integer","min":0,"max":50},{"key":"direction","label":"Direction of travel","type":"select","options":["N","NE","E","SE","S","SW","W","NW","Stationary"]}]', 1);
+
+INSERT INTO incident_categories (name, description, fields, display_order)
+VALUES ('Suspicious person', 'Person or group behaving suspiciously', '[{"key":"person_count","label":"Number of persons","type":"integer","required":true,"min":1,"max":50},{"key":"person_description","label":"Description","type":"text","max_length":500},{"key":"direction","label":"Direction of travel","type":"select","options":["N","NE","E","SE","S","SW","W","NW","Stationary"]}]', 2);
+
+INSERT INTO incident_categories (name, description, fields, display_order)
+VALUES ('Break-in', 'Break-in or attempted break-in at a property', '[{"key":"address","label":"Address","type":"text","max_length":200},{"key":"person_count","label":"Number of suspects","type":"integer","min":0,"max":50}]', 3);
+
+INSERT INTO incident_categories (name, description, fields, display_order)
+VALUES ('Fence cut', 'Damaged or cut perimeter fence', '[{"key":"location_description","label":"Where on the fence","type":"text","max_length":200}]', 4);
+
+INSERT INTO incident_categories (name, description, fields, display_order)
+VALUES ('Other', 'Anything that does not fit another category', '[]', 99);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_incident_escalation_messages_outbox_id;
+DROP TABLE IF EXISTS incident_escalation_messages;
+
+DROP INDEX IF EXISTS idx_incident_escalations_status;
+DROP TABLE IF EXISTS incident_escalations;
@@ -0,0 +1,24 @@
+-- Escalation state for serious (severity 2) reports.
+-- status moves from 'pending' to 'acknowledged' when an owl or admin acknowledges the alert.
+-- A pending escalation whose escalate_at has passed has had its SMS stage released.
+CREATE TABLE incident_escalations (
+    escalation_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    report_id INTEGER NOT NULL UNIQUE REFERENCES reports(report_id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'acknowledged')),
+    escalate_at DATETIME NOT NULL,
+    acknowledged_by_user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    acknowledged_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_incident_escalations_status ON incident_escalations(status);
+
+-- Outbox messages sent as part of an escalation, by stage ('push' or 'sms')
+CREATE TABLE incident_escalation_messages (
+    escalation_id INTEGER NOT NULL REFERENCES incident_escalations(escalation_id) ON DELETE CASCADE,
+    outbox_id INTEGER NOT NULL REFERENCES outbox(outbox_id) ON DELETE CASCADE,
+    stage TEXT NOT NULL CHECK (stage IN ('push', 'sms')),
+    PRIMARY KEY (escalation_id, outbox_id)
+);
+
+CREATE INDEX idx_incident_escalation_messages_outbox_id ON incident_escalation_messages(outbox_id);
@@ -0,0 +1,4 @@
+-- Remove report retention policies
+ALTER TABLE reports DROP COLUMN anonymised_at;
+DROP INDEX IF EXISTS idx_report_retention_policies_scope;
+DROP TABLE IF EXISTS report_retention_policies;
@@ -0,0 +1,26 @@
+-- Admin-configurable report retention.
+-- A policy applies to a severity, a category, both or neither (the fallback).
+-- archive_after_days counts from report creation, NULL means never archive.
+-- purge_after_days counts from archiving, NULL means archived reports are kept forever.
+CREATE TABLE report_retention_policies (
+    policy_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    severity INTEGER CHECK (severity IN (0, 1, 2)),
+    category_id INTEGER REFERENCES incident_categories(category_id),
+    archive_after_days INTEGER CHECK (archive_after_days IS NULL OR archive_after_days >= 0),
+    purge_after_days INTEGER CHECK (purge_after_days IS NULL OR purge_after_days >= 0),
+    purge_action TEXT NOT NULL DEFAULT 'delete' CHECK (purge_action IN ('delete', 'anonymise')),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One policy per scope (NULLs are distinct in plain unique constraints)
+CREATE UNIQUE INDEX idx_report_retention_policies_scope
+    ON report_retention_policies(COALESCE(severity, -1), COALESCE(category_id, 0));
+
+-- Anonymised reports keep severity, category and timestamps for statistics
+ALTER TABLE reports ADD COLUMN anonymised_at DATETIME;
+
+-- Seed the rules that were previously hard-coded
+INSERT INTO report_retention_policies (severity, archive_after_days) VALUES (0, 30);
+INSERT INTO report_retention_policies (severity, archive_after_days) VALUES (1, 365);
+INSERT INTO report_retention_policies (severity, archive_after_days) VALUES (2, NULL);
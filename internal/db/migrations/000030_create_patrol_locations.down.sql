@@ -0,0 +1,6 @@
+-- Remove patrol location tracking
+DROP INDEX IF EXISTS idx_patrol_locations_recorded_at;
+DROP INDEX IF EXISTS idx_patrol_locations_booking_recorded;
+DROP TABLE IF EXISTS patrol_locations;
+DROP TABLE IF EXISTS patrol_location_sharing;
+ALTER TABLE bookings DROP COLUMN checked_out_at;
@@ -0,0 +1,27 @@
+-- Owls can end a shift explicitly, which also stops location sharing
+ALTER TABLE bookings ADD COLUMN checked_out_at DATETIME;
+
+-- Opt-in location sharing for a booking. Sharing is active while stopped_at is NULL,
+-- the booking is checked in and not checked out, and the shift has not ended.
+CREATE TABLE patrol_location_sharing (
+    booking_id INTEGER PRIMARY KEY REFERENCES bookings(booking_id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    started_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    stopped_at DATETIME,
+    stop_reason TEXT CHECK (stop_reason IN ('owl', 'check_out', 'shift_end'))
+);
+
+-- GPS breadcrumbs sent while sharing is active
+CREATE TABLE patrol_locations (
+    location_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    booking_id INTEGER NOT NULL REFERENCES bookings(booking_id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    latitude REAL NOT NULL,
+    longitude REAL NOT NULL,
+    accuracy REAL,
+    recorded_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_patrol_locations_booking_recorded ON patrol_locations(booking_id, recorded_at);
+CREATE INDEX idx_patrol_locations_recorded_at ON patrol_locations(recorded_at);
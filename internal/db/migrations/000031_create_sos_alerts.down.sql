@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS sos_acknowledgements;
+DROP INDEX IF EXISTS idx_sos_alerts_active_user;
+DROP INDEX IF EXISTS idx_sos_alerts_user_id;
+DROP INDEX IF EXISTS idx_sos_alerts_status;
+DROP TABLE IF EXISTS sos_alerts;
@@ -0,0 +1,30 @@
+-- SOS alerts raised by owls. Each alert creates a severity-2 report and an
+-- escalation whose push and SMS messages are dispatched immediately.
+-- status moves from 'active' to 'stood_down' when an admin stands the alert down.
+CREATE TABLE sos_alerts (
+    alert_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    booking_id INTEGER REFERENCES bookings(booking_id) ON DELETE SET NULL,
+    report_id INTEGER REFERENCES reports(report_id) ON DELETE SET NULL,
+    latitude REAL,
+    longitude REAL,
+    accuracy REAL,
+    status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'stood_down')),
+    stood_down_by_user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    stood_down_at DATETIME,
+    stand_down_note TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_sos_alerts_status ON sos_alerts(status);
+CREATE INDEX idx_sos_alerts_user_id ON sos_alerts(user_id);
+-- An owl can have only one active SOS, so concurrent taps raise a single alert
+CREATE UNIQUE INDEX idx_sos_alerts_active_user ON sos_alerts(user_id) WHERE status = 'active';
+
+-- Owls and admins who have acknowledged an SOS and are responding
+CREATE TABLE sos_acknowledgements (
+    alert_id INTEGER NOT NULL REFERENCES sos_alerts(alert_id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    acknowledged_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (alert_id, user_id)
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_handover_notes_user_id;
+DROP TABLE IF EXISTS handover_notes;
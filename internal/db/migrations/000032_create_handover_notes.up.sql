@@ -0,0 +1,16 @@
+-- Handover notes left by the outgoing owl at the end of a patrol. They are
+-- shown to owls on the next slot of the same schedule and on overlapping
+-- schedules once they check in. One note per booking, editable by its owner.
+CREATE TABLE handover_notes (
+    handover_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    booking_id INTEGER NOT NULL UNIQUE REFERENCES bookings(booking_id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    open_issues TEXT NOT NULL DEFAULT '',
+    vehicles_seen TEXT NOT NULL DEFAULT '',
+    gates_open TEXT NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_handover_notes_user_id ON handover_notes(user_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_report_flags_reporter;
+DROP TABLE IF EXISTS report_flags;
+DROP INDEX IF EXISTS idx_off_shift_report_submissions_ip;
+DROP INDEX IF EXISTS idx_off_shift_report_submissions_user;
+DROP TABLE IF EXISTS off_shift_report_submissions;
@@ -0,0 +1,26 @@
+-- Accepted off-shift report submissions, counted per user and per client IP
+-- over a sliding window to rate limit off-shift reporting.
+CREATE TABLE off_shift_report_submissions (
+    submission_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    client_ip TEXT,
+    report_id INTEGER REFERENCES reports(report_id) ON DELETE SET NULL,
+    submitted_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_off_shift_report_submissions_user ON off_shift_report_submissions(user_id, submitted_at);
+CREATE INDEX idx_off_shift_report_submissions_ip ON off_shift_report_submissions(client_ip, submitted_at);
+
+-- Admin verdicts on reports that turned out to be spam or a false alarm.
+-- The reporter is kept on the flag so it still counts against their trust
+-- score after the report itself is anonymised.
+CREATE TABLE report_flags (
+    report_id INTEGER PRIMARY KEY REFERENCES reports(report_id) ON DELETE CASCADE,
+    reporter_user_id INTEGER REFERENCES users(user_id) ON DELETE CASCADE,
+    flag TEXT NOT NULL CHECK (flag IN ('spam', 'false_alarm')),
+    note TEXT,
+    flagged_by_user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    flagged_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_report_flags_reporter ON report_flags(reporter_user_id, flagged_at);
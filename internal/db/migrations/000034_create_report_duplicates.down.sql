@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_report_merges_primary;
+DROP TABLE IF EXISTS report_merges;
+DROP INDEX IF EXISTS idx_report_duplicate_matches_duplicate_of;
+DROP TABLE IF EXISTS report_duplicate_matches;
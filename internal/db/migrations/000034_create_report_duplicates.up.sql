@@ -0,0 +1,23 @@
+-- Likely duplicates found when a report is filed: the earlier report it most
+-- resembles by time, distance and wording, and how closely it matched.
+CREATE TABLE report_duplicate_matches (
+    report_id INTEGER PRIMARY KEY REFERENCES reports(report_id) ON DELETE CASCADE,
+    duplicate_of_report_id INTEGER NOT NULL REFERENCES reports(report_id) ON DELETE CASCADE,
+    score REAL NOT NULL,
+    distance_meters REAL,
+    detected_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_report_duplicate_matches_duplicate_of ON report_duplicate_matches(duplicate_of_report_id);
+
+-- Reports an admin merged into a primary incident. Merged reports are archived
+-- and their photos moved to the primary, but their messages and reporters stay
+-- on the original rows.
+CREATE TABLE report_merges (
+    report_id INTEGER PRIMARY KEY REFERENCES reports(report_id) ON DELETE CASCADE,
+    primary_report_id INTEGER NOT NULL REFERENCES reports(report_id) ON DELETE CASCADE,
+    merged_by_user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    merged_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_report_merges_primary ON report_merges(primary_report_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_tip_submissions_submitted_at;
+DROP INDEX IF EXISTS idx_tip_submissions_client;
+DROP TABLE IF EXISTS tip_submissions;
+DROP INDEX IF EXISTS idx_tips_status;
+DROP TABLE IF EXISTS tips;
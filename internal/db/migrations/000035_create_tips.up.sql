@@ -0,0 +1,32 @@
+-- Anonymous tips from residents who are not registered owls. Tips wait for
+-- admin review and are then promoted to a report or discarded. Only what the
+-- tipster chose to give is kept: no user, IP address or device details.
+CREATE TABLE tips (
+    tip_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    message TEXT NOT NULL,
+    severity INTEGER NOT NULL DEFAULT 0 CHECK (severity BETWEEN 0 AND 2),
+    latitude REAL,
+    longitude REAL,
+    contact TEXT,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'promoted', 'discarded')),
+    report_id INTEGER REFERENCES reports(report_id) ON DELETE SET NULL,
+    review_note TEXT,
+    reviewed_by_user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    reviewed_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_tips_status ON tips(status, created_at);
+
+-- Rate limiting for the public tip endpoint. Rows are not linked to tips: the
+-- client is identified only by a keyed hash that changes daily, and each proof
+-- of work challenge can be redeemed once.
+CREATE TABLE tip_submissions (
+    submission_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    client_hash TEXT NOT NULL,
+    challenge_hash TEXT NOT NULL UNIQUE,
+    submitted_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_tip_submissions_client ON tip_submissions(client_hash, submitted_at);
+CREATE INDEX idx_tip_submissions_submitted_at ON tip_submissions(submitted_at);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_watchlist_matches_report;
+DROP TABLE IF EXISTS watchlist_matches;
+DROP INDEX IF EXISTS idx_watchlist_photos_entry;
+DROP TABLE IF EXISTS watchlist_photos;
+DROP INDEX IF EXISTS idx_watchlist_entries_active;
+DROP TABLE IF EXISTS watchlist_entries;
@@ -0,0 +1,42 @@
+-- Vehicles and people of interest that owls keep seeing. New reports are
+-- matched against the registration and keywords of active entries.
+CREATE TABLE watchlist_entries (
+    entry_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL CHECK (kind IN ('vehicle', 'person')),
+    label TEXT NOT NULL,
+    registration TEXT, -- Normalised: upper case letters and digits only
+    description TEXT,
+    notes TEXT,
+    keywords TEXT NOT NULL DEFAULT '[]', -- JSON array of phrases matched against report text
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    created_by_user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_watchlist_entries_active ON watchlist_entries(is_active);
+
+CREATE TABLE watchlist_photos (
+    photo_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entry_id INTEGER NOT NULL REFERENCES watchlist_entries(entry_id) ON DELETE CASCADE,
+    filename TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    file_size_bytes INTEGER NOT NULL,
+    storage_path TEXT NOT NULL,
+    uploaded_by_user_id INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_watchlist_photos_entry ON watchlist_photos(entry_id);
+
+-- Reports linked to a watchlist entry, and what in the report matched
+CREATE TABLE watchlist_matches (
+    entry_id INTEGER NOT NULL REFERENCES watchlist_entries(entry_id) ON DELETE CASCADE,
+    report_id INTEGER NOT NULL REFERENCES reports(report_id) ON DELETE CASCADE,
+    matched_on TEXT NOT NULL CHECK (matched_on IN ('registration', 'keyword')),
+    matched_term TEXT NOT NULL,
+    matched_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (entry_id, report_id)
+);
+
+CREATE INDEX idx_watchlist_matches_report ON watchlist_matches(report_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_outbox_provider_message;
+
+ALTER TABLE outbox DROP COLUMN delivery_updated_at;
+ALTER TABLE outbox DROP COLUMN delivery_error;
+ALTER TABLE outbox DROP COLUMN delivery_status;
+ALTER TABLE outbox DROP COLUMN provider_message_id;
+ALTER TABLE outbox DROP COLUMN provider;
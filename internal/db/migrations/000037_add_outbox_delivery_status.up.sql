@@ -0,0 +1,10 @@
+-- Track SMS gateway delivery for outbox items. The provider's message ID is
+-- stored when a message is handed over so that delivery report callbacks can
+-- be matched back to the row.
+ALTER TABLE outbox ADD COLUMN provider TEXT;
+ALTER TABLE outbox ADD COLUMN provider_message_id TEXT;
+ALTER TABLE outbox ADD COLUMN delivery_status TEXT CHECK (delivery_status IN ('sent', 'delivered', 'failed'));
+ALTER TABLE outbox ADD COLUMN delivery_error TEXT;
+ALTER TABLE outbox ADD COLUMN delivery_updated_at DATETIME;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_provider_message ON outbox(provider, provider_message_id);
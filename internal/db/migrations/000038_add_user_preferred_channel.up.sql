@@ -0,0 +1,3 @@
+-- The channel a user would like notifications on. Messages fall back to the
+-- other channels when the preferred one is unavailable for the user.
+ALTER TABLE users ADD COLUMN preferred_channel TEXT NOT NULL DEFAULT 'push' CHECK (preferred_channel IN ('push', 'sms', 'email'));
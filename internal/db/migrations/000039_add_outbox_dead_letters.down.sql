@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_outbox_status_send_at;
+
+ALTER TABLE outbox DROP COLUMN dead_lettered_at;
+ALTER TABLE outbox DROP COLUMN last_error;
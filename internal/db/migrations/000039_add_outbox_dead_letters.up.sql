@@ -0,0 +1,8 @@
+-- Failed outbox items are retried with backoff by moving send_at forward,
+-- and dead-lettered (status 'permanently_failed') once out of retries.
+ALTER TABLE outbox ADD COLUMN last_error TEXT;
+ALTER TABLE outbox ADD COLUMN dead_lettered_at DATETIME;
+
+UPDATE outbox SET dead_lettered_at = created_at WHERE status = 'permanently_failed';
+
+CREATE INDEX IF NOT EXISTS idx_outbox_status_send_at ON outbox(status, send_at);
@@ -0,0 +1,5 @@
+-- Dispatcher runs claim outbox rows by leasing them. A lease keeps other runs
+-- off the rows until it expires, so items held by a crashed run are picked up
+-- again later instead of being lost or sent twice.
+ALTER TABLE outbox ADD COLUMN lease_token TEXT;
+ALTER TABLE outbox ADD COLUMN leased_until DATETIME;
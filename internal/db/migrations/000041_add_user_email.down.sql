@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_users_email;
+ALTER TABLE users DROP COLUMN email_verification_attempts;
+ALTER TABLE users DROP COLUMN email_verification_expires_at;
+ALTER TABLE users DROP COLUMN email_verification_code_hash;
+ALTER TABLE users DROP COLUMN pending_email;
+ALTER TABLE users DROP COLUMN email_verified_at;
+ALTER TABLE users DROP COLUMN email;
@@ -0,0 +1,11 @@
+-- Optional email address for notifications. email only holds an address the
+-- user has confirmed; an address awaiting confirmation is kept in
+-- pending_email along with a hash of the code that was sent to it.
+ALTER TABLE users ADD COLUMN email TEXT;
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME;
+ALTER TABLE users ADD COLUMN pending_email TEXT;
+ALTER TABLE users ADD COLUMN email_verification_code_hash TEXT;
+ALTER TABLE users ADD COLUMN email_verification_expires_at DATETIME;
+ALTER TABLE users ADD COLUMN email_verification_attempts INTEGER NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX idx_users_email ON users(email) WHERE email IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE broadcasts DROP COLUMN urgent;
+ALTER TABLE outbox DROP COLUMN category;
+DROP TABLE IF EXISTS notification_preferences;
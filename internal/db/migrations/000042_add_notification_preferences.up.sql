@@ -0,0 +1,20 @@
+-- Per-user notification preferences. Users without a row get the defaults:
+-- every category and no quiet hours. The channel is users.preferred_channel.
+CREATE TABLE notification_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES users(user_id) ON DELETE CASCADE,
+    reminders BOOLEAN NOT NULL DEFAULT TRUE,
+    broadcasts BOOLEAN NOT NULL DEFAULT TRUE,
+    coverage_alerts BOOLEAN NOT NULL DEFAULT TRUE,
+    report_updates BOOLEAN NOT NULL DEFAULT TRUE,
+    quiet_hours_start TEXT, -- HH:MM in the user's time zone; both ends are set or neither
+    quiet_hours_end TEXT,
+    timezone TEXT, -- IANA zone; NOTIFICATION_TIMEZONE when NULL
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The preference category an outbox item falls under. Items without one,
+-- such as OTPs and booking confirmations, are always sent straight away.
+ALTER TABLE outbox ADD COLUMN category TEXT;
+
+-- Urgent broadcasts reach everyone in the audience, even during quiet hours
+ALTER TABLE broadcasts ADD COLUMN urgent BOOLEAN NOT NULL DEFAULT FALSE;
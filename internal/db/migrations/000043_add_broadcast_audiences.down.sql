@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_broadcast_recipients_user;
+DROP TABLE IF EXISTS broadcast_recipients;
+ALTER TABLE broadcasts DROP COLUMN audience_filter;
+DROP INDEX IF EXISTS idx_broadcast_group_members_user;
+DROP TABLE IF EXISTS broadcast_group_members;
+DROP TABLE IF EXISTS broadcast_groups;
@@ -0,0 +1,29 @@
+-- Admin-defined named groups of users that broadcasts can be addressed to
+CREATE TABLE broadcast_groups (
+    group_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    description TEXT,
+    created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE broadcast_group_members (
+    group_id INTEGER NOT NULL REFERENCES broadcast_groups(group_id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    added_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_broadcast_group_members_user ON broadcast_group_members(user_id);
+
+-- The audience criteria as JSON; audience is 'custom' when they are set
+ALTER TABLE broadcasts ADD COLUMN audience_filter TEXT;
+
+-- The users a broadcast's audience resolved to when it was created
+CREATE TABLE broadcast_recipients (
+    broadcast_id INTEGER NOT NULL REFERENCES broadcasts(broadcast_id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    PRIMARY KEY (broadcast_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_broadcast_recipients_user ON broadcast_recipients(user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE broadcast_recipients DROP COLUMN resent_at;
+ALTER TABLE broadcast_recipients DROP COLUMN resend_outbox_id;
+ALTER TABLE broadcast_recipients DROP COLUMN read_at;
+ALTER TABLE broadcast_recipients DROP COLUMN outbox_id;
@@ -0,0 +1,7 @@
+-- Delivery and read receipts for broadcast recipients. The outbox item records
+-- whether the message was delivered; read_at is set when the app acknowledges it.
+-- The outbox columns are not foreign keys so they can be dropped again.
+ALTER TABLE broadcast_recipients ADD COLUMN outbox_id INTEGER;
+ALTER TABLE broadcast_recipients ADD COLUMN read_at DATETIME;
+ALTER TABLE broadcast_recipients ADD COLUMN resend_outbox_id INTEGER;
+ALTER TABLE broadcast_recipients ADD COLUMN resent_at DATETIME;
@@ -0,0 +1,5 @@
+ALTER TABLE broadcast_recipients DROP COLUMN message;
+DROP INDEX IF EXISTS idx_broadcasts_parent;
+ALTER TABLE broadcasts DROP COLUMN parent_broadcast_id;
+ALTER TABLE broadcasts DROP COLUMN recurrence_cron;
+DROP TABLE IF EXISTS broadcast_templates;
@@ -0,0 +1,21 @@
+-- Reusable broadcast wording. Messages may use variables that are filled in
+-- for each recipient.
+CREATE TABLE broadcast_templates (
+    template_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_by INTEGER REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A recurring broadcast has status 'recurring' and scheduled_at set to its
+-- next run; each run is sent as a broadcast pointing back at it
+ALTER TABLE broadcasts ADD COLUMN recurrence_cron TEXT;
+ALTER TABLE broadcasts ADD COLUMN parent_broadcast_id INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_broadcasts_parent ON broadcasts(parent_broadcast_id);
+
+-- The message as rendered for the recipient
+ALTER TABLE broadcast_recipients ADD COLUMN message TEXT;
@@ -0,0 +1,7 @@
+ALTER TABLE push_subscriptions DROP COLUMN last_error;
+ALTER TABLE push_subscriptions DROP COLUMN last_failure_at;
+ALTER TABLE push_subscriptions DROP COLUMN last_success_at;
+ALTER TABLE push_subscriptions DROP COLUMN consecutive_failures;
+ALTER TABLE push_subscriptions DROP COLUMN failure_count;
+ALTER TABLE push_subscriptions DROP COLUMN success_count;
+ALTER TABLE push_subscriptions DROP COLUMN device_label;
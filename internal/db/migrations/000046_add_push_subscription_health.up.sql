@@ -0,0 +1,9 @@
+-- Delivery health of each push subscription. consecutive_failures is reset by
+-- a successful send; endpoints that keep failing are pruned by the sender.
+ALTER TABLE push_subscriptions ADD COLUMN device_label TEXT;
+ALTER TABLE push_subscriptions ADD COLUMN success_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE push_subscriptions ADD COLUMN failure_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE push_subscriptions ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE push_subscriptions ADD COLUMN last_success_at DATETIME;
+ALTER TABLE push_subscriptions ADD COLUMN last_failure_at DATETIME;
+ALTER TABLE push_subscriptions ADD COLUMN last_error TEXT;
@@ -91,6 +91,16 @@ WHERE ae.created_at >= ? AND ae.created_at <= ?
 ORDER BY ae.created_at DESC
 LIMIT ? OFFSET ?;
 
+-- name: ListAuditEventsByEntity :many
+-- Full history of a single record, oldest first
+SELECT 
+    ae.*,
+    COALESCE(actor.name, '') as actor_name
+FROM audit_events ae
+LEFT JOIN users actor ON ae.actor_user_id = actor.user_id
+WHERE ae.entity_type = ? AND ae.entity_id = ?
+ORDER BY ae.created_at ASC, ae.event_id ASC;
+
 -- name: ListAuditEventsWithFilters :many
 SELECT 
     ae.*,
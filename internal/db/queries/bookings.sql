@@ -52,6 +52,12 @@ SET checked_in_at = ?
 WHERE booking_id = ?
 RETURNING *;
 
+-- name: UpdateBookingCheckOut :one
+UPDATE bookings
+SET checked_out_at = ?
+WHERE booking_id = ? AND checked_out_at IS NULL
+RETURNING *;
+
 -- name: DeleteBooking :exec
 DELETE FROM bookings
 WHERE booking_id = ?;
@@ -140,4 +146,16 @@ WHERE b.shift_start >= datetime('now', '-60 days')
     AND b.shift_start <= datetime('now')
 GROUP BY day_of_week, hour_of_day
 HAVING total_bookings >= 3
-ORDER BY check_in_rate ASC, completion_rate ASC; 
\ No newline at end of file
+ORDER BY check_in_rate ASC, completion_rate ASC; 
+-- name: ListOnDutyBookings :many
+SELECT * FROM bookings
+WHERE shift_start <= ? AND shift_end > ?
+ORDER BY shift_start ASC;
+
+-- name: GetNextBookingForUser :one
+SELECT b.shift_start, s.name AS schedule_name
+FROM bookings b
+JOIN schedules s ON b.schedule_id = s.schedule_id
+WHERE b.user_id = ? AND b.shift_start > ?
+ORDER BY b.shift_start
+LIMIT 1;
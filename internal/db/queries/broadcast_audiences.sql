@@ -0,0 +1,81 @@
+-- name: ListBroadcastAudience :many
+-- Every filter is optional; users must match all of those that are set
+SELECT u.user_id, u.phone, u.name, u.role
+FROM users u
+WHERE (sqlc.narg('role') IS NULL OR u.role = sqlc.narg('role'))
+  AND (sqlc.narg('active_since') IS NULL OR u.last_activity_date >= sqlc.narg('active_since'))
+  AND (sqlc.narg('group_id') IS NULL OR EXISTS (
+      SELECT 1 FROM broadcast_group_members m
+      WHERE m.user_id = u.user_id AND m.group_id = sqlc.narg('group_id')
+  ))
+  AND (NOT CAST(sqlc.arg('booked') AS BOOLEAN) OR EXISTS (
+      SELECT 1 FROM bookings b
+      WHERE b.user_id = u.user_id
+        AND (sqlc.narg('schedule_id') IS NULL OR b.schedule_id = sqlc.narg('schedule_id'))
+        AND (sqlc.narg('shift_from') IS NULL OR b.shift_start >= sqlc.narg('shift_from'))
+        AND (sqlc.narg('shift_to') IS NULL OR b.shift_start < sqlc.narg('shift_to'))
+  ))
+  AND (NOT CAST(sqlc.arg('without_push') AS BOOLEAN) OR NOT EXISTS (
+      SELECT 1 FROM push_subscriptions p WHERE p.user_id = u.user_id
+  ))
+  AND (NOT CAST(sqlc.arg('without_bookings') AS BOOLEAN) OR NOT EXISTS (
+      SELECT 1 FROM bookings b WHERE b.user_id = u.user_id
+  ))
+ORDER BY u.user_id;
+
+-- name: AddBroadcastRecipient :exec
+INSERT OR IGNORE INTO broadcast_recipients (broadcast_id, user_id)
+VALUES (?, ?);
+
+-- name: ListBroadcastRecipients :many
+SELECT u.user_id, u.phone, u.name, u.role
+FROM broadcast_recipients r
+JOIN users u ON u.user_id = r.user_id
+WHERE r.broadcast_id = ?
+ORDER BY u.user_id;
+
+-- name: CreateBroadcastGroup :one
+INSERT INTO broadcast_groups (name, description, created_by)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: GetBroadcastGroup :one
+SELECT * FROM broadcast_groups
+WHERE group_id = ?;
+
+-- name: ListBroadcastGroups :many
+SELECT
+    g.group_id,
+    g.name,
+    g.description,
+    g.created_by,
+    g.created_at,
+    (SELECT COUNT(*) FROM broadcast_group_members m WHERE m.group_id = g.group_id) AS member_count
+FROM broadcast_groups g
+ORDER BY g.name;
+
+-- name: UpdateBroadcastGroup :one
+UPDATE broadcast_groups
+SET name = ?,
+    description = ?
+WHERE group_id = ?
+RETURNING *;
+
+-- name: DeleteBroadcastGroup :execrows
+DELETE FROM broadcast_groups
+WHERE group_id = ?;
+
+-- name: AddBroadcastGroupMember :exec
+INSERT OR IGNORE INTO broadcast_group_members (group_id, user_id)
+VALUES (?, ?);
+
+-- name: RemoveBroadcastGroupMember :execrows
+DELETE FROM broadcast_group_members
+WHERE group_id = ? AND user_id = ?;
+
+-- name: ListBroadcastGroupMembers :many
+SELECT u.user_id, u.phone, u.name, u.role, m.added_at
+FROM broadcast_group_members m
+JOIN users u ON u.user_id = m.user_id
+WHERE m.group_id = ?
+ORDER BY u.name, u.user_id;
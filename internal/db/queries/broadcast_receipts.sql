@@ -0,0 +1,70 @@
+-- name: SetBroadcastRecipientOutboxItem :exec
+-- Also records the recipients of broadcasts created before recipients were stored
+INSERT INTO broadcast_recipients (broadcast_id, user_id, outbox_id, message)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (broadcast_id, user_id) DO UPDATE
+SET outbox_id = excluded.outbox_id,
+    message = excluded.message;
+
+-- name: MarkBroadcastRead :execrows
+-- Keeps the time of the first acknowledgement
+UPDATE broadcast_recipients
+SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+WHERE broadcast_id = ? AND user_id = ?;
+
+-- name: MarkBroadcastRecipientResent :exec
+UPDATE broadcast_recipients
+SET resend_outbox_id = ?,
+    resent_at = CURRENT_TIMESTAMP
+WHERE broadcast_id = ? AND user_id = ?;
+
+-- name: ListBroadcastReceipts :many
+SELECT
+    r.user_id,
+    u.name,
+    u.phone,
+    r.read_at,
+    r.resent_at,
+    o.message_type,
+    o.status AS outbox_status,
+    o.delivery_status,
+    o.sent_at,
+    rs.status AS resend_status,
+    rs.delivery_status AS resend_delivery_status,
+    r.message
+FROM broadcast_recipients r
+JOIN users u ON u.user_id = r.user_id
+LEFT JOIN outbox o ON o.outbox_id = r.outbox_id
+LEFT JOIN outbox rs ON rs.outbox_id = r.resend_outbox_id
+WHERE r.broadcast_id = ?
+ORDER BY u.user_id;
+
+-- name: ListUserBroadcastReceipts :many
+SELECT broadcast_id, read_at, message
+FROM broadcast_recipients
+WHERE user_id = ?;
+
+-- name: RefreshBroadcastDeliveryCounts :execrows
+-- Counts delivered and failed outbox items for broadcasts sent since the given
+-- time. Broadcasts sent before delivery was tracked keep their counts.
+UPDATE broadcasts
+SET sent_count = (
+        SELECT COUNT(*) FROM broadcast_recipients r
+        JOIN outbox o ON o.outbox_id = r.outbox_id
+        WHERE r.broadcast_id = broadcasts.broadcast_id
+          AND o.status = 'sent'
+          AND (o.delivery_status IS NULL OR o.delivery_status != 'failed')
+    ),
+    failed_count = (
+        SELECT COUNT(*) FROM broadcast_recipients r
+        JOIN outbox o ON o.outbox_id = r.outbox_id
+        WHERE r.broadcast_id = broadcasts.broadcast_id
+          AND (o.status IN ('permanently_failed', 'discarded')
+               OR (o.status = 'sent' AND o.delivery_status = 'failed'))
+    )
+WHERE status = 'sent'
+  AND sent_at >= ?
+  AND EXISTS (
+      SELECT 1 FROM broadcast_recipients r
+      WHERE r.broadcast_id = broadcasts.broadcast_id AND r.outbox_id IS NOT NULL
+  );
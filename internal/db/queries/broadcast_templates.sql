@@ -0,0 +1,25 @@
+-- name: CreateBroadcastTemplate :one
+INSERT INTO broadcast_templates (name, title, message, created_by)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetBroadcastTemplate :one
+SELECT * FROM broadcast_templates
+WHERE template_id = ?;
+
+-- name: ListBroadcastTemplates :many
+SELECT * FROM broadcast_templates
+ORDER BY name;
+
+-- name: UpdateBroadcastTemplate :one
+UPDATE broadcast_templates
+SET name = ?,
+    title = ?,
+    message = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE template_id = ?
+RETURNING *;
+
+-- name: DeleteBroadcastTemplate :execrows
+DELETE FROM broadcast_templates
+WHERE template_id = ?;
@@ -6,8 +6,18 @@ INSERT INTO broadcasts (
     sender_user_id,
     push_enabled,
     scheduled_at,
-    recipient_count
+    recipient_count,
+    urgent,
+    audience_filter,
+    status,
+    recurrence_cron,
+    parent_broadcast_id
 ) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
     ?,
     ?,
     ?,
@@ -41,6 +51,10 @@ SELECT
     b.sent_count,
     b.failed_count,
     b.created_at,
+    b.urgent,
+    b.audience_filter,
+    b.recurrence_cron,
+    b.parent_broadcast_id,
     COALESCE(u.name, 'Unknown User') as sender_name
 FROM broadcasts b
 LEFT JOIN users u ON b.sender_user_id = u.user_id
@@ -63,6 +77,19 @@ WHERE status = 'pending'
 AND (scheduled_at IS NULL OR scheduled_at <= datetime('now'))
 ORDER BY created_at ASC;
 
+-- name: ListDueRecurringBroadcasts :many
+SELECT * FROM broadcasts
+WHERE status = 'recurring'
+AND scheduled_at <= datetime('now')
+ORDER BY scheduled_at ASC;
+
+-- name: UpdateBroadcastSchedule :execrows
+-- Only broadcasts still waiting to be sent can be rescheduled
+UPDATE broadcasts
+SET scheduled_at = ?
+WHERE broadcast_id = ?
+AND status IN ('pending', 'recurring');
+
 -- name: DeleteBroadcast :exec
 DELETE FROM broadcasts 
 WHERE broadcast_id = ?; 
\ No newline at end of file
@@ -0,0 +1,59 @@
+-- Admin data exports. Rows are read in pages keyed on the primary key so
+-- that large exports can be streamed without loading every row at once.
+
+-- name: ListReportsForExport :many
+SELECT
+    r.report_id,
+    r.created_at,
+    r.severity,
+    r.message,
+    r.latitude,
+    r.longitude,
+    r.gps_accuracy,
+    r.category_id,
+    COALESCE(ic.name, '') AS category_name,
+    r.category_fields,
+    r.booking_id,
+    COALESCE(b.schedule_id, 0) AS schedule_id,
+    COALESCE(s.name, 'Off-Shift Report') AS schedule_name,
+    COALESCE(u.name, '') AS user_name,
+    r.archived_at
+FROM reports r
+LEFT JOIN users u ON r.user_id = u.user_id
+LEFT JOIN bookings b ON r.booking_id = b.booking_id
+LEFT JOIN schedules s ON b.schedule_id = s.schedule_id
+LEFT JOIN incident_categories ic ON r.category_id = ic.category_id
+WHERE r.report_id > sqlc.arg('after_id')
+  AND r.created_at >= sqlc.arg('created_from')
+  AND r.created_at < sqlc.arg('created_to')
+  AND (sqlc.narg('severity') IS NULL OR r.severity = sqlc.narg('severity'))
+  AND (sqlc.narg('category_id') IS NULL OR r.category_id = sqlc.narg('category_id'))
+  AND (sqlc.narg('schedule_id') IS NULL OR COALESCE(b.schedule_id, 0) = sqlc.narg('schedule_id'))
+  AND (sqlc.narg('user_id') IS NULL OR r.user_id = sqlc.narg('user_id'))
+ORDER BY r.report_id
+LIMIT sqlc.arg('page_size');
+
+-- name: ListBookingsForExport :many
+SELECT
+    b.booking_id,
+    b.shift_start,
+    b.shift_end,
+    b.schedule_id,
+    s.name AS schedule_name,
+    b.user_id,
+    COALESCE(u.name, '') AS user_name,
+    b.buddy_name,
+    b.checked_in_at,
+    b.checked_out_at,
+    b.created_at,
+    (SELECT COUNT(*) FROM reports r WHERE r.booking_id = b.booking_id) AS report_count
+FROM bookings b
+JOIN schedules s ON b.schedule_id = s.schedule_id
+JOIN users u ON b.user_id = u.user_id
+WHERE b.booking_id > sqlc.arg('after_id')
+  AND b.shift_start >= sqlc.arg('shift_from')
+  AND b.shift_start < sqlc.arg('shift_to')
+  AND (sqlc.narg('schedule_id') IS NULL OR b.schedule_id = sqlc.narg('schedule_id'))
+  AND (sqlc.narg('user_id') IS NULL OR b.user_id = sqlc.narg('user_id'))
+ORDER BY b.booking_id
+LIMIT sqlc.arg('page_size');
@@ -0,0 +1,40 @@
+-- name: UpsertHandoverNote :one
+INSERT INTO handover_notes (booking_id, user_id, open_issues, vehicles_seen, gates_open, notes)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(booking_id) DO UPDATE SET
+    open_issues = excluded.open_issues,
+    vehicles_seen = excluded.vehicles_seen,
+    gates_open = excluded.gates_open,
+    notes = excluded.notes,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetHandoverNoteByBookingID :one
+SELECT * FROM handover_notes
+WHERE booking_id = ?;
+
+-- name: ListIncomingHandoverNotes :many
+SELECT
+    hn.handover_id,
+    hn.booking_id,
+    hn.user_id,
+    COALESCE(u.name, '') AS user_name,
+    b.schedule_id,
+    s.name AS schedule_name,
+    b.shift_start,
+    b.shift_end,
+    hn.open_issues,
+    hn.vehicles_seen,
+    hn.gates_open,
+    hn.notes,
+    hn.created_at,
+    hn.updated_at
+FROM handover_notes hn
+JOIN bookings b ON hn.booking_id = b.booking_id
+JOIN schedules s ON b.schedule_id = s.schedule_id
+JOIN users u ON hn.user_id = u.user_id
+WHERE hn.booking_id != sqlc.arg('booking_id')
+  AND b.shift_start < sqlc.arg('shift_start')
+  AND b.shift_end >= sqlc.arg('since')
+ORDER BY b.shift_end DESC, hn.handover_id DESC
+LIMIT ?;
@@ -0,0 +1,38 @@
+-- name: ListIncidentCategories :many
+SELECT category_id, name, description, fields, is_active, display_order, created_at, updated_at
+FROM incident_categories
+WHERE is_active = 1
+ORDER BY display_order ASC, name ASC;
+
+-- name: GetIncidentCategoryByID :one
+SELECT category_id, name, description, fields, is_active, display_order, created_at, updated_at
+FROM incident_categories
+WHERE category_id = ? AND is_active = 1;
+
+-- name: CreateIncidentCategory :one
+INSERT INTO incident_categories (name, description, fields, display_order)
+VALUES (?, ?, ?, ?)
+RETURNING category_id, name, description, fields, is_active, display_order, created_at, updated_at;
+
+-- name: UpdateIncidentCategory :one
+UPDATE incident_categories
+SET name = ?, description = ?, fields = ?, display_order = ?, updated_at = CURRENT_TIMESTAMP
+WHERE category_id = ? AND is_active = 1
+RETURNING category_id, name, description, fields, is_active, display_order, created_at, updated_at;
+
+-- name: DeleteIncidentCategory :exec
+UPDATE incident_categories
+SET is_active = 0, updated_at = CURRENT_TIMESTAMP
+WHERE category_id = ?;
+
+-- name: GetReportCategoryBreakdown :many
+SELECT
+    COALESCE(ic.category_id, 0) AS category_id,
+    COALESCE(ic.name, 'Uncategorised') AS category_name,
+    COUNT(r.report_id) AS report_count,
+    COUNT(CASE WHEN r.severity = 2 THEN 1 END) AS incident_count
+FROM reports r
+LEFT JOIN incident_categories ic ON r.category_id = ic.category_id
+WHERE r.created_at >= ?
+GROUP BY COALESCE(ic.category_id, 0), COALESCE(ic.name, 'Uncategorised')
+ORDER BY report_count DESC, category_name ASC;
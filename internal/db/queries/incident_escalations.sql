@@ -0,0 +1,49 @@
+-- name: CreateIncidentEscalation :one
+INSERT INTO incident_escalations (report_id, escalate_at)
+VALUES (?, ?)
+RETURNING *;
+
+-- name: GetIncidentEscalationByReportID :one
+SELECT * FROM incident_escalations
+WHERE report_id = ?;
+
+-- name: AcknowledgeIncidentEscalation :one
+UPDATE incident_escalations
+SET status = 'acknowledged',
+    acknowledged_by_user_id = ?,
+    acknowledged_at = CURRENT_TIMESTAMP
+WHERE escalation_id = ? AND status = 'pending'
+RETURNING *;
+
+-- name: LinkEscalationOutboxItem :exec
+INSERT INTO incident_escalation_messages (escalation_id, outbox_id, stage)
+VALUES (?, ?, ?);
+
+-- name: CancelPendingEscalationMessages :execrows
+UPDATE outbox
+SET status = 'cancelled'
+WHERE status IN ('pending', 'failed')
+  AND outbox_id IN (
+    SELECT m.outbox_id FROM incident_escalation_messages m
+    WHERE m.escalation_id = ? AND m.stage = 'sms'
+  );
+
+-- name: ListEscalationMessages :many
+SELECT
+    m.stage,
+    o.outbox_id,
+    o.message_type,
+    o.recipient,
+    o.user_id,
+    o.status,
+    o.send_at,
+    o.sent_at
+FROM incident_escalation_messages m
+JOIN outbox o ON m.outbox_id = o.outbox_id
+WHERE m.escalation_id = ?
+ORDER BY o.send_at ASC, o.outbox_id ASC;
+
+-- name: CountEscalationPushRecipient :one
+SELECT COUNT(*) FROM incident_escalation_messages m
+JOIN outbox o ON m.outbox_id = o.outbox_id
+WHERE m.escalation_id = ? AND m.stage = 'push' AND o.user_id = ?;
@@ -0,0 +1,45 @@
+-- name: GetNotificationPreferences :one
+-- Preference columns are NULL for users who have never saved any
+SELECT
+    u.user_id,
+    u.preferred_channel,
+    np.reminders,
+    np.broadcasts,
+    np.coverage_alerts,
+    np.report_updates,
+    np.quiet_hours_start,
+    np.quiet_hours_end,
+    np.timezone
+FROM users u
+LEFT JOIN notification_preferences np ON np.user_id = u.user_id
+WHERE u.user_id = ?;
+
+-- name: UpsertNotificationPreferences :exec
+INSERT INTO notification_preferences (
+    user_id,
+    reminders,
+    broadcasts,
+    coverage_alerts,
+    report_updates,
+    quiet_hours_start,
+    quiet_hours_end,
+    timezone
+) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
+    ?
+)
+ON CONFLICT(user_id) DO UPDATE SET
+    reminders = excluded.reminders,
+    broadcasts = excluded.broadcasts,
+    coverage_alerts = excluded.coverage_alerts,
+    report_updates = excluded.report_updates,
+    quiet_hours_start = excluded.quiet_hours_start,
+    quiet_hours_end = excluded.quiet_hours_end,
+    timezone = excluded.timezone,
+    updated_at = CURRENT_TIMESTAMP;
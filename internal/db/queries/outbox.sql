@@ -4,19 +4,21 @@ INSERT INTO outbox (
     recipient,
     payload,
     user_id,
-    send_at
+    send_at,
+    category
 ) VALUES (
     ?,
     ?,
     ?,
     ?,
+    ?,
     ?
 )
 RETURNING *;
 
 -- name: GetPendingOutboxItems :many
 SELECT * FROM outbox
-WHERE status = 'pending'
+WHERE status IN ('pending', 'failed')
   AND send_at <= CURRENT_TIMESTAMP
 ORDER BY created_at ASC
 LIMIT ?; -- Limit to prevent processing too many at once
@@ -33,4 +35,139 @@ SET status = ?,
     sent_at = ?,
     retry_count = ?
 WHERE outbox_id = ?
-RETURNING *; 
\ No newline at end of file
+RETURNING *; 
+-- name: GetOutboxItemByID :one
+SELECT * FROM outbox
+WHERE outbox_id = ?;
+
+-- name: SetOutboxProviderMessage :exec
+UPDATE outbox
+SET provider = ?,
+    provider_message_id = ?,
+    delivery_status = 'sent',
+    delivery_updated_at = CURRENT_TIMESTAMP
+WHERE outbox_id = ?;
+
+-- name: UpdateOutboxDeliveryStatus :execrows
+-- Delivered and failed are final, so a late intermediate report cannot overwrite them
+UPDATE outbox
+SET delivery_status = ?,
+    delivery_error = ?,
+    delivery_updated_at = CURRENT_TIMESTAMP
+WHERE provider = ?
+  AND provider_message_id = ?
+  AND (delivery_status IS NULL OR delivery_status = 'sent');
+
+-- name: RescheduleOutboxItem :execrows
+-- Failed items are retried once send_at comes round again
+UPDATE outbox
+SET status = 'failed',
+    retry_count = ?,
+    send_at = ?,
+    last_error = ?,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND lease_token = ?
+  AND status != 'cancelled';
+
+-- name: DeadLetterOutboxItem :execrows
+UPDATE outbox
+SET status = 'permanently_failed',
+    retry_count = ?,
+    last_error = ?,
+    dead_lettered_at = CURRENT_TIMESTAMP,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND lease_token = ?
+  AND status != 'cancelled';
+
+-- name: ListDeadLetterOutboxItems :many
+SELECT * FROM outbox
+WHERE status = 'permanently_failed'
+  AND (sqlc.narg('message_type') IS NULL OR message_type = sqlc.narg('message_type'))
+ORDER BY dead_lettered_at DESC, outbox_id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: RequeueOutboxItem :one
+UPDATE outbox
+SET status = 'pending',
+    retry_count = 0,
+    send_at = ?,
+    dead_lettered_at = NULL,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND status = 'permanently_failed'
+RETURNING *;
+
+-- name: DiscardOutboxItem :one
+UPDATE outbox
+SET status = 'discarded'
+WHERE outbox_id = ?
+  AND status = 'permanently_failed'
+RETURNING *;
+
+-- name: ClaimOutboxItems :many
+-- Leases a batch of due items to one dispatcher run. Items leased by another
+-- run are skipped until the lease expires.
+UPDATE outbox
+SET lease_token = ?,
+    leased_until = ?
+WHERE outbox_id IN (
+    SELECT outbox_id FROM outbox
+    WHERE status IN ('pending', 'failed')
+      AND send_at <= CURRENT_TIMESTAMP
+      AND (leased_until IS NULL OR leased_until <= CURRENT_TIMESTAMP)
+    ORDER BY created_at ASC
+    LIMIT ?
+)
+RETURNING *;
+
+-- name: ClaimOutboxItemsByID :many
+UPDATE outbox
+SET lease_token = ?,
+    leased_until = ?
+WHERE outbox_id IN (sqlc.slice('outbox_ids'))
+  AND status = 'pending'
+  AND (leased_until IS NULL OR leased_until <= CURRENT_TIMESTAMP)
+RETURNING *;
+
+-- name: MarkOutboxItemSent :execrows
+UPDATE outbox
+SET status = 'sent',
+    sent_at = ?,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND lease_token = ?
+  AND status != 'cancelled';
+
+-- name: DeferOutboxItem :execrows
+-- Holds an item back until the recipient's quiet hours end
+UPDATE outbox
+SET send_at = ?,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND lease_token = ?;
+
+-- name: SuppressOutboxItem :execrows
+-- Drops an item the recipient has opted out of
+UPDATE outbox
+SET status = 'suppressed',
+    last_error = ?,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND lease_token = ?;
+
+-- name: CancelShiftReminders :execrows
+-- Cancels the reminders still queued for a booking that has been cancelled
+UPDATE outbox
+SET status = 'cancelled'
+WHERE category = 'reminders'
+  AND status IN ('pending', 'failed')
+  AND user_id = ?
+  AND json_extract(payload, '$.data.booking_id') = CAST(sqlc.arg('booking_id') AS INTEGER);
@@ -0,0 +1,62 @@
+-- name: StartPatrolLocationSharing :one
+INSERT INTO patrol_location_sharing (booking_id, user_id)
+VALUES (?, ?)
+ON CONFLICT(booking_id) DO UPDATE SET started_at = CURRENT_TIMESTAMP, stopped_at = NULL, stop_reason = NULL
+RETURNING booking_id, user_id, started_at, stopped_at, stop_reason;
+
+-- name: GetPatrolLocationSharing :one
+SELECT booking_id, user_id, started_at, stopped_at, stop_reason
+FROM patrol_location_sharing
+WHERE booking_id = ?;
+
+-- name: StopPatrolLocationSharing :execrows
+UPDATE patrol_location_sharing
+SET stopped_at = ?, stop_reason = ?
+WHERE booking_id = ? AND stopped_at IS NULL;
+
+-- name: StopEndedPatrolLocationSharing :execrows
+UPDATE patrol_location_sharing
+SET stopped_at = ?, stop_reason = 'shift_end'
+WHERE stopped_at IS NULL
+  AND booking_id IN (SELECT b.booking_id FROM bookings b WHERE b.shift_end <= ?);
+
+-- name: CreatePatrolLocation :exec
+INSERT INTO patrol_locations (booking_id, user_id, latitude, longitude, accuracy, recorded_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ListPatrolLocationsByBooking :many
+SELECT location_id, booking_id, user_id, latitude, longitude, accuracy, recorded_at, created_at
+FROM patrol_locations
+WHERE booking_id = ?
+ORDER BY recorded_at ASC, location_id ASC;
+
+-- name: ListLivePatrolPositions :many
+SELECT
+    b.booking_id,
+    b.user_id,
+    COALESCE(u.name, '') AS user_name,
+    s.name AS schedule_name,
+    b.shift_end,
+    pl.latitude,
+    pl.longitude,
+    pl.accuracy,
+    pl.recorded_at
+FROM patrol_location_sharing pls
+JOIN bookings b ON pls.booking_id = b.booking_id
+JOIN users u ON b.user_id = u.user_id
+JOIN schedules s ON b.schedule_id = s.schedule_id
+JOIN patrol_locations pl ON pl.location_id = (
+    SELECT latest.location_id FROM patrol_locations latest
+    WHERE latest.booking_id = b.booking_id
+    ORDER BY latest.recorded_at DESC, latest.location_id DESC
+    LIMIT 1
+)
+WHERE pls.stopped_at IS NULL
+  AND b.checked_in_at IS NOT NULL
+  AND b.checked_out_at IS NULL
+  AND b.shift_end > ?
+ORDER BY user_name ASC;
+
+-- name: DeletePatrolLocationsBefore :execrows
+DELETE FROM patrol_locations
+WHERE recorded_at < ?;
@@ -159,4 +159,8 @@ SELECT
 FROM bookings b
 WHERE b.user_id = ?
     AND b.checked_in_at IS NOT NULL
-    AND strftime('%Y-%m', b.shift_start) = ?; 
\ No newline at end of file
+    AND strftime('%Y-%m', b.shift_start) = ?; 
+-- name: CountUserBookingPointsByReason :one
+-- Count how many times a user was awarded points for a booking with a given reason
+SELECT COUNT(*) FROM points_history
+WHERE user_id = ? AND booking_id = ? AND reason = ?;
@@ -1,17 +1,54 @@
 -- name: UpsertSubscription :exec
-INSERT INTO push_subscriptions (user_id, endpoint, p256dh_key, auth_key, user_agent, platform)
-VALUES (?, ?, ?, ?, ?, ?)
+INSERT INTO push_subscriptions (user_id, endpoint, p256dh_key, auth_key, user_agent, platform, device_label)
+VALUES (?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(endpoint) DO UPDATE
-SET p256dh_key = excluded.p256dh_key,
-    auth_key   = excluded.auth_key,
-    user_agent = excluded.user_agent,
-    platform   = excluded.platform;
+SET p256dh_key   = excluded.p256dh_key,
+    auth_key     = excluded.auth_key,
+    user_agent   = excluded.user_agent,
+    platform     = excluded.platform,
+    device_label = excluded.device_label,
+    consecutive_failures = 0;
 
 -- name: DeleteSubscription :exec
 DELETE FROM push_subscriptions WHERE endpoint = ? AND user_id = ?;
 
 -- name: GetSubscriptionsByUser :many
-SELECT endpoint, p256dh_key, auth_key FROM push_subscriptions WHERE user_id = ?;
+SELECT id, endpoint, p256dh_key, auth_key FROM push_subscriptions WHERE user_id = ?;
 
 -- name: GetAllSubscriptions :many
-SELECT user_id, endpoint, p256dh_key, auth_key FROM push_subscriptions; 
\ No newline at end of file
+SELECT user_id, endpoint, p256dh_key, auth_key FROM push_subscriptions;
+
+-- name: ListUserPushSubscriptions :many
+SELECT id, user_id, endpoint, p256dh_key, auth_key, user_agent, platform, created_at,
+       device_label, success_count, failure_count, consecutive_failures,
+       last_success_at, last_failure_at, last_error
+FROM push_subscriptions
+WHERE user_id = ?
+ORDER BY created_at DESC, id DESC;
+
+-- name: GetUserPushSubscription :one
+SELECT id, user_id, endpoint, p256dh_key, auth_key, user_agent, platform, created_at,
+       device_label, success_count, failure_count, consecutive_failures,
+       last_success_at, last_failure_at, last_error
+FROM push_subscriptions
+WHERE id = ? AND user_id = ?;
+
+-- name: RecordPushSuccess :exec
+UPDATE push_subscriptions
+SET success_count = success_count + 1,
+    consecutive_failures = 0,
+    last_success_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: RecordPushFailure :one
+-- Returns what the sender needs to decide whether to prune the subscription
+UPDATE push_subscriptions
+SET failure_count = failure_count + 1,
+    consecutive_failures = consecutive_failures + 1,
+    last_failure_at = CURRENT_TIMESTAMP,
+    last_error = ?
+WHERE id = ?
+RETURNING consecutive_failures, last_success_at, created_at;
+
+-- name: DeletePushSubscriptionByID :exec
+DELETE FROM push_subscriptions WHERE id = ?;
@@ -0,0 +1,44 @@
+-- Off-shift report rate limiting
+
+-- name: CreateOffShiftReportSubmission :exec
+INSERT INTO off_shift_report_submissions (user_id, client_ip, report_id, submitted_at)
+VALUES (?, ?, ?, ?);
+
+-- name: CountOffShiftReportSubmissionsByUser :one
+SELECT COUNT(*) FROM off_shift_report_submissions
+WHERE user_id = ? AND submitted_at >= ?;
+
+-- name: CountOffShiftReportSubmissionsByIP :one
+SELECT COUNT(*) FROM off_shift_report_submissions
+WHERE client_ip = ? AND submitted_at >= ?;
+
+-- name: CleanupOldOffShiftReportSubmissions :execrows
+DELETE FROM off_shift_report_submissions
+WHERE submitted_at < ?;
+
+-- Report flags
+
+-- name: UpsertReportFlag :one
+INSERT INTO report_flags (report_id, reporter_user_id, flag, note, flagged_by_user_id, flagged_at)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT(report_id) DO UPDATE SET
+    flag = excluded.flag,
+    note = excluded.note,
+    flagged_by_user_id = excluded.flagged_by_user_id,
+    flagged_at = excluded.flagged_at
+RETURNING *;
+
+-- name: GetReportFlag :one
+SELECT * FROM report_flags
+WHERE report_id = ?;
+
+-- name: DeleteReportFlag :execrows
+DELETE FROM report_flags
+WHERE report_id = ?;
+
+-- name: GetReporterFlagCounts :one
+SELECT
+    CAST(COALESCE(SUM(CASE WHEN flag = 'spam' THEN 1 ELSE 0 END), 0) AS INTEGER) AS spam_count,
+    CAST(COALESCE(SUM(CASE WHEN flag = 'false_alarm' THEN 1 ELSE 0 END), 0) AS INTEGER) AS false_alarm_count
+FROM report_flags
+WHERE reporter_user_id = ? AND flagged_at >= ?;
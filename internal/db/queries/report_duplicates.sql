@@ -0,0 +1,87 @@
+-- Duplicate detection
+
+-- name: ListReportDuplicateCandidates :many
+-- Earlier active reports filed since the given time that have not been merged
+SELECT
+    r.report_id,
+    r.user_id,
+    r.severity,
+    r.message,
+    r.latitude,
+    r.longitude,
+    r.created_at,
+    d.duplicate_of_report_id
+FROM reports r
+LEFT JOIN report_duplicate_matches d ON d.report_id = r.report_id
+WHERE r.report_id < sqlc.arg(report_id)
+  AND r.created_at >= sqlc.arg(since)
+  AND r.archived_at IS NULL
+  AND NOT EXISTS (SELECT 1 FROM report_merges m WHERE m.report_id = r.report_id)
+ORDER BY r.created_at DESC, r.report_id DESC;
+
+-- name: CreateReportDuplicateMatch :exec
+INSERT INTO report_duplicate_matches (report_id, duplicate_of_report_id, score, distance_meters)
+VALUES (?, ?, ?, ?);
+
+-- name: GetReportDuplicateMatch :one
+SELECT * FROM report_duplicate_matches
+WHERE report_id = ?;
+
+-- name: ListSuspectedDuplicatesOfReport :many
+-- Unmerged reports detected as likely duplicates of the given report
+SELECT
+    d.report_id,
+    d.score,
+    d.distance_meters,
+    d.detected_at,
+    r.user_id,
+    COALESCE(u.name, '') AS user_name,
+    r.severity,
+    r.message,
+    r.created_at
+FROM report_duplicate_matches d
+JOIN reports r ON d.report_id = r.report_id
+LEFT JOIN users u ON r.user_id = u.user_id
+WHERE d.duplicate_of_report_id = ?
+  AND NOT EXISTS (SELECT 1 FROM report_merges m WHERE m.report_id = d.report_id)
+ORDER BY r.created_at, d.report_id;
+
+-- Merging
+
+-- name: CreateReportMerge :exec
+INSERT INTO report_merges (report_id, primary_report_id, merged_by_user_id)
+VALUES (?, ?, ?);
+
+-- name: GetReportMerge :one
+SELECT * FROM report_merges
+WHERE report_id = ?;
+
+-- name: ReassignReportMerges :exec
+-- Points reports merged into a report that is itself being merged at the new primary
+UPDATE report_merges
+SET primary_report_id = sqlc.arg(primary_report_id)
+WHERE primary_report_id = sqlc.arg(previous_primary_report_id);
+
+-- name: ListMergedReports :many
+SELECT
+    m.report_id,
+    m.merged_by_user_id,
+    m.merged_at,
+    r.booking_id,
+    r.user_id,
+    COALESCE(u.name, '') AS user_name,
+    r.severity,
+    r.message,
+    r.latitude,
+    r.longitude,
+    r.created_at
+FROM report_merges m
+JOIN reports r ON m.report_id = r.report_id
+LEFT JOIN users u ON r.user_id = u.user_id
+WHERE m.primary_report_id = ?
+ORDER BY r.created_at, m.report_id;
+
+-- name: MoveReportPhotos :execrows
+UPDATE report_photos
+SET report_id = sqlc.arg(to_report_id)
+WHERE report_id = sqlc.arg(from_report_id);
@@ -0,0 +1,24 @@
+-- name: ListReportRetentionPolicies :many
+SELECT policy_id, severity, category_id, archive_after_days, purge_after_days, purge_action, created_at, updated_at
+FROM report_retention_policies
+ORDER BY severity IS NULL, severity ASC, category_id IS NULL, category_id ASC;
+
+-- name: GetReportRetentionPolicy :one
+SELECT policy_id, severity, category_id, archive_after_days, purge_after_days, purge_action, created_at, updated_at
+FROM report_retention_policies
+WHERE policy_id = ?;
+
+-- name: CreateReportRetentionPolicy :one
+INSERT INTO report_retention_policies (severity, category_id, archive_after_days, purge_after_days, purge_action)
+VALUES (?, ?, ?, ?, ?)
+RETURNING policy_id, severity, category_id, archive_after_days, purge_after_days, purge_action, created_at, updated_at;
+
+-- name: UpdateReportRetentionPolicy :one
+UPDATE report_retention_policies
+SET archive_after_days = ?, purge_after_days = ?, purge_action = ?, updated_at = CURRENT_TIMESTAMP
+WHERE policy_id = ?
+RETURNING policy_id, severity, category_id, archive_after_days, purge_after_days, purge_action, created_at, updated_at;
+
+-- name: DeleteReportRetentionPolicy :exec
+DELETE FROM report_retention_policies
+WHERE policy_id = ?;
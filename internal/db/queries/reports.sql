@@ -8,6 +8,8 @@ INSERT INTO reports (
     longitude,
     gps_accuracy,
     gps_timestamp,
+    category_id,
+    category_fields,
     archived_at
 ) VALUES (
     ?,
@@ -18,6 +20,8 @@ INSERT INTO reports (
     ?,
     ?,
     ?,
+    ?,
+    ?,
     NULL
 )
 RETURNING *;
@@ -31,6 +35,8 @@ INSERT INTO reports (
     longitude,
     gps_accuracy,
     gps_timestamp,
+    category_id,
+    category_fields,
     archived_at
 ) VALUES (
     ?,
@@ -40,6 +46,8 @@ INSERT INTO reports (
     ?,
     ?,
     ?,
+    ?,
+    ?,
     NULL
 )
 RETURNING *;
@@ -67,6 +75,9 @@ SELECT
     r.longitude,
     r.gps_accuracy,
     r.gps_timestamp,
+    r.category_id,
+    r.category_fields,
+    COALESCE(ic.name, '') as category_name,
     COALESCE(u.name, '') as user_name,
     u.phone as user_phone,
     COALESCE(b.schedule_id, 0) as schedule_id,
@@ -77,6 +88,7 @@ FROM reports r
 JOIN users u ON r.user_id = u.user_id
 LEFT JOIN bookings b ON r.booking_id = b.booking_id
 LEFT JOIN schedules s ON b.schedule_id = s.schedule_id
+LEFT JOIN incident_categories ic ON r.category_id = ic.category_id
 WHERE r.report_id = ?;
 
 -- name: AdminListReportsWithContext :many
@@ -92,6 +104,9 @@ SELECT
     r.longitude,
     r.gps_accuracy,
     r.gps_timestamp,
+    r.category_id,
+    r.category_fields,
+    COALESCE(ic.name, '') as category_name,
     COALESCE(u.name, '') as user_name,
     u.phone as user_phone,
     COALESCE(b.schedule_id, 0) as schedule_id,
@@ -102,6 +117,7 @@ FROM reports r
 JOIN users u ON r.user_id = u.user_id
 LEFT JOIN bookings b ON r.booking_id = b.booking_id
 LEFT JOIN schedules s ON b.schedule_id = s.schedule_id
+LEFT JOIN incident_categories ic ON r.category_id = ic.category_id
 WHERE r.archived_at IS NULL
 ORDER BY r.created_at DESC;
 
@@ -128,6 +144,9 @@ SELECT
     r.longitude,
     r.gps_accuracy,
     r.gps_timestamp,
+    r.category_id,
+    r.category_fields,
+    COALESCE(ic.name, '') as category_name,
     COALESCE(u.name, '') as user_name,
     u.phone as user_phone,
     COALESCE(b.schedule_id, 0) as schedule_id,
@@ -138,21 +157,15 @@ FROM reports r
 JOIN users u ON r.user_id = u.user_id
 LEFT JOIN bookings b ON r.booking_id = b.booking_id
 LEFT JOIN schedules s ON b.schedule_id = s.schedule_id
+LEFT JOIN incident_categories ic ON r.category_id = ic.category_id
 WHERE r.archived_at IS NOT NULL
 ORDER BY r.archived_at DESC;
 
--- name: GetReportsForAutoArchiving :many
-SELECT report_id, severity, created_at
-FROM reports 
-WHERE archived_at IS NULL 
-AND (
-    -- Normal reports older than 1 month
-    (severity = 0 AND created_at < datetime('now', '-1 month'))
-    OR
-    -- Suspicion reports older than 1 year  
-    (severity = 1 AND created_at < datetime('now', '-1 year'))
-    -- Incident reports (severity = 2) are never auto-archived
-);
+-- name: ListReportsForRetention :many
+SELECT report_id, severity, category_id, created_at, archived_at
+FROM reports
+WHERE anonymised_at IS NULL
+ORDER BY report_id ASC;
 
 -- name: BulkArchiveReports :exec
 UPDATE reports 
@@ -200,4 +213,20 @@ WHERE photo_id = ? AND report_id = ?;
 
 -- name: DeleteReportPhoto :exec
 DELETE FROM report_photos 
-WHERE photo_id = ? AND report_id = ?; 
\ No newline at end of file
+WHERE photo_id = ? AND report_id = ?; 
+
+-- name: AnonymiseReport :exec
+UPDATE reports
+SET message = NULL,
+    latitude = NULL,
+    longitude = NULL,
+    gps_accuracy = NULL,
+    gps_timestamp = NULL,
+    category_fields = NULL,
+    photo_count = 0,
+    anonymised_at = CURRENT_TIMESTAMP
+WHERE report_id = ? AND anonymised_at IS NULL;
+
+-- name: DeleteReportPhotosByReportID :exec
+DELETE FROM report_photos
+WHERE report_id = ?;
@@ -0,0 +1,74 @@
+-- name: CreateSOSAlert :one
+INSERT INTO sos_alerts (user_id, booking_id, report_id, latitude, longitude, accuracy)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- SetSOSAlertReport links an alert to the report filed for it.
+-- name: SetSOSAlertReport :one
+UPDATE sos_alerts
+SET report_id = ?
+WHERE alert_id = ?
+RETURNING *;
+
+-- DeleteSOSAlert removes an alert whose report could not be filed.
+-- name: DeleteSOSAlert :exec
+DELETE FROM sos_alerts
+WHERE alert_id = ?;
+
+-- name: GetSOSAlertByID :one
+SELECT * FROM sos_alerts
+WHERE alert_id = ?;
+
+-- name: GetActiveSOSAlertByUser :one
+SELECT * FROM sos_alerts
+WHERE user_id = ? AND status = 'active'
+ORDER BY created_at DESC, alert_id DESC
+LIMIT 1;
+
+-- name: ListSOSAlerts :many
+SELECT
+    a.alert_id,
+    a.user_id,
+    COALESCE(u.name, '') AS user_name,
+    u.phone AS user_phone,
+    a.booking_id,
+    a.report_id,
+    a.latitude,
+    a.longitude,
+    a.accuracy,
+    a.status,
+    a.stood_down_by_user_id,
+    a.stood_down_at,
+    a.stand_down_note,
+    a.created_at,
+    (SELECT COUNT(*) FROM sos_acknowledgements ack WHERE ack.alert_id = a.alert_id) AS acknowledgement_count
+FROM sos_alerts a
+JOIN users u ON a.user_id = u.user_id
+WHERE (sqlc.narg('status') IS NULL OR a.status = sqlc.narg('status'))
+ORDER BY a.created_at DESC, a.alert_id DESC
+LIMIT ?;
+
+-- name: StandDownSOSAlert :one
+UPDATE sos_alerts
+SET status = 'stood_down',
+    stood_down_by_user_id = ?,
+    stood_down_at = CURRENT_TIMESTAMP,
+    stand_down_note = ?
+WHERE alert_id = ? AND status = 'active'
+RETURNING *;
+
+-- name: CreateSOSAcknowledgement :execrows
+INSERT INTO sos_acknowledgements (alert_id, user_id)
+VALUES (?, ?)
+ON CONFLICT(alert_id, user_id) DO NOTHING;
+
+-- name: ListSOSAcknowledgements :many
+SELECT
+    ack.alert_id,
+    ack.user_id,
+    COALESCE(u.name, '') AS user_name,
+    ack.acknowledged_at
+FROM sos_acknowledgements ack
+JOIN users u ON ack.user_id = u.user_id
+WHERE ack.alert_id = ?
+ORDER BY ack.acknowledged_at ASC, ack.user_id ASC;
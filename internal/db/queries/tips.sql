@@ -0,0 +1,45 @@
+-- Anonymous tips
+
+-- name: CreateTip :one
+INSERT INTO tips (message, severity, latitude, longitude, contact)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetTip :one
+SELECT * FROM tips
+WHERE tip_id = ?;
+
+-- name: ListTipsByStatus :many
+SELECT * FROM tips
+WHERE status = ?
+ORDER BY created_at DESC, tip_id DESC
+LIMIT ? OFFSET ?;
+
+-- name: ReviewTip :one
+-- Only pending tips can be reviewed, so concurrent reviews cannot both succeed
+UPDATE tips
+SET status = ?,
+    report_id = ?,
+    review_note = ?,
+    reviewed_by_user_id = ?,
+    reviewed_at = ?
+WHERE tip_id = ? AND status = 'pending'
+RETURNING *;
+
+-- Tip rate limiting
+
+-- name: CreateTipSubmission :exec
+INSERT INTO tip_submissions (client_hash, challenge_hash, submitted_at)
+VALUES (?, ?, ?);
+
+-- name: CountTipSubmissionsByClient :one
+SELECT COUNT(*) FROM tip_submissions
+WHERE client_hash = ? AND submitted_at >= ?;
+
+-- name: CountTipSubmissions :one
+SELECT COUNT(*) FROM tip_submissions
+WHERE submitted_at >= ?;
+
+-- name: CleanupOldTipSubmissions :execrows
+DELETE FROM tip_submissions
+WHERE submitted_at < ?;
@@ -18,6 +18,10 @@ WHERE phone = ?;
 SELECT user_id, phone, name, created_at, role FROM users
 WHERE user_id = ?;
 
+-- name: GetUserNotificationTarget :one
+SELECT user_id, phone, name, preferred_channel, email FROM users
+WHERE user_id = ?;
+
 -- name: CountUsers :one
 SELECT COUNT(*) FROM users;
 
@@ -42,4 +46,49 @@ SET
     role = COALESCE(sqlc.narg('role'), role)
 WHERE
     user_id = sqlc.arg('user_id')
-RETURNING user_id, phone, name, created_at, role; 
\ No newline at end of file
+RETURNING user_id, phone, name, created_at, role; ;
+
+-- name: GetUserEmail :one
+SELECT user_id, email, email_verified_at, pending_email, email_verification_code_hash, email_verification_expires_at, email_verification_attempts FROM users
+WHERE user_id = ?;
+
+-- name: SetUserPendingEmail :exec
+UPDATE users
+SET pending_email = ?,
+    email_verification_code_hash = ?,
+    email_verification_expires_at = ?,
+    email_verification_attempts = 0
+WHERE user_id = ?;
+
+-- name: ConfirmUserEmail :execrows
+-- Promotes the pending address once its code has been checked
+UPDATE users
+SET email = pending_email,
+    email_verified_at = CURRENT_TIMESTAMP,
+    pending_email = NULL,
+    email_verification_code_hash = NULL,
+    email_verification_expires_at = NULL,
+    email_verification_attempts = 0
+WHERE user_id = ?
+  AND pending_email IS NOT NULL;
+
+-- name: RecordEmailVerificationFailure :one
+UPDATE users
+SET email_verification_attempts = email_verification_attempts + 1
+WHERE user_id = ?
+RETURNING email_verification_attempts;
+
+-- name: ClearUserEmail :exec
+UPDATE users
+SET email = NULL,
+    email_verified_at = NULL,
+    pending_email = NULL,
+    email_verification_code_hash = NULL,
+    email_verification_expires_at = NULL,
+    email_verification_attempts = 0
+WHERE user_id = ?;
+
+-- name: SetUserPreferredChannel :exec
+UPDATE users
+SET preferred_channel = ?
+WHERE user_id = ?;
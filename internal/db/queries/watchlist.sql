@@ -0,0 +1,96 @@
+-- Watchlist entries
+
+-- name: CreateWatchlistEntry :one
+INSERT INTO watchlist_entries (kind, label, registration, description, notes, keywords, is_active, created_by_user_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetWatchlistEntry :one
+SELECT * FROM watchlist_entries
+WHERE entry_id = ?;
+
+-- name: ListWatchlistEntries :many
+SELECT
+    e.entry_id,
+    e.kind,
+    e.label,
+    e.registration,
+    e.description,
+    e.notes,
+    e.keywords,
+    e.is_active,
+    e.created_by_user_id,
+    e.created_at,
+    e.updated_at,
+    (SELECT COUNT(*) FROM watchlist_matches m WHERE m.entry_id = e.entry_id) AS match_count,
+    (SELECT COUNT(*) FROM watchlist_photos p WHERE p.entry_id = e.entry_id) AS photo_count
+FROM watchlist_entries e
+ORDER BY e.is_active DESC, e.updated_at DESC, e.entry_id DESC;
+
+-- name: ListActiveWatchlistEntries :many
+SELECT * FROM watchlist_entries
+WHERE is_active = 1
+ORDER BY entry_id;
+
+-- name: UpdateWatchlistEntry :one
+UPDATE watchlist_entries
+SET kind = ?,
+    label = ?,
+    registration = ?,
+    description = ?,
+    notes = ?,
+    keywords = ?,
+    is_active = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE entry_id = ?
+RETURNING *;
+
+-- name: DeleteWatchlistEntry :execrows
+DELETE FROM watchlist_entries
+WHERE entry_id = ?;
+
+-- Watchlist photos
+
+-- name: CreateWatchlistPhoto :one
+INSERT INTO watchlist_photos (entry_id, filename, mime_type, file_size_bytes, storage_path, uploaded_by_user_id)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetWatchlistPhoto :one
+SELECT * FROM watchlist_photos
+WHERE photo_id = ? AND entry_id = ?;
+
+-- name: ListWatchlistPhotos :many
+SELECT * FROM watchlist_photos
+WHERE entry_id = ?
+ORDER BY created_at, photo_id;
+
+-- name: DeleteWatchlistPhoto :execrows
+DELETE FROM watchlist_photos
+WHERE photo_id = ? AND entry_id = ?;
+
+-- Watchlist matches
+
+-- name: CreateWatchlistMatch :execrows
+-- A report is linked to an entry once, however many times it mentions it
+INSERT OR IGNORE INTO watchlist_matches (entry_id, report_id, matched_on, matched_term)
+VALUES (?, ?, ?, ?);
+
+-- name: ListWatchlistMatchesByEntry :many
+SELECT
+    m.report_id,
+    m.matched_on,
+    m.matched_term,
+    m.matched_at,
+    r.severity,
+    r.message,
+    r.latitude,
+    r.longitude,
+    r.created_at,
+    r.archived_at,
+    COALESCE(u.name, '') AS reporter_name
+FROM watchlist_matches m
+JOIN reports r ON r.report_id = m.report_id
+LEFT JOIN users u ON u.user_id = r.user_id
+WHERE m.entry_id = ?
+ORDER BY r.created_at DESC, m.report_id DESC;
@@ -384,6 +384,73 @@ func (q *Queries) ListAuditEventsByDateRange(ctx context.Context, arg ListAuditE
 	return items, nil
 }
 
+const listAuditEventsByEntity = `-- name: ListAuditEventsByEntity :many
+SELECT 
+    ae.event_id, ae.event_type, ae.actor_user_id, ae.target_user_id, ae.entity_type, ae.entity_id, ae."action", ae.details, ae.ip_address, ae.user_agent, ae.created_at,
+    COALESCE(actor.name, '') as actor_name
+FROM audit_events ae
+LEFT JOIN users actor ON ae.actor_user_id = actor.user_id
+WHERE ae.entity_type = ? AND ae.entity_id = ?
+ORDER BY ae.created_at ASC, ae.event_id ASC
+`
+
+type ListAuditEventsByEntityParams struct {
+	EntityType string        `json:"entity_type"`
+	EntityID   sql.NullInt64 `json:"entity_id"`
+}
+
+type ListAuditEventsByEntityRow struct {
+	EventID      int64          `json:"event_id"`
+	EventType    string         `json:"event_type"`
+	ActorUserID  sql.NullInt64  `json:"actor_user_id"`
+	TargetUserID sql.NullInt64  `json:"target_user_id"`
+	EntityType   string         `json:"entity_type"`
+	EntityID     sql.NullInt64  `json:"entity_id"`
+	Action       string         `json:"action"`
+	Details      sql.NullString `json:"details"`
+	IpAddress    sql.NullString `json:"ip_address"`
+	UserAgent    sql.NullString `json:"user_agent"`
+	CreatedAt    sql.NullTime   `json:"created_at"`
+	ActorName    string         `json:"actor_name"`
+}
+
+// Full history of a single record, oldest first
+func (q *Queries) ListAuditEventsByEntity(ctx context.Context, arg ListAuditEventsByEntityParams) ([]ListAuditEventsByEntityRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEventsByEntity, arg.EntityType, arg.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAuditEventsByEntityRow{}
+	for rows.Next() {
+		var i ListAuditEventsByEntityRow
+		if err := rows.Scan(
+			&i.EventID,
+			&i.EventType,
+			&i.ActorUserID,
+			&i.TargetUserID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Action,
+			&i.Details,
+			&i.IpAddress,
+			&i.UserAgent,
+			&i.CreatedAt,
+			&i.ActorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAuditEventsByTarget = `-- name: ListAuditEventsByTarget :many
 SELECT 
     ae.event_id, ae.event_type, ae.actor_user_id, ae.target_user_id, ae.entity_type, ae.entity_id, ae."action", ae.details, ae.ip_address, ae.user_agent, ae.created_at,
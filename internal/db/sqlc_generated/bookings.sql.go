@@ -27,7 +27,7 @@ INSERT INTO bookings (
     ?,
     ?
 )
-RETURNING booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at
+RETURNING booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at, checked_out_at
 `
 
 type CreateBookingParams struct {
@@ -59,6 +59,7 @@ func (q *Queries) CreateBooking(ctx context.Context, arg CreateBookingParams) (B
 		&i.BuddyName,
 		&i.CheckedInAt,
 		&i.CreatedAt,
+		&i.CheckedOutAt,
 	)
 	return i, err
 }
@@ -74,7 +75,7 @@ func (q *Queries) DeleteBooking(ctx context.Context, bookingID int64) error {
 }
 
 const getBookingByID = `-- name: GetBookingByID :one
-SELECT booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at FROM bookings
+SELECT booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at, checked_out_at FROM bookings
 WHERE booking_id = ?
 `
 
@@ -91,12 +92,13 @@ func (q *Queries) GetBookingByID(ctx context.Context, bookingID int64) (Booking,
 		&i.BuddyName,
 		&i.CheckedInAt,
 		&i.CreatedAt,
+		&i.CheckedOutAt,
 	)
 	return i, err
 }
 
 const getBookingByScheduleAndStartTime = `-- name: GetBookingByScheduleAndStartTime :one
-SELECT booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at FROM bookings
+SELECT booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at, checked_out_at FROM bookings
 WHERE schedule_id = ? AND shift_start = ?
 `
 
@@ -118,6 +120,7 @@ func (q *Queries) GetBookingByScheduleAndStartTime(ctx context.Context, arg GetB
 		&i.BuddyName,
 		&i.CheckedInAt,
 		&i.CreatedAt,
+		&i.CheckedOutAt,
 	)
 	return i, err
 }
@@ -384,8 +387,34 @@ func (q *Queries) GetMemberContributions(ctx context.Context) ([]GetMemberContri
 	return items, nil
 }
 
+const getNextBookingForUser = `-- name: GetNextBookingForUser :one
+SELECT b.shift_start, s.name AS schedule_name
+FROM bookings b
+JOIN schedules s ON b.schedule_id = s.schedule_id
+WHERE b.user_id = ? AND b.shift_start > ?
+ORDER BY b.shift_start
+LIMIT 1
+`
+
+type GetNextBookingForUserParams struct {
+	UserID     int64     `json:"user_id"`
+	ShiftStart time.Time `json:"shift_start"`
+}
+
+type GetNextBookingForUserRow struct {
+	ShiftStart   time.Time `json:"shift_start"`
+	ScheduleName string    `json:"schedule_name"`
+}
+
+func (q *Queries) GetNextBookingForUser(ctx context.Context, arg GetNextBookingForUserParams) (GetNextBookingForUserRow, error) {
+	row := q.db.QueryRowContext(ctx, getNextBookingForUser, arg.UserID, arg.ShiftStart)
+	var i GetNextBookingForUserRow
+	err := row.Scan(&i.ShiftStart, &i.ScheduleName)
+	return i, err
+}
+
 const listBookingsByUserID = `-- name: ListBookingsByUserID :many
-SELECT booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at FROM bookings
+SELECT booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at, checked_out_at FROM bookings
 WHERE user_id = ?
 ORDER BY shift_start DESC
 `
@@ -409,6 +438,7 @@ func (q *Queries) ListBookingsByUserID(ctx context.Context, userID int64) ([]Boo
 			&i.BuddyName,
 			&i.CheckedInAt,
 			&i.CreatedAt,
+			&i.CheckedOutAt,
 		); err != nil {
 			return nil, err
 		}
@@ -488,11 +518,56 @@ func (q *Queries) ListBookingsByUserIDWithSchedule(ctx context.Context, userID i
 	return items, nil
 }
 
+const listOnDutyBookings = `-- name: ListOnDutyBookings :many
+SELECT booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at, checked_out_at FROM bookings
+WHERE shift_start <= ? AND shift_end > ?
+ORDER BY shift_start ASC
+`
+
+type ListOnDutyBookingsParams struct {
+	ShiftStart time.Time `json:"shift_start"`
+	ShiftEnd   time.Time `json:"shift_end"`
+}
+
+func (q *Queries) ListOnDutyBookings(ctx context.Context, arg ListOnDutyBookingsParams) ([]Booking, error) {
+	rows, err := q.db.QueryContext(ctx, listOnDutyBookings, arg.ShiftStart, arg.ShiftEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Booking{}
+	for rows.Next() {
+		var i Booking
+		if err := rows.Scan(
+			&i.BookingID,
+			&i.UserID,
+			&i.ScheduleID,
+			&i.ShiftStart,
+			&i.ShiftEnd,
+			&i.BuddyUserID,
+			&i.BuddyName,
+			&i.CheckedInAt,
+			&i.CreatedAt,
+			&i.CheckedOutAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateBookingCheckIn = `-- name: UpdateBookingCheckIn :one
 UPDATE bookings
 SET checked_in_at = ?
 WHERE booking_id = ?
-RETURNING booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at
+RETURNING booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at, checked_out_at
 `
 
 type UpdateBookingCheckInParams struct {
@@ -513,6 +588,37 @@ func (q *Queries) UpdateBookingCheckIn(ctx context.Context, arg UpdateBookingChe
 		&i.BuddyName,
 		&i.CheckedInAt,
 		&i.CreatedAt,
+		&i.CheckedOutAt,
+	)
+	return i, err
+}
+
+const updateBookingCheckOut = `-- name: UpdateBookingCheckOut :one
+UPDATE bookings
+SET checked_out_at = ?
+WHERE booking_id = ? AND checked_out_at IS NULL
+RETURNING booking_id, user_id, schedule_id, shift_start, shift_end, buddy_user_id, buddy_name, checked_in_at, created_at, checked_out_at
+`
+
+type UpdateBookingCheckOutParams struct {
+	CheckedOutAt sql.NullTime `json:"checked_out_at"`
+	BookingID    int64        `json:"booking_id"`
+}
+
+func (q *Queries) UpdateBookingCheckOut(ctx context.Context, arg UpdateBookingCheckOutParams) (Booking, error) {
+	row := q.db.QueryRowContext(ctx, updateBookingCheckOut, arg.CheckedOutAt, arg.BookingID)
+	var i Booking
+	err := row.Scan(
+		&i.BookingID,
+		&i.UserID,
+		&i.ScheduleID,
+		&i.ShiftStart,
+		&i.ShiftEnd,
+		&i.BuddyUserID,
+		&i.BuddyName,
+		&i.CheckedInAt,
+		&i.CreatedAt,
+		&i.CheckedOutAt,
 	)
 	return i, err
 }
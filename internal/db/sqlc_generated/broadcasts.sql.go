@@ -18,8 +18,18 @@ INSERT INTO broadcasts (
     sender_user_id,
     push_enabled,
     scheduled_at,
-    recipient_count
+    recipient_count,
+    urgent,
+    audience_filter,
+    status,
+    recurrence_cron,
+    parent_broadcast_id
 ) VALUES (
+    ?,
+    ?,
+    ?,
+    ?,
+    ?,
     ?,
     ?,
     ?,
@@ -28,17 +38,22 @@ INSERT INTO broadcasts (
     ?,
     ?
 )
-RETURNING broadcast_id, message, audience, sender_user_id, push_enabled, scheduled_at, sent_at, status, recipient_count, sent_count, failed_count, created_at, title
+RETURNING broadcast_id, message, audience, sender_user_id, push_enabled, scheduled_at, sent_at, status, recipient_count, sent_count, failed_count, created_at, title, urgent, audience_filter, recurrence_cron, parent_broadcast_id
 `
 
 type CreateBroadcastParams struct {
-	Title          string        `json:"title"`
-	Message        string        `json:"message"`
-	Audience       string        `json:"audience"`
-	SenderUserID   int64         `json:"sender_user_id"`
-	PushEnabled    bool          `json:"push_enabled"`
-	ScheduledAt    sql.NullTime  `json:"scheduled_at"`
-	RecipientCount sql.NullInt64 `json:"recipient_count"`
+	Title             string         `json:"title"`
+	Message           string         `json:"message"`
+	Audience          string         `json:"audience"`
+	SenderUserID      int64          `json:"sender_user_id"`
+	PushEnabled       bool           `json:"push_enabled"`
+	ScheduledAt       sql.NullTime   `json:"scheduled_at"`
+	RecipientCount    sql.NullInt64  `json:"recipient_count"`
+	Urgent            bool           `json:"urgent"`
+	AudienceFilter    sql.NullString `json:"audience_filter"`
+	Status            string         `json:"status"`
+	RecurrenceCron    sql.NullString `json:"recurrence_cron"`
+	ParentBroadcastID sql.NullInt64  `json:"parent_broadcast_id"`
 }
 
 func (q *Queries) CreateBroadcast(ctx context.Context, arg CreateBroadcastParams) (Broadcast, error) {
@@ -50,6 +65,11 @@ func (q *Queries) CreateBroadcast(ctx context.Context, arg CreateBroadcastParams
 		arg.PushEnabled,
 		arg.ScheduledAt,
 		arg.RecipientCount,
+		arg.Urgent,
+		arg.AudienceFilter,
+		arg.Status,
+		arg.RecurrenceCron,
+		arg.ParentBroadcastID,
 	)
 	var i Broadcast
 	err := row.Scan(
@@ -66,6 +86,10 @@ func (q *Queries) CreateBroadcast(ctx context.Context, arg CreateBroadcastParams
 		&i.FailedCount,
 		&i.CreatedAt,
 		&i.Title,
+		&i.Urgent,
+		&i.AudienceFilter,
+		&i.RecurrenceCron,
+		&i.ParentBroadcastID,
 	)
 	return i, err
 }
@@ -81,7 +105,7 @@ func (q *Queries) DeleteBroadcast(ctx context.Context, broadcastID int64) error
 }
 
 const getBroadcastByID = `-- name: GetBroadcastByID :one
-SELECT broadcast_id, message, audience, sender_user_id, push_enabled, scheduled_at, sent_at, status, recipient_count, sent_count, failed_count, created_at, title FROM broadcasts
+SELECT broadcast_id, message, audience, sender_user_id, push_enabled, scheduled_at, sent_at, status, recipient_count, sent_count, failed_count, created_at, title, urgent, audience_filter, recurrence_cron, parent_broadcast_id FROM broadcasts
 WHERE broadcast_id = ?
 `
 
@@ -102,12 +126,16 @@ func (q *Queries) GetBroadcastByID(ctx context.Context, broadcastID int64) (Broa
 		&i.FailedCount,
 		&i.CreatedAt,
 		&i.Title,
+		&i.Urgent,
+		&i.AudienceFilter,
+		&i.RecurrenceCron,
+		&i.ParentBroadcastID,
 	)
 	return i, err
 }
 
 const listBroadcasts = `-- name: ListBroadcasts :many
-SELECT broadcast_id, message, audience, sender_user_id, push_enabled, scheduled_at, sent_at, status, recipient_count, sent_count, failed_count, created_at, title FROM broadcasts
+SELECT broadcast_id, message, audience, sender_user_id, push_enabled, scheduled_at, sent_at, status, recipient_count, sent_count, failed_count, created_at, title, urgent, audience_filter, recurrence_cron, parent_broadcast_id FROM broadcasts
 ORDER BY created_at DESC
 `
 
@@ -134,6 +162,10 @@ func (q *Queries) ListBroadcasts(ctx context.Context) ([]Broadcast, error) {
 			&i.FailedCount,
 			&i.CreatedAt,
 			&i.Title,
+			&i.Urgent,
+			&i.AudienceFilter,
+			&i.RecurrenceCron,
+			&i.ParentBroadcastID,
 		); err != nil {
 			return nil, err
 		}
@@ -163,6 +195,10 @@ SELECT
     b.sent_count,
     b.failed_count,
     b.created_at,
+    b.urgent,
+    b.audience_filter,
+    b.recurrence_cron,
+    b.parent_broadcast_id,
     COALESCE(u.name, 'Unknown User') as sender_name
 FROM broadcasts b
 LEFT JOIN users u ON b.sender_user_id = u.user_id
@@ -170,20 +206,24 @@ ORDER BY b.created_at DESC
 `
 
 type ListBroadcastsWithSenderRow struct {
-	BroadcastID    int64         `json:"broadcast_id"`
-	Title          string        `json:"title"`
-	Message        string        `json:"message"`
-	Audience       string        `json:"audience"`
-	SenderUserID   int64         `json:"sender_user_id"`
-	PushEnabled    bool          `json:"push_enabled"`
-	ScheduledAt    sql.NullTime  `json:"scheduled_at"`
-	SentAt         sql.NullTime  `json:"sent_at"`
-	Status         string        `json:"status"`
-	RecipientCount sql.NullInt64 `json:"recipient_count"`
-	SentCount      sql.NullInt64 `json:"sent_count"`
-	FailedCount    sql.NullInt64 `json:"failed_count"`
-	CreatedAt      sql.NullTime  `json:"created_at"`
-	SenderName     string        `json:"sender_name"`
+	BroadcastID       int64          `json:"broadcast_id"`
+	Title             string         `json:"title"`
+	Message           string         `json:"message"`
+	Audience          string         `json:"audience"`
+	SenderUserID      int64          `json:"sender_user_id"`
+	PushEnabled       bool           `json:"push_enabled"`
+	ScheduledAt       sql.NullTime   `json:"scheduled_at"`
+	SentAt            sql.NullTime   `json:"sent_at"`
+	Status            string         `json:"status"`
+	RecipientCount    sql.NullInt64  `json:"recipient_count"`
+	SentCount         sql.NullInt64  `json:"sent_count"`
+	FailedCount       sql.NullInt64  `json:"failed_count"`
+	CreatedAt         sql.NullTime   `json:"created_at"`
+	Urgent            bool           `json:"urgent"`
+	AudienceFilter    sql.NullString `json:"audience_filter"`
+	RecurrenceCron    sql.NullString `json:"recurrence_cron"`
+	ParentBroadcastID sql.NullInt64  `json:"parent_broadcast_id"`
+	SenderName        string         `json:"sender_name"`
 }
 
 func (q *Queries) ListBroadcastsWithSender(ctx context.Context) ([]ListBroadcastsWithSenderRow, error) {
@@ -209,6 +249,10 @@ func (q *Queries) ListBroadcastsWithSender(ctx context.Context) ([]ListBroadcast
 			&i.SentCount,
 			&i.FailedCount,
 			&i.CreatedAt,
+			&i.Urgent,
+			&i.AudienceFilter,
+			&i.RecurrenceCron,
+			&i.ParentBroadcastID,
 			&i.SenderName,
 		); err != nil {
 			return nil, err
@@ -224,8 +268,56 @@ func (q *Queries) ListBroadcastsWithSender(ctx context.Context) ([]ListBroadcast
 	return items, nil
 }
 
+const listDueRecurringBroadcasts = `-- name: ListDueRecurringBroadcasts :many
+SELECT broadcast_id, message, audience, sender_user_id, push_enabled, scheduled_at, sent_at, status, recipient_count, sent_count, failed_count, created_at, title, urgent, audience_filter, recurrence_cron, parent_broadcast_id FROM broadcasts
+WHERE status = 'recurring'
+AND scheduled_at <= datetime('now')
+ORDER BY scheduled_at ASC
+`
+
+func (q *Queries) ListDueRecurringBroadcasts(ctx context.Context) ([]Broadcast, error) {
+	rows, err := q.db.QueryContext(ctx, listDueRecurringBroadcasts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Broadcast{}
+	for rows.Next() {
+		var i Broadcast
+		if err := rows.Scan(
+			&i.BroadcastID,
+			&i.Message,
+			&i.Audience,
+			&i.SenderUserID,
+			&i.PushEnabled,
+			&i.ScheduledAt,
+			&i.SentAt,
+			&i.Status,
+			&i.RecipientCount,
+			&i.SentCount,
+			&i.FailedCount,
+			&i.CreatedAt,
+			&i.Title,
+			&i.Urgent,
+			&i.AudienceFilter,
+			&i.RecurrenceCron,
+			&i.ParentBroadcastID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPendingBroadcasts = `-- name: ListPendingBroadcasts :many
-SELECT broadcast_id, message, audience, sender_user_id, push_enabled, scheduled_at, sent_at, status, recipient_count, sent_count, failed_count, created_at, title FROM broadcasts
+SELECT broadcast_id, message, audience, sender_user_id, push_enabled, scheduled_at, sent_at, status, recipient_count, sent_count, failed_count, created_at, title, urgent, audience_filter, recurrence_cron, parent_broadcast_id FROM broadcasts
 WHERE status = 'pending'
 AND (scheduled_at IS NULL OR scheduled_at <= datetime('now'))
 ORDER BY created_at ASC
@@ -254,6 +346,10 @@ func (q *Queries) ListPendingBroadcasts(ctx context.Context) ([]Broadcast, error
 			&i.FailedCount,
 			&i.CreatedAt,
 			&i.Title,
+			&i.Urgent,
+			&i.AudienceFilter,
+			&i.RecurrenceCron,
+			&i.ParentBroadcastID,
 		); err != nil {
 			return nil, err
 		}
@@ -268,6 +364,27 @@ func (q *Queries) ListPendingBroadcasts(ctx context.Context) ([]Broadcast, error
 	return items, nil
 }
 
+const updateBroadcastSchedule = `-- name: UpdateBroadcastSchedule :execrows
+UPDATE broadcasts
+SET scheduled_at = ?
+WHERE broadcast_id = ?
+AND status IN ('pending', 'recurring')
+`
+
+type UpdateBroadcastScheduleParams struct {
+	ScheduledAt sql.NullTime `json:"scheduled_at"`
+	BroadcastID int64        `json:"broadcast_id"`
+}
+
+// Only broadcasts still waiting to be sent can be rescheduled
+func (q *Queries) UpdateBroadcastSchedule(ctx context.Context, arg UpdateBroadcastScheduleParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateBroadcastSchedule, arg.ScheduledAt, arg.BroadcastID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateBroadcastStatus = `-- name: UpdateBroadcastStatus :one
 UPDATE broadcasts
 SET
@@ -277,7 +394,7 @@ SET
     failed_count = ?
 WHERE
     broadcast_id = ?
-RETURNING broadcast_id, message, audience, sender_user_id, push_enabled, scheduled_at, sent_at, status, recipient_count, sent_count, failed_count, created_at, title
+RETURNING broadcast_id, message, audience, sender_user_id, push_enabled, scheduled_at, sent_at, status, recipient_count, sent_count, failed_count, created_at, title, urgent, audience_filter, recurrence_cron, parent_broadcast_id
 `
 
 type UpdateBroadcastStatusParams struct {
@@ -311,6 +428,10 @@ func (q *Queries) UpdateBroadcastStatus(ctx context.Context, arg UpdateBroadcast
 		&i.FailedCount,
 		&i.CreatedAt,
 		&i.Title,
+		&i.Urgent,
+		&i.AudienceFilter,
+		&i.RecurrenceCron,
+		&i.ParentBroadcastID,
 	)
 	return i, err
 }
@@ -34,31 +34,70 @@ type AuditEvent struct {
 }
 
 type Booking struct {
-	BookingID   int64          `json:"booking_id"`
-	UserID      int64          `json:"user_id"`
-	ScheduleID  int64          `json:"schedule_id"`
-	ShiftStart  time.Time      `json:"shift_start"`
-	ShiftEnd    time.Time      `json:"shift_end"`
-	BuddyUserID sql.NullInt64  `json:"buddy_user_id"`
-	BuddyName   sql.NullString `json:"buddy_name"`
-	CheckedInAt sql.NullTime   `json:"checked_in_at"`
-	CreatedAt   sql.NullTime   `json:"created_at"`
+	BookingID    int64          `json:"booking_id"`
+	UserID       int64          `json:"user_id"`
+	ScheduleID   int64          `json:"schedule_id"`
+	ShiftStart   time.Time      `json:"shift_start"`
+	ShiftEnd     time.Time      `json:"shift_end"`
+	BuddyUserID  sql.NullInt64  `json:"buddy_user_id"`
+	BuddyName    sql.NullString `json:"buddy_name"`
+	CheckedInAt  sql.NullTime   `json:"checked_in_at"`
+	CreatedAt    sql.NullTime   `json:"created_at"`
+	CheckedOutAt sql.NullTime   `json:"checked_out_at"`
 }
 
 type Broadcast struct {
-	BroadcastID    int64         `json:"broadcast_id"`
-	Message        string        `json:"message"`
-	Audience       string        `json:"audience"`
-	SenderUserID   int64         `json:"sender_user_id"`
-	PushEnabled    bool          `json:"push_enabled"`
-	ScheduledAt    sql.NullTime  `json:"scheduled_at"`
-	SentAt         sql.NullTime  `json:"sent_at"`
-	Status         string        `json:"status"`
-	RecipientCount sql.NullInt64 `json:"recipient_count"`
-	SentCount      sql.NullInt64 `json:"sent_count"`
-	FailedCount    sql.NullInt64 `json:"failed_count"`
-	CreatedAt      sql.NullTime  `json:"created_at"`
-	Title          string        `json:"title"`
+	BroadcastID       int64          `json:"broadcast_id"`
+	Message           string         `json:"message"`
+	Audience          string         `json:"audience"`
+	SenderUserID      int64          `json:"sender_user_id"`
+	PushEnabled       bool           `json:"push_enabled"`
+	ScheduledAt       sql.NullTime   `json:"scheduled_at"`
+	SentAt            sql.NullTime   `json:"sent_at"`
+	Status            string         `json:"status"`
+	RecipientCount    sql.NullInt64  `json:"recipient_count"`
+	SentCount         sql.NullInt64  `json:"sent_count"`
+	FailedCount       sql.NullInt64  `json:"failed_count"`
+	CreatedAt         sql.NullTime   `json:"created_at"`
+	Title             string         `json:"title"`
+	Urgent            bool           `json:"urgent"`
+	AudienceFilter    sql.NullString `json:"audience_filter"`
+	RecurrenceCron    sql.NullString `json:"recurrence_cron"`
+	ParentBroadcastID sql.NullInt64  `json:"parent_broadcast_id"`
+}
+
+type BroadcastGroup struct {
+	GroupID     int64          `json:"group_id"`
+	Name        string         `json:"name"`
+	Description sql.NullString `json:"description"`
+	CreatedBy   sql.NullInt64  `json:"created_by"`
+	CreatedAt   sql.NullTime   `json:"created_at"`
+}
+
+type BroadcastGroupMember struct {
+	GroupID int64        `json:"group_id"`
+	UserID  int64        `json:"user_id"`
+	AddedAt sql.NullTime `json:"added_at"`
+}
+
+type BroadcastRecipient struct {
+	BroadcastID    int64          `json:"broadcast_id"`
+	UserID         int64          `json:"user_id"`
+	OutboxID       sql.NullInt64  `json:"outbox_id"`
+	ReadAt         sql.NullTime   `json:"read_at"`
+	ResendOutboxID sql.NullInt64  `json:"resend_outbox_id"`
+	ResentAt       sql.NullTime   `json:"resent_at"`
+	Message        sql.NullString `json:"message"`
+}
+
+type BroadcastTemplate struct {
+	TemplateID int64         `json:"template_id"`
+	Name       string        `json:"name"`
+	Title      string        `json:"title"`
+	Message    string        `json:"message"`
+	CreatedBy  sql.NullInt64 `json:"created_by"`
+	CreatedAt  sql.NullTime  `json:"created_at"`
+	UpdatedAt  sql.NullTime  `json:"updated_at"`
 }
 
 type CalendarToken struct {
@@ -84,6 +123,65 @@ type EmergencyContact struct {
 	UpdatedAt    sql.NullTime   `json:"updated_at"`
 }
 
+type HandoverNote struct {
+	HandoverID   int64        `json:"handover_id"`
+	BookingID    int64        `json:"booking_id"`
+	UserID       int64        `json:"user_id"`
+	OpenIssues   string       `json:"open_issues"`
+	VehiclesSeen string       `json:"vehicles_seen"`
+	GatesOpen    string       `json:"gates_open"`
+	Notes        string       `json:"notes"`
+	CreatedAt    sql.NullTime `json:"created_at"`
+	UpdatedAt    sql.NullTime `json:"updated_at"`
+}
+
+type IncidentCategory struct {
+	CategoryID   int64          `json:"category_id"`
+	Name         string         `json:"name"`
+	Description  sql.NullString `json:"description"`
+	Fields       string         `json:"fields"`
+	IsActive     bool           `json:"is_active"`
+	DisplayOrder int64          `json:"display_order"`
+	CreatedAt    sql.NullTime   `json:"created_at"`
+	UpdatedAt    sql.NullTime   `json:"updated_at"`
+}
+
+type IncidentEscalation struct {
+	EscalationID         int64         `json:"escalation_id"`
+	ReportID             int64         `json:"report_id"`
+	Status               string        `json:"status"`
+	EscalateAt           time.Time     `json:"escalate_at"`
+	AcknowledgedByUserID sql.NullInt64 `json:"acknowledged_by_user_id"`
+	AcknowledgedAt       sql.NullTime  `json:"acknowledged_at"`
+	CreatedAt            sql.NullTime  `json:"created_at"`
+}
+
+type IncidentEscalationMessage struct {
+	EscalationID int64  `json:"escalation_id"`
+	OutboxID     int64  `json:"outbox_id"`
+	Stage        string `json:"stage"`
+}
+
+type NotificationPreference struct {
+	UserID          int64          `json:"user_id"`
+	Reminders       bool           `json:"reminders"`
+	Broadcasts      bool           `json:"broadcasts"`
+	CoverageAlerts  bool           `json:"coverage_alerts"`
+	ReportUpdates   bool           `json:"report_updates"`
+	QuietHoursStart sql.NullString `json:"quiet_hours_start"`
+	QuietHoursEnd   sql.NullString `json:"quiet_hours_end"`
+	Timezone        sql.NullString `json:"timezone"`
+	UpdatedAt       sql.NullTime   `json:"updated_at"`
+}
+
+type OffShiftReportSubmission struct {
+	SubmissionID int64          `json:"submission_id"`
+	UserID       int64          `json:"user_id"`
+	ClientIp     sql.NullString `json:"client_ip"`
+	ReportID     sql.NullInt64  `json:"report_id"`
+	SubmittedAt  time.Time      `json:"submitted_at"`
+}
+
 type OtpAttempt struct {
 	AttemptID   int64          `json:"attempt_id"`
 	Phone       string         `json:"phone"`
@@ -105,16 +203,45 @@ type OtpRateLimit struct {
 }
 
 type Outbox struct {
-	OutboxID    int64          `json:"outbox_id"`
-	MessageType string         `json:"message_type"`
-	Recipient   string         `json:"recipient"`
-	Payload     sql.NullString `json:"payload"`
-	Status      string         `json:"status"`
-	CreatedAt   sql.NullTime   `json:"created_at"`
-	SentAt      sql.NullTime   `json:"sent_at"`
-	RetryCount  sql.NullInt64  `json:"retry_count"`
-	UserID      sql.NullInt64  `json:"user_id"`
-	SendAt      time.Time      `json:"send_at"`
+	OutboxID          int64          `json:"outbox_id"`
+	MessageType       string         `json:"message_type"`
+	Recipient         string         `json:"recipient"`
+	Payload           sql.NullString `json:"payload"`
+	Status            string         `json:"status"`
+	CreatedAt         sql.NullTime   `json:"created_at"`
+	SentAt            sql.NullTime   `json:"sent_at"`
+	RetryCount        sql.NullInt64  `json:"retry_count"`
+	UserID            sql.NullInt64  `json:"user_id"`
+	SendAt            time.Time      `json:"send_at"`
+	Provider          sql.NullString `json:"provider"`
+	ProviderMessageID sql.NullString `json:"provider_message_id"`
+	DeliveryStatus    sql.NullString `json:"delivery_status"`
+	DeliveryError     sql.NullString `json:"delivery_error"`
+	DeliveryUpdatedAt sql.NullTime   `json:"delivery_updated_at"`
+	LastError         sql.NullString `json:"last_error"`
+	DeadLetteredAt    sql.NullTime   `json:"dead_lettered_at"`
+	LeaseToken        sql.NullString `json:"lease_token"`
+	LeasedUntil       sql.NullTime   `json:"leased_until"`
+	Category          sql.NullString `json:"category"`
+}
+
+type PatrolLocation struct {
+	LocationID int64           `json:"location_id"`
+	BookingID  int64           `json:"booking_id"`
+	UserID     int64           `json:"user_id"`
+	Latitude   float64         `json:"latitude"`
+	Longitude  float64         `json:"longitude"`
+	Accuracy   sql.NullFloat64 `json:"accuracy"`
+	RecordedAt time.Time       `json:"recorded_at"`
+	CreatedAt  sql.NullTime    `json:"created_at"`
+}
+
+type PatrolLocationSharing struct {
+	BookingID  int64          `json:"booking_id"`
+	UserID     int64          `json:"user_id"`
+	StartedAt  time.Time      `json:"started_at"`
+	StoppedAt  sql.NullTime   `json:"stopped_at"`
+	StopReason sql.NullString `json:"stop_reason"`
 }
 
 type PointsHistory struct {
@@ -128,29 +255,63 @@ type PointsHistory struct {
 }
 
 type PushSubscription struct {
-	ID        int64          `json:"id"`
-	UserID    int64          `json:"user_id"`
-	Endpoint  string         `json:"endpoint"`
-	P256dhKey string         `json:"p256dh_key"`
-	AuthKey   string         `json:"auth_key"`
-	UserAgent sql.NullString `json:"user_agent"`
-	Platform  sql.NullString `json:"platform"`
-	CreatedAt sql.NullTime   `json:"created_at"`
+	ID                  int64          `json:"id"`
+	UserID              int64          `json:"user_id"`
+	Endpoint            string         `json:"endpoint"`
+	P256dhKey           string         `json:"p256dh_key"`
+	AuthKey             string         `json:"auth_key"`
+	UserAgent           sql.NullString `json:"user_agent"`
+	Platform            sql.NullString `json:"platform"`
+	CreatedAt           sql.NullTime   `json:"created_at"`
+	DeviceLabel         sql.NullString `json:"device_label"`
+	SuccessCount        int64          `json:"success_count"`
+	FailureCount        int64          `json:"failure_count"`
+	ConsecutiveFailures int64          `json:"consecutive_failures"`
+	LastSuccessAt       sql.NullTime   `json:"last_success_at"`
+	LastFailureAt       sql.NullTime   `json:"last_failure_at"`
+	LastError           sql.NullString `json:"last_error"`
 }
 
 type Report struct {
-	ReportID     int64           `json:"report_id"`
-	BookingID    sql.NullInt64   `json:"booking_id"`
-	UserID       sql.NullInt64   `json:"user_id"`
-	Severity     int64           `json:"severity"`
-	Message      sql.NullString  `json:"message"`
-	CreatedAt    sql.NullTime    `json:"created_at"`
-	Latitude     sql.NullFloat64 `json:"latitude"`
-	Longitude    sql.NullFloat64 `json:"longitude"`
-	GpsAccuracy  sql.NullFloat64 `json:"gps_accuracy"`
-	GpsTimestamp sql.NullTime    `json:"gps_timestamp"`
-	ArchivedAt   sql.NullTime    `json:"archived_at"`
-	PhotoCount   sql.NullInt64   `json:"photo_count"`
+	ReportID       int64           `json:"report_id"`
+	BookingID      sql.NullInt64   `json:"booking_id"`
+	UserID         sql.NullInt64   `json:"user_id"`
+	Severity       int64           `json:"severity"`
+	Message        sql.NullString  `json:"message"`
+	CreatedAt      sql.NullTime    `json:"created_at"`
+	Latitude       sql.NullFloat64 `json:"latitude"`
+	Longitude      sql.NullFloat64 `json:"longitude"`
+	GpsAccuracy    sql.NullFloat64 `json:"gps_accuracy"`
+	GpsTimestamp   sql.NullTime    `json:"gps_timestamp"`
+	ArchivedAt     sql.NullTime    `json:"archived_at"`
+	PhotoCount     sql.NullInt64   `json:"photo_count"`
+	CategoryID     sql.NullInt64   `json:"category_id"`
+	CategoryFields sql.NullString  `json:"category_fields"`
+	AnonymisedAt   sql.NullTime    `json:"anonymised_at"`
+}
+
+type ReportDuplicateMatch struct {
+	ReportID            int64           `json:"report_id"`
+	DuplicateOfReportID int64           `json:"duplicate_of_report_id"`
+	Score               float64         `json:"score"`
+	DistanceMeters      sql.NullFloat64 `json:"distance_meters"`
+	DetectedAt          sql.NullTime    `json:"detected_at"`
+}
+
+type ReportFlag struct {
+	ReportID        int64          `json:"report_id"`
+	ReporterUserID  sql.NullInt64  `json:"reporter_user_id"`
+	Flag            string         `json:"flag"`
+	Note            sql.NullString `json:"note"`
+	FlaggedByUserID sql.NullInt64  `json:"flagged_by_user_id"`
+	FlaggedAt       sql.NullTime   `json:"flagged_at"`
+}
+
+type ReportMerge struct {
+	ReportID        int64         `json:"report_id"`
+	PrimaryReportID int64         `json:"primary_report_id"`
+	MergedByUserID  sql.NullInt64 `json:"merged_by_user_id"`
+	MergedAt        sql.NullTime  `json:"merged_at"`
 }
 
 type ReportPhoto struct {
@@ -169,6 +330,17 @@ type ReportPhoto struct {
 	IsProcessed      sql.NullBool   `json:"is_processed"`
 }
 
+type ReportRetentionPolicy struct {
+	PolicyID         int64         `json:"policy_id"`
+	Severity         sql.NullInt64 `json:"severity"`
+	CategoryID       sql.NullInt64 `json:"category_id"`
+	ArchiveAfterDays sql.NullInt64 `json:"archive_after_days"`
+	PurgeAfterDays   sql.NullInt64 `json:"purge_after_days"`
+	PurgeAction      string        `json:"purge_action"`
+	CreatedAt        sql.NullTime  `json:"created_at"`
+	UpdatedAt        sql.NullTime  `json:"updated_at"`
+}
+
 type Schedule struct {
 	ScheduleID      int64          `json:"schedule_id"`
 	Name            string         `json:"name"`
@@ -179,15 +351,65 @@ type Schedule struct {
 	Timezone        sql.NullString `json:"timezone"`
 }
 
+type SosAcknowledgement struct {
+	AlertID        int64        `json:"alert_id"`
+	UserID         int64        `json:"user_id"`
+	AcknowledgedAt sql.NullTime `json:"acknowledged_at"`
+}
+
+type SosAlert struct {
+	AlertID           int64           `json:"alert_id"`
+	UserID            int64           `json:"user_id"`
+	BookingID         sql.NullInt64   `json:"booking_id"`
+	ReportID          sql.NullInt64   `json:"report_id"`
+	Latitude          sql.NullFloat64 `json:"latitude"`
+	Longitude         sql.NullFloat64 `json:"longitude"`
+	Accuracy          sql.NullFloat64 `json:"accuracy"`
+	Status            string          `json:"status"`
+	StoodDownByUserID sql.NullInt64   `json:"stood_down_by_user_id"`
+	StoodDownAt       sql.NullTime    `json:"stood_down_at"`
+	StandDownNote     sql.NullString  `json:"stand_down_note"`
+	CreatedAt         sql.NullTime    `json:"created_at"`
+}
+
+type Tip struct {
+	TipID            int64           `json:"tip_id"`
+	Message          string          `json:"message"`
+	Severity         int64           `json:"severity"`
+	Latitude         sql.NullFloat64 `json:"latitude"`
+	Longitude        sql.NullFloat64 `json:"longitude"`
+	Contact          sql.NullString  `json:"contact"`
+	Status           string          `json:"status"`
+	ReportID         sql.NullInt64   `json:"report_id"`
+	ReviewNote       sql.NullString  `json:"review_note"`
+	ReviewedByUserID sql.NullInt64   `json:"reviewed_by_user_id"`
+	ReviewedAt       sql.NullTime    `json:"reviewed_at"`
+	CreatedAt        sql.NullTime    `json:"created_at"`
+}
+
+type TipSubmission struct {
+	SubmissionID  int64     `json:"submission_id"`
+	ClientHash    string    `json:"client_hash"`
+	ChallengeHash string    `json:"challenge_hash"`
+	SubmittedAt   time.Time `json:"submitted_at"`
+}
+
 type User struct {
-	UserID           int64          `json:"user_id"`
-	Phone            string         `json:"phone"`
-	Name             sql.NullString `json:"name"`
-	CreatedAt        sql.NullTime   `json:"created_at"`
-	Role             string         `json:"role"`
-	TotalPoints      sql.NullInt64  `json:"total_points"`
-	ShiftCount       sql.NullInt64  `json:"shift_count"`
-	LastActivityDate sql.NullTime   `json:"last_activity_date"`
+	UserID                     int64          `json:"user_id"`
+	Phone                      string         `json:"phone"`
+	Name                       sql.NullString `json:"name"`
+	CreatedAt                  sql.NullTime   `json:"created_at"`
+	Role                       string         `json:"role"`
+	TotalPoints                sql.NullInt64  `json:"total_points"`
+	ShiftCount                 sql.NullInt64  `json:"shift_count"`
+	LastActivityDate           sql.NullTime   `json:"last_activity_date"`
+	PreferredChannel           string         `json:"preferred_channel"`
+	Email                      sql.NullString `json:"email"`
+	EmailVerifiedAt            sql.NullTime   `json:"email_verified_at"`
+	PendingEmail               sql.NullString `json:"pending_email"`
+	EmailVerificationCodeHash  sql.NullString `json:"email_verification_code_hash"`
+	EmailVerificationExpiresAt sql.NullTime   `json:"email_verification_expires_at"`
+	EmailVerificationAttempts  int64          `json:"email_verification_attempts"`
 }
 
 type UserAchievement struct {
@@ -195,3 +417,36 @@ type UserAchievement struct {
 	AchievementID int64        `json:"achievement_id"`
 	EarnedAt      sql.NullTime `json:"earned_at"`
 }
+
+type WatchlistEntry struct {
+	EntryID         int64          `json:"entry_id"`
+	Kind            string         `json:"kind"`
+	Label           string         `json:"label"`
+	Registration    sql.NullString `json:"registration"`
+	Description     sql.NullString `json:"description"`
+	Notes           sql.NullString `json:"notes"`
+	Keywords        string         `json:"keywords"`
+	IsActive        bool           `json:"is_active"`
+	CreatedByUserID sql.NullInt64  `json:"created_by_user_id"`
+	CreatedAt       sql.NullTime   `json:"created_at"`
+	UpdatedAt       sql.NullTime   `json:"updated_at"`
+}
+
+type WatchlistMatch struct {
+	EntryID     int64        `json:"entry_id"`
+	ReportID    int64        `json:"report_id"`
+	MatchedOn   string       `json:"matched_on"`
+	MatchedTerm string       `json:"matched_term"`
+	MatchedAt   sql.NullTime `json:"matched_at"`
+}
+
+type WatchlistPhoto struct {
+	PhotoID          int64         `json:"photo_id"`
+	EntryID          int64         `json:"entry_id"`
+	Filename         string        `json:"filename"`
+	MimeType         string        `json:"mime_type"`
+	FileSizeBytes    int64         `json:"file_size_bytes"`
+	StoragePath      string        `json:"storage_path"`
+	UploadedByUserID sql.NullInt64 `json:"uploaded_by_user_id"`
+	CreatedAt        sql.NullTime  `json:"created_at"`
+}
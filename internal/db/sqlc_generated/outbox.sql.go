@@ -8,6 +8,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 )
 
@@ -17,15 +18,17 @@ INSERT INTO outbox (
     recipient,
     payload,
     user_id,
-    send_at
+    send_at,
+    category
 ) VALUES (
     ?,
     ?,
     ?,
     ?,
+    ?,
     ?
 )
-RETURNING outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at
+RETURNING outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at, provider, provider_message_id, delivery_status, delivery_error, delivery_updated_at, last_error, dead_lettered_at, lease_token, leased_until, category
 `
 
 type CreateOutboxItemParams struct {
@@ -34,6 +37,7 @@ type CreateOutboxItemParams struct {
 	Payload     sql.NullString `json:"payload"`
 	UserID      sql.NullInt64  `json:"user_id"`
 	SendAt      time.Time      `json:"send_at"`
+	Category    sql.NullString `json:"category"`
 }
 
 func (q *Queries) CreateOutboxItem(ctx context.Context, arg CreateOutboxItemParams) (Outbox, error) {
@@ -43,6 +47,7 @@ func (q *Queries) CreateOutboxItem(ctx context.Context, arg CreateOutboxItemPara
 		arg.Payload,
 		arg.UserID,
 		arg.SendAt,
+		arg.Category,
 	)
 	var i Outbox
 	err := row.Scan(
@@ -56,13 +61,23 @@ func (q *Queries) CreateOutboxItem(ctx context.Context, arg CreateOutboxItemPara
 		&i.RetryCount,
 		&i.UserID,
 		&i.SendAt,
+		&i.Provider,
+		&i.ProviderMessageID,
+		&i.DeliveryStatus,
+		&i.DeliveryError,
+		&i.DeliveryUpdatedAt,
+		&i.LastError,
+		&i.DeadLetteredAt,
+		&i.LeaseToken,
+		&i.LeasedUntil,
+		&i.Category,
 	)
 	return i, err
 }
 
 const getPendingOutboxItems = `-- name: GetPendingOutboxItems :many
-SELECT outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at FROM outbox
-WHERE status = 'pending'
+SELECT outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at, provider, provider_message_id, delivery_status, delivery_error, delivery_updated_at, last_error, dead_lettered_at, lease_token, leased_until, category FROM outbox
+WHERE status IN ('pending', 'failed')
   AND send_at <= CURRENT_TIMESTAMP
 ORDER BY created_at ASC
 LIMIT ?
@@ -88,6 +103,16 @@ func (q *Queries) GetPendingOutboxItems(ctx context.Context, limit int64) ([]Out
 			&i.RetryCount,
 			&i.UserID,
 			&i.SendAt,
+			&i.Provider,
+			&i.ProviderMessageID,
+			&i.DeliveryStatus,
+			&i.DeliveryError,
+			&i.DeliveryUpdatedAt,
+			&i.LastError,
+			&i.DeadLetteredAt,
+			&i.LeaseToken,
+			&i.LeasedUntil,
+			&i.Category,
 		); err != nil {
 			return nil, err
 		}
@@ -104,7 +129,7 @@ func (q *Queries) GetPendingOutboxItems(ctx context.Context, limit int64) ([]Out
 
 const getRecentOutboxItemsByRecipient = `-- name: GetRecentOutboxItemsByRecipient :many
 
-SELECT outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at FROM outbox
+SELECT outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at, provider, provider_message_id, delivery_status, delivery_error, delivery_updated_at, last_error, dead_lettered_at, lease_token, leased_until, category FROM outbox
 WHERE recipient = ?
 ORDER BY created_at DESC
 LIMIT ?
@@ -136,6 +161,16 @@ func (q *Queries) GetRecentOutboxItemsByRecipient(ctx context.Context, arg GetRe
 			&i.RetryCount,
 			&i.UserID,
 			&i.SendAt,
+			&i.Provider,
+			&i.ProviderMessageID,
+			&i.DeliveryStatus,
+			&i.DeliveryError,
+			&i.DeliveryUpdatedAt,
+			&i.LastError,
+			&i.DeadLetteredAt,
+			&i.LeaseToken,
+			&i.LeasedUntil,
+			&i.Category,
 		); err != nil {
 			return nil, err
 		}
@@ -156,7 +191,7 @@ SET status = ?,
     sent_at = ?,
     retry_count = ?
 WHERE outbox_id = ?
-RETURNING outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at
+RETURNING outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at, provider, provider_message_id, delivery_status, delivery_error, delivery_updated_at, last_error, dead_lettered_at, lease_token, leased_until, category
 `
 
 type UpdateOutboxItemStatusParams struct {
@@ -185,6 +220,545 @@ func (q *Queries) UpdateOutboxItemStatus(ctx context.Context, arg UpdateOutboxIt
 		&i.RetryCount,
 		&i.UserID,
 		&i.SendAt,
+		&i.Provider,
+		&i.ProviderMessageID,
+		&i.DeliveryStatus,
+		&i.DeliveryError,
+		&i.DeliveryUpdatedAt,
+		&i.LastError,
+		&i.DeadLetteredAt,
+		&i.LeaseToken,
+		&i.LeasedUntil,
+		&i.Category,
+	)
+	return i, err
+}
+
+const getOutboxItemByID = `-- name: GetOutboxItemByID :one
+SELECT outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at, provider, provider_message_id, delivery_status, delivery_error, delivery_updated_at, last_error, dead_lettered_at, lease_token, leased_until, category FROM outbox
+WHERE outbox_id = ?
+`
+
+func (q *Queries) GetOutboxItemByID(ctx context.Context, outboxID int64) (Outbox, error) {
+	row := q.db.QueryRowContext(ctx, getOutboxItemByID, outboxID)
+	var i Outbox
+	err := row.Scan(
+		&i.OutboxID,
+		&i.MessageType,
+		&i.Recipient,
+		&i.Payload,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SentAt,
+		&i.RetryCount,
+		&i.UserID,
+		&i.SendAt,
+		&i.Provider,
+		&i.ProviderMessageID,
+		&i.DeliveryStatus,
+		&i.DeliveryError,
+		&i.DeliveryUpdatedAt,
+		&i.LastError,
+		&i.DeadLetteredAt,
+		&i.LeaseToken,
+		&i.LeasedUntil,
+		&i.Category,
+	)
+	return i, err
+}
+
+const setOutboxProviderMessage = `-- name: SetOutboxProviderMessage :exec
+UPDATE outbox
+SET provider = ?,
+    provider_message_id = ?,
+    delivery_status = 'sent',
+    delivery_updated_at = CURRENT_TIMESTAMP
+WHERE outbox_id = ?
+`
+
+type SetOutboxProviderMessageParams struct {
+	Provider          sql.NullString `json:"provider"`
+	ProviderMessageID sql.NullString `json:"provider_message_id"`
+	OutboxID          int64          `json:"outbox_id"`
+}
+
+func (q *Queries) SetOutboxProviderMessage(ctx context.Context, arg SetOutboxProviderMessageParams) error {
+	_, err := q.db.ExecContext(ctx, setOutboxProviderMessage, arg.Provider, arg.ProviderMessageID, arg.OutboxID)
+	return err
+}
+
+const updateOutboxDeliveryStatus = `-- name: UpdateOutboxDeliveryStatus :execrows
+UPDATE outbox
+SET delivery_status = ?,
+    delivery_error = ?,
+    delivery_updated_at = CURRENT_TIMESTAMP
+WHERE provider = ?
+  AND provider_message_id = ?
+  AND (delivery_status IS NULL OR delivery_status = 'sent')
+`
+
+type UpdateOutboxDeliveryStatusParams struct {
+	DeliveryStatus    sql.NullString `json:"delivery_status"`
+	DeliveryError     sql.NullString `json:"delivery_error"`
+	Provider          sql.NullString `json:"provider"`
+	ProviderMessageID sql.NullString `json:"provider_message_id"`
+}
+
+// Delivered and failed are final, so a late intermediate report cannot overwrite them
+func (q *Queries) UpdateOutboxDeliveryStatus(ctx context.Context, arg UpdateOutboxDeliveryStatusParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateOutboxDeliveryStatus,
+		arg.DeliveryStatus,
+		arg.DeliveryError,
+		arg.Provider,
+		arg.ProviderMessageID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const rescheduleOutboxItem = `-- name: RescheduleOutboxItem :execrows
+UPDATE outbox
+SET status = 'failed',
+    retry_count = ?,
+    send_at = ?,
+    last_error = ?,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND lease_token = ?
+  AND status != 'cancelled'
+`
+
+type RescheduleOutboxItemParams struct {
+	RetryCount sql.NullInt64  `json:"retry_count"`
+	SendAt     time.Time      `json:"send_at"`
+	LastError  sql.NullString `json:"last_error"`
+	OutboxID   int64          `json:"outbox_id"`
+	LeaseToken sql.NullString `json:"lease_token"`
+}
+
+// Failed items are retried once send_at comes round again
+func (q *Queries) RescheduleOutboxItem(ctx context.Context, arg RescheduleOutboxItemParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rescheduleOutboxItem,
+		arg.RetryCount,
+		arg.SendAt,
+		arg.LastError,
+		arg.OutboxID,
+		arg.LeaseToken,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deadLetterOutboxItem = `-- name: DeadLetterOutboxItem :execrows
+UPDATE outbox
+SET status = 'permanently_failed',
+    retry_count = ?,
+    last_error = ?,
+    dead_lettered_at = CURRENT_TIMESTAMP,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND lease_token = ?
+  AND status != 'cancelled'
+`
+
+type DeadLetterOutboxItemParams struct {
+	RetryCount sql.NullInt64  `json:"retry_count"`
+	LastError  sql.NullString `json:"last_error"`
+	OutboxID   int64          `json:"outbox_id"`
+	LeaseToken sql.NullString `json:"lease_token"`
+}
+
+func (q *Queries) DeadLetterOutboxItem(ctx context.Context, arg DeadLetterOutboxItemParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deadLetterOutboxItem,
+		arg.RetryCount,
+		arg.LastError,
+		arg.OutboxID,
+		arg.LeaseToken,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listDeadLetterOutboxItems = `-- name: ListDeadLetterOutboxItems :many
+SELECT outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at, provider, provider_message_id, delivery_status, delivery_error, delivery_updated_at, last_error, dead_lettered_at, lease_token, leased_until, category FROM outbox
+WHERE status = 'permanently_failed'
+  AND (?1 IS NULL OR message_type = ?1)
+ORDER BY dead_lettered_at DESC, outbox_id DESC
+LIMIT ?2 OFFSET ?3
+`
+
+type ListDeadLetterOutboxItemsParams struct {
+	MessageType interface{} `json:"message_type"`
+	Limit       int64       `json:"limit"`
+	Offset      int64       `json:"offset"`
+}
+
+func (q *Queries) ListDeadLetterOutboxItems(ctx context.Context, arg ListDeadLetterOutboxItemsParams) ([]Outbox, error) {
+	rows, err := q.db.QueryContext(ctx, listDeadLetterOutboxItems, arg.MessageType, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Outbox{}
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.OutboxID,
+			&i.MessageType,
+			&i.Recipient,
+			&i.Payload,
+			&i.Status,
+			&i.CreatedAt,
+			&i.SentAt,
+			&i.RetryCount,
+			&i.UserID,
+			&i.SendAt,
+			&i.Provider,
+			&i.ProviderMessageID,
+			&i.DeliveryStatus,
+			&i.DeliveryError,
+			&i.DeliveryUpdatedAt,
+			&i.LastError,
+			&i.DeadLetteredAt,
+			&i.LeaseToken,
+			&i.LeasedUntil,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const requeueOutboxItem = `-- name: RequeueOutboxItem :one
+UPDATE outbox
+SET status = 'pending',
+    retry_count = 0,
+    send_at = ?,
+    dead_lettered_at = NULL,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND status = 'permanently_failed'
+RETURNING outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at, provider, provider_message_id, delivery_status, delivery_error, delivery_updated_at, last_error, dead_lettered_at, lease_token, leased_until, category
+`
+
+type RequeueOutboxItemParams struct {
+	SendAt   time.Time `json:"send_at"`
+	OutboxID int64     `json:"outbox_id"`
+}
+
+func (q *Queries) RequeueOutboxItem(ctx context.Context, arg RequeueOutboxItemParams) (Outbox, error) {
+	row := q.db.QueryRowContext(ctx, requeueOutboxItem, arg.SendAt, arg.OutboxID)
+	var i Outbox
+	err := row.Scan(
+		&i.OutboxID,
+		&i.MessageType,
+		&i.Recipient,
+		&i.Payload,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SentAt,
+		&i.RetryCount,
+		&i.UserID,
+		&i.SendAt,
+		&i.Provider,
+		&i.ProviderMessageID,
+		&i.DeliveryStatus,
+		&i.DeliveryError,
+		&i.DeliveryUpdatedAt,
+		&i.LastError,
+		&i.DeadLetteredAt,
+		&i.LeaseToken,
+		&i.LeasedUntil,
+		&i.Category,
+	)
+	return i, err
+}
+
+const discardOutboxItem = `-- name: DiscardOutboxItem :one
+UPDATE outbox
+SET status = 'discarded'
+WHERE outbox_id = ?
+  AND status = 'permanently_failed'
+RETURNING outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at, provider, provider_message_id, delivery_status, delivery_error, delivery_updated_at, last_error, dead_lettered_at, lease_token, leased_until, category
+`
+
+func (q *Queries) DiscardOutboxItem(ctx context.Context, outboxID int64) (Outbox, error) {
+	row := q.db.QueryRowContext(ctx, discardOutboxItem, outboxID)
+	var i Outbox
+	err := row.Scan(
+		&i.OutboxID,
+		&i.MessageType,
+		&i.Recipient,
+		&i.Payload,
+		&i.Status,
+		&i.CreatedAt,
+		&i.SentAt,
+		&i.RetryCount,
+		&i.UserID,
+		&i.SendAt,
+		&i.Provider,
+		&i.ProviderMessageID,
+		&i.DeliveryStatus,
+		&i.DeliveryError,
+		&i.DeliveryUpdatedAt,
+		&i.LastError,
+		&i.DeadLetteredAt,
+		&i.LeaseToken,
+		&i.LeasedUntil,
+		&i.Category,
 	)
 	return i, err
 }
+
+const claimOutboxItems = `-- name: ClaimOutboxItems :many
+UPDATE outbox
+SET lease_token = ?,
+    leased_until = ?
+WHERE outbox_id IN (
+    SELECT outbox_id FROM outbox
+    WHERE status IN ('pending', 'failed')
+      AND send_at <= CURRENT_TIMESTAMP
+      AND (leased_until IS NULL OR leased_until <= CURRENT_TIMESTAMP)
+    ORDER BY created_at ASC
+    LIMIT ?
+)
+RETURNING outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at, provider, provider_message_id, delivery_status, delivery_error, delivery_updated_at, last_error, dead_lettered_at, lease_token, leased_until, category
+`
+
+type ClaimOutboxItemsParams struct {
+	LeaseToken  sql.NullString `json:"lease_token"`
+	LeasedUntil sql.NullTime   `json:"leased_until"`
+	Limit       int64          `json:"limit"`
+}
+
+// Leases a batch of due items to one dispatcher run. Items leased by another
+// run are skipped until the lease expires.
+func (q *Queries) ClaimOutboxItems(ctx context.Context, arg ClaimOutboxItemsParams) ([]Outbox, error) {
+	rows, err := q.db.QueryContext(ctx, claimOutboxItems, arg.LeaseToken, arg.LeasedUntil, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Outbox{}
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.OutboxID,
+			&i.MessageType,
+			&i.Recipient,
+			&i.Payload,
+			&i.Status,
+			&i.CreatedAt,
+			&i.SentAt,
+			&i.RetryCount,
+			&i.UserID,
+			&i.SendAt,
+			&i.Provider,
+			&i.ProviderMessageID,
+			&i.DeliveryStatus,
+			&i.DeliveryError,
+			&i.DeliveryUpdatedAt,
+			&i.LastError,
+			&i.DeadLetteredAt,
+			&i.LeaseToken,
+			&i.LeasedUntil,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimOutboxItemsByID = `-- name: ClaimOutboxItemsByID :many
+UPDATE outbox
+SET lease_token = ?,
+    leased_until = ?
+WHERE outbox_id IN (/*SLICE:outbox_ids*/?)
+  AND status = 'pending'
+  AND (leased_until IS NULL OR leased_until <= CURRENT_TIMESTAMP)
+RETURNING outbox_id, message_type, recipient, payload, status, created_at, sent_at, retry_count, user_id, send_at, provider, provider_message_id, delivery_status, delivery_error, delivery_updated_at, last_error, dead_lettered_at, lease_token, leased_until, category
+`
+
+type ClaimOutboxItemsByIDParams struct {
+	LeaseToken  sql.NullString `json:"lease_token"`
+	LeasedUntil sql.NullTime   `json:"leased_until"`
+	OutboxIds   []int64        `json:"outbox_ids"`
+}
+
+func (q *Queries) ClaimOutboxItemsByID(ctx context.Context, arg ClaimOutboxItemsByIDParams) ([]Outbox, error) {
+	query := claimOutboxItemsByID
+	var queryParams []interface{}
+	queryParams = append(queryParams, arg.LeaseToken)
+	queryParams = append(queryParams, arg.LeasedUntil)
+	if len(arg.OutboxIds) > 0 {
+		for _, v := range arg.OutboxIds {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:outbox_ids*/?", strings.Repeat(",?", len(arg.OutboxIds))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:outbox_ids*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Outbox{}
+	for rows.Next() {
+		var i Outbox
+		if err := rows.Scan(
+			&i.OutboxID,
+			&i.MessageType,
+			&i.Recipient,
+			&i.Payload,
+			&i.Status,
+			&i.CreatedAt,
+			&i.SentAt,
+			&i.RetryCount,
+			&i.UserID,
+			&i.SendAt,
+			&i.Provider,
+			&i.ProviderMessageID,
+			&i.DeliveryStatus,
+			&i.DeliveryError,
+			&i.DeliveryUpdatedAt,
+			&i.LastError,
+			&i.DeadLetteredAt,
+			&i.LeaseToken,
+			&i.LeasedUntil,
+			&i.Category,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markOutboxItemSent = `-- name: MarkOutboxItemSent :execrows
+UPDATE outbox
+SET status = 'sent',
+    sent_at = ?,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND lease_token = ?
+  AND status != 'cancelled'
+`
+
+type MarkOutboxItemSentParams struct {
+	SentAt     sql.NullTime   `json:"sent_at"`
+	OutboxID   int64          `json:"outbox_id"`
+	LeaseToken sql.NullString `json:"lease_token"`
+}
+
+func (q *Queries) MarkOutboxItemSent(ctx context.Context, arg MarkOutboxItemSentParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markOutboxItemSent, arg.SentAt, arg.OutboxID, arg.LeaseToken)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deferOutboxItem = `-- name: DeferOutboxItem :execrows
+UPDATE outbox
+SET send_at = ?,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND lease_token = ?
+`
+
+type DeferOutboxItemParams struct {
+	SendAt     time.Time      `json:"send_at"`
+	OutboxID   int64          `json:"outbox_id"`
+	LeaseToken sql.NullString `json:"lease_token"`
+}
+
+// Holds an item back until the recipient's quiet hours end
+func (q *Queries) DeferOutboxItem(ctx context.Context, arg DeferOutboxItemParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deferOutboxItem, arg.SendAt, arg.OutboxID, arg.LeaseToken)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const suppressOutboxItem = `-- name: SuppressOutboxItem :execrows
+UPDATE outbox
+SET status = 'suppressed',
+    last_error = ?,
+    lease_token = NULL,
+    leased_until = NULL
+WHERE outbox_id = ?
+  AND lease_token = ?
+`
+
+type SuppressOutboxItemParams struct {
+	LastError  sql.NullString `json:"last_error"`
+	OutboxID   int64          `json:"outbox_id"`
+	LeaseToken sql.NullString `json:"lease_token"`
+}
+
+// Drops an item the recipient has opted out of
+func (q *Queries) SuppressOutboxItem(ctx context.Context, arg SuppressOutboxItemParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, suppressOutboxItem, arg.LastError, arg.OutboxID, arg.LeaseToken)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const cancelShiftReminders = `-- name: CancelShiftReminders :execrows
+UPDATE outbox
+SET status = 'cancelled'
+WHERE category = 'reminders'
+  AND status IN ('pending', 'failed')
+  AND user_id = ?
+  AND json_extract(payload, '$.data.booking_id') = CAST(? AS INTEGER)
+`
+
+type CancelShiftRemindersParams struct {
+	UserID    sql.NullInt64 `json:"user_id"`
+	BookingID int64         `json:"booking_id"`
+}
+
+// Cancels the reminders still queued for a booking that has been cancelled
+func (q *Queries) CancelShiftReminders(ctx context.Context, arg CancelShiftRemindersParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelShiftReminders, arg.UserID, arg.BookingID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
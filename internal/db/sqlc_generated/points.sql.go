@@ -54,6 +54,25 @@ func (q *Queries) AwardPoints(ctx context.Context, arg AwardPointsParams) error
 	return err
 }
 
+const countUserBookingPointsByReason = `-- name: CountUserBookingPointsByReason :one
+SELECT COUNT(*) FROM points_history
+WHERE user_id = ? AND booking_id = ? AND reason = ?
+`
+
+type CountUserBookingPointsByReasonParams struct {
+	UserID    int64         `json:"user_id"`
+	BookingID sql.NullInt64 `json:"booking_id"`
+	Reason    string        `json:"reason"`
+}
+
+// Count how many times a user was awarded points for a booking with a given reason
+func (q *Queries) CountUserBookingPointsByReason(ctx context.Context, arg CountUserBookingPointsByReasonParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserBookingPointsByReason, arg.UserID, arg.BookingID, arg.Reason)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getAvailableAchievements = `-- name: GetAvailableAchievements :many
 SELECT 
     a.achievement_id,
@@ -10,6 +10,15 @@ import (
 	"database/sql"
 )
 
+const deletePushSubscriptionByID = `-- name: DeletePushSubscriptionByID :exec
+DELETE FROM push_subscriptions WHERE id = ?
+`
+
+func (q *Queries) DeletePushSubscriptionByID(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deletePushSubscriptionByID, id)
+	return err
+}
+
 const deleteSubscription = `-- name: DeleteSubscription :exec
 DELETE FROM push_subscriptions WHERE endpoint = ? AND user_id = ?
 `
@@ -64,10 +73,11 @@ func (q *Queries) GetAllSubscriptions(ctx context.Context) ([]GetAllSubscription
 }
 
 const getSubscriptionsByUser = `-- name: GetSubscriptionsByUser :many
-SELECT endpoint, p256dh_key, auth_key FROM push_subscriptions WHERE user_id = ?
+SELECT id, endpoint, p256dh_key, auth_key FROM push_subscriptions WHERE user_id = ?
 `
 
 type GetSubscriptionsByUserRow struct {
+	ID        int64  `json:"id"`
 	Endpoint  string `json:"endpoint"`
 	P256dhKey string `json:"p256dh_key"`
 	AuthKey   string `json:"auth_key"`
@@ -82,7 +92,96 @@ func (q *Queries) GetSubscriptionsByUser(ctx context.Context, userID int64) ([]G
 	items := []GetSubscriptionsByUserRow{}
 	for rows.Next() {
 		var i GetSubscriptionsByUserRow
-		if err := rows.Scan(&i.Endpoint, &i.P256dhKey, &i.AuthKey); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Endpoint,
+			&i.P256dhKey,
+			&i.AuthKey,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserPushSubscription = `-- name: GetUserPushSubscription :one
+SELECT id, user_id, endpoint, p256dh_key, auth_key, user_agent, platform, created_at,
+       device_label, success_count, failure_count, consecutive_failures,
+       last_success_at, last_failure_at, last_error
+FROM push_subscriptions
+WHERE id = ? AND user_id = ?
+`
+
+type GetUserPushSubscriptionParams struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+}
+
+func (q *Queries) GetUserPushSubscription(ctx context.Context, arg GetUserPushSubscriptionParams) (PushSubscription, error) {
+	row := q.db.QueryRowContext(ctx, getUserPushSubscription, arg.ID, arg.UserID)
+	var i PushSubscription
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Endpoint,
+		&i.P256dhKey,
+		&i.AuthKey,
+		&i.UserAgent,
+		&i.Platform,
+		&i.CreatedAt,
+		&i.DeviceLabel,
+		&i.SuccessCount,
+		&i.FailureCount,
+		&i.ConsecutiveFailures,
+		&i.LastSuccessAt,
+		&i.LastFailureAt,
+		&i.LastError,
+	)
+	return i, err
+}
+
+const listUserPushSubscriptions = `-- name: ListUserPushSubscriptions :many
+SELECT id, user_id, endpoint, p256dh_key, auth_key, user_agent, platform, created_at,
+       device_label, success_count, failure_count, consecutive_failures,
+       last_success_at, last_failure_at, last_error
+FROM push_subscriptions
+WHERE user_id = ?
+ORDER BY created_at DESC, id DESC
+`
+
+func (q *Queries) ListUserPushSubscriptions(ctx context.Context, userID int64) ([]PushSubscription, error) {
+	rows, err := q.db.QueryContext(ctx, listUserPushSubscriptions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []PushSubscription{}
+	for rows.Next() {
+		var i PushSubscription
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Endpoint,
+			&i.P256dhKey,
+			&i.AuthKey,
+			&i.UserAgent,
+			&i.Platform,
+			&i.CreatedAt,
+			&i.DeviceLabel,
+			&i.SuccessCount,
+			&i.FailureCount,
+			&i.ConsecutiveFailures,
+			&i.LastSuccessAt,
+			&i.LastFailureAt,
+			&i.LastError,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -96,23 +195,68 @@ func (q *Queries) GetSubscriptionsByUser(ctx context.Context, userID int64) ([]G
 	return items, nil
 }
 
+const recordPushFailure = `-- name: RecordPushFailure :one
+UPDATE push_subscriptions
+SET failure_count = failure_count + 1,
+    consecutive_failures = consecutive_failures + 1,
+    last_failure_at = CURRENT_TIMESTAMP,
+    last_error = ?
+WHERE id = ?
+RETURNING consecutive_failures, last_success_at, created_at
+`
+
+type RecordPushFailureParams struct {
+	LastError sql.NullString `json:"last_error"`
+	ID        int64          `json:"id"`
+}
+
+type RecordPushFailureRow struct {
+	ConsecutiveFailures int64        `json:"consecutive_failures"`
+	LastSuccessAt       sql.NullTime `json:"last_success_at"`
+	CreatedAt           sql.NullTime `json:"created_at"`
+}
+
+// Returns what the sender needs to decide whether to prune the subscription
+func (q *Queries) RecordPushFailure(ctx context.Context, arg RecordPushFailureParams) (RecordPushFailureRow, error) {
+	row := q.db.QueryRowContext(ctx, recordPushFailure, arg.LastError, arg.ID)
+	var i RecordPushFailureRow
+	err := row.Scan(&i.ConsecutiveFailures, &i.LastSuccessAt, &i.CreatedAt)
+	return i, err
+}
+
+const recordPushSuccess = `-- name: RecordPushSuccess :exec
+UPDATE push_subscriptions
+SET success_count = success_count + 1,
+    consecutive_failures = 0,
+    last_success_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+func (q *Queries) RecordPushSuccess(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, recordPushSuccess, id)
+	return err
+}
+
 const upsertSubscription = `-- name: UpsertSubscription :exec
-INSERT INTO push_subscriptions (user_id, endpoint, p256dh_key, auth_key, user_agent, platform)
-VALUES (?, ?, ?, ?, ?, ?)
+INSERT INTO push_subscriptions (user_id, endpoint, p256dh_key, auth_key, user_agent, platform, device_label)
+VALUES (?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT(endpoint) DO UPDATE
-SET p256dh_key = excluded.p256dh_key,
-    auth_key   = excluded.auth_key,
-    user_agent = excluded.user_agent,
-    platform   = excluded.platform
+SET p256dh_key   = excluded.p256dh_key,
+    auth_key     = excluded.auth_key,
+    user_agent   = excluded.user_agent,
+    platform     = excluded.platform,
+    device_label = excluded.device_label,
+    consecutive_failures = 0
 `
 
 type UpsertSubscriptionParams struct {
-	UserID    int64          `json:"user_id"`
-	Endpoint  string         `json:"endpoint"`
-	P256dhKey string         `json:"p256dh_key"`
-	AuthKey   string         `json:"auth_key"`
-	UserAgent sql.NullString `json:"user_agent"`
-	Platform  sql.NullString `json:"platform"`
+	UserID      int64          `json:"user_id"`
+	Endpoint    string         `json:"endpoint"`
+	P256dhKey   string         `json:"p256dh_key"`
+	AuthKey     string         `json:"auth_key"`
+	UserAgent   sql.NullString `json:"user_agent"`
+	Platform    sql.NullString `json:"platform"`
+	DeviceLabel sql.NullString `json:"device_label"`
 }
 
 func (q *Queries) UpsertSubscription(ctx context.Context, arg UpsertSubscriptionParams) error {
@@ -123,6 +267,7 @@ func (q *Queries) UpsertSubscription(ctx context.Context, arg UpsertSubscription
 		arg.AuthKey,
 		arg.UserAgent,
 		arg.Platform,
+		arg.DeviceLabel,
 	)
 	return err
 }
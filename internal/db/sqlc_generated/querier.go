@@ -11,11 +11,15 @@ import (
 )
 
 type Querier interface {
+	AcknowledgeIncidentEscalation(ctx context.Context, arg AcknowledgeIncidentEscalationParams) (IncidentEscalation, error)
+	AddBroadcastGroupMember(ctx context.Context, arg AddBroadcastGroupMemberParams) error
+	AddBroadcastRecipient(ctx context.Context, arg AddBroadcastRecipientParams) error
 	AdminBulkDeleteSchedules(ctx context.Context, scheduleIds []int64) error
 	AdminBulkDeleteUsers(ctx context.Context, userIds []int64) error
 	AdminGetReportWithContext(ctx context.Context, reportID int64) (AdminGetReportWithContextRow, error)
 	AdminListArchivedReportsWithContext(ctx context.Context) ([]AdminListArchivedReportsWithContextRow, error)
 	AdminListReportsWithContext(ctx context.Context) ([]AdminListReportsWithContextRow, error)
+	AnonymiseReport(ctx context.Context, reportID int64) error
 	ArchiveReport(ctx context.Context, reportID int64) error
 	// Award an achievement to a user
 	AwardAchievement(ctx context.Context, arg AwardAchievementParams) error
@@ -23,35 +27,88 @@ type Querier interface {
 	// Award points to a user for a specific reason
 	AwardPoints(ctx context.Context, arg AwardPointsParams) error
 	BulkArchiveReports(ctx context.Context, reportIds []int64) error
+	CancelPendingEscalationMessages(ctx context.Context, escalationID int64) (int64, error)
+	// Cancels the reminders still queued for a booking that has been cancelled
+	CancelShiftReminders(ctx context.Context, arg CancelShiftRemindersParams) (int64, error)
+	// Leases a batch of due items to one dispatcher run. Items leased by another
+	// run are skipped until the lease expires.
+	ClaimOutboxItems(ctx context.Context, arg ClaimOutboxItemsParams) ([]Outbox, error)
+	ClaimOutboxItemsByID(ctx context.Context, arg ClaimOutboxItemsByIDParams) ([]Outbox, error)
 	CleanupExpiredCalendarTokens(ctx context.Context) error
 	CleanupExpiredLocks(ctx context.Context) error
 	CleanupOldOTPAttempts(ctx context.Context, createdAt time.Time) error
+	CleanupOldOffShiftReportSubmissions(ctx context.Context, submittedAt time.Time) (int64, error)
+	CleanupOldTipSubmissions(ctx context.Context, submittedAt time.Time) (int64, error)
+	ClearUserEmail(ctx context.Context, userID int64) error
+	// Promotes the pending address once its code has been checked
+	ConfirmUserEmail(ctx context.Context, userID int64) (int64, error)
+	CountEscalationPushRecipient(ctx context.Context, arg CountEscalationPushRecipientParams) (int64, error)
+	CountOffShiftReportSubmissionsByIP(ctx context.Context, arg CountOffShiftReportSubmissionsByIPParams) (int64, error)
+	CountOffShiftReportSubmissionsByUser(ctx context.Context, arg CountOffShiftReportSubmissionsByUserParams) (int64, error)
+	CountTipSubmissions(ctx context.Context, submittedAt time.Time) (int64, error)
+	CountTipSubmissionsByClient(ctx context.Context, arg CountTipSubmissionsByClientParams) (int64, error)
+	// Count how many times a user was awarded points for a booking with a given reason
+	CountUserBookingPointsByReason(ctx context.Context, arg CountUserBookingPointsByReasonParams) (int64, error)
 	CountUsers(ctx context.Context) (int64, error)
 	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
 	CreateBooking(ctx context.Context, arg CreateBookingParams) (Booking, error)
 	CreateBroadcast(ctx context.Context, arg CreateBroadcastParams) (Broadcast, error)
+	CreateBroadcastGroup(ctx context.Context, arg CreateBroadcastGroupParams) (BroadcastGroup, error)
+	CreateBroadcastTemplate(ctx context.Context, arg CreateBroadcastTemplateParams) (BroadcastTemplate, error)
 	// Calendar Token Queries
 	CreateCalendarToken(ctx context.Context, arg CreateCalendarTokenParams) (CalendarToken, error)
 	CreateEmergencyContact(ctx context.Context, arg CreateEmergencyContactParams) (EmergencyContact, error)
+	CreateIncidentCategory(ctx context.Context, arg CreateIncidentCategoryParams) (IncidentCategory, error)
+	CreateIncidentEscalation(ctx context.Context, arg CreateIncidentEscalationParams) (IncidentEscalation, error)
 	// OTP Attempts Queries
 	CreateOTPAttempt(ctx context.Context, arg CreateOTPAttemptParams) (OtpAttempt, error)
 	CreateOTPRateLimit(ctx context.Context, arg CreateOTPRateLimitParams) (OtpRateLimit, error)
 	CreateOffShiftReport(ctx context.Context, arg CreateOffShiftReportParams) (Report, error)
+	CreateOffShiftReportSubmission(ctx context.Context, arg CreateOffShiftReportSubmissionParams) error
 	CreateOutboxItem(ctx context.Context, arg CreateOutboxItemParams) (Outbox, error)
+	CreatePatrolLocation(ctx context.Context, arg CreatePatrolLocationParams) error
 	CreateReport(ctx context.Context, arg CreateReportParams) (Report, error)
+	CreateReportDuplicateMatch(ctx context.Context, arg CreateReportDuplicateMatchParams) error
+	CreateReportMerge(ctx context.Context, arg CreateReportMergeParams) error
 	// Photo operations
 	CreateReportPhoto(ctx context.Context, arg CreateReportPhotoParams) (ReportPhoto, error)
+	CreateReportRetentionPolicy(ctx context.Context, arg CreateReportRetentionPolicyParams) (ReportRetentionPolicy, error)
+	CreateSOSAcknowledgement(ctx context.Context, arg CreateSOSAcknowledgementParams) (int64, error)
+	CreateSOSAlert(ctx context.Context, arg CreateSOSAlertParams) (SosAlert, error)
 	CreateSchedule(ctx context.Context, arg CreateScheduleParams) (Schedule, error)
+	CreateTip(ctx context.Context, arg CreateTipParams) (Tip, error)
+	CreateTipSubmission(ctx context.Context, arg CreateTipSubmissionParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error)
+	CreateWatchlistEntry(ctx context.Context, arg CreateWatchlistEntryParams) (WatchlistEntry, error)
+	// A report is linked to an entry once, however many times it mentions it
+	CreateWatchlistMatch(ctx context.Context, arg CreateWatchlistMatchParams) (int64, error)
+	CreateWatchlistPhoto(ctx context.Context, arg CreateWatchlistPhotoParams) (WatchlistPhoto, error)
+	DeadLetterOutboxItem(ctx context.Context, arg DeadLetterOutboxItemParams) (int64, error)
+	// Holds an item back until the recipient's quiet hours end
+	DeferOutboxItem(ctx context.Context, arg DeferOutboxItemParams) (int64, error)
 	DeleteBooking(ctx context.Context, bookingID int64) error
 	DeleteBroadcast(ctx context.Context, broadcastID int64) error
+	DeleteBroadcastGroup(ctx context.Context, groupID int64) (int64, error)
+	DeleteBroadcastTemplate(ctx context.Context, templateID int64) (int64, error)
 	DeleteEmergencyContact(ctx context.Context, contactID int64) error
+	DeleteIncidentCategory(ctx context.Context, categoryID int64) error
 	DeleteOTPRateLimit(ctx context.Context, phone string) error
+	DeletePatrolLocationsBefore(ctx context.Context, recordedAt time.Time) (int64, error)
+	DeletePushSubscriptionByID(ctx context.Context, id int64) error
 	DeleteReport(ctx context.Context, reportID int64) error
+	DeleteReportFlag(ctx context.Context, reportID int64) (int64, error)
 	DeleteReportPhoto(ctx context.Context, arg DeleteReportPhotoParams) error
+	DeleteReportPhotosByReportID(ctx context.Context, reportID int64) error
+	DeleteReportRetentionPolicy(ctx context.Context, policyID int64) error
+	// DeleteSOSAlert removes an alert whose report could not be filed.
+	DeleteSOSAlert(ctx context.Context, alertID int64) error
 	DeleteSchedule(ctx context.Context, scheduleID int64) error
 	DeleteSubscription(ctx context.Context, arg DeleteSubscriptionParams) error
 	DeleteUser(ctx context.Context, userID int64) error
+	DeleteWatchlistEntry(ctx context.Context, entryID int64) (int64, error)
+	DeleteWatchlistPhoto(ctx context.Context, arg DeleteWatchlistPhotoParams) (int64, error)
+	DiscardOutboxItem(ctx context.Context, outboxID int64) (Outbox, error)
+	GetActiveSOSAlertByUser(ctx context.Context, userID int64) (SosAlert, error)
 	GetAllOTPAttemptsInWindow(ctx context.Context, attemptedAt time.Time) ([]GetAllOTPAttemptsInWindowRow, error)
 	GetAllSubscriptions(ctx context.Context) ([]GetAllSubscriptionsRow, error)
 	GetAuditEventStats(ctx context.Context) (GetAuditEventStatsRow, error)
@@ -68,6 +125,8 @@ type Querier interface {
 	// Get all bookings in date range with check-in and report status
 	GetBookingsInDateRange(ctx context.Context, arg GetBookingsInDateRangeParams) ([]GetBookingsInDateRangeRow, error)
 	GetBroadcastByID(ctx context.Context, broadcastID int64) (Broadcast, error)
+	GetBroadcastGroup(ctx context.Context, groupID int64) (BroadcastGroup, error)
+	GetBroadcastTemplate(ctx context.Context, templateID int64) (BroadcastTemplate, error)
 	GetCalendarTokenByHash(ctx context.Context, tokenHash string) (CalendarToken, error)
 	// Keep expired tokens for 30 days for audit
 	GetCalendarTokenStats(ctx context.Context) (GetCalendarTokenStatsRow, error)
@@ -76,23 +135,38 @@ type Querier interface {
 	GetEmergencyContactByID(ctx context.Context, contactID int64) (EmergencyContact, error)
 	GetEmergencyContacts(ctx context.Context) ([]EmergencyContact, error)
 	GetFailedOTPAttemptsInWindow(ctx context.Context, arg GetFailedOTPAttemptsInWindowParams) (int64, error)
+	GetHandoverNoteByBookingID(ctx context.Context, bookingID int64) (HandoverNote, error)
+	GetIncidentCategoryByID(ctx context.Context, categoryID int64) (IncidentCategory, error)
+	GetIncidentEscalationByReportID(ctx context.Context, reportID int64) (IncidentEscalation, error)
 	GetLockedPhones(ctx context.Context) ([]GetLockedPhonesRow, error)
 	// Get member contribution analysis for the past month
 	GetMemberContributions(ctx context.Context) ([]GetMemberContributionsRow, error)
+	GetNextBookingForUser(ctx context.Context, arg GetNextBookingForUserParams) (GetNextBookingForUserRow, error)
+	// Preference columns are NULL for users who have never saved any
+	GetNotificationPreferences(ctx context.Context, userID int64) (GetNotificationPreferencesRow, error)
 	GetOTPAttemptsInWindow(ctx context.Context, arg GetOTPAttemptsInWindowParams) ([]OtpAttempt, error)
 	// OTP Rate Limits Queries
 	GetOTPRateLimit(ctx context.Context, phone string) (OtpRateLimit, error)
+	GetOutboxItemByID(ctx context.Context, outboxID int64) (Outbox, error)
+	GetPatrolLocationSharing(ctx context.Context, bookingID int64) (PatrolLocationSharing, error)
 	GetPendingOutboxItems(ctx context.Context, limit int64) ([]Outbox, error)
 	// Get recent point-earning activities across all users for activity feed
 	GetRecentActivity(ctx context.Context, limit int64) ([]GetRecentActivityRow, error)
 	// Limit to prevent processing too many at once
 	GetRecentOutboxItemsByRecipient(ctx context.Context, arg GetRecentOutboxItemsByRecipientParams) ([]Outbox, error)
 	GetReportByBookingID(ctx context.Context, bookingID sql.NullInt64) (Report, error)
+	GetReportCategoryBreakdown(ctx context.Context, createdAt sql.NullTime) ([]GetReportCategoryBreakdownRow, error)
+	GetReportDuplicateMatch(ctx context.Context, reportID int64) (ReportDuplicateMatch, error)
+	GetReportFlag(ctx context.Context, reportID int64) (ReportFlag, error)
+	GetReportMerge(ctx context.Context, reportID int64) (ReportMerge, error)
 	GetReportPhoto(ctx context.Context, arg GetReportPhotoParams) (ReportPhoto, error)
 	GetReportPhotos(ctx context.Context, reportID int64) ([]ReportPhoto, error)
-	GetReportsForAutoArchiving(ctx context.Context) ([]GetReportsForAutoArchivingRow, error)
+	GetReportRetentionPolicy(ctx context.Context, policyID int64) (ReportRetentionPolicy, error)
+	GetReporterFlagCounts(ctx context.Context, arg GetReporterFlagCountsParams) (GetReporterFlagCountsRow, error)
+	GetSOSAlertByID(ctx context.Context, alertID int64) (SosAlert, error)
 	GetScheduleByID(ctx context.Context, scheduleID int64) (Schedule, error)
 	GetSubscriptionsByUser(ctx context.Context, userID int64) ([]GetSubscriptionsByUserRow, error)
+	GetTip(ctx context.Context, tipID int64) (Tip, error)
 	// Get leaderboard of top users by points
 	GetTopUsers(ctx context.Context, limit int64) ([]GetTopUsersRow, error)
 	// Get leaderboard of top users by shift count
@@ -102,40 +176,123 @@ type Querier interface {
 	GetUserByID(ctx context.Context, userID int64) (GetUserByIDRow, error)
 	GetUserByPhone(ctx context.Context, phone string) (GetUserByPhoneRow, error)
 	GetUserCalendarTokens(ctx context.Context, userID int64) ([]GetUserCalendarTokensRow, error)
+	GetUserEmail(ctx context.Context, userID int64) (GetUserEmailRow, error)
+	GetUserNotificationTarget(ctx context.Context, userID int64) (GetUserNotificationTargetRow, error)
 	// Get a user's current points and shift information
 	GetUserPoints(ctx context.Context, userID int64) (GetUserPointsRow, error)
 	// Get recent points history for a user
 	GetUserPointsHistory(ctx context.Context, arg GetUserPointsHistoryParams) ([]GetUserPointsHistoryRow, error)
+	GetUserPushSubscription(ctx context.Context, arg GetUserPushSubscriptionParams) (PushSubscription, error)
 	// Get a specific user's rank by points
 	GetUserRank(ctx context.Context, userID int64) (int64, error)
 	// Get the number of shifts a user has completed in a specific month
 	GetUserShiftCountForMonth(ctx context.Context, arg GetUserShiftCountForMonthParams) (int64, error)
+	GetWatchlistEntry(ctx context.Context, entryID int64) (WatchlistEntry, error)
+	GetWatchlistPhoto(ctx context.Context, arg GetWatchlistPhotoParams) (WatchlistPhoto, error)
+	LinkEscalationOutboxItem(ctx context.Context, arg LinkEscalationOutboxItemParams) error
 	ListActiveSchedules(ctx context.Context, arg ListActiveSchedulesParams) ([]Schedule, error)
+	ListActiveWatchlistEntries(ctx context.Context) ([]WatchlistEntry, error)
 	ListAllSchedules(ctx context.Context) ([]Schedule, error)
 	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]ListAuditEventsRow, error)
 	ListAuditEventsByActor(ctx context.Context, arg ListAuditEventsByActorParams) ([]ListAuditEventsByActorRow, error)
 	ListAuditEventsByDateRange(ctx context.Context, arg ListAuditEventsByDateRangeParams) ([]ListAuditEventsByDateRangeRow, error)
+	// Full history of a single record, oldest first
+	ListAuditEventsByEntity(ctx context.Context, arg ListAuditEventsByEntityParams) ([]ListAuditEventsByEntityRow, error)
 	ListAuditEventsByTarget(ctx context.Context, arg ListAuditEventsByTargetParams) ([]ListAuditEventsByTargetRow, error)
 	ListAuditEventsByType(ctx context.Context, arg ListAuditEventsByTypeParams) ([]ListAuditEventsByTypeRow, error)
 	ListAuditEventsWithFilters(ctx context.Context, arg ListAuditEventsWithFiltersParams) ([]ListAuditEventsWithFiltersRow, error)
 	ListBookingsByUserID(ctx context.Context, userID int64) ([]Booking, error)
 	ListBookingsByUserIDWithSchedule(ctx context.Context, userID int64) ([]ListBookingsByUserIDWithScheduleRow, error)
+	ListBookingsForExport(ctx context.Context, arg ListBookingsForExportParams) ([]ListBookingsForExportRow, error)
+	// Every filter is optional; users must match all of those that are set
+	ListBroadcastAudience(ctx context.Context, arg ListBroadcastAudienceParams) ([]ListBroadcastAudienceRow, error)
+	ListBroadcastGroupMembers(ctx context.Context, groupID int64) ([]ListBroadcastGroupMembersRow, error)
+	ListBroadcastGroups(ctx context.Context) ([]ListBroadcastGroupsRow, error)
+	ListBroadcastReceipts(ctx context.Context, broadcastID int64) ([]ListBroadcastReceiptsRow, error)
+	ListBroadcastRecipients(ctx context.Context, broadcastID int64) ([]ListBroadcastRecipientsRow, error)
+	ListBroadcastTemplates(ctx context.Context) ([]BroadcastTemplate, error)
 	ListBroadcasts(ctx context.Context) ([]Broadcast, error)
 	ListBroadcastsWithSender(ctx context.Context) ([]ListBroadcastsWithSenderRow, error)
+	ListDeadLetterOutboxItems(ctx context.Context, arg ListDeadLetterOutboxItemsParams) ([]Outbox, error)
+	ListDueRecurringBroadcasts(ctx context.Context) ([]Broadcast, error)
+	ListEscalationMessages(ctx context.Context, escalationID int64) ([]ListEscalationMessagesRow, error)
+	ListIncidentCategories(ctx context.Context) ([]IncidentCategory, error)
+	ListIncomingHandoverNotes(ctx context.Context, arg ListIncomingHandoverNotesParams) ([]ListIncomingHandoverNotesRow, error)
+	ListLivePatrolPositions(ctx context.Context, shiftEnd time.Time) ([]ListLivePatrolPositionsRow, error)
+	ListMergedReports(ctx context.Context, primaryReportID int64) ([]ListMergedReportsRow, error)
+	ListOnDutyBookings(ctx context.Context, arg ListOnDutyBookingsParams) ([]Booking, error)
+	ListPatrolLocationsByBooking(ctx context.Context, bookingID int64) ([]PatrolLocation, error)
 	ListPendingBroadcasts(ctx context.Context) ([]Broadcast, error)
+	// Earlier active reports filed since the given time that have not been merged
+	ListReportDuplicateCandidates(ctx context.Context, arg ListReportDuplicateCandidatesParams) ([]ListReportDuplicateCandidatesRow, error)
+	ListReportRetentionPolicies(ctx context.Context) ([]ReportRetentionPolicy, error)
 	ListReportsByUserID(ctx context.Context, userID sql.NullInt64) ([]Report, error)
+	ListReportsForExport(ctx context.Context, arg ListReportsForExportParams) ([]ListReportsForExportRow, error)
+	ListReportsForRetention(ctx context.Context) ([]ListReportsForRetentionRow, error)
+	ListSOSAcknowledgements(ctx context.Context, alertID int64) ([]ListSOSAcknowledgementsRow, error)
+	ListSOSAlerts(ctx context.Context, arg ListSOSAlertsParams) ([]ListSOSAlertsRow, error)
+	// Unmerged reports detected as likely duplicates of the given report
+	ListSuspectedDuplicatesOfReport(ctx context.Context, duplicateOfReportID int64) ([]ListSuspectedDuplicatesOfReportRow, error)
+	ListTipsByStatus(ctx context.Context, arg ListTipsByStatusParams) ([]Tip, error)
+	ListUserBroadcastReceipts(ctx context.Context, userID int64) ([]ListUserBroadcastReceiptsRow, error)
+	ListUserPushSubscriptions(ctx context.Context, userID int64) ([]PushSubscription, error)
 	ListUsers(ctx context.Context, searchTerm interface{}) ([]ListUsersRow, error)
+	ListWatchlistEntries(ctx context.Context) ([]ListWatchlistEntriesRow, error)
+	ListWatchlistMatchesByEntry(ctx context.Context, entryID int64) ([]ListWatchlistMatchesByEntryRow, error)
+	ListWatchlistPhotos(ctx context.Context, entryID int64) ([]WatchlistPhoto, error)
+	// Keeps the time of the first acknowledgement
+	MarkBroadcastRead(ctx context.Context, arg MarkBroadcastReadParams) (int64, error)
+	MarkBroadcastRecipientResent(ctx context.Context, arg MarkBroadcastRecipientResentParams) error
+	MarkOutboxItemSent(ctx context.Context, arg MarkOutboxItemSentParams) (int64, error)
+	MoveReportPhotos(ctx context.Context, arg MoveReportPhotosParams) (int64, error)
+	// Points reports merged into a report that is itself being merged at the new primary
+	ReassignReportMerges(ctx context.Context, arg ReassignReportMergesParams) error
+	RecordEmailVerificationFailure(ctx context.Context, userID int64) (int64, error)
+	// Returns what the sender needs to decide whether to prune the subscription
+	RecordPushFailure(ctx context.Context, arg RecordPushFailureParams) (RecordPushFailureRow, error)
+	RecordPushSuccess(ctx context.Context, id int64) error
+	// Counts delivered and failed outbox items for broadcasts sent since the given
+	// time. Broadcasts sent before delivery was tracked keep their counts.
+	RefreshBroadcastDeliveryCounts(ctx context.Context, sentAt sql.NullTime) (int64, error)
+	RemoveBroadcastGroupMember(ctx context.Context, arg RemoveBroadcastGroupMemberParams) (int64, error)
+	RequeueOutboxItem(ctx context.Context, arg RequeueOutboxItemParams) (Outbox, error)
+	// Failed items are retried once send_at comes round again
+	RescheduleOutboxItem(ctx context.Context, arg RescheduleOutboxItemParams) (int64, error)
 	ResetOTPRateLimit(ctx context.Context, phone string) error
+	// Only pending tips can be reviewed, so concurrent reviews cannot both succeed
+	ReviewTip(ctx context.Context, arg ReviewTipParams) (Tip, error)
 	RevokeAllUserCalendarTokens(ctx context.Context, userID int64) error
 	RevokeCalendarToken(ctx context.Context, arg RevokeCalendarTokenParams) error
+	// Also records the recipients of broadcasts created before recipients were stored
+	SetBroadcastRecipientOutboxItem(ctx context.Context, arg SetBroadcastRecipientOutboxItemParams) error
 	SetDefaultEmergencyContact(ctx context.Context, contactID int64) error
+	SetOutboxProviderMessage(ctx context.Context, arg SetOutboxProviderMessageParams) error
+	// SetSOSAlertReport links an alert to the report filed for it.
+	SetSOSAlertReport(ctx context.Context, arg SetSOSAlertReportParams) (SosAlert, error)
+	SetUserPendingEmail(ctx context.Context, arg SetUserPendingEmailParams) error
+	SetUserPreferredChannel(ctx context.Context, arg SetUserPreferredChannelParams) error
+	StandDownSOSAlert(ctx context.Context, arg StandDownSOSAlertParams) (SosAlert, error)
+	StartPatrolLocationSharing(ctx context.Context, arg StartPatrolLocationSharingParams) (PatrolLocationSharing, error)
+	StopEndedPatrolLocationSharing(ctx context.Context, arg StopEndedPatrolLocationSharingParams) (int64, error)
+	StopPatrolLocationSharing(ctx context.Context, arg StopPatrolLocationSharingParams) (int64, error)
+	// Drops an item the recipient has opted out of
+	SuppressOutboxItem(ctx context.Context, arg SuppressOutboxItemParams) (int64, error)
 	UnarchiveReport(ctx context.Context, reportID int64) error
 	UpdateBookingCheckIn(ctx context.Context, arg UpdateBookingCheckInParams) (Booking, error)
+	UpdateBookingCheckOut(ctx context.Context, arg UpdateBookingCheckOutParams) (Booking, error)
+	UpdateBroadcastGroup(ctx context.Context, arg UpdateBroadcastGroupParams) (BroadcastGroup, error)
+	// Only broadcasts still waiting to be sent can be rescheduled
+	UpdateBroadcastSchedule(ctx context.Context, arg UpdateBroadcastScheduleParams) (int64, error)
 	UpdateBroadcastStatus(ctx context.Context, arg UpdateBroadcastStatusParams) (Broadcast, error)
+	UpdateBroadcastTemplate(ctx context.Context, arg UpdateBroadcastTemplateParams) (BroadcastTemplate, error)
 	UpdateEmergencyContact(ctx context.Context, arg UpdateEmergencyContactParams) (EmergencyContact, error)
+	UpdateIncidentCategory(ctx context.Context, arg UpdateIncidentCategoryParams) (IncidentCategory, error)
 	UpdateOTPRateLimit(ctx context.Context, arg UpdateOTPRateLimitParams) error
+	// Delivered and failed are final, so a late intermediate report cannot overwrite them
+	UpdateOutboxDeliveryStatus(ctx context.Context, arg UpdateOutboxDeliveryStatusParams) (int64, error)
 	UpdateOutboxItemStatus(ctx context.Context, arg UpdateOutboxItemStatusParams) (Outbox, error)
 	UpdateReportPhotoCount(ctx context.Context, reportID int64) error
+	UpdateReportRetentionPolicy(ctx context.Context, arg UpdateReportRetentionPolicyParams) (ReportRetentionPolicy, error)
 	UpdateSchedule(ctx context.Context, arg UpdateScheduleParams) (Schedule, error)
 	UpdateTokenAccess(ctx context.Context, tokenHash string) error
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateUserRow, error)
@@ -143,6 +300,10 @@ type Querier interface {
 	UpdateUserShiftCount(ctx context.Context, userID int64) error
 	// Update user's total points (should be called after AwardPoints)
 	UpdateUserTotalPoints(ctx context.Context, arg UpdateUserTotalPointsParams) error
+	UpdateWatchlistEntry(ctx context.Context, arg UpdateWatchlistEntryParams) (WatchlistEntry, error)
+	UpsertHandoverNote(ctx context.Context, arg UpsertHandoverNoteParams) (HandoverNote, error)
+	UpsertNotificationPreferences(ctx context.Context, arg UpsertNotificationPreferencesParams) error
+	UpsertReportFlag(ctx context.Context, arg UpsertReportFlagParams) (ReportFlag, error)
 	UpsertSubscription(ctx context.Context, arg UpsertSubscriptionParams) error
 	ValidateCalendarToken(ctx context.Context, arg ValidateCalendarTokenParams) (ValidateCalendarTokenRow, error)
 }
@@ -24,6 +24,9 @@ SELECT
     r.longitude,
     r.gps_accuracy,
     r.gps_timestamp,
+    r.category_id,
+    r.category_fields,
+    COALESCE(ic.name, '') as category_name,
     COALESCE(u.name, '') as user_name,
     u.phone as user_phone,
     COALESCE(b.schedule_id, 0) as schedule_id,
@@ -34,27 +37,31 @@ FROM reports r
 JOIN users u ON r.user_id = u.user_id
 LEFT JOIN bookings b ON r.booking_id = b.booking_id
 LEFT JOIN schedules s ON b.schedule_id = s.schedule_id
+LEFT JOIN incident_categories ic ON r.category_id = ic.category_id
 WHERE r.report_id = ?
 `
 
 type AdminGetReportWithContextRow struct {
-	ReportID     int64           `json:"report_id"`
-	BookingID    sql.NullInt64   `json:"booking_id"`
-	UserID       sql.NullInt64   `json:"user_id"`
-	Severity     int64           `json:"severity"`
-	Message      sql.NullString  `json:"message"`
-	CreatedAt    sql.NullTime    `json:"created_at"`
-	ArchivedAt   sql.NullTime    `json:"archived_at"`
-	Latitude     sql.NullFloat64 `json:"latitude"`
-	Longitude    sql.NullFloat64 `json:"longitude"`
-	GpsAccuracy  sql.NullFloat64 `json:"gps_accuracy"`
-	GpsTimestamp sql.NullTime    `json:"gps_timestamp"`
-	UserName     string          `json:"user_name"`
-	UserPhone    string          `json:"user_phone"`
-	ScheduleID   int64           `json:"schedule_id"`
-	ScheduleName string          `json:"schedule_name"`
-	ShiftStart   interface{}     `json:"shift_start"`
-	ShiftEnd     interface{}     `json:"shift_end"`
+	ReportID       int64           `json:"report_id"`
+	BookingID      sql.NullInt64   `json:"booking_id"`
+	UserID         sql.NullInt64   `json:"user_id"`
+	Severity       int64           `json:"severity"`
+	Message        sql.NullString  `json:"message"`
+	CreatedAt      sql.NullTime    `json:"created_at"`
+	ArchivedAt     sql.NullTime    `json:"archived_at"`
+	Latitude       sql.NullFloat64 `json:"latitude"`
+	Longitude      sql.NullFloat64 `json:"longitude"`
+	GpsAccuracy    sql.NullFloat64 `json:"gps_accuracy"`
+	GpsTimestamp   sql.NullTime    `json:"gps_timestamp"`
+	CategoryID     sql.NullInt64   `json:"category_id"`
+	CategoryFields sql.NullString  `json:"category_fields"`
+	CategoryName   string          `json:"category_name"`
+	UserName       string          `json:"user_name"`
+	UserPhone      string          `json:"user_phone"`
+	ScheduleID     int64           `json:"schedule_id"`
+	ScheduleName   string          `json:"schedule_name"`
+	ShiftStart     interface{}     `json:"shift_start"`
+	ShiftEnd       interface{}     `json:"shift_end"`
 }
 
 func (q *Queries) AdminGetReportWithContext(ctx context.Context, reportID int64) (AdminGetReportWithContextRow, error) {
@@ -72,6 +79,9 @@ func (q *Queries) AdminGetReportWithContext(ctx context.Context, reportID int64)
 		&i.Longitude,
 		&i.GpsAccuracy,
 		&i.GpsTimestamp,
+		&i.CategoryID,
+		&i.CategoryFields,
+		&i.CategoryName,
 		&i.UserName,
 		&i.UserPhone,
 		&i.ScheduleID,
@@ -95,6 +105,9 @@ SELECT
     r.longitude,
     r.gps_accuracy,
     r.gps_timestamp,
+    r.category_id,
+    r.category_fields,
+    COALESCE(ic.name, '') as category_name,
     COALESCE(u.name, '') as user_name,
     u.phone as user_phone,
     COALESCE(b.schedule_id, 0) as schedule_id,
@@ -105,28 +118,32 @@ FROM reports r
 JOIN users u ON r.user_id = u.user_id
 LEFT JOIN bookings b ON r.booking_id = b.booking_id
 LEFT JOIN schedules s ON b.schedule_id = s.schedule_id
+LEFT JOIN incident_categories ic ON r.category_id = ic.category_id
 WHERE r.archived_at IS NOT NULL
 ORDER BY r.archived_at DESC
 `
 
 type AdminListArchivedReportsWithContextRow struct {
-	ReportID     int64           `json:"report_id"`
-	BookingID    sql.NullInt64   `json:"booking_id"`
-	UserID       sql.NullInt64   `json:"user_id"`
-	Severity     int64           `json:"severity"`
-	Message      sql.NullString  `json:"message"`
-	CreatedAt    sql.NullTime    `json:"created_at"`
-	ArchivedAt   sql.NullTime    `json:"archived_at"`
-	Latitude     sql.NullFloat64 `json:"latitude"`
-	Longitude    sql.NullFloat64 `json:"longitude"`
-	GpsAccuracy  sql.NullFloat64 `json:"gps_accuracy"`
-	GpsTimestamp sql.NullTime    `json:"gps_timestamp"`
-	UserName     string          `json:"user_name"`
-	UserPhone    string          `json:"user_phone"`
-	ScheduleID   int64           `json:"schedule_id"`
-	ScheduleName string          `json:"schedule_name"`
-	ShiftStart   interface{}     `json:"shift_start"`
-	ShiftEnd     interface{}     `json:"shift_end"`
+	ReportID       int64           `json:"report_id"`
+	BookingID      sql.NullInt64   `json:"booking_id"`
+	UserID         sql.NullInt64   `json:"user_id"`
+	Severity       int64           `json:"severity"`
+	Message        sql.NullString  `json:"message"`
+	CreatedAt      sql.NullTime    `json:"created_at"`
+	ArchivedAt     sql.NullTime    `json:"archived_at"`
+	Latitude       sql.NullFloat64 `json:"latitude"`
+	Longitude      sql.NullFloat64 `json:"longitude"`
+	GpsAccuracy    sql.NullFloat64 `json:"gps_accuracy"`
+	GpsTimestamp   sql.NullTime    `json:"gps_timestamp"`
+	CategoryID     sql.NullInt64   `json:"category_id"`
+	CategoryFields sql.NullString  `json:"category_fields"`
+	CategoryName   string          `json:"category_name"`
+	UserName       string          `json:"user_name"`
+	UserPhone      string          `json:"user_phone"`
+	ScheduleID     int64           `json:"schedule_id"`
+	ScheduleName   string          `json:"schedule_name"`
+	ShiftStart     interface{}     `json:"shift_start"`
+	ShiftEnd       interface{}     `json:"shift_end"`
 }
 
 func (q *Queries) AdminListArchivedReportsWithContext(ctx context.Context) ([]AdminListArchivedReportsWithContextRow, error) {
@@ -150,6 +167,9 @@ func (q *Queries) AdminListArchivedReportsWithContext(ctx context.Context) ([]Ad
 			&i.Longitude,
 			&i.GpsAccuracy,
 			&i.GpsTimestamp,
+			&i.CategoryID,
+			&i.CategoryFields,
+			&i.CategoryName,
 			&i.UserName,
 			&i.UserPhone,
 			&i.ScheduleID,
@@ -183,6 +203,9 @@ SELECT
     r.longitude,
     r.gps_accuracy,
     r.gps_timestamp,
+    r.category_id,
+    r.category_fields,
+    COALESCE(ic.name, '') as category_name,
     COALESCE(u.name, '') as user_name,
     u.phone as user_phone,
     COALESCE(b.schedule_id, 0) as schedule_id,
@@ -193,28 +216,32 @@ FROM reports r
 JOIN users u ON r.user_id = u.user_id
 LEFT JOIN bookings b ON r.booking_id = b.booking_id
 LEFT JOIN schedules s ON b.schedule_id = s.schedule_id
+LEFT JOIN incident_categories ic ON r.category_id = ic.category_id
 WHERE r.archived_at IS NULL
 ORDER BY r.created_at DESC
 `
 
 type AdminListReportsWithContextRow struct {
-	ReportID     int64           `json:"report_id"`
-	BookingID    sql.NullInt64   `json:"booking_id"`
-	UserID       sql.NullInt64   `json:"user_id"`
-	Severity     int64           `json:"severity"`
-	Message      sql.NullString  `json:"message"`
-	CreatedAt    sql.NullTime    `json:"created_at"`
-	ArchivedAt   sql.NullTime    `json:"archived_at"`
-	Latitude     sql.NullFloat64 `json:"latitude"`
-	Longitude    sql.NullFloat64 `json:"longitude"`
-	GpsAccuracy  sql.NullFloat64 `json:"gps_accuracy"`
-	GpsTimestamp sql.NullTime    `json:"gps_timestamp"`
-	UserName     string          `json:"user_name"`
-	UserPhone    string          `json:"user_phone"`
-	ScheduleID   int64           `json:"schedule_id"`
-	ScheduleName string          `json:"schedule_name"`
-	ShiftStart   interface{}     `json:"shift_start"`
-	ShiftEnd     interface{}     `json:"shift_end"`
+	ReportID       int64           `json:"report_id"`
+	BookingID      sql.NullInt64   `json:"booking_id"`
+	UserID         sql.NullInt64   `json:"user_id"`
+	Severity       int64           `json:"severity"`
+	Message        sql.NullString  `json:"message"`
+	CreatedAt      sql.NullTime    `json:"created_at"`
+	ArchivedAt     sql.NullTime    `json:"archived_at"`
+	Latitude       sql.NullFloat64 `json:"latitude"`
+	Longitude      sql.NullFloat64 `json:"longitude"`
+	GpsAccuracy    sql.NullFloat64 `json:"gps_accuracy"`
+	GpsTimestamp   sql.NullTime    `json:"gps_timestamp"`
+	CategoryID     sql.NullInt64   `json:"category_id"`
+	CategoryFields sql.NullString  `json:"category_fields"`
+	CategoryName   string          `json:"category_name"`
+	UserName       string          `json:"user_name"`
+	UserPhone      string          `json:"user_phone"`
+	ScheduleID     int64           `json:"schedule_id"`
+	ScheduleName   string          `json:"schedule_name"`
+	ShiftStart     interface{}     `json:"shift_start"`
+	ShiftEnd       interface{}     `json:"shift_end"`
 }
 
 func (q *Queries) AdminListReportsWithContext(ctx context.Context) ([]AdminListReportsWithContextRow, error) {
@@ -238,6 +265,9 @@ func (q *Queries) AdminListReportsWithContext(ctx context.Context) ([]AdminListR
 			&i.Longitude,
 			&i.GpsAccuracy,
 			&i.GpsTimestamp,
+			&i.CategoryID,
+			&i.CategoryFields,
+			&i.CategoryName,
 			&i.UserName,
 			&i.UserPhone,
 			&i.ScheduleID,
@@ -258,6 +288,24 @@ func (q *Queries) AdminListReportsWithContext(ctx context.Context) ([]AdminListR
 	return items, nil
 }
 
+const anonymiseReport = `-- name: AnonymiseReport :exec
+UPDATE reports
+SET message = NULL,
+    latitude = NULL,
+    longitude = NULL,
+    gps_accuracy = NULL,
+    gps_timestamp = NULL,
+    category_fields = NULL,
+    photo_count = 0,
+    anonymised_at = CURRENT_TIMESTAMP
+WHERE report_id = ? AND anonymised_at IS NULL
+`
+
+func (q *Queries) AnonymiseReport(ctx context.Context, reportID int64) error {
+	_, err := q.db.ExecContext(ctx, anonymiseReport, reportID)
+	return err
+}
+
 const archiveReport = `-- name: ArchiveReport :exec
 UPDATE reports 
 SET archived_at = CURRENT_TIMESTAMP 
@@ -299,6 +347,8 @@ INSERT INTO reports (
     longitude,
     gps_accuracy,
     gps_timestamp,
+    category_id,
+    category_fields,
     archived_at
 ) VALUES (
     ?,
@@ -308,19 +358,23 @@ INSERT INTO reports (
     ?,
     ?,
     ?,
+    ?,
+    ?,
     NULL
 )
-RETURNING report_id, booking_id, user_id, severity, message, created_at, latitude, longitude, gps_accuracy, gps_timestamp, archived_at, photo_count
+RETURNING report_id, booking_id, user_id, severity, message, created_at, latitude, longitude, gps_accuracy, gps_timestamp, archived_at, photo_count, category_id, category_fields, anonymised_at
 `
 
 type CreateOffShiftReportParams struct {
-	UserID       sql.NullInt64   `json:"user_id"`
-	Severity     int64           `json:"severity"`
-	Message      sql.NullString  `json:"message"`
-	Latitude     sql.NullFloat64 `json:"latitude"`
-	Longitude    sql.NullFloat64 `json:"longitude"`
-	GpsAccuracy  sql.NullFloat64 `json:"gps_accuracy"`
-	GpsTimestamp sql.NullTime    `json:"gps_timestamp"`
+	UserID         sql.NullInt64   `json:"user_id"`
+	Severity       int64           `json:"severity"`
+	Message        sql.NullString  `json:"message"`
+	Latitude       sql.NullFloat64 `json:"latitude"`
+	Longitude      sql.NullFloat64 `json:"longitude"`
+	GpsAccuracy    sql.NullFloat64 `json:"gps_accuracy"`
+	GpsTimestamp   sql.NullTime    `json:"gps_timestamp"`
+	CategoryID     sql.NullInt64   `json:"category_id"`
+	CategoryFields sql.NullString  `json:"category_fields"`
 }
 
 func (q *Queries) CreateOffShiftReport(ctx context.Context, arg CreateOffShiftReportParams) (Report, error) {
@@ -332,6 +386,8 @@ func (q *Queries) CreateOffShiftReport(ctx context.Context, arg CreateOffShiftRe
 		arg.Longitude,
 		arg.GpsAccuracy,
 		arg.GpsTimestamp,
+		arg.CategoryID,
+		arg.CategoryFields,
 	)
 	var i Report
 	err := row.Scan(
@@ -347,6 +403,9 @@ func (q *Queries) CreateOffShiftReport(ctx context.Context, arg CreateOffShiftRe
 		&i.GpsTimestamp,
 		&i.ArchivedAt,
 		&i.PhotoCount,
+		&i.CategoryID,
+		&i.CategoryFields,
+		&i.AnonymisedAt,
 	)
 	return i, err
 }
@@ -361,6 +420,8 @@ INSERT INTO reports (
     longitude,
     gps_accuracy,
     gps_timestamp,
+    category_id,
+    category_fields,
     archived_at
 ) VALUES (
     ?,
@@ -371,20 +432,24 @@ INSERT INTO reports (
     ?,
     ?,
     ?,
+    ?,
+    ?,
     NULL
 )
-RETURNING report_id, booking_id, user_id, severity, message, created_at, latitude, longitude, gps_accuracy, gps_timestamp, archived_at, photo_count
+RETURNING report_id, booking_id, user_id, severity, message, created_at, latitude, longitude, gps_accuracy, gps_timestamp, archived_at, photo_count, category_id, category_fields, anonymised_at
 `
 
 type CreateReportParams struct {
-	BookingID    sql.NullInt64   `json:"booking_id"`
-	UserID       sql.NullInt64   `json:"user_id"`
-	Severity     int64           `json:"severity"`
-	Message      sql.NullString  `json:"message"`
-	Latitude     sql.NullFloat64 `json:"latitude"`
-	Longitude    sql.NullFloat64 `json:"longitude"`
-	GpsAccuracy  sql.NullFloat64 `json:"gps_accuracy"`
-	GpsTimestamp sql.NullTime    `json:"gps_timestamp"`
+	BookingID      sql.NullInt64   `json:"booking_id"`
+	UserID         sql.NullInt64   `json:"user_id"`
+	Severity       int64           `json:"severity"`
+	Message        sql.NullString  `json:"message"`
+	Latitude       sql.NullFloat64 `json:"latitude"`
+	Longitude      sql.NullFloat64 `json:"longitude"`
+	GpsAccuracy    sql.NullFloat64 `json:"gps_accuracy"`
+	GpsTimestamp   sql.NullTime    `json:"gps_timestamp"`
+	CategoryID     sql.NullInt64   `json:"category_id"`
+	CategoryFields sql.NullString  `json:"category_fields"`
 }
 
 func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) (Report, error) {
@@ -397,6 +462,8 @@ func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) (Rep
 		arg.Longitude,
 		arg.GpsAccuracy,
 		arg.GpsTimestamp,
+		arg.CategoryID,
+		arg.CategoryFields,
 	)
 	var i Report
 	err := row.Scan(
@@ -412,6 +479,9 @@ func (q *Queries) CreateReport(ctx context.Context, arg CreateReportParams) (Rep
 		&i.GpsTimestamp,
 		&i.ArchivedAt,
 		&i.PhotoCount,
+		&i.CategoryID,
+		&i.CategoryFields,
+		&i.AnonymisedAt,
 	)
 	return i, err
 }
@@ -508,8 +578,18 @@ func (q *Queries) DeleteReportPhoto(ctx context.Context, arg DeleteReportPhotoPa
 	return err
 }
 
+const deleteReportPhotosByReportID = `-- name: DeleteReportPhotosByReportID :exec
+DELETE FROM report_photos
+WHERE report_id = ?
+`
+
+func (q *Queries) DeleteReportPhotosByReportID(ctx context.Context, reportID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteReportPhotosByReportID, reportID)
+	return err
+}
+
 const getReportByBookingID = `-- name: GetReportByBookingID :one
-SELECT report_id, booking_id, user_id, severity, message, created_at, latitude, longitude, gps_accuracy, gps_timestamp, archived_at, photo_count FROM reports
+SELECT report_id, booking_id, user_id, severity, message, created_at, latitude, longitude, gps_accuracy, gps_timestamp, archived_at, photo_count, category_id, category_fields, anonymised_at FROM reports
 WHERE booking_id = ? AND archived_at IS NULL
 `
 
@@ -529,6 +609,9 @@ func (q *Queries) GetReportByBookingID(ctx context.Context, bookingID sql.NullIn
 		&i.GpsTimestamp,
 		&i.ArchivedAt,
 		&i.PhotoCount,
+		&i.CategoryID,
+		&i.CategoryFields,
+		&i.AnonymisedAt,
 	)
 	return i, err
 }
@@ -607,36 +690,39 @@ func (q *Queries) GetReportPhotos(ctx context.Context, reportID int64) ([]Report
 	return items, nil
 }
 
-const getReportsForAutoArchiving = `-- name: GetReportsForAutoArchiving :many
-SELECT report_id, severity, created_at
-FROM reports 
-WHERE archived_at IS NULL 
-AND (
-    -- Normal reports older than 1 month
-    (severity = 0 AND created_at < datetime('now', '-1 month'))
-    OR
-    -- Suspicion reports older than 1 year  
-    (severity = 1 AND created_at < datetime('now', '-1 year'))
-    -- Incident reports (severity = 2) are never auto-archived
-)
+const listReportsByUserID = `-- name: ListReportsByUserID :many
+SELECT r.report_id, r.booking_id, r.user_id, r.severity, r.message, r.created_at, r.latitude, r.longitude, r.gps_accuracy, r.gps_timestamp, r.archived_at, r.photo_count, r.category_id, r.category_fields, r.anonymised_at 
+FROM reports r
+WHERE r.user_id = ? AND r.archived_at IS NULL
+ORDER BY r.created_at DESC
 `
 
-type GetReportsForAutoArchivingRow struct {
-	ReportID  int64        `json:"report_id"`
-	Severity  int64        `json:"severity"`
-	CreatedAt sql.NullTime `json:"created_at"`
-}
-
-func (q *Queries) GetReportsForAutoArchiving(ctx context.Context) ([]GetReportsForAutoArchivingRow, error) {
-	rows, err := q.db.QueryContext(ctx, getReportsForAutoArchiving)
+func (q *Queries) ListReportsByUserID(ctx context.Context, userID sql.NullInt64) ([]Report, error) {
+	rows, err := q.db.QueryContext(ctx, listReportsByUserID, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []GetReportsForAutoArchivingRow{}
+	items := []Report{}
 	for rows.Next() {
-		var i GetReportsForAutoArchivingRow
-		if err := rows.Scan(&i.ReportID, &i.Severity, &i.CreatedAt); err != nil {
+		var i Report
+		if err := rows.Scan(
+			&i.ReportID,
+			&i.BookingID,
+			&i.UserID,
+			&i.Severity,
+			&i.Message,
+			&i.CreatedAt,
+			&i.Latitude,
+			&i.Longitude,
+			&i.GpsAccuracy,
+			&i.GpsTimestamp,
+			&i.ArchivedAt,
+			&i.PhotoCount,
+			&i.CategoryID,
+			&i.CategoryFields,
+			&i.AnonymisedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -650,35 +736,36 @@ func (q *Queries) GetReportsForAutoArchiving(ctx context.Context) ([]GetReportsF
 	return items, nil
 }
 
-const listReportsByUserID = `-- name: ListReportsByUserID :many
-SELECT r.report_id, r.booking_id, r.user_id, r.severity, r.message, r.created_at, r.latitude, r.longitude, r.gps_accuracy, r.gps_timestamp, r.archived_at, r.photo_count 
-FROM reports r
-WHERE r.user_id = ? AND r.archived_at IS NULL
-ORDER BY r.created_at DESC
+const listReportsForRetention = `-- name: ListReportsForRetention :many
+SELECT report_id, severity, category_id, created_at, archived_at
+FROM reports
+WHERE anonymised_at IS NULL
+ORDER BY report_id ASC
 `
 
-func (q *Queries) ListReportsByUserID(ctx context.Context, userID sql.NullInt64) ([]Report, error) {
-	rows, err := q.db.QueryContext(ctx, listReportsByUserID, userID)
+type ListReportsForRetentionRow struct {
+	ReportID   int64         `json:"report_id"`
+	Severity   int64         `json:"severity"`
+	CategoryID sql.NullInt64 `json:"category_id"`
+	CreatedAt  sql.NullTime  `json:"created_at"`
+	ArchivedAt sql.NullTime  `json:"archived_at"`
+}
+
+func (q *Queries) ListReportsForRetention(ctx context.Context) ([]ListReportsForRetentionRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReportsForRetention)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []Report{}
+	items := []ListReportsForRetentionRow{}
 	for rows.Next() {
-		var i Report
+		var i ListReportsForRetentionRow
 		if err := rows.Scan(
 			&i.ReportID,
-			&i.BookingID,
-			&i.UserID,
 			&i.Severity,
-			&i.Message,
+			&i.CategoryID,
 			&i.CreatedAt,
-			&i.Latitude,
-			&i.Longitude,
-			&i.GpsAccuracy,
-			&i.GpsTimestamp,
 			&i.ArchivedAt,
-			&i.PhotoCount,
 		); err != nil {
 			return nil, err
 		}
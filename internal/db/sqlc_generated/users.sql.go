@@ -118,6 +118,32 @@ func (q *Queries) GetUserByID(ctx context.Context, userID int64) (GetUserByIDRow
 	return i, err
 }
 
+const getUserNotificationTarget = `-- name: GetUserNotificationTarget :one
+SELECT user_id, phone, name, preferred_channel, email FROM users
+WHERE user_id = ?
+`
+
+type GetUserNotificationTargetRow struct {
+	UserID           int64          `json:"user_id"`
+	Phone            string         `json:"phone"`
+	Name             sql.NullString `json:"name"`
+	PreferredChannel string         `json:"preferred_channel"`
+	Email            sql.NullString `json:"email"`
+}
+
+func (q *Queries) GetUserNotificationTarget(ctx context.Context, userID int64) (GetUserNotificationTargetRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserNotificationTarget, userID)
+	var i GetUserNotificationTargetRow
+	err := row.Scan(
+		&i.UserID,
+		&i.Phone,
+		&i.Name,
+		&i.PreferredChannel,
+		&i.Email,
+	)
+	return i, err
+}
+
 const getUserByPhone = `-- name: GetUserByPhone :one
 SELECT user_id, phone, name, created_at, role FROM users
 WHERE phone = ?
@@ -230,3 +256,126 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateU
 	)
 	return i, err
 }
+
+const getUserEmail = `-- name: GetUserEmail :one
+SELECT user_id, email, email_verified_at, pending_email, email_verification_code_hash, email_verification_expires_at, email_verification_attempts FROM users
+WHERE user_id = ?
+`
+
+type GetUserEmailRow struct {
+	UserID                     int64          `json:"user_id"`
+	Email                      sql.NullString `json:"email"`
+	EmailVerifiedAt            sql.NullTime   `json:"email_verified_at"`
+	PendingEmail               sql.NullString `json:"pending_email"`
+	EmailVerificationCodeHash  sql.NullString `json:"email_verification_code_hash"`
+	EmailVerificationExpiresAt sql.NullTime   `json:"email_verification_expires_at"`
+	EmailVerificationAttempts  int64          `json:"email_verification_attempts"`
+}
+
+func (q *Queries) GetUserEmail(ctx context.Context, userID int64) (GetUserEmailRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserEmail, userID)
+	var i GetUserEmailRow
+	err := row.Scan(
+		&i.UserID,
+		&i.Email,
+		&i.EmailVerifiedAt,
+		&i.PendingEmail,
+		&i.EmailVerificationCodeHash,
+		&i.EmailVerificationExpiresAt,
+		&i.EmailVerificationAttempts,
+	)
+	return i, err
+}
+
+const setUserPendingEmail = `-- name: SetUserPendingEmail :exec
+UPDATE users
+SET pending_email = ?,
+    email_verification_code_hash = ?,
+    email_verification_expires_at = ?,
+    email_verification_attempts = 0
+WHERE user_id = ?
+`
+
+type SetUserPendingEmailParams struct {
+	PendingEmail               sql.NullString `json:"pending_email"`
+	EmailVerificationCodeHash  sql.NullString `json:"email_verification_code_hash"`
+	EmailVerificationExpiresAt sql.NullTime   `json:"email_verification_expires_at"`
+	UserID                     int64          `json:"user_id"`
+}
+
+func (q *Queries) SetUserPendingEmail(ctx context.Context, arg SetUserPendingEmailParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPendingEmail,
+		arg.PendingEmail,
+		arg.EmailVerificationCodeHash,
+		arg.EmailVerificationExpiresAt,
+		arg.UserID,
+	)
+	return err
+}
+
+const confirmUserEmail = `-- name: ConfirmUserEmail :execrows
+UPDATE users
+SET email = pending_email,
+    email_verified_at = CURRENT_TIMESTAMP,
+    pending_email = NULL,
+    email_verification_code_hash = NULL,
+    email_verification_expires_at = NULL,
+    email_verification_attempts = 0
+WHERE user_id = ?
+  AND pending_email IS NOT NULL
+`
+
+// Promotes the pending address once its code has been checked
+func (q *Queries) ConfirmUserEmail(ctx context.Context, userID int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, confirmUserEmail, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recordEmailVerificationFailure = `-- name: RecordEmailVerificationFailure :one
+UPDATE users
+SET email_verification_attempts = email_verification_attempts + 1
+WHERE user_id = ?
+RETURNING email_verification_attempts
+`
+
+func (q *Queries) RecordEmailVerificationFailure(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, recordEmailVerificationFailure, userID)
+	var email_verification_attempts int64
+	err := row.Scan(&email_verification_attempts)
+	return email_verification_attempts, err
+}
+
+const clearUserEmail = `-- name: ClearUserEmail :exec
+UPDATE users
+SET email = NULL,
+    email_verified_at = NULL,
+    pending_email = NULL,
+    email_verification_code_hash = NULL,
+    email_verification_expires_at = NULL,
+    email_verification_attempts = 0
+WHERE user_id = ?
+`
+
+func (q *Queries) ClearUserEmail(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, clearUserEmail, userID)
+	return err
+}
+
+const setUserPreferredChannel = `-- name: SetUserPreferredChannel :exec
+UPDATE users
+SET preferred_channel = ?
+WHERE user_id = ?
+`
+
+type SetUserPreferredChannelParams struct {
+	PreferredChannel string `json:"preferred_channel"`
+	UserID           int64  `json:"user_id"`
+}
+
+func (q *Queries) SetUserPreferredChannel(ctx context.Context, arg SetUserPreferredChannelParams) error {
+	_, err := q.db.ExecContext(ctx, setUserPreferredChannel, arg.PreferredChannel, arg.UserID)
+	return err
+}
@@ -2,9 +2,17 @@ package outbox
 
 import (
 	"context"
+	cryptorand "crypto/rand"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog" // For converting UserID from string if necessary, though it should be int64 from DB
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"night-owls-go/internal/config" // For config values
@@ -17,104 +25,525 @@ import (
 // 	maxRetryCount    = 3  // Moved to config
 // )
 
+// Fallbacks for retry backoff when the config leaves it unset
+const (
+	defaultRetryBaseDelay = 30 * time.Second
+	defaultRetryMaxDelay  = time.Hour
+)
+
+// defaultLeaseDuration is how long claimed items are held when the config leaves it unset
+const defaultLeaseDuration = 5 * time.Minute
+
+// maxLastErrorLength caps the error text kept on a failed outbox item
+const maxLastErrorLength = 500
+
 // DispatcherService processes pending messages from the outbox.
 type DispatcherService struct {
-	querier    db.Querier
-	smsSender  MessageSender // Renaming 'sender' to 'smsSender' for clarity
-	pushSender *service.PushSender
-	logger     *slog.Logger
-	cfg        *config.Config
+	querier     db.Querier
+	smsSender   MessageSender // Renaming 'sender' to 'smsSender' for clarity
+	pushSender  *service.PushSender
+	emailSender EmailSender // nil while email is disabled
+	preferences *service.NotificationPreferencesService
+	handlers    *HandlerRegistry
+	logger      *slog.Logger
+	cfg         *config.Config
 }
 
 // NewDispatcherService creates a new DispatcherService.
-func NewDispatcherService(querier db.Querier, smsSender MessageSender, pushSender *service.PushSender, logger *slog.Logger, cfg *config.Config) *DispatcherService {
+func NewDispatcherService(querier db.Querier, smsSender MessageSender, pushSender *service.PushSender, emailSender EmailSender, preferences *service.NotificationPreferencesService, logger *slog.Logger, cfg *config.Config) *DispatcherService {
+	logger = logger.With("service", "OutboxDispatcher")
+
+	loc, err := time.LoadLocation(cfg.NotificationTimezone)
+	if err != nil {
+		logger.Warn("Invalid notification timezone, using UTC", "timezone", cfg.NotificationTimezone, "error", err)
+		loc = time.UTC
+	}
+
 	return &DispatcherService{
-		querier:    querier,
-		smsSender:  smsSender,
-		pushSender: pushSender,
-		logger:     logger.With("service", "OutboxDispatcher"),
-		cfg:        cfg,
+		querier:     querier,
+		smsSender:   smsSender,
+		pushSender:  pushSender,
+		emailSender: emailSender,
+		preferences: preferences,
+		handlers:    DefaultHandlerRegistry(loc),
+		logger:      logger,
+		cfg:         cfg,
 	}
 }
 
-// ProcessPendingOutboxItems processes pending outbox items and dispatches them via the appropriate sender.
+// RegisterHandler adds or replaces the handler for an outbox message type.
+func (s *DispatcherService) RegisterHandler(messageType string, handler MessageHandler) {
+	s.handlers.Register(messageType, handler)
+}
+
+// ProcessPendingOutboxItems claims due outbox items in batches and dispatches
+// them via the appropriate sender. Each batch is leased to this run, so
+// overlapping runs, or several server instances, never send the same item
+// twice; items held by a run that died are picked up once the lease expires.
 func (s *DispatcherService) ProcessPendingOutboxItems(ctx context.Context) (int, int) {
-	pendingItems, err := s.querier.GetPendingOutboxItems(ctx, int64(s.cfg.OutboxBatchSize))
-	if err != nil {
-		s.logger.ErrorContext(ctx, "Failed to get pending outbox items", "error", err)
-		return 0, 1 // Increment errCount for fetch failure
+	processedCount := 0
+	errCount := 0
+
+	for ctx.Err() == nil {
+		leaseToken, err := newLeaseToken()
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to create outbox lease token", "error", err)
+			return processedCount, errCount + 1
+		}
+
+		items, err := s.querier.ClaimOutboxItems(ctx, db.ClaimOutboxItemsParams{
+			LeaseToken:  sql.NullString{String: leaseToken, Valid: true},
+			LeasedUntil: sql.NullTime{Time: time.Now().UTC().Add(s.leaseDuration()), Valid: true},
+			Limit:       int64(s.cfg.OutboxBatchSize),
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to claim pending outbox items", "error", err)
+			return processedCount, errCount + 1 // Increment errCount for fetch failure
+		}
+
+		if len(items) == 0 {
+			if processedCount+errCount == 0 {
+				s.logger.InfoContext(ctx, "No pending outbox items to process")
+			}
+			break
+		}
+
+		s.logger.InfoContext(ctx, "Processing pending outbox items", "count", len(items))
+		processed, errs := s.dispatchItems(ctx, items, leaseToken)
+		processedCount += processed
+		errCount += errs
+
+		if len(items) < s.cfg.OutboxBatchSize {
+			break
+		}
 	}
+	return processedCount, errCount
+}
 
-	if len(pendingItems) == 0 {
-		s.logger.InfoContext(ctx, "No pending outbox items to process")
+// DispatchOutboxItems delivers the given outbox items straight away instead of
+// waiting for the next scheduled run. Items that are no longer pending, or are
+// already leased to another run, are skipped. It implements
+// service.OutboxDispatcher.
+func (s *DispatcherService) DispatchOutboxItems(ctx context.Context, outboxIDs []int64) (int, int) {
+	if len(outboxIDs) == 0 {
 		return 0, 0
 	}
 
-	s.logger.InfoContext(ctx, "Processing pending outbox items", "count", len(pendingItems))
+	leaseToken, err := newLeaseToken()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create outbox lease token", "error", err)
+		return 0, 1
+	}
+
+	items, err := s.querier.ClaimOutboxItemsByID(ctx, db.ClaimOutboxItemsByIDParams{
+		LeaseToken:  sql.NullString{String: leaseToken, Valid: true},
+		LeasedUntil: sql.NullTime{Time: time.Now().UTC().Add(s.leaseDuration()), Valid: true},
+		OutboxIds:   outboxIDs,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to claim outbox items for immediate dispatch", "outbox_ids", outboxIDs, "error", err)
+		return 0, 1
+	}
 
-	processedCount := 0
-	errCount := 0
+	return s.dispatchItems(ctx, items, leaseToken)
+}
 
-	for _, item := range pendingItems {
-		sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-		var dispatchErr error
+// dispatchItems sends claimed items from a pool of workers and records the
+// outcome of each. It returns once every item has been handled.
+func (s *DispatcherService) dispatchItems(ctx context.Context, items []db.Outbox, leaseToken string) (int, int) {
+	// RETURNING gives no order guarantee, so send oldest first
+	sort.Slice(items, func(i, j int) bool { return items[i].OutboxID < items[j].OutboxID })
 
-		switch item.MessageType {
-		case "sms":
-			if s.smsSender != nil {
-				dispatchErr = s.smsSender.Send(item.Recipient, item.MessageType, item.Payload.String)
-			} else {
-				dispatchErr = errors.New("smsSender not configured")
-				s.logger.ErrorContext(sendCtx, dispatchErr.Error(), "outbox_id", item.OutboxID)
+	workers := s.cfg.OutboxWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	var processedCount, errCount atomic.Int64
+	queue := make(chan db.Outbox)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range queue {
+				processed, errs := s.dispatchItem(ctx, item, leaseToken)
+				processedCount.Add(int64(processed))
+				errCount.Add(int64(errs))
 			}
-		case "push":
-			if s.pushSender != nil {
-				if !item.UserID.Valid {
-					dispatchErr = errors.New("user_id is null for push notification")
-					s.logger.ErrorContext(sendCtx, dispatchErr.Error(), "outbox_id", item.OutboxID)
-				} else {
-					dispatchErr = s.pushSender.Send(sendCtx, item.UserID.Int64, []byte(item.Payload.String), 604800) // Use 1 week TTL
-					if dispatchErr != nil {
-						s.logger.ErrorContext(sendCtx, "PushSender failed", "outbox_id", item.OutboxID, "error", dispatchErr)
-					}
-				}
-			} else {
-				dispatchErr = errors.New("pushSender not configured")
+		}()
+	}
+	for _, item := range items {
+		queue <- item
+	}
+	close(queue)
+	wg.Wait()
+
+	return int(processedCount.Load()), int(errCount.Load())
+}
+
+// dispatchItem sends one claimed item and records the outcome, releasing its
+// lease. It returns the processed and error counts for the item; an item held
+// back by the user's notification preferences counts as neither.
+func (s *DispatcherService) dispatchItem(ctx context.Context, item db.Outbox, leaseToken string) (int, int) {
+	held, err := s.holdForPreferences(ctx, item, leaseToken)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to hold back outbox item", "outbox_id", item.OutboxID, "error", err)
+		return 0, 1
+	}
+	if held {
+		return 0, 0
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	var dispatchErr error
+
+	switch item.MessageType {
+	case "sms":
+		if provider, ok := s.smsSender.(SMSProvider); ok {
+			dispatchErr = s.sendViaProvider(sendCtx, provider, item.OutboxID, item.Recipient, item.Payload.String)
+		} else if s.smsSender != nil {
+			dispatchErr = s.smsSender.Send(item.Recipient, item.MessageType, item.Payload.String)
+		} else {
+			dispatchErr = errors.New("smsSender not configured")
+			s.logger.ErrorContext(sendCtx, dispatchErr.Error(), "outbox_id", item.OutboxID)
+		}
+	case "push":
+		if s.pushSender != nil {
+			if !item.UserID.Valid {
+				dispatchErr = errors.New("user_id is null for push notification")
 				s.logger.ErrorContext(sendCtx, dispatchErr.Error(), "outbox_id", item.OutboxID)
+			} else {
+				dispatchErr = s.pushSender.Send(sendCtx, item.UserID.Int64, []byte(item.Payload.String), 604800) // Use 1 week TTL
+				if dispatchErr != nil {
+					s.logger.ErrorContext(sendCtx, "PushSender failed", "outbox_id", item.OutboxID, "error", dispatchErr)
+				}
 			}
-		default:
+		} else {
+			dispatchErr = errors.New("pushSender not configured")
+			s.logger.ErrorContext(sendCtx, dispatchErr.Error(), "outbox_id", item.OutboxID)
+		}
+	case service.OutboxMessageEmail, service.OutboxMessageEmailVerification:
+		if s.emailSender != nil {
+			dispatchErr = s.emailSender.Send(item.Recipient, item.MessageType, item.Payload.String)
+		} else {
+			dispatchErr = errors.New("emailSender not configured")
+			s.logger.ErrorContext(sendCtx, dispatchErr.Error(), "outbox_id", item.OutboxID)
+		}
+	default:
+		if handler, ok := s.handlers.Lookup(item.MessageType); ok {
+			dispatchErr = s.dispatchRendered(sendCtx, handler, item)
+		} else {
 			dispatchErr = errors.New("unknown message type: " + item.MessageType)
-			s.logger.WarnContext(sendCtx, "Unknown message type in outbox", "outbox_id", item.OutboxID, "message_type", item.MessageType)
+			s.logger.ErrorContext(sendCtx, "No handler for outbox message type", "outbox_id", item.OutboxID, "message_type", item.MessageType)
+		}
+	}
+	cancel()
+
+	if dispatchErr != nil {
+		s.logger.ErrorContext(ctx, "Failed to dispatch message from outbox", "outbox_id", item.OutboxID, "message_type", item.MessageType, "error", dispatchErr)
+		if err := s.recordFailure(ctx, item, leaseToken, dispatchErr); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record outbox item failure", "outbox_id", item.OutboxID, "error", err)
+			return 0, 2
+		}
+		return 0, 1
+	}
+
+	s.logger.InfoContext(ctx, "Successfully dispatched message from outbox", "outbox_id", item.OutboxID, "message_type", item.MessageType)
+	rows, updateErr := s.querier.MarkOutboxItemSent(ctx, db.MarkOutboxItemSentParams{
+		SentAt:     sql.NullTime{Time: time.Now(), Valid: true},
+		OutboxID:   item.OutboxID,
+		LeaseToken: sql.NullString{String: leaseToken, Valid: true},
+	})
+	if updateErr != nil {
+		s.logger.ErrorContext(ctx, "Failed to update outbox item status", "outbox_id", item.OutboxID, "error", updateErr)
+		return 1, 1
+	}
+	if rows == 0 {
+		s.logger.WarnContext(ctx, "Outbox item was cancelled, or its lease expired, before it was marked sent", "outbox_id", item.OutboxID)
+	}
+	return 1, 0
+}
+
+// holdForPreferences suppresses an item in a category the user has opted out
+// of, or defers it to the end of the user's quiet hours. Items that were due
+// before the quiet hours began are still sent, as are transactional and urgent
+// items. It reports whether the item was held back.
+func (s *DispatcherService) holdForPreferences(ctx context.Context, item db.Outbox, leaseToken string) (bool, error) {
+	category := item.Category.String
+	if category == "" || category == service.NotificationCategoryUrgent || !item.UserID.Valid {
+		return false, nil
+	}
+	prefs, err := s.preferences.Get(ctx, item.UserID.Int64)
+	if err != nil {
+		// Sending is better than silently dropping when preferences cannot be read
+		s.logger.WarnContext(ctx, "Failed to get notification preferences, sending anyway", "outbox_id", item.OutboxID, "user_id", item.UserID.Int64, "error", err)
+		return false, nil
+	}
+
+	if !prefs.Allows(category) {
+		rows, err := s.querier.SuppressOutboxItem(ctx, db.SuppressOutboxItemParams{
+			LastError:  sql.NullString{String: "user opted out of " + category, Valid: true},
+			OutboxID:   item.OutboxID,
+			LeaseToken: sql.NullString{String: leaseToken, Valid: true},
+		})
+		if err != nil {
+			return true, err
+		}
+		if rows > 0 {
+			s.logger.InfoContext(ctx, "Suppressed outbox item the user opted out of", "outbox_id", item.OutboxID, "user_id", item.UserID.Int64, "category", category)
 		}
-		cancel()
+		return true, nil
+	}
+
+	start, end, quiet := prefs.QuietPeriod(time.Now())
+	if !quiet || item.SendAt.Before(start) {
+		return false, nil
+	}
+	rows, err := s.querier.DeferOutboxItem(ctx, db.DeferOutboxItemParams{
+		SendAt:     end.UTC(),
+		OutboxID:   item.OutboxID,
+		LeaseToken: sql.NullString{String: leaseToken, Valid: true},
+	})
+	if err != nil {
+		return true, err
+	}
+	if rows > 0 {
+		s.logger.InfoContext(ctx, "Deferred outbox item until quiet hours end", "outbox_id", item.OutboxID, "user_id", item.UserID.Int64, "send_at", end)
+	}
+	return true, nil
+}
+
+// leaseDuration returns how long claimed items are held by a dispatcher run.
+func (s *DispatcherService) leaseDuration() time.Duration {
+	if s.cfg.OutboxLeaseDuration <= 0 {
+		return defaultLeaseDuration
+	}
+	return s.cfg.OutboxLeaseDuration
+}
+
+// newLeaseToken returns a random token identifying one dispatcher run's claim.
+func newLeaseToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := cryptorand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
 
-		updateParams := db.UpdateOutboxItemStatusParams{
+// recordFailure schedules a failed item for another attempt after a backoff,
+// or dead-letters it once it has used up its retries.
+func (s *DispatcherService) recordFailure(ctx context.Context, item db.Outbox, leaseToken string, dispatchErr error) error {
+	retryCount := item.RetryCount.Int64 + 1
+	lastError := dispatchErr.Error()
+	if len(lastError) > maxLastErrorLength {
+		lastError = lastError[:maxLastErrorLength]
+	}
+
+	var rows int64
+	var err error
+	if retryCount >= int64(s.maxRetries(item.MessageType)) {
+		s.logger.WarnContext(ctx, "Message reached max retry count, moving to dead letters", "outbox_id", item.OutboxID, "message_type", item.MessageType, "retry_count", retryCount)
+		rows, err = s.querier.DeadLetterOutboxItem(ctx, db.DeadLetterOutboxItemParams{
+			RetryCount: sql.NullInt64{Int64: retryCount, Valid: true},
+			LastError:  sql.NullString{String: lastError, Valid: true},
+			OutboxID:   item.OutboxID,
+			LeaseToken: sql.NullString{String: leaseToken, Valid: true},
+		})
+	} else {
+		sendAt := time.Now().UTC().Add(s.retryDelay(retryCount))
+		s.logger.InfoContext(ctx, "Scheduled outbox item for retry", "outbox_id", item.OutboxID, "retry_count", retryCount, "send_at", sendAt)
+		rows, err = s.querier.RescheduleOutboxItem(ctx, db.RescheduleOutboxItemParams{
+			RetryCount: sql.NullInt64{Int64: retryCount, Valid: true},
+			SendAt:     sendAt,
+			LastError:  sql.NullString{String: lastError, Valid: true},
 			OutboxID:   item.OutboxID,
-			RetryCount: item.RetryCount,
-		}
-
-		if dispatchErr != nil {
-			s.logger.ErrorContext(ctx, "Failed to dispatch message from outbox", "outbox_id", item.OutboxID, "message_type", item.MessageType, "error", dispatchErr)
-			errCount++
-			updateParams.Status = "failed"
-			updateParams.RetryCount.Int64 = item.RetryCount.Int64 + 1
-			updateParams.RetryCount.Valid = true
-			if updateParams.RetryCount.Int64 >= int64(s.cfg.OutboxMaxRetries) {
-				s.logger.WarnContext(ctx, "Message reached max retry count, marking as permanently failed", "outbox_id", item.OutboxID)
-				updateParams.Status = "permanently_failed"
+			LeaseToken: sql.NullString{String: leaseToken, Valid: true},
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		s.logger.WarnContext(ctx, "Outbox item was cancelled, or its lease expired, before the failure was recorded", "outbox_id", item.OutboxID)
+	}
+	return nil
+}
+
+// maxRetries returns the number of attempts allowed for a message type.
+func (s *DispatcherService) maxRetries(messageType string) int {
+	if limit, ok := s.cfg.OutboxRetryLimits[messageType]; ok {
+		return limit
+	}
+	return s.cfg.OutboxMaxRetries
+}
+
+// retryDelay returns the backoff before a retry: the base delay doubled for
+// each earlier retry and capped at the maximum. The upper half of the delay
+// is random so that items which failed together do not retry together.
+func (s *DispatcherService) retryDelay(retryCount int64) time.Duration {
+	baseDelay := s.cfg.OutboxRetryBaseDelay
+	if baseDelay <= 0 {
+		baseDelay = defaultRetryBaseDelay
+	}
+	maxDelay := s.cfg.OutboxRetryMaxDelay
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+
+	delay := baseDelay
+	for i := int64(1); i < retryCount && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int64N(int64(delay-half)+1)) // #nosec G404 - jitter does not need a secure source
+}
+
+// sendViaProvider sends an SMS for an outbox item through a gateway and
+// records the gateway's message ID on the item so delivery reports can be
+// matched to it.
+func (s *DispatcherService) sendViaProvider(ctx context.Context, provider SMSProvider, outboxID int64, to, body string) error {
+	messageID, err := provider.SendSMS(ctx, to, body)
+	if err != nil {
+		return err
+	}
+	if err := s.querier.SetOutboxProviderMessage(ctx, db.SetOutboxProviderMessageParams{
+		Provider:          sql.NullString{String: provider.Name(), Valid: true},
+		ProviderMessageID: sql.NullString{String: messageID, Valid: true},
+		OutboxID:          outboxID,
+	}); err != nil {
+		// The message has gone out, so this must not count as a failed send
+		s.logger.ErrorContext(ctx, "Failed to record SMS provider message ID", "outbox_id", outboxID, "provider", provider.Name(), "error", err)
+	}
+	return nil
+}
+
+// dispatchRendered renders an item with its handler and sends it on the first
+// channel that can reach the user, starting with their preferred channel.
+func (s *DispatcherService) dispatchRendered(ctx context.Context, handler MessageHandler, item db.Outbox) error {
+	message, err := handler.Render(item)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to render outbox message", "outbox_id", item.OutboxID, "message_type", item.MessageType, "error", err)
+		return err
+	}
+
+	// Items without a user, such as OTPs for new numbers, go to the recipient
+	phone := item.Recipient
+	email := ""
+	preferred := ""
+	userID, hasUser := outboxUserID(item, message)
+	if hasUser {
+		target, err := s.querier.GetUserNotificationTarget(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get notification target for user %d: %w", userID, err)
+		}
+		phone = target.Phone
+		email = target.Email.String
+		preferred = target.PreferredChannel
+	}
+
+	for _, channel := range channelOrder(preferred, handler.Channels()) {
+		switch channel {
+		case ChannelPush:
+			if s.pushSender == nil || !hasUser {
+				continue
 			}
-		} else {
-			s.logger.InfoContext(ctx, "Successfully dispatched message from outbox", "outbox_id", item.OutboxID, "message_type", item.MessageType)
-			processedCount++
-			updateParams.Status = "sent"
-			updateParams.SentAt = sql.NullTime{Time: time.Now(), Valid: true}
+			subs, err := s.querier.GetSubscriptionsByUser(ctx, userID)
+			if err != nil {
+				return fmt.Errorf("failed to get push subscriptions: %w", err)
+			}
+			if len(subs) == 0 {
+				continue
+			}
+			payload, err := json.Marshal(map[string]interface{}{
+				"type":  message.Type,
+				"title": message.Title,
+				"body":  message.Body,
+				"data":  message.Data,
+			})
+			if err != nil {
+				return err
+			}
+			return s.pushSender.Send(ctx, userID, payload, 604800)
+		case ChannelSMS:
+			if s.smsSender == nil || phone == "" {
+				continue
+			}
+			if provider, ok := s.smsSender.(SMSProvider); ok {
+				return s.sendViaProvider(ctx, provider, item.OutboxID, phone, message.Body)
+			}
+			return s.smsSender.Send(phone, item.MessageType, message.Body)
+		case ChannelEmail:
+			if s.emailSender == nil || email == "" {
+				continue
+			}
+			return s.emailSender.SendEmail(ctx, email, EmailMessage{Subject: message.Title, Body: message.Body})
 		}
+	}
+	return fmt.Errorf("no delivery channel available for %s", item.MessageType)
+}
 
-		_, updateErr := s.querier.UpdateOutboxItemStatus(ctx, updateParams)
-		if updateErr != nil {
-			s.logger.ErrorContext(ctx, "Failed to update outbox item status", "outbox_id", item.OutboxID, "error", updateErr)
-			errCount++
+// outboxUserID returns the user an item is for, falling back to the payload's
+// user_id for items enqueued without one.
+func outboxUserID(item db.Outbox, message RenderedMessage) (int64, bool) {
+	if item.UserID.Valid {
+		return item.UserID.Int64, true
+	}
+	if number, ok := message.Data["user_id"].(json.Number); ok {
+		if userID, err := number.Int64(); err == nil {
+			return userID, true
 		}
 	}
-	return processedCount, errCount
+	return 0, false
+}
+
+// channelOrder puts the preferred channel first when the handler allows it.
+func channelOrder(preferred string, allowed []string) []string {
+	ordered := make([]string, 0, len(allowed))
+	for _, channel := range allowed {
+		if channel == preferred {
+			ordered = append(ordered, channel)
+		}
+	}
+	for _, channel := range allowed {
+		if channel != preferred {
+			ordered = append(ordered, channel)
+		}
+	}
+	return ordered
+}
+
+// ApplyDeliveryReports records delivery reports from an SMS provider on the
+// matching outbox items. Reports for unknown messages, and intermediate
+// reports arriving after a final one, are ignored. It returns the number of
+// items updated.
+func (s *DispatcherService) ApplyDeliveryReports(ctx context.Context, provider string, reports []DeliveryReport) (int, error) {
+	updated := 0
+	for _, report := range reports {
+		rows, err := s.querier.UpdateOutboxDeliveryStatus(ctx, db.UpdateOutboxDeliveryStatusParams{
+			DeliveryStatus:    sql.NullString{String: report.Status, Valid: true},
+			DeliveryError:     sql.NullString{String: report.Error, Valid: report.Error != ""},
+			Provider:          sql.NullString{String: provider, Valid: true},
+			ProviderMessageID: sql.NullString{String: report.MessageID, Valid: true},
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record SMS delivery report", "provider", provider, "message_id", report.MessageID, "error", err)
+			return updated, err
+		}
+		if rows == 0 {
+			s.logger.DebugContext(ctx, "Ignored SMS delivery report", "provider", provider, "message_id", report.MessageID, "status", report.Status)
+			continue
+		}
+		updated += int(rows)
+		if report.Status == DeliveryStatusFailed {
+			s.logger.WarnContext(ctx, "SMS delivery failed", "provider", provider, "message_id", report.MessageID, "error", report.Error)
+		}
+	}
+	return updated, nil
 }
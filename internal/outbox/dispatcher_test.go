@@ -0,0 +1,309 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/config"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingSender is a MessageSender whose gateway is always down.
+type failingSender struct{}
+
+func (failingSender) Send(recipient, messageType, payload string) error {
+	return errors.New("gateway unavailable")
+}
+
+func TestDispatcher_RetryDelay(t *testing.T) {
+	dispatcher := &DispatcherService{cfg: &config.Config{OutboxRetryBaseDelay: 10 * time.Second, OutboxRetryMaxDelay: time.Minute}}
+
+	for retryCount, expected := range map[int64]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: time.Minute, 10: time.Minute} {
+		for i := 0; i < 20; i++ {
+			delay := dispatcher.retryDelay(retryCount)
+			assert.GreaterOrEqual(t, delay, expected/2, "retry %d", retryCount)
+			assert.LessOrEqual(t, delay, expected, "retry %d", retryCount)
+		}
+	}
+}
+
+func TestDispatcher_BackoffAndDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	dbConn := newOutboxTestDB(t)
+	querier := db.New(dbConn)
+	cfg := &config.Config{
+		OutboxBatchSize:      10,
+		OutboxMaxRetries:     2,
+		OutboxRetryBaseDelay: time.Minute,
+		OutboxRetryMaxDelay:  time.Hour,
+		OutboxRetryLimits:    map[string]int{"push": 1},
+	}
+	dispatcher := NewDispatcherService(querier, failingSender{}, nil, nil, service.NewNotificationPreferencesService(querier, cfg, discardLogger()), discardLogger(), cfg)
+
+	enqueue := func(messageType string) db.Outbox {
+		item, err := querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
+			MessageType: messageType,
+			Recipient:   "+27820000001",
+			Payload:     sql.NullString{String: "hello", Valid: true},
+			SendAt:      time.Now().UTC().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+		return item
+	}
+	// makeDue pulls a rescheduled item back so the next run picks it up
+	makeDue := func(item db.Outbox) {
+		_, err := dbConn.Exec(`UPDATE outbox SET send_at = ? WHERE outbox_id = ?`, time.Now().UTC().Add(-time.Minute), item.OutboxID)
+		require.NoError(t, err)
+	}
+
+	sms := enqueue("sms")
+	push := enqueue("push")
+
+	before := time.Now().UTC()
+	_, errs := dispatcher.ProcessPendingOutboxItems(ctx)
+	assert.Equal(t, 2, errs)
+
+	retried, err := querier.GetOutboxItemByID(ctx, sms.OutboxID)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", retried.Status)
+	assert.Equal(t, int64(1), retried.RetryCount.Int64)
+	assert.Equal(t, "gateway unavailable", retried.LastError.String)
+	assert.True(t, retried.SendAt.After(before.Add(29*time.Second)), "send_at is pushed forward by the backoff")
+
+	// The push limit of 1 overrides the global limit of 2
+	deadPush, err := querier.GetOutboxItemByID(ctx, push.OutboxID)
+	require.NoError(t, err)
+	assert.Equal(t, "permanently_failed", deadPush.Status)
+	assert.True(t, deadPush.DeadLetteredAt.Valid)
+
+	// Nothing is due until the backoff has passed
+	processed, errs := dispatcher.ProcessPendingOutboxItems(ctx)
+	assert.Equal(t, 0, processed+errs)
+
+	makeDue(retried)
+	_, errs = dispatcher.ProcessPendingOutboxItems(ctx)
+	assert.Equal(t, 1, errs)
+
+	deadSMS, err := querier.GetOutboxItemByID(ctx, sms.OutboxID)
+	require.NoError(t, err)
+	assert.Equal(t, "permanently_failed", deadSMS.Status)
+	assert.Equal(t, int64(2), deadSMS.RetryCount.Int64)
+
+	deadLetters, err := querier.ListDeadLetterOutboxItems(ctx, db.ListDeadLetterOutboxItemsParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, deadLetters, 2)
+}
+
+func TestDispatcher_ConcurrentRunsSendEachItemOnce(t *testing.T) {
+	ctx := context.Background()
+	dbConn := newOutboxTestDB(t)
+	querier := db.New(dbConn)
+	sender := &recordingSender{}
+	cfg := &config.Config{OutboxBatchSize: 5, OutboxMaxRetries: 3, OutboxWorkers: 4, OutboxLeaseDuration: time.Minute}
+	dispatcher := NewDispatcherService(querier, sender, nil, nil, service.NewNotificationPreferencesService(querier, cfg, discardLogger()), discardLogger(), cfg)
+
+	for i := 0; i < 23; i++ {
+		_, err := querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
+			MessageType: "sms",
+			Recipient:   fmt.Sprintf("+278200000%02d", i),
+			Payload:     sql.NullString{String: "hello", Valid: true},
+			SendAt:      time.Now().UTC().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	total := 0
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			processed, errs := dispatcher.ProcessPendingOutboxItems(ctx)
+			assert.Equal(t, 0, errs)
+			mu.Lock()
+			total += processed
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 23, total)
+	recipients := make(map[string]int)
+	for _, message := range sender.sent {
+		recipients[message.recipient]++
+	}
+	assert.Len(t, recipients, 23)
+	for recipient, count := range recipients {
+		assert.Equal(t, 1, count, "%s was sent more than once", recipient)
+	}
+
+	var unsent int
+	require.NoError(t, dbConn.QueryRow(`SELECT COUNT(*) FROM outbox WHERE status != 'sent' OR lease_token IS NOT NULL`).Scan(&unsent))
+	assert.Zero(t, unsent)
+}
+
+func TestDispatcher_Leases(t *testing.T) {
+	ctx := context.Background()
+	dbConn := newOutboxTestDB(t)
+	querier := db.New(dbConn)
+	sender := &recordingSender{}
+	cfg := &config.Config{OutboxBatchSize: 10, OutboxMaxRetries: 3}
+	dispatcher := NewDispatcherService(querier, sender, nil, nil, service.NewNotificationPreferencesService(querier, cfg, discardLogger()), discardLogger(), cfg)
+
+	item, err := querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
+		MessageType: "sms",
+		Recipient:   "+27820000001",
+		Payload:     sql.NullString{String: "hello", Valid: true},
+		SendAt:      time.Now().UTC().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	lease := func(until time.Time) {
+		_, err := dbConn.Exec(`UPDATE outbox SET lease_token = 'crashed-run', leased_until = ? WHERE outbox_id = ?`, until, item.OutboxID)
+		require.NoError(t, err)
+	}
+
+	// Held by another run
+	lease(time.Now().UTC().Add(time.Minute))
+	processed, errs := dispatcher.ProcessPendingOutboxItems(ctx)
+	assert.Equal(t, 0, processed+errs)
+	processed, errs = dispatcher.DispatchOutboxItems(ctx, []int64{item.OutboxID})
+	assert.Equal(t, 0, processed+errs)
+	assert.Empty(t, sender.sent)
+
+	// The other run died and its lease has run out
+	lease(time.Now().UTC().Add(-time.Minute))
+	processed, errs = dispatcher.ProcessPendingOutboxItems(ctx)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, 0, errs)
+	assert.Len(t, sender.sent, 1)
+
+	sent, err := querier.GetOutboxItemByID(ctx, item.OutboxID)
+	require.NoError(t, err)
+	assert.Equal(t, "sent", sent.Status)
+	assert.False(t, sent.LeaseToken.Valid)
+
+	// The dead run cannot overwrite the outcome once it has lost the lease
+	rows, err := querier.RescheduleOutboxItem(ctx, db.RescheduleOutboxItemParams{
+		RetryCount: sql.NullInt64{Int64: 1, Valid: true},
+		SendAt:     time.Now().UTC(),
+		OutboxID:   item.OutboxID,
+		LeaseToken: sql.NullString{String: "crashed-run", Valid: true},
+	})
+	require.NoError(t, err)
+	assert.Zero(t, rows)
+}
+
+// cancellingSender cancels the item it is sending, as an acknowledged
+// escalation does when its SMS is already on the way.
+type cancellingSender struct {
+	dbConn *sql.DB
+	err    error
+}
+
+func (c cancellingSender) Send(recipient, messageType, payload string) error {
+	if _, err := c.dbConn.Exec(`UPDATE outbox SET status = 'cancelled' WHERE recipient = ?`, recipient); err != nil {
+		return err
+	}
+	return c.err
+}
+
+func TestDispatcher_CancelledWhileSending(t *testing.T) {
+	for name, sendErr := range map[string]error{"sent": nil, "failed": errors.New("gateway unavailable")} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			dbConn := newOutboxTestDB(t)
+			querier := db.New(dbConn)
+			cfg := &config.Config{OutboxBatchSize: 10, OutboxMaxRetries: 3}
+			dispatcher := NewDispatcherService(querier, cancellingSender{dbConn: dbConn, err: sendErr}, nil, nil, service.NewNotificationPreferencesService(querier, cfg, discardLogger()), discardLogger(), cfg)
+
+			item, err := querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
+				MessageType: "sms",
+				Recipient:   "+27820000001",
+				Payload:     sql.NullString{String: "hello", Valid: true},
+				SendAt:      time.Now().UTC().Add(-time.Minute),
+			})
+			require.NoError(t, err)
+
+			dispatcher.ProcessPendingOutboxItems(ctx)
+
+			cancelled, err := querier.GetOutboxItemByID(ctx, item.OutboxID)
+			require.NoError(t, err)
+			assert.Equal(t, "cancelled", cancelled.Status, "the outcome of the send does not undo the cancellation")
+		})
+	}
+}
+
+func TestDispatcher_NotificationPreferences(t *testing.T) {
+	ctx := context.Background()
+	dbConn := newOutboxTestDB(t)
+	querier := db.New(dbConn)
+	sender := &recordingSender{}
+	cfg := &config.Config{OutboxBatchSize: 10, OutboxMaxRetries: 3, NotificationTimezone: "UTC"}
+	dispatcher := NewDispatcherService(querier, sender, nil, nil, service.NewNotificationPreferencesService(querier, cfg, discardLogger()), discardLogger(), cfg)
+
+	now := time.Now().UTC()
+	createUser := func(phone string) int64 {
+		result, err := dbConn.Exec(`INSERT INTO users (phone, name) VALUES (?, 'Owl')`, phone)
+		require.NoError(t, err)
+		userID, err := result.LastInsertId()
+		require.NoError(t, err)
+		return userID
+	}
+	optedOut := createUser("+27820000001")
+	quiet := createUser("+27820000002")
+	defaults := createUser("+27820000003")
+	require.NoError(t, querier.UpsertNotificationPreferences(ctx, db.UpsertNotificationPreferencesParams{
+		UserID: optedOut, Reminders: true, Broadcasts: false, CoverageAlerts: true, ReportUpdates: true,
+	}))
+	require.NoError(t, querier.UpsertNotificationPreferences(ctx, db.UpsertNotificationPreferencesParams{
+		UserID: quiet, Reminders: true, Broadcasts: true, CoverageAlerts: true, ReportUpdates: true,
+		QuietHoursStart: sql.NullString{String: now.Add(-time.Hour).Format("15:04"), Valid: true},
+		QuietHoursEnd:   sql.NullString{String: now.Add(time.Hour).Format("15:04"), Valid: true},
+	}))
+
+	enqueue := func(userID int64, category string, sendAt time.Time) db.Outbox {
+		item, err := querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
+			MessageType: "sms",
+			Recipient:   fmt.Sprintf("user-%d", userID),
+			Payload:     sql.NullString{String: category, Valid: true},
+			UserID:      sql.NullInt64{Int64: userID, Valid: true},
+			SendAt:      sendAt,
+			Category:    sql.NullString{String: category, Valid: category != ""},
+		})
+		require.NoError(t, err)
+		return item
+	}
+	suppressed := enqueue(optedOut, "broadcasts", now.Add(-time.Minute))
+	deferred := enqueue(quiet, "broadcasts", now.Add(-time.Minute))
+	enqueue(quiet, "broadcasts", now.Add(-2*time.Hour)) // Due before quiet hours began
+	enqueue(defaults, "broadcasts", now.Add(-time.Minute))
+	enqueue(optedOut, "urgent", now.Add(-time.Minute))
+	enqueue(quiet, "urgent", now.Add(-time.Minute))
+	enqueue(quiet, "", now.Add(-time.Minute))
+
+	processed, errs := dispatcher.ProcessPendingOutboxItems(ctx)
+	assert.Equal(t, 5, processed)
+	assert.Equal(t, 0, errs)
+	assert.Len(t, sender.sent, 5)
+
+	item, err := querier.GetOutboxItemByID(ctx, suppressed.OutboxID)
+	require.NoError(t, err)
+	assert.Equal(t, "suppressed", item.Status)
+	assert.False(t, item.LeaseToken.Valid)
+
+	item, err = querier.GetOutboxItemByID(ctx, deferred.OutboxID)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", item.Status)
+	assert.False(t, item.LeaseToken.Valid)
+	assert.WithinDuration(t, now.Add(time.Hour), item.SendAt, time.Minute, "held until quiet hours end")
+}
@@ -0,0 +1,345 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"night-owls-go/internal/config"
+	"night-owls-go/internal/service"
+)
+
+// SMTP connection security modes
+const (
+	SMTPTLSStartTLS = "starttls"
+	SMTPTLSImplicit = "tls"
+	SMTPTLSNone     = "none"
+)
+
+//go:embed templates/email/*.tmpl
+var emailTemplateFS embed.FS
+
+// EmailMessage is the content of an email before it is laid out. Body is
+// plain text; blank lines separate paragraphs.
+type EmailMessage struct {
+	Subject string `json:"subject"`
+	Title   string `json:"title,omitempty"` // Heading in the email; defaults to the subject
+	Body    string `json:"body"`
+}
+
+// EmailSender is a MessageSender that delivers email. Send takes an "email"
+// outbox payload, which is a JSON EmailMessage.
+type EmailSender interface {
+	MessageSender
+	SendEmail(ctx context.Context, to string, message EmailMessage) error
+}
+
+// SMTPConfig configures the SMTP email sender.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // Authentication is skipped when empty
+	Password string
+	From     string
+	TLS      string // One of the SMTPTLS constants
+	Timeout  time.Duration
+}
+
+// SMTPEmailSender sends email through an SMTP server, with an HTML and a
+// plain-text version of every message.
+type SMTPEmailSender struct {
+	cfg          SMTPConfig
+	from         *mail.Address
+	htmlTemplate *htmltemplate.Template
+	textTemplate *texttemplate.Template
+	logger       *slog.Logger
+}
+
+// NewSMTPEmailSender creates a new SMTPEmailSender.
+func NewSMTPEmailSender(cfg SMTPConfig, logger *slog.Logger) (*SMTPEmailSender, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP from address %q: %w", cfg.From, err)
+	}
+	switch cfg.TLS {
+	case "":
+		cfg.TLS = SMTPTLSStartTLS
+	case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP TLS mode %q", cfg.TLS)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	htmlTemplate, err := htmltemplate.ParseFS(emailTemplateFS, "templates/email/layout.html.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML email template: %w", err)
+	}
+	textTemplate, err := texttemplate.ParseFS(emailTemplateFS, "templates/email/layout.txt.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse text email template: %w", err)
+	}
+
+	return &SMTPEmailSender{
+		cfg:          cfg,
+		from:         from,
+		htmlTemplate: htmlTemplate,
+		textTemplate: textTemplate,
+		logger:       logger.With("component", "SMTPEmailSender"),
+	}, nil
+}
+
+// NewEmailSender returns an SMTP email sender, or nil when cfg.SMTPHost is
+// not set and email is disabled.
+func NewEmailSender(cfg *config.Config, logger *slog.Logger) (EmailSender, error) {
+	if cfg.SMTPHost == "" {
+		return nil, nil
+	}
+	if cfg.SMTPFrom == "" {
+		return nil, errors.New("email needs SMTP_FROM when SMTP_HOST is set")
+	}
+	sender, err := NewSMTPEmailSender(SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+		TLS:      cfg.SMTPTLS,
+		Timeout:  cfg.SMTPTimeout,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+	return sender, nil
+}
+
+// Send implements MessageSender for "email" and EMAIL_VERIFICATION outbox items.
+func (s *SMTPEmailSender) Send(recipient, messageType, payload string) error {
+	var message EmailMessage
+	switch messageType {
+	case service.OutboxMessageEmailVerification:
+		var data struct {
+			Code             string `json:"code"`
+			ExpiresInMinutes int    `json:"expires_in_minutes"`
+		}
+		if err := json.Unmarshal([]byte(payload), &data); err != nil {
+			return fmt.Errorf("invalid email verification payload: %w", err)
+		}
+		if data.Code == "" {
+			return errors.New("email verification payload has no code")
+		}
+		message = EmailMessage{
+			Subject: "Confirm your email address",
+			Body: fmt.Sprintf("Your Night Owls confirmation code is %s. It expires in %d minutes.\n\n"+
+				"If you did not ask to add this address to Night Owls, you can ignore this email.", data.Code, data.ExpiresInMinutes),
+		}
+	default:
+		if err := json.Unmarshal([]byte(payload), &message); err != nil {
+			return fmt.Errorf("invalid email payload: %w", err)
+		}
+	}
+	return s.SendEmail(context.Background(), recipient, message)
+}
+
+// SendEmail lays out the message with the email templates and sends it.
+func (s *SMTPEmailSender) SendEmail(ctx context.Context, to string, message EmailMessage) error {
+	if message.Subject == "" || message.Body == "" {
+		return errors.New("email needs a subject and a body")
+	}
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid email recipient %q: %w", to, err)
+	}
+
+	body, err := s.buildMessage(recipient, message)
+	if err != nil {
+		return err
+	}
+	if err := s.deliver(ctx, recipient.Address, body); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Email handed to SMTP server", "host", s.cfg.Host, "subject", message.Subject)
+	return nil
+}
+
+// buildMessage renders a multipart/alternative message with text and HTML parts.
+func (s *SMTPEmailSender) buildMessage(recipient *mail.Address, message EmailMessage) ([]byte, error) {
+	title := message.Title
+	if title == "" {
+		title = message.Subject
+	}
+	data := struct {
+		Subject    string
+		Title      string
+		Body       string
+		Paragraphs []string
+	}{
+		Subject:    message.Subject,
+		Title:      title,
+		Body:       strings.TrimSpace(message.Body),
+		Paragraphs: emailParagraphs(message.Body),
+	}
+
+	var text, html bytes.Buffer
+	if err := s.textTemplate.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render text email: %w", err)
+	}
+	if err := s.htmlTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render HTML email: %w", err)
+	}
+
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+	messageID, err := newMessageID(s.from.Address)
+	if err != nil {
+		return nil, err
+	}
+	headers := []struct{ name, value string }{
+		{"From", s.from.String()},
+		{"To", recipient.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", message.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + parts.Boundary()},
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header.name, header.value)
+	}
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		writer, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(writer)
+		if _, err := encoder.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deliver runs one SMTP session. With starttls, the message is refused
+// rather than sent in the clear when the server does not offer STARTTLS.
+func (s *SMTPEmailSender) deliver(ctx context.Context, to string, body []byte) error {
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	dialer := &net.Dialer{Timeout: s.cfg.Timeout}
+
+	var conn net.Conn
+	var err error
+	if s.cfg.TLS == SMTPTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.cfg.Host, MinVersion: tls.VersionTLS12}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(s.cfg.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if s.cfg.TLS == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("server does not offer STARTTLS")
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(body); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// emailParagraphs splits a plain-text body on blank lines.
+func emailParagraphs(body string) []string {
+	var paragraphs []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			paragraphs = append(paragraphs, paragraph)
+		}
+	}
+	return paragraphs
+}
+
+// newMessageID returns a unique Message-ID in the sender's domain.
+func newMessageID(from string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain), nil
+}
@@ -0,0 +1,269 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/config"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpSink is a minimal local SMTP server that keeps every message it is given.
+type smtpSink struct {
+	listener net.Listener
+	mu       sync.Mutex
+	messages []sinkMessage
+}
+
+type sinkMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	sink := &smtpSink{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go sink.serve(conn)
+		}
+	}()
+	return sink
+}
+
+func (s *smtpSink) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) received() []sinkMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sinkMessage(nil), s.messages...)
+}
+
+func (s *smtpSink) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+
+	reply("220 sink ESMTP")
+	var current sinkMessage
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250-sink")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			from, _, _ := strings.Cut(strings.TrimSpace(line)[len("MAIL FROM:"):], " ") // Drops parameters such as BODY=8BITMIME
+			current = sinkMessage{from: strings.Trim(from, "<>")}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			current.to = append(current.to, strings.Trim(strings.TrimSpace(line)[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, "."))
+			}
+			current.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 OK: queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// emailParts returns the text and HTML parts of a sunk message.
+func emailParts(t *testing.T, message sinkMessage) (*mail.Message, string, string) {
+	t.Helper()
+	parsed, err := mail.ReadMessage(strings.NewReader(message.data))
+	require.NoError(t, err)
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(parsed.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(part) // Decodes quoted-printable
+		require.NoError(t, err)
+		partType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		parts[partType] = strings.ReplaceAll(string(content), "\r\n", "\n")
+	}
+	return parsed, parts["text/plain"], parts["text/html"]
+}
+
+func newTestEmailSender(t *testing.T, sink *smtpSink) *SMTPEmailSender {
+	t.Helper()
+	sender, err := NewSMTPEmailSender(SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    sink.port(),
+		From:    "Night Owls <owls@example.com>",
+		TLS:     SMTPTLSNone,
+		Timeout: 5 * time.Second,
+	}, discardLogger())
+	require.NoError(t, err)
+	return sender
+}
+
+func TestSMTPEmailSender_SendEmail(t *testing.T) {
+	sink := newSMTPSink(t)
+	sender := newTestEmailSender(t, sink)
+
+	err := sender.SendEmail(context.Background(), "committee@example.com", EmailMessage{
+		Subject: "Roster for the week",
+		Body:    "Monday: Alice & Bob\n\nTuesday: <unassigned>",
+	})
+	require.NoError(t, err)
+
+	received := sink.received()
+	require.Len(t, received, 1)
+	assert.Equal(t, "owls@example.com", received[0].from)
+	assert.Equal(t, []string{"committee@example.com"}, received[0].to)
+
+	parsed, text, html := emailParts(t, received[0])
+	assert.Equal(t, "Roster for the week", parsed.Header.Get("Subject"))
+	assert.Equal(t, `"Night Owls" <owls@example.com>`, parsed.Header.Get("From"))
+	assert.Contains(t, parsed.Header.Get("Message-ID"), "@example.com>")
+
+	assert.Contains(t, text, "Monday: Alice & Bob\n\nTuesday: <unassigned>")
+	assert.Contains(t, html, "<h1")
+	assert.Contains(t, html, "Monday: Alice &amp; Bob</p>")
+	assert.Contains(t, html, "Tuesday: &lt;unassigned&gt;</p>", "the body is escaped in the HTML part")
+}
+
+func TestSMTPEmailSender_Send(t *testing.T) {
+	sink := newSMTPSink(t)
+	sender := newTestEmailSender(t, sink)
+
+	require.NoError(t, sender.Send("owl@example.com", service.OutboxMessageEmail, `{"subject": "Report summary", "body": "Three reports this week."}`))
+	require.NoError(t, sender.Send("owl@example.com", service.OutboxMessageEmailVerification, `{"code": "123456", "expires_in_minutes": 30}`))
+	assert.Error(t, sender.Send("owl@example.com", service.OutboxMessageEmail, `{"subject": "No body"}`))
+	assert.Error(t, sender.Send("not an address", service.OutboxMessageEmail, `{"subject": "Hi", "body": "Hello"}`))
+
+	received := sink.received()
+	require.Len(t, received, 2)
+	parsed, text, _ := emailParts(t, received[1])
+	assert.Equal(t, "Confirm your email address", parsed.Header.Get("Subject"))
+	assert.Contains(t, text, "123456")
+}
+
+func TestSMTPEmailSender_RequiresStartTLS(t *testing.T) {
+	sink := newSMTPSink(t)
+	sender, err := NewSMTPEmailSender(SMTPConfig{
+		Host: "127.0.0.1",
+		Port: sink.port(),
+		From: "owls@example.com",
+	}, discardLogger())
+	require.NoError(t, err)
+
+	err = sender.SendEmail(context.Background(), "owl@example.com", EmailMessage{Subject: "Hi", Body: "Hello"})
+	assert.ErrorContains(t, err, "STARTTLS")
+	assert.Empty(t, sink.received(), "nothing is sent in the clear")
+}
+
+func TestNewEmailSender(t *testing.T) {
+	sender, err := NewEmailSender(&config.Config{}, discardLogger())
+	require.NoError(t, err)
+	assert.Nil(t, sender, "email is disabled without an SMTP host")
+
+	_, err = NewEmailSender(&config.Config{SMTPHost: "localhost", SMTPPort: 1025}, discardLogger())
+	assert.Error(t, err, "a from address is required")
+
+	_, err = NewEmailSender(&config.Config{SMTPHost: "localhost", SMTPFrom: "owls@example.com", SMTPTLS: "ssl"}, discardLogger())
+	assert.Error(t, err)
+}
+
+func TestDispatcher_RoutesToVerifiedEmail(t *testing.T) {
+	ctx := context.Background()
+	dbConn := newOutboxTestDB(t)
+	querier := db.New(dbConn)
+	sink := newSMTPSink(t)
+	smsSender := &recordingSender{}
+	cfg := &config.Config{OutboxBatchSize: 10, OutboxMaxRetries: 3, NotificationTimezone: "UTC"}
+	dispatcher := NewDispatcherService(querier, smsSender, nil, newTestEmailSender(t, sink), service.NewNotificationPreferencesService(querier, cfg, discardLogger()), discardLogger(), cfg)
+
+	result, err := dbConn.Exec(`INSERT INTO users (phone, name, preferred_channel, email, email_verified_at) VALUES ('+27820000001', 'Owl', 'email', 'owl@example.com', CURRENT_TIMESTAMP)`)
+	require.NoError(t, err)
+	userID, err := result.LastInsertId()
+	require.NoError(t, err)
+	result, err = dbConn.Exec(`INSERT INTO users (phone, name, preferred_channel, pending_email) VALUES ('+27820000002', 'Unconfirmed', 'email', 'pending@example.com')`)
+	require.NoError(t, err)
+	unconfirmedID, err := result.LastInsertId()
+	require.NoError(t, err)
+
+	for _, id := range []int64{userID, unconfirmedID} {
+		_, err := querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
+			MessageType: service.OutboxMessageBookingConfirmation,
+			Recipient:   strconv.FormatInt(id, 10),
+			Payload:     sql.NullString{String: `{"booking_id": 1, "user_id": ` + strconv.FormatInt(id, 10) + `, "shift_start": "2025-06-06T20:00:00Z"}`, Valid: true},
+			UserID:      sql.NullInt64{Int64: id, Valid: true},
+			SendAt:      time.Now().UTC().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+	}
+
+	processed, errs := dispatcher.ProcessPendingOutboxItems(ctx)
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, 0, errs)
+
+	received := sink.received()
+	require.Len(t, received, 1)
+	assert.Equal(t, []string{"owl@example.com"}, received[0].to)
+	parsed, text, _ := emailParts(t, received[0])
+	assert.Equal(t, "Shift booked", parsed.Header.Get("Subject"))
+	assert.Contains(t, text, "Fri 6 Jun at 20:00")
+
+	// An unconfirmed address is never used, so that user falls back to SMS
+	require.Len(t, smsSender.sent, 1)
+	assert.Equal(t, "+27820000002", smsSender.sent[0].recipient)
+}
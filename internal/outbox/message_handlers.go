@@ -0,0 +1,151 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+)
+
+// Delivery channels a rendered message can be routed to
+const (
+	ChannelPush  = "push"
+	ChannelSMS   = "sms"
+	ChannelEmail = "email"
+)
+
+// RenderedMessage is an outbox item rendered for delivery. Push uses all of
+// it; SMS gets the body only.
+type RenderedMessage struct {
+	Type  string // Push payload type, used by the client to route taps
+	Title string
+	Body  string
+	Data  map[string]interface{}
+}
+
+// MessageHandler renders one outbox message type.
+type MessageHandler interface {
+	Render(item db.Outbox) (RenderedMessage, error)
+	// Channels lists the channels the message may go out on, in fallback
+	// order. The user's preferred channel is tried first when it is listed.
+	Channels() []string
+}
+
+// HandlerRegistry maps outbox message types to their handlers.
+type HandlerRegistry struct {
+	handlers map[string]MessageHandler
+}
+
+// NewHandlerRegistry creates an empty HandlerRegistry.
+func NewHandlerRegistry() *HandlerRegistry {
+	return &HandlerRegistry{handlers: make(map[string]MessageHandler)}
+}
+
+// Register adds or replaces the handler for a message type.
+func (r *HandlerRegistry) Register(messageType string, handler MessageHandler) {
+	r.handlers[messageType] = handler
+}
+
+// Lookup returns the handler for a message type.
+func (r *HandlerRegistry) Lookup(messageType string) (MessageHandler, bool) {
+	handler, ok := r.handlers[messageType]
+	return handler, ok
+}
+
+// templateHandler renders a message from text templates over the item's JSON
+// payload.
+type templateHandler struct {
+	pushType string
+	title    *template.Template
+	body     *template.Template
+	channels []string
+}
+
+// newTemplateHandler parses the title and body templates. Shift times in
+// the payload can be formatted in loc with the shiftTime function.
+func newTemplateHandler(loc *time.Location, pushType, title, body string, channels ...string) *templateHandler {
+	funcs := template.FuncMap{
+		"shiftTime": func(value string) string {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return value
+			}
+			return t.In(loc).Format("Mon 2 Jan at 15:04")
+		},
+	}
+	return &templateHandler{
+		pushType: pushType,
+		title:    template.Must(template.New(pushType + "_title").Funcs(funcs).Option("missingkey=error").Parse(title)),
+		body:     template.Must(template.New(pushType + "_body").Funcs(funcs).Option("missingkey=error").Parse(body)),
+		channels: channels,
+	}
+}
+
+// Render implements MessageHandler.
+func (h *templateHandler) Render(item db.Outbox) (RenderedMessage, error) {
+	var data map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(item.Payload.String))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return RenderedMessage{}, fmt.Errorf("invalid %s payload: %w", item.MessageType, err)
+	}
+
+	var title, body bytes.Buffer
+	if err := h.title.Execute(&title, data); err != nil {
+		return RenderedMessage{}, fmt.Errorf("failed to render %s title: %w", item.MessageType, err)
+	}
+	if err := h.body.Execute(&body, data); err != nil {
+		return RenderedMessage{}, fmt.Errorf("failed to render %s body: %w", item.MessageType, err)
+	}
+
+	return RenderedMessage{
+		Type:  h.pushType,
+		Title: title.String(),
+		Body:  body.String(),
+		Data:  data,
+	}, nil
+}
+
+// Channels implements MessageHandler.
+func (h *templateHandler) Channels() []string {
+	return h.channels
+}
+
+// DefaultHandlerRegistry returns the handlers for the message types enqueued
+// by the services, with shift times shown in loc.
+func DefaultHandlerRegistry(loc *time.Location) *HandlerRegistry {
+	registry := NewHandlerRegistry()
+
+	// Codes must never land on a shared device via push
+	registry.Register(service.OutboxMessageOTPVerification, newTemplateHandler(loc, "otp_verification",
+		"Night Owls verification code",
+		"Your Night Owls verification code is {{.otp}}. Do not share it with anyone.",
+		ChannelSMS))
+
+	registry.Register(service.OutboxMessageBookingConfirmation, newTemplateHandler(loc, "booking_confirmation",
+		"Shift booked",
+		"You're booked for the shift on {{shiftTime .shift_start}}. Thank you for keeping watch!",
+		ChannelPush, ChannelSMS, ChannelEmail))
+
+	registry.Register(service.OutboxMessageBookingCancellation, newTemplateHandler(loc, "booking_cancellation",
+		"Shift cancelled",
+		"Your booking for the shift on {{shiftTime .shift_start}} has been cancelled.",
+		ChannelPush, ChannelSMS, ChannelEmail))
+
+	registry.Register(service.OutboxMessageAdminShiftAssignment, newTemplateHandler(loc, "shift_assigned",
+		"Shift assigned",
+		"An admin has booked you for the shift on {{shiftTime .shift_start}}.",
+		ChannelPush, ChannelSMS, ChannelEmail))
+
+	registry.Register(service.OutboxMessageAdminShiftUnassignment, newTemplateHandler(loc, "shift_unassigned",
+		"Shift unassigned",
+		"An admin has removed you from the shift on {{shiftTime .shift_start}}.",
+		ChannelPush, ChannelSMS, ChannelEmail))
+
+	return registry
+}
@@ -0,0 +1,117 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/config"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSender is a MessageSender that records what it was asked to send.
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []sentMessage
+}
+
+type sentMessage struct {
+	recipient, messageType, payload string
+}
+
+func (s *recordingSender) Send(recipient, messageType, payload string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, sentMessage{recipient, messageType, payload})
+	return nil
+}
+
+func TestDefaultHandlerRegistry_CoversEnqueuedTypes(t *testing.T) {
+	registry := DefaultHandlerRegistry(time.UTC)
+	for _, messageType := range service.OutboxMessageTypes() {
+		switch messageType {
+		case service.OutboxMessagePush, service.OutboxMessageSMS, service.OutboxMessageEmail, service.OutboxMessageEmailVerification:
+			continue
+		}
+		_, ok := registry.Lookup(messageType)
+		assert.True(t, ok, "no handler for %s", messageType)
+	}
+}
+
+func TestTemplateHandler_Render(t *testing.T) {
+	loc, err := time.LoadLocation("Africa/Johannesburg")
+	require.NoError(t, err)
+	handler, ok := DefaultHandlerRegistry(loc).Lookup(service.OutboxMessageBookingConfirmation)
+	require.True(t, ok)
+
+	message, err := handler.Render(db.Outbox{
+		MessageType: service.OutboxMessageBookingConfirmation,
+		Payload:     sql.NullString{String: `{"booking_id": 7, "user_id": 3, "shift_start": "2025-06-06T20:00:00Z"}`, Valid: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "booking_confirmation", message.Type)
+	assert.Equal(t, "Shift booked", message.Title)
+	assert.Contains(t, message.Body, "Fri 6 Jun at 22:00")
+
+	_, err = handler.Render(db.Outbox{MessageType: service.OutboxMessageBookingConfirmation, Payload: sql.NullString{String: `{"booking_id": 7}`, Valid: true}})
+	assert.Error(t, err, "a missing field must not render as <no value>")
+}
+
+func TestChannelOrder(t *testing.T) {
+	allowed := []string{ChannelPush, ChannelSMS, ChannelEmail}
+	assert.Equal(t, []string{ChannelSMS, ChannelPush, ChannelEmail}, channelOrder(ChannelSMS, allowed))
+	assert.Equal(t, allowed, channelOrder("", allowed))
+	assert.Equal(t, []string{ChannelSMS}, channelOrder(ChannelPush, []string{ChannelSMS}))
+}
+
+func TestDispatcher_RoutesRenderedMessages(t *testing.T) {
+	ctx := context.Background()
+	dbConn := newOutboxTestDB(t)
+	querier := db.New(dbConn)
+	sender := &recordingSender{}
+	cfg := &config.Config{OutboxBatchSize: 10, OutboxMaxRetries: 3, NotificationTimezone: "UTC"}
+	pushSender := service.NewPushSender(querier, cfg, discardLogger())
+	dispatcher := NewDispatcherService(querier, sender, pushSender, nil, service.NewNotificationPreferencesService(querier, cfg, discardLogger()), discardLogger(), cfg)
+
+	// Prefers push but has no subscriptions, so falls back to SMS
+	result, err := dbConn.Exec(`INSERT INTO users (phone, name, preferred_channel) VALUES ('+27820000001', 'Owl', 'push')`)
+	require.NoError(t, err)
+	userID, err := result.LastInsertId()
+	require.NoError(t, err)
+
+	enqueue := func(messageType, recipient, payload string, userID sql.NullInt64) db.Outbox {
+		item, err := querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
+			MessageType: messageType,
+			Recipient:   recipient,
+			Payload:     sql.NullString{String: payload, Valid: true},
+			UserID:      userID,
+			SendAt:      time.Now().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+		return item
+	}
+
+	enqueue(service.OutboxMessageBookingConfirmation, "1", `{"booking_id": 1, "user_id": 1, "shift_start": "2025-06-06T20:00:00Z"}`, sql.NullInt64{Int64: userID, Valid: true})
+	enqueue(service.OutboxMessageOTPVerification, "+27820000002", `{"otp": "123456"}`, sql.NullInt64{})
+	unknown := enqueue("CARRIER_PIGEON", "1", `{}`, sql.NullInt64{})
+
+	processed, errs := dispatcher.ProcessPendingOutboxItems(ctx)
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, 1, errs)
+
+	require.Len(t, sender.sent, 2)
+	assert.Equal(t, "+27820000001", sender.sent[0].recipient)
+	assert.Contains(t, sender.sent[0].payload, "Fri 6 Jun at 20:00")
+	assert.Equal(t, "+27820000002", sender.sent[1].recipient)
+	assert.Contains(t, sender.sent[1].payload, "123456")
+
+	failed, err := querier.GetOutboxItemByID(ctx, unknown.OutboxID)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", failed.Status)
+}
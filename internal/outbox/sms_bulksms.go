@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// BulkSMSConfig configures the BulkSMS JSON REST provider.
+type BulkSMSConfig struct {
+	BaseURL       string // Defaults to the BulkSMS API; overridden in tests
+	TokenID       string
+	TokenSecret   string
+	From          string // Optional sender ID
+	CallbackToken string // Shared secret on delivery report webhooks
+}
+
+// BulkSMSProvider sends SMS through the BulkSMS JSON REST API.
+type BulkSMSProvider struct {
+	client *http.Client
+	cfg    BulkSMSConfig
+	logger *slog.Logger
+}
+
+// NewBulkSMSProvider creates a new BulkSMSProvider.
+func NewBulkSMSProvider(client *http.Client, cfg BulkSMSConfig, logger *slog.Logger) *BulkSMSProvider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.bulksms.com"
+	}
+	return &BulkSMSProvider{
+		client: client,
+		cfg:    cfg,
+		logger: logger.With("component", "BulkSMSProvider"),
+	}
+}
+
+// Name returns the provider name used in callback URLs and on outbox items.
+func (p *BulkSMSProvider) Name() string {
+	return SMSProviderBulkSMS
+}
+
+// Send implements MessageSender.
+func (p *BulkSMSProvider) Send(recipient, messageType, payload string) error {
+	_, err := p.SendSMS(context.Background(), recipient, payload)
+	return err
+}
+
+// bulkSMSMessage is the message shape used in BulkSMS responses and webhooks.
+type bulkSMSMessage struct {
+	ID     string `json:"id"`
+	Status struct {
+		Type    string `json:"type"`
+		Subtype string `json:"subtype"`
+	} `json:"status"`
+}
+
+// SendSMS submits one message and returns the BulkSMS message ID. Messages
+// that need it are sent as unicode automatically.
+func (p *BulkSMSProvider) SendSMS(ctx context.Context, to, body string) (string, error) {
+	message := map[string]string{"to": to, "body": body}
+	if p.cfg.From != "" {
+		message["from"] = p.cfg.From
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.BaseURL+"/v1/messages?auto-unicode=true", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(p.cfg.TokenID, p.cfg.TokenSecret)
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp []bulkSMSMessage
+	if err := doProviderRequest(p.client, req, &resp); err != nil {
+		return "", fmt.Errorf("bulksms: %w", err)
+	}
+	if len(resp) != 1 || resp[0].ID == "" {
+		return "", fmt.Errorf("bulksms: unexpected response with %d messages", len(resp))
+	}
+	if bulkSMSDeliveryStatus(resp[0].Status.Type) == DeliveryStatusFailed {
+		return "", fmt.Errorf("bulksms: message rejected: %s", resp[0].Status.Subtype)
+	}
+
+	p.logger.InfoContext(ctx, "SMS handed to BulkSMS", "message_id", resp[0].ID, "status", resp[0].Status.Type)
+	return resp[0].ID, nil
+}
+
+// bulkSMSDeliveryStatus maps BulkSMS status types to delivery statuses.
+func bulkSMSDeliveryStatus(statusType string) string {
+	switch statusType {
+	case "DELIVERED":
+		return DeliveryStatusDelivered
+	case "FAILED", "CANCELLED", "UNKNOWN":
+		return DeliveryStatusFailed
+	default:
+		return DeliveryStatusSent
+	}
+}
+
+// ParseDeliveryReports checks the webhook token and reads a BulkSMS status
+// webhook, which carries a batch of messages.
+func (p *BulkSMSProvider) ParseDeliveryReports(r *http.Request) ([]DeliveryReport, error) {
+	if err := checkCallbackToken(r, p.cfg.CallbackToken); err != nil {
+		return nil, err
+	}
+
+	var messages []bulkSMSMessage
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxProviderResponseSize)).Decode(&messages); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDeliveryReport, err)
+	}
+
+	reports := make([]DeliveryReport, 0, len(messages))
+	for _, message := range messages {
+		if message.ID == "" || message.Status.Type == "" {
+			continue
+		}
+		report := DeliveryReport{MessageID: message.ID, Status: bulkSMSDeliveryStatus(message.Status.Type)}
+		if report.Status == DeliveryStatusFailed {
+			report.Error = message.Status.Type
+			if message.Status.Subtype != "" {
+				report.Error += ": " + message.Status.Subtype
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
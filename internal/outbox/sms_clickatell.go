@@ -0,0 +1,133 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// ClickatellSMSConfig configures the Clickatell Platform provider.
+type ClickatellSMSConfig struct {
+	BaseURL       string // Defaults to the Clickatell Platform API; overridden in tests
+	APIKey        string
+	From          string // Optional two-way number or sender ID
+	CallbackToken string // Shared secret on delivery report callbacks
+}
+
+// ClickatellSMSProvider sends SMS through the Clickatell Platform API.
+type ClickatellSMSProvider struct {
+	client *http.Client
+	cfg    ClickatellSMSConfig
+	logger *slog.Logger
+}
+
+// NewClickatellSMSProvider creates a new ClickatellSMSProvider.
+func NewClickatellSMSProvider(client *http.Client, cfg ClickatellSMSConfig, logger *slog.Logger) *ClickatellSMSProvider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://platform.clickatell.com"
+	}
+	return &ClickatellSMSProvider{
+		client: client,
+		cfg:    cfg,
+		logger: logger.With("component", "ClickatellSMSProvider"),
+	}
+}
+
+// Name returns the provider name used in callback URLs and on outbox items.
+func (p *ClickatellSMSProvider) Name() string {
+	return SMSProviderClickatell
+}
+
+// Send implements MessageSender.
+func (p *ClickatellSMSProvider) Send(recipient, messageType, payload string) error {
+	_, err := p.SendSMS(context.Background(), recipient, payload)
+	return err
+}
+
+// SendSMS submits one message and returns Clickatell's message ID. Clickatell
+// expects numbers in international format without the leading plus.
+func (p *ClickatellSMSProvider) SendSMS(ctx context.Context, to, body string) (string, error) {
+	message := map[string]string{
+		"channel": "sms",
+		"to":      strings.TrimPrefix(to, "+"),
+		"content": body,
+	}
+	if p.cfg.From != "" {
+		message["from"] = p.cfg.From
+	}
+	payload, err := json.Marshal(map[string]interface{}{"messages": []map[string]string{message}})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.BaseURL+"/v1/message", bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", p.cfg.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	var resp struct {
+		Messages []struct {
+			APIMessageID string          `json:"apiMessageId"`
+			Accepted     bool            `json:"accepted"`
+			Error        json.RawMessage `json:"error"`
+		} `json:"messages"`
+		Error json.RawMessage `json:"error"`
+	}
+	if err := doProviderRequest(p.client, req, &resp); err != nil {
+		return "", fmt.Errorf("clickatell: %w", err)
+	}
+	if len(resp.Messages) != 1 {
+		return "", fmt.Errorf("clickatell: message not accepted: %s", resp.Error)
+	}
+	if msg := resp.Messages[0]; !msg.Accepted || msg.APIMessageID == "" {
+		return "", fmt.Errorf("clickatell: message not accepted: %s", msg.Error)
+	}
+
+	messageID := resp.Messages[0].APIMessageID
+	p.logger.InfoContext(ctx, "SMS handed to Clickatell", "message_id", messageID)
+	return messageID, nil
+}
+
+// clickatellDeliveryStatus maps Clickatell message statuses to delivery statuses.
+func clickatellDeliveryStatus(status string) string {
+	switch {
+	case status == "RECEIVED_BY_RECIPIENT":
+		return DeliveryStatusDelivered
+	case strings.Contains(status, "ERROR"), status == "EXPIRED", status == "CANCELLED", status == "REJECTED":
+		return DeliveryStatusFailed
+	default:
+		return DeliveryStatusSent
+	}
+}
+
+// ParseDeliveryReports checks the callback token and reads a Clickatell
+// message status callback.
+func (p *ClickatellSMSProvider) ParseDeliveryReports(r *http.Request) ([]DeliveryReport, error) {
+	if err := checkCallbackToken(r, p.cfg.CallbackToken); err != nil {
+		return nil, err
+	}
+
+	var callback struct {
+		MessageID string `json:"messageId"`
+		Status    string `json:"status"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxProviderResponseSize)).Decode(&callback); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDeliveryReport, err)
+	}
+	if callback.MessageID == "" || callback.Status == "" {
+		return nil, fmt.Errorf("%w: missing messageId or status", ErrInvalidDeliveryReport)
+	}
+
+	report := DeliveryReport{MessageID: callback.MessageID, Status: clickatellDeliveryStatus(callback.Status)}
+	if report.Status == DeliveryStatusFailed {
+		report.Error = callback.Status
+	}
+	return []DeliveryReport{report}, nil
+}
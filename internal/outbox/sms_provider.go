@@ -0,0 +1,175 @@
+package outbox
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"night-owls-go/internal/config"
+)
+
+// Supported SMS providers
+const (
+	SMSProviderLog        = "log"
+	SMSProviderTwilio     = "twilio"
+	SMSProviderClickatell = "clickatell"
+	SMSProviderBulkSMS    = "bulksms"
+)
+
+// Delivery statuses recorded on outbox items. Delivered and failed are final.
+const (
+	DeliveryStatusSent      = "sent"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+var (
+	// ErrInvalidDeliveryReport is returned when a delivery report callback cannot be parsed.
+	ErrInvalidDeliveryReport = errors.New("invalid delivery report")
+	// ErrUnauthorizedDeliveryReport is returned when a delivery report callback fails authentication.
+	ErrUnauthorizedDeliveryReport = errors.New("unauthorized delivery report")
+)
+
+// maxProviderResponseSize caps how much of a provider response or callback body is read
+const maxProviderResponseSize = 64 * 1024
+
+// DeliveryReport is a provider's report on the fate of one message.
+type DeliveryReport struct {
+	MessageID string
+	Status    string // One of the DeliveryStatus constants
+	Error     string
+}
+
+// SMSProvider is a MessageSender backed by an SMS gateway. SendSMS returns the
+// gateway's message ID so that delivery reports can be matched back to the
+// outbox item that was sent.
+type SMSProvider interface {
+	MessageSender
+	Name() string
+	SendSMS(ctx context.Context, to, body string) (string, error)
+	// ParseDeliveryReports authenticates and reads a delivery report callback.
+	ParseDeliveryReports(r *http.Request) ([]DeliveryReport, error)
+}
+
+// NewSMSHTTPClient returns the HTTP client shared by the SMS providers, with
+// timeouts on connecting, the TLS handshake and the whole request so that a
+// slow gateway cannot hold up the outbox.
+func NewSMSHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           (&net.Dialer{Timeout: 5 * time.Second, KeepAlive: 30 * time.Second}).DialContext,
+			TLSHandshakeTimeout:   5 * time.Second,
+			ResponseHeaderTimeout: timeout,
+			MaxIdleConnsPerHost:   4,
+			IdleConnTimeout:       90 * time.Second,
+		},
+	}
+}
+
+// SMSStatusCallbackURL returns the URL a provider should post delivery reports
+// to, or "" when no public base URL is configured.
+func SMSStatusCallbackURL(cfg *config.Config, provider string) string {
+	if cfg.SMSCallbackBaseURL == "" {
+		return ""
+	}
+	callbackURL := cfg.SMSCallbackBaseURL + "/api/sms/status/" + provider
+	if cfg.SMSCallbackToken != "" {
+		callbackURL += "?token=" + url.QueryEscape(cfg.SMSCallbackToken)
+	}
+	return callbackURL
+}
+
+// NewSMSSender returns the MessageSender selected by cfg.SMSProvider. The log
+// provider writes messages to cfg.OTPLogPath instead of sending them.
+func NewSMSSender(cfg *config.Config, logger *slog.Logger) (MessageSender, error) {
+	client := NewSMSHTTPClient(cfg.SMSHTTPTimeout)
+
+	switch cfg.SMSProvider {
+	case "", SMSProviderLog:
+		return NewLogFileMessageSender(cfg.OTPLogPath, logger)
+	case SMSProviderTwilio:
+		if cfg.TwilioAccountSID == "" || cfg.TwilioAuthToken == "" || (cfg.TwilioFromNumber == "" && cfg.TwilioMessagingServiceSID == "") {
+			return nil, errors.New("twilio SMS needs TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM_NUMBER or TWILIO_MESSAGING_SERVICE_SID")
+		}
+		return NewTwilioSMSProvider(client, TwilioSMSConfig{
+			AccountSID:          cfg.TwilioAccountSID,
+			AuthToken:           cfg.TwilioAuthToken,
+			From:                cfg.TwilioFromNumber,
+			MessagingServiceSID: cfg.TwilioMessagingServiceSID,
+			StatusCallbackURL:   SMSStatusCallbackURL(cfg, SMSProviderTwilio),
+		}, logger), nil
+	case SMSProviderClickatell:
+		if cfg.ClickatellAPIKey == "" {
+			return nil, errors.New("clickatell SMS needs CLICKATELL_API_KEY")
+		}
+		return NewClickatellSMSProvider(client, ClickatellSMSConfig{
+			APIKey:        cfg.ClickatellAPIKey,
+			From:          cfg.SMSSenderID,
+			CallbackToken: cfg.SMSCallbackToken,
+		}, logger), nil
+	case SMSProviderBulkSMS:
+		if cfg.BulkSMSTokenID == "" || cfg.BulkSMSTokenSecret == "" {
+			return nil, errors.New("bulksms SMS needs BULKSMS_TOKEN_ID and BULKSMS_TOKEN_SECRET")
+		}
+		return NewBulkSMSProvider(client, BulkSMSConfig{
+			TokenID:       cfg.BulkSMSTokenID,
+			TokenSecret:   cfg.BulkSMSTokenSecret,
+			From:          cfg.SMSSenderID,
+			CallbackToken: cfg.SMSCallbackToken,
+		}, logger), nil
+	default:
+		return nil, fmt.Errorf("unknown SMS provider %q", cfg.SMSProvider)
+	}
+}
+
+// checkCallbackToken verifies the shared secret on callbacks from providers
+// that do not sign their requests. Callbacks are refused when no token is set.
+func checkCallbackToken(r *http.Request, token string) error {
+	if token == "" {
+		return ErrUnauthorizedDeliveryReport
+	}
+	if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(token)) != 1 {
+		return ErrUnauthorizedDeliveryReport
+	}
+	return nil
+}
+
+// doProviderRequest sends req and decodes a successful JSON response into out.
+// Error responses are returned with their status and a snippet of the body.
+func doProviderRequest(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxProviderResponseSize))
+	if err != nil {
+		return fmt.Errorf("failed to read provider response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet := strings.TrimSpace(string(body))
+		if len(snippet) > 200 {
+			snippet = snippet[:200]
+		}
+		return fmt.Errorf("provider returned %d: %s", resp.StatusCode, snippet)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode provider response: %w", err)
+	}
+	return nil
+}
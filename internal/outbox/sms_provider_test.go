@@ -0,0 +1,274 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"night-owls-go/internal/config"
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// newOutboxTestDB opens a throwaway database with all migrations applied.
+func newOutboxTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dbConn, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "outbox.db")+"?_foreign_keys=on&_pragma=busy_timeout(5000)")
+	require.NoError(t, err)
+	t.Cleanup(func() { dbConn.Close() })
+
+	_, currentFile, _, ok := runtime.Caller(0)
+	require.True(t, ok)
+	migrationFiles, err := filepath.Glob(filepath.Join(filepath.Dir(currentFile), "..", "db", "migrations", "*.up.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, migrationFiles)
+	sort.Strings(migrationFiles)
+
+	for _, migrationFile := range migrationFiles {
+		sqlBytes, err := os.ReadFile(migrationFile)
+		require.NoError(t, err)
+		_, err = dbConn.Exec(string(sqlBytes))
+		require.NoError(t, err, "migration %s", filepath.Base(migrationFile))
+	}
+	return dbConn
+}
+
+func TestTwilioSMSProvider_SendSMS(t *testing.T) {
+	var gotPath string
+	var gotForm url.Values
+	var gotUser, gotPass string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotUser, gotPass, _ = r.BasicAuth()
+		require.NoError(t, r.ParseForm())
+		gotForm = r.PostForm
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM123","status":"queued"}`))
+	}))
+	defer server.Close()
+
+	provider := NewTwilioSMSProvider(server.Client(), TwilioSMSConfig{
+		BaseURL:           server.URL,
+		AccountSID:        "AC1",
+		AuthToken:         "secret",
+		From:              "+15550001111",
+		StatusCallbackURL: "https://owls.example/api/sms/status/twilio",
+	}, discardLogger())
+
+	sid, err := provider.SendSMS(context.Background(), "+27821234567", "Your code is 123456")
+	require.NoError(t, err)
+	assert.Equal(t, "SM123", sid)
+	assert.Equal(t, "/2010-04-01/Accounts/AC1/Messages.json", gotPath)
+	assert.Equal(t, "AC1", gotUser)
+	assert.Equal(t, "secret", gotPass)
+	assert.Equal(t, "+27821234567", gotForm.Get("To"))
+	assert.Equal(t, "+15550001111", gotForm.Get("From"))
+	assert.Equal(t, "Your code is 123456", gotForm.Get("Body"))
+	assert.Equal(t, "https://owls.example/api/sms/status/twilio", gotForm.Get("StatusCallback"))
+}
+
+func TestTwilioSMSProvider_SendSMSError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number"}`))
+	}))
+	defer server.Close()
+
+	provider := NewTwilioSMSProvider(server.Client(), TwilioSMSConfig{BaseURL: server.URL, AccountSID: "AC1", AuthToken: "secret", From: "+1"}, discardLogger())
+	_, err := provider.SendSMS(context.Background(), "nope", "hi")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400")
+	assert.Contains(t, err.Error(), "Invalid 'To' Phone Number")
+}
+
+func TestTwilioSMSProvider_ParseDeliveryReports(t *testing.T) {
+	callbackURL := "https://owls.example/api/sms/status/twilio"
+	provider := NewTwilioSMSProvider(http.DefaultClient, TwilioSMSConfig{AccountSID: "AC1", AuthToken: "secret", StatusCallbackURL: callbackURL}, discardLogger())
+
+	form := url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"undelivered"}, "ErrorCode": {"30003"}}
+	newRequest := func(signature string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/sms/status/twilio", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("X-Twilio-Signature", signature)
+		return r
+	}
+
+	reports, err := provider.ParseDeliveryReports(newRequest(twilioSignature("secret", callbackURL, form)))
+	require.NoError(t, err)
+	assert.Equal(t, []DeliveryReport{{MessageID: "SM123", Status: DeliveryStatusFailed, Error: "twilio error 30003"}}, reports)
+
+	_, err = provider.ParseDeliveryReports(newRequest(twilioSignature("wrong", callbackURL, form)))
+	assert.ErrorIs(t, err, ErrUnauthorizedDeliveryReport)
+}
+
+func TestClickatellSMSProvider(t *testing.T) {
+	var gotAuth string
+	var gotBody map[string][]map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/message", r.URL.Path)
+		gotAuth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"messages":[{"apiMessageId":"c1","accepted":true,"to":"27821234567"}],"error":null}`))
+	}))
+	defer server.Close()
+
+	provider := NewClickatellSMSProvider(server.Client(), ClickatellSMSConfig{BaseURL: server.URL, APIKey: "key", CallbackToken: "tok"}, discardLogger())
+
+	messageID, err := provider.SendSMS(context.Background(), "+27821234567", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "c1", messageID)
+	assert.Equal(t, "key", gotAuth)
+	require.Len(t, gotBody["messages"], 1)
+	assert.Equal(t, "27821234567", gotBody["messages"][0]["to"])
+	assert.Equal(t, "hello", gotBody["messages"][0]["content"])
+
+	body := `{"messageId":"c1","status":"RECEIVED_BY_RECIPIENT"}`
+	reports, err := provider.ParseDeliveryReports(httptest.NewRequest(http.MethodPost, "/api/sms/status/clickatell?token=tok", strings.NewReader(body)))
+	require.NoError(t, err)
+	assert.Equal(t, []DeliveryReport{{MessageID: "c1", Status: DeliveryStatusDelivered}}, reports)
+
+	_, err = provider.ParseDeliveryReports(httptest.NewRequest(http.MethodPost, "/api/sms/status/clickatell?token=bad", strings.NewReader(body)))
+	assert.ErrorIs(t, err, ErrUnauthorizedDeliveryReport)
+
+	_, err = provider.ParseDeliveryReports(httptest.NewRequest(http.MethodPost, "/api/sms/status/clickatell?token=tok", strings.NewReader(`{"status":"x"}`)))
+	assert.ErrorIs(t, err, ErrInvalidDeliveryReport)
+}
+
+func TestBulkSMSProvider(t *testing.T) {
+	var gotUser, gotPass string
+	var gotBody map[string]string
+	rejected := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("auto-unicode"))
+		gotUser, gotPass, _ = r.BasicAuth()
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&gotBody))
+		w.WriteHeader(http.StatusCreated)
+		if rejected {
+			w.Write([]byte(`[{"id":"b2","status":{"type":"FAILED","subtype":"BLOCKED"}}]`))
+			return
+		}
+		w.Write([]byte(`[{"id":"b1","status":{"type":"ACCEPTED"}}]`))
+	}))
+	defer server.Close()
+
+	provider := NewBulkSMSProvider(server.Client(), BulkSMSConfig{BaseURL: server.URL, TokenID: "id", TokenSecret: "sec", From: "NightOwls", CallbackToken: "tok"}, discardLogger())
+
+	messageID, err := provider.SendSMS(context.Background(), "+27821234567", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "b1", messageID)
+	assert.Equal(t, "id", gotUser)
+	assert.Equal(t, "sec", gotPass)
+	assert.Equal(t, map[string]string{"to": "+27821234567", "body": "hello", "from": "NightOwls"}, gotBody)
+
+	rejected = true
+	_, err = provider.SendSMS(context.Background(), "+27821234567", "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "BLOCKED")
+
+	body := `[{"id":"b1","status":{"type":"DELIVERED"}},{"id":"b2","status":{"type":"FAILED","subtype":"BLOCKED"}}]`
+	reports, err := provider.ParseDeliveryReports(httptest.NewRequest(http.MethodPost, "/api/sms/status/bulksms?token=tok", strings.NewReader(body)))
+	require.NoError(t, err)
+	assert.Equal(t, []DeliveryReport{
+		{MessageID: "b1", Status: DeliveryStatusDelivered},
+		{MessageID: "b2", Status: DeliveryStatusFailed, Error: "FAILED: BLOCKED"},
+	}, reports)
+
+	_, err = provider.ParseDeliveryReports(httptest.NewRequest(http.MethodPost, "/api/sms/status/bulksms", strings.NewReader(body)))
+	assert.ErrorIs(t, err, ErrUnauthorizedDeliveryReport)
+}
+
+func TestNewSMSSender(t *testing.T) {
+	cfg := &config.Config{SMSProvider: SMSProviderTwilio, SMSHTTPTimeout: time.Second, OTPLogPath: os.DevNull}
+	_, err := NewSMSSender(cfg, discardLogger())
+	assert.Error(t, err, "twilio without credentials should be refused")
+
+	cfg.SMSProvider = "carrier-pigeon"
+	_, err = NewSMSSender(cfg, discardLogger())
+	assert.Error(t, err)
+
+	cfg.SMSProvider = SMSProviderClickatell
+	cfg.ClickatellAPIKey = "key"
+	sender, err := NewSMSSender(cfg, discardLogger())
+	require.NoError(t, err)
+	assert.IsType(t, &ClickatellSMSProvider{}, sender)
+
+	cfg.SMSProvider = SMSProviderLog
+	sender, err = NewSMSSender(cfg, discardLogger())
+	require.NoError(t, err)
+	_, isProvider := sender.(SMSProvider)
+	assert.False(t, isProvider)
+}
+
+func TestDispatcher_SMSProviderDeliveryReports(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`[{"id":"b1","status":{"type":"ACCEPTED"}}]`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	querier := db.New(newOutboxTestDB(t))
+	provider := NewBulkSMSProvider(server.Client(), BulkSMSConfig{BaseURL: server.URL, TokenID: "id", TokenSecret: "sec"}, discardLogger())
+	cfg := &config.Config{OutboxBatchSize: 10, OutboxMaxRetries: 3}
+	dispatcher := NewDispatcherService(querier, provider, nil, nil, service.NewNotificationPreferencesService(querier, cfg, discardLogger()), discardLogger(), cfg)
+
+	item, err := querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
+		MessageType: "sms",
+		Recipient:   "+27821234567",
+		Payload:     sql.NullString{String: "hello", Valid: true},
+		SendAt:      time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	processed, errs := dispatcher.ProcessPendingOutboxItems(ctx)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, 0, errs)
+
+	sent, err := querier.GetOutboxItemByID(ctx, item.OutboxID)
+	require.NoError(t, err)
+	assert.Equal(t, "sent", sent.Status)
+	assert.Equal(t, SMSProviderBulkSMS, sent.Provider.String)
+	assert.Equal(t, "b1", sent.ProviderMessageID.String)
+	assert.Equal(t, DeliveryStatusSent, sent.DeliveryStatus.String)
+
+	updated, err := dispatcher.ApplyDeliveryReports(ctx, SMSProviderBulkSMS, []DeliveryReport{{MessageID: "b1", Status: DeliveryStatusDelivered}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+
+	// A late intermediate report, a report from another provider and one for
+	// an unknown message are all ignored
+	updated, err = dispatcher.ApplyDeliveryReports(ctx, SMSProviderBulkSMS, []DeliveryReport{{MessageID: "b1", Status: DeliveryStatusSent}, {MessageID: "zz", Status: DeliveryStatusFailed}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, updated)
+	updated, err = dispatcher.ApplyDeliveryReports(ctx, SMSProviderTwilio, []DeliveryReport{{MessageID: "b1", Status: DeliveryStatusFailed}})
+	require.NoError(t, err)
+	assert.Equal(t, 0, updated)
+
+	delivered, err := querier.GetOutboxItemByID(ctx, item.OutboxID)
+	require.NoError(t, err)
+	assert.Equal(t, DeliveryStatusDelivered, delivered.DeliveryStatus.String)
+	assert.True(t, delivered.DeliveryUpdatedAt.Valid)
+}
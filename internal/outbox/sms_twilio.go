@@ -0,0 +1,157 @@
+package outbox
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1" // #nosec G505 - Twilio signs callbacks with HMAC-SHA1
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// TwilioSMSConfig configures the Twilio Programmable Messaging provider.
+type TwilioSMSConfig struct {
+	BaseURL             string // Defaults to the Twilio API; overridden in tests
+	AccountSID          string
+	AuthToken           string
+	From                string
+	MessagingServiceSID string // Used instead of From when set
+	StatusCallbackURL   string // Delivery reports are only requested when set
+}
+
+// TwilioSMSProvider sends SMS through Twilio Programmable Messaging.
+type TwilioSMSProvider struct {
+	client *http.Client
+	cfg    TwilioSMSConfig
+	logger *slog.Logger
+}
+
+// NewTwilioSMSProvider creates a new TwilioSMSProvider.
+func NewTwilioSMSProvider(client *http.Client, cfg TwilioSMSConfig, logger *slog.Logger) *TwilioSMSProvider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "https://api.twilio.com"
+	}
+	return &TwilioSMSProvider{
+		client: client,
+		cfg:    cfg,
+		logger: logger.With("component", "TwilioSMSProvider"),
+	}
+}
+
+// Name returns the provider name used in callback URLs and on outbox items.
+func (p *TwilioSMSProvider) Name() string {
+	return SMSProviderTwilio
+}
+
+// Send implements MessageSender.
+func (p *TwilioSMSProvider) Send(recipient, messageType, payload string) error {
+	_, err := p.SendSMS(context.Background(), recipient, payload)
+	return err
+}
+
+// SendSMS creates a Twilio message and returns its SID.
+func (p *TwilioSMSProvider) SendSMS(ctx context.Context, to, body string) (string, error) {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("Body", body)
+	if p.cfg.MessagingServiceSID != "" {
+		form.Set("MessagingServiceSid", p.cfg.MessagingServiceSID)
+	} else {
+		form.Set("From", p.cfg.From)
+	}
+	if p.cfg.StatusCallbackURL != "" {
+		form.Set("StatusCallback", p.cfg.StatusCallbackURL)
+	}
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", p.cfg.BaseURL, url.PathEscape(p.cfg.AccountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(p.cfg.AccountSID, p.cfg.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp struct {
+		SID          string `json:"sid"`
+		Status       string `json:"status"`
+		ErrorMessage string `json:"error_message"`
+	}
+	if err := doProviderRequest(p.client, req, &resp); err != nil {
+		return "", fmt.Errorf("twilio: %w", err)
+	}
+	if resp.SID == "" {
+		return "", fmt.Errorf("twilio: message not accepted: %s", resp.ErrorMessage)
+	}
+
+	p.logger.InfoContext(ctx, "SMS handed to Twilio", "message_sid", resp.SID, "status", resp.Status)
+	return resp.SID, nil
+}
+
+// twilioSignature computes the X-Twilio-Signature for a form callback: the
+// base64 HMAC-SHA1 of the URL followed by each parameter name and value in
+// name order, keyed with the auth token.
+func twilioSignature(authToken, callbackURL string, form url.Values) string {
+	keys := make([]string, 0, len(form))
+	for key := range form {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(callbackURL)
+	for _, key := range keys {
+		for _, value := range form[key] {
+			sb.WriteString(key)
+			sb.WriteString(value)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(sb.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// twilioDeliveryStatus maps Twilio message statuses to delivery statuses.
+func twilioDeliveryStatus(status string) string {
+	switch status {
+	case "delivered", "read":
+		return DeliveryStatusDelivered
+	case "undelivered", "failed", "canceled":
+		return DeliveryStatusFailed
+	default:
+		return DeliveryStatusSent
+	}
+}
+
+// ParseDeliveryReports verifies the Twilio request signature against the
+// configured status callback URL and reads the message status.
+func (p *TwilioSMSProvider) ParseDeliveryReports(r *http.Request) ([]DeliveryReport, error) {
+	if p.cfg.StatusCallbackURL == "" {
+		return nil, ErrUnauthorizedDeliveryReport
+	}
+	r.Body = http.MaxBytesReader(nil, r.Body, maxProviderResponseSize)
+	if err := r.ParseForm(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDeliveryReport, err)
+	}
+
+	expected := twilioSignature(p.cfg.AuthToken, p.cfg.StatusCallbackURL, r.PostForm)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Twilio-Signature"))) {
+		return nil, ErrUnauthorizedDeliveryReport
+	}
+
+	sid := r.PostForm.Get("MessageSid")
+	status := r.PostForm.Get("MessageStatus")
+	if sid == "" || status == "" {
+		return nil, fmt.Errorf("%w: missing MessageSid or MessageStatus", ErrInvalidDeliveryReport)
+	}
+
+	report := DeliveryReport{MessageID: sid, Status: twilioDeliveryStatus(status)}
+	if code := r.PostForm.Get("ErrorCode"); code != "" && report.Status == DeliveryStatusFailed {
+		report.Error = "twilio error " + code
+	}
+	return []DeliveryReport{report}, nil
+}
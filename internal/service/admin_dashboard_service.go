@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"time"
 
@@ -74,11 +77,19 @@ type TimeSlotPattern struct {
 	CompletionRate float64 `json:"completion_rate"`
 }
 
+type CategoryBreakdown struct {
+	CategoryID    int64  `json:"category_id"`
+	CategoryName  string `json:"category_name"`
+	ReportCount   int    `json:"report_count"`
+	IncidentCount int    `json:"incident_count"`
+}
+
 type AdminDashboard struct {
 	Metrics             DashboardMetrics     `json:"metrics"`
 	MemberContributions []MemberContribution `json:"member_contributions"`
 	QualityMetrics      QualityMetrics       `json:"quality_metrics"`
 	ProblematicSlots    []TimeSlotPattern    `json:"problematic_slots"`
+	CategoryBreakdown   []CategoryBreakdown  `json:"category_breakdown"`
 	GeneratedAt         time.Time            `json:"generated_at"`
 }
 
@@ -115,15 +126,39 @@ func (s *AdminDashboardService) GetDashboard(ctx context.Context) (*AdminDashboa
 		problematicSlots = []TimeSlotPattern{} // Return empty slice instead of failing
 	}
 
+	// Get report counts per incident category (past 30 days)
+	categoryBreakdown := s.getCategoryBreakdown(ctx, now.AddDate(0, 0, -30))
+
 	return &AdminDashboard{
 		Metrics:             *metrics,
 		MemberContributions: contributions,
 		QualityMetrics:      *qualityMetrics,
 		ProblematicSlots:    problematicSlots,
+		CategoryBreakdown:   categoryBreakdown,
 		GeneratedAt:         now,
 	}, nil
 }
 
+func (s *AdminDashboardService) getCategoryBreakdown(ctx context.Context, since time.Time) []CategoryBreakdown {
+	rows, err := s.querier.GetReportCategoryBreakdown(ctx, sql.NullTime{Time: since, Valid: true})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get report category breakdown", "error", err)
+		return []CategoryBreakdown{}
+	}
+
+	result := make([]CategoryBreakdown, len(rows))
+	for i, row := range rows {
+		result[i] = CategoryBreakdown{
+			CategoryID:    row.CategoryID,
+			CategoryName:  row.CategoryName,
+			ReportCount:   int(row.ReportCount),
+			IncidentCount: int(row.IncidentCount),
+		}
+	}
+
+	return result
+}
+
 func (s *AdminDashboardService) calculateDashboardMetrics(ctx context.Context, from, to time.Time) *DashboardMetrics {
 	// Get all available slots for the period using existing schedule service
 	allSlots, err := s.scheduleService.AdminGetAllShiftSlots(ctx, &from, &to, nil)
@@ -354,3 +389,67 @@ func (s *AdminDashboardService) getWeekendStatus(slots []AdminAvailableShiftSlot
 	}
 	return "partial_coverage"
 }
+
+// coverageAlertWindow is how far ahead coverage alerts look for unfilled shifts
+const coverageAlertWindow = 24 * time.Hour
+
+// NotifyCoverageGaps pushes a coverage alert to owls and admins when shifts in
+// the next 24 hours are still unbooked. The alerts use the coverage_alerts
+// notification category, so users who turned them off are skipped by the
+// outbox dispatcher. It returns the number of alerts queued.
+func (s *AdminDashboardService) NotifyCoverageGaps(ctx context.Context) (int, error) {
+	from := time.Now().UTC()
+	to := from.Add(coverageAlertWindow)
+	slots, err := s.scheduleService.GetUpcomingAvailableSlots(ctx, &from, &to, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(slots) == 0 {
+		return 0, nil
+	}
+
+	body := fmt.Sprintf("%d shifts in the next 24 hours still need owls. Can you take one?", len(slots))
+	if len(slots) == 1 {
+		body = fmt.Sprintf("The %s shift in the next 24 hours still needs an owl. Can you take it?", slots[0].ScheduleName)
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":  "coverage_alert",
+		"title": "Shifts need cover",
+		"body":  body,
+		"data": map[string]interface{}{
+			"type":           "coverage_alert",
+			"unfilled_slots": len(slots),
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	users, err := s.querier.ListUsers(ctx, nil)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list users for coverage alert", "error", err)
+		return 0, ErrInternalServer
+	}
+
+	queued := 0
+	for _, user := range users {
+		if user.Role != "owl" && user.Role != "admin" {
+			continue
+		}
+		_, err := enqueueOutboxItem(ctx, s.querier, s.logger, db.CreateOutboxItemParams{
+			MessageType: OutboxMessagePush,
+			Payload:     sql.NullString{String: string(payload), Valid: true},
+			UserID:      sql.NullInt64{Int64: user.UserID, Valid: true},
+			SendAt:      time.Now().Add(-1 * time.Second),
+			Category:    sql.NullString{String: NotificationCategoryCoverageAlerts, Valid: true},
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to queue coverage alert", "user_id", user.UserID, "error", err)
+			continue
+		}
+		queued++
+	}
+
+	s.logger.InfoContext(ctx, "Coverage alerts queued", "unfilled_slots", len(slots), "recipients", queued)
+	return queued, nil
+}
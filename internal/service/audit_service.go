@@ -296,6 +296,23 @@ func (s *AuditService) LogBookingCheckedIn(ctx context.Context, userID, bookingI
 	})
 }
 
+// LogBookingCheckedOut logs when a user checks out at the end of their patrol
+func (s *AuditService) LogBookingCheckedOut(ctx context.Context, userID, bookingID, scheduleID int64, shiftEnd string, ipAddress, userAgent string) error {
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "booking.checked_out",
+		ActorUserID: &userID,
+		EntityType:  "booking",
+		EntityID:    &bookingID,
+		Action:      "checked_out",
+		Details: map[string]interface{}{
+			"schedule_id": scheduleID,
+			"shift_end":   shiftEnd,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+}
+
 // LogBookingAdminAssigned logs when an admin assigns a user to a booking
 func (s *AuditService) LogBookingAdminAssigned(ctx context.Context, actorUserID, targetUserID, bookingID, scheduleID int64, scheduleName string, shiftStart, shiftEnd string, ipAddress, userAgent string) error {
 	return s.LogEvent(ctx, AuditEvent{
@@ -364,6 +381,81 @@ func (s *AuditService) LogReportArchived(ctx context.Context, actorUserID, repor
 	})
 }
 
+// LogReportFlagged logs when an admin flags a report as spam or a false alarm
+func (s *AuditService) LogReportFlagged(ctx context.Context, actorUserID, reportID int64, reporterUserID *int64, flag, note string, trust *ReporterTrust, ipAddress, userAgent string) error {
+	details := map[string]interface{}{
+		"flag": flag,
+	}
+	if note != "" {
+		details["note"] = note
+	}
+	if reporterUserID != nil {
+		details["reporter_user_id"] = *reporterUserID
+	}
+	if trust != nil {
+		details["reporter_trust_score"] = trust.Score
+		details["reporter_suspended"] = trust.Suspended
+	}
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "report.flagged",
+		ActorUserID: &actorUserID,
+		EntityType:  "report",
+		EntityID:    &reportID,
+		Action:      "flagged",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// LogReportUnflagged logs when an admin clears a report's spam or false alarm flag
+func (s *AuditService) LogReportUnflagged(ctx context.Context, actorUserID, reportID int64, reporterUserID *int64, previousFlag string, trust *ReporterTrust, ipAddress, userAgent string) error {
+	details := map[string]interface{}{
+		"previous_flag": previousFlag,
+	}
+	if reporterUserID != nil {
+		details["reporter_user_id"] = *reporterUserID
+	}
+	if trust != nil {
+		details["reporter_trust_score"] = trust.Score
+		details["reporter_suspended"] = trust.Suspended
+	}
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "report.unflagged",
+		ActorUserID: &actorUserID,
+		EntityType:  "report",
+		EntityID:    &reportID,
+		Action:      "unflagged",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// LogReportsMerged logs when an admin merges duplicate reports into a primary report
+func (s *AuditService) LogReportsMerged(ctx context.Context, actorUserID, primaryReportID int64, mergedReportIDs []int64, photosMoved int64, pointsRevokedUserIDs []int64, ipAddress, userAgent string) error {
+	details := map[string]interface{}{
+		"merged_report_ids": mergedReportIDs,
+		"photos_moved":      photosMoved,
+	}
+	if len(pointsRevokedUserIDs) > 0 {
+		details["points_revoked_user_ids"] = pointsRevokedUserIDs
+	}
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "report.merged",
+		ActorUserID: &actorUserID,
+		EntityType:  "report",
+		EntityID:    &primaryReportID,
+		Action:      "merged",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
 // LogReportUnarchived logs when an admin unarchives a report
 func (s *AuditService) LogReportUnarchived(ctx context.Context, actorUserID, reportID int64, reporterUserID *int64, severity int64, ipAddress, userAgent string) error {
 	details := map[string]interface{}{
@@ -427,6 +519,321 @@ func (s *AuditService) LogReportDeleted(ctx context.Context, actorUserID, report
 	})
 }
 
+// LogReportsExported logs when an admin exports reports, including the filters applied
+func (s *AuditService) LogReportsExported(ctx context.Context, actorUserID int64, format string, filters map[string]interface{}, reportCount int, ipAddress, userAgent string) error {
+	details := map[string]interface{}{
+		"format":       format,
+		"report_count": reportCount,
+		"filters":      filters,
+	}
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "report.exported",
+		ActorUserID: &actorUserID,
+		EntityType:  "report",
+		Action:      "exported",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// LogReportsPrinted logs when an admin renders reports to PDF, including
+// whether reporter identities were shown in full
+func (s *AuditService) LogReportsPrinted(ctx context.Context, actorUserID int64, reportIDs []int64, filters map[string]interface{}, fullReporter bool, ipAddress, userAgent string) error {
+	details := map[string]interface{}{
+		"report_ids":    reportIDs,
+		"report_count":  len(reportIDs),
+		"full_reporter": fullReporter,
+	}
+	if filters != nil {
+		details["filters"] = filters
+	}
+
+	event := AuditEvent{
+		EventType:   "report.printed",
+		ActorUserID: &actorUserID,
+		EntityType:  "report",
+		Action:      "printed",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	}
+	if len(reportIDs) == 1 {
+		event.EntityID = &reportIDs[0]
+	}
+	return s.LogEvent(ctx, event)
+}
+
+// LogDataExported logs when an admin exports a dataset as CSV or XLSX, including
+// the columns and filters used
+func (s *AuditService) LogDataExported(ctx context.Context, actorUserID int64, dataset, format string, columns []string, filters map[string]interface{}, ipAddress, userAgent string) error {
+	details := map[string]interface{}{
+		"dataset": dataset,
+		"format":  format,
+		"columns": columns,
+		"filters": filters,
+	}
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "data.exported",
+		ActorUserID: &actorUserID,
+		EntityType:  "export",
+		Action:      "exported",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// LogReportEscalationAcknowledged logs when an owl or admin acknowledges a severity-2 escalation
+func (s *AuditService) LogReportEscalationAcknowledged(ctx context.Context, actorUserID, reportID, escalationID int64, cancelledSMS int64, ipAddress, userAgent string) error {
+	details := map[string]interface{}{
+		"escalation_id": escalationID,
+		"cancelled_sms": cancelledSMS,
+	}
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "report.escalation_acknowledged",
+		ActorUserID: &actorUserID,
+		EntityType:  "report",
+		EntityID:    &reportID,
+		Action:      "escalation_acknowledged",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// LogReportsPurged logs when archived reports are permanently deleted or anonymised under a retention policy.
+// actorUserID is nil when the purge was run by the scheduler.
+func (s *AuditService) LogReportsPurged(ctx context.Context, actorUserID *int64, deletedReportIDs, anonymisedReportIDs []int64, photoFilesRemoved int, ipAddress, userAgent string) error {
+	details := map[string]interface{}{
+		"deleted_report_ids":    deletedReportIDs,
+		"anonymised_report_ids": anonymisedReportIDs,
+		"deleted_count":         len(deletedReportIDs),
+		"anonymised_count":      len(anonymisedReportIDs),
+		"photo_files_removed":   photoFilesRemoved,
+	}
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "report.purged",
+		ActorUserID: actorUserID,
+		EntityType:  "report",
+		Action:      "purged",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// LogRetentionPolicyChanged logs when an admin creates, updates or deletes a report retention policy
+func (s *AuditService) LogRetentionPolicyChanged(ctx context.Context, actorUserID, policyID int64, action string, policy map[string]interface{}, ipAddress, userAgent string) error {
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "retention_policy." + action,
+		ActorUserID: &actorUserID,
+		EntityType:  "retention_policy",
+		EntityID:    &policyID,
+		Action:      action,
+		Details:     policy,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// ===== TIP LINE EVENTS =====
+
+// LogTipPromoted logs when an admin turns an anonymous tip into a report
+func (s *AuditService) LogTipPromoted(ctx context.Context, adminUserID, tipID, reportID int64, ipAddress, userAgent string) error {
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "tip.promoted",
+		ActorUserID: &adminUserID,
+		EntityType:  "tip",
+		EntityID:    &tipID,
+		Action:      "promoted",
+		Details: map[string]interface{}{
+			"report_id": reportID,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+}
+
+// LogTipDiscarded logs when an admin discards an anonymous tip
+func (s *AuditService) LogTipDiscarded(ctx context.Context, adminUserID, tipID int64, note string, ipAddress, userAgent string) error {
+	details := map[string]interface{}{}
+	if note != "" {
+		details["note"] = note
+	}
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "tip.discarded",
+		ActorUserID: &adminUserID,
+		EntityType:  "tip",
+		EntityID:    &tipID,
+		Action:      "discarded",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// ===== WATCHLIST EVENTS =====
+
+// LogWatchlistEntryCreated logs when an admin adds a vehicle or person to the watchlist
+func (s *AuditService) LogWatchlistEntryCreated(ctx context.Context, adminUserID, entryID int64, kind, label, registration string, ipAddress, userAgent string) error {
+	details := map[string]interface{}{
+		"kind":  kind,
+		"label": label,
+	}
+	if registration != "" {
+		details["registration"] = registration
+	}
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "watchlist.entry_created",
+		ActorUserID: &adminUserID,
+		EntityType:  "watchlist_entry",
+		EntityID:    &entryID,
+		Action:      "created",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// LogWatchlistEntryUpdated logs when an admin edits a watchlist entry
+func (s *AuditService) LogWatchlistEntryUpdated(ctx context.Context, adminUserID, entryID int64, label string, isActive bool, ipAddress, userAgent string) error {
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "watchlist.entry_updated",
+		ActorUserID: &adminUserID,
+		EntityType:  "watchlist_entry",
+		EntityID:    &entryID,
+		Action:      "updated",
+		Details: map[string]interface{}{
+			"label":     label,
+			"is_active": isActive,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+}
+
+// LogWatchlistEntryDeleted logs when an admin removes a watchlist entry
+func (s *AuditService) LogWatchlistEntryDeleted(ctx context.Context, adminUserID, entryID int64, label string, ipAddress, userAgent string) error {
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "watchlist.entry_deleted",
+		ActorUserID: &adminUserID,
+		EntityType:  "watchlist_entry",
+		EntityID:    &entryID,
+		Action:      "deleted",
+		Details: map[string]interface{}{
+			"label": label,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+}
+
+// ===== SOS EVENTS =====
+
+// LogSOSRaised logs when an owl raises an SOS alert
+func (s *AuditService) LogSOSRaised(ctx context.Context, userID, alertID int64, reportID, bookingID *int64, latitude, longitude *float64, ipAddress, userAgent string) error {
+	details := map[string]interface{}{}
+	if reportID != nil {
+		details["report_id"] = *reportID
+	}
+	if bookingID != nil {
+		details["booking_id"] = *bookingID
+	}
+	if latitude != nil && longitude != nil {
+		details["latitude"] = *latitude
+		details["longitude"] = *longitude
+	}
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "sos.raised",
+		ActorUserID: &userID,
+		EntityType:  "sos_alert",
+		EntityID:    &alertID,
+		Action:      "raised",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// LogSOSAcknowledged logs when an owl or admin acknowledges an SOS alert and is responding
+func (s *AuditService) LogSOSAcknowledged(ctx context.Context, userID, alertID int64, ipAddress, userAgent string) error {
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "sos.acknowledged",
+		ActorUserID: &userID,
+		EntityType:  "sos_alert",
+		EntityID:    &alertID,
+		Action:      "acknowledged",
+		Details:     map[string]interface{}{},
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// LogSOSStoodDown logs when an admin stands an SOS alert down
+func (s *AuditService) LogSOSStoodDown(ctx context.Context, adminUserID, alertID int64, note string, acknowledgements int, ipAddress, userAgent string) error {
+	details := map[string]interface{}{
+		"acknowledgements": acknowledgements,
+	}
+	if note != "" {
+		details["note"] = note
+	}
+
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "sos.stood_down",
+		ActorUserID: &adminUserID,
+		EntityType:  "sos_alert",
+		EntityID:    &alertID,
+		Action:      "stood_down",
+		Details:     details,
+		IPAddress:   ipAddress,
+		UserAgent:   userAgent,
+	})
+}
+
+// ===== OUTBOX EVENTS =====
+
+// LogOutboxItemRequeued logs when an admin requeues a dead-lettered outbox item
+func (s *AuditService) LogOutboxItemRequeued(ctx context.Context, adminUserID, outboxID int64, messageType, lastError string, ipAddress, userAgent string) error {
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "outbox.requeued",
+		ActorUserID: &adminUserID,
+		EntityType:  "outbox",
+		EntityID:    &outboxID,
+		Action:      "requeued",
+		Details: map[string]interface{}{
+			"message_type": messageType,
+			"last_error":   lastError,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+}
+
+// LogOutboxItemDiscarded logs when an admin discards a dead-lettered outbox item
+func (s *AuditService) LogOutboxItemDiscarded(ctx context.Context, adminUserID, outboxID int64, messageType, lastError string, ipAddress, userAgent string) error {
+	return s.LogEvent(ctx, AuditEvent{
+		EventType:   "outbox.discarded",
+		ActorUserID: &adminUserID,
+		EntityType:  "outbox",
+		EntityID:    &outboxID,
+		Action:      "discarded",
+		Details: map[string]interface{}{
+			"message_type": messageType,
+			"last_error":   lastError,
+		},
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+}
+
 // ===== SCHEDULE MANAGEMENT EVENTS =====
 
 // LogScheduleCreated logs when an admin creates a schedule
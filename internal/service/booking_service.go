@@ -23,6 +23,8 @@ var (
 	ErrForbiddenUpdate          = errors.New("user not authorized to update this booking")
 	ErrCheckInTooEarly          = errors.New("check-in is too early - can only check in up to 30 minutes before shift starts")
 	ErrBookingCannotBeCancelled = errors.New("booking cannot be cancelled - shift has already started or is too close to start time")
+	ErrNotCheckedIn             = errors.New("booking has not been checked in")
+	ErrAlreadyCheckedOut        = errors.New("booking has already been checked out")
 )
 
 // BookingService handles logic related to bookings.
@@ -31,6 +33,8 @@ type BookingService struct {
 	cfg           *config.Config
 	logger        *slog.Logger
 	pointsService *PointsService
+	events        *EventBroker
+	reminders     *Scheduler
 }
 
 // NewBookingService creates a new BookingService.
@@ -43,6 +47,74 @@ func NewBookingService(querier db.Querier, cfg *config.Config, logger *slog.Logg
 	}
 }
 
+// SetEventBroker enables publishing booking events to the real-time stream
+func (s *BookingService) SetEventBroker(events *EventBroker) {
+	s.events = events
+}
+
+// SetReminderScheduler enables shift reminders for new bookings
+func (s *BookingService) SetReminderScheduler(reminders *Scheduler) {
+	s.reminders = reminders
+}
+
+// queueShiftReminders schedules the reminders for a new booking. Failures are
+// logged but don't fail the booking.
+func (s *BookingService) queueShiftReminders(ctx context.Context, booking db.Booking) {
+	if s.reminders == nil {
+		return
+	}
+	if err := s.reminders.EnqueueShiftReminders(ctx, booking); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to queue shift reminders", "booking_id", booking.BookingID, "error", err)
+	}
+}
+
+// cancelShiftReminders cancels the reminders still queued for a deleted booking.
+func (s *BookingService) cancelShiftReminders(ctx context.Context, booking db.Booking) {
+	if s.reminders == nil {
+		return
+	}
+	if err := s.reminders.CancelShiftReminders(ctx, booking); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to cancel shift reminders", "booking_id", booking.BookingID, "error", err)
+	}
+}
+
+// publishBookingEvent sends a booking event to the booking's owner, their buddy and
+// admins. Slot changes are also shared with other owls, without saying who booked.
+func (s *BookingService) publishBookingEvent(eventType string, booking db.Booking) {
+	data := map[string]interface{}{
+		"booking_id":  booking.BookingID,
+		"user_id":     booking.UserID,
+		"schedule_id": booking.ScheduleID,
+		"shift_start": booking.ShiftStart,
+		"shift_end":   booking.ShiftEnd,
+	}
+	if booking.BuddyName.Valid {
+		data["buddy_name"] = booking.BuddyName.String
+	}
+	if booking.CheckedInAt.Valid {
+		data["checked_in_at"] = booking.CheckedInAt.Time
+	}
+	if booking.CheckedOutAt.Valid {
+		data["checked_out_at"] = booking.CheckedOutAt.Time
+	}
+
+	userIDs := []int64{booking.UserID}
+	if booking.BuddyUserID.Valid {
+		userIDs = append(userIDs, booking.BuddyUserID.Int64)
+	}
+
+	var public interface{}
+	if eventType == EventBookingCreated || eventType == EventBookingCancelled {
+		public = map[string]interface{}{
+			"schedule_id": booking.ScheduleID,
+			"shift_start": booking.ShiftStart,
+			"shift_end":   booking.ShiftEnd,
+		}
+	}
+
+	s.events.Publish(DomainEvent{Type: eventType, Data: data, UserIDs: userIDs, Public: public})
+}
+
 // CreateBooking handles the logic for creating a new booking.
 func (s *BookingService) CreateBooking(ctx context.Context, userID int64, scheduleID int64, startTime time.Time, buddyPhone, buddyName sql.NullString) (db.Booking, error) {
 	// 1. Validate schedule and start time
@@ -166,10 +238,11 @@ func (s *BookingService) CreateBooking(ctx context.Context, userID int64, schedu
 	// 6. Queue confirmation message to outbox
 	outboxPayload := fmt.Sprintf(`{"booking_id": %d, "user_id": %d, "shift_start": "%s"}`,
 		createdBooking.BookingID, createdBooking.UserID, createdBooking.ShiftStart.Format(time.RFC3339))
-	_, err = s.querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
-		MessageType: "BOOKING_CONFIRMATION",
+	_, err = enqueueOutboxItem(ctx, s.querier, s.logger, db.CreateOutboxItemParams{
+		MessageType: OutboxMessageBookingConfirmation,
 		Recipient:   fmt.Sprintf("%d", createdBooking.UserID), // Could be phone number or user ID
 		Payload:     sql.NullString{String: outboxPayload, Valid: true},
+		UserID:      sql.NullInt64{Int64: createdBooking.UserID, Valid: true},
 		SendAt:      time.Now().UTC().Add(-1 * time.Second),
 	})
 	if err != nil {
@@ -177,6 +250,8 @@ func (s *BookingService) CreateBooking(ctx context.Context, userID int64, schedu
 		// Non-fatal for booking creation itself, but log it.
 	}
 
+	s.queueShiftReminders(ctx, createdBooking)
+	s.publishBookingEvent(EventBookingCreated, createdBooking)
 	return createdBooking, nil
 }
 
@@ -242,6 +317,55 @@ func (s *BookingService) MarkCheckIn(ctx context.Context, bookingID int64, userI
 	}
 
 	s.logger.InfoContext(ctx, "Booking check-in marked successfully", "booking_id", updatedBooking.BookingID, "checked_in_at", updatedBooking.CheckedInAt)
+	s.publishBookingEvent(EventBookingCheckedIn, updatedBooking)
+	return updatedBooking, nil
+}
+
+// MarkCheckOut records the end of a patrol for a checked-in booking and stops live location sharing.
+func (s *BookingService) MarkCheckOut(ctx context.Context, bookingID int64, userIDFromAuth int64) (db.Booking, error) {
+	booking, err := s.querier.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Booking{}, ErrBookingNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get booking by ID for check-out", "booking_id", bookingID, "error", err)
+		return db.Booking{}, ErrInternalServer
+	}
+
+	if booking.UserID != userIDFromAuth {
+		s.logger.WarnContext(ctx, "User forbidden to check out of booking", "booking_id", bookingID, "booking_owner_id", booking.UserID, "auth_user_id", userIDFromAuth)
+		return db.Booking{}, ErrForbiddenUpdate
+	}
+	if !booking.CheckedInAt.Valid {
+		return db.Booking{}, ErrNotCheckedIn
+	}
+	if booking.CheckedOutAt.Valid {
+		return db.Booking{}, ErrAlreadyCheckedOut
+	}
+
+	now := time.Now().UTC()
+	updatedBooking, err := s.querier.UpdateBookingCheckOut(ctx, db.UpdateBookingCheckOutParams{
+		CheckedOutAt: sql.NullTime{Time: now, Valid: true},
+		BookingID:    bookingID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Booking{}, ErrAlreadyCheckedOut
+		}
+		s.logger.ErrorContext(ctx, "Failed to update booking check-out in DB", "booking_id", bookingID, "error", err)
+		return db.Booking{}, ErrInternalServer
+	}
+
+	if _, err := s.querier.StopPatrolLocationSharing(ctx, db.StopPatrolLocationSharingParams{
+		StoppedAt:  sql.NullTime{Time: now, Valid: true},
+		StopReason: sql.NullString{String: PatrolSharingStopCheckOut, Valid: true},
+		BookingID:  bookingID,
+	}); err != nil {
+		s.logger.WarnContext(ctx, "Failed to stop location sharing on check-out", "booking_id", bookingID, "error", err)
+	}
+
+	s.logger.InfoContext(ctx, "Booking check-out marked successfully", "booking_id", bookingID)
+	s.publishBookingEvent(EventBookingCheckedOut, updatedBooking)
 	return updatedBooking, nil
 }
 
@@ -293,10 +417,11 @@ func (s *BookingService) CancelBooking(ctx context.Context, bookingID int64, use
 	// Queue cancellation notification to outbox
 	outboxPayload := fmt.Sprintf(`{"booking_id": %d, "user_id": %d, "shift_start": "%s", "cancelled_at": "%s"}`,
 		bookingID, booking.UserID, booking.ShiftStart.Format(time.RFC3339), now.Format(time.RFC3339))
-	_, err = s.querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
-		MessageType: "BOOKING_CANCELLATION",
+	_, err = enqueueOutboxItem(ctx, s.querier, s.logger, db.CreateOutboxItemParams{
+		MessageType: OutboxMessageBookingCancellation,
 		Recipient:   fmt.Sprintf("%d", booking.UserID),
 		Payload:     sql.NullString{String: outboxPayload, Valid: true},
+		UserID:      sql.NullInt64{Int64: booking.UserID, Valid: true},
 		SendAt:      time.Now().UTC().Add(-1 * time.Second),
 	})
 	if err != nil {
@@ -304,6 +429,8 @@ func (s *BookingService) CancelBooking(ctx context.Context, bookingID int64, use
 		// Non-fatal for cancellation itself, but log it.
 	}
 
+	s.cancelShiftReminders(ctx, booking)
+	s.publishBookingEvent(EventBookingCancelled, booking)
 	return nil
 }
 
@@ -428,8 +555,8 @@ func (s *BookingService) AdminAssignUserToShift(ctx context.Context, targetUserI
 	// 7. (Optional) Queue confirmation message to outbox for the assigned user
 	outboxPayload := fmt.Sprintf(`{"booking_id": %d, "user_id": %d, "shift_start": "%s", "assigned_by": "admin"}`,
 		createdBooking.BookingID, createdBooking.UserID, createdBooking.ShiftStart.Format(time.RFC3339))
-	_, err = s.querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
-		MessageType: "ADMIN_SHIFT_ASSIGNMENT",
+	_, err = enqueueOutboxItem(ctx, s.querier, s.logger, db.CreateOutboxItemParams{
+		MessageType: OutboxMessageAdminShiftAssignment,
 		Recipient:   fmt.Sprintf("%d", createdBooking.UserID), // Or user's phone if preferred for notification
 		Payload:     sql.NullString{String: outboxPayload, Valid: true},
 		UserID:      sql.NullInt64{Int64: targetUserID, Valid: true},
@@ -440,6 +567,8 @@ func (s *BookingService) AdminAssignUserToShift(ctx context.Context, targetUserI
 		// Non-fatal for booking creation itself, but log it.
 	}
 
+	s.queueShiftReminders(ctx, createdBooking)
+	s.publishBookingEvent(EventBookingCreated, createdBooking)
 	return createdBooking, nil
 }
 
@@ -485,8 +614,8 @@ func (s *BookingService) AdminUnassignUserFromShift(ctx context.Context, schedul
 	// 4. (Optional) Queue notification message to outbox for the unassigned user
 	outboxPayload := fmt.Sprintf(`{"booking_id": %d, "user_id": %d, "shift_start": "%s", "unassigned_by": "admin", "unassigned_at": "%s"}`,
 		booking.BookingID, booking.UserID, booking.ShiftStart.Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339))
-	_, err = s.querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
-		MessageType: "ADMIN_SHIFT_UNASSIGNMENT",
+	_, err = enqueueOutboxItem(ctx, s.querier, s.logger, db.CreateOutboxItemParams{
+		MessageType: OutboxMessageAdminShiftUnassignment,
 		Recipient:   fmt.Sprintf("%d", booking.UserID),
 		Payload:     sql.NullString{String: outboxPayload, Valid: true},
 		UserID:      sql.NullInt64{Int64: booking.UserID, Valid: true},
@@ -497,6 +626,8 @@ func (s *BookingService) AdminUnassignUserFromShift(ctx context.Context, schedul
 		// Non-fatal for unassignment itself, but log it.
 	}
 
+	s.cancelShiftReminders(ctx, booking)
+	s.publishBookingEvent(EventBookingCancelled, booking)
 	return nil
 }
 
@@ -0,0 +1,358 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+// Broadcast audiences. The presets predate audience filters; a custom
+// audience is described by an AudienceFilter stored with the broadcast.
+const (
+	BroadcastAudienceAll    = "all"
+	BroadcastAudienceAdmins = "admins"
+	BroadcastAudienceOwls   = "owls"
+	BroadcastAudienceActive = "active"
+	BroadcastAudienceCustom = "custom"
+)
+
+// Onboarding states an audience can be narrowed to. Onboarding itself happens
+// in the app, so these are the parts of it the server can see.
+const (
+	OnboardingNoPushSubscription = "no_push_subscription"
+	OnboardingNeverBooked        = "never_booked"
+)
+
+// Broadcast statuses. A broadcast is preparing while its recipients are stored.
+const (
+	BroadcastStatusPreparing = "preparing"
+	BroadcastStatusPending   = "pending"
+)
+
+const (
+	// activeAudienceDays is how recently users of the "active" preset were active
+	activeAudienceDays     = 30
+	maxActiveWithinDays    = 365
+	audiencePreviewSamples = 10
+)
+
+var (
+	ErrInvalidAudience        = errors.New("invalid broadcast audience")
+	ErrBroadcastGroupNotFound = errors.New("broadcast group not found")
+	ErrBroadcastNotFound      = errors.New("broadcast not found")
+)
+
+// AudienceFilter selects broadcast recipients. Users must match every
+// criterion that is set.
+type AudienceFilter struct {
+	Role             string `json:"role,omitempty"`               // admin, owl or guest
+	ActiveWithinDays int    `json:"active_within_days,omitempty"` // By last activity date
+	ScheduleID       int64  `json:"schedule_id,omitempty"`        // Has a booking on this schedule
+	ShiftDate        string `json:"shift_date,omitempty"`         // Has a booking starting on this YYYY-MM-DD, in the notification time zone
+	Onboarding       string `json:"onboarding,omitempty"`         // One of the Onboarding constants
+	GroupID          int64  `json:"group_id,omitempty"`           // Member of this broadcast group
+}
+
+func (f AudienceFilter) isEmpty() bool {
+	return f == AudienceFilter{}
+}
+
+// BroadcastRecipient is a user a broadcast is addressed to.
+type BroadcastRecipient struct {
+	UserID int64  `json:"user_id"`
+	Name   string `json:"name"`
+	Phone  string `json:"phone"`
+	Role   string `json:"role"`
+}
+
+// AudiencePreview is the number of users an audience currently resolves to,
+// with a few of them as a sample.
+type AudiencePreview struct {
+	RecipientCount int                  `json:"recipient_count"`
+	Sample         []BroadcastRecipient `json:"sample"`
+}
+
+// CreateBroadcastParams describes a broadcast to be sent. Filter is required
+// when Audience is custom and ignored otherwise. A title or message left empty
+// is taken from the template, if one is given. With a Recurrence the broadcast
+// is sent on every run of that cron expression from ScheduledAt on.
+type CreateBroadcastParams struct {
+	Title             string
+	Message           string
+	TemplateID        int64
+	Audience          string
+	Filter            AudienceFilter
+	SenderUserID      int64
+	PushEnabled       bool
+	Urgent            bool
+	ScheduledAt       sql.NullTime
+	Recurrence        string
+	ParentBroadcastID int64 // The recurring broadcast this is a run of
+}
+
+// audienceFilter returns the filter for an audience, validating custom filters.
+func (s *BroadcastService) audienceFilter(ctx context.Context, audience string, filter AudienceFilter) (AudienceFilter, error) {
+	switch audience {
+	case BroadcastAudienceAll:
+		return AudienceFilter{}, nil
+	case BroadcastAudienceAdmins:
+		return AudienceFilter{Role: "admin"}, nil
+	case BroadcastAudienceOwls:
+		return AudienceFilter{Role: "owl"}, nil
+	case BroadcastAudienceActive:
+		return AudienceFilter{ActiveWithinDays: activeAudienceDays}, nil
+	case BroadcastAudienceCustom:
+	default:
+		return AudienceFilter{}, fmt.Errorf("%w: unknown audience %q", ErrInvalidAudience, audience)
+	}
+
+	if filter.isEmpty() {
+		return AudienceFilter{}, fmt.Errorf("%w: a custom audience needs at least one filter", ErrInvalidAudience)
+	}
+	switch filter.Role {
+	case "", "admin", "owl", "guest":
+	default:
+		return AudienceFilter{}, fmt.Errorf("%w: unknown role %q", ErrInvalidAudience, filter.Role)
+	}
+	if filter.ActiveWithinDays < 0 || filter.ActiveWithinDays > maxActiveWithinDays {
+		return AudienceFilter{}, fmt.Errorf("%w: active_within_days must be between 1 and %d", ErrInvalidAudience, maxActiveWithinDays)
+	}
+	if filter.ShiftDate != "" {
+		if _, err := time.Parse("2006-01-02", filter.ShiftDate); err != nil {
+			return AudienceFilter{}, fmt.Errorf("%w: shift_date must be YYYY-MM-DD", ErrInvalidAudience)
+		}
+	}
+	switch filter.Onboarding {
+	case "", OnboardingNoPushSubscription, OnboardingNeverBooked:
+	default:
+		return AudienceFilter{}, fmt.Errorf("%w: unknown onboarding state %q", ErrInvalidAudience, filter.Onboarding)
+	}
+	if filter.ScheduleID != 0 {
+		if _, err := s.querier.GetScheduleByID(ctx, filter.ScheduleID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return AudienceFilter{}, ErrScheduleNotFound
+			}
+			return AudienceFilter{}, err
+		}
+	}
+	if filter.GroupID != 0 {
+		if _, err := s.querier.GetBroadcastGroup(ctx, filter.GroupID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return AudienceFilter{}, ErrBroadcastGroupNotFound
+			}
+			return AudienceFilter{}, err
+		}
+	}
+	return filter, nil
+}
+
+// ResolveAudience returns the users an audience currently covers.
+func (s *BroadcastService) ResolveAudience(ctx context.Context, audience string, filter AudienceFilter) ([]BroadcastRecipient, error) {
+	filter, err := s.audienceFilter(ctx, audience, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	params := db.ListBroadcastAudienceParams{
+		WithoutPush:     filter.Onboarding == OnboardingNoPushSubscription,
+		WithoutBookings: filter.Onboarding == OnboardingNeverBooked,
+	}
+	if filter.Role != "" {
+		params.Role = filter.Role
+	}
+	if filter.ActiveWithinDays > 0 {
+		params.ActiveSince = time.Now().UTC().AddDate(0, 0, -filter.ActiveWithinDays).Format("2006-01-02")
+	}
+	if filter.GroupID != 0 {
+		params.GroupID = filter.GroupID
+	}
+	if filter.ScheduleID != 0 {
+		params.Booked = true
+		params.ScheduleID = filter.ScheduleID
+	}
+	if filter.ShiftDate != "" {
+		day, err := time.ParseInLocation("2006-01-02", filter.ShiftDate, s.location())
+		if err != nil {
+			return nil, fmt.Errorf("%w: shift_date must be YYYY-MM-DD", ErrInvalidAudience)
+		}
+		params.Booked = true
+		params.ShiftFrom = day.UTC()
+		params.ShiftTo = day.AddDate(0, 0, 1).UTC()
+	}
+
+	rows, err := s.querier.ListBroadcastAudience(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	recipients := make([]BroadcastRecipient, 0, len(rows))
+	for _, row := range rows {
+		recipients = append(recipients, BroadcastRecipient{UserID: row.UserID, Name: row.Name.String, Phone: row.Phone, Role: row.Role})
+	}
+	return recipients, nil
+}
+
+// PreviewAudience counts the users an audience covers, so admins can check it
+// before sending.
+func (s *BroadcastService) PreviewAudience(ctx context.Context, audience string, filter AudienceFilter) (AudiencePreview, error) {
+	recipients, err := s.ResolveAudience(ctx, audience, filter)
+	if err != nil {
+		return AudiencePreview{}, err
+	}
+	sample := recipients
+	if len(sample) > audiencePreviewSamples {
+		sample = sample[:audiencePreviewSamples]
+	}
+	return AudiencePreview{RecipientCount: len(recipients), Sample: sample}, nil
+}
+
+// CreateBroadcast resolves the audience and stores the broadcast with its
+// recipients. The broadcast goes to the users the audience covered when it
+// was created, even if it is scheduled for later.
+func (s *BroadcastService) CreateBroadcast(ctx context.Context, params CreateBroadcastParams) (db.Broadcast, error) {
+	if params.TemplateID != 0 && (params.Title == "" || params.Message == "") {
+		tmpl, err := s.querier.GetBroadcastTemplate(ctx, params.TemplateID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return db.Broadcast{}, ErrBroadcastTemplateNotFound
+			}
+			return db.Broadcast{}, fmt.Errorf("failed to get broadcast template: %w", err)
+		}
+		if params.Title == "" {
+			params.Title = tmpl.Title
+		}
+		if params.Message == "" {
+			params.Message = tmpl.Message
+		}
+	}
+	if params.Title == "" || params.Message == "" {
+		return db.Broadcast{}, fmt.Errorf("%w: title and message are required", ErrInvalidBroadcastMessage)
+	}
+	if err := validateBroadcastMessage(params.Message); err != nil {
+		return db.Broadcast{}, err
+	}
+
+	recipients, err := s.ResolveAudience(ctx, params.Audience, params.Filter)
+	if err != nil {
+		return db.Broadcast{}, err
+	}
+
+	var audienceFilter sql.NullString
+	if params.Audience == BroadcastAudienceCustom {
+		filterJSON, err := json.Marshal(params.Filter)
+		if err != nil {
+			return db.Broadcast{}, err
+		}
+		audienceFilter = sql.NullString{String: string(filterJSON), Valid: true}
+	}
+
+	create := db.CreateBroadcastParams{
+		Title:             params.Title,
+		Message:           params.Message,
+		Audience:          params.Audience,
+		SenderUserID:      params.SenderUserID,
+		PushEnabled:       params.PushEnabled,
+		ScheduledAt:       params.ScheduledAt,
+		RecipientCount:    sql.NullInt64{Int64: int64(len(recipients)), Valid: true},
+		Urgent:            params.Urgent,
+		AudienceFilter:    audienceFilter,
+		Status:            BroadcastStatusPreparing,
+		ParentBroadcastID: sql.NullInt64{Int64: params.ParentBroadcastID, Valid: params.ParentBroadcastID != 0},
+	}
+
+	if params.Recurrence != "" {
+		// Each run resolves its own recipients, so none are stored with the
+		// recurring broadcast; its count shows the audience size today
+		from := time.Now()
+		if params.ScheduledAt.Valid && params.ScheduledAt.Time.After(from) {
+			from = params.ScheduledAt.Time
+		}
+		next, err := s.nextRecurrence(params.Recurrence, from)
+		if err != nil {
+			return db.Broadcast{}, err
+		}
+		create.Status = BroadcastStatusRecurring
+		create.ScheduledAt = sql.NullTime{Time: next, Valid: true}
+		create.RecurrenceCron = sql.NullString{String: params.Recurrence, Valid: true}
+		broadcast, err := s.querier.CreateBroadcast(ctx, create)
+		if err != nil {
+			return db.Broadcast{}, fmt.Errorf("failed to create broadcast: %w", err)
+		}
+		s.logger.InfoContext(ctx, "Recurring broadcast created", "broadcast_id", broadcast.BroadcastID, "recurrence", params.Recurrence, "next_run", next)
+		return broadcast, nil
+	}
+
+	// The broadcast is not picked up for sending until its recipients are stored
+	broadcast, err := s.querier.CreateBroadcast(ctx, create)
+	if err != nil {
+		return db.Broadcast{}, fmt.Errorf("failed to create broadcast: %w", err)
+	}
+
+	for _, recipient := range recipients {
+		if err := s.querier.AddBroadcastRecipient(ctx, db.AddBroadcastRecipientParams{
+			BroadcastID: broadcast.BroadcastID,
+			UserID:      recipient.UserID,
+		}); err != nil {
+			return db.Broadcast{}, fmt.Errorf("failed to store broadcast recipients: %w", err)
+		}
+	}
+
+	broadcast, err = s.querier.UpdateBroadcastStatus(ctx, db.UpdateBroadcastStatusParams{
+		BroadcastID: broadcast.BroadcastID,
+		Status:      BroadcastStatusPending,
+		SentCount:   sql.NullInt64{Int64: 0, Valid: true},
+		FailedCount: sql.NullInt64{Int64: 0, Valid: true},
+	})
+	if err != nil {
+		return db.Broadcast{}, fmt.Errorf("failed to queue broadcast: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Broadcast created", "broadcast_id", broadcast.BroadcastID, "audience", params.Audience, "recipients", len(recipients))
+	return broadcast, nil
+}
+
+// ListRecipients returns the users a broadcast is addressed to.
+func (s *BroadcastService) ListRecipients(ctx context.Context, broadcastID int64) ([]BroadcastRecipient, error) {
+	broadcast, err := s.getBroadcast(ctx, broadcastID)
+	if err != nil {
+		return nil, err
+	}
+	return s.broadcastRecipients(ctx, broadcast)
+}
+
+// broadcastRecipients returns the recipients stored with a broadcast.
+// Broadcasts created before recipients were stored resolve their audience now.
+func (s *BroadcastService) broadcastRecipients(ctx context.Context, broadcast db.Broadcast) ([]BroadcastRecipient, error) {
+	rows, err := s.querier.ListBroadcastRecipients(ctx, broadcast.BroadcastID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 && (!broadcast.RecipientCount.Valid || broadcast.RecipientCount.Int64 > 0) {
+		var filter AudienceFilter
+		if broadcast.AudienceFilter.Valid {
+			if err := json.Unmarshal([]byte(broadcast.AudienceFilter.String), &filter); err != nil {
+				return nil, fmt.Errorf("invalid audience filter: %w", err)
+			}
+		}
+		return s.ResolveAudience(ctx, broadcast.Audience, filter)
+	}
+
+	recipients := make([]BroadcastRecipient, 0, len(rows))
+	for _, row := range rows {
+		recipients = append(recipients, BroadcastRecipient{UserID: row.UserID, Name: row.Name.String, Phone: row.Phone, Role: row.Role})
+	}
+	return recipients, nil
+}
+
+// location returns the time zone shift dates are given in.
+func (s *BroadcastService) location() *time.Location {
+	loc, err := time.LoadLocation(s.cfg.NotificationTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+var (
+	ErrInvalidBroadcastGroup   = errors.New("broadcast group needs a name of at most 100 characters")
+	ErrBroadcastGroupNameTaken = errors.New("a broadcast group with that name already exists")
+)
+
+const maxBroadcastGroupNameLength = 100
+
+// BroadcastGroup is an admin-defined group of users that broadcasts can be
+// addressed to.
+type BroadcastGroup struct {
+	db.BroadcastGroup
+	Members []db.ListBroadcastGroupMembersRow `json:"members"`
+}
+
+// BroadcastGroupService manages named broadcast groups and their members.
+type BroadcastGroupService struct {
+	querier db.Querier
+	logger  *slog.Logger
+}
+
+// NewBroadcastGroupService creates a new BroadcastGroupService.
+func NewBroadcastGroupService(querier db.Querier, logger *slog.Logger) *BroadcastGroupService {
+	return &BroadcastGroupService{
+		querier: querier,
+		logger:  logger.With("service", "BroadcastGroupService"),
+	}
+}
+
+// ListGroups returns every group with its member count.
+func (s *BroadcastGroupService) ListGroups(ctx context.Context) ([]db.ListBroadcastGroupsRow, error) {
+	groups, err := s.querier.ListBroadcastGroups(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list broadcast groups", "error", err)
+		return nil, ErrInternalServer
+	}
+	return groups, nil
+}
+
+// GetGroup returns a group with its members.
+func (s *BroadcastGroupService) GetGroup(ctx context.Context, groupID int64) (BroadcastGroup, error) {
+	group, err := s.querier.GetBroadcastGroup(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return BroadcastGroup{}, ErrBroadcastGroupNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get broadcast group", "group_id", groupID, "error", err)
+		return BroadcastGroup{}, ErrInternalServer
+	}
+	members, err := s.querier.ListBroadcastGroupMembers(ctx, groupID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list broadcast group members", "group_id", groupID, "error", err)
+		return BroadcastGroup{}, ErrInternalServer
+	}
+	return BroadcastGroup{BroadcastGroup: group, Members: members}, nil
+}
+
+// CreateGroup creates an empty group.
+func (s *BroadcastGroupService) CreateGroup(ctx context.Context, name, description string, createdBy int64) (BroadcastGroup, error) {
+	name, err := normalizeBroadcastGroupName(name)
+	if err != nil {
+		return BroadcastGroup{}, err
+	}
+	group, err := s.querier.CreateBroadcastGroup(ctx, db.CreateBroadcastGroupParams{
+		Name:        name,
+		Description: sql.NullString{String: strings.TrimSpace(description), Valid: strings.TrimSpace(description) != ""},
+		CreatedBy:   sql.NullInt64{Int64: createdBy, Valid: createdBy != 0},
+	})
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return BroadcastGroup{}, ErrBroadcastGroupNameTaken
+		}
+		s.logger.ErrorContext(ctx, "Failed to create broadcast group", "name", name, "error", err)
+		return BroadcastGroup{}, ErrInternalServer
+	}
+	s.logger.InfoContext(ctx, "Broadcast group created", "group_id", group.GroupID, "name", name)
+	return BroadcastGroup{BroadcastGroup: group, Members: []db.ListBroadcastGroupMembersRow{}}, nil
+}
+
+// UpdateGroup renames a group or changes its description.
+func (s *BroadcastGroupService) UpdateGroup(ctx context.Context, groupID int64, name, description string) (BroadcastGroup, error) {
+	name, err := normalizeBroadcastGroupName(name)
+	if err != nil {
+		return BroadcastGroup{}, err
+	}
+	if _, err := s.querier.UpdateBroadcastGroup(ctx, db.UpdateBroadcastGroupParams{
+		Name:        name,
+		Description: sql.NullString{String: strings.TrimSpace(description), Valid: strings.TrimSpace(description) != ""},
+		GroupID:     groupID,
+	}); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return BroadcastGroup{}, ErrBroadcastGroupNotFound
+		}
+		if isUniqueConstraintError(err) {
+			return BroadcastGroup{}, ErrBroadcastGroupNameTaken
+		}
+		s.logger.ErrorContext(ctx, "Failed to update broadcast group", "group_id", groupID, "error", err)
+		return BroadcastGroup{}, ErrInternalServer
+	}
+	return s.GetGroup(ctx, groupID)
+}
+
+// DeleteGroup deletes a group. Broadcasts already sent to it keep their recipients.
+func (s *BroadcastGroupService) DeleteGroup(ctx context.Context, groupID int64) error {
+	rows, err := s.querier.DeleteBroadcastGroup(ctx, groupID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete broadcast group", "group_id", groupID, "error", err)
+		return ErrInternalServer
+	}
+	if rows == 0 {
+		return ErrBroadcastGroupNotFound
+	}
+	s.logger.InfoContext(ctx, "Broadcast group deleted", "group_id", groupID)
+	return nil
+}
+
+// AddMembers adds users to a group. Users already in it are left as they are.
+func (s *BroadcastGroupService) AddMembers(ctx context.Context, groupID int64, userIDs []int64) (BroadcastGroup, error) {
+	if _, err := s.GetGroup(ctx, groupID); err != nil {
+		return BroadcastGroup{}, err
+	}
+	for _, userID := range userIDs {
+		if _, err := s.querier.GetUserByID(ctx, userID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return BroadcastGroup{}, ErrUserNotFound
+			}
+			s.logger.ErrorContext(ctx, "Failed to get user", "user_id", userID, "error", err)
+			return BroadcastGroup{}, ErrInternalServer
+		}
+	}
+	for _, userID := range userIDs {
+		if err := s.querier.AddBroadcastGroupMember(ctx, db.AddBroadcastGroupMemberParams{GroupID: groupID, UserID: userID}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to add broadcast group member", "group_id", groupID, "user_id", userID, "error", err)
+			return BroadcastGroup{}, ErrInternalServer
+		}
+	}
+	return s.GetGroup(ctx, groupID)
+}
+
+// RemoveMember removes a user from a group.
+func (s *BroadcastGroupService) RemoveMember(ctx context.Context, groupID, userID int64) error {
+	rows, err := s.querier.RemoveBroadcastGroupMember(ctx, db.RemoveBroadcastGroupMemberParams{GroupID: groupID, UserID: userID})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to remove broadcast group member", "group_id", groupID, "user_id", userID, "error", err)
+		return ErrInternalServer
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func normalizeBroadcastGroupName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxBroadcastGroupNameLength {
+		return "", ErrInvalidBroadcastGroup
+	}
+	return name, nil
+}
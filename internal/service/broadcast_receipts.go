@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+// Delivery states of a broadcast to one recipient, derived from its outbox item.
+const (
+	BroadcastDeliveryPending   = "pending"
+	BroadcastDeliveryDelivered = "delivered"
+	BroadcastDeliveryFailed    = "failed"
+	BroadcastDeliveryNotSent   = "not_sent" // Opted out, push disabled or never queued
+)
+
+// deliveryCountWindow is how long after sending a broadcast's delivered and
+// failed counts keep being refreshed from its outbox items
+const deliveryCountWindow = 7 * 24 * time.Hour
+
+var ErrBroadcastNotSent = errors.New("broadcast has not been sent yet")
+
+// BroadcastReceipt is the delivery and read state of a broadcast for one recipient.
+type BroadcastReceipt struct {
+	UserID         int64      `json:"user_id"`
+	Name           string     `json:"name"`
+	Phone          string     `json:"phone"`
+	Channel        string     `json:"channel,omitempty"` // Outbox message type the broadcast went out as
+	Delivery       string     `json:"delivery"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	ReadAt         *time.Time `json:"read_at,omitempty"`
+	ResentAt       *time.Time `json:"resent_at,omitempty"`
+	ResendDelivery string     `json:"resend_delivery,omitempty"` // Delivery state of the SMS re-send
+}
+
+// BroadcastReceipts summarises how far a broadcast got with its recipients.
+type BroadcastReceipts struct {
+	BroadcastID int64              `json:"broadcast_id"`
+	Recipients  int                `json:"recipients"`
+	Delivered   int                `json:"delivered"`
+	Failed      int                `json:"failed"`
+	Pending     int                `json:"pending"`
+	NotSent     int                `json:"not_sent"`
+	Read        int                `json:"read"`
+	Unread      int                `json:"unread"`
+	Receipts    []BroadcastReceipt `json:"receipts"`
+}
+
+// deliveryState maps an outbox item's status to a delivery state. SMS items
+// count as delivered once sent unless the provider reports a failure.
+func deliveryState(status, deliveryStatus sql.NullString) string {
+	if !status.Valid {
+		return BroadcastDeliveryNotSent
+	}
+	switch status.String {
+	case "sent":
+		if deliveryStatus.String == "failed" {
+			return BroadcastDeliveryFailed
+		}
+		return BroadcastDeliveryDelivered
+	case "permanently_failed", "discarded":
+		return BroadcastDeliveryFailed
+	case "suppressed":
+		return BroadcastDeliveryNotSent
+	default:
+		return BroadcastDeliveryPending
+	}
+}
+
+// Receipts returns the per-recipient delivery and read state of a broadcast.
+func (s *BroadcastService) Receipts(ctx context.Context, broadcastID int64) (BroadcastReceipts, error) {
+	if _, err := s.getBroadcast(ctx, broadcastID); err != nil {
+		return BroadcastReceipts{}, err
+	}
+	rows, err := s.querier.ListBroadcastReceipts(ctx, broadcastID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list broadcast receipts", "broadcast_id", broadcastID, "error", err)
+		return BroadcastReceipts{}, ErrInternalServer
+	}
+
+	result := BroadcastReceipts{
+		BroadcastID: broadcastID,
+		Recipients:  len(rows),
+		Receipts:    make([]BroadcastReceipt, 0, len(rows)),
+	}
+	for _, row := range rows {
+		receipt := BroadcastReceipt{
+			UserID:   row.UserID,
+			Name:     row.Name.String,
+			Phone:    row.Phone,
+			Channel:  row.MessageType.String,
+			Delivery: deliveryState(row.OutboxStatus, row.DeliveryStatus),
+			ReadAt:   nullTimePointer(row.ReadAt),
+			ResentAt: nullTimePointer(row.ResentAt),
+		}
+		if receipt.Delivery == BroadcastDeliveryDelivered {
+			receipt.DeliveredAt = nullTimePointer(row.SentAt)
+		}
+		if row.ResentAt.Valid {
+			receipt.ResendDelivery = deliveryState(row.ResendStatus, row.ResendDeliveryStatus)
+		}
+
+		switch receipt.Delivery {
+		case BroadcastDeliveryDelivered:
+			result.Delivered++
+		case BroadcastDeliveryFailed:
+			result.Failed++
+		case BroadcastDeliveryPending:
+			result.Pending++
+		default:
+			result.NotSent++
+		}
+		if row.ReadAt.Valid {
+			result.Read++
+		} else {
+			result.Unread++
+		}
+		result.Receipts = append(result.Receipts, receipt)
+	}
+	return result, nil
+}
+
+// MarkRead records that a recipient has read a broadcast. Reading it again
+// keeps the first time.
+func (s *BroadcastService) MarkRead(ctx context.Context, broadcastID, userID int64) error {
+	rows, err := s.querier.MarkBroadcastRead(ctx, db.MarkBroadcastReadParams{BroadcastID: broadcastID, UserID: userID})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to mark broadcast read", "broadcast_id", broadcastID, "user_id", userID, "error", err)
+		return ErrInternalServer
+	}
+	if rows == 0 {
+		return ErrBroadcastNotFound
+	}
+	return nil
+}
+
+// ResendUnreadBySMS sends a sent broadcast again by SMS to each recipient who
+// has not read it. Recipients who already got it by SMS, or were re-sent it
+// before, are left out. It returns the number of messages queued.
+func (s *BroadcastService) ResendUnreadBySMS(ctx context.Context, broadcastID int64) (int, error) {
+	broadcast, err := s.getBroadcast(ctx, broadcastID)
+	if err != nil {
+		return 0, err
+	}
+	if broadcast.Status != "sent" {
+		return 0, ErrBroadcastNotSent
+	}
+	rows, err := s.querier.ListBroadcastReceipts(ctx, broadcastID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list broadcast receipts", "broadcast_id", broadcastID, "error", err)
+		return 0, ErrInternalServer
+	}
+
+	category := NotificationCategoryBroadcasts
+	if broadcast.Urgent {
+		category = NotificationCategoryUrgent
+	}
+	queued := 0
+	for _, row := range rows {
+		if row.ReadAt.Valid || row.ResentAt.Valid || row.Phone == "" {
+			continue
+		}
+		if row.MessageType.String == OutboxMessageSMS && deliveryState(row.OutboxStatus, row.DeliveryStatus) != BroadcastDeliveryFailed {
+			continue
+		}
+
+		// Re-send what the recipient was sent, with their variables filled in
+		message := broadcast.Message
+		if row.Message.Valid {
+			message = row.Message.String
+		}
+		item, err := enqueueOutboxItem(ctx, s.querier, s.logger, db.CreateOutboxItemParams{
+			UserID:      sql.NullInt64{Int64: row.UserID, Valid: true},
+			Recipient:   row.Phone,
+			MessageType: OutboxMessageSMS,
+			Payload:     sql.NullString{String: message, Valid: true},
+			SendAt:      time.Now().Add(-1 * time.Second),
+			Category:    sql.NullString{String: category, Valid: true},
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to queue broadcast re-send", "broadcast_id", broadcastID, "user_id", row.UserID, "error", err)
+			return queued, ErrInternalServer
+		}
+		if err := s.querier.MarkBroadcastRecipientResent(ctx, db.MarkBroadcastRecipientResentParams{
+			ResendOutboxID: sql.NullInt64{Int64: item.OutboxID, Valid: true},
+			BroadcastID:    broadcastID,
+			UserID:         row.UserID,
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record broadcast re-send", "broadcast_id", broadcastID, "user_id", row.UserID, "error", err)
+			return queued, ErrInternalServer
+		}
+		queued++
+	}
+
+	s.logger.InfoContext(ctx, "Re-sent broadcast to unread recipients by SMS", "broadcast_id", broadcastID, "queued", queued)
+	return queued, nil
+}
+
+// refreshDeliveryCounts updates the sent and failed counts of recent
+// broadcasts from the delivery state of their outbox items.
+func (s *BroadcastService) refreshDeliveryCounts(ctx context.Context) {
+	since := sql.NullTime{Time: time.Now().Add(-deliveryCountWindow), Valid: true}
+	if _, err := s.querier.RefreshBroadcastDeliveryCounts(ctx, since); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to refresh broadcast delivery counts", "error", err)
+	}
+}
+
+func (s *BroadcastService) getBroadcast(ctx context.Context, broadcastID int64) (db.Broadcast, error) {
+	broadcast, err := s.querier.GetBroadcastByID(ctx, broadcastID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Broadcast{}, ErrBroadcastNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get broadcast", "broadcast_id", broadcastID, "error", err)
+		return db.Broadcast{}, ErrInternalServer
+	}
+	return broadcast, nil
+}
+
+func nullTimePointer(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
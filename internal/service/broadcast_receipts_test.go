@@ -0,0 +1,30 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeliveryState(t *testing.T) {
+	status := func(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ""} }
+	tests := []struct {
+		status         string
+		deliveryStatus string
+		want           string
+	}{
+		{"", "", BroadcastDeliveryNotSent},
+		{"pending", "", BroadcastDeliveryPending},
+		{"failed", "", BroadcastDeliveryPending},
+		{"sent", "", BroadcastDeliveryDelivered},
+		{"sent", "delivered", BroadcastDeliveryDelivered},
+		{"sent", "failed", BroadcastDeliveryFailed},
+		{"permanently_failed", "", BroadcastDeliveryFailed},
+		{"discarded", "", BroadcastDeliveryFailed},
+		{"suppressed", "", BroadcastDeliveryNotSent},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, deliveryState(status(tt.status), status(tt.deliveryStatus)), "status %q, delivery %q", tt.status, tt.deliveryStatus)
+	}
+}
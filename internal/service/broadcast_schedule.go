@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+
+	"github.com/robfig/cron/v3"
+)
+
+// BroadcastStatusRecurring marks a broadcast that is sent again on every run
+// of its cron schedule. Each run is created as a pending broadcast.
+const BroadcastStatusRecurring = "recurring"
+
+var (
+	ErrInvalidRecurrence    = errors.New("recurrence must be a five-field cron expression")
+	ErrBroadcastAlreadySent = errors.New("broadcast has already been sent")
+)
+
+// nextRecurrence returns the first run of a cron expression after t. The
+// expression is read in the notification time zone.
+func (s *BroadcastService) nextRecurrence(expr string, after time.Time) (time.Time, error) {
+	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+	schedule, err := parser.Parse(expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+	next := schedule.Next(after.In(s.location()))
+	if next.IsZero() {
+		return time.Time{}, fmt.Errorf("%w: %q never runs", ErrInvalidRecurrence, expr)
+	}
+	return next.UTC(), nil
+}
+
+// ScheduleBroadcast moves a broadcast that has not been sent yet to a new
+// time. For a recurring broadcast this is its next run; later runs follow
+// its cron schedule.
+func (s *BroadcastService) ScheduleBroadcast(ctx context.Context, broadcastID int64, scheduledAt time.Time) (db.Broadcast, error) {
+	broadcast, err := s.getBroadcast(ctx, broadcastID)
+	if err != nil {
+		return db.Broadcast{}, err
+	}
+	if broadcast.Status != BroadcastStatusPending && broadcast.Status != BroadcastStatusRecurring {
+		return db.Broadcast{}, ErrBroadcastAlreadySent
+	}
+
+	rows, err := s.querier.UpdateBroadcastSchedule(ctx, db.UpdateBroadcastScheduleParams{
+		ScheduledAt: sql.NullTime{Time: scheduledAt.UTC(), Valid: true},
+		BroadcastID: broadcastID,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to schedule broadcast", "broadcast_id", broadcastID, "error", err)
+		return db.Broadcast{}, ErrInternalServer
+	}
+	if rows == 0 {
+		// Picked up for sending in the meantime
+		return db.Broadcast{}, ErrBroadcastAlreadySent
+	}
+
+	s.logger.InfoContext(ctx, "Broadcast scheduled", "broadcast_id", broadcastID, "scheduled_at", scheduledAt)
+	return s.getBroadcast(ctx, broadcastID)
+}
+
+// startRecurringBroadcasts creates a pending broadcast for each recurring
+// broadcast that is due, resolving its audience afresh, and moves the
+// recurring broadcast on to its next run.
+func (s *BroadcastService) startRecurringBroadcasts(ctx context.Context) {
+	due, err := s.querier.ListDueRecurringBroadcasts(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list due recurring broadcasts", "error", err)
+		return
+	}
+
+	for _, series := range due {
+		next, err := s.nextRecurrence(series.RecurrenceCron.String, time.Now())
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Recurring broadcast has an invalid schedule", "broadcast_id", series.BroadcastID, "error", err)
+			continue
+		}
+		// Move on first so a run is never sent twice
+		rows, err := s.querier.UpdateBroadcastSchedule(ctx, db.UpdateBroadcastScheduleParams{
+			ScheduledAt: sql.NullTime{Time: next, Valid: true},
+			BroadcastID: series.BroadcastID,
+		})
+		if err != nil || rows == 0 {
+			s.logger.ErrorContext(ctx, "Failed to move recurring broadcast to its next run", "broadcast_id", series.BroadcastID, "error", err)
+			continue
+		}
+
+		var filter AudienceFilter
+		if series.AudienceFilter.Valid {
+			if err := json.Unmarshal([]byte(series.AudienceFilter.String), &filter); err != nil {
+				s.logger.ErrorContext(ctx, "Recurring broadcast has an invalid audience filter", "broadcast_id", series.BroadcastID, "error", err)
+				continue
+			}
+		}
+		run, err := s.CreateBroadcast(ctx, CreateBroadcastParams{
+			Title:             series.Title,
+			Message:           series.Message,
+			Audience:          series.Audience,
+			Filter:            filter,
+			SenderUserID:      series.SenderUserID,
+			PushEnabled:       series.PushEnabled,
+			Urgent:            series.Urgent,
+			ParentBroadcastID: series.BroadcastID,
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to start recurring broadcast", "broadcast_id", series.BroadcastID, "error", err)
+			continue
+		}
+		s.logger.InfoContext(ctx, "Started recurring broadcast", "broadcast_id", series.BroadcastID, "run_broadcast_id", run.BroadcastID, "next_run", next)
+	}
+}
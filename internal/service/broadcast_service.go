@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -14,22 +15,38 @@ import (
 
 // BroadcastService handles broadcast processing and delivery
 type BroadcastService struct {
-	querier db.Querier
-	logger  *slog.Logger
-	cfg     *config.Config
+	querier     db.Querier
+	preferences *NotificationPreferencesService
+	schedules   *ScheduleService
+	logger      *slog.Logger
+	cfg         *config.Config
+	events      *EventBroker
 }
 
+// errRecipientOptedOut marks a recipient skipped because of their notification preferences
+var errRecipientOptedOut = errors.New("recipient opted out of broadcasts")
+
 // NewBroadcastService creates a new BroadcastService
-func NewBroadcastService(querier db.Querier, logger *slog.Logger, cfg *config.Config) *BroadcastService {
+func NewBroadcastService(querier db.Querier, preferences *NotificationPreferencesService, schedules *ScheduleService, logger *slog.Logger, cfg *config.Config) *BroadcastService {
 	return &BroadcastService{
-		querier: querier,
-		logger:  logger.With("service", "BroadcastService"),
-		cfg:     cfg,
+		querier:     querier,
+		preferences: preferences,
+		schedules:   schedules,
+		logger:      logger.With("service", "BroadcastService"),
+		cfg:         cfg,
 	}
 }
 
+// SetEventBroker enables publishing sent broadcasts to the real-time stream
+func (s *BroadcastService) SetEventBroker(events *EventBroker) {
+	s.events = events
+}
+
 // ProcessPendingBroadcasts processes all pending broadcasts and creates outbox entries
 func (s *BroadcastService) ProcessPendingBroadcasts(ctx context.Context) (int, error) {
+	s.refreshDeliveryCounts(ctx)
+	s.startRecurringBroadcasts(ctx)
+
 	pendingBroadcasts, err := s.querier.ListPendingBroadcasts(ctx)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "Failed to get pending broadcasts", "error", err)
@@ -83,8 +100,7 @@ func (s *BroadcastService) processBroadcast(ctx context.Context, broadcast db.Br
 		return fmt.Errorf("failed to update broadcast status to sending: %w", err)
 	}
 
-	// Get recipients based on audience
-	recipients, err := s.getRecipients(ctx, broadcast.Audience)
+	recipients, err := s.broadcastRecipients(ctx, broadcast)
 	if err != nil {
 		return fmt.Errorf("failed to get recipients: %w", err)
 	}
@@ -102,21 +118,19 @@ func (s *BroadcastService) processBroadcast(ctx context.Context, broadcast db.Br
 		return err
 	}
 
-	// Create outbox entries for push notifications (if enabled)
-	var outboxCount int64
-	if broadcast.PushEnabled {
-		outboxCount, err = s.createPushOutboxEntries(ctx, broadcast, recipients)
-		if err != nil {
-			return fmt.Errorf("failed to create push outbox entries: %w", err)
-		}
+	// Render each recipient's message and queue it (if push is enabled)
+	outboxCount, err := s.queueRecipientMessages(ctx, broadcast, recipients)
+	if err != nil {
+		return fmt.Errorf("failed to create push outbox entries: %w", err)
 	}
 
-	// Update broadcast status to sent
+	// Update broadcast status to sent. The counts are filled in as the outbox
+	// items are delivered.
 	_, err = s.querier.UpdateBroadcastStatus(ctx, db.UpdateBroadcastStatusParams{
 		BroadcastID: broadcast.BroadcastID,
 		Status:      "sent",
 		SentAt:      sql.NullTime{Time: time.Now(), Valid: true},
-		SentCount:   sql.NullInt64{Int64: outboxCount, Valid: true},
+		SentCount:   sql.NullInt64{Int64: 0, Valid: true},
 		FailedCount: sql.NullInt64{Int64: 0, Valid: true},
 	})
 	if err != nil {
@@ -128,87 +142,132 @@ func (s *BroadcastService) processBroadcast(ctx context.Context, broadcast db.Br
 		"recipients", len(recipients),
 		"outbox_entries", outboxCount)
 
+	userIDs := make([]int64, 0, len(recipients))
+	for _, recipient := range recipients {
+		userIDs = append(userIDs, recipient.UserID)
+	}
+	s.events.Publish(DomainEvent{
+		Type: EventBroadcastSent,
+		Data: map[string]interface{}{
+			"broadcast_id": broadcast.BroadcastID,
+			"title":        broadcast.Title,
+			"message":      broadcast.Message,
+			"audience":     broadcast.Audience,
+		},
+		UserIDs: userIDs,
+	})
+
 	return nil
 }
 
-// getRecipients gets the list of users based on the audience filter
-func (s *BroadcastService) getRecipients(ctx context.Context, audience string) ([]db.ListUsersRow, error) {
-	allUsers, err := s.querier.ListUsers(ctx, nil)
-	if err != nil {
-		return nil, err
+// queueRecipientMessages renders the message for each recipient and, when
+// push is enabled, queues it on their preferred channel. Recipients who have
+// opted out of broadcasts are skipped unless the broadcast is urgent; quiet
+// hours are left to the dispatcher. Each recipient's rendered message and
+// outbox item are recorded with the broadcast.
+func (s *BroadcastService) queueRecipientMessages(ctx context.Context, broadcast db.Broadcast, recipients []BroadcastRecipient) (int64, error) {
+	category := NotificationCategoryBroadcasts
+	if broadcast.Urgent {
+		category = NotificationCategoryUrgent
 	}
+	renderer := s.newMessageRenderer(broadcast.Message)
 
-	var recipients []db.ListUsersRow
-	switch audience {
-	case "all":
-		recipients = allUsers
-	case "admins":
-		for _, user := range allUsers {
-			if user.Role == "admin" {
-				recipients = append(recipients, user)
+	var count, optedOut int64
+	for _, recipient := range recipients {
+		message, templated := renderer.render(ctx, recipient)
+		var outboxID sql.NullInt64
+		if broadcast.PushEnabled {
+			item, err := s.queueRecipientMessage(ctx, broadcast, recipient, category, message)
+			switch {
+			case errors.Is(err, errRecipientOptedOut):
+				optedOut++
+			case err != nil:
+				s.logger.ErrorContext(ctx, "Failed to create outbox entry for broadcast",
+					"user_id", recipient.UserID,
+					"broadcast_id", broadcast.BroadcastID,
+					"error", err)
+			default:
+				outboxID = sql.NullInt64{Int64: item.OutboxID, Valid: true}
+				count++
 			}
 		}
-	case "owls":
-		for _, user := range allUsers {
-			if user.Role == "owl" || user.Role == "" {
-				recipients = append(recipients, user)
-			}
+
+		// The outbox item is the recipient's delivery receipt
+		if err := s.querier.SetBroadcastRecipientOutboxItem(ctx, db.SetBroadcastRecipientOutboxItemParams{
+			BroadcastID: broadcast.BroadcastID,
+			UserID:      recipient.UserID,
+			OutboxID:    outboxID,
+			Message:     sql.NullString{String: message, Valid: templated},
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record broadcast recipient",
+				"user_id", recipient.UserID,
+				"broadcast_id", broadcast.BroadcastID,
+				"outbox_id", outboxID.Int64,
+				"error", err)
 		}
-	case "active":
-		// For now, return all users. In future, filter by last activity
-		recipients = allUsers
-	default:
-		return nil, fmt.Errorf("unknown audience: %s", audience)
 	}
 
-	return recipients, nil
+	if optedOut > 0 {
+		s.logger.InfoContext(ctx, "Skipped broadcast recipients who opted out", "broadcast_id", broadcast.BroadcastID, "count", optedOut)
+	}
+	return count, nil
 }
 
-// createPushOutboxEntries creates outbox entries for push notifications
-func (s *BroadcastService) createPushOutboxEntries(ctx context.Context, broadcast db.Broadcast, recipients []db.ListUsersRow) (int64, error) {
-	// Create push notification payload
-	pushPayload := map[string]interface{}{
+// queueRecipientMessage queues a broadcast message for one recipient on their
+// preferred channel.
+func (s *BroadcastService) queueRecipientMessage(ctx context.Context, broadcast db.Broadcast, recipient BroadcastRecipient, category, message string) (db.Outbox, error) {
+	prefs, err := s.preferences.Get(ctx, recipient.UserID)
+	if err != nil {
+		return db.Outbox{}, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	if !prefs.Allows(category) {
+		return db.Outbox{}, errRecipientOptedOut
+	}
+
+	payloadBytes, err := json.Marshal(map[string]interface{}{
 		"type":  "broadcast",
 		"title": "New Message",
-		"body":  broadcast.Message,
+		"body":  message,
 		"data": map[string]interface{}{
 			"broadcast_id": broadcast.BroadcastID,
 			"type":         "broadcast",
 		},
-	}
-
-	payloadBytes, err := json.Marshal(pushPayload)
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to marshal push payload: %w", err)
+		return db.Outbox{}, fmt.Errorf("failed to marshal push payload: %w", err)
+	}
+	params := db.CreateOutboxItemParams{
+		UserID:      sql.NullInt64{Int64: recipient.UserID, Valid: true},
+		Recipient:   "", // Not used for push notifications
+		MessageType: OutboxMessagePush,
+		Payload:     sql.NullString{String: string(payloadBytes), Valid: true},
+		SendAt:      time.Now().Add(-1 * time.Second),
+		Category:    sql.NullString{String: category, Valid: true},
 	}
 
-	var count int64
-	for _, recipient := range recipients {
-		// Create outbox entry for push notification
-		_, err := s.querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
-			UserID:      sql.NullInt64{Int64: recipient.UserID, Valid: true},
-			Recipient:   "", // Not used for push notifications
-			MessageType: "push",
-			Payload:     sql.NullString{String: string(payloadBytes), Valid: true},
-			SendAt:      time.Now().Add(-1 * time.Second),
-		})
+	switch prefs.Channel {
+	case NotificationChannelSMS:
+		params.MessageType = OutboxMessageSMS
+		params.Recipient = recipient.Phone
+		params.Payload = sql.NullString{String: message, Valid: true}
+	case NotificationChannelEmail:
+		// Only a confirmed address is used; otherwise the user gets a push
+		target, err := s.querier.GetUserNotificationTarget(ctx, recipient.UserID)
 		if err != nil {
-			s.logger.ErrorContext(ctx, "Failed to create outbox entry for push notification",
-				"user_id", recipient.UserID,
-				"broadcast_id", broadcast.BroadcastID,
-				"error", err)
-			continue
+			s.logger.ErrorContext(ctx, "Failed to get email for broadcast recipient", "user_id", recipient.UserID, "error", err)
+		} else if target.Email.Valid {
+			emailBytes, err := json.Marshal(map[string]string{
+				"subject": broadcast.Title,
+				"body":    message,
+			})
+			if err != nil {
+				return db.Outbox{}, fmt.Errorf("failed to marshal email payload: %w", err)
+			}
+			params.MessageType = OutboxMessageEmail
+			params.Recipient = target.Email.String
+			params.Payload = sql.NullString{String: string(emailBytes), Valid: true}
 		}
-		count++
 	}
 
-	return count, nil
-}
-
-// ScheduleBroadcast schedules a broadcast for future delivery
-func (s *BroadcastService) ScheduleBroadcast(ctx context.Context, broadcastID int64, scheduledAt time.Time) error {
-	// This is handled by the ProcessPendingBroadcasts method which checks the scheduled_at field
-	// The cron job should call ProcessPendingBroadcasts regularly to handle scheduled broadcasts
-	s.logger.InfoContext(ctx, "Broadcast scheduled", "broadcast_id", broadcastID, "scheduled_at", scheduledAt)
-	return nil
+	return enqueueOutboxItem(ctx, s.querier, s.logger, params)
 }
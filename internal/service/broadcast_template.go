@@ -0,0 +1,332 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+// Variables a broadcast message can use, e.g. "Hi {{.first_name}}". They are
+// filled in for each recipient when the broadcast is sent.
+const (
+	BroadcastVariableName          = "name"
+	BroadcastVariableFirstName     = "first_name"
+	BroadcastVariableNextShift     = "next_shift"     // The recipient's next booked shift
+	BroadcastVariableUnfilledSlots = "unfilled_slots" // Unbooked shifts in the coming week
+)
+
+const (
+	maxBroadcastTemplateNameLength = 100
+	// unfilledSlotsWindow is how far ahead unfilled_slots looks
+	unfilledSlotsWindow = 7 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidBroadcastMessage    = errors.New("invalid broadcast message")
+	ErrInvalidBroadcastTemplate   = errors.New("broadcast template needs a name of at most 100 characters, a title and a message")
+	ErrBroadcastTemplateNameTaken = errors.New("a broadcast template with that name already exists")
+	ErrBroadcastTemplateNotFound  = errors.New("broadcast template not found")
+)
+
+// sampleBroadcastVariables are used to check a message renders before it is saved
+var sampleBroadcastVariables = map[string]interface{}{
+	BroadcastVariableName:          "Sam Jones",
+	BroadcastVariableFirstName:     "Sam",
+	BroadcastVariableNextShift:     "Sat 7 Jun at 22:00 (Night Patrol)",
+	BroadcastVariableUnfilledSlots: 3,
+}
+
+// broadcastVariablePattern matches the start of a variable such as
+// "{{.name}}" or "{{- .name }}". Other braces are left as written.
+var broadcastVariablePattern = regexp.MustCompile(`\{\{-?\s*\.`)
+
+// isTemplated reports whether a message uses variables.
+func isTemplated(message string) bool {
+	return broadcastVariablePattern.MatchString(message)
+}
+
+func parseBroadcastMessage(message string) (*template.Template, error) {
+	return template.New("broadcast").Option("missingkey=error").Parse(message)
+}
+
+// referencedVariables returns the variables a parsed message uses, so values
+// that need a lookup are only fetched when the message shows them.
+func referencedVariables(tmpl *template.Template) map[string]bool {
+	used := make(map[string]bool)
+	var walk func(node parse.Node)
+	walk = func(node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.RangeNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.WithNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			walk(n.Pipe)
+		case *parse.ChainNode:
+			walk(n.Node)
+		case *parse.FieldNode:
+			used[n.Ident[0]] = true
+		case *parse.DotNode:
+			// The message prints every variable
+			for name := range sampleBroadcastVariables {
+				used[name] = true
+			}
+		}
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			walk(t.Tree.Root)
+		}
+	}
+	return used
+}
+
+// validateBroadcastMessage checks that a message only uses known variables.
+func validateBroadcastMessage(message string) error {
+	if !isTemplated(message) {
+		return nil
+	}
+	tmpl, err := parseBroadcastMessage(message)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBroadcastMessage, err)
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, sampleBroadcastVariables); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBroadcastMessage, err)
+	}
+	return nil
+}
+
+// messageRenderer fills in a broadcast's variables for each recipient.
+// Values shared by all recipients are looked up once.
+type messageRenderer struct {
+	service       *BroadcastService
+	message       string
+	tmpl          *template.Template
+	used          map[string]bool
+	unfilledSlots interface{}
+}
+
+func (s *BroadcastService) newMessageRenderer(message string) *messageRenderer {
+	renderer := &messageRenderer{service: s, message: message}
+	if !isTemplated(message) {
+		return renderer
+	}
+	tmpl, err := parseBroadcastMessage(message)
+	if err != nil {
+		// Messages are validated when they are saved, so this is a broadcast
+		// created before templates; it goes out as written
+		s.logger.Warn("Broadcast message is not a valid template", "error", err)
+		return renderer
+	}
+	renderer.tmpl = tmpl
+	renderer.used = referencedVariables(tmpl)
+	return renderer
+}
+
+// render returns the message for a recipient, and whether it was templated.
+func (r *messageRenderer) render(ctx context.Context, recipient BroadcastRecipient) (string, bool) {
+	if r.tmpl == nil {
+		return r.message, false
+	}
+
+	var body bytes.Buffer
+	if err := r.tmpl.Execute(&body, r.variables(ctx, recipient)); err != nil {
+		r.service.logger.ErrorContext(ctx, "Failed to render broadcast message", "user_id", recipient.UserID, "error", err)
+		return r.message, false
+	}
+	return body.String(), true
+}
+
+func (r *messageRenderer) variables(ctx context.Context, recipient BroadcastRecipient) map[string]interface{} {
+	s := r.service
+	name := strings.TrimSpace(recipient.Name)
+	if name == "" {
+		name = "Night Owl"
+	}
+	vars := map[string]interface{}{
+		BroadcastVariableName:      name,
+		BroadcastVariableFirstName: strings.Fields(name)[0],
+	}
+
+	if r.used[BroadcastVariableNextShift] {
+		vars[BroadcastVariableNextShift] = "none booked"
+		next, err := s.querier.GetNextBookingForUser(ctx, db.GetNextBookingForUserParams{
+			UserID:     recipient.UserID,
+			ShiftStart: time.Now().UTC(),
+		})
+		switch {
+		case err == nil:
+			vars[BroadcastVariableNextShift] = fmt.Sprintf("%s (%s)", next.ShiftStart.In(s.location()).Format("Mon 2 Jan at 15:04"), next.ScheduleName)
+		case !errors.Is(err, sql.ErrNoRows):
+			s.logger.ErrorContext(ctx, "Failed to get next booking for broadcast", "user_id", recipient.UserID, "error", err)
+			vars[BroadcastVariableNextShift] = "unknown"
+		}
+	}
+
+	if !r.used[BroadcastVariableUnfilledSlots] {
+		return vars
+	}
+	if r.unfilledSlots == nil {
+		from := time.Now().UTC()
+		to := from.Add(unfilledSlotsWindow)
+		slots, err := s.schedules.GetUpcomingAvailableSlots(ctx, &from, &to, nil)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to count unfilled slots for broadcast", "error", err)
+			r.unfilledSlots = "unknown"
+		} else {
+			r.unfilledSlots = len(slots)
+		}
+	}
+	vars[BroadcastVariableUnfilledSlots] = r.unfilledSlots
+	return vars
+}
+
+// BroadcastTemplateService manages reusable broadcast wording.
+type BroadcastTemplateService struct {
+	querier db.Querier
+	logger  *slog.Logger
+}
+
+// NewBroadcastTemplateService creates a new BroadcastTemplateService.
+func NewBroadcastTemplateService(querier db.Querier, logger *slog.Logger) *BroadcastTemplateService {
+	return &BroadcastTemplateService{
+		querier: querier,
+		logger:  logger.With("service", "BroadcastTemplateService"),
+	}
+}
+
+// ListTemplates returns every template by name.
+func (s *BroadcastTemplateService) ListTemplates(ctx context.Context) ([]db.BroadcastTemplate, error) {
+	templates, err := s.querier.ListBroadcastTemplates(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list broadcast templates", "error", err)
+		return nil, ErrInternalServer
+	}
+	return templates, nil
+}
+
+// GetTemplate returns a template.
+func (s *BroadcastTemplateService) GetTemplate(ctx context.Context, templateID int64) (db.BroadcastTemplate, error) {
+	tmpl, err := s.querier.GetBroadcastTemplate(ctx, templateID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.BroadcastTemplate{}, ErrBroadcastTemplateNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get broadcast template", "template_id", templateID, "error", err)
+		return db.BroadcastTemplate{}, ErrInternalServer
+	}
+	return tmpl, nil
+}
+
+// CreateTemplate saves a new template.
+func (s *BroadcastTemplateService) CreateTemplate(ctx context.Context, name, title, message string, createdBy int64) (db.BroadcastTemplate, error) {
+	name, title, err := normalizeBroadcastTemplate(name, title, message)
+	if err != nil {
+		return db.BroadcastTemplate{}, err
+	}
+	tmpl, err := s.querier.CreateBroadcastTemplate(ctx, db.CreateBroadcastTemplateParams{
+		Name:      name,
+		Title:     title,
+		Message:   message,
+		CreatedBy: sql.NullInt64{Int64: createdBy, Valid: createdBy != 0},
+	})
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return db.BroadcastTemplate{}, ErrBroadcastTemplateNameTaken
+		}
+		s.logger.ErrorContext(ctx, "Failed to create broadcast template", "name", name, "error", err)
+		return db.BroadcastTemplate{}, ErrInternalServer
+	}
+	s.logger.InfoContext(ctx, "Broadcast template created", "template_id", tmpl.TemplateID, "name", name)
+	return tmpl, nil
+}
+
+// UpdateTemplate replaces a template's name and wording. Broadcasts already
+// created from it are not changed.
+func (s *BroadcastTemplateService) UpdateTemplate(ctx context.Context, templateID int64, name, title, message string) (db.BroadcastTemplate, error) {
+	name, title, err := normalizeBroadcastTemplate(name, title, message)
+	if err != nil {
+		return db.BroadcastTemplate{}, err
+	}
+	tmpl, err := s.querier.UpdateBroadcastTemplate(ctx, db.UpdateBroadcastTemplateParams{
+		Name:       name,
+		Title:      title,
+		Message:    message,
+		TemplateID: templateID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.BroadcastTemplate{}, ErrBroadcastTemplateNotFound
+		}
+		if isUniqueConstraintError(err) {
+			return db.BroadcastTemplate{}, ErrBroadcastTemplateNameTaken
+		}
+		s.logger.ErrorContext(ctx, "Failed to update broadcast template", "template_id", templateID, "error", err)
+		return db.BroadcastTemplate{}, ErrInternalServer
+	}
+	return tmpl, nil
+}
+
+// DeleteTemplate deletes a template.
+func (s *BroadcastTemplateService) DeleteTemplate(ctx context.Context, templateID int64) error {
+	rows, err := s.querier.DeleteBroadcastTemplate(ctx, templateID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete broadcast template", "template_id", templateID, "error", err)
+		return ErrInternalServer
+	}
+	if rows == 0 {
+		return ErrBroadcastTemplateNotFound
+	}
+	s.logger.InfoContext(ctx, "Broadcast template deleted", "template_id", templateID)
+	return nil
+}
+
+func normalizeBroadcastTemplate(name, title, message string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	title = strings.TrimSpace(title)
+	if name == "" || len(name) > maxBroadcastTemplateNameLength || title == "" || strings.TrimSpace(message) == "" {
+		return "", "", ErrInvalidBroadcastTemplate
+	}
+	if err := validateBroadcastMessage(message); err != nil {
+		return "", "", err
+	}
+	return name, title, nil
+}
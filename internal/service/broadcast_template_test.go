@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTemplated(t *testing.T) {
+	tests := map[string]bool{
+		"Hi {{.first_name}}":           true,
+		"Hi {{ .name }}":               true,
+		"Hi {{- .name -}}":             true,
+		"Hi {{.nickname":               true,
+		"Patrol tonight":               false,
+		"Braai at {{ the hall }} at 7": false,
+		"JSON looks like {{\"a\": 1}}": false,
+	}
+	for message, want := range tests {
+		assert.Equal(t, want, isTemplated(message), "message %q", message)
+	}
+}
+
+func TestReferencedVariables(t *testing.T) {
+	tests := []struct {
+		message string
+		want    []string
+	}{
+		{"Hi {{.first_name}}", []string{BroadcastVariableFirstName}},
+		{"{{.name}}, next: {{.next_shift}}", []string{BroadcastVariableName, BroadcastVariableNextShift}},
+		{"{{.first_name}}{{if .unfilled_slots}}: {{.unfilled_slots}} open{{end}}", []string{BroadcastVariableFirstName, BroadcastVariableUnfilledSlots}},
+		{"{{.}}", []string{BroadcastVariableName, BroadcastVariableFirstName, BroadcastVariableNextShift, BroadcastVariableUnfilledSlots}},
+	}
+	for _, tt := range tests {
+		tmpl, err := parseBroadcastMessage(tt.message)
+		require.NoError(t, err)
+		used := referencedVariables(tmpl)
+		assert.Len(t, used, len(tt.want), "message %q", tt.message)
+		for _, name := range tt.want {
+			assert.True(t, used[name], "message %q uses %s", tt.message, name)
+		}
+	}
+}
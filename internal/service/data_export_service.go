@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/utils"
+)
+
+var (
+	// ErrInvalidExportDataset is returned when an unknown dataset is requested.
+	ErrInvalidExportDataset = errors.New("unknown export dataset")
+	// ErrInvalidExportColumn is returned when a requested column does not exist in the dataset.
+	ErrInvalidExportColumn = errors.New("unknown export column")
+	// ErrExportFilterNotSupported is returned when report-only filters are used on another dataset.
+	ErrExportFilterNotSupported = errors.New("severity and category filters only apply to reports")
+)
+
+// Tabular export datasets
+const (
+	ExportDatasetReports    = "reports"
+	ExportDatasetBookings   = "bookings"
+	ExportDatasetAttendance = "attendance"
+)
+
+// Tabular export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+const (
+	// exportPageSize is the number of rows read from the database at a time
+	exportPageSize = 500
+	// exportTimeLayout is the timestamp format spreadsheets recognise as a date
+	exportTimeLayout = "2006-01-02 15:04:05"
+)
+
+// exportEndOfTime is the upper bound used when an export has no end date.
+var exportEndOfTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// DataExportRequest describes a tabular export of one dataset.
+type DataExportRequest struct {
+	Dataset string
+	Format  string
+	// Columns lists the columns to export, in order. Empty selects every column.
+	Columns []string
+	Filter  ReportFilter
+}
+
+// exportPageFunc returns the rows after afterID as values in the dataset's
+// column order, together with the ID of the last row returned.
+type exportPageFunc func(ctx context.Context, querier db.Querier, filter ReportFilter, afterID int64) ([][]interface{}, int64, error)
+
+// exportDataset describes the columns of a dataset and how to page through it.
+type exportDataset struct {
+	sheetName string
+	columns   []string
+	page      exportPageFunc
+	// reportFilters is set when severity and category filters apply
+	reportFilters bool
+	// startedOnly limits the dataset to shifts that have already started
+	startedOnly bool
+}
+
+var exportDatasets = map[string]exportDataset{
+	ExportDatasetReports: {
+		sheetName: "Reports",
+		columns: []string{
+			"report_id", "created_at", "severity", "severity_label", "category_id", "category_name",
+			"message", "category_fields", "latitude", "longitude", "gps_accuracy",
+			"booking_id", "schedule_id", "schedule_name", "reporter", "archived_at",
+		},
+		page:          reportExportPage,
+		reportFilters: true,
+	},
+	ExportDatasetBookings: {
+		sheetName: "Bookings",
+		columns: []string{
+			"booking_id", "shift_start", "shift_end", "schedule_id", "schedule_name", "user_id", "user_name",
+			"buddy_name", "checked_in_at", "checked_out_at", "report_count", "created_at",
+		},
+		page: bookingExportPage,
+	},
+	ExportDatasetAttendance: {
+		sheetName: "Attendance",
+		columns: []string{
+			"booking_id", "shift_date", "shift_start", "shift_end", "schedule_id", "schedule_name", "user_id", "user_name",
+			"attended", "checked_in_at", "minutes_late", "checked_out_at", "report_count",
+		},
+		page:        attendanceExportPage,
+		startedOnly: true,
+	},
+}
+
+// ExportColumns returns the columns available in a dataset, in default order.
+func ExportColumns(dataset string) ([]string, error) {
+	ds, ok := exportDatasets[dataset]
+	if !ok {
+		return nil, ErrInvalidExportDataset
+	}
+	return append([]string(nil), ds.columns...), nil
+}
+
+// exportNullable returns the value of a nullable column, or nil when it is NULL.
+func exportNullable(v driver.Valuer) interface{} {
+	value, _ := v.Value()
+	return value
+}
+
+// exportNullableName masks a nullable name, keeping NULL as nil.
+func exportNullableName(name sql.NullString) interface{} {
+	if !name.Valid || strings.TrimSpace(name.String) == "" {
+		return nil
+	}
+	return utils.MaskName(name.String)
+}
+
+// exportTimeRange returns the filter's date range with open ends filled in.
+func exportTimeRange(filter ReportFilter) (time.Time, time.Time) {
+	from, to := time.Time{}, exportEndOfTime
+	if filter.From != nil {
+		from = filter.From.UTC()
+	}
+	if filter.To != nil {
+		to = filter.To.UTC()
+	}
+	return from, to
+}
+
+// exportOptionalInt converts an optional filter to a nullable query argument.
+func exportOptionalInt(value *int64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
+
+func reportExportPage(ctx context.Context, querier db.Querier, filter ReportFilter, afterID int64) ([][]interface{}, int64, error) {
+	from, to := exportTimeRange(filter)
+	reports, err := querier.ListReportsForExport(ctx, db.ListReportsForExportParams{
+		AfterID:     afterID,
+		CreatedFrom: sql.NullTime{Time: from, Valid: true},
+		CreatedTo:   sql.NullTime{Time: to, Valid: true},
+		Severity:    exportOptionalInt(filter.Severity),
+		CategoryID:  exportOptionalInt(filter.CategoryID),
+		ScheduleID:  exportOptionalInt(filter.ScheduleID),
+		UserID:      exportOptionalInt(filter.UserID),
+		PageSize:    exportPageSize,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows := make([][]interface{}, 0, len(reports))
+	for _, r := range reports {
+		var categoryName interface{}
+		if r.CategoryID.Valid {
+			categoryName = r.CategoryName
+		}
+		rows = append(rows, []interface{}{
+			r.ReportID, exportNullable(r.CreatedAt), r.Severity, SeverityLabel(r.Severity),
+			exportNullable(r.CategoryID), categoryName,
+			r.Message.String, exportNullable(r.CategoryFields),
+			exportNullable(r.Latitude), exportNullable(r.Longitude), exportNullable(r.GpsAccuracy),
+			exportNullable(r.BookingID), r.ScheduleID, r.ScheduleName,
+			utils.MaskName(r.UserName), exportNullable(r.ArchivedAt),
+		})
+		afterID = r.ReportID
+	}
+	return rows, afterID, nil
+}
+
+// listBookingsForExport reads one page of bookings for the booking and attendance datasets.
+func listBookingsForExport(ctx context.Context, querier db.Querier, filter ReportFilter, afterID int64) ([]db.ListBookingsForExportRow, error) {
+	from, to := exportTimeRange(filter)
+	return querier.ListBookingsForExport(ctx, db.ListBookingsForExportParams{
+		AfterID:    afterID,
+		ShiftFrom:  from,
+		ShiftTo:    to,
+		ScheduleID: exportOptionalInt(filter.ScheduleID),
+		UserID:     exportOptionalInt(filter.UserID),
+		PageSize:   exportPageSize,
+	})
+}
+
+func bookingExportPage(ctx context.Context, querier db.Querier, filter ReportFilter, afterID int64) ([][]interface{}, int64, error) {
+	bookings, err := listBookingsForExport(ctx, querier, filter, afterID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows := make([][]interface{}, 0, len(bookings))
+	for _, b := range bookings {
+		rows = append(rows, []interface{}{
+			b.BookingID, b.ShiftStart, b.ShiftEnd, b.ScheduleID, b.ScheduleName, b.UserID, utils.MaskName(b.UserName),
+			exportNullableName(b.BuddyName), exportNullable(b.CheckedInAt), exportNullable(b.CheckedOutAt),
+			b.ReportCount, exportNullable(b.CreatedAt),
+		})
+		afterID = b.BookingID
+	}
+	return rows, afterID, nil
+}
+
+func attendanceExportPage(ctx context.Context, querier db.Querier, filter ReportFilter, afterID int64) ([][]interface{}, int64, error) {
+	bookings, err := listBookingsForExport(ctx, querier, filter, afterID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows := make([][]interface{}, 0, len(bookings))
+	for _, b := range bookings {
+		var minutesLate interface{}
+		if b.CheckedInAt.Valid {
+			late := int64(b.CheckedInAt.Time.Sub(b.ShiftStart).Minutes())
+			if late < 0 {
+				late = 0
+			}
+			minutesLate = late
+		}
+		rows = append(rows, []interface{}{
+			b.BookingID, b.ShiftStart.UTC().Format("2006-01-02"), b.ShiftStart, b.ShiftEnd,
+			b.ScheduleID, b.ScheduleName, b.UserID, utils.MaskName(b.UserName),
+			b.CheckedInAt.Valid, exportNullable(b.CheckedInAt), minutesLate, exportNullable(b.CheckedOutAt),
+			b.ReportCount,
+		})
+		afterID = b.BookingID
+	}
+	return rows, afterID, nil
+}
+
+// exportTableWriter writes rows in one of the tabular export formats.
+type exportTableWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []interface{}) error
+	// Flush pushes buffered rows to the client between pages
+	Flush() error
+	Close() error
+}
+
+// csvExportWriter writes rows as CSV.
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+// csvFormulaPrefixes are the characters spreadsheets treat as the start of a
+// formula. Free text starting with one is prefixed with a quote so that an
+// opened export cannot execute anything a reporter typed.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+func csvExportValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(exportTimeLayout)
+	case string:
+		if v != "" && strings.ContainsRune(csvFormulaPrefixes, rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func (c *csvExportWriter) WriteHeader(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvExportWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = csvExportValue(value)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvExportWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvExportWriter) Close() error {
+	return c.Flush()
+}
+
+// xlsxExportWriter writes rows to a single sheet workbook.
+type xlsxExportWriter struct {
+	x *utils.XLSXWriter
+}
+
+func (x *xlsxExportWriter) WriteHeader(columns []string) error {
+	return x.x.WriteHeader(columns)
+}
+
+func (x *xlsxExportWriter) WriteRow(values []interface{}) error {
+	return x.x.WriteRow(values)
+}
+
+// Flush is a no-op; the workbook is compressed and flushed by the zip writer as it fills.
+func (x *xlsxExportWriter) Flush() error {
+	return nil
+}
+
+func (x *xlsxExportWriter) Close() error {
+	return x.x.Close()
+}
+
+// DataExportService streams reports, bookings and attendance as CSV or XLSX
+// for offline statistics. Names are masked in every export.
+type DataExportService struct {
+	querier      db.Querier
+	auditService *AuditService
+	logger       *slog.Logger
+}
+
+// NewDataExportService creates a new DataExportService.
+func NewDataExportService(querier db.Querier, auditService *AuditService, logger *slog.Logger) *DataExportService {
+	return &DataExportService{
+		querier:      querier,
+		auditService: auditService,
+		logger:       logger.With("service", "DataExportService"),
+	}
+}
+
+// resolveColumns returns the positions of the requested columns in the dataset.
+func (ds exportDataset) resolveColumns(requested []string) ([]string, []int, error) {
+	if len(requested) == 0 {
+		requested = ds.columns
+	}
+	positions := make(map[string]int, len(ds.columns))
+	for i, column := range ds.columns {
+		positions[column] = i
+	}
+
+	columns := make([]string, 0, len(requested))
+	indexes := make([]int, 0, len(requested))
+	seen := map[string]bool{}
+	for _, column := range requested {
+		column = strings.TrimSpace(column)
+		if column == "" || seen[column] {
+			continue
+		}
+		i, ok := positions[column]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: %s", ErrInvalidExportColumn, column)
+		}
+		seen[column] = true
+		columns = append(columns, column)
+		indexes = append(indexes, i)
+	}
+	if len(columns) == 0 {
+		return nil, nil, fmt.Errorf("%w: no columns selected", ErrInvalidExportColumn)
+	}
+	return columns, indexes, nil
+}
+
+// Export validates the request, records it in the audit trail and then
+// streams the matching rows to w a page at a time. The audit event is written
+// before any data so that no export leaves the system unlogged. It returns
+// the number of rows written.
+func (s *DataExportService) Export(ctx context.Context, w io.Writer, req DataExportRequest, actorUserID int64, ipAddress, userAgent string) (int, error) {
+	ds, ok := exportDatasets[req.Dataset]
+	if !ok {
+		return 0, ErrInvalidExportDataset
+	}
+	if req.Format != ExportFormatCSV && req.Format != ExportFormatXLSX {
+		return 0, ErrInvalidExportFormat
+	}
+	if !ds.reportFilters && (req.Filter.Severity != nil || req.Filter.CategoryID != nil) {
+		return 0, ErrExportFilterNotSupported
+	}
+	columns, indexes, err := ds.resolveColumns(req.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	filter := req.Filter
+	if ds.startedOnly {
+		now := time.Now().UTC()
+		if filter.To == nil || filter.To.After(now) {
+			filter.To = &now
+		}
+	}
+
+	if err := s.auditService.LogDataExported(ctx, actorUserID, req.Dataset, req.Format, columns, req.Filter.AuditDetails(), ipAddress, userAgent); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to log data export audit event, refusing export", "dataset", req.Dataset, "error", err)
+		return 0, ErrInternalServer
+	}
+
+	var tw exportTableWriter
+	switch req.Format {
+	case ExportFormatCSV:
+		tw = &csvExportWriter{w: csv.NewWriter(w)}
+	case ExportFormatXLSX:
+		x, err := utils.NewXLSXWriter(w, ds.sheetName)
+		if err != nil {
+			return 0, err
+		}
+		tw = &xlsxExportWriter{x: x}
+	}
+
+	if err := tw.WriteHeader(columns); err != nil {
+		return 0, err
+	}
+
+	written := 0
+	selected := make([]interface{}, len(indexes))
+	for afterID := int64(0); ; {
+		rows, lastID, err := ds.page(ctx, s.querier, filter, afterID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to read export page", "dataset", req.Dataset, "after_id", afterID, "error", err)
+			return written, ErrInternalServer
+		}
+		for _, row := range rows {
+			for i, index := range indexes {
+				selected[i] = row[index]
+			}
+			if err := tw.WriteRow(selected); err != nil {
+				return written, err
+			}
+			written++
+		}
+		if err := tw.Flush(); err != nil {
+			return written, err
+		}
+		if len(rows) < exportPageSize {
+			break
+		}
+		afterID = lastID
+	}
+
+	if err := tw.Close(); err != nil {
+		return written, err
+	}
+	return written, nil
+}
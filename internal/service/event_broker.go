@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Domain event types published to the real-time stream
+const (
+	EventBookingCreated    = "booking.created"
+	EventBookingCancelled  = "booking.cancelled"
+	EventBookingCheckedIn  = "booking.checked_in"
+	EventBookingCheckedOut = "booking.checked_out"
+	EventReportCreated     = "report.created"
+	EventBroadcastSent     = "broadcast.sent"
+	EventSOSRaised         = "sos.raised"
+	EventSOSAcknowledged   = "sos.acknowledged"
+	EventSOSStoodDown      = "sos.stood_down"
+	EventWatchlistMatch    = "watchlist.match"
+
+	// EventStreamReset tells a reconnecting client that events it missed are no
+	// longer buffered and it should refetch its data.
+	EventStreamReset = "stream.reset"
+)
+
+const (
+	// DefaultEventBufferSize is how many recent events are kept for clients resuming after a reconnect.
+	DefaultEventBufferSize = 1000
+	// eventSubscriberBuffer is how many events may queue for a subscriber before it is disconnected.
+	eventSubscriberBuffer = 64
+)
+
+// DomainEvent is an event as published by a service. Admins and the users in
+// UserIDs receive Data. Every other authenticated user receives Public, if set,
+// so owls can learn that a shift slot was taken without seeing who took it.
+type DomainEvent struct {
+	Type    string
+	Data    interface{}
+	UserIDs []int64
+	Public  interface{}
+}
+
+// StreamEvent is a domain event as delivered to one subscriber.
+type StreamEvent struct {
+	ID        int64       `json:"id"`
+	Type      string      `json:"type"`
+	Data      interface{} `json:"data"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+type bufferedEvent struct {
+	DomainEvent
+	id        int64
+	createdAt time.Time
+}
+
+// viewFor returns what the subscriber may see of the event, if anything.
+func (e bufferedEvent) viewFor(userID int64, isAdmin bool) (StreamEvent, bool) {
+	event := StreamEvent{ID: e.id, Type: e.Type, CreatedAt: e.createdAt}
+	if isAdmin {
+		event.Data = e.Data
+		return event, true
+	}
+	for _, id := range e.UserIDs {
+		if id == userID {
+			event.Data = e.Data
+			return event, true
+		}
+	}
+	if e.Public != nil {
+		event.Data = e.Public
+		return event, true
+	}
+	return StreamEvent{}, false
+}
+
+type eventSubscriber struct {
+	userID  int64
+	isAdmin bool
+	ch      chan StreamEvent
+}
+
+// EventBroker fans domain events out to connected stream clients. Recent events
+// are kept in memory so a client reconnecting with its last event ID receives
+// what it missed. IDs are seeded from the clock at startup, so an ID from before
+// a restart is older than anything buffered and the client gets a stream.reset.
+type EventBroker struct {
+	mu          sync.Mutex
+	nextID      int64
+	buffer      []bufferedEvent
+	bufferSize  int
+	subscribers map[*eventSubscriber]struct{}
+	logger      *slog.Logger
+}
+
+// NewEventBroker creates a new EventBroker keeping up to bufferSize recent events.
+func NewEventBroker(bufferSize int, logger *slog.Logger) *EventBroker {
+	if bufferSize <= 0 {
+		bufferSize = DefaultEventBufferSize
+	}
+	return &EventBroker{
+		nextID:      time.Now().UnixMicro(),
+		bufferSize:  bufferSize,
+		subscribers: map[*eventSubscriber]struct{}{},
+		logger:      logger.With("service", "EventBroker"),
+	}
+}
+
+// Publish assigns the event an ID and delivers it to every entitled subscriber.
+// Subscribers that are too far behind are disconnected rather than blocking the
+// publisher; they resume from their last event ID when they reconnect. Publish
+// on a nil broker is a no-op so services work without a stream configured.
+func (b *EventBroker) Publish(event DomainEvent) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	buffered := bufferedEvent{DomainEvent: event, id: b.nextID, createdAt: time.Now().UTC()}
+	b.nextID++
+	b.buffer = append(b.buffer, buffered)
+	if len(b.buffer) > b.bufferSize {
+		b.buffer = b.buffer[len(b.buffer)-b.bufferSize:]
+	}
+
+	for sub := range b.subscribers {
+		view, ok := buffered.viewFor(sub.userID, sub.isAdmin)
+		if !ok {
+			continue
+		}
+		select {
+		case sub.ch <- view:
+		default:
+			b.logger.Warn("Disconnecting slow event stream subscriber", "user_id", sub.userID)
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe registers a subscriber and returns the buffered events after
+// lastEventID that it is entitled to, plus a channel of new events. The channel
+// is closed when ctx is done or the subscriber falls too far behind. A
+// lastEventID of 0 means the client is connecting for the first time. If events
+// after lastEventID have already left the buffer, the replay starts with a
+// stream.reset event.
+func (b *EventBroker) Subscribe(ctx context.Context, userID int64, isAdmin bool, lastEventID int64) ([]StreamEvent, <-chan StreamEvent) {
+	sub := &eventSubscriber{
+		userID:  userID,
+		isAdmin: isAdmin,
+		ch:      make(chan StreamEvent, eventSubscriberBuffer),
+	}
+
+	b.mu.Lock()
+	var replay []StreamEvent
+	if lastEventID > 0 {
+		oldest := b.nextID
+		if len(b.buffer) > 0 {
+			oldest = b.buffer[0].id
+		}
+		if lastEventID < oldest-1 || lastEventID >= b.nextID {
+			replay = append(replay, StreamEvent{ID: b.nextID - 1, Type: EventStreamReset, CreatedAt: time.Now().UTC()})
+		}
+		for _, event := range b.buffer {
+			if event.id <= lastEventID {
+				continue
+			}
+			if view, ok := event.viewFor(userID, isAdmin); ok {
+				replay = append(replay, view)
+			}
+		}
+	}
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[sub]; ok {
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}()
+
+	return replay, sub.ch
+}
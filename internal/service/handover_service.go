@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"night-owls-go/internal/config"
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+var (
+	ErrHandoverNoteEmpty    = errors.New("handover note must include open issues, vehicles seen, gates open or notes")
+	ErrHandoverNoteNotFound = errors.New("handover note not found")
+)
+
+// MaxReceivedHandoverNotes caps how many earlier patrols' notes are shown for one shift.
+const MaxReceivedHandoverNotes = 10
+
+// HandoverNoteInput is the structured note the outgoing owl leaves.
+type HandoverNoteInput struct {
+	OpenIssues   string
+	VehiclesSeen string
+	GatesOpen    string
+	Notes        string
+}
+
+// HandoverService handles shift handover notes. The owl on a patrol leaves a
+// note on their booking; owls on the next slot of the same schedule, or on an
+// overlapping schedule, see it once they check in.
+type HandoverService struct {
+	querier db.Querier
+	cfg     *config.Config
+	logger  *slog.Logger
+}
+
+// NewHandoverService creates a new HandoverService.
+func NewHandoverService(querier db.Querier, cfg *config.Config, logger *slog.Logger) *HandoverService {
+	return &HandoverService{
+		querier: querier,
+		cfg:     cfg,
+		logger:  logger.With("service", "HandoverService"),
+	}
+}
+
+// getOwnBooking loads a booking and checks the user booked it.
+func (s *HandoverService) getOwnBooking(ctx context.Context, bookingID, userID int64) (db.Booking, error) {
+	booking, err := s.querier.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Booking{}, ErrBookingNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get booking for handover", "booking_id", bookingID, "error", err)
+		return db.Booking{}, ErrInternalServer
+	}
+	if booking.UserID != userID {
+		s.logger.WarnContext(ctx, "User forbidden to access handover for booking", "booking_id", bookingID, "booking_owner_id", booking.UserID, "auth_user_id", userID)
+		return db.Booking{}, ErrForbiddenUpdate
+	}
+	return booking, nil
+}
+
+// SaveHandoverNote creates or replaces the handover note for a checked-in booking.
+func (s *HandoverService) SaveHandoverNote(ctx context.Context, bookingID, userID int64, input HandoverNoteInput) (db.HandoverNote, error) {
+	booking, err := s.getOwnBooking(ctx, bookingID, userID)
+	if err != nil {
+		return db.HandoverNote{}, err
+	}
+	if !booking.CheckedInAt.Valid {
+		return db.HandoverNote{}, ErrNotCheckedIn
+	}
+
+	params := db.UpsertHandoverNoteParams{
+		BookingID:    bookingID,
+		UserID:       userID,
+		OpenIssues:   strings.TrimSpace(input.OpenIssues),
+		VehiclesSeen: strings.TrimSpace(input.VehiclesSeen),
+		GatesOpen:    strings.TrimSpace(input.GatesOpen),
+		Notes:        strings.TrimSpace(input.Notes),
+	}
+	if params.OpenIssues == "" && params.VehiclesSeen == "" && params.GatesOpen == "" && params.Notes == "" {
+		return db.HandoverNote{}, ErrHandoverNoteEmpty
+	}
+
+	note, err := s.querier.UpsertHandoverNote(ctx, params)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save handover note", "booking_id", bookingID, "error", err)
+		return db.HandoverNote{}, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Handover note saved", "booking_id", bookingID, "user_id", userID, "handover_id", note.HandoverID)
+	return note, nil
+}
+
+// GetHandoverNote returns the note left on a booking. Owls may only read their own.
+func (s *HandoverService) GetHandoverNote(ctx context.Context, bookingID, userID int64, isAdmin bool) (db.HandoverNote, error) {
+	if !isAdmin {
+		if _, err := s.getOwnBooking(ctx, bookingID, userID); err != nil {
+			return db.HandoverNote{}, err
+		}
+	}
+	return s.getNote(ctx, bookingID)
+}
+
+func (s *HandoverService) getNote(ctx context.Context, bookingID int64) (db.HandoverNote, error) {
+	note, err := s.querier.GetHandoverNoteByBookingID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.HandoverNote{}, ErrHandoverNoteNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get handover note", "booking_id", bookingID, "error", err)
+		return db.HandoverNote{}, ErrInternalServer
+	}
+	return note, nil
+}
+
+// ListReceivedNotes returns the notes handed over to a shift: those left on
+// earlier patrols that overlap it or ended within the configured lookback
+// before it starts, most recent first.
+func (s *HandoverService) ListReceivedNotes(ctx context.Context, bookingID int64, shiftStart time.Time) ([]db.ListIncomingHandoverNotesRow, error) {
+	var lookback time.Duration
+	if s.cfg != nil && s.cfg.HandoverLookback > 0 {
+		lookback = s.cfg.HandoverLookback
+	}
+	notes, err := s.querier.ListIncomingHandoverNotes(ctx, db.ListIncomingHandoverNotesParams{
+		BookingID:  bookingID,
+		ShiftStart: shiftStart,
+		Since:      shiftStart.Add(-lookback),
+		Limit:      MaxReceivedHandoverNotes,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list received handover notes", "booking_id", bookingID, "error", err)
+		return nil, ErrInternalServer
+	}
+	return notes, nil
+}
+
+// ListReceivedNotesForOwl returns the notes handed over to the owl's booking.
+// They become available once the owl has checked in.
+func (s *HandoverService) ListReceivedNotesForOwl(ctx context.Context, bookingID, userID int64) ([]db.ListIncomingHandoverNotesRow, error) {
+	booking, err := s.getOwnBooking(ctx, bookingID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !booking.CheckedInAt.Valid {
+		return nil, ErrNotCheckedIn
+	}
+	return s.ListReceivedNotes(ctx, booking.BookingID, booking.ShiftStart)
+}
+
+// GetBookingHandovers returns the note left on a booking, if any, and the notes
+// handed over to it, for showing alongside reports filed on the shift.
+func (s *HandoverService) GetBookingHandovers(ctx context.Context, bookingID int64) (*db.HandoverNote, []db.ListIncomingHandoverNotesRow, error) {
+	booking, err := s.querier.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrBookingNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get booking for handover context", "booking_id", bookingID, "error", err)
+		return nil, nil, ErrInternalServer
+	}
+
+	var left *db.HandoverNote
+	note, err := s.getNote(ctx, bookingID)
+	switch {
+	case err == nil:
+		left = &note
+	case !errors.Is(err, ErrHandoverNoteNotFound):
+		return nil, nil, err
+	}
+
+	received, err := s.ListReceivedNotes(ctx, booking.BookingID, booking.ShiftStart)
+	if err != nil {
+		return nil, nil, err
+	}
+	return left, received, nil
+}
@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"strings"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+var (
+	ErrIncidentCategoryNotFound = errors.New("incident category not found")
+	ErrInvalidCategoryData      = errors.New("invalid incident category data")
+	ErrInvalidCategoryFields    = errors.New("invalid incident category field values")
+)
+
+// Supported structured field types
+const (
+	CategoryFieldText    = "text"
+	CategoryFieldInteger = "integer"
+	CategoryFieldNumber  = "number"
+	CategoryFieldSelect  = "select"
+	CategoryFieldBoolean = "boolean"
+)
+
+const defaultCategoryTextMaxLength = 500
+
+var categoryFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// CategoryField defines one structured field on an incident category.
+type CategoryField struct {
+	Key       string   `json:"key"`
+	Label     string   `json:"label"`
+	Type      string   `json:"type"`
+	Required  bool     `json:"required,omitempty"`
+	Options   []string `json:"options,omitempty"`
+	Min       *float64 `json:"min,omitempty"`
+	Max       *float64 `json:"max,omitempty"`
+	MaxLength int      `json:"max_length,omitempty"`
+}
+
+// ReportCategoryInput carries the category and structured field values submitted with a report.
+type ReportCategoryInput struct {
+	CategoryID int64
+	Fields     map[string]interface{}
+}
+
+// IncidentCategoryService handles admin management of incident categories.
+type IncidentCategoryService struct {
+	querier db.Querier
+	logger  *slog.Logger
+}
+
+// NewIncidentCategoryService creates a new IncidentCategoryService.
+func NewIncidentCategoryService(querier db.Querier, logger *slog.Logger) *IncidentCategoryService {
+	return &IncidentCategoryService{
+		querier: querier,
+		logger:  logger.With("service", "IncidentCategoryService"),
+	}
+}
+
+// ParseCategoryFields decodes the stored field definitions of a category.
+func ParseCategoryFields(category db.IncidentCategory) ([]CategoryField, error) {
+	fields := []CategoryField{}
+	if strings.TrimSpace(category.Fields) == "" {
+		return fields, nil
+	}
+	if err := json.Unmarshal([]byte(category.Fields), &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse fields for category %d: %w", category.CategoryID, err)
+	}
+	return fields, nil
+}
+
+// validateFieldDefinitions checks that admin-supplied field definitions are well formed.
+func validateFieldDefinitions(fields []CategoryField) error {
+	seen := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		if !categoryFieldKeyPattern.MatchString(field.Key) {
+			return fmt.Errorf("%w: field key %q must be lower_snake_case", ErrInvalidCategoryData, field.Key)
+		}
+		if seen[field.Key] {
+			return fmt.Errorf("%w: duplicate field key %q", ErrInvalidCategoryData, field.Key)
+		}
+		seen[field.Key] = true
+
+		if strings.TrimSpace(field.Label) == "" {
+			return fmt.Errorf("%w: field %q needs a label", ErrInvalidCategoryData, field.Key)
+		}
+
+		switch field.Type {
+		case CategoryFieldText, CategoryFieldInteger, CategoryFieldNumber, CategoryFieldBoolean:
+		case CategoryFieldSelect:
+			if len(field.Options) == 0 {
+				return fmt.Errorf("%w: select field %q needs options", ErrInvalidCategoryData, field.Key)
+			}
+		default:
+			return fmt.Errorf("%w: field %q has unsupported type %q", ErrInvalidCategoryData, field.Key, field.Type)
+		}
+
+		if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+			return fmt.Errorf("%w: field %q min is greater than max", ErrInvalidCategoryData, field.Key)
+		}
+		if field.MaxLength < 0 {
+			return fmt.Errorf("%w: field %q max_length must be positive", ErrInvalidCategoryData, field.Key)
+		}
+	}
+	return nil
+}
+
+// ValidateCategoryFieldValues checks submitted values against a category's field
+// definitions and returns the normalised values. Unknown keys are rejected.
+func ValidateCategoryFieldValues(fields []CategoryField, values map[string]interface{}) (map[string]interface{}, error) {
+	definitions := make(map[string]CategoryField, len(fields))
+	for _, field := range fields {
+		definitions[field.Key] = field
+	}
+	for key := range values {
+		if _, ok := definitions[key]; !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidCategoryFields, key)
+		}
+	}
+
+	normalised := make(map[string]interface{}, len(values))
+	for _, field := range fields {
+		raw, present := values[field.Key]
+		if s, ok := raw.(string); ok && strings.TrimSpace(s) == "" {
+			present = false
+		}
+		if !present || raw == nil {
+			if field.Required {
+				return nil, fmt.Errorf("%w: %s is required", ErrInvalidCategoryFields, field.Label)
+			}
+			continue
+		}
+
+		value, err := normaliseCategoryFieldValue(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		normalised[field.Key] = value
+	}
+	return normalised, nil
+}
+
+// normaliseCategoryFieldValue validates a single value against its field definition.
+func normaliseCategoryFieldValue(field CategoryField, raw interface{}) (interface{}, error) {
+	switch field.Type {
+	case CategoryFieldText:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be text", ErrInvalidCategoryFields, field.Label)
+		}
+		s = strings.TrimSpace(s)
+		maxLength := field.MaxLength
+		if maxLength == 0 {
+			maxLength = defaultCategoryTextMaxLength
+		}
+		if len([]rune(s)) > maxLength {
+			return nil, fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidCategoryFields, field.Label, maxLength)
+		}
+		return s, nil
+
+	case CategoryFieldInteger, CategoryFieldNumber:
+		n, ok := raw.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be a number", ErrInvalidCategoryFields, field.Label)
+		}
+		if field.Type == CategoryFieldInteger && n != math.Trunc(n) {
+			return nil, fmt.Errorf("%w: %s must be a whole number", ErrInvalidCategoryFields, field.Label)
+		}
+		if field.Min != nil && n < *field.Min {
+			return nil, fmt.Errorf("%w: %s must be at least %v", ErrInvalidCategoryFields, field.Label, *field.Min)
+		}
+		if field.Max != nil && n > *field.Max {
+			return nil, fmt.Errorf("%w: %s must be at most %v", ErrInvalidCategoryFields, field.Label, *field.Max)
+		}
+		if field.Type == CategoryFieldInteger {
+			return int64(n), nil
+		}
+		return n, nil
+
+	case CategoryFieldSelect:
+		s, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be one of %s", ErrInvalidCategoryFields, field.Label, strings.Join(field.Options, ", "))
+		}
+		for _, option := range field.Options {
+			if strings.EqualFold(strings.TrimSpace(s), option) {
+				return option, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s must be one of %s", ErrInvalidCategoryFields, field.Label, strings.Join(field.Options, ", "))
+
+	case CategoryFieldBoolean:
+		b, ok := raw.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s must be true or false", ErrInvalidCategoryFields, field.Label)
+		}
+		return b, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s has unsupported type %q", ErrInvalidCategoryFields, field.Label, field.Type)
+}
+
+// ListCategories returns all active incident categories ordered by display order
+func (s *IncidentCategoryService) ListCategories(ctx context.Context) ([]db.IncidentCategory, error) {
+	categories, err := s.querier.ListIncidentCategories(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list incident categories", "error", err)
+		return nil, err
+	}
+	return categories, nil
+}
+
+// GetCategory returns a specific active incident category
+func (s *IncidentCategoryService) GetCategory(ctx context.Context, categoryID int64) (db.IncidentCategory, error) {
+	category, err := s.querier.GetIncidentCategoryByID(ctx, categoryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.IncidentCategory{}, ErrIncidentCategoryNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get incident category", "category_id", categoryID, "error", err)
+		return db.IncidentCategory{}, err
+	}
+	return category, nil
+}
+
+// CreateCategory creates a new incident category
+func (s *IncidentCategoryService) CreateCategory(ctx context.Context, name, description string, fields []CategoryField, displayOrder int64) (db.IncidentCategory, error) {
+	fieldsJSON, err := s.prepareCategory(name, fields)
+	if err != nil {
+		return db.IncidentCategory{}, err
+	}
+
+	category, err := s.querier.CreateIncidentCategory(ctx, db.CreateIncidentCategoryParams{
+		Name:         strings.TrimSpace(name),
+		Description:  sql.NullString{String: description, Valid: description != ""},
+		Fields:       fieldsJSON,
+		DisplayOrder: displayOrder,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create incident category", "name", name, "error", err)
+		return db.IncidentCategory{}, err
+	}
+
+	s.logger.InfoContext(ctx, "Incident category created", "category_id", category.CategoryID, "name", category.Name)
+	return category, nil
+}
+
+// UpdateCategory updates an existing incident category
+func (s *IncidentCategoryService) UpdateCategory(ctx context.Context, categoryID int64, name, description string, fields []CategoryField, displayOrder int64) (db.IncidentCategory, error) {
+	fieldsJSON, err := s.prepareCategory(name, fields)
+	if err != nil {
+		return db.IncidentCategory{}, err
+	}
+
+	category, err := s.querier.UpdateIncidentCategory(ctx, db.UpdateIncidentCategoryParams{
+		Name:         strings.TrimSpace(name),
+		Description:  sql.NullString{String: description, Valid: description != ""},
+		Fields:       fieldsJSON,
+		DisplayOrder: displayOrder,
+		CategoryID:   categoryID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.IncidentCategory{}, ErrIncidentCategoryNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to update incident category", "category_id", categoryID, "error", err)
+		return db.IncidentCategory{}, err
+	}
+
+	s.logger.InfoContext(ctx, "Incident category updated", "category_id", categoryID)
+	return category, nil
+}
+
+// DeleteCategory deactivates an incident category. Existing reports keep their category.
+func (s *IncidentCategoryService) DeleteCategory(ctx context.Context, categoryID int64) error {
+	if _, err := s.GetCategory(ctx, categoryID); err != nil {
+		return err
+	}
+
+	if err := s.querier.DeleteIncidentCategory(ctx, categoryID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete incident category", "category_id", categoryID, "error", err)
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "Incident category deleted", "category_id", categoryID)
+	return nil
+}
+
+// prepareCategory validates the category name and field definitions and encodes the fields for storage.
+func (s *IncidentCategoryService) prepareCategory(name string, fields []CategoryField) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidCategoryData)
+	}
+	if fields == nil {
+		fields = []CategoryField{}
+	}
+	if err := validateFieldDefinitions(fields); err != nil {
+		return "", err
+	}
+
+	fieldsJSON, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode category fields: %w", err)
+	}
+	return string(fieldsJSON), nil
+}
@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"night-owls-go/internal/config"
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+var (
+	ErrEscalationNotFound            = errors.New("no escalation exists for this report")
+	ErrEscalationAlreadyAcknowledged = errors.New("escalation has already been acknowledged")
+	ErrEscalationAckForbidden        = errors.New("user was not alerted for this escalation")
+)
+
+// Escalation states reported to clients. "escalated" is derived: the escalation
+// is still pending but its SMS stage has been released to the outbox.
+const (
+	EscalationStatePending      = "pending"
+	EscalationStateEscalated    = "escalated"
+	EscalationStateAcknowledged = "acknowledged"
+)
+
+// Escalation message stages
+const (
+	EscalationStagePush = "push"
+	EscalationStageSMS  = "sms"
+)
+
+const escalationSMSMessageLimit = 100
+
+// IncidentEscalationService drives the alert chain for severity-2 reports:
+// an immediate push to on-duty owls and admins, followed by SMS to the
+// designated responders if nobody acknowledges within the configured delay.
+// Every message goes through the outbox so delivery is retried and tracked.
+type IncidentEscalationService struct {
+	querier                 db.Querier
+	emergencyContactService *EmergencyContactService
+	cfg                     *config.Config
+	logger                  *slog.Logger
+}
+
+// NewIncidentEscalationService creates a new IncidentEscalationService.
+func NewIncidentEscalationService(querier db.Querier, emergencyContactService *EmergencyContactService, cfg *config.Config, logger *slog.Logger) *IncidentEscalationService {
+	return &IncidentEscalationService{
+		querier:                 querier,
+		emergencyContactService: emergencyContactService,
+		cfg:                     cfg,
+		logger:                  logger.With("service", "IncidentEscalationService"),
+	}
+}
+
+// EscalationState returns the client-facing state of an escalation at the given time.
+func EscalationState(escalation db.IncidentEscalation, now time.Time) string {
+	if escalation.Status == EscalationStateAcknowledged {
+		return EscalationStateAcknowledged
+	}
+	if !now.Before(escalation.EscalateAt) {
+		return EscalationStateEscalated
+	}
+	return EscalationStatePending
+}
+
+// escalationOptions distinguishes an SOS from an ordinary serious report.
+type escalationOptions struct {
+	smsDelay time.Duration
+	sos      bool
+	raisedBy string // Name of the owl who raised the SOS
+}
+
+// EscalateReport starts the escalation chain for a severity-2 report. Reports
+// of lower severity are ignored and a nil escalation is returned.
+func (s *IncidentEscalationService) EscalateReport(ctx context.Context, report db.Report) (*db.IncidentEscalation, error) {
+	if report.Severity < 2 {
+		return nil, nil
+	}
+	escalation, _, err := s.escalate(ctx, report, escalationOptions{smsDelay: s.cfg.EscalationSMSDelay})
+	return escalation, err
+}
+
+// EscalateSOS starts the escalation chain for the report behind an SOS alert.
+// Push and SMS messages are all due immediately. The IDs of the queued outbox
+// items are returned so the caller can dispatch them without waiting for the
+// outbox poll.
+func (s *IncidentEscalationService) EscalateSOS(ctx context.Context, report db.Report, raisedBy string) (*db.IncidentEscalation, []int64, error) {
+	return s.escalate(ctx, report, escalationOptions{sos: true, raisedBy: raisedBy})
+}
+
+func (s *IncidentEscalationService) escalate(ctx context.Context, report db.Report, opts escalationOptions) (*db.IncidentEscalation, []int64, error) {
+	now := time.Now().UTC()
+	escalation, err := s.querier.CreateIncidentEscalation(ctx, db.CreateIncidentEscalationParams{
+		ReportID:   report.ReportID,
+		EscalateAt: now.Add(opts.smsDelay),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create incident escalation", "report_id", report.ReportID, "error", err)
+		return nil, nil, ErrInternalServer
+	}
+
+	var emergencyContact *db.EmergencyContact
+	if s.emergencyContactService != nil {
+		if contact, err := s.emergencyContactService.GetDefaultEmergencyContact(ctx); err == nil {
+			emergencyContact = &contact
+		} else {
+			s.logger.WarnContext(ctx, "No default emergency contact for escalation payload", "report_id", report.ReportID)
+		}
+	}
+
+	pushRecipients, allUsers, err := s.pushRecipients(ctx, now)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to resolve escalation push recipients", "report_id", report.ReportID, "error", err)
+		return nil, nil, ErrInternalServer
+	}
+
+	pushPayload, err := json.Marshal(s.buildPushPayload(report, escalation, emergencyContact, opts))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to marshal escalation push payload", "report_id", report.ReportID, "error", err)
+		return nil, nil, ErrInternalServer
+	}
+
+	var outboxIDs []int64
+	pushCount := 0
+	for _, userID := range pushRecipients {
+		if userID == report.UserID.Int64 {
+			continue // The reporter already knows
+		}
+		if outboxID, ok := s.enqueue(ctx, escalation, EscalationStagePush, db.CreateOutboxItemParams{
+			MessageType: "push",
+			Recipient:   "",
+			Payload:     sql.NullString{String: string(pushPayload), Valid: true},
+			UserID:      sql.NullInt64{Int64: userID, Valid: true},
+			SendAt:      now.Add(-1 * time.Second),
+			Category:    sql.NullString{String: NotificationCategoryUrgent, Valid: true},
+		}); ok {
+			outboxIDs = append(outboxIDs, outboxID)
+			pushCount++
+		}
+	}
+
+	smsBody := s.buildSMSBody(report, emergencyContact, opts)
+	smsCount := 0
+	for _, phone := range s.responderPhones(allUsers) {
+		if outboxID, ok := s.enqueue(ctx, escalation, EscalationStageSMS, db.CreateOutboxItemParams{
+			MessageType: "sms",
+			Recipient:   phone,
+			Payload:     sql.NullString{String: smsBody, Valid: true},
+			SendAt:      escalation.EscalateAt,
+			Category:    sql.NullString{String: NotificationCategoryUrgent, Valid: true},
+		}); ok {
+			outboxIDs = append(outboxIDs, outboxID)
+			smsCount++
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Incident escalation started",
+		"report_id", report.ReportID,
+		"escalation_id", escalation.EscalationID,
+		"push_count", pushCount,
+		"sms_count", smsCount,
+		"escalate_at", escalation.EscalateAt,
+		"sos", opts.sos)
+	return &escalation, outboxIDs, nil
+}
+
+// pushRecipients returns the users on duty right now plus all admins, without
+// duplicates. The full user list is returned for responder fallback.
+func (s *IncidentEscalationService) pushRecipients(ctx context.Context, now time.Time) ([]int64, []db.ListUsersRow, error) {
+	return onDutyAndAdminUserIDs(ctx, s.querier, now)
+}
+
+// onDutyAndAdminUserIDs returns the owls on duty at now, their buddies and all
+// admins, without duplicates, along with the full user list.
+func onDutyAndAdminUserIDs(ctx context.Context, querier db.Querier, now time.Time) ([]int64, []db.ListUsersRow, error) {
+	onDuty, err := querier.ListOnDutyBookings(ctx, db.ListOnDutyBookingsParams{
+		ShiftStart: now,
+		ShiftEnd:   now,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users, err := querier.ListUsers(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seen := map[int64]bool{}
+	var recipients []int64
+	add := func(userID int64) {
+		if !seen[userID] {
+			seen[userID] = true
+			recipients = append(recipients, userID)
+		}
+	}
+	for _, booking := range onDuty {
+		add(booking.UserID)
+		if booking.BuddyUserID.Valid {
+			add(booking.BuddyUserID.Int64)
+		}
+	}
+	for _, user := range users {
+		if user.Role == "admin" {
+			add(user.UserID)
+		}
+	}
+	return recipients, users, nil
+}
+
+// responderPhones returns the configured responders, falling back to admin phones.
+func (s *IncidentEscalationService) responderPhones(users []db.ListUsersRow) []string {
+	if len(s.cfg.EscalationResponderPhones) > 0 {
+		return s.cfg.EscalationResponderPhones
+	}
+	var phones []string
+	for _, user := range users {
+		if user.Role == "admin" && user.Phone != "" {
+			phones = append(phones, user.Phone)
+		}
+	}
+	return phones
+}
+
+// enqueue creates an outbox item and links it to the escalation.
+func (s *IncidentEscalationService) enqueue(ctx context.Context, escalation db.IncidentEscalation, stage string, params db.CreateOutboxItemParams) (int64, bool) {
+	item, err := enqueueOutboxItem(ctx, s.querier, s.logger, params)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to enqueue escalation message", "escalation_id", escalation.EscalationID, "stage", stage, "error", err)
+		return 0, false
+	}
+	if err := s.querier.LinkEscalationOutboxItem(ctx, db.LinkEscalationOutboxItemParams{
+		EscalationID: escalation.EscalationID,
+		OutboxID:     item.OutboxID,
+		Stage:        stage,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to link escalation message", "escalation_id", escalation.EscalationID, "outbox_id", item.OutboxID, "error", err)
+		return 0, false
+	}
+	return item.OutboxID, true
+}
+
+func (s *IncidentEscalationService) buildPushPayload(report db.Report, escalation db.IncidentEscalation, contact *db.EmergencyContact, opts escalationOptions) map[string]interface{} {
+	payloadType := "incident_escalation"
+	if opts.sos {
+		payloadType = "sos_alert"
+	}
+	data := map[string]interface{}{
+		"type":          payloadType,
+		"report_id":     report.ReportID,
+		"escalation_id": escalation.EscalationID,
+		"severity":      report.Severity,
+	}
+	if report.Latitude.Valid && report.Longitude.Valid {
+		data["latitude"] = report.Latitude.Float64
+		data["longitude"] = report.Longitude.Float64
+	}
+	if contact != nil {
+		data["emergency_contact"] = map[string]interface{}{
+			"name":   contact.Name,
+			"number": contact.Number,
+		}
+	}
+
+	title := "Serious incident reported"
+	body := "A serious incident has been reported. Tap to acknowledge."
+	if opts.sos {
+		title = "SOS - owl needs help"
+		body = fmt.Sprintf("%s has raised an SOS. Tap to respond.", opts.raisedBy)
+	} else if report.Message.Valid && strings.TrimSpace(report.Message.String) != "" {
+		body = truncateRunes(strings.TrimSpace(report.Message.String), escalationSMSMessageLimit)
+	}
+
+	return map[string]interface{}{
+		"type":  payloadType,
+		"title": title,
+		"body":  body,
+		"data":  data,
+	}
+}
+
+func (s *IncidentEscalationService) buildSMSBody(report db.Report, contact *db.EmergencyContact, opts escalationOptions) string {
+	var sb strings.Builder
+	if opts.sos {
+		fmt.Fprintf(&sb, "NIGHT OWLS SOS: %s needs urgent help (incident #%d).", opts.raisedBy, report.ReportID)
+	} else {
+		fmt.Fprintf(&sb, "NIGHT OWLS: serious incident #%d not acknowledged after %d min.", report.ReportID, int(s.cfg.EscalationSMSDelay.Minutes()))
+	}
+	if report.Message.Valid && strings.TrimSpace(report.Message.String) != "" {
+		fmt.Fprintf(&sb, " %s", truncateRunes(strings.TrimSpace(report.Message.String), escalationSMSMessageLimit))
+	}
+	if report.Latitude.Valid && report.Longitude.Valid {
+		fmt.Fprintf(&sb, " Location: %.5f,%.5f.", report.Latitude.Float64, report.Longitude.Float64)
+	}
+	if contact != nil {
+		fmt.Fprintf(&sb, " Emergency: %s %s", contact.Name, contact.Number)
+	}
+	return sb.String()
+}
+
+// truncateRunes shortens s to at most limit runes, adding an ellipsis when cut.
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// GetEscalation returns the escalation for a report along with its outbox messages.
+func (s *IncidentEscalationService) GetEscalation(ctx context.Context, reportID int64) (db.IncidentEscalation, []db.ListEscalationMessagesRow, error) {
+	escalation, err := s.querier.GetIncidentEscalationByReportID(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.IncidentEscalation{}, nil, ErrEscalationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get incident escalation", "report_id", reportID, "error", err)
+		return db.IncidentEscalation{}, nil, ErrInternalServer
+	}
+
+	messages, err := s.querier.ListEscalationMessages(ctx, escalation.EscalationID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list escalation messages", "escalation_id", escalation.EscalationID, "error", err)
+		return db.IncidentEscalation{}, nil, ErrInternalServer
+	}
+	return escalation, messages, nil
+}
+
+// AcknowledgeEscalation records that a user has picked up a severity-2 alert
+// and cancels any SMS that has not been sent yet. Admins may always
+// acknowledge; owls only if they received the push alert. It returns the
+// updated escalation and the number of SMS messages cancelled.
+func (s *IncidentEscalationService) AcknowledgeEscalation(ctx context.Context, reportID, userID int64, isAdmin bool) (db.IncidentEscalation, int64, error) {
+	escalation, err := s.querier.GetIncidentEscalationByReportID(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.IncidentEscalation{}, 0, ErrEscalationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get incident escalation", "report_id", reportID, "error", err)
+		return db.IncidentEscalation{}, 0, ErrInternalServer
+	}
+
+	if escalation.Status == EscalationStateAcknowledged {
+		return escalation, 0, ErrEscalationAlreadyAcknowledged
+	}
+
+	if !isAdmin {
+		count, err := s.querier.CountEscalationPushRecipient(ctx, db.CountEscalationPushRecipientParams{
+			EscalationID: escalation.EscalationID,
+			UserID:       sql.NullInt64{Int64: userID, Valid: true},
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to check escalation recipient", "escalation_id", escalation.EscalationID, "user_id", userID, "error", err)
+			return db.IncidentEscalation{}, 0, ErrInternalServer
+		}
+		if count == 0 {
+			return db.IncidentEscalation{}, 0, ErrEscalationAckForbidden
+		}
+	}
+
+	acknowledged, err := s.querier.AcknowledgeIncidentEscalation(ctx, db.AcknowledgeIncidentEscalationParams{
+		AcknowledgedByUserID: sql.NullInt64{Int64: userID, Valid: true},
+		EscalationID:         escalation.EscalationID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Someone else acknowledged between our read and update
+			return escalation, 0, ErrEscalationAlreadyAcknowledged
+		}
+		s.logger.ErrorContext(ctx, "Failed to acknowledge incident escalation", "escalation_id", escalation.EscalationID, "error", err)
+		return db.IncidentEscalation{}, 0, ErrInternalServer
+	}
+
+	cancelled, err := s.querier.CancelPendingEscalationMessages(ctx, escalation.EscalationID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to cancel pending escalation SMS", "escalation_id", escalation.EscalationID, "error", err)
+		return acknowledged, 0, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Incident escalation acknowledged",
+		"report_id", reportID,
+		"escalation_id", escalation.EscalationID,
+		"user_id", userID,
+		"cancelled_sms", cancelled)
+	return acknowledged, cancelled, nil
+}
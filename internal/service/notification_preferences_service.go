@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"night-owls-go/internal/config"
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+// Notification categories users can opt out of. Outbox items record the
+// category they fall under; items without one, such as OTPs and booking
+// confirmations, are transactional and always sent. Urgent items, such as
+// SOS alerts and urgent broadcasts, cannot be opted out of and are sent
+// during quiet hours.
+const (
+	NotificationCategoryReminders      = "reminders"
+	NotificationCategoryBroadcasts     = "broadcasts"
+	NotificationCategoryCoverageAlerts = "coverage_alerts"
+	NotificationCategoryReportUpdates  = "report_updates"
+	NotificationCategoryUrgent         = "urgent"
+)
+
+// Notification channels a user can prefer
+const (
+	NotificationChannelPush  = "push"
+	NotificationChannelSMS   = "sms"
+	NotificationChannelEmail = "email"
+)
+
+var (
+	ErrInvalidQuietHours          = errors.New("quiet hours must be HH:MM with both a start and an end that differ")
+	ErrInvalidTimezone            = errors.New("unknown time zone")
+	ErrInvalidNotificationChannel = errors.New("notification channel must be push, sms or email")
+)
+
+// NotificationPreferences are a user's notification settings. A user who has
+// never saved any gets every category and no quiet hours.
+type NotificationPreferences struct {
+	UserID          int64  `json:"user_id"`
+	Channel         string `json:"channel"`
+	Reminders       bool   `json:"reminders"`
+	Broadcasts      bool   `json:"broadcasts"`
+	CoverageAlerts  bool   `json:"coverage_alerts"`
+	ReportUpdates   bool   `json:"report_updates"`
+	QuietHoursStart string `json:"quiet_hours_start,omitempty"` // HH:MM in Timezone
+	QuietHoursEnd   string `json:"quiet_hours_end,omitempty"`
+	Timezone        string `json:"timezone"`
+
+	location *time.Location
+}
+
+// Allows reports whether the user wants notifications in category.
+// Transactional and urgent notifications are always allowed.
+func (p NotificationPreferences) Allows(category string) bool {
+	switch category {
+	case NotificationCategoryReminders:
+		return p.Reminders
+	case NotificationCategoryBroadcasts:
+		return p.Broadcasts
+	case NotificationCategoryCoverageAlerts:
+		return p.CoverageAlerts
+	case NotificationCategoryReportUpdates:
+		return p.ReportUpdates
+	default:
+		return true
+	}
+}
+
+// QuietPeriod returns the quiet hours that t falls in, if any. Quiet hours
+// that end earlier in the day than they start run over midnight.
+func (p NotificationPreferences) QuietPeriod(t time.Time) (time.Time, time.Time, bool) {
+	startMinutes, err := parseClockTime(p.QuietHoursStart)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	endMinutes, err := parseClockTime(p.QuietHoursEnd)
+	if err != nil || startMinutes == endMinutes {
+		return time.Time{}, time.Time{}, false
+	}
+
+	loc := p.location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	// A period that crosses midnight may have started the day before
+	for _, dayOffset := range []int{-1, 0} {
+		day := local.AddDate(0, 0, dayOffset)
+		start := time.Date(day.Year(), day.Month(), day.Day(), startMinutes/60, startMinutes%60, 0, 0, loc)
+		end := time.Date(day.Year(), day.Month(), day.Day(), endMinutes/60, endMinutes%60, 0, 0, loc)
+		if endMinutes < startMinutes {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !t.Before(start) && t.Before(end) {
+			return start, end, true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// UpdateNotificationPreferencesParams are the settings a user can change.
+// Clear quiet hours by leaving both ends empty; an empty Timezone uses the
+// server's notification time zone.
+type UpdateNotificationPreferencesParams struct {
+	Channel         string `json:"channel"`
+	Reminders       bool   `json:"reminders"`
+	Broadcasts      bool   `json:"broadcasts"`
+	CoverageAlerts  bool   `json:"coverage_alerts"`
+	ReportUpdates   bool   `json:"report_updates"`
+	QuietHoursStart string `json:"quiet_hours_start"`
+	QuietHoursEnd   string `json:"quiet_hours_end"`
+	Timezone        string `json:"timezone"`
+}
+
+// NotificationPreferencesService manages which notifications users receive,
+// on which channel and when.
+type NotificationPreferencesService struct {
+	querier         db.Querier
+	defaultLocation *time.Location
+	defaultTimezone string
+	logger          *slog.Logger
+}
+
+// NewNotificationPreferencesService creates a new NotificationPreferencesService.
+func NewNotificationPreferencesService(querier db.Querier, cfg *config.Config, logger *slog.Logger) *NotificationPreferencesService {
+	logger = logger.With("service", "NotificationPreferencesService")
+
+	defaultTimezone := cfg.NotificationTimezone
+	loc, err := time.LoadLocation(defaultTimezone)
+	if err != nil {
+		logger.Warn("Invalid notification timezone, using UTC", "timezone", defaultTimezone, "error", err)
+		loc = time.UTC
+		defaultTimezone = "UTC"
+	}
+
+	return &NotificationPreferencesService{
+		querier:         querier,
+		defaultLocation: loc,
+		defaultTimezone: defaultTimezone,
+		logger:          logger,
+	}
+}
+
+// Get returns the user's notification preferences.
+func (s *NotificationPreferencesService) Get(ctx context.Context, userID int64) (NotificationPreferences, error) {
+	row, err := s.querier.GetNotificationPreferences(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return NotificationPreferences{}, ErrUserNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get notification preferences", "user_id", userID, "error", err)
+		return NotificationPreferences{}, ErrInternalServer
+	}
+
+	prefs := NotificationPreferences{
+		UserID:          row.UserID,
+		Channel:         row.PreferredChannel,
+		Reminders:       !row.Reminders.Valid || row.Reminders.Bool,
+		Broadcasts:      !row.Broadcasts.Valid || row.Broadcasts.Bool,
+		CoverageAlerts:  !row.CoverageAlerts.Valid || row.CoverageAlerts.Bool,
+		ReportUpdates:   !row.ReportUpdates.Valid || row.ReportUpdates.Bool,
+		QuietHoursStart: row.QuietHoursStart.String,
+		QuietHoursEnd:   row.QuietHoursEnd.String,
+		Timezone:        s.defaultTimezone,
+		location:        s.defaultLocation,
+	}
+	if row.Timezone.Valid {
+		if loc, err := time.LoadLocation(row.Timezone.String); err == nil {
+			prefs.Timezone = row.Timezone.String
+			prefs.location = loc
+		} else {
+			s.logger.WarnContext(ctx, "Stored time zone is unknown, using the default", "user_id", userID, "timezone", row.Timezone.String)
+		}
+	}
+	return prefs, nil
+}
+
+// Update validates and saves the user's notification preferences.
+func (s *NotificationPreferencesService) Update(ctx context.Context, userID int64, params UpdateNotificationPreferencesParams) (NotificationPreferences, error) {
+	channel := strings.ToLower(strings.TrimSpace(params.Channel))
+	switch channel {
+	case NotificationChannelPush, NotificationChannelSMS, NotificationChannelEmail:
+	default:
+		return NotificationPreferences{}, ErrInvalidNotificationChannel
+	}
+
+	start := strings.TrimSpace(params.QuietHoursStart)
+	end := strings.TrimSpace(params.QuietHoursEnd)
+	if start != "" || end != "" {
+		startMinutes, startErr := parseClockTime(start)
+		endMinutes, endErr := parseClockTime(end)
+		if startErr != nil || endErr != nil || startMinutes == endMinutes {
+			return NotificationPreferences{}, ErrInvalidQuietHours
+		}
+		start = fmt.Sprintf("%02d:%02d", startMinutes/60, startMinutes%60)
+		end = fmt.Sprintf("%02d:%02d", endMinutes/60, endMinutes%60)
+	}
+
+	timezone := strings.TrimSpace(params.Timezone)
+	if timezone != "" {
+		// LoadLocation treats "" and "Local" as the server's zone, which users cannot pick
+		if _, err := time.LoadLocation(timezone); err != nil || timezone == "Local" {
+			return NotificationPreferences{}, ErrInvalidTimezone
+		}
+	}
+
+	if _, err := s.Get(ctx, userID); err != nil {
+		return NotificationPreferences{}, err
+	}
+
+	if err := s.querier.SetUserPreferredChannel(ctx, db.SetUserPreferredChannelParams{
+		PreferredChannel: channel,
+		UserID:           userID,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to set preferred channel", "user_id", userID, "error", err)
+		return NotificationPreferences{}, ErrInternalServer
+	}
+	if err := s.querier.UpsertNotificationPreferences(ctx, db.UpsertNotificationPreferencesParams{
+		UserID:          userID,
+		Reminders:       params.Reminders,
+		Broadcasts:      params.Broadcasts,
+		CoverageAlerts:  params.CoverageAlerts,
+		ReportUpdates:   params.ReportUpdates,
+		QuietHoursStart: sql.NullString{String: start, Valid: start != ""},
+		QuietHoursEnd:   sql.NullString{String: end, Valid: end != ""},
+		Timezone:        sql.NullString{String: timezone, Valid: timezone != ""},
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save notification preferences", "user_id", userID, "error", err)
+		return NotificationPreferences{}, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Notification preferences updated", "user_id", userID)
+	return s.Get(ctx, userID)
+}
+
+// parseClockTime parses an HH:MM time of day into minutes after midnight.
+func parseClockTime(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
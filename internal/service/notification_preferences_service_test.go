@@ -0,0 +1,69 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationPreferences_QuietPeriod(t *testing.T) {
+	johannesburg, err := time.LoadLocation("Africa/Johannesburg")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.June, day, hour, minute, 0, 0, johannesburg)
+	}
+
+	overnight := NotificationPreferences{QuietHoursStart: "22:00", QuietHoursEnd: "06:30", location: johannesburg}
+	tests := []struct {
+		name      string
+		prefs     NotificationPreferences
+		t         time.Time
+		wantQuiet bool
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{"before overnight quiet hours", overnight, at(10, 21, 59), false, time.Time{}, time.Time{}},
+		{"start of overnight quiet hours", overnight, at(10, 22, 0), true, at(10, 22, 0), at(11, 6, 30)},
+		{"after midnight", overnight, at(11, 3, 0), true, at(10, 22, 0), at(11, 6, 30)},
+		{"end of overnight quiet hours", overnight, at(11, 6, 30), false, time.Time{}, time.Time{}},
+		{"compared in the user's zone", overnight, time.Date(2025, time.June, 10, 20, 30, 0, 0, time.UTC), true, at(10, 22, 0), at(11, 6, 30)},
+		{"daytime quiet hours", NotificationPreferences{QuietHoursStart: "13:00", QuietHoursEnd: "15:00", location: johannesburg}, at(10, 14, 0), true, at(10, 13, 0), at(10, 15, 0)},
+		{"outside daytime quiet hours", NotificationPreferences{QuietHoursStart: "13:00", QuietHoursEnd: "15:00", location: johannesburg}, at(10, 23, 0), false, time.Time{}, time.Time{}},
+		{"no quiet hours", NotificationPreferences{location: johannesburg}, at(10, 23, 0), false, time.Time{}, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, quiet := tt.prefs.QuietPeriod(tt.t)
+			assert.Equal(t, tt.wantQuiet, quiet)
+			if tt.wantQuiet {
+				assert.True(t, tt.wantStart.Equal(start), "start %s, want %s", start, tt.wantStart)
+				assert.True(t, tt.wantEnd.Equal(end), "end %s, want %s", end, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestNotificationPreferences_Allows(t *testing.T) {
+	prefs := NotificationPreferences{Reminders: true, Broadcasts: false, CoverageAlerts: true, ReportUpdates: false}
+
+	assert.True(t, prefs.Allows(NotificationCategoryReminders))
+	assert.False(t, prefs.Allows(NotificationCategoryBroadcasts))
+	assert.True(t, prefs.Allows(NotificationCategoryCoverageAlerts))
+	assert.False(t, prefs.Allows(NotificationCategoryReportUpdates))
+	assert.True(t, prefs.Allows(NotificationCategoryUrgent), "urgent messages cannot be opted out of")
+	assert.True(t, prefs.Allows(""), "transactional messages cannot be opted out of")
+}
+
+func TestReminderTime(t *testing.T) {
+	prefs := NotificationPreferences{QuietHoursStart: "22:00", QuietHoursEnd: "07:00", location: time.UTC}
+
+	due := time.Date(2025, time.June, 10, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, time.June, 10, 21, 59, 0, 0, time.UTC), reminderTime(prefs, due),
+		"a reminder in quiet hours is sent before they start")
+
+	due = time.Date(2025, time.June, 10, 19, 0, 0, 0, time.UTC)
+	assert.Equal(t, due, reminderTime(prefs, due))
+}
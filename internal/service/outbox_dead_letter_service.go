@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+// OutboxStatusDeadLettered is the status of outbox items that have used up their retries.
+const OutboxStatusDeadLettered = "permanently_failed"
+
+var (
+	ErrOutboxItemNotFound        = errors.New("outbox item not found")
+	ErrOutboxItemNotDeadLettered = errors.New("outbox item is not dead-lettered")
+)
+
+// OutboxDeadLetterService lets admins inspect, requeue and discard outbox
+// items that failed on every attempt.
+type OutboxDeadLetterService struct {
+	querier db.Querier
+	logger  *slog.Logger
+}
+
+// NewOutboxDeadLetterService creates a new OutboxDeadLetterService.
+func NewOutboxDeadLetterService(querier db.Querier, logger *slog.Logger) *OutboxDeadLetterService {
+	return &OutboxDeadLetterService{
+		querier: querier,
+		logger:  logger.With("service", "OutboxDeadLetterService"),
+	}
+}
+
+// ListDeadLetters returns dead-lettered items, most recent first. An empty
+// messageType lists every type.
+func (s *OutboxDeadLetterService) ListDeadLetters(ctx context.Context, messageType string, limit, offset int64) ([]db.Outbox, error) {
+	params := db.ListDeadLetterOutboxItemsParams{Limit: limit, Offset: offset}
+	if messageType != "" {
+		params.MessageType = messageType
+	}
+	items, err := s.querier.ListDeadLetterOutboxItems(ctx, params)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list dead-lettered outbox items", "error", err)
+		return nil, ErrInternalServer
+	}
+	return items, nil
+}
+
+// GetDeadLetter returns a single dead-lettered item.
+func (s *OutboxDeadLetterService) GetDeadLetter(ctx context.Context, outboxID int64) (db.Outbox, error) {
+	item, err := s.querier.GetOutboxItemByID(ctx, outboxID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Outbox{}, ErrOutboxItemNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get outbox item", "outbox_id", outboxID, "error", err)
+		return db.Outbox{}, ErrInternalServer
+	}
+	if item.Status != OutboxStatusDeadLettered {
+		return db.Outbox{}, ErrOutboxItemNotDeadLettered
+	}
+	return item, nil
+}
+
+// Requeue returns a dead-lettered item to the outbox with a fresh set of
+// retries. It is sent on the next dispatcher run.
+func (s *OutboxDeadLetterService) Requeue(ctx context.Context, outboxID int64) (db.Outbox, error) {
+	if _, err := s.GetDeadLetter(ctx, outboxID); err != nil {
+		return db.Outbox{}, err
+	}
+
+	item, err := s.querier.RequeueOutboxItem(ctx, db.RequeueOutboxItemParams{
+		SendAt:   time.Now().UTC(),
+		OutboxID: outboxID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Outbox{}, ErrOutboxItemNotDeadLettered
+		}
+		s.logger.ErrorContext(ctx, "Failed to requeue outbox item", "outbox_id", outboxID, "error", err)
+		return db.Outbox{}, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Dead-lettered outbox item requeued", "outbox_id", outboxID, "message_type", item.MessageType)
+	return item, nil
+}
+
+// Discard closes a dead-lettered item without sending it. The row is kept so
+// that escalation history still refers to it.
+func (s *OutboxDeadLetterService) Discard(ctx context.Context, outboxID int64) (db.Outbox, error) {
+	if _, err := s.GetDeadLetter(ctx, outboxID); err != nil {
+		return db.Outbox{}, err
+	}
+
+	item, err := s.querier.DiscardOutboxItem(ctx, outboxID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Outbox{}, ErrOutboxItemNotDeadLettered
+		}
+		s.logger.ErrorContext(ctx, "Failed to discard outbox item", "outbox_id", outboxID, "error", err)
+		return db.Outbox{}, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Dead-lettered outbox item discarded", "outbox_id", outboxID, "message_type", item.MessageType)
+	return item, nil
+}
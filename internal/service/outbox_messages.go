@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+// Outbox message types. "push", "sms" and "email" items carry a ready-to-send
+// payload for that channel, as does EMAIL_VERIFICATION, which must go to the
+// address being confirmed; the others carry data that the dispatcher renders
+// and routes to the user's preferred channel.
+const (
+	OutboxMessagePush                   = "push"
+	OutboxMessageSMS                    = "sms"
+	OutboxMessageEmail                  = "email"
+	OutboxMessageEmailVerification      = "EMAIL_VERIFICATION"
+	OutboxMessageOTPVerification        = "OTP_VERIFICATION"
+	OutboxMessageBookingConfirmation    = "BOOKING_CONFIRMATION"
+	OutboxMessageBookingCancellation    = "BOOKING_CANCELLATION"
+	OutboxMessageAdminShiftAssignment   = "ADMIN_SHIFT_ASSIGNMENT"
+	OutboxMessageAdminShiftUnassignment = "ADMIN_SHIFT_UNASSIGNMENT"
+)
+
+// ErrUnknownOutboxMessageType is returned when enqueueing a message type the dispatcher cannot deliver.
+var ErrUnknownOutboxMessageType = errors.New("unknown outbox message type")
+
+// OutboxMessageTypes returns every message type that may be enqueued. The
+// dispatcher must have a handler or channel for each of them.
+func OutboxMessageTypes() []string {
+	return []string{
+		OutboxMessagePush,
+		OutboxMessageSMS,
+		OutboxMessageEmail,
+		OutboxMessageEmailVerification,
+		OutboxMessageOTPVerification,
+		OutboxMessageBookingConfirmation,
+		OutboxMessageBookingCancellation,
+		OutboxMessageAdminShiftAssignment,
+		OutboxMessageAdminShiftUnassignment,
+	}
+}
+
+// enqueueOutboxItem creates an outbox item after checking its message type.
+// An unknown type is a programming error: it would sit in the outbox failing
+// until it is given up on, so it is logged as an error and refused here.
+func enqueueOutboxItem(ctx context.Context, querier db.Querier, logger *slog.Logger, params db.CreateOutboxItemParams) (db.Outbox, error) {
+	for _, messageType := range OutboxMessageTypes() {
+		if params.MessageType == messageType {
+			return querier.CreateOutboxItem(ctx, params)
+		}
+	}
+	logger.ErrorContext(ctx, "Refusing to enqueue outbox item with unknown message type",
+		"message_type", params.MessageType, "user_id", params.UserID.Int64)
+	return db.Outbox{}, fmt.Errorf("%w: %q", ErrUnknownOutboxMessageType, params.MessageType)
+}
@@ -0,0 +1,310 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"night-owls-go/internal/config"
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+var (
+	ErrLocationSharingInactive = errors.New("location sharing is not active for this booking")
+	ErrBookingNotActive        = errors.New("booking is not an active patrol - check in first and share before the shift ends")
+	ErrInvalidLocation         = errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+	ErrTooManyLocations        = errors.New("too many locations in one batch")
+	ErrNoLocations             = errors.New("at least one location is required")
+)
+
+// Reasons recorded when location sharing stops
+const (
+	PatrolSharingStopOwl      = "owl"
+	PatrolSharingStopCheckOut = "check_out"
+	PatrolSharingStopShiftEnd = "shift_end"
+)
+
+// MaxPatrolLocationBatch caps the breadcrumbs accepted in one upload. Clients
+// buffer points while offline and send them in batches.
+const MaxPatrolLocationBatch = 100
+
+// PatrolLocationPoint is a single GPS breadcrumb sent by an owl.
+type PatrolLocationPoint struct {
+	Latitude   float64
+	Longitude  float64
+	Accuracy   *float64
+	RecordedAt time.Time
+}
+
+// PatrolTrackingService manages opt-in live location sharing for active bookings.
+// Sharing is only honoured while the booking is checked in, not checked out and
+// before shift end. It stops automatically when any of these no longer hold.
+type PatrolTrackingService struct {
+	querier db.Querier
+	cfg     *config.Config
+	logger  *slog.Logger
+}
+
+// NewPatrolTrackingService creates a new PatrolTrackingService.
+func NewPatrolTrackingService(querier db.Querier, cfg *config.Config, logger *slog.Logger) *PatrolTrackingService {
+	return &PatrolTrackingService{
+		querier: querier,
+		cfg:     cfg,
+		logger:  logger.With("service", "PatrolTrackingService"),
+	}
+}
+
+// getOwnedBooking loads a booking and checks that it belongs to the user.
+func (s *PatrolTrackingService) getOwnedBooking(ctx context.Context, bookingID, userID int64) (db.Booking, error) {
+	booking, err := s.querier.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Booking{}, ErrBookingNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get booking", "booking_id", bookingID, "error", err)
+		return db.Booking{}, ErrInternalServer
+	}
+	if booking.UserID != userID {
+		return db.Booking{}, ErrForbiddenUpdate
+	}
+	return booking, nil
+}
+
+// stopReasonFor returns why sharing can no longer continue for a booking, or "" if it can.
+func stopReasonFor(booking db.Booking, now time.Time) string {
+	switch {
+	case booking.CheckedOutAt.Valid:
+		return PatrolSharingStopCheckOut
+	case !now.Before(booking.ShiftEnd):
+		return PatrolSharingStopShiftEnd
+	}
+	return ""
+}
+
+// StartSharing opts a checked-in booking into live location sharing.
+func (s *PatrolTrackingService) StartSharing(ctx context.Context, bookingID, userID int64) (db.PatrolLocationSharing, error) {
+	booking, err := s.getOwnedBooking(ctx, bookingID, userID)
+	if err != nil {
+		return db.PatrolLocationSharing{}, err
+	}
+	if !booking.CheckedInAt.Valid || stopReasonFor(booking, time.Now().UTC()) != "" {
+		return db.PatrolLocationSharing{}, ErrBookingNotActive
+	}
+
+	sharing, err := s.querier.StartPatrolLocationSharing(ctx, db.StartPatrolLocationSharingParams{
+		BookingID: bookingID,
+		UserID:    userID,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to start location sharing", "booking_id", bookingID, "error", err)
+		return db.PatrolLocationSharing{}, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Location sharing started", "booking_id", bookingID, "user_id", userID)
+	return sharing, nil
+}
+
+// StopSharing ends location sharing at the owl's request. Stopping an inactive
+// share is not an error.
+func (s *PatrolTrackingService) StopSharing(ctx context.Context, bookingID, userID int64) error {
+	if _, err := s.getOwnedBooking(ctx, bookingID, userID); err != nil {
+		return err
+	}
+	return s.stop(ctx, bookingID, PatrolSharingStopOwl, time.Now().UTC())
+}
+
+func (s *PatrolTrackingService) stop(ctx context.Context, bookingID int64, reason string, now time.Time) error {
+	if _, err := s.querier.StopPatrolLocationSharing(ctx, db.StopPatrolLocationSharingParams{
+		StoppedAt:  sql.NullTime{Time: now, Valid: true},
+		StopReason: sql.NullString{String: reason, Valid: true},
+		BookingID:  bookingID,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to stop location sharing", "booking_id", bookingID, "reason", reason, "error", err)
+		return ErrInternalServer
+	}
+	s.logger.InfoContext(ctx, "Location sharing stopped", "booking_id", bookingID, "reason", reason)
+	return nil
+}
+
+// RecordLocations stores a batch of breadcrumbs for a booking. Points are only
+// accepted while sharing is active. If the shift has ended or the owl has checked
+// out, sharing is stopped and ErrLocationSharingInactive is returned so the
+// client stops sending.
+func (s *PatrolTrackingService) RecordLocations(ctx context.Context, bookingID, userID int64, points []PatrolLocationPoint) (int, error) {
+	if len(points) == 0 {
+		return 0, ErrNoLocations
+	}
+	if len(points) > MaxPatrolLocationBatch {
+		return 0, fmt.Errorf("%w: maximum is %d", ErrTooManyLocations, MaxPatrolLocationBatch)
+	}
+	for _, p := range points {
+		if p.Latitude < -90 || p.Latitude > 90 || p.Longitude < -180 || p.Longitude > 180 {
+			return 0, ErrInvalidLocation
+		}
+	}
+
+	booking, err := s.getOwnedBooking(ctx, bookingID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	sharing, err := s.querier.GetPatrolLocationSharing(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrLocationSharingInactive
+		}
+		s.logger.ErrorContext(ctx, "Failed to get location sharing", "booking_id", bookingID, "error", err)
+		return 0, ErrInternalServer
+	}
+	if sharing.StoppedAt.Valid {
+		return 0, ErrLocationSharingInactive
+	}
+
+	now := time.Now().UTC()
+	if reason := stopReasonFor(booking, now); reason != "" {
+		if err := s.stop(ctx, bookingID, reason, now); err != nil {
+			return 0, err
+		}
+		return 0, ErrLocationSharingInactive
+	}
+
+	recorded := 0
+	for _, p := range points {
+		recordedAt := p.RecordedAt.UTC()
+		if p.RecordedAt.IsZero() || recordedAt.After(now) {
+			recordedAt = now
+		}
+		// Points buffered from before sharing started or after the shift ended are dropped
+		if recordedAt.Before(sharing.StartedAt) || !recordedAt.Before(booking.ShiftEnd) {
+			continue
+		}
+
+		accuracy := sql.NullFloat64{}
+		if p.Accuracy != nil {
+			accuracy = sql.NullFloat64{Float64: *p.Accuracy, Valid: true}
+		}
+		if err := s.querier.CreatePatrolLocation(ctx, db.CreatePatrolLocationParams{
+			BookingID:  bookingID,
+			UserID:     userID,
+			Latitude:   p.Latitude,
+			Longitude:  p.Longitude,
+			Accuracy:   accuracy,
+			RecordedAt: recordedAt,
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to store patrol location", "booking_id", bookingID, "error", err)
+			return recorded, ErrInternalServer
+		}
+		recorded++
+	}
+
+	return recorded, nil
+}
+
+// ListLivePositions returns the latest position of every owl currently sharing.
+func (s *PatrolTrackingService) ListLivePositions(ctx context.Context, now time.Time) ([]db.ListLivePatrolPositionsRow, error) {
+	positions, err := s.querier.ListLivePatrolPositions(ctx, now.UTC())
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list live patrol positions", "error", err)
+		return nil, ErrInternalServer
+	}
+	return positions, nil
+}
+
+// BuildTrack renders the breadcrumbs of a booking as GeoJSON for replay: a
+// LineString of the whole route followed by a Point per breadcrumb carrying
+// its timestamp.
+func (s *PatrolTrackingService) BuildTrack(ctx context.Context, bookingID int64) (*GeoJSONFeatureCollection, error) {
+	booking, err := s.querier.GetBookingByID(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBookingNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get booking for track", "booking_id", bookingID, "error", err)
+		return nil, ErrInternalServer
+	}
+
+	locations, err := s.querier.ListPatrolLocationsByBooking(ctx, bookingID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list patrol locations", "booking_id", bookingID, "error", err)
+		return nil, ErrInternalServer
+	}
+
+	collection := NewGeoJSONFeatureCollection()
+	collection.Metadata = map[string]interface{}{
+		"booking_id":  booking.BookingID,
+		"user_id":     booking.UserID,
+		"schedule_id": booking.ScheduleID,
+		"shift_start": booking.ShiftStart,
+		"shift_end":   booking.ShiftEnd,
+		"point_count": len(locations),
+	}
+	if len(locations) == 0 {
+		return collection, nil
+	}
+
+	line := make([][]float64, 0, len(locations))
+	for _, loc := range locations {
+		line = append(line, []float64{loc.Longitude, loc.Latitude})
+	}
+	if len(line) >= 2 {
+		collection.Features = append(collection.Features, GeoJSONFeature{
+			Type:     "Feature",
+			Geometry: &GeoJSONGeometry{Type: "LineString", Coordinates: line},
+			Properties: map[string]interface{}{
+				"booking_id": booking.BookingID,
+				"started_at": locations[0].RecordedAt,
+				"ended_at":   locations[len(locations)-1].RecordedAt,
+			},
+		})
+	}
+
+	for _, loc := range locations {
+		properties := map[string]interface{}{
+			"location_id": loc.LocationID,
+			"recorded_at": loc.RecordedAt,
+		}
+		if loc.Accuracy.Valid {
+			properties["accuracy"] = loc.Accuracy.Float64
+		}
+		collection.Features = append(collection.Features, GeoJSONFeature{
+			Type:       "Feature",
+			Geometry:   &GeoJSONGeometry{Type: "Point", Coordinates: []float64{loc.Longitude, loc.Latitude}},
+			Properties: properties,
+		})
+	}
+
+	return collection, nil
+}
+
+// StopEndedSharing stops sharing for every booking whose shift has ended.
+func (s *PatrolTrackingService) StopEndedSharing(ctx context.Context, now time.Time) (int64, error) {
+	stopped, err := s.querier.StopEndedPatrolLocationSharing(ctx, db.StopEndedPatrolLocationSharingParams{
+		StoppedAt: sql.NullTime{Time: now.UTC(), Valid: true},
+		ShiftEnd:  now.UTC(),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to stop location sharing for ended shifts", "error", err)
+		return 0, ErrInternalServer
+	}
+	if stopped > 0 {
+		s.logger.InfoContext(ctx, "Stopped location sharing for ended shifts", "count", stopped)
+	}
+	return stopped, nil
+}
+
+// PurgeOldLocations deletes breadcrumbs older than the configured retention period.
+func (s *PatrolTrackingService) PurgeOldLocations(ctx context.Context, now time.Time) (int64, error) {
+	cutoff := now.UTC().Add(-s.cfg.PatrolLocationRetention)
+	deleted, err := s.querier.DeletePatrolLocationsBefore(ctx, cutoff)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to purge old patrol locations", "cutoff", cutoff, "error", err)
+		return 0, ErrInternalServer
+	}
+	if deleted > 0 {
+		s.logger.InfoContext(ctx, "Purged old patrol locations", "count", deleted, "cutoff", cutoff)
+	}
+	return deleted, nil
+}
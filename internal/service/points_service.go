@@ -52,6 +52,7 @@ const (
 	ReasonWeekendBonus    PointReason = "weekend_bonus"
 	ReasonLateNightBonus  PointReason = "late_night_bonus"
 	ReasonFrequencyBonus  PointReason = "frequency_bonus"
+	ReasonDuplicateReport PointReason = "duplicate_report"
 )
 
 // AwardShiftCheckinPoints awards points when a user checks in to a shift
@@ -190,6 +191,43 @@ func (ps *PointsService) AwardShiftCompletionPoints(ctx context.Context, userID
 	return nil
 }
 
+// RevokeDuplicateReportPoints takes back the serious report bonus a user earned
+// for a booking's report once it has been merged into another report of the same
+// incident. It returns false if there was no bonus left to take back.
+func (ps *PointsService) RevokeDuplicateReportPoints(ctx context.Context, userID int64, bookingID int64) (bool, error) {
+	countFor := func(reason PointReason) (int64, error) {
+		return ps.querier.CountUserBookingPointsByReason(ctx, db.CountUserBookingPointsByReasonParams{
+			UserID:    userID,
+			BookingID: sql.NullInt64{Int64: bookingID, Valid: true},
+			Reason:    string(reason),
+		})
+	}
+
+	awarded, err := countFor(ReasonLevel2Report)
+	if err != nil {
+		return false, fmt.Errorf("failed to count serious report bonuses: %w", err)
+	}
+	revoked, err := countFor(ReasonDuplicateReport)
+	if err != nil {
+		return false, fmt.Errorf("failed to count revoked bonuses: %w", err)
+	}
+	if awarded <= revoked {
+		return false, nil
+	}
+
+	if err := ps.awardPointsWithHistory(ctx, userID, &bookingID, -PointsLevel2Report, ReasonDuplicateReport, 1.0); err != nil {
+		return false, fmt.Errorf("failed to revoke duplicate report points: %w", err)
+	}
+	if err := ps.updateUserTotalPoints(ctx, userID); err != nil {
+		return false, fmt.Errorf("failed to update total points: %w", err)
+	}
+
+	ps.logger.InfoContext(ctx, "Revoked serious report bonus for duplicate report",
+		"user_id", userID, "booking_id", bookingID, "points", -PointsLevel2Report)
+
+	return true, nil
+}
+
 // Helper methods
 
 func (ps *PointsService) awardPointsWithHistory(ctx context.Context, userID int64, bookingID *int64, points int, reason PointReason, multiplier float64) error {
@@ -2,8 +2,12 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"time"
 
 	"night-owls-go/internal/config"
 	db "night-owls-go/internal/db/sqlc_generated"
@@ -11,6 +15,13 @@ import (
 	webpush "github.com/SherClockHolmes/webpush-go"
 )
 
+// A subscription is removed once it has failed this many times in a row and
+// has not had a success for pushPruneAfter.
+const (
+	maxConsecutivePushFailures = 5
+	pushPruneAfter             = 72 * time.Hour
+)
+
 // PushSender sends web push notifications.
 type PushSender struct {
 	db     db.Querier
@@ -30,10 +41,6 @@ func NewPushSender(db db.Querier, cfg *config.Config, logger *slog.Logger) *Push
 // Send sends a push notification to all registered subscriptions for a user.
 // Now returns an error if any send fails, for better upstream handling.
 func (s *PushSender) Send(ctx context.Context, userID int64, payload []byte, ttl int) error {
-	if ttl == 0 {
-		ttl = 604800 // Default to 1 week if not specified
-	}
-
 	subs, err := s.db.GetSubscriptionsByUser(ctx, userID)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to get subscriptions by user", "user_id", userID, "error", err)
@@ -47,42 +54,99 @@ func (s *PushSender) Send(ctx context.Context, userID int64, payload []byte, ttl
 
 	var lastErr error
 	for _, sub := range subs {
-		subscription := &webpush.Subscription{
-			Endpoint: sub.Endpoint,
-			Keys:     webpush.Keys{P256dh: sub.P256dhKey, Auth: sub.AuthKey},
+		result := s.sendToSubscription(ctx, userID, sub, payload, ttl)
+		if !result.Delivered {
+			lastErr = errors.New(result.Error) // Capture last error, continue to next sub
 		}
+	}
+
+	if lastErr != nil {
+		return fmt.Errorf("failed to send to at least one subscription: %w", lastErr)
+	}
+	return nil
+}
+
+// sendToSubscription sends a push notification to one subscription and
+// records the outcome against it. Subscriptions the push service reports as
+// gone are removed, as are those that keep failing.
+func (s *PushSender) sendToSubscription(ctx context.Context, userID int64, sub db.GetSubscriptionsByUserRow, payload []byte, ttl int) PushResult {
+	if ttl == 0 {
+		ttl = 604800 // Default to 1 week if not specified
+	}
+	result := PushResult{DeviceID: sub.ID}
 
-		resp, err := webpush.SendNotification(payload, subscription, &webpush.Options{
-			VAPIDPublicKey:  s.config.VAPIDPublic,
-			VAPIDPrivateKey: s.config.VAPIDPrivate,
-			TTL:             ttl,
-			Subscriber:      s.config.VAPIDSubject,
-			Urgency:         "high", // Add for FCM priority
-		})
-		if resp != nil && resp.Body != nil {
+	subscription := &webpush.Subscription{
+		Endpoint: sub.Endpoint,
+		Keys:     webpush.Keys{P256dh: sub.P256dhKey, Auth: sub.AuthKey},
+	}
+	resp, err := webpush.SendNotification(payload, subscription, &webpush.Options{
+		VAPIDPublicKey:  s.config.VAPIDPublic,
+		VAPIDPrivateKey: s.config.VAPIDPrivate,
+		TTL:             ttl,
+		Subscriber:      s.config.VAPIDSubject,
+		Urgency:         "high", // Add for FCM priority
+	})
+	if resp != nil {
+		result.StatusCode = resp.StatusCode
+		if resp.Body != nil {
 			_ = resp.Body.Close()
 		}
+	}
+	if err == nil && (result.StatusCode < 200 || result.StatusCode >= 300) {
+		err = fmt.Errorf("non-success status: %d", result.StatusCode)
+	}
 
-		if err != nil || (resp != nil && (resp.StatusCode < 200 || resp.StatusCode >= 300)) {
-			if err == nil && resp != nil { err = fmt.Errorf("non-success status: %d", resp.StatusCode) }
-			s.logger.ErrorContext(ctx, "failed to send web push notification", "user_id", userID, "endpoint", sub.Endpoint, "error", err)
-			lastErr = err // Capture last error
-			// Clean up expired subscriptions
-			if resp != nil && (resp.StatusCode == 404 || resp.StatusCode == 410) {
-				params := db.DeleteSubscriptionParams{Endpoint: sub.Endpoint, UserID: userID}
-				if delErr := s.db.DeleteSubscription(ctx, params); delErr != nil {
-					s.logger.ErrorContext(ctx, "failed to remove expired subscription", "user_id", userID, "endpoint", sub.Endpoint, "error", delErr)
-				} else {
-					s.logger.InfoContext(ctx, "removed expired push subscription", "user_id", userID, "endpoint", sub.Endpoint)
-				}
-			}
-			continue // Continue to next sub, don't fail all
+	if err == nil {
+		result.Delivered = true
+		if recErr := s.db.RecordPushSuccess(ctx, sub.ID); recErr != nil {
+			s.logger.ErrorContext(ctx, "failed to record push success", "subscription_id", sub.ID, "error", recErr)
 		}
-		s.logger.InfoContext(ctx, "web push notification sent successfully", "user_id", userID, "endpoint", sub.Endpoint, "status_code", resp.StatusCode)
+		s.logger.InfoContext(ctx, "web push notification sent successfully", "user_id", userID, "subscription_id", sub.ID, "status_code", result.StatusCode)
+		return result
 	}
 
-	if lastErr != nil {
-		return fmt.Errorf("failed to send to at least one subscription: %w", lastErr)
+	result.Error = err.Error()
+	s.logger.ErrorContext(ctx, "failed to send web push notification", "user_id", userID, "subscription_id", sub.ID, "endpoint", sub.Endpoint, "error", err)
+
+	// Clean up expired subscriptions
+	if result.StatusCode == http.StatusNotFound || result.StatusCode == http.StatusGone {
+		result.Removed = s.removeSubscription(ctx, userID, sub.ID, "expired")
+		return result
 	}
-	return nil
+
+	failure, recErr := s.db.RecordPushFailure(ctx, db.RecordPushFailureParams{
+		LastError: sql.NullString{String: result.Error, Valid: true},
+		ID:        sub.ID,
+	})
+	if recErr != nil {
+		s.logger.ErrorContext(ctx, "failed to record push failure", "subscription_id", sub.ID, "error", recErr)
+		return result
+	}
+	if shouldPruneSubscription(failure, time.Now()) {
+		result.Removed = s.removeSubscription(ctx, userID, sub.ID, "failing")
+	}
+	return result
+}
+
+// shouldPruneSubscription reports whether a subscription has failed often
+// enough, for long enough, to be given up on. The age check keeps an outage
+// at the push service from wiping out every subscription.
+func shouldPruneSubscription(failure db.RecordPushFailureRow, now time.Time) bool {
+	if failure.ConsecutiveFailures < maxConsecutivePushFailures {
+		return false
+	}
+	lastGood := failure.CreatedAt
+	if failure.LastSuccessAt.Valid {
+		lastGood = failure.LastSuccessAt
+	}
+	return !lastGood.Valid || now.Sub(lastGood.Time) >= pushPruneAfter
+}
+
+func (s *PushSender) removeSubscription(ctx context.Context, userID, subscriptionID int64, reason string) bool {
+	if err := s.db.DeletePushSubscriptionByID(ctx, subscriptionID); err != nil {
+		s.logger.ErrorContext(ctx, "failed to remove push subscription", "user_id", userID, "subscription_id", subscriptionID, "reason", reason, "error", err)
+		return false
+	}
+	s.logger.InfoContext(ctx, "removed push subscription", "user_id", userID, "subscription_id", subscriptionID, "reason", reason)
+	return true
 }
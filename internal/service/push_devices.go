@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+var ErrPushDeviceNotFound = errors.New("push device not found")
+
+// PushDevice is one of a user's push subscriptions with its delivery stats.
+// The endpoint itself is not exposed; PushService names the host it lives on.
+type PushDevice struct {
+	ID                  int64      `json:"id"`
+	Label               string     `json:"label"`
+	UserAgent           string     `json:"user_agent,omitempty"`
+	Platform            string     `json:"platform,omitempty"`
+	PushService         string     `json:"push_service"`
+	CreatedAt           *time.Time `json:"created_at,omitempty"`
+	SuccessCount        int64      `json:"success_count"`
+	FailureCount        int64      `json:"failure_count"`
+	ConsecutiveFailures int64      `json:"consecutive_failures"`
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	Healthy             bool       `json:"healthy"` // No failures since the last success
+}
+
+// PushResult is the outcome of sending a push notification to one device.
+type PushResult struct {
+	DeviceID   int64  `json:"device_id"`
+	Delivered  bool   `json:"delivered"`
+	StatusCode int    `json:"status_code,omitempty"` // Status returned by the push service
+	Error      string `json:"error,omitempty"`
+	Removed    bool   `json:"removed"` // The subscription was pruned after this failure
+}
+
+// ListDevices returns a user's push subscriptions, newest first.
+func (s *PushSender) ListDevices(ctx context.Context, userID int64) ([]PushDevice, error) {
+	subs, err := s.db.ListUserPushSubscriptions(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to list push subscriptions", "user_id", userID, "error", err)
+		return nil, ErrInternalServer
+	}
+	devices := make([]PushDevice, 0, len(subs))
+	for _, sub := range subs {
+		devices = append(devices, pushDevice(sub))
+	}
+	return devices, nil
+}
+
+// SendTest sends a test notification to one of a user's devices and reports
+// what the push service said. The outcome counts towards the device's stats.
+func (s *PushSender) SendTest(ctx context.Context, userID, deviceID int64) (PushResult, error) {
+	sub, err := s.db.GetUserPushSubscription(ctx, db.GetUserPushSubscriptionParams{ID: deviceID, UserID: userID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return PushResult{}, ErrPushDeviceNotFound
+		}
+		s.logger.ErrorContext(ctx, "failed to get push subscription", "user_id", userID, "subscription_id", deviceID, "error", err)
+		return PushResult{}, ErrInternalServer
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":  "test",
+		"title": "Test Push Notification",
+		"body":  "This is a test push notification from the admin panel",
+		"data": map[string]interface{}{
+			"test":      true,
+			"device_id": deviceID,
+		},
+	})
+	if err != nil {
+		return PushResult{}, err
+	}
+
+	target := db.GetSubscriptionsByUserRow{ID: sub.ID, Endpoint: sub.Endpoint, P256dhKey: sub.P256dhKey, AuthKey: sub.AuthKey}
+	return s.sendToSubscription(ctx, userID, target, payload, 300), nil // 5 minutes TTL
+}
+
+func pushDevice(sub db.PushSubscription) PushDevice {
+	label := sub.DeviceLabel.String
+	if label == "" {
+		label = PushDeviceLabel(sub.UserAgent.String, sub.Platform.String)
+	}
+	device := PushDevice{
+		ID:                  sub.ID,
+		Label:               label,
+		UserAgent:           sub.UserAgent.String,
+		Platform:            sub.Platform.String,
+		CreatedAt:           nullTimePointer(sub.CreatedAt),
+		SuccessCount:        sub.SuccessCount,
+		FailureCount:        sub.FailureCount,
+		ConsecutiveFailures: sub.ConsecutiveFailures,
+		LastSuccessAt:       nullTimePointer(sub.LastSuccessAt),
+		LastFailureAt:       nullTimePointer(sub.LastFailureAt),
+		LastError:           sub.LastError.String,
+		Healthy:             sub.ConsecutiveFailures == 0,
+	}
+	if endpoint, err := url.Parse(sub.Endpoint); err == nil {
+		device.PushService = endpoint.Host
+	}
+	return device
+}
+
+// userAgentBrowsers and userAgentSystems are matched in order, so more
+// specific tokens come first (Edge and Opera also claim to be Chrome).
+var (
+	userAgentBrowsers = []struct{ token, name string }{
+		{"Edg", "Edge"},
+		{"OPR/", "Opera"},
+		{"SamsungBrowser", "Samsung Internet"},
+		{"FxiOS", "Firefox"},
+		{"Firefox/", "Firefox"},
+		{"CriOS", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	}
+	userAgentSystems = []struct{ token, name string }{
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"CrOS", "ChromeOS"},
+		{"Macintosh", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// PushDeviceLabel describes a device from its user agent, e.g. "Chrome on
+// Android", falling back to the platform the browser reported.
+func PushDeviceLabel(userAgent, platform string) string {
+	var browser, system string
+	for _, b := range userAgentBrowsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, sys := range userAgentSystems {
+		if strings.Contains(userAgent, sys.token) {
+			system = sys.name
+			break
+		}
+	}
+	if system == "" && platform != "" && platform != "unknown" {
+		system = platform
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "Unknown device"
+	}
+}
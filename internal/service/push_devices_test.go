@@ -0,0 +1,48 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushDeviceLabel(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		platform  string
+		want      string
+	}{
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36", "", "Chrome on Android"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1", "", "Safari on iPhone"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0", "", "Edge on Windows"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14.5; rv:127.0) Gecko/20100101 Firefox/127.0", "", "Firefox on macOS"},
+		{"", "Linux armv8l", "Linux armv8l"},
+		{"", "unknown", "Unknown device"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, PushDeviceLabel(tt.userAgent, tt.platform), "user agent %q", tt.userAgent)
+	}
+}
+
+func TestShouldPruneSubscription(t *testing.T) {
+	now := time.Now()
+	at := func(ago time.Duration) sql.NullTime { return sql.NullTime{Time: now.Add(-ago), Valid: true} }
+	tests := []struct {
+		name    string
+		failure db.RecordPushFailureRow
+		want    bool
+	}{
+		{"few failures", db.RecordPushFailureRow{ConsecutiveFailures: 2, CreatedAt: at(30 * 24 * time.Hour)}, false},
+		{"failing since creation", db.RecordPushFailureRow{ConsecutiveFailures: 5, CreatedAt: at(4 * 24 * time.Hour)}, true},
+		{"new subscription", db.RecordPushFailureRow{ConsecutiveFailures: 5, CreatedAt: at(time.Hour)}, false},
+		{"recent success", db.RecordPushFailureRow{ConsecutiveFailures: 8, CreatedAt: at(30 * 24 * time.Hour), LastSuccessAt: at(time.Hour)}, false},
+		{"old success", db.RecordPushFailureRow{ConsecutiveFailures: 8, CreatedAt: at(30 * 24 * time.Hour), LastSuccessAt: at(5 * 24 * time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, shouldPruneSubscription(tt.failure, now), tt.name)
+	}
+}
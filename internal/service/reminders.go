@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"time"
@@ -13,30 +14,50 @@ import (
 
 // Scheduler handles scheduling of notifications, like shift reminders.
 type Scheduler struct {
-	querier db.Querier
-	logger  *slog.Logger
+	querier     db.Querier
+	preferences *NotificationPreferencesService
+	logger      *slog.Logger
 }
 
 // NewScheduler creates a new Scheduler.
-func NewScheduler(querier db.Querier, logger *slog.Logger) *Scheduler {
+func NewScheduler(querier db.Querier, preferences *NotificationPreferencesService, logger *slog.Logger) *Scheduler {
 	return &Scheduler{
-		querier: querier,
-		logger:  logger.With("service", "Scheduler"),
+		querier:     querier,
+		preferences: preferences,
+		logger:      logger.With("service", "Scheduler"),
 	}
 }
 
 // scheduleReminder creates a scheduled outbox item for a shift reminder.
 // This helper reduces duplication in scheduling logic.
 func (s *Scheduler) scheduleReminder(ctx context.Context, booking db.Booking, hours int, sendAt time.Time) error {
-	payload := fmt.Sprintf(`{"type":"shift_reminder","hours":%d,"start_time":"%s","booking_id":%d}`,
-		hours, booking.ShiftStart.Format(time.RFC3339), booking.BookingID)
+	body := "Your shift starts in 1 hour."
+	if hours != 1 {
+		body = fmt.Sprintf("Your shift starts in %d hours.", hours)
+	}
+	payloadBytes, err := json.Marshal(map[string]interface{}{
+		"type":  "shift_reminder",
+		"title": "Shift reminder",
+		"body":  body,
+		"data": map[string]interface{}{
+			"type":       "shift_reminder",
+			"booking_id": booking.BookingID,
+			"hours":      hours,
+			"start_time": booking.ShiftStart.Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %dh reminder: %w", hours, err)
+	}
+	payload := string(payloadBytes)
 
 	params := db.CreateOutboxItemParams{
-		MessageType: "push",
+		MessageType: OutboxMessagePush,
 		Recipient:   "",
 		Payload:     sql.NullString{String: payload, Valid: true},
 		UserID:      sql.NullInt64{Int64: booking.UserID, Valid: true},
 		SendAt:      sendAt,
+		Category:    sql.NullString{String: NotificationCategoryReminders, Valid: true},
 	}
 
 	s.logger.InfoContext(ctx, "Enqueueing shift reminder",
@@ -46,7 +67,7 @@ func (s *Scheduler) scheduleReminder(ctx context.Context, booking db.Booking, ho
 		"remind_at", sendAt,
 		"payload", payload)
 
-	_, err := s.querier.CreateOutboxItem(ctx, params)
+	_, err = enqueueOutboxItem(ctx, s.querier, s.logger, params)
 	if err != nil {
 		s.logger.ErrorContext(ctx, "failed to enqueue shift reminder",
 			"booking_id", booking.BookingID,
@@ -61,19 +82,53 @@ func (s *Scheduler) scheduleReminder(ctx context.Context, booking db.Booking, ho
 
 // EnqueueShiftReminders schedules -24h and -1h push notification reminders for a booking.
 // It uses the outbox pattern by creating entries in the outbox table.
+// Nothing is queued for users who have turned reminders off, and a reminder
+// that falls in the user's quiet hours is brought forward to just before they
+// begin, so that it is not held back until after the shift has started.
+// Reminders whose time has already passed are skipped.
 func (s *Scheduler) EnqueueShiftReminders(ctx context.Context, booking db.Booking) error {
-	// Schedule 24-hour reminder
-	remindAt24h := booking.ShiftStart.Add(-24 * time.Hour)
-	if err := s.scheduleReminder(ctx, booking, 24, remindAt24h); err != nil {
-		return err
+	prefs, err := s.preferences.Get(ctx, booking.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	if !prefs.Allows(NotificationCategoryReminders) {
+		s.logger.InfoContext(ctx, "User has turned off reminders, not queueing any", "booking_id", booking.BookingID, "user_id", booking.UserID)
+		return nil
 	}
 
-	// Schedule 1-hour reminder
-	remindAt1h := booking.ShiftStart.Add(-1 * time.Hour)
-	if err := s.scheduleReminder(ctx, booking, 1, remindAt1h); err != nil {
-		return err
+	now := time.Now().UTC()
+	for _, hours := range []int{24, 1} {
+		remindAt := reminderTime(prefs, booking.ShiftStart.Add(-time.Duration(hours)*time.Hour))
+		if remindAt.Before(now) {
+			continue
+		}
+		if err := s.scheduleReminder(ctx, booking, hours, remindAt); err != nil {
+			return err
+		}
 	}
 
 	s.logger.InfoContext(ctx, "Shift reminders queued", "booking_id", booking.BookingID)
 	return nil
 }
+
+// CancelShiftReminders cancels the reminders still queued for a booking that
+// has been cancelled.
+func (s *Scheduler) CancelShiftReminders(ctx context.Context, booking db.Booking) error {
+	cancelled, err := s.querier.CancelShiftReminders(ctx, db.CancelShiftRemindersParams{
+		UserID:    sql.NullInt64{Int64: booking.UserID, Valid: true},
+		BookingID: booking.BookingID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cancel shift reminders: %w", err)
+	}
+	s.logger.InfoContext(ctx, "Shift reminders cancelled", "booking_id", booking.BookingID, "count", cancelled)
+	return nil
+}
+
+// reminderTime moves a reminder due during quiet hours to a minute before they start.
+func reminderTime(prefs NotificationPreferences, remindAt time.Time) time.Time {
+	if start, _, quiet := prefs.QuietPeriod(remindAt); quiet {
+		return start.Add(-time.Minute)
+	}
+	return remindAt
+}
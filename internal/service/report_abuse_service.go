@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+var (
+	ErrOffShiftReportRateLimited = errors.New("too many off-shift reports, try again later")
+	ErrOffShiftReportForbidden   = errors.New("only owls and admins may file off-shift reports")
+	ErrReporterTrustTooLow       = errors.New("off-shift reporting suspended after reports were flagged as spam or false alarms")
+	ErrInvalidGPSTimestamp       = errors.New("location timestamp cannot be in the future")
+	ErrInvalidReportFlag         = errors.New("flag must be spam or false_alarm")
+	ErrReportNotFound            = errors.New("report not found")
+	ErrReportFlagNotFound        = errors.New("report is not flagged")
+)
+
+// Report flags admins can set on a report
+const (
+	ReportFlagSpam       = "spam"
+	ReportFlagFalseAlarm = "false_alarm"
+)
+
+// Off-shift report abuse controls
+const (
+	MaxOffShiftReportsPerUser = 10              // Max off-shift reports per user per window
+	MaxOffShiftReportsPerIP   = 30              // Max off-shift reports per client IP per window
+	OffShiftReportWindow      = 1 * time.Hour   // Time window for counting submissions
+	MaxGPSClockSkew           = 5 * time.Minute // How far ahead of the server a location timestamp may be
+
+	// Reporter trust scoring
+	MaxReporterTrustScore           = 100                 // Score of a reporter with no flagged reports
+	SpamFlagPenalty                 = 25                  // Deducted per report flagged as spam
+	FalseAlarmFlagPenalty           = 10                  // Deducted per report flagged as a false alarm
+	ReporterTrustWindow             = 90 * 24 * time.Hour // Flags older than this no longer count
+	MinTrustScoreForOffShiftReports = 30                  // Below this, off-shift reporting is suspended
+)
+
+// offShiftReportRoles are the roles allowed to file off-shift reports
+var offShiftReportRoles = map[string]bool{
+	"owl":   true,
+	"admin": true,
+}
+
+// ReporterTrust summarises how a user's recent reports have been judged by admins.
+type ReporterTrust struct {
+	UserID          int64 `json:"user_id"`
+	Score           int64 `json:"score"`
+	SpamFlags       int64 `json:"spam_flags"`
+	FalseAlarmFlags int64 `json:"false_alarm_flags"`
+	Suspended       bool  `json:"suspended"`
+}
+
+// ReportAbuseService guards off-shift reporting with role checks, per-user and
+// per-IP rate limits and a trust score fed by admin spam and false alarm flags.
+type ReportAbuseService struct {
+	querier db.Querier
+	logger  *slog.Logger
+}
+
+func NewReportAbuseService(querier db.Querier, logger *slog.Logger) *ReportAbuseService {
+	return &ReportAbuseService{
+		querier: querier,
+		logger:  logger.With("service", "ReportAbuse"),
+	}
+}
+
+// CheckOffShiftReport verifies a user is allowed to file an off-shift report now
+func (s *ReportAbuseService) CheckOffShiftReport(ctx context.Context, userID int64, clientIP string) error {
+	user, err := s.querier.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get user for off-shift report", "user_id", userID, "error", err)
+		return ErrInternalServer
+	}
+	if !offShiftReportRoles[user.Role] {
+		s.logger.WarnContext(ctx, "Off-shift report blocked - role not allowed", "user_id", userID, "role", user.Role)
+		return ErrOffShiftReportForbidden
+	}
+
+	trust, err := s.GetReporterTrust(ctx, userID)
+	if err != nil {
+		// Allow the report on database error rather than silencing a real incident
+		s.logger.ErrorContext(ctx, "Failed to get reporter trust score", "user_id", userID, "error", err)
+	} else if trust.Suspended {
+		s.logger.WarnContext(ctx, "Off-shift report blocked - trust score too low",
+			"user_id", userID,
+			"trust_score", trust.Score,
+			"min_trust_score", MinTrustScoreForOffShiftReports,
+		)
+		return ErrReporterTrustTooLow
+	}
+
+	windowStart := time.Now().UTC().Add(-OffShiftReportWindow)
+	userCount, err := s.querier.CountOffShiftReportSubmissionsByUser(ctx, db.CountOffShiftReportSubmissionsByUserParams{
+		UserID:      userID,
+		SubmittedAt: windowStart,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to count off-shift reports by user", "user_id", userID, "error", err)
+		// Allow the report on database error
+		return nil
+	}
+	if userCount >= MaxOffShiftReportsPerUser {
+		s.logger.WarnContext(ctx, "Off-shift report blocked - user rate limit exceeded",
+			"user_id", userID,
+			"count", userCount,
+			"max_reports", MaxOffShiftReportsPerUser,
+		)
+		return ErrOffShiftReportRateLimited
+	}
+
+	if clientIP == "" {
+		return nil
+	}
+	ipCount, err := s.querier.CountOffShiftReportSubmissionsByIP(ctx, db.CountOffShiftReportSubmissionsByIPParams{
+		ClientIp:    sql.NullString{String: clientIP, Valid: true},
+		SubmittedAt: windowStart,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to count off-shift reports by IP", "client_ip", clientIP, "error", err)
+		return nil
+	}
+	if ipCount >= MaxOffShiftReportsPerIP {
+		s.logger.WarnContext(ctx, "Off-shift report blocked - IP rate limit exceeded",
+			"user_id", userID,
+			"client_ip", clientIP,
+			"count", ipCount,
+			"max_reports", MaxOffShiftReportsPerIP,
+		)
+		return ErrOffShiftReportRateLimited
+	}
+
+	return nil
+}
+
+// RecordOffShiftReport counts an accepted off-shift report towards the rate limits
+func (s *ReportAbuseService) RecordOffShiftReport(ctx context.Context, userID int64, clientIP string, reportID int64) {
+	err := s.querier.CreateOffShiftReportSubmission(ctx, db.CreateOffShiftReportSubmissionParams{
+		UserID:      userID,
+		ClientIp:    sql.NullString{String: clientIP, Valid: clientIP != ""},
+		ReportID:    sql.NullInt64{Int64: reportID, Valid: true},
+		SubmittedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record off-shift report submission", "user_id", userID, "report_id", reportID, "error", err)
+	}
+}
+
+// ValidateGPSLocation rejects coordinates outside the valid range and location
+// fixes timestamped in the future.
+func ValidateGPSLocation(location *GPSLocation, now time.Time) error {
+	if location == nil {
+		return nil
+	}
+	if location.Latitude != nil && (*location.Latitude < -90 || *location.Latitude > 90) {
+		return ErrInvalidLocation
+	}
+	if location.Longitude != nil && (*location.Longitude < -180 || *location.Longitude > 180) {
+		return ErrInvalidLocation
+	}
+	if location.Timestamp != nil && location.Timestamp.After(now.Add(MaxGPSClockSkew)) {
+		return ErrInvalidGPSTimestamp
+	}
+	return nil
+}
+
+// trustScore converts flag counts into a score between 0 and MaxReporterTrustScore
+func trustScore(spamFlags, falseAlarmFlags int64) int64 {
+	score := int64(MaxReporterTrustScore) - spamFlags*SpamFlagPenalty - falseAlarmFlags*FalseAlarmFlagPenalty
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+// GetReporterTrust returns a user's trust score from flags on their recent reports
+func (s *ReportAbuseService) GetReporterTrust(ctx context.Context, userID int64) (ReporterTrust, error) {
+	counts, err := s.querier.GetReporterFlagCounts(ctx, db.GetReporterFlagCountsParams{
+		ReporterUserID: sql.NullInt64{Int64: userID, Valid: true},
+		FlaggedAt:      sql.NullTime{Time: time.Now().UTC().Add(-ReporterTrustWindow), Valid: true},
+	})
+	if err != nil {
+		return ReporterTrust{}, err
+	}
+
+	score := trustScore(counts.SpamCount, counts.FalseAlarmCount)
+	return ReporterTrust{
+		UserID:          userID,
+		Score:           score,
+		SpamFlags:       counts.SpamCount,
+		FalseAlarmFlags: counts.FalseAlarmCount,
+		Suspended:       score < MinTrustScoreForOffShiftReports,
+	}, nil
+}
+
+// reporterTrust returns the trust score of a flag's reporter, or nil if the report has none
+func (s *ReportAbuseService) reporterTrust(ctx context.Context, flag db.ReportFlag) *ReporterTrust {
+	if !flag.ReporterUserID.Valid {
+		return nil
+	}
+	trust, err := s.GetReporterTrust(ctx, flag.ReporterUserID.Int64)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get reporter trust score", "user_id", flag.ReporterUserID.Int64, "error", err)
+		return nil
+	}
+	return &trust
+}
+
+// FlagReport marks a report as spam or a false alarm, replacing any earlier
+// flag. The flag counts against the reporter's trust score.
+func (s *ReportAbuseService) FlagReport(ctx context.Context, reportID, flaggedByUserID int64, flag, note string) (db.ReportFlag, *ReporterTrust, error) {
+	if flag != ReportFlagSpam && flag != ReportFlagFalseAlarm {
+		return db.ReportFlag{}, nil, ErrInvalidReportFlag
+	}
+
+	report, err := s.querier.AdminGetReportWithContext(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.ReportFlag{}, nil, ErrReportNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get report to flag", "report_id", reportID, "error", err)
+		return db.ReportFlag{}, nil, ErrInternalServer
+	}
+
+	note = strings.TrimSpace(note)
+	reportFlag, err := s.querier.UpsertReportFlag(ctx, db.UpsertReportFlagParams{
+		ReportID:        reportID,
+		ReporterUserID:  report.UserID,
+		Flag:            flag,
+		Note:            sql.NullString{String: note, Valid: note != ""},
+		FlaggedByUserID: sql.NullInt64{Int64: flaggedByUserID, Valid: true},
+		FlaggedAt:       sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to flag report", "report_id", reportID, "error", err)
+		return db.ReportFlag{}, nil, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Report flagged", "report_id", reportID, "flag", flag, "flagged_by", flaggedByUserID)
+	return reportFlag, s.reporterTrust(ctx, reportFlag), nil
+}
+
+// ClearReportFlag removes a report's flag, restoring the reporter's trust score.
+// It returns the flag that was removed.
+func (s *ReportAbuseService) ClearReportFlag(ctx context.Context, reportID int64) (db.ReportFlag, *ReporterTrust, error) {
+	reportFlag, err := s.GetReportFlag(ctx, reportID)
+	if err != nil {
+		return db.ReportFlag{}, nil, err
+	}
+
+	if _, err := s.querier.DeleteReportFlag(ctx, reportID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to clear report flag", "report_id", reportID, "error", err)
+		return db.ReportFlag{}, nil, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Report flag cleared", "report_id", reportID, "flag", reportFlag.Flag)
+	return reportFlag, s.reporterTrust(ctx, reportFlag), nil
+}
+
+// GetReportFlag returns the flag set on a report
+func (s *ReportAbuseService) GetReportFlag(ctx context.Context, reportID int64) (db.ReportFlag, error) {
+	reportFlag, err := s.querier.GetReportFlag(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.ReportFlag{}, ErrReportFlagNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get report flag", "report_id", reportID, "error", err)
+		return db.ReportFlag{}, ErrInternalServer
+	}
+	return reportFlag, nil
+}
+
+// CleanupOldSubmissions removes off-shift report submissions that no longer count towards any limit
+func (s *ReportAbuseService) CleanupOldSubmissions(ctx context.Context, olderThan time.Duration) (int64, error) {
+	cutoff := time.Now().UTC().Add(-olderThan)
+	deleted, err := s.querier.CleanupOldOffShiftReportSubmissions(ctx, cutoff)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to cleanup old off-shift report submissions", "cutoff", cutoff, "error", err)
+		return 0, err
+	}
+
+	s.logger.InfoContext(ctx, "Cleaned up old off-shift report submissions", "cutoff", cutoff, "deleted", deleted)
+	return deleted, nil
+}
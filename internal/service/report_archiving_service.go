@@ -2,48 +2,295 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"strings"
 	"time"
 
 	db "night-owls-go/internal/db/sqlc_generated"
 )
 
-// ReportArchivingService handles automatic archiving of reports based on retention policies
+var (
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+	ErrInvalidRetentionPolicy  = errors.New("invalid retention policy")
+	ErrRetentionPolicyExists   = errors.New("a retention policy already exists for this severity and category")
+)
+
+// Purge actions applied to archived reports once their purge period has passed
+const (
+	PurgeActionDelete    = "delete"
+	PurgeActionAnonymise = "anonymise"
+)
+
+// RetentionPolicyInput describes a retention policy to create or update.
+// A nil Severity or CategoryID widens the scope to every severity or category,
+// a nil ArchiveAfterDays means never archive and a nil PurgeAfterDays means never purge.
+type RetentionPolicyInput struct {
+	Severity         *int64
+	CategoryID       *int64
+	ArchiveAfterDays *int64
+	PurgeAfterDays   *int64
+	PurgeAction      string
+}
+
+// RetentionCandidate is a report that a retention run would archive or purge
+type RetentionCandidate struct {
+	ReportID   int64      `json:"report_id"`
+	Severity   int64      `json:"severity"`
+	CategoryID *int64     `json:"category_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	PolicyID   int64      `json:"policy_id"`
+	Action     string     `json:"action"` // archive, delete or anonymise
+}
+
+// RetentionPlan lists what a retention run would do at a given moment
+type RetentionPlan struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	ToArchive   []RetentionCandidate `json:"to_archive"`
+	ToPurge     []RetentionCandidate `json:"to_purge"`
+}
+
+// PurgeResult summarises a purge run. In a dry run nothing is changed and the counts are what would happen.
+type PurgeResult struct {
+	DryRun            bool                 `json:"dry_run"`
+	Deleted           int                  `json:"deleted"`
+	Anonymised        int                  `json:"anonymised"`
+	PhotoFilesRemoved int                  `json:"photo_files_removed"`
+	Reports           []RetentionCandidate `json:"reports"`
+}
+
+// ReportArchivingService handles automatic archiving and purging of reports based on retention policies
 type ReportArchivingService struct {
-	querier db.Querier
-	logger  *slog.Logger
+	querier      db.Querier
+	auditService *AuditService
+	logger       *slog.Logger
 }
 
 // NewReportArchivingService creates a new ReportArchivingService
-func NewReportArchivingService(querier db.Querier, logger *slog.Logger) *ReportArchivingService {
+func NewReportArchivingService(querier db.Querier, auditService *AuditService, logger *slog.Logger) *ReportArchivingService {
 	return &ReportArchivingService{
-		querier: querier,
-		logger:  logger.With("service", "ReportArchivingService"),
+		querier:      querier,
+		auditService: auditService,
+		logger:       logger.With("service", "ReportArchivingService"),
+	}
+}
+
+// ListPolicies returns all retention policies, severity-specific ones first
+func (s *ReportArchivingService) ListPolicies(ctx context.Context) ([]db.ReportRetentionPolicy, error) {
+	policies, err := s.querier.ListReportRetentionPolicies(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list retention policies", "error", err)
+		return nil, ErrInternalServer
+	}
+	return policies, nil
+}
+
+// CreatePolicy validates and stores a new retention policy
+func (s *ReportArchivingService) CreatePolicy(ctx context.Context, input RetentionPolicyInput) (db.ReportRetentionPolicy, error) {
+	if err := s.validatePolicy(ctx, &input); err != nil {
+		return db.ReportRetentionPolicy{}, err
+	}
+
+	policy, err := s.querier.CreateReportRetentionPolicy(ctx, db.CreateReportRetentionPolicyParams{
+		Severity:         nullInt64FromPtr(input.Severity),
+		CategoryID:       nullInt64FromPtr(input.CategoryID),
+		ArchiveAfterDays: nullInt64FromPtr(input.ArchiveAfterDays),
+		PurgeAfterDays:   nullInt64FromPtr(input.PurgeAfterDays),
+		PurgeAction:      input.PurgeAction,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return db.ReportRetentionPolicy{}, ErrRetentionPolicyExists
+		}
+		s.logger.ErrorContext(ctx, "Failed to create retention policy", "error", err)
+		return db.ReportRetentionPolicy{}, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Retention policy created", "policy_id", policy.PolicyID)
+	return policy, nil
+}
+
+// UpdatePolicy changes the retention periods of an existing policy. The scope of a policy cannot change.
+func (s *ReportArchivingService) UpdatePolicy(ctx context.Context, policyID int64, input RetentionPolicyInput) (db.ReportRetentionPolicy, error) {
+	_, err := s.querier.GetReportRetentionPolicy(ctx, policyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.ReportRetentionPolicy{}, ErrRetentionPolicyNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get retention policy", "policy_id", policyID, "error", err)
+		return db.ReportRetentionPolicy{}, ErrInternalServer
+	}
+
+	// The scope was validated on creation and stays as it is
+	input.Severity, input.CategoryID = nil, nil
+	if err := s.validatePolicy(ctx, &input); err != nil {
+		return db.ReportRetentionPolicy{}, err
+	}
+
+	policy, err := s.querier.UpdateReportRetentionPolicy(ctx, db.UpdateReportRetentionPolicyParams{
+		ArchiveAfterDays: nullInt64FromPtr(input.ArchiveAfterDays),
+		PurgeAfterDays:   nullInt64FromPtr(input.PurgeAfterDays),
+		PurgeAction:      input.PurgeAction,
+		PolicyID:         policyID,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update retention policy", "policy_id", policyID, "error", err)
+		return db.ReportRetentionPolicy{}, ErrInternalServer
+	}
+	return policy, nil
+}
+
+// DeletePolicy removes a retention policy so reports fall back to a broader one
+func (s *ReportArchivingService) DeletePolicy(ctx context.Context, policyID int64) (db.ReportRetentionPolicy, error) {
+	existing, err := s.querier.GetReportRetentionPolicy(ctx, policyID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.ReportRetentionPolicy{}, ErrRetentionPolicyNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get retention policy", "policy_id", policyID, "error", err)
+		return db.ReportRetentionPolicy{}, ErrInternalServer
+	}
+
+	if err := s.querier.DeleteReportRetentionPolicy(ctx, policyID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete retention policy", "policy_id", policyID, "error", err)
+		return db.ReportRetentionPolicy{}, ErrInternalServer
+	}
+	return existing, nil
+}
+
+func (s *ReportArchivingService) validatePolicy(ctx context.Context, input *RetentionPolicyInput) error {
+	if input.Severity != nil && (*input.Severity < 0 || *input.Severity > 2) {
+		return fmt.Errorf("%w: severity must be 0, 1 or 2", ErrInvalidRetentionPolicy)
+	}
+	if input.ArchiveAfterDays != nil && *input.ArchiveAfterDays < 0 {
+		return fmt.Errorf("%w: archive_after_days cannot be negative", ErrInvalidRetentionPolicy)
+	}
+	if input.PurgeAfterDays != nil && *input.PurgeAfterDays < 0 {
+		return fmt.Errorf("%w: purge_after_days cannot be negative", ErrInvalidRetentionPolicy)
+	}
+
+	if input.PurgeAction == "" {
+		input.PurgeAction = PurgeActionDelete
+	}
+	if input.PurgeAction != PurgeActionDelete && input.PurgeAction != PurgeActionAnonymise {
+		return fmt.Errorf("%w: purge_action must be %q or %q", ErrInvalidRetentionPolicy, PurgeActionDelete, PurgeActionAnonymise)
+	}
+
+	if input.CategoryID != nil {
+		if _, err := s.querier.GetIncidentCategoryByID(ctx, *input.CategoryID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: %v", ErrInvalidRetentionPolicy, ErrIncidentCategoryNotFound)
+			}
+			s.logger.ErrorContext(ctx, "Failed to look up category for retention policy", "category_id", *input.CategoryID, "error", err)
+			return ErrInternalServer
+		}
+	}
+	return nil
+}
+
+// policyFor returns the most specific policy for a report: severity and category,
+// then category only, then severity only, then the fallback policy.
+func policyFor(policies []db.ReportRetentionPolicy, severity int64, categoryID sql.NullInt64) *db.ReportRetentionPolicy {
+	var best *db.ReportRetentionPolicy
+	bestScore := -1
+	for i := range policies {
+		policy := &policies[i]
+		if policy.Severity.Valid && policy.Severity.Int64 != severity {
+			continue
+		}
+		if policy.CategoryID.Valid && (!categoryID.Valid || policy.CategoryID.Int64 != categoryID.Int64) {
+			continue
+		}
+
+		score := 0
+		if policy.CategoryID.Valid {
+			score += 2
+		}
+		if policy.Severity.Valid {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = policy, score
+		}
 	}
+	return best
 }
 
-// ArchiveOldReports automatically archives reports based on retention policies:
-// - Normal reports (severity 0): archived after 1 month
-// - Suspicion reports (severity 1): archived after 1 year
-// - Incident reports (severity 2): never auto-archived
+// PlanRetention works out which reports are due for archiving or purging without changing anything
+func (s *ReportArchivingService) PlanRetention(ctx context.Context, now time.Time) (*RetentionPlan, error) {
+	policies, err := s.querier.ListReportRetentionPolicies(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to load retention policies", "error", err)
+		return nil, err
+	}
+
+	reports, err := s.querier.ListReportsForRetention(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to load reports for retention", "error", err)
+		return nil, err
+	}
+
+	plan := &RetentionPlan{
+		GeneratedAt: now,
+		ToArchive:   []RetentionCandidate{},
+		ToPurge:     []RetentionCandidate{},
+	}
+	for _, report := range reports {
+		policy := policyFor(policies, report.Severity, report.CategoryID)
+		if policy == nil {
+			continue
+		}
+
+		candidate := RetentionCandidate{
+			ReportID:   report.ReportID,
+			Severity:   report.Severity,
+			CategoryID: ptrFromNullInt64(report.CategoryID),
+			CreatedAt:  report.CreatedAt.Time,
+			PolicyID:   policy.PolicyID,
+		}
+
+		if !report.ArchivedAt.Valid {
+			if policy.ArchiveAfterDays.Valid && report.CreatedAt.Valid &&
+				!report.CreatedAt.Time.After(now.AddDate(0, 0, -int(policy.ArchiveAfterDays.Int64))) {
+				candidate.Action = "archive"
+				plan.ToArchive = append(plan.ToArchive, candidate)
+			}
+			continue
+		}
+
+		if policy.PurgeAfterDays.Valid &&
+			!report.ArchivedAt.Time.After(now.AddDate(0, 0, -int(policy.PurgeAfterDays.Int64))) {
+			archivedAt := report.ArchivedAt.Time
+			candidate.ArchivedAt = &archivedAt
+			candidate.Action = policy.PurgeAction
+			plan.ToPurge = append(plan.ToPurge, candidate)
+		}
+	}
+
+	return plan, nil
+}
+
+// ArchiveOldReports archives every report whose retention policy archive period has passed
 func (s *ReportArchivingService) ArchiveOldReports(ctx context.Context) (int, error) {
 	s.logger.InfoContext(ctx, "Starting automatic report archiving process")
 
-	// Get reports that should be archived
-	reportsToArchive, err := s.querier.GetReportsForAutoArchiving(ctx)
+	plan, err := s.PlanRetention(ctx, time.Now().UTC())
 	if err != nil {
-		s.logger.ErrorContext(ctx, "Failed to get reports for auto-archiving", "error", err)
 		return 0, err
 	}
 
-	if len(reportsToArchive) == 0 {
+	if len(plan.ToArchive) == 0 {
 		s.logger.InfoContext(ctx, "No reports found for auto-archiving")
 		return 0, nil
 	}
 
 	// Extract report IDs
-	reportIDs := make([]int64, len(reportsToArchive))
-	for i, report := range reportsToArchive {
+	reportIDs := make([]int64, len(plan.ToArchive))
+	for i, report := range plan.ToArchive {
 		reportIDs[i] = report.ReportID
 	}
 
@@ -56,7 +303,7 @@ func (s *ReportArchivingService) ArchiveOldReports(ctx context.Context) (int, er
 
 	// Log details about what was archived
 	severityCounts := make(map[int64]int)
-	for _, report := range reportsToArchive {
+	for _, report := range plan.ToArchive {
 		severityCounts[report.Severity]++
 	}
 
@@ -69,31 +316,128 @@ func (s *ReportArchivingService) ArchiveOldReports(ctx context.Context) (int, er
 	return len(reportIDs), nil
 }
 
-// GetArchivingStats returns statistics about archivable reports
+// PurgeArchivedReports permanently deletes or anonymises archived reports whose purge period has passed,
+// removing their photo files. With dryRun set it only reports what would happen.
+// actorUserID is nil when the purge is run by the scheduler.
+func (s *ReportArchivingService) PurgeArchivedReports(ctx context.Context, dryRun bool, actorUserID *int64, ipAddress, userAgent string) (*PurgeResult, error) {
+	plan, err := s.PlanRetention(ctx, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PurgeResult{
+		DryRun:  dryRun,
+		Reports: []RetentionCandidate{},
+	}
+	var deletedIDs, anonymisedIDs []int64
+
+	for _, candidate := range plan.ToPurge {
+		photos, err := s.querier.GetReportPhotos(ctx, candidate.ReportID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to load photos for purge", "report_id", candidate.ReportID, "error", err)
+			continue
+		}
+
+		if dryRun {
+			result.PhotoFilesRemoved += countPhotoFiles(photos)
+		} else {
+			result.PhotoFilesRemoved += s.removePhotoFiles(ctx, photos)
+			if err := s.querier.DeleteReportPhotosByReportID(ctx, candidate.ReportID); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to delete photo records for purge", "report_id", candidate.ReportID, "error", err)
+				continue
+			}
+
+			if candidate.Action == PurgeActionAnonymise {
+				err = s.querier.AnonymiseReport(ctx, candidate.ReportID)
+			} else {
+				err = s.querier.DeleteReport(ctx, candidate.ReportID)
+			}
+			if err != nil {
+				s.logger.ErrorContext(ctx, "Failed to purge report", "report_id", candidate.ReportID, "action", candidate.Action, "error", err)
+				continue
+			}
+		}
+
+		if candidate.Action == PurgeActionAnonymise {
+			result.Anonymised++
+			anonymisedIDs = append(anonymisedIDs, candidate.ReportID)
+		} else {
+			result.Deleted++
+			deletedIDs = append(deletedIDs, candidate.ReportID)
+		}
+		result.Reports = append(result.Reports, candidate)
+	}
+
+	if !dryRun && len(result.Reports) > 0 {
+		if err := s.auditService.LogReportsPurged(ctx, actorUserID, deletedIDs, anonymisedIDs, result.PhotoFilesRemoved, ipAddress, userAgent); err != nil {
+			s.logger.WarnContext(ctx, "Failed to log report purge audit event", "error", err)
+		}
+		s.logger.InfoContext(ctx, "Purged archived reports",
+			"deleted", result.Deleted,
+			"anonymised", result.Anonymised,
+			"photo_files_removed", result.PhotoFilesRemoved)
+	}
+
+	return result, nil
+}
+
+func countPhotoFiles(photos []db.ReportPhoto) int {
+	count := 0
+	for _, photo := range photos {
+		count++
+		if photo.ThumbnailPath.Valid && photo.ThumbnailPath.String != "" {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *ReportArchivingService) removePhotoFiles(ctx context.Context, photos []db.ReportPhoto) int {
+	removed := 0
+	for _, photo := range photos {
+		paths := []string{photo.StoragePath}
+		if photo.ThumbnailPath.Valid && photo.ThumbnailPath.String != "" {
+			paths = append(paths, photo.ThumbnailPath.String)
+		}
+		for _, path := range paths {
+			if err := os.Remove(path); err != nil {
+				if !os.IsNotExist(err) {
+					s.logger.WarnContext(ctx, "Failed to remove photo file", "path", path, "error", err)
+				}
+				continue
+			}
+			removed++
+		}
+	}
+	return removed
+}
+
+// GetArchivingStats returns statistics about archivable and purgeable reports
 func (s *ReportArchivingService) GetArchivingStats(ctx context.Context) (map[string]interface{}, error) {
-	reportsToArchive, err := s.querier.GetReportsForAutoArchiving(ctx)
+	plan, err := s.PlanRetention(ctx, time.Now().UTC())
 	if err != nil {
 		return nil, err
 	}
 
 	stats := map[string]interface{}{
-		"total_archivable": len(reportsToArchive),
+		"total_archivable": len(plan.ToArchive),
 		"by_severity": map[string]int{
 			"normal":    0,
 			"suspicion": 0,
 			"incident":  0,
 		},
 		"oldest_archivable": nil,
+		"total_purgeable":   len(plan.ToPurge),
 	}
 
-	if len(reportsToArchive) == 0 {
+	if len(plan.ToArchive) == 0 {
 		return stats, nil
 	}
 
 	var oldestTime *time.Time
 	severityMap := stats["by_severity"].(map[string]int)
 
-	for _, report := range reportsToArchive {
+	for _, report := range plan.ToArchive {
 		switch report.Severity {
 		case 0:
 			severityMap["normal"]++
@@ -103,10 +447,9 @@ func (s *ReportArchivingService) GetArchivingStats(ctx context.Context) (map[str
 			severityMap["incident"]++
 		}
 
-		if report.CreatedAt.Valid {
-			if oldestTime == nil || report.CreatedAt.Time.Before(*oldestTime) {
-				oldestTime = &report.CreatedAt.Time
-			}
+		if oldestTime == nil || report.CreatedAt.Before(*oldestTime) {
+			createdAt := report.CreatedAt
+			oldestTime = &createdAt
 		}
 	}
 
@@ -116,3 +459,18 @@ func (s *ReportArchivingService) GetArchivingStats(ctx context.Context) (map[str
 
 	return stats, nil
 }
+
+func nullInt64FromPtr(val *int64) sql.NullInt64 {
+	if val == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *val, Valid: true}
+}
+
+func ptrFromNullInt64(val sql.NullInt64) *int64 {
+	if !val.Valid {
+		return nil
+	}
+	v := val.Int64
+	return &v
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/utils"
+)
+
+var (
+	ErrNoReportsToMerge    = errors.New("at least one report to merge is required")
+	ErrMergeIntoSelf       = errors.New("a report cannot be merged into itself")
+	ErrReportAlreadyMerged = errors.New("report has already been merged into another report")
+)
+
+// Duplicate detection
+const (
+	DuplicateReportWindow      = 30 * time.Minute // Reports further apart than this are never duplicates
+	DuplicateReportMaxDistance = 500.0            // Metres; reports further apart than this are never duplicates
+	DuplicateScoreThreshold    = 0.6              // Minimum similarity score for a likely duplicate
+
+	// Weights when both reports have a GPS fix
+	duplicateDistanceWeight = 0.4
+	duplicateTimeWeight     = 0.3
+	duplicateTextWeight     = 0.3
+)
+
+// DuplicateMatch is the earlier report a new report most likely duplicates.
+type DuplicateMatch struct {
+	DuplicateOfReportID int64
+	Score               float64
+	DistanceMeters      *float64
+	Severity            int64 // Severity of the matched report
+}
+
+// ReportIncident is a report together with its likely and confirmed duplicates.
+type ReportIncident struct {
+	DuplicateOf         *db.ReportDuplicateMatch
+	MergedInto          *db.ReportMerge
+	SuspectedDuplicates []db.ListSuspectedDuplicatesOfReportRow
+	MergedReports       []db.ListMergedReportsRow
+}
+
+// MergeResult describes what a merge changed.
+type MergeResult struct {
+	PrimaryReportID int64   `json:"primary_report_id"`
+	MergedReportIDs []int64 `json:"merged_report_ids"`
+	PhotosMoved     int64   `json:"photos_moved"`
+	PointsRevoked   []int64 `json:"points_revoked_user_ids"` // Reporters whose serious report bonus was taken back
+}
+
+// ReportDuplicateService flags likely duplicate reports when they are filed and
+// lets admins merge duplicates into a primary incident.
+type ReportDuplicateService struct {
+	querier       db.Querier
+	pointsService *PointsService
+	logger        *slog.Logger
+}
+
+// NewReportDuplicateService creates a new ReportDuplicateService.
+func NewReportDuplicateService(querier db.Querier, pointsService *PointsService, logger *slog.Logger) *ReportDuplicateService {
+	return &ReportDuplicateService{
+		querier:       querier,
+		pointsService: pointsService,
+		logger:        logger.With("service", "ReportDuplicateService"),
+	}
+}
+
+// messageWords splits a report message into its distinct lower-case words.
+func messageWords(message string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		words[word] = true
+	}
+	return words
+}
+
+// textSimilarity is the share of words two messages have in common (Jaccard index).
+func textSimilarity(a, b string) float64 {
+	wordsA, wordsB := messageWords(a), messageWords(b)
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(wordsA)+len(wordsB)-shared)
+}
+
+// duplicateScore rates how likely a candidate duplicates a report, from 0 to 1.
+// Reports too far apart in time or space never match.
+func duplicateScore(report db.Report, candidate db.ListReportDuplicateCandidatesRow, reportTime time.Time) (float64, *float64, bool) {
+	if !candidate.CreatedAt.Valid {
+		return 0, nil, false
+	}
+	gap := reportTime.Sub(candidate.CreatedAt.Time)
+	if gap < 0 {
+		gap = -gap
+	}
+	if gap > DuplicateReportWindow {
+		return 0, nil, false
+	}
+	timeScore := 1 - float64(gap)/float64(DuplicateReportWindow)
+	textScore := textSimilarity(report.Message.String, candidate.Message.String)
+
+	if report.Latitude.Valid && report.Longitude.Valid && candidate.Latitude.Valid && candidate.Longitude.Valid {
+		distance := utils.DistanceMeters(report.Latitude.Float64, report.Longitude.Float64, candidate.Latitude.Float64, candidate.Longitude.Float64)
+		if distance > DuplicateReportMaxDistance {
+			return 0, nil, false
+		}
+		distanceScore := 1 - distance/DuplicateReportMaxDistance
+		score := duplicateDistanceWeight*distanceScore + duplicateTimeWeight*timeScore + duplicateTextWeight*textScore
+		return score, &distance, true
+	}
+
+	// Without two GPS fixes only timing and wording can be compared
+	return (timeScore + textScore) / 2, nil, true
+}
+
+// DetectDuplicate looks for an earlier report the new report likely duplicates
+// and records the best match. It returns nil when there is none. Matches point
+// at the first report of an incident rather than at another duplicate.
+func (s *ReportDuplicateService) DetectDuplicate(ctx context.Context, report db.Report) (*DuplicateMatch, error) {
+	reportTime := time.Now().UTC()
+	if report.CreatedAt.Valid {
+		reportTime = report.CreatedAt.Time
+	}
+
+	candidates, err := s.querier.ListReportDuplicateCandidates(ctx, db.ListReportDuplicateCandidatesParams{
+		ReportID: report.ReportID,
+		Since:    sql.NullTime{Time: reportTime.Add(-DuplicateReportWindow), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var best *DuplicateMatch
+	for _, candidate := range candidates {
+		score, distance, ok := duplicateScore(report, candidate, reportTime)
+		if !ok || score < DuplicateScoreThreshold || (best != nil && score <= best.Score) {
+			continue
+		}
+		best = &DuplicateMatch{
+			DuplicateOfReportID: candidate.ReportID,
+			Score:               score,
+			DistanceMeters:      distance,
+			Severity:            candidate.Severity,
+		}
+		if candidate.DuplicateOfReportID.Valid {
+			best.DuplicateOfReportID = candidate.DuplicateOfReportID.Int64
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	var distance sql.NullFloat64
+	if best.DistanceMeters != nil {
+		distance = sql.NullFloat64{Float64: *best.DistanceMeters, Valid: true}
+	}
+	err = s.querier.CreateReportDuplicateMatch(ctx, db.CreateReportDuplicateMatchParams{
+		ReportID:            report.ReportID,
+		DuplicateOfReportID: best.DuplicateOfReportID,
+		Score:               best.Score,
+		DistanceMeters:      distance,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Likely duplicate report detected",
+		"report_id", report.ReportID,
+		"duplicate_of_report_id", best.DuplicateOfReportID,
+		"score", best.Score,
+	)
+	return best, nil
+}
+
+// getUnmergedReport loads a report and fails if it has already been merged.
+func (s *ReportDuplicateService) getUnmergedReport(ctx context.Context, reportID int64) (db.AdminGetReportWithContextRow, error) {
+	report, err := s.querier.AdminGetReportWithContext(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.AdminGetReportWithContextRow{}, ErrReportNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get report for merge", "report_id", reportID, "error", err)
+		return db.AdminGetReportWithContextRow{}, ErrInternalServer
+	}
+
+	_, err = s.querier.GetReportMerge(ctx, reportID)
+	if err == nil {
+		return db.AdminGetReportWithContextRow{}, ErrReportAlreadyMerged
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		s.logger.ErrorContext(ctx, "Failed to check whether report was merged", "report_id", reportID, "error", err)
+		return db.AdminGetReportWithContextRow{}, ErrInternalServer
+	}
+	return report, nil
+}
+
+// MergeReports merges duplicate reports into a primary incident. Merged reports
+// are archived and their photos moved to the primary; their messages and
+// reporters stay listed on the primary. Reporters who earned the serious report
+// bonus for a merged duplicate have it taken back, so one incident earns it once.
+func (s *ReportDuplicateService) MergeReports(ctx context.Context, primaryReportID int64, reportIDs []int64, mergedByUserID int64) (MergeResult, error) {
+	seen := make(map[int64]bool)
+	var ids []int64
+	for _, id := range reportIDs {
+		if id == primaryReportID {
+			return MergeResult{}, ErrMergeIntoSelf
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return MergeResult{}, ErrNoReportsToMerge
+	}
+
+	if _, err := s.getUnmergedReport(ctx, primaryReportID); err != nil {
+		return MergeResult{}, err
+	}
+	duplicates := make([]db.AdminGetReportWithContextRow, 0, len(ids))
+	for _, id := range ids {
+		report, err := s.getUnmergedReport(ctx, id)
+		if err != nil {
+			return MergeResult{}, err
+		}
+		duplicates = append(duplicates, report)
+	}
+
+	result := MergeResult{PrimaryReportID: primaryReportID, MergedReportIDs: []int64{}, PointsRevoked: []int64{}}
+	for _, duplicate := range duplicates {
+		err := s.querier.CreateReportMerge(ctx, db.CreateReportMergeParams{
+			ReportID:        duplicate.ReportID,
+			PrimaryReportID: primaryReportID,
+			MergedByUserID:  sql.NullInt64{Int64: mergedByUserID, Valid: true},
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to merge report", "report_id", duplicate.ReportID, "primary_report_id", primaryReportID, "error", err)
+			return result, ErrInternalServer
+		}
+		result.MergedReportIDs = append(result.MergedReportIDs, duplicate.ReportID)
+
+		// Reports already merged into this duplicate now belong to the primary
+		if err := s.querier.ReassignReportMerges(ctx, db.ReassignReportMergesParams{
+			PrimaryReportID:         primaryReportID,
+			PreviousPrimaryReportID: duplicate.ReportID,
+		}); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to reassign merged reports", "report_id", duplicate.ReportID, "error", err)
+		}
+
+		moved, err := s.querier.MoveReportPhotos(ctx, db.MoveReportPhotosParams{
+			ToReportID:   primaryReportID,
+			FromReportID: duplicate.ReportID,
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to move photos to primary report", "report_id", duplicate.ReportID, "error", err)
+		} else if moved > 0 {
+			result.PhotosMoved += moved
+			for _, id := range []int64{primaryReportID, duplicate.ReportID} {
+				if err := s.querier.UpdateReportPhotoCount(ctx, id); err != nil {
+					s.logger.WarnContext(ctx, "Failed to update report photo count", "report_id", id, "error", err)
+				}
+			}
+		}
+
+		if !duplicate.ArchivedAt.Valid {
+			if err := s.querier.ArchiveReport(ctx, duplicate.ReportID); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to archive merged report", "report_id", duplicate.ReportID, "error", err)
+			}
+		}
+
+		if s.pointsService != nil && duplicate.Severity >= 2 && duplicate.UserID.Valid && duplicate.BookingID.Valid {
+			revoked, err := s.pointsService.RevokeDuplicateReportPoints(ctx, duplicate.UserID.Int64, duplicate.BookingID.Int64)
+			if err != nil {
+				s.logger.WarnContext(ctx, "Failed to revoke serious report bonus for merged report", "report_id", duplicate.ReportID, "user_id", duplicate.UserID.Int64, "error", err)
+			} else if revoked {
+				result.PointsRevoked = append(result.PointsRevoked, duplicate.UserID.Int64)
+			}
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Reports merged",
+		"primary_report_id", primaryReportID,
+		"merged_report_ids", result.MergedReportIDs,
+		"photos_moved", result.PhotosMoved,
+		"merged_by", mergedByUserID,
+	)
+	return result, nil
+}
+
+// GetIncident returns a report's duplicate status, the reports detected as likely
+// duplicates of it and the reports merged into it.
+func (s *ReportDuplicateService) GetIncident(ctx context.Context, reportID int64) (ReportIncident, error) {
+	if _, err := s.querier.AdminGetReportWithContext(ctx, reportID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ReportIncident{}, ErrReportNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get report", "report_id", reportID, "error", err)
+		return ReportIncident{}, ErrInternalServer
+	}
+
+	var incident ReportIncident
+	match, err := s.querier.GetReportDuplicateMatch(ctx, reportID)
+	switch {
+	case err == nil:
+		incident.DuplicateOf = &match
+	case !errors.Is(err, sql.ErrNoRows):
+		s.logger.ErrorContext(ctx, "Failed to get duplicate match", "report_id", reportID, "error", err)
+		return ReportIncident{}, ErrInternalServer
+	}
+
+	merge, err := s.querier.GetReportMerge(ctx, reportID)
+	switch {
+	case err == nil:
+		incident.MergedInto = &merge
+	case !errors.Is(err, sql.ErrNoRows):
+		s.logger.ErrorContext(ctx, "Failed to get report merge", "report_id", reportID, "error", err)
+		return ReportIncident{}, ErrInternalServer
+	}
+
+	incident.SuspectedDuplicates, err = s.querier.ListSuspectedDuplicatesOfReport(ctx, reportID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list suspected duplicates", "report_id", reportID, "error", err)
+		return ReportIncident{}, ErrInternalServer
+	}
+
+	incident.MergedReports, err = s.querier.ListMergedReports(ctx, reportID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list merged reports", "report_id", reportID, "error", err)
+		return ReportIncident{}, ErrInternalServer
+	}
+
+	return incident, nil
+}
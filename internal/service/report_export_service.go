@@ -0,0 +1,219 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/utils"
+)
+
+// ErrInvalidExportFormat is returned when an unsupported export format is requested.
+var ErrInvalidExportFormat = errors.New("unsupported export format")
+
+// Supported report export formats
+const (
+	ReportExportFormatGeoJSON = "geojson"
+	ReportExportFormatKML     = "kml"
+)
+
+// severityLabels maps report severity to a human readable label
+var severityLabels = map[int64]string{
+	0: "Normal",
+	1: "Suspicion",
+	2: "Incident",
+}
+
+// SeverityLabel returns the human readable label for a report severity.
+func SeverityLabel(severity int64) string {
+	if label, ok := severityLabels[severity]; ok {
+		return label
+	}
+	return fmt.Sprintf("Severity %d", severity)
+}
+
+// ReportExportService renders filtered reports into geospatial export formats.
+// Reporter names are always masked in exports.
+type ReportExportService struct {
+	geoService   *ReportGeoService
+	auditService *AuditService
+	logger       *slog.Logger
+}
+
+// NewReportExportService creates a new ReportExportService.
+func NewReportExportService(geoService *ReportGeoService, auditService *AuditService, logger *slog.Logger) *ReportExportService {
+	return &ReportExportService{
+		geoService:   geoService,
+		auditService: auditService,
+		logger:       logger.With("service", "ReportExportService"),
+	}
+}
+
+// reportShiftTime converts the string shift times returned by the admin report queries.
+func reportShiftTime(value interface{}) *time.Time {
+	s, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02 15:04:05", s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// exportProperties returns the exported properties of a report.
+func exportProperties(report db.AdminListReportsWithContextRow) map[string]interface{} {
+	properties := map[string]interface{}{
+		"report_id":      report.ReportID,
+		"severity":       report.Severity,
+		"severity_label": SeverityLabel(report.Severity),
+		"schedule_id":    report.ScheduleID,
+		"schedule_name":  report.ScheduleName,
+		"reporter":       utils.MaskName(report.UserName),
+	}
+	if report.CreatedAt.Valid {
+		properties["created_at"] = report.CreatedAt.Time.UTC().Format(time.RFC3339)
+	}
+	if start := reportShiftTime(report.ShiftStart); start != nil {
+		properties["shift_start"] = start.Format(time.RFC3339)
+	}
+	if end := reportShiftTime(report.ShiftEnd); end != nil {
+		properties["shift_end"] = end.Format(time.RFC3339)
+	}
+	if report.GpsAccuracy.Valid {
+		properties["gps_accuracy"] = report.GpsAccuracy.Float64
+	}
+	if report.CategoryID.Valid {
+		properties["category_id"] = report.CategoryID.Int64
+		properties["category_name"] = report.CategoryName
+	}
+	return properties
+}
+
+// BuildGeoJSON returns a FeatureCollection with one Point feature per report.
+// Reports without a location are included with a null geometry.
+func (s *ReportExportService) BuildGeoJSON(reports []db.AdminListReportsWithContextRow) *GeoJSONFeatureCollection {
+	collection := NewGeoJSONFeatureCollection()
+	for _, report := range reports {
+		feature := GeoJSONFeature{
+			Type:       "Feature",
+			Properties: exportProperties(report),
+		}
+		if report.Latitude.Valid && report.Longitude.Valid {
+			feature.Geometry = &GeoJSONGeometry{
+				Type:        "Point",
+				Coordinates: []float64{report.Longitude.Float64, report.Latitude.Float64},
+			}
+		}
+		collection.Features = append(collection.Features, feature)
+	}
+	return collection
+}
+
+// kmlEscape returns s escaped for use as XML character data.
+func kmlEscape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// WriteKML writes the reports as a KML document. KML placemarks require a
+// location, so reports without GPS coordinates are skipped. It returns the
+// number of placemarks written.
+func (s *ReportExportService) WriteKML(w io.Writer, reports []db.AdminListReportsWithContextRow) (int, error) {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprint(bw, xml.Header)
+	fmt.Fprint(bw, `<kml xmlns="http://www.opengis.net/kml/2.2">`+"\n<Document>\n<name>Night Owls reports</name>\n")
+
+	// One style per severity so map viewers colour placemarks consistently
+	styleColours := map[int64]string{0: "ff00ff00", 1: "ff00a5ff", 2: "ff0000ff"}
+	for severity := int64(0); severity <= 2; severity++ {
+		fmt.Fprintf(bw, "<Style id=\"severity-%d\"><IconStyle><color>%s</color></IconStyle></Style>\n", severity, styleColours[severity])
+	}
+
+	written := 0
+	for _, report := range reports {
+		if !report.Latitude.Valid || !report.Longitude.Valid {
+			continue
+		}
+		props := exportProperties(report)
+
+		fmt.Fprint(bw, "<Placemark>\n")
+		fmt.Fprintf(bw, "<name>%s #%d</name>\n", kmlEscape(SeverityLabel(report.Severity)), report.ReportID)
+		if createdAt, ok := props["created_at"].(string); ok {
+			fmt.Fprintf(bw, "<TimeStamp><when>%s</when></TimeStamp>\n", createdAt)
+		}
+		fmt.Fprintf(bw, "<styleUrl>#severity-%d</styleUrl>\n", report.Severity)
+		fmt.Fprint(bw, "<ExtendedData>\n")
+		for _, key := range []string{"report_id", "severity", "severity_label", "created_at", "schedule_id", "schedule_name", "category_name", "shift_start", "shift_end", "reporter", "gps_accuracy"} {
+			value, ok := props[key]
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(bw, "<Data name=\"%s\"><value>%s</value></Data>\n", key, kmlEscape(fmt.Sprint(value)))
+		}
+		fmt.Fprint(bw, "</ExtendedData>\n")
+		fmt.Fprintf(bw, "<Point><coordinates>%f,%f</coordinates></Point>\n", report.Longitude.Float64, report.Latitude.Float64)
+		fmt.Fprint(bw, "</Placemark>\n")
+		written++
+	}
+
+	fmt.Fprint(bw, "</Document>\n</kml>\n")
+	return written, bw.Flush()
+}
+
+// ExportReports loads the reports matching the filter, renders them in the
+// requested format and records the export in the audit trail. The export is
+// rendered in full before the audit event is written, and is only returned
+// once it has been logged, so no export leaves the system unlogged and a
+// failure never leaves the caller with a partial file.
+func (s *ReportExportService) ExportReports(ctx context.Context, format string, filter ReportFilter, actorUserID int64, ipAddress, userAgent string) ([]byte, int, error) {
+	if format != ReportExportFormatGeoJSON && format != ReportExportFormatKML {
+		return nil, 0, ErrInvalidExportFormat
+	}
+
+	reports, err := s.geoService.ListFilteredReports(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case ReportExportFormatGeoJSON:
+		err = json.NewEncoder(&buf).Encode(s.BuildGeoJSON(reports))
+	case ReportExportFormatKML:
+		_, err = s.WriteKML(&buf, reports)
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to render report export", "format", format, "error", err)
+		return nil, 0, ErrInternalServer
+	}
+
+	exported := len(reports)
+	if format == ReportExportFormatKML {
+		exported = 0
+		for _, report := range reports {
+			if report.Latitude.Valid && report.Longitude.Valid {
+				exported++
+			}
+		}
+	}
+
+	if err := s.auditService.LogReportsExported(ctx, actorUserID, format, filter.AuditDetails(), exported, ipAddress, userAgent); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to log report export audit event, refusing export", "error", err)
+		return nil, 0, ErrInternalServer
+	}
+
+	return buf.Bytes(), exported, nil
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/utils"
+)
+
+var (
+	// ErrInvalidHeatmapBinning is returned when an unknown binning method is requested.
+	ErrInvalidHeatmapBinning = errors.New("binning must be 'grid' or 'geohash'")
+	// ErrInvalidHeatmapResolution is returned when the cell size or geohash precision is out of range.
+	ErrInvalidHeatmapResolution = errors.New("heatmap resolution out of range")
+)
+
+const (
+	// HeatmapBinningGrid bins reports into square cells of a fixed size in metres.
+	HeatmapBinningGrid = "grid"
+	// HeatmapBinningGeohash bins reports by geohash prefix.
+	HeatmapBinningGeohash = "geohash"
+
+	DefaultHeatmapCellSizeMeters = 250.0
+	MinHeatmapCellSizeMeters     = 10.0
+	MaxHeatmapCellSizeMeters     = 50000.0
+	DefaultHeatmapGeohashLength  = 7
+	MinHeatmapGeohashLength      = 1
+	MaxHeatmapGeohashLength      = 9
+
+	metersPerDegreeLat = 111320.0
+)
+
+// ReportFilter holds the optional filters shared by the admin report views.
+// Nil fields are not applied.
+type ReportFilter struct {
+	From       *time.Time
+	To         *time.Time
+	Severity   *int64
+	ScheduleID *int64
+	UserID     *int64
+	CategoryID *int64
+}
+
+// Matches reports whether a report row satisfies the filter.
+func (f ReportFilter) Matches(report db.AdminListReportsWithContextRow) bool {
+	if f.From != nil || f.To != nil {
+		if !report.CreatedAt.Valid {
+			return false
+		}
+		if f.From != nil && report.CreatedAt.Time.Before(*f.From) {
+			return false
+		}
+		if f.To != nil && !report.CreatedAt.Time.Before(*f.To) {
+			return false
+		}
+	}
+	if f.Severity != nil && report.Severity != *f.Severity {
+		return false
+	}
+	if f.ScheduleID != nil && report.ScheduleID != *f.ScheduleID {
+		return false
+	}
+	if f.UserID != nil && (!report.UserID.Valid || report.UserID.Int64 != *f.UserID) {
+		return false
+	}
+	if f.CategoryID != nil && (!report.CategoryID.Valid || report.CategoryID.Int64 != *f.CategoryID) {
+		return false
+	}
+	return true
+}
+
+// AuditDetails returns the applied filters in a form suitable for audit event details.
+func (f ReportFilter) AuditDetails() map[string]interface{} {
+	details := map[string]interface{}{}
+	if f.From != nil {
+		details["from"] = f.From.Format(time.RFC3339)
+	}
+	if f.To != nil {
+		details["to"] = f.To.Format(time.RFC3339)
+	}
+	if f.Severity != nil {
+		details["severity"] = *f.Severity
+	}
+	if f.ScheduleID != nil {
+		details["schedule_id"] = *f.ScheduleID
+	}
+	if f.UserID != nil {
+		details["user_id"] = *f.UserID
+	}
+	if f.CategoryID != nil {
+		details["category_id"] = *f.CategoryID
+	}
+	return details
+}
+
+// HeatmapOptions controls how reports are binned.
+type HeatmapOptions struct {
+	Binning        string
+	CellSizeMeters float64
+	GeohashLength  int
+}
+
+// GeoJSONFeatureCollection is a minimal GeoJSON FeatureCollection.
+type GeoJSONFeatureCollection struct {
+	Type     string                 `json:"type"`
+	Features []GeoJSONFeature       `json:"features"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// GeoJSONFeature is a minimal GeoJSON Feature.
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	BBox       []float64              `json:"bbox,omitempty"`
+	Geometry   *GeoJSONGeometry       `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONGeometry is a GeoJSON geometry. Coordinates are in [longitude, latitude] order.
+// A nil geometry is encoded as null, which GeoJSON allows for unlocated features.
+type GeoJSONGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// NewGeoJSONFeatureCollection creates an empty FeatureCollection.
+func NewGeoJSONFeatureCollection() *GeoJSONFeatureCollection {
+	return &GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: []GeoJSONFeature{},
+	}
+}
+
+// heatmapBin accumulates the reports that fall into one cell.
+type heatmapBin struct {
+	key            string
+	minLat, maxLat float64
+	minLon, maxLon float64
+	reportIDs      []int64
+	severityCounts map[int64]int
+	maxSeverity    int64
+	latestAt       time.Time
+}
+
+// ReportGeoService aggregates report locations for map views.
+type ReportGeoService struct {
+	querier db.Querier
+	logger  *slog.Logger
+}
+
+// NewReportGeoService creates a new ReportGeoService.
+func NewReportGeoService(querier db.Querier, logger *slog.Logger) *ReportGeoService {
+	return &ReportGeoService{
+		querier: querier,
+		logger:  logger.With("service", "ReportGeoService"),
+	}
+}
+
+// ListFilteredReports returns the active (non-archived) reports matching the filter.
+func (s *ReportGeoService) ListFilteredReports(ctx context.Context, filter ReportFilter) ([]db.AdminListReportsWithContextRow, error) {
+	reports, err := s.querier.AdminListReportsWithContext(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list reports", "error", err)
+		return nil, ErrInternalServer
+	}
+
+	filtered := make([]db.AdminListReportsWithContextRow, 0, len(reports))
+	for _, report := range reports {
+		if filter.Matches(report) {
+			filtered = append(filtered, report)
+		}
+	}
+	return filtered, nil
+}
+
+// BuildHeatmap bins the located reports matching the filter and returns one
+// GeoJSON Point feature per non-empty cell, with counts and the IDs of the
+// reports in the cell for drill-down.
+func (s *ReportGeoService) BuildHeatmap(ctx context.Context, filter ReportFilter, opts HeatmapOptions) (*GeoJSONFeatureCollection, error) {
+	switch opts.Binning {
+	case "":
+		opts.Binning = HeatmapBinningGrid
+	case HeatmapBinningGrid, HeatmapBinningGeohash:
+	default:
+		return nil, ErrInvalidHeatmapBinning
+	}
+	if opts.CellSizeMeters == 0 {
+		opts.CellSizeMeters = DefaultHeatmapCellSizeMeters
+	}
+	if opts.GeohashLength == 0 {
+		opts.GeohashLength = DefaultHeatmapGeohashLength
+	}
+	if opts.CellSizeMeters < MinHeatmapCellSizeMeters || opts.CellSizeMeters > MaxHeatmapCellSizeMeters ||
+		opts.GeohashLength < MinHeatmapGeohashLength || opts.GeohashLength > MaxHeatmapGeohashLength {
+		return nil, ErrInvalidHeatmapResolution
+	}
+
+	reports, err := s.ListFilteredReports(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	bins := make(map[string]*heatmapBin)
+	located, unlocated := 0, 0
+	for _, report := range reports {
+		if !report.Latitude.Valid || !report.Longitude.Valid {
+			unlocated++
+			continue
+		}
+		located++
+
+		lat, lon := report.Latitude.Float64, report.Longitude.Float64
+		var key string
+		var minLat, maxLat, minLon, maxLon float64
+		if opts.Binning == HeatmapBinningGeohash {
+			key = utils.EncodeGeohash(lat, lon, opts.GeohashLength)
+			b := utils.DecodeGeohashBounds(key)
+			minLat, maxLat, minLon, maxLon = b.MinLat, b.MaxLat, b.MinLon, b.MaxLon
+		} else {
+			key, minLat, maxLat, minLon, maxLon = gridCell(lat, lon, opts.CellSizeMeters)
+		}
+
+		bin, ok := bins[key]
+		if !ok {
+			bin = &heatmapBin{
+				key:            key,
+				minLat:         minLat,
+				maxLat:         maxLat,
+				minLon:         minLon,
+				maxLon:         maxLon,
+				severityCounts: map[int64]int{0: 0, 1: 0, 2: 0},
+				maxSeverity:    report.Severity,
+			}
+			bins[key] = bin
+		}
+		bin.reportIDs = append(bin.reportIDs, report.ReportID)
+		bin.severityCounts[report.Severity]++
+		if report.Severity > bin.maxSeverity {
+			bin.maxSeverity = report.Severity
+		}
+		if report.CreatedAt.Valid && report.CreatedAt.Time.After(bin.latestAt) {
+			bin.latestAt = report.CreatedAt.Time
+		}
+	}
+
+	ordered := make([]*heatmapBin, 0, len(bins))
+	for _, bin := range bins {
+		ordered = append(ordered, bin)
+	}
+	// Busiest cells first; ties broken by key for stable output
+	sort.Slice(ordered, func(i, j int) bool {
+		if len(ordered[i].reportIDs) != len(ordered[j].reportIDs) {
+			return len(ordered[i].reportIDs) > len(ordered[j].reportIDs)
+		}
+		return ordered[i].key < ordered[j].key
+	})
+
+	collection := NewGeoJSONFeatureCollection()
+	maxCount := 0
+	for _, bin := range ordered {
+		if len(bin.reportIDs) > maxCount {
+			maxCount = len(bin.reportIDs)
+		}
+		sort.Slice(bin.reportIDs, func(i, j int) bool { return bin.reportIDs[i] < bin.reportIDs[j] })
+
+		properties := map[string]interface{}{
+			"cell":            bin.key,
+			"count":           len(bin.reportIDs),
+			"report_ids":      bin.reportIDs,
+			"severity_counts": map[string]int{"0": bin.severityCounts[0], "1": bin.severityCounts[1], "2": bin.severityCounts[2]},
+			"max_severity":    bin.maxSeverity,
+		}
+		if !bin.latestAt.IsZero() {
+			properties["latest_report_at"] = bin.latestAt
+		}
+
+		collection.Features = append(collection.Features, GeoJSONFeature{
+			Type: "Feature",
+			BBox: []float64{bin.minLon, bin.minLat, bin.maxLon, bin.maxLat},
+			Geometry: &GeoJSONGeometry{
+				Type:        "Point",
+				Coordinates: []float64{(bin.minLon + bin.maxLon) / 2, (bin.minLat + bin.maxLat) / 2},
+			},
+			Properties: properties,
+		})
+	}
+
+	collection.Metadata = map[string]interface{}{
+		"binning":           opts.Binning,
+		"total_reports":     len(reports),
+		"located_reports":   located,
+		"unlocated_reports": unlocated,
+		"cell_count":        len(collection.Features),
+		"max_count":         maxCount,
+	}
+	if opts.Binning == HeatmapBinningGeohash {
+		collection.Metadata["geohash_length"] = opts.GeohashLength
+	} else {
+		collection.Metadata["cell_size_meters"] = opts.CellSizeMeters
+	}
+
+	return collection, nil
+}
+
+// gridCell returns the key and bounds of the grid cell containing a point.
+// Rows are a fixed latitude step; each row's longitude step is widened by the
+// cosine of the row's centre latitude so cells stay roughly square on the ground.
+func gridCell(lat, lon, cellSizeMeters float64) (key string, minLat, maxLat, minLon, maxLon float64) {
+	latStep := cellSizeMeters / metersPerDegreeLat
+	row := int64(math.Floor((lat + 90) / latStep))
+	minLat = float64(row)*latStep - 90
+	maxLat = minLat + latStep
+
+	cosLat := math.Cos((minLat + latStep/2) * math.Pi / 180)
+	if cosLat < 0.01 {
+		cosLat = 0.01
+	}
+	lonStep := cellSizeMeters / (metersPerDegreeLat * cosLat)
+	col := int64(math.Floor((lon + 180) / lonStep))
+	minLon = float64(col)*lonStep - 180
+	maxLon = minLon + lonStep
+
+	return fmt.Sprintf("%d:%d", row, col), minLat, maxLat, minLon, maxLon
+}
@@ -0,0 +1,440 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+	"night-owls-go/internal/utils"
+)
+
+// ErrTooManyReportsForPDF is returned when a date range matches more than MaxReportsPerPDF reports.
+var ErrTooManyReportsForPDF = errors.New("too many reports for one PDF")
+
+const (
+	// MaxReportsPerPDF caps a date range document, since every report carries its photos
+	MaxReportsPerPDF = 100
+	// ReportPDFMapZoom is the zoom level of the static map, roughly street level
+	ReportPDFMapZoom = 16
+
+	reportPDFMargin       = 45.0
+	reportPDFFooterHeight = 40.0
+	reportPDFLabelWidth   = 110.0
+	reportPDFMapWidth     = 640
+	reportPDFMapHeight    = 320
+	reportPDFTimeLayout   = "2006-01-02 15:04 MST"
+)
+
+// ReportPDFOptions controls what a printed report reveals.
+type ReportPDFOptions struct {
+	// FullReporter shows reporter names and phone numbers in full. Names are masked otherwise.
+	FullReporter bool
+}
+
+// ReportPDFService renders reports to printable PDF documents for community
+// meetings and insurance claims. Documents are built entirely server-side;
+// maps come from an offline tile cache and fall back to plain coordinates.
+type ReportPDFService struct {
+	querier      db.Querier
+	geoService   *ReportGeoService
+	auditService *AuditService
+	tileCacheDir string
+	logger       *slog.Logger
+}
+
+// NewReportPDFService creates a new ReportPDFService. tileCacheDir may be empty,
+// in which case locations are printed as coordinates only.
+func NewReportPDFService(querier db.Querier, geoService *ReportGeoService, auditService *AuditService, tileCacheDir string, logger *slog.Logger) *ReportPDFService {
+	return &ReportPDFService{
+		querier:      querier,
+		geoService:   geoService,
+		auditService: auditService,
+		tileCacheDir: tileCacheDir,
+		logger:       logger.With("service", "ReportPDFService"),
+	}
+}
+
+// RenderReport renders a single report, including archived ones. The print is
+// recorded in the audit trail before the document is built.
+func (s *ReportPDFService) RenderReport(ctx context.Context, reportID int64, opts ReportPDFOptions, actorUserID int64, ipAddress, userAgent string) ([]byte, error) {
+	row, err := s.querier.AdminGetReportWithContext(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReportNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get report for PDF", "report_id", reportID, "error", err)
+		return nil, ErrInternalServer
+	}
+
+	if err := s.auditService.LogReportsPrinted(ctx, actorUserID, []int64{reportID}, nil, opts.FullReporter, ipAddress, userAgent); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to log report print audit event, refusing print", "error", err)
+		return nil, ErrInternalServer
+	}
+
+	return s.render(ctx, fmt.Sprintf("Incident report #%d", reportID), nil, []db.AdminListReportsWithContextRow{db.AdminListReportsWithContextRow(row)}, opts)
+}
+
+// RenderReports renders the active reports matching the filter, oldest first,
+// with a summary page. It returns the document and the number of reports in it.
+func (s *ReportPDFService) RenderReports(ctx context.Context, filter ReportFilter, opts ReportPDFOptions, actorUserID int64, ipAddress, userAgent string) ([]byte, int, error) {
+	reports, err := s.geoService.ListFilteredReports(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(reports) > MaxReportsPerPDF {
+		return nil, 0, ErrTooManyReportsForPDF
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].CreatedAt.Time.Before(reports[j].CreatedAt.Time)
+	})
+
+	reportIDs := make([]int64, len(reports))
+	for i, report := range reports {
+		reportIDs[i] = report.ReportID
+	}
+	if err := s.auditService.LogReportsPrinted(ctx, actorUserID, reportIDs, filter.AuditDetails(), opts.FullReporter, ipAddress, userAgent); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to log report print audit event, refusing print", "error", err)
+		return nil, 0, ErrInternalServer
+	}
+
+	doc, err := s.render(ctx, "Incident reports", &filter, reports, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return doc, len(reports), nil
+}
+
+// render lays out the document. A summary is included when filter is set.
+func (s *ReportPDFService) render(ctx context.Context, title string, filter *ReportFilter, reports []db.AdminListReportsWithContextRow, opts ReportPDFOptions) ([]byte, error) {
+	contacts, err := s.querier.GetEmergencyContacts(ctx)
+	if err != nil {
+		// The document is still useful without the contact header
+		s.logger.WarnContext(ctx, "Failed to load emergency contacts for PDF", "error", err)
+	}
+
+	doc := utils.NewPDFDocument()
+	l := newReportPDFLayout(doc)
+
+	l.textLine(title, 18, true)
+	l.textLine("Night Owls community watch - generated "+formatPDFTime(time.Now()), 9, false)
+	l.space(6)
+	l.textLine("Emergency contacts", 11, true)
+	if len(contacts) == 0 {
+		l.paragraph("None configured", 10, false)
+	}
+	for _, contact := range contacts {
+		line := contact.Name + ": " + contact.Number
+		if contact.Description.Valid && contact.Description.String != "" {
+			line += " (" + contact.Description.String + ")"
+		}
+		l.paragraph(line, 10, false)
+	}
+	l.rule()
+
+	if filter != nil {
+		s.writeSummary(l, *filter, reports)
+	}
+
+	for i, report := range reports {
+		if filter != nil || i > 0 {
+			l.newPage()
+		}
+		s.writeReport(ctx, l, report, opts)
+	}
+
+	pages := doc.PageCount()
+	for page := 1; page <= pages; page++ {
+		doc.SetPage(page)
+		doc.Text(reportPDFMargin, 25, 8, false, title)
+		footer := fmt.Sprintf("Page %d of %d", page, pages)
+		doc.Text(utils.PDFPageWidth-reportPDFMargin-utils.PDFTextWidth(footer, 8, false), 25, 8, false, footer)
+	}
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to write PDF", "error", err)
+		return nil, ErrInternalServer
+	}
+	return buf.Bytes(), nil
+}
+
+// writeSummary lists the filters and an index of the reports in a date range document.
+func (s *ReportPDFService) writeSummary(l *reportPDFLayout, filter ReportFilter, reports []db.AdminListReportsWithContextRow) {
+	l.heading("Summary")
+
+	period := "All time"
+	switch {
+	case filter.From != nil && filter.To != nil:
+		period = formatPDFTime(*filter.From) + " to " + formatPDFTime(*filter.To)
+	case filter.From != nil:
+		period = "From " + formatPDFTime(*filter.From)
+	case filter.To != nil:
+		period = "Until " + formatPDFTime(*filter.To)
+	}
+	l.field("Period", period)
+	if filter.Severity != nil {
+		l.field("Severity", SeverityLabel(*filter.Severity))
+	}
+	if filter.ScheduleID != nil {
+		l.field("Schedule ID", fmt.Sprint(*filter.ScheduleID))
+	}
+	if filter.UserID != nil {
+		l.field("Reporter ID", fmt.Sprint(*filter.UserID))
+	}
+	if filter.CategoryID != nil {
+		l.field("Category ID", fmt.Sprint(*filter.CategoryID))
+	}
+	l.field("Reports", fmt.Sprint(len(reports)))
+	l.space(6)
+
+	if len(reports) == 0 {
+		l.paragraph("No reports match these filters.", 10, false)
+		return
+	}
+	for _, report := range reports {
+		schedule := report.ScheduleName
+		if !report.BookingID.Valid {
+			schedule = "Off-shift"
+		}
+		l.paragraph(fmt.Sprintf("#%d - %s - %s - %s", report.ReportID, formatPDFNullTime(report.CreatedAt), SeverityLabel(report.Severity), schedule), 9, false)
+	}
+}
+
+// writeReport lays out a single report: details, message, location, photos and audit history.
+func (s *ReportPDFService) writeReport(ctx context.Context, l *reportPDFLayout, report db.AdminListReportsWithContextRow, opts ReportPDFOptions) {
+	l.heading(fmt.Sprintf("Report #%d - %s", report.ReportID, SeverityLabel(report.Severity)))
+
+	l.field("Filed", formatPDFNullTime(report.CreatedAt))
+	if report.CategoryID.Valid {
+		l.field("Category", report.CategoryName)
+	}
+	if report.ArchivedAt.Valid {
+		l.field("Status", "Archived "+formatPDFNullTime(report.ArchivedAt))
+	}
+
+	reporter := utils.MaskName(report.UserName)
+	if opts.FullReporter {
+		reporter = report.UserName
+		if report.UserPhone != "" {
+			reporter += " (" + report.UserPhone + ")"
+		}
+	}
+	l.field("Reporter", reporter)
+
+	if report.BookingID.Valid {
+		l.field("Schedule", report.ScheduleName)
+		start, end := reportShiftTime(report.ShiftStart), reportShiftTime(report.ShiftEnd)
+		if start != nil && end != nil {
+			l.field("Shift", formatPDFTime(*start)+" to "+formatPDFTime(*end))
+		}
+	} else {
+		l.field("Schedule", "Off-shift report")
+	}
+
+	l.subheading("Message")
+	message := strings.TrimSpace(report.Message.String)
+	if message == "" {
+		message = "(no message)"
+	}
+	l.paragraph(message, 10, false)
+
+	l.subheading("Location")
+	if report.Latitude.Valid && report.Longitude.Valid {
+		l.field("Coordinates", fmt.Sprintf("%.6f, %.6f", report.Latitude.Float64, report.Longitude.Float64))
+		if report.GpsAccuracy.Valid {
+			l.field("Accuracy", fmt.Sprintf("%.0f m", report.GpsAccuracy.Float64))
+		}
+		if report.GpsTimestamp.Valid {
+			l.field("GPS fix", formatPDFNullTime(report.GpsTimestamp))
+		}
+
+		mapImage, err := utils.RenderStaticMap(s.tileCacheDir, ReportPDFMapZoom, report.Latitude.Float64, report.Longitude.Float64, reportPDFMapWidth, reportPDFMapHeight)
+		switch {
+		case err == nil:
+			if err := l.image(mapImage, l.contentWidth(), l.contentWidth()/2); err != nil {
+				s.logger.WarnContext(ctx, "Failed to add map to PDF", "report_id", report.ReportID, "error", err)
+			}
+		case !errors.Is(err, utils.ErrMapTileNotCached):
+			s.logger.WarnContext(ctx, "Failed to render static map", "report_id", report.ReportID, "error", err)
+		}
+	} else {
+		l.paragraph("No location recorded", 10, false)
+	}
+
+	photos, err := s.querier.GetReportPhotos(ctx, report.ReportID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to load photos for PDF", "report_id", report.ReportID, "error", err)
+	}
+	if len(photos) > 0 {
+		l.subheading(fmt.Sprintf("Photos (%d)", len(photos)))
+		for _, photo := range photos {
+			caption := photo.Filename
+			if photo.UploadTimestamp.Valid {
+				caption += ", uploaded " + formatPDFNullTime(photo.UploadTimestamp)
+			}
+			img, err := loadReportPhoto(photo.StoragePath)
+			if err != nil {
+				s.logger.WarnContext(ctx, "Failed to load photo for PDF", "photo_id", photo.PhotoID, "error", err)
+				l.paragraph(caption+" - could not be included ("+photo.MimeType+")", 9, false)
+				continue
+			}
+			if err := l.image(img, l.contentWidth(), 300); err != nil {
+				s.logger.WarnContext(ctx, "Failed to add photo to PDF", "photo_id", photo.PhotoID, "error", err)
+				continue
+			}
+			l.paragraph(caption, 8, false)
+		}
+	}
+
+	events, err := s.querier.ListAuditEventsByEntity(ctx, db.ListAuditEventsByEntityParams{
+		EntityType: "report",
+		EntityID:   sql.NullInt64{Int64: report.ReportID, Valid: true},
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to load audit history for PDF", "report_id", report.ReportID, "error", err)
+	}
+	l.subheading("Audit history")
+	if len(events) == 0 {
+		l.paragraph("No recorded events", 10, false)
+	}
+	for _, event := range events {
+		actor := event.ActorName
+		switch {
+		case !event.ActorUserID.Valid:
+			actor = "system"
+		case !opts.FullReporter && report.UserID.Valid && event.ActorUserID.Int64 == report.UserID.Int64:
+			// The reporter's own actions would otherwise reveal their name
+			actor = utils.MaskName(actor)
+		}
+		l.paragraph(fmt.Sprintf("%s - %s by %s", formatPDFNullTime(event.CreatedAt), event.EventType, actor), 9, false)
+	}
+}
+
+// loadReportPhoto decodes a stored photo. WebP photos cannot be decoded with
+// the standard library and are reported as not included.
+func loadReportPhoto(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	return img, err
+}
+
+// formatPDFTime formats a timestamp for print in UTC.
+func formatPDFTime(t time.Time) string {
+	return t.UTC().Format(reportPDFTimeLayout)
+}
+
+// formatPDFNullTime formats an optional timestamp for print.
+func formatPDFNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return "unknown"
+	}
+	return formatPDFTime(t.Time)
+}
+
+// reportPDFLayout flows content down the page, starting new pages as needed.
+type reportPDFLayout struct {
+	doc *utils.PDFDocument
+	y   float64
+}
+
+func newReportPDFLayout(doc *utils.PDFDocument) *reportPDFLayout {
+	l := &reportPDFLayout{doc: doc}
+	l.newPage()
+	return l
+}
+
+func (l *reportPDFLayout) contentWidth() float64 {
+	return utils.PDFPageWidth - 2*reportPDFMargin
+}
+
+func (l *reportPDFLayout) newPage() {
+	l.doc.AddPage()
+	l.y = utils.PDFPageHeight - reportPDFMargin
+}
+
+// ensureSpace starts a new page unless height points are left above the footer.
+func (l *reportPDFLayout) ensureSpace(height float64) {
+	if l.y-height < reportPDFFooterHeight {
+		l.newPage()
+	}
+}
+
+func (l *reportPDFLayout) space(height float64) {
+	l.y -= height
+}
+
+func (l *reportPDFLayout) textLine(text string, size float64, bold bool) {
+	l.ensureSpace(size * 1.4)
+	l.y -= size * 1.4
+	l.doc.Text(reportPDFMargin, l.y, size, bold, text)
+}
+
+func (l *reportPDFLayout) paragraph(text string, size float64, bold bool) {
+	for _, line := range utils.WrapPDFText(text, size, bold, l.contentWidth()) {
+		l.textLine(line, size, bold)
+	}
+}
+
+func (l *reportPDFLayout) rule() {
+	l.space(6)
+	l.doc.Line(reportPDFMargin, l.y, utils.PDFPageWidth-reportPDFMargin, l.y)
+	l.space(4)
+}
+
+func (l *reportPDFLayout) heading(text string) {
+	l.ensureSpace(60)
+	l.space(8)
+	l.textLine(text, 14, true)
+	l.rule()
+}
+
+func (l *reportPDFLayout) subheading(text string) {
+	l.ensureSpace(40)
+	l.space(8)
+	l.textLine(text, 11, true)
+}
+
+// field prints a bold label with the value wrapped beside it.
+func (l *reportPDFLayout) field(label, value string) {
+	const size = 10
+	lines := utils.WrapPDFText(value, size, false, l.contentWidth()-reportPDFLabelWidth)
+	for i, line := range lines {
+		l.ensureSpace(size * 1.4)
+		l.y -= size * 1.4
+		if i == 0 {
+			l.doc.Text(reportPDFMargin, l.y, size, true, label)
+		}
+		l.doc.Text(reportPDFMargin+reportPDFLabelWidth, l.y, size, false, line)
+	}
+}
+
+// image draws img scaled to fit within maxWidth x maxHeight, keeping its aspect ratio.
+func (l *reportPDFLayout) image(img image.Image, maxWidth, maxHeight float64) error {
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return errors.New("empty image")
+	}
+	scale := maxWidth / float64(bounds.Dx())
+	if h := float64(bounds.Dy()) * scale; h > maxHeight {
+		scale = maxHeight / float64(bounds.Dy())
+	}
+	width, height := float64(bounds.Dx())*scale, float64(bounds.Dy())*scale
+
+	l.ensureSpace(height + 8)
+	l.y -= height + 4
+	return l.doc.Image(img, reportPDFMargin, l.y, width, height)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"time"
@@ -25,22 +26,110 @@ var (
 
 // ReportService handles logic related to incident reports.
 type ReportService struct {
-	querier       db.Querier
-	logger        *slog.Logger
-	pointsService *PointsService
+	querier           db.Querier
+	logger            *slog.Logger
+	pointsService     *PointsService
+	escalationService *IncidentEscalationService
+	events            *EventBroker
+	abuse             *ReportAbuseService
+	duplicates        *ReportDuplicateService
+	watchlist         *WatchlistService
 }
 
 // NewReportService creates a new ReportService.
-func NewReportService(querier db.Querier, logger *slog.Logger, pointsService *PointsService) *ReportService {
+func NewReportService(querier db.Querier, logger *slog.Logger, pointsService *PointsService, abuse *ReportAbuseService) *ReportService {
 	return &ReportService{
 		querier:       querier,
 		logger:        logger.With("service", "ReportService"),
 		pointsService: pointsService,
+		abuse:         abuse,
+	}
+}
+
+// SetEscalationService enables the severity-2 escalation chain for new reports
+func (s *ReportService) SetEscalationService(escalationService *IncidentEscalationService) {
+	s.escalationService = escalationService
+}
+
+// SetEventBroker enables publishing new reports to the real-time stream
+func (s *ReportService) SetEventBroker(events *EventBroker) {
+	s.events = events
+}
+
+// SetDuplicateService enables duplicate detection for new reports
+func (s *ReportService) SetDuplicateService(duplicates *ReportDuplicateService) {
+	s.duplicates = duplicates
+}
+
+// SetWatchlistService enables matching new reports against the watchlist
+func (s *ReportService) SetWatchlistService(watchlist *WatchlistService) {
+	s.watchlist = watchlist
+}
+
+// matchWatchlist links a new report to the watchlist entries it mentions.
+// Failures are logged but never fail report creation.
+func (s *ReportService) matchWatchlist(ctx context.Context, report db.Report) {
+	if s.watchlist == nil {
+		return
+	}
+	if _, err := s.watchlist.MatchReport(ctx, report); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to match report against watchlist", "report_id", report.ReportID, "error", err)
+	}
+}
+
+// detectDuplicate records whether a new report likely duplicates an earlier one.
+// Failures are logged but never fail report creation.
+func (s *ReportService) detectDuplicate(ctx context.Context, report db.Report) *DuplicateMatch {
+	if s.duplicates == nil {
+		return nil
+	}
+	match, err := s.duplicates.DetectDuplicate(ctx, report)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to check report for duplicates", "report_id", report.ReportID, "error", err)
+		return nil
+	}
+	return match
+}
+
+// publishCreated tells admins and the reporter that a report was filed.
+func (s *ReportService) publishCreated(report db.Report) {
+	data := map[string]interface{}{
+		"report_id": report.ReportID,
+		"severity":  report.Severity,
+		"message":   report.Message.String,
+	}
+	if report.BookingID.Valid {
+		data["booking_id"] = report.BookingID.Int64
+	}
+	if report.CategoryID.Valid {
+		data["category_id"] = report.CategoryID.Int64
+	}
+	if report.CreatedAt.Valid {
+		data["created_at"] = report.CreatedAt.Time
+	}
+
+	var userIDs []int64
+	if report.UserID.Valid {
+		data["user_id"] = report.UserID.Int64
+		userIDs = append(userIDs, report.UserID.Int64)
+	}
+
+	s.events.Publish(DomainEvent{Type: EventReportCreated, Data: data, UserIDs: userIDs})
+}
+
+// escalate starts the escalation chain for serious reports. Failures are logged
+// but never fail report creation.
+func (s *ReportService) escalate(ctx context.Context, report db.Report) {
+	if s.escalationService == nil || report.Severity < 2 {
+		return
+	}
+	if _, err := s.escalationService.EscalateReport(ctx, report); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to escalate serious report", "report_id", report.ReportID, "error", err)
 	}
 }
 
 // CreateReport handles the logic for creating a new incident report.
-func (s *ReportService) CreateReport(ctx context.Context, userIDFromAuth int64, bookingID int64, severity int32, message string, gpsLocation *GPSLocation) (db.Report, error) {
+func (s *ReportService) CreateReport(ctx context.Context, userIDFromAuth int64, bookingID int64, severity int32, message string, gpsLocation *GPSLocation, category *ReportCategoryInput) (db.Report, error) {
 	// 1. Validate booking exists and user is authorized
 	// A user can only report on their own bookings.
 	booking, err := s.querier.GetBookingByID(ctx, bookingID)
@@ -64,6 +153,11 @@ func (s *ReportService) CreateReport(ctx context.Context, userIDFromAuth int64,
 		return db.Report{}, ErrSeverityOutOfRange
 	}
 
+	categoryID, categoryFields, err := s.resolveReportCategory(ctx, category)
+	if err != nil {
+		return db.Report{}, err
+	}
+
 	// 3. Prepare GPS data
 	var latitude, longitude, accuracy sql.NullFloat64
 	var gpsTimestamp sql.NullTime
@@ -85,14 +179,16 @@ func (s *ReportService) CreateReport(ctx context.Context, userIDFromAuth int64,
 
 	// 4. Insert report into DB
 	reportParams := db.CreateReportParams{
-		BookingID:    sql.NullInt64{Int64: bookingID, Valid: true},
-		UserID:       sql.NullInt64{Int64: userIDFromAuth, Valid: true},
-		Severity:     int64(severity),
-		Message:      sql.NullString{String: message, Valid: message != ""},
-		Latitude:     latitude,
-		Longitude:    longitude,
-		GpsAccuracy:  accuracy,
-		GpsTimestamp: gpsTimestamp,
+		BookingID:      sql.NullInt64{Int64: bookingID, Valid: true},
+		UserID:         sql.NullInt64{Int64: userIDFromAuth, Valid: true},
+		Severity:       int64(severity),
+		Message:        sql.NullString{String: message, Valid: message != ""},
+		Latitude:       latitude,
+		Longitude:      longitude,
+		GpsAccuracy:    accuracy,
+		GpsTimestamp:   gpsTimestamp,
+		CategoryID:     categoryID,
+		CategoryFields: categoryFields,
 	}
 
 	createdReport, err := s.querier.CreateReport(ctx, reportParams)
@@ -101,9 +197,14 @@ func (s *ReportService) CreateReport(ctx context.Context, userIDFromAuth int64,
 		return db.Report{}, ErrInternalServer
 	}
 
-	// Award shift completion points to the user
+	// Award shift completion points to the user. A serious report that repeats
+	// one already filed about the same incident doesn't earn the bonus again.
+	pointsSeverity := int(createdReport.Severity)
+	if duplicate := s.detectDuplicate(ctx, createdReport); duplicate != nil && duplicate.Severity >= 2 && pointsSeverity >= 2 {
+		pointsSeverity = 1
+	}
 	if s.pointsService != nil {
-		if err := s.pointsService.AwardShiftCompletionPoints(ctx, userIDFromAuth, bookingID, int(createdReport.Severity)); err != nil {
+		if err := s.pointsService.AwardShiftCompletionPoints(ctx, userIDFromAuth, bookingID, pointsSeverity); err != nil {
 			s.logger.WarnContext(ctx, "Failed to award completion points", "report_id", createdReport.ReportID, "booking_id", bookingID, "user_id", userIDFromAuth, "error", err)
 			// Non-fatal - don't fail the report creation if points can't be awarded
 		} else {
@@ -111,19 +212,39 @@ func (s *ReportService) CreateReport(ctx context.Context, userIDFromAuth int64,
 		}
 	}
 
+	s.escalate(ctx, createdReport)
+	s.publishCreated(createdReport)
+	s.matchWatchlist(ctx, createdReport)
+
 	s.logger.InfoContext(ctx, "Report created successfully", "report_id", createdReport.ReportID, "booking_id", bookingID)
 	return createdReport, nil
 }
 
 // CreateOffShiftReport handles the logic for creating an off-shift incident report.
-func (s *ReportService) CreateOffShiftReport(ctx context.Context, userIDFromAuth int64, severity int32, message string, gpsLocation *GPSLocation) (db.Report, error) {
-	// 1. Validate severity (0-2)
+// Only owls and admins in good standing may file one, subject to per-user and
+// per-IP rate limits.
+func (s *ReportService) CreateOffShiftReport(ctx context.Context, userIDFromAuth int64, clientIP string, severity int32, message string, gpsLocation *GPSLocation, category *ReportCategoryInput) (db.Report, error) {
+	// 1. Check role, trust score and rate limits
+	if err := s.abuse.CheckOffShiftReport(ctx, userIDFromAuth, clientIP); err != nil {
+		return db.Report{}, err
+	}
+
+	// 2. Validate severity (0-2) and location
 	if severity < 0 || severity > 2 {
 		s.logger.WarnContext(ctx, "Severity out of range for off-shift report", "severity", severity)
 		return db.Report{}, ErrSeverityOutOfRange
 	}
+	if err := ValidateGPSLocation(gpsLocation, time.Now().UTC()); err != nil {
+		s.logger.WarnContext(ctx, "Invalid location for off-shift report", "user_id", userIDFromAuth, "error", err)
+		return db.Report{}, err
+	}
 
-	// 2. Prepare GPS data
+	categoryID, categoryFields, err := s.resolveReportCategory(ctx, category)
+	if err != nil {
+		return db.Report{}, err
+	}
+
+	// 3. Prepare GPS data
 	var latitude, longitude, accuracy sql.NullFloat64
 	var gpsTimestamp sql.NullTime
 
@@ -142,10 +263,56 @@ func (s *ReportService) CreateOffShiftReport(ctx context.Context, userIDFromAuth
 		}
 	}
 
-	// 3. Insert off-shift report into DB
+	// 4. Insert off-shift report into DB
 	reportParams := db.CreateOffShiftReportParams{
-		UserID:       sql.NullInt64{Int64: userIDFromAuth, Valid: true},
-		Severity:     int64(severity),
+		UserID:         sql.NullInt64{Int64: userIDFromAuth, Valid: true},
+		Severity:       int64(severity),
+		Message:        sql.NullString{String: message, Valid: message != ""},
+		Latitude:       latitude,
+		Longitude:      longitude,
+		GpsAccuracy:    accuracy,
+		GpsTimestamp:   gpsTimestamp,
+		CategoryID:     categoryID,
+		CategoryFields: categoryFields,
+	}
+
+	createdReport, err := s.querier.CreateOffShiftReport(ctx, reportParams)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create off-shift report in DB", "params", reportParams, "error", err)
+		return db.Report{}, ErrInternalServer
+	}
+
+	s.abuse.RecordOffShiftReport(ctx, userIDFromAuth, clientIP, createdReport.ReportID)
+	s.detectDuplicate(ctx, createdReport)
+	s.escalate(ctx, createdReport)
+	s.publishCreated(createdReport)
+	s.matchWatchlist(ctx, createdReport)
+
+	s.logger.InfoContext(ctx, "Off-shift report created successfully", "report_id", createdReport.ReportID, "user_id", userIDFromAuth)
+	return createdReport, nil
+}
+
+// CreateSOSReport files the severity-2 report behind an SOS alert. It skips the
+// off-shift rate limits and points, and leaves escalation to the SOS service,
+// which runs it without the usual SMS delay.
+func (s *ReportService) CreateSOSReport(ctx context.Context, userID int64, bookingID sql.NullInt64, message string, gpsLocation *GPSLocation) (db.Report, error) {
+	var latitude, longitude, accuracy sql.NullFloat64
+	var gpsTimestamp sql.NullTime
+	if gpsLocation != nil && gpsLocation.Latitude != nil && gpsLocation.Longitude != nil {
+		latitude = sql.NullFloat64{Float64: *gpsLocation.Latitude, Valid: true}
+		longitude = sql.NullFloat64{Float64: *gpsLocation.Longitude, Valid: true}
+		if gpsLocation.Accuracy != nil {
+			accuracy = sql.NullFloat64{Float64: *gpsLocation.Accuracy, Valid: true}
+		}
+		if gpsLocation.Timestamp != nil {
+			gpsTimestamp = sql.NullTime{Time: *gpsLocation.Timestamp, Valid: true}
+		}
+	}
+
+	reportParams := db.CreateReportParams{
+		BookingID:    bookingID,
+		UserID:       sql.NullInt64{Int64: userID, Valid: true},
+		Severity:     2,
 		Message:      sql.NullString{String: message, Valid: message != ""},
 		Latitude:     latitude,
 		Longitude:    longitude,
@@ -153,16 +320,92 @@ func (s *ReportService) CreateOffShiftReport(ctx context.Context, userIDFromAuth
 		GpsTimestamp: gpsTimestamp,
 	}
 
-	createdReport, err := s.querier.CreateOffShiftReport(ctx, reportParams)
+	createdReport, err := s.querier.CreateReport(ctx, reportParams)
 	if err != nil {
-		s.logger.ErrorContext(ctx, "Failed to create off-shift report in DB", "params", reportParams, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to create SOS report in DB", "params", reportParams, "error", err)
 		return db.Report{}, ErrInternalServer
 	}
 
-	s.logger.InfoContext(ctx, "Off-shift report created successfully", "report_id", createdReport.ReportID, "user_id", userIDFromAuth)
+	s.detectDuplicate(ctx, createdReport)
+	s.publishCreated(createdReport)
+	s.matchWatchlist(ctx, createdReport)
+
+	s.logger.InfoContext(ctx, "SOS report created successfully", "report_id", createdReport.ReportID, "user_id", userID)
 	return createdReport, nil
 }
 
+// FlagReport marks a report as spam or a false alarm, lowering the reporter's trust score
+func (s *ReportService) FlagReport(ctx context.Context, reportID, flaggedByUserID int64, flag, note string) (db.ReportFlag, *ReporterTrust, error) {
+	return s.abuse.FlagReport(ctx, reportID, flaggedByUserID, flag, note)
+}
+
+// ClearReportFlag removes a report's spam or false alarm flag
+func (s *ReportService) ClearReportFlag(ctx context.Context, reportID int64) (db.ReportFlag, *ReporterTrust, error) {
+	return s.abuse.ClearReportFlag(ctx, reportID)
+}
+
+// GetReportFlag returns the spam or false alarm flag set on a report
+func (s *ReportService) GetReportFlag(ctx context.Context, reportID int64) (db.ReportFlag, error) {
+	return s.abuse.GetReportFlag(ctx, reportID)
+}
+
+// GetReporterTrust returns a user's trust score as a reporter
+func (s *ReportService) GetReporterTrust(ctx context.Context, userID int64) (ReporterTrust, error) {
+	trust, err := s.abuse.GetReporterTrust(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get reporter trust score", "user_id", userID, "error", err)
+		return ReporterTrust{}, ErrInternalServer
+	}
+	return trust, nil
+}
+
+// CleanupOffShiftReportSubmissions removes rate limiting records older than the given age
+func (s *ReportService) CleanupOffShiftReportSubmissions(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return s.abuse.CleanupOldSubmissions(ctx, olderThan)
+}
+
+// resolveReportCategory validates the submitted category and structured field
+// values, returning the values to store with the report.
+func (s *ReportService) resolveReportCategory(ctx context.Context, input *ReportCategoryInput) (sql.NullInt64, sql.NullString, error) {
+	if input == nil {
+		return sql.NullInt64{}, sql.NullString{}, nil
+	}
+
+	category, err := s.querier.GetIncidentCategoryByID(ctx, input.CategoryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "Unknown incident category for report", "category_id", input.CategoryID)
+			return sql.NullInt64{}, sql.NullString{}, ErrIncidentCategoryNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get incident category for report", "category_id", input.CategoryID, "error", err)
+		return sql.NullInt64{}, sql.NullString{}, ErrInternalServer
+	}
+
+	fields, err := ParseCategoryFields(category)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Incident category has malformed field definitions", "category_id", category.CategoryID, "error", err)
+		return sql.NullInt64{}, sql.NullString{}, ErrInternalServer
+	}
+
+	values, err := ValidateCategoryFieldValues(fields, input.Fields)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Report category fields failed validation", "category_id", category.CategoryID, "error", err)
+		return sql.NullInt64{}, sql.NullString{}, err
+	}
+
+	categoryID := sql.NullInt64{Int64: category.CategoryID, Valid: true}
+	if len(values) == 0 {
+		return categoryID, sql.NullString{}, nil
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to encode report category fields", "category_id", category.CategoryID, "error", err)
+		return sql.NullInt64{}, sql.NullString{}, ErrInternalServer
+	}
+	return categoryID, sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
 // BookingDetails represents booking information for reports
 type BookingDetails struct {
 	ScheduleName string
@@ -0,0 +1,476 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+var (
+	ErrSOSAlertNotFound  = errors.New("SOS alert not found")
+	ErrSOSAlertStoodDown = errors.New("SOS alert has already been stood down")
+	ErrSOSAckForbidden   = errors.New("user was not alerted for this SOS")
+	ErrInvalidSOSStatus  = errors.New("status must be 'active' or 'stood_down'")
+)
+
+// SOS alert states
+const (
+	SOSStatusActive    = "active"
+	SOSStatusStoodDown = "stood_down"
+)
+
+// OutboxDispatcher delivers outbox items straight away instead of waiting for
+// the scheduled outbox run. It is implemented by the outbox dispatcher.
+type OutboxDispatcher interface {
+	DispatchOutboxItems(ctx context.Context, outboxIDs []int64) (int, int)
+}
+
+// SOSInput is the location and optional message sent with an SOS.
+type SOSInput struct {
+	Latitude  *float64
+	Longitude *float64
+	Accuracy  *float64
+	Message   string
+}
+
+// SOSService handles panic alerts. Raising an SOS files a severity-2 report,
+// runs the escalation chain with no SMS delay and dispatches every message
+// immediately. The alert stays active, collecting acknowledgements from
+// responders, until an admin stands it down.
+type SOSService struct {
+	querier           db.Querier
+	reportService     *ReportService
+	escalationService *IncidentEscalationService
+	dispatcher        OutboxDispatcher
+	logger            *slog.Logger
+	events            *EventBroker
+}
+
+// NewSOSService creates a new SOSService. dispatcher may be nil, in which case
+// alerts are delivered by the regular outbox run.
+func NewSOSService(querier db.Querier, reportService *ReportService, escalationService *IncidentEscalationService, dispatcher OutboxDispatcher, logger *slog.Logger) *SOSService {
+	return &SOSService{
+		querier:           querier,
+		reportService:     reportService,
+		escalationService: escalationService,
+		dispatcher:        dispatcher,
+		logger:            logger.With("service", "SOSService"),
+	}
+}
+
+// SetEventBroker enables publishing SOS activity to the real-time stream
+func (s *SOSService) SetEventBroker(events *EventBroker) {
+	s.events = events
+}
+
+// RaiseSOS raises an SOS for the user. If the user already has an active alert
+// it is returned unchanged with created set to false, so a repeated tap does
+// not alert everyone twice. At most one alert per user can be active, so of
+// two concurrent taps only one raises the alarm.
+func (s *SOSService) RaiseSOS(ctx context.Context, userID int64, input SOSInput) (db.SosAlert, bool, error) {
+	if input.Latitude != nil && (*input.Latitude < -90 || *input.Latitude > 90) ||
+		input.Longitude != nil && (*input.Longitude < -180 || *input.Longitude > 180) {
+		return db.SosAlert{}, false, ErrInvalidLocation
+	}
+
+	existing, err := s.querier.GetActiveSOSAlertByUser(ctx, userID)
+	if err == nil {
+		s.logger.InfoContext(ctx, "User already has an active SOS", "alert_id", existing.AlertID, "user_id", userID)
+		return existing, false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		s.logger.ErrorContext(ctx, "Failed to check for active SOS", "user_id", userID, "error", err)
+		return db.SosAlert{}, false, ErrInternalServer
+	}
+
+	user, err := s.querier.GetUserByID(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to get user raising SOS", "user_id", userID, "error", err)
+		return db.SosAlert{}, false, ErrInternalServer
+	}
+	raisedBy := user.Phone
+	if user.Name.Valid && strings.TrimSpace(user.Name.String) != "" {
+		raisedBy = strings.TrimSpace(user.Name.String)
+	}
+
+	now := time.Now().UTC()
+	bookingID := s.activeBookingID(ctx, userID, now)
+
+	var latitude, longitude, accuracy sql.NullFloat64
+	if input.Latitude != nil && input.Longitude != nil {
+		latitude = sql.NullFloat64{Float64: *input.Latitude, Valid: true}
+		longitude = sql.NullFloat64{Float64: *input.Longitude, Valid: true}
+		if input.Accuracy != nil {
+			accuracy = sql.NullFloat64{Float64: *input.Accuracy, Valid: true}
+		}
+	}
+
+	// The alert is created first so a concurrent tap hits the one-active-alert
+	// index before a second report is filed.
+	alert, err := s.querier.CreateSOSAlert(ctx, db.CreateSOSAlertParams{
+		UserID:    userID,
+		BookingID: bookingID,
+		Latitude:  latitude,
+		Longitude: longitude,
+		Accuracy:  accuracy,
+	})
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			existing, getErr := s.querier.GetActiveSOSAlertByUser(ctx, userID)
+			if getErr == nil {
+				s.logger.InfoContext(ctx, "User already has an active SOS", "alert_id", existing.AlertID, "user_id", userID)
+				return existing, false, nil
+			}
+			err = getErr
+		}
+		s.logger.ErrorContext(ctx, "Failed to create SOS alert", "user_id", userID, "error", err)
+		return db.SosAlert{}, false, ErrInternalServer
+	}
+
+	message := fmt.Sprintf("SOS raised by %s", raisedBy)
+	if trimmed := strings.TrimSpace(input.Message); trimmed != "" {
+		message += ": " + trimmed
+	}
+	report, err := s.reportService.CreateSOSReport(ctx, userID, bookingID, message, &GPSLocation{
+		Latitude:  input.Latitude,
+		Longitude: input.Longitude,
+		Accuracy:  input.Accuracy,
+		Timestamp: &now,
+	})
+	if err != nil {
+		// Free the slot so the owl can raise the SOS again
+		if delErr := s.querier.DeleteSOSAlert(ctx, alert.AlertID); delErr != nil {
+			s.logger.ErrorContext(ctx, "Failed to remove SOS alert without a report", "alert_id", alert.AlertID, "error", delErr)
+		}
+		s.logger.ErrorContext(ctx, "Failed to create SOS report", "user_id", userID, "error", err)
+		return db.SosAlert{}, false, ErrInternalServer
+	}
+
+	reportID := sql.NullInt64{Int64: report.ReportID, Valid: true}
+	if linked, err := s.querier.SetSOSAlertReport(ctx, db.SetSOSAlertReportParams{ReportID: reportID, AlertID: alert.AlertID}); err != nil {
+		// Keep going: responders must still be alerted
+		s.logger.ErrorContext(ctx, "Failed to link SOS alert to its report", "alert_id", alert.AlertID, "report_id", report.ReportID, "error", err)
+		alert.ReportID = reportID
+	} else {
+		alert = linked
+	}
+
+	// The alert is recorded even if messages could not be queued, so admins still see it
+	_, outboxIDs, err := s.escalationService.EscalateSOS(ctx, report, raisedBy)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to escalate SOS", "alert_id", alert.AlertID, "error", err)
+	}
+	s.dispatch(outboxIDs)
+	s.publish(ctx, EventSOSRaised, alert, nil)
+
+	s.logger.WarnContext(ctx, "SOS raised",
+		"alert_id", alert.AlertID,
+		"user_id", userID,
+		"report_id", report.ReportID,
+		"booking_id", bookingID.Int64,
+		"message_count", len(outboxIDs))
+	return alert, true, nil
+}
+
+// activeBookingID returns the booking the user is on duty for right now, if any.
+func (s *SOSService) activeBookingID(ctx context.Context, userID int64, now time.Time) sql.NullInt64 {
+	bookings, err := s.querier.ListOnDutyBookings(ctx, db.ListOnDutyBookingsParams{
+		ShiftStart: now,
+		ShiftEnd:   now,
+	})
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to look up active booking for SOS", "user_id", userID, "error", err)
+		return sql.NullInt64{}
+	}
+	for _, booking := range bookings {
+		if booking.UserID == userID && !booking.CheckedOutAt.Valid {
+			return sql.NullInt64{Int64: booking.BookingID, Valid: true}
+		}
+	}
+	return sql.NullInt64{}
+}
+
+// dispatch sends outbox items in the background so the caller is not held up
+// by push and SMS delivery.
+func (s *SOSService) dispatch(outboxIDs []int64) {
+	if s.dispatcher == nil || len(outboxIDs) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+		sent, failed := s.dispatcher.DispatchOutboxItems(ctx, outboxIDs)
+		s.logger.InfoContext(ctx, "SOS messages dispatched", "sent", sent, "failed", failed)
+	}()
+}
+
+// getAlert loads an alert, mapping a missing row to ErrSOSAlertNotFound.
+func (s *SOSService) getAlert(ctx context.Context, alertID int64) (db.SosAlert, error) {
+	alert, err := s.querier.GetSOSAlertByID(ctx, alertID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.SosAlert{}, ErrSOSAlertNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get SOS alert", "alert_id", alertID, "error", err)
+		return db.SosAlert{}, ErrInternalServer
+	}
+	return alert, nil
+}
+
+// GetAlert returns an alert with everyone who has acknowledged it.
+func (s *SOSService) GetAlert(ctx context.Context, alertID int64) (db.SosAlert, []db.ListSOSAcknowledgementsRow, error) {
+	alert, err := s.getAlert(ctx, alertID)
+	if err != nil {
+		return db.SosAlert{}, nil, err
+	}
+	acks, err := s.querier.ListSOSAcknowledgements(ctx, alertID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list SOS acknowledgements", "alert_id", alertID, "error", err)
+		return db.SosAlert{}, nil, ErrInternalServer
+	}
+	return alert, acks, nil
+}
+
+// ListAlerts returns recent alerts, optionally filtered by status.
+func (s *SOSService) ListAlerts(ctx context.Context, status string, limit int64) ([]db.ListSOSAlertsRow, error) {
+	var statusFilter interface{}
+	if status != "" {
+		if status != SOSStatusActive && status != SOSStatusStoodDown {
+			return nil, ErrInvalidSOSStatus
+		}
+		statusFilter = status
+	}
+	alerts, err := s.querier.ListSOSAlerts(ctx, db.ListSOSAlertsParams{Status: statusFilter, Limit: limit})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list SOS alerts", "error", err)
+		return nil, ErrInternalServer
+	}
+	return alerts, nil
+}
+
+// Acknowledge records that a user is responding to an active SOS. Admins may
+// always acknowledge; owls only if they were alerted. Acknowledging twice is
+// not an error. It returns the alert and its acknowledgements.
+func (s *SOSService) Acknowledge(ctx context.Context, alertID, userID int64, isAdmin bool) (db.SosAlert, []db.ListSOSAcknowledgementsRow, bool, error) {
+	alert, err := s.getAlert(ctx, alertID)
+	if err != nil {
+		return db.SosAlert{}, nil, false, err
+	}
+	if alert.Status != SOSStatusActive {
+		return alert, nil, false, ErrSOSAlertStoodDown
+	}
+
+	if !isAdmin {
+		alerted, err := s.wasAlerted(ctx, alert, userID)
+		if err != nil {
+			return db.SosAlert{}, nil, false, err
+		}
+		if !alerted {
+			return db.SosAlert{}, nil, false, ErrSOSAckForbidden
+		}
+	}
+
+	added, err := s.querier.CreateSOSAcknowledgement(ctx, db.CreateSOSAcknowledgementParams{
+		AlertID: alertID,
+		UserID:  userID,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record SOS acknowledgement", "alert_id", alertID, "user_id", userID, "error", err)
+		return db.SosAlert{}, nil, false, ErrInternalServer
+	}
+
+	acks, err := s.querier.ListSOSAcknowledgements(ctx, alertID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list SOS acknowledgements", "alert_id", alertID, "error", err)
+		return db.SosAlert{}, nil, false, ErrInternalServer
+	}
+
+	if added > 0 {
+		s.publish(ctx, EventSOSAcknowledged, alert, map[string]interface{}{"acknowledged_by_user_id": userID})
+	}
+
+	s.logger.InfoContext(ctx, "SOS acknowledged", "alert_id", alertID, "user_id", userID, "new", added > 0)
+	return alert, acks, added > 0, nil
+}
+
+// wasAlerted reports whether the user was sent the SOS push notification.
+func (s *SOSService) wasAlerted(ctx context.Context, alert db.SosAlert, userID int64) (bool, error) {
+	if !alert.ReportID.Valid {
+		return false, nil
+	}
+	escalation, err := s.querier.GetIncidentEscalationByReportID(ctx, alert.ReportID.Int64)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		s.logger.ErrorContext(ctx, "Failed to get SOS escalation", "alert_id", alert.AlertID, "error", err)
+		return false, ErrInternalServer
+	}
+	count, err := s.querier.CountEscalationPushRecipient(ctx, db.CountEscalationPushRecipientParams{
+		EscalationID: escalation.EscalationID,
+		UserID:       sql.NullInt64{Int64: userID, Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to check SOS recipient", "alert_id", alert.AlertID, "user_id", userID, "error", err)
+		return false, ErrInternalServer
+	}
+	return count > 0, nil
+}
+
+// StandDown closes an active SOS. The underlying escalation is acknowledged on
+// the admin's behalf and everyone who was alerted is told to stand down.
+func (s *SOSService) StandDown(ctx context.Context, alertID, adminUserID int64, note string) (db.SosAlert, []db.ListSOSAcknowledgementsRow, error) {
+	note = strings.TrimSpace(note)
+	alert, err := s.querier.StandDownSOSAlert(ctx, db.StandDownSOSAlertParams{
+		StoodDownByUserID: sql.NullInt64{Int64: adminUserID, Valid: true},
+		StandDownNote:     sql.NullString{String: note, Valid: note != ""},
+		AlertID:           alertID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, getErr := s.getAlert(ctx, alertID); getErr != nil {
+				return db.SosAlert{}, nil, getErr
+			}
+			return db.SosAlert{}, nil, ErrSOSAlertStoodDown
+		}
+		s.logger.ErrorContext(ctx, "Failed to stand down SOS alert", "alert_id", alertID, "error", err)
+		return db.SosAlert{}, nil, ErrInternalServer
+	}
+
+	if alert.ReportID.Valid {
+		if _, _, err := s.escalationService.AcknowledgeEscalation(ctx, alert.ReportID.Int64, adminUserID, true); err != nil &&
+			!errors.Is(err, ErrEscalationAlreadyAcknowledged) && !errors.Is(err, ErrEscalationNotFound) {
+			s.logger.WarnContext(ctx, "Failed to acknowledge SOS escalation on stand-down", "alert_id", alertID, "error", err)
+		}
+		s.notifyStandDown(ctx, alert)
+	}
+
+	acks, err := s.querier.ListSOSAcknowledgements(ctx, alertID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list SOS acknowledgements", "alert_id", alertID, "error", err)
+		return db.SosAlert{}, nil, ErrInternalServer
+	}
+
+	s.publish(ctx, EventSOSStoodDown, alert, nil)
+
+	s.logger.InfoContext(ctx, "SOS stood down", "alert_id", alertID, "admin_user_id", adminUserID, "acknowledgements", len(acks))
+	return alert, acks, nil
+}
+
+// publish sends an SOS event to admins, the owl who raised it and everyone
+// who was alerted.
+func (s *SOSService) publish(ctx context.Context, eventType string, alert db.SosAlert, extra map[string]interface{}) {
+	if s.events == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"alert_id":   alert.AlertID,
+		"user_id":    alert.UserID,
+		"status":     alert.Status,
+		"created_at": alert.CreatedAt.Time,
+	}
+	if alert.BookingID.Valid {
+		data["booking_id"] = alert.BookingID.Int64
+	}
+	if alert.ReportID.Valid {
+		data["report_id"] = alert.ReportID.Int64
+	}
+	if alert.Latitude.Valid && alert.Longitude.Valid {
+		data["latitude"] = alert.Latitude.Float64
+		data["longitude"] = alert.Longitude.Float64
+	}
+	if alert.StoodDownAt.Valid {
+		data["stood_down_at"] = alert.StoodDownAt.Time
+		data["stand_down_note"] = alert.StandDownNote.String
+	}
+	for key, value := range extra {
+		data[key] = value
+	}
+
+	userIDs := append([]int64{alert.UserID}, s.alertedUserIDs(ctx, alert)...)
+	s.events.Publish(DomainEvent{Type: eventType, Data: data, UserIDs: userIDs})
+}
+
+// alertedUserIDs returns the users who were sent the SOS push notification.
+func (s *SOSService) alertedUserIDs(ctx context.Context, alert db.SosAlert) []int64 {
+	if !alert.ReportID.Valid {
+		return nil
+	}
+	escalation, err := s.querier.GetIncidentEscalationByReportID(ctx, alert.ReportID.Int64)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.logger.WarnContext(ctx, "Failed to get SOS escalation for event", "alert_id", alert.AlertID, "error", err)
+		}
+		return nil
+	}
+	messages, err := s.querier.ListEscalationMessages(ctx, escalation.EscalationID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to list SOS recipients for event", "alert_id", alert.AlertID, "error", err)
+		return nil
+	}
+	var userIDs []int64
+	for _, message := range messages {
+		if message.Stage == EscalationStagePush && message.UserID.Valid {
+			userIDs = append(userIDs, message.UserID.Int64)
+		}
+	}
+	return userIDs
+}
+
+// notifyStandDown pushes a stand-down notice to everyone who received the SOS.
+func (s *SOSService) notifyStandDown(ctx context.Context, alert db.SosAlert) {
+	escalation, err := s.querier.GetIncidentEscalationByReportID(ctx, alert.ReportID.Int64)
+	if err != nil {
+		s.logger.WarnContext(ctx, "No escalation found for SOS stand-down notice", "alert_id", alert.AlertID, "error", err)
+		return
+	}
+	messages, err := s.querier.ListEscalationMessages(ctx, escalation.EscalationID)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to list SOS recipients for stand-down notice", "alert_id", alert.AlertID, "error", err)
+		return
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"type":  "sos_stood_down",
+		"title": "SOS stood down",
+		"body":  "The SOS alert has been resolved. No further response is needed.",
+		"data": map[string]interface{}{
+			"type":      "sos_stood_down",
+			"alert_id":  alert.AlertID,
+			"report_id": alert.ReportID.Int64,
+		},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to marshal SOS stand-down payload", "alert_id", alert.AlertID, "error", err)
+		return
+	}
+
+	var outboxIDs []int64
+	now := time.Now().UTC()
+	for _, message := range messages {
+		if message.Stage != EscalationStagePush || !message.UserID.Valid {
+			continue
+		}
+		item, err := enqueueOutboxItem(ctx, s.querier, s.logger, db.CreateOutboxItemParams{
+			MessageType: "push",
+			Payload:     sql.NullString{String: string(payload), Valid: true},
+			UserID:      message.UserID,
+			SendAt:      now.Add(-1 * time.Second),
+			Category:    sql.NullString{String: NotificationCategoryUrgent, Valid: true},
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to enqueue SOS stand-down notice", "alert_id", alert.AlertID, "user_id", message.UserID.Int64, "error", err)
+			continue
+		}
+		outboxIDs = append(outboxIDs, item.OutboxID)
+	}
+	s.dispatch(outboxIDs)
+}
@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"night-owls-go/internal/config"
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+var (
+	ErrTipRateLimited      = errors.New("too many tips, try again later")
+	ErrInvalidTipChallenge = errors.New("invalid, expired or already used challenge")
+	ErrInvalidTip          = errors.New("tip message is required and must be at most 2000 characters")
+	ErrInvalidTipStatus    = errors.New("status must be pending, promoted or discarded")
+	ErrTipNotFound         = errors.New("tip not found")
+	ErrTipAlreadyReviewed  = errors.New("tip has already been reviewed")
+)
+
+// Tip review states
+const (
+	TipStatusPending   = "pending"
+	TipStatusPromoted  = "promoted"
+	TipStatusDiscarded = "discarded"
+)
+
+// Tip line abuse controls
+const (
+	MaxTipsPerClient     = 3                // Max tips per client per window
+	MaxTipsPerWindow     = 30               // Max tips from everyone per window, so a botnet cannot flood the review queue
+	TipWindow            = 1 * time.Hour    // Time window for counting submissions
+	TipChallengeLifetime = 10 * time.Minute // How long a proof of work challenge can be redeemed
+	MaxTipMessageLength  = 2000
+	MaxTipContactLength  = 200
+)
+
+// TipChallenge is a proof of work puzzle the tipster's browser solves before
+// submitting: find a nonce such that SHA-256(challenge + ":" + nonce) starts
+// with Difficulty zero bits.
+type TipChallenge struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// TipSubmission is a tip as sent by the public. Contact and location are optional.
+type TipSubmission struct {
+	Message   string
+	Severity  int64
+	Latitude  *float64
+	Longitude *float64
+	Contact   string
+	Challenge string
+	Nonce     string
+	// Honeypot is a form field hidden from people. Anything in it marks a bot.
+	Honeypot string
+}
+
+// TipService runs the anonymous tip line. Tips go into a review queue rather
+// than the reports table; admins promote them to reports or discard them.
+// Submissions are protected by a proof of work challenge, a honeypot field and
+// rate limits keyed on a daily-rotating hash of the client, so no IP address
+// or other detail the tipster did not choose to give is stored.
+type TipService struct {
+	querier    db.Querier
+	secret     []byte
+	difficulty int
+	logger     *slog.Logger
+	watchlist  *WatchlistService
+}
+
+// NewTipService creates a new TipService.
+func NewTipService(querier db.Querier, cfg *config.Config, logger *slog.Logger) *TipService {
+	// Derive a dedicated key so challenges and client hashes never expose the JWT secret
+	secret := sha256.Sum256([]byte("night-owls-tip-line:" + cfg.JWTSecret))
+	return &TipService{
+		querier:    querier,
+		secret:     secret[:],
+		difficulty: cfg.TipProofOfWorkBits,
+		logger:     logger.With("service", "TipService"),
+	}
+}
+
+// SetWatchlistService enables matching promoted tips against the watchlist
+func (s *TipService) SetWatchlistService(watchlist *WatchlistService) {
+	s.watchlist = watchlist
+}
+
+func (s *TipService) sign(parts ...string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewChallenge issues a signed proof of work challenge. Challenges are
+// stateless until redeemed.
+func (s *TipService) NewChallenge(now time.Time) (TipChallenge, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		s.logger.Error("Failed to generate tip challenge", "error", err)
+		return TipChallenge{}, ErrInternalServer
+	}
+
+	expiresAt := now.Add(TipChallengeLifetime).UTC().Truncate(time.Second)
+	payload := hex.EncodeToString(random) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return TipChallenge{
+		Challenge:  payload + "." + s.sign("challenge", payload),
+		Difficulty: s.difficulty,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// verifyChallenge checks the challenge signature and expiry and that the nonce solves it.
+func (s *TipService) verifyChallenge(challenge, nonce string, now time.Time) bool {
+	parts := strings.Split(challenge, ".")
+	if len(parts) != 3 || nonce == "" || len(nonce) > 64 {
+		return false
+	}
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign("challenge", payload))) {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return false
+	}
+	return LeadingZeroBits(sha256.Sum256([]byte(challenge+":"+nonce))) >= s.difficulty
+}
+
+// LeadingZeroBits counts the zero bits at the start of a hash.
+func LeadingZeroBits(hash [32]byte) int {
+	count := 0
+	for _, b := range hash {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+		count += 8
+	}
+	return count
+}
+
+// clientHash identifies a client for rate limiting without storing its IP.
+// The key includes the date, so hashes cannot be linked across days.
+func (s *TipService) clientHash(clientIP string, now time.Time) string {
+	return s.sign("client", now.UTC().Format("2006-01-02"), clientIP)
+}
+
+// SubmitTip validates and stores an anonymous tip. A submission that trips
+// the honeypot is accepted silently but discarded, so bots learn nothing.
+func (s *TipService) SubmitTip(ctx context.Context, submission TipSubmission, clientIP string, now time.Time) error {
+	message := strings.TrimSpace(submission.Message)
+	contact := strings.TrimSpace(submission.Contact)
+	if message == "" || utf8.RuneCountInString(message) > MaxTipMessageLength || utf8.RuneCountInString(contact) > MaxTipContactLength {
+		return ErrInvalidTip
+	}
+	if submission.Severity < 0 || submission.Severity > 2 {
+		return ErrSeverityOutOfRange
+	}
+	if (submission.Latitude == nil) != (submission.Longitude == nil) {
+		return ErrInvalidLocation
+	}
+	if err := ValidateGPSLocation(&GPSLocation{Latitude: submission.Latitude, Longitude: submission.Longitude}, now); err != nil {
+		return err
+	}
+
+	if !s.verifyChallenge(submission.Challenge, submission.Nonce, now) {
+		return ErrInvalidTipChallenge
+	}
+
+	// Rate limits fail open, like the OTP limiter: an outage of the counters
+	// should not silence the tip line, and the proof of work still applies
+	client := s.clientHash(clientIP, now)
+	since := now.Add(-TipWindow)
+	if count, err := s.querier.CountTipSubmissionsByClient(ctx, db.CountTipSubmissionsByClientParams{ClientHash: client, SubmittedAt: since}); err != nil {
+		s.logger.WarnContext(ctx, "Failed to count tip submissions by client", "error", err)
+	} else if count >= MaxTipsPerClient {
+		return ErrTipRateLimited
+	}
+	if count, err := s.querier.CountTipSubmissions(ctx, since); err != nil {
+		s.logger.WarnContext(ctx, "Failed to count tip submissions", "error", err)
+	} else if count >= MaxTipsPerWindow {
+		s.logger.WarnContext(ctx, "Tip line global rate limit reached", "count", count)
+		return ErrTipRateLimited
+	}
+
+	challengeHash := sha256.Sum256([]byte(submission.Challenge))
+	err := s.querier.CreateTipSubmission(ctx, db.CreateTipSubmissionParams{
+		ClientHash:    client,
+		ChallengeHash: hex.EncodeToString(challengeHash[:]),
+		SubmittedAt:   now,
+	})
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return ErrInvalidTipChallenge
+		}
+		s.logger.ErrorContext(ctx, "Failed to record tip submission", "error", err)
+		return ErrInternalServer
+	}
+
+	if submission.Honeypot != "" {
+		s.logger.InfoContext(ctx, "Discarded tip that filled in the honeypot field")
+		return nil
+	}
+
+	params := db.CreateTipParams{
+		Message:  message,
+		Severity: submission.Severity,
+		Contact:  sql.NullString{String: contact, Valid: contact != ""},
+	}
+	if submission.Latitude != nil {
+		params.Latitude = sql.NullFloat64{Float64: *submission.Latitude, Valid: true}
+		params.Longitude = sql.NullFloat64{Float64: *submission.Longitude, Valid: true}
+	}
+	tip, err := s.querier.CreateTip(ctx, params)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create tip", "error", err)
+		return ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Tip received", "tip_id", tip.TipID, "severity", tip.Severity)
+	return nil
+}
+
+// ListTips returns tips in the given review state, newest first.
+func (s *TipService) ListTips(ctx context.Context, status string, limit, offset int64) ([]db.Tip, error) {
+	if status != TipStatusPending && status != TipStatusPromoted && status != TipStatusDiscarded {
+		return nil, ErrInvalidTipStatus
+	}
+	tips, err := s.querier.ListTipsByStatus(ctx, db.ListTipsByStatusParams{Status: status, Limit: limit, Offset: offset})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list tips", "status", status, "error", err)
+		return nil, ErrInternalServer
+	}
+	return tips, nil
+}
+
+// GetTip returns a single tip.
+func (s *TipService) GetTip(ctx context.Context, tipID int64) (db.Tip, error) {
+	tip, err := s.querier.GetTip(ctx, tipID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Tip{}, ErrTipNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get tip", "tip_id", tipID, "error", err)
+		return db.Tip{}, ErrInternalServer
+	}
+	return tip, nil
+}
+
+// getPendingTip returns a tip that is still awaiting review.
+func (s *TipService) getPendingTip(ctx context.Context, tipID int64) (db.Tip, error) {
+	tip, err := s.GetTip(ctx, tipID)
+	if err != nil {
+		return db.Tip{}, err
+	}
+	if tip.Status != TipStatusPending {
+		return db.Tip{}, ErrTipAlreadyReviewed
+	}
+	return tip, nil
+}
+
+// PromoteTip turns a pending tip into an off-shift report filed by the
+// reviewing admin, since every report needs a reporter or a booking. The admin
+// may correct the severity. The tipster's contact details stay on the tip and
+// are not copied to the report.
+func (s *TipService) PromoteTip(ctx context.Context, tipID, adminUserID int64, severity *int64, note string) (db.Tip, db.Report, error) {
+	tip, err := s.getPendingTip(ctx, tipID)
+	if err != nil {
+		return db.Tip{}, db.Report{}, err
+	}
+
+	reportSeverity := tip.Severity
+	if severity != nil {
+		if *severity < 0 || *severity > 2 {
+			return db.Tip{}, db.Report{}, ErrSeverityOutOfRange
+		}
+		reportSeverity = *severity
+	}
+
+	report, err := s.querier.CreateReport(ctx, db.CreateReportParams{
+		UserID:    sql.NullInt64{Int64: adminUserID, Valid: true},
+		Severity:  reportSeverity,
+		Message:   sql.NullString{String: tip.Message, Valid: true},
+		Latitude:  tip.Latitude,
+		Longitude: tip.Longitude,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create report from tip", "tip_id", tipID, "error", err)
+		return db.Tip{}, db.Report{}, ErrInternalServer
+	}
+
+	reviewed, err := s.review(ctx, tipID, TipStatusPromoted, sql.NullInt64{Int64: report.ReportID, Valid: true}, adminUserID, note)
+	if err != nil {
+		// Another admin reviewed the tip first; drop the report we just made
+		if delErr := s.querier.DeleteReport(ctx, report.ReportID); delErr != nil {
+			s.logger.ErrorContext(ctx, "Failed to remove report for tip reviewed concurrently", "report_id", report.ReportID, "error", delErr)
+		}
+		return db.Tip{}, db.Report{}, err
+	}
+
+	if s.watchlist != nil {
+		if _, err := s.watchlist.MatchReport(ctx, report); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to match promoted tip against watchlist", "report_id", report.ReportID, "error", err)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Tip promoted to report", "tip_id", tipID, "report_id", report.ReportID, "admin_user_id", adminUserID)
+	return reviewed, report, nil
+}
+
+// DiscardTip closes a pending tip without creating a report.
+func (s *TipService) DiscardTip(ctx context.Context, tipID, adminUserID int64, note string) (db.Tip, error) {
+	if _, err := s.getPendingTip(ctx, tipID); err != nil {
+		return db.Tip{}, err
+	}
+
+	tip, err := s.review(ctx, tipID, TipStatusDiscarded, sql.NullInt64{}, adminUserID, note)
+	if err != nil {
+		return db.Tip{}, err
+	}
+
+	s.logger.InfoContext(ctx, "Tip discarded", "tip_id", tipID, "admin_user_id", adminUserID)
+	return tip, nil
+}
+
+// review records the outcome of a review if the tip is still pending.
+func (s *TipService) review(ctx context.Context, tipID int64, status string, reportID sql.NullInt64, adminUserID int64, note string) (db.Tip, error) {
+	note = strings.TrimSpace(note)
+	tip, err := s.querier.ReviewTip(ctx, db.ReviewTipParams{
+		Status:           status,
+		ReportID:         reportID,
+		ReviewNote:       sql.NullString{String: note, Valid: note != ""},
+		ReviewedByUserID: sql.NullInt64{Int64: adminUserID, Valid: true},
+		ReviewedAt:       sql.NullTime{Time: time.Now(), Valid: true},
+		TipID:            tipID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Tip{}, ErrTipAlreadyReviewed
+		}
+		s.logger.ErrorContext(ctx, "Failed to review tip", "tip_id", tipID, "error", err)
+		return db.Tip{}, ErrInternalServer
+	}
+	return tip, nil
+}
+
+// CleanupOldSubmissions removes tip rate limiting records older than maxAge.
+func (s *TipService) CleanupOldSubmissions(ctx context.Context, maxAge time.Duration) (int64, error) {
+	deleted, err := s.querier.CleanupOldTipSubmissions(ctx, time.Now().Add(-maxAge))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to clean up old tip submissions", "error", err)
+		return 0, err
+	}
+	if deleted > 0 {
+		s.logger.InfoContext(ctx, "Cleaned up old tip submissions", "deleted_count", deleted)
+	}
+	return deleted, nil
+}
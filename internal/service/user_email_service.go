@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/mail"
+	"strings"
+	"time"
+
+	"night-owls-go/internal/auth"
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+var (
+	ErrInvalidEmail               = errors.New("invalid email address")
+	ErrEmailInUse                 = errors.New("email address is already in use")
+	ErrNoPendingEmail             = errors.New("no email address is awaiting confirmation")
+	ErrEmailVerificationFailed    = errors.New("email verification failed")
+	ErrEmailVerificationThrottled = errors.New("email verification requested too recently")
+)
+
+const (
+	emailVerificationValidity    = 30 * time.Minute
+	emailVerificationResendAfter = time.Minute
+	maxEmailVerificationAttempts = 5
+	maxEmailLength               = 254
+)
+
+// UserEmailService manages the optional email address users can receive
+// notifications on. An address is only used once the user has confirmed it
+// with a code sent to that address.
+type UserEmailService struct {
+	querier db.Querier
+	logger  *slog.Logger
+}
+
+// NewUserEmailService creates a new UserEmailService.
+func NewUserEmailService(querier db.Querier, logger *slog.Logger) *UserEmailService {
+	return &UserEmailService{
+		querier: querier,
+		logger:  logger.With("service", "UserEmailService"),
+	}
+}
+
+// GetEmail returns the user's confirmed and pending email addresses.
+func (s *UserEmailService) GetEmail(ctx context.Context, userID int64) (db.GetUserEmailRow, error) {
+	row, err := s.querier.GetUserEmail(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.GetUserEmailRow{}, ErrUserNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get user email", "user_id", userID, "error", err)
+		return db.GetUserEmailRow{}, ErrInternalServer
+	}
+	return row, nil
+}
+
+// RequestVerification records address as the user's pending email and sends
+// a confirmation code to it. A confirmed address stays in use until the new
+// one is confirmed.
+func (s *UserEmailService) RequestVerification(ctx context.Context, userID int64, address string) (db.GetUserEmailRow, error) {
+	email, err := normalizeEmail(address)
+	if err != nil {
+		return db.GetUserEmailRow{}, err
+	}
+
+	current, err := s.GetEmail(ctx, userID)
+	if err != nil {
+		return db.GetUserEmailRow{}, err
+	}
+	if current.EmailVerificationExpiresAt.Valid {
+		sentAt := current.EmailVerificationExpiresAt.Time.Add(-emailVerificationValidity)
+		if time.Since(sentAt) < emailVerificationResendAfter {
+			return db.GetUserEmailRow{}, ErrEmailVerificationThrottled
+		}
+	}
+
+	code, err := auth.GenerateOTP()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to generate email verification code", "user_id", userID, "error", err)
+		return db.GetUserEmailRow{}, ErrInternalServer
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"code":               code,
+		"expires_in_minutes": int(emailVerificationValidity / time.Minute),
+	})
+	if err != nil {
+		return db.GetUserEmailRow{}, ErrInternalServer
+	}
+
+	if err := s.querier.SetUserPendingEmail(ctx, db.SetUserPendingEmailParams{
+		PendingEmail:               sql.NullString{String: email, Valid: true},
+		EmailVerificationCodeHash:  sql.NullString{String: hashEmailVerificationCode(code), Valid: true},
+		EmailVerificationExpiresAt: sql.NullTime{Time: time.Now().UTC().Add(emailVerificationValidity), Valid: true},
+		UserID:                     userID,
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to store pending email", "user_id", userID, "error", err)
+		return db.GetUserEmailRow{}, ErrInternalServer
+	}
+
+	if _, err := enqueueOutboxItem(ctx, s.querier, s.logger, db.CreateOutboxItemParams{
+		MessageType: OutboxMessageEmailVerification,
+		Recipient:   email,
+		Payload:     sql.NullString{String: string(payload), Valid: true},
+		UserID:      sql.NullInt64{Int64: userID, Valid: true},
+		SendAt:      immediateSendAt(),
+	}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to queue email verification", "user_id", userID, "error", err)
+		return db.GetUserEmailRow{}, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Email verification requested", "user_id", userID)
+	return s.GetEmail(ctx, userID)
+}
+
+// Verify confirms the pending address with the code that was emailed to it.
+// The pending address is dropped after too many wrong codes.
+func (s *UserEmailService) Verify(ctx context.Context, userID int64, code string) (db.GetUserEmailRow, error) {
+	current, err := s.GetEmail(ctx, userID)
+	if err != nil {
+		return db.GetUserEmailRow{}, err
+	}
+	if !current.PendingEmail.Valid {
+		return db.GetUserEmailRow{}, ErrNoPendingEmail
+	}
+
+	expired := !current.EmailVerificationExpiresAt.Valid || time.Now().After(current.EmailVerificationExpiresAt.Time)
+	matches := subtle.ConstantTimeCompare([]byte(hashEmailVerificationCode(strings.TrimSpace(code))), []byte(current.EmailVerificationCodeHash.String)) == 1
+	if expired || !matches {
+		attempts, err := s.querier.RecordEmailVerificationFailure(ctx, userID)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record email verification failure", "user_id", userID, "error", err)
+			return db.GetUserEmailRow{}, ErrInternalServer
+		}
+		if attempts >= maxEmailVerificationAttempts {
+			s.logger.WarnContext(ctx, "Too many wrong email verification codes, dropping pending email", "user_id", userID)
+			if err := s.querier.SetUserPendingEmail(ctx, db.SetUserPendingEmailParams{UserID: userID}); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to drop pending email", "user_id", userID, "error", err)
+			}
+		}
+		return db.GetUserEmailRow{}, ErrEmailVerificationFailed
+	}
+
+	if _, err := s.querier.ConfirmUserEmail(ctx, userID); err != nil {
+		if isUniqueConstraintError(err) {
+			return db.GetUserEmailRow{}, ErrEmailInUse
+		}
+		s.logger.ErrorContext(ctx, "Failed to confirm user email", "user_id", userID, "error", err)
+		return db.GetUserEmailRow{}, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "User email confirmed", "user_id", userID)
+	return s.GetEmail(ctx, userID)
+}
+
+// RemoveEmail clears the user's confirmed and pending addresses.
+func (s *UserEmailService) RemoveEmail(ctx context.Context, userID int64) error {
+	if err := s.querier.ClearUserEmail(ctx, userID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to clear user email", "user_id", userID, "error", err)
+		return ErrInternalServer
+	}
+	s.logger.InfoContext(ctx, "User email removed", "user_id", userID)
+	return nil
+}
+
+// normalizeEmail checks that address is a bare email address and lower-cases it.
+func normalizeEmail(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if address == "" || len(address) > maxEmailLength {
+		return "", ErrInvalidEmail
+	}
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address || !strings.Contains(address[strings.LastIndex(address, "@"):], ".") {
+		return "", ErrInvalidEmail
+	}
+	return strings.ToLower(address), nil
+}
+
+func hashEmailVerificationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -208,8 +208,8 @@ func (s *UserService) RegisterOrLoginUser(ctx context.Context, phone string, nam
 
 		// Queue OTP message to outbox for mock SMS
 		outboxPayload := fmt.Sprintf(`{"otp": "%s"}`, otp)
-		_, err = s.querier.CreateOutboxItem(ctx, db.CreateOutboxItemParams{
-			MessageType: "OTP_VERIFICATION",
+		_, err = enqueueOutboxItem(ctx, s.querier, s.logger, db.CreateOutboxItemParams{
+			MessageType: OutboxMessageOTPVerification,
 			Recipient:   phone,
 			Payload:     sql.NullString{String: outboxPayload, Valid: true},
 			SendAt:      immediateSendAt(),
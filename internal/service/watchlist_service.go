@@ -0,0 +1,552 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"night-owls-go/internal/config"
+	db "night-owls-go/internal/db/sqlc_generated"
+)
+
+var (
+	ErrWatchlistEntryNotFound   = errors.New("watchlist entry not found")
+	ErrWatchlistPhotoNotFound   = errors.New("watchlist photo not found")
+	ErrInvalidWatchlistEntry    = errors.New("invalid watchlist entry")
+	ErrInvalidWatchlistPhoto    = errors.New("photo must be a JPEG, PNG or WebP image of at most 10MB")
+	errWatchlistPhotoMissingDir = errors.New("watchlist photo directory is not configured")
+)
+
+// Watchlist entry kinds
+const (
+	WatchlistKindVehicle = "vehicle"
+	WatchlistKindPerson  = "person"
+)
+
+// What in a report linked it to a watchlist entry
+const (
+	WatchlistMatchRegistration = "registration"
+	WatchlistMatchKeyword      = "keyword"
+)
+
+const (
+	MaxWatchlistLabelLength   = 100
+	MaxWatchlistKeywords      = 20
+	MinWatchlistKeywordLength = 3
+	MinRegistrationLength     = 4 // Shorter plates would match too much unrelated text
+	MaxWatchlistPhotoSize     = 10 * 1024 * 1024
+)
+
+// WatchlistEntryInput is an entry as created or edited by an admin.
+type WatchlistEntryInput struct {
+	Kind         string
+	Label        string
+	Registration string
+	Description  string
+	Notes        string
+	Keywords     []string
+	IsActive     bool
+}
+
+// WatchlistHit is a watchlist entry a report was newly linked to.
+type WatchlistHit struct {
+	Entry       db.WatchlistEntry
+	MatchedOn   string
+	MatchedTerm string
+}
+
+// WatchlistService manages the admin watchlist of vehicles and people of
+// interest and matches new reports against it. A report that mentions an
+// active entry's registration or one of its keywords, in its message or its
+// category fields, is linked to the entry and on-duty owls and admins are
+// alerted.
+type WatchlistService struct {
+	querier  db.Querier
+	photoDir string
+	logger   *slog.Logger
+	events   *EventBroker
+}
+
+// NewWatchlistService creates a new WatchlistService.
+func NewWatchlistService(querier db.Querier, cfg *config.Config, logger *slog.Logger) *WatchlistService {
+	return &WatchlistService{
+		querier:  querier,
+		photoDir: cfg.WatchlistPhotoDir,
+		logger:   logger.With("service", "WatchlistService"),
+	}
+}
+
+// SetEventBroker enables publishing watchlist matches to the real-time stream
+func (s *WatchlistService) SetEventBroker(events *EventBroker) {
+	s.events = events
+}
+
+// NormaliseRegistration reduces a vehicle registration to upper case letters
+// and digits, so "ca 123-456" and "CA123456" compare equal.
+func NormaliseRegistration(registration string) string {
+	var sb strings.Builder
+	for _, r := range registration {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return sb.String()
+}
+
+// matchWords splits text into lower case words for keyword matching.
+func matchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// containsPhrase reports whether phrase appears in words as consecutive words.
+func containsPhrase(words, phrase []string) bool {
+	if len(phrase) == 0 {
+		return false
+	}
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		match := true
+		for j, word := range phrase {
+			if words[i+j] != word {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// reportMatchText is the report's message followed by the values of its
+// category fields, which is where structured details like a registration end up.
+func reportMatchText(report db.Report) string {
+	parts := []string{report.Message.String}
+	if report.CategoryFields.Valid && report.CategoryFields.String != "" {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(report.CategoryFields.String), &fields); err == nil {
+			for _, value := range fields {
+				switch v := value.(type) {
+				case string:
+					parts = append(parts, v)
+				case float64, bool:
+					parts = append(parts, fmt.Sprint(v))
+				}
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// matchEntry reports the first thing in text that matches the entry.
+func matchEntry(entry db.WatchlistEntry, registrations string, words []string) (string, string, bool) {
+	if entry.Registration.Valid && entry.Registration.String != "" && strings.Contains(registrations, entry.Registration.String) {
+		return WatchlistMatchRegistration, entry.Registration.String, true
+	}
+	for _, keyword := range decodeKeywords(entry.Keywords) {
+		if containsPhrase(words, matchWords(keyword)) {
+			return WatchlistMatchKeyword, keyword, true
+		}
+	}
+	return "", "", false
+}
+
+func decodeKeywords(raw string) []string {
+	var keywords []string
+	if err := json.Unmarshal([]byte(raw), &keywords); err != nil {
+		return nil
+	}
+	return keywords
+}
+
+// DecodeWatchlistKeywords returns an entry's keywords.
+func DecodeWatchlistKeywords(entry db.WatchlistEntry) []string {
+	keywords := decodeKeywords(entry.Keywords)
+	if keywords == nil {
+		return []string{}
+	}
+	return keywords
+}
+
+// validateWatchlistInput checks and normalises an entry, returning its encoded keywords.
+func validateWatchlistInput(input *WatchlistEntryInput) (string, error) {
+	input.Kind = strings.TrimSpace(input.Kind)
+	if input.Kind != WatchlistKindVehicle && input.Kind != WatchlistKindPerson {
+		return "", fmt.Errorf("%w: kind must be vehicle or person", ErrInvalidWatchlistEntry)
+	}
+	input.Label = strings.TrimSpace(input.Label)
+	if input.Label == "" || utf8.RuneCountInString(input.Label) > MaxWatchlistLabelLength {
+		return "", fmt.Errorf("%w: label is required and must be at most %d characters", ErrInvalidWatchlistEntry, MaxWatchlistLabelLength)
+	}
+
+	input.Registration = NormaliseRegistration(input.Registration)
+	if input.Registration != "" {
+		if input.Kind != WatchlistKindVehicle {
+			return "", fmt.Errorf("%w: only vehicles have a registration", ErrInvalidWatchlistEntry)
+		}
+		if len(input.Registration) < MinRegistrationLength {
+			return "", fmt.Errorf("%w: registration must have at least %d letters or digits", ErrInvalidWatchlistEntry, MinRegistrationLength)
+		}
+	}
+	input.Description = strings.TrimSpace(input.Description)
+	input.Notes = strings.TrimSpace(input.Notes)
+
+	seen := map[string]bool{}
+	keywords := []string{}
+	for _, keyword := range input.Keywords {
+		keyword = strings.Join(matchWords(keyword), " ")
+		if keyword == "" || seen[keyword] {
+			continue
+		}
+		if utf8.RuneCountInString(keyword) < MinWatchlistKeywordLength {
+			return "", fmt.Errorf("%w: keywords must be at least %d characters", ErrInvalidWatchlistEntry, MinWatchlistKeywordLength)
+		}
+		seen[keyword] = true
+		keywords = append(keywords, keyword)
+	}
+	if len(keywords) > MaxWatchlistKeywords {
+		return "", fmt.Errorf("%w: at most %d keywords", ErrInvalidWatchlistEntry, MaxWatchlistKeywords)
+	}
+	input.Keywords = keywords
+
+	encoded, err := json.Marshal(keywords)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func nullableString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// CreateEntry adds a watchlist entry.
+func (s *WatchlistService) CreateEntry(ctx context.Context, input WatchlistEntryInput, adminUserID int64) (db.WatchlistEntry, error) {
+	keywords, err := validateWatchlistInput(&input)
+	if err != nil {
+		return db.WatchlistEntry{}, err
+	}
+
+	entry, err := s.querier.CreateWatchlistEntry(ctx, db.CreateWatchlistEntryParams{
+		Kind:            input.Kind,
+		Label:           input.Label,
+		Registration:    nullableString(input.Registration),
+		Description:     nullableString(input.Description),
+		Notes:           nullableString(input.Notes),
+		Keywords:        keywords,
+		IsActive:        input.IsActive,
+		CreatedByUserID: sql.NullInt64{Int64: adminUserID, Valid: true},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create watchlist entry", "error", err)
+		return db.WatchlistEntry{}, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Watchlist entry created", "entry_id", entry.EntryID, "kind", entry.Kind, "admin_user_id", adminUserID)
+	return entry, nil
+}
+
+// UpdateEntry replaces a watchlist entry's details.
+func (s *WatchlistService) UpdateEntry(ctx context.Context, entryID int64, input WatchlistEntryInput) (db.WatchlistEntry, error) {
+	keywords, err := validateWatchlistInput(&input)
+	if err != nil {
+		return db.WatchlistEntry{}, err
+	}
+
+	entry, err := s.querier.UpdateWatchlistEntry(ctx, db.UpdateWatchlistEntryParams{
+		Kind:         input.Kind,
+		Label:        input.Label,
+		Registration: nullableString(input.Registration),
+		Description:  nullableString(input.Description),
+		Notes:        nullableString(input.Notes),
+		Keywords:     keywords,
+		IsActive:     input.IsActive,
+		EntryID:      entryID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.WatchlistEntry{}, ErrWatchlistEntryNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to update watchlist entry", "entry_id", entryID, "error", err)
+		return db.WatchlistEntry{}, ErrInternalServer
+	}
+	return entry, nil
+}
+
+// GetEntry returns a watchlist entry.
+func (s *WatchlistService) GetEntry(ctx context.Context, entryID int64) (db.WatchlistEntry, error) {
+	entry, err := s.querier.GetWatchlistEntry(ctx, entryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.WatchlistEntry{}, ErrWatchlistEntryNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get watchlist entry", "entry_id", entryID, "error", err)
+		return db.WatchlistEntry{}, ErrInternalServer
+	}
+	return entry, nil
+}
+
+// ListEntries returns every watchlist entry, active entries first.
+func (s *WatchlistService) ListEntries(ctx context.Context) ([]db.ListWatchlistEntriesRow, error) {
+	entries, err := s.querier.ListWatchlistEntries(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list watchlist entries", "error", err)
+		return nil, ErrInternalServer
+	}
+	return entries, nil
+}
+
+// GetTimeline returns the reports linked to an entry, newest first, and the
+// entry's photos.
+func (s *WatchlistService) GetTimeline(ctx context.Context, entryID int64) ([]db.ListWatchlistMatchesByEntryRow, []db.WatchlistPhoto, error) {
+	matches, err := s.querier.ListWatchlistMatchesByEntry(ctx, entryID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list watchlist matches", "entry_id", entryID, "error", err)
+		return nil, nil, ErrInternalServer
+	}
+	photos, err := s.querier.ListWatchlistPhotos(ctx, entryID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list watchlist photos", "entry_id", entryID, "error", err)
+		return nil, nil, ErrInternalServer
+	}
+	return matches, photos, nil
+}
+
+// DeleteEntry removes an entry, its photos and its links to reports. The
+// reports themselves are kept.
+func (s *WatchlistService) DeleteEntry(ctx context.Context, entryID int64) (db.WatchlistEntry, error) {
+	entry, err := s.GetEntry(ctx, entryID)
+	if err != nil {
+		return db.WatchlistEntry{}, err
+	}
+	photos, err := s.querier.ListWatchlistPhotos(ctx, entryID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list watchlist photos for deletion", "entry_id", entryID, "error", err)
+		return db.WatchlistEntry{}, ErrInternalServer
+	}
+
+	if _, err := s.querier.DeleteWatchlistEntry(ctx, entryID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete watchlist entry", "entry_id", entryID, "error", err)
+		return db.WatchlistEntry{}, ErrInternalServer
+	}
+	for _, photo := range photos {
+		if err := os.Remove(photo.StoragePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			s.logger.WarnContext(ctx, "Failed to delete watchlist photo file", "path", photo.StoragePath, "error", err)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "Watchlist entry deleted", "entry_id", entryID, "photo_count", len(photos))
+	return entry, nil
+}
+
+// AddPhoto stores a photo of the vehicle or person on an entry. The image
+// type is taken from the file's content rather than what the client claims.
+func (s *WatchlistService) AddPhoto(ctx context.Context, entryID, adminUserID int64, photo io.Reader) (db.WatchlistPhoto, error) {
+	if _, err := s.GetEntry(ctx, entryID); err != nil {
+		return db.WatchlistPhoto{}, err
+	}
+	if s.photoDir == "" {
+		s.logger.ErrorContext(ctx, "Cannot store watchlist photo", "error", errWatchlistPhotoMissingDir)
+		return db.WatchlistPhoto{}, ErrInternalServer
+	}
+
+	data, err := io.ReadAll(io.LimitReader(photo, MaxWatchlistPhotoSize+1))
+	if err != nil {
+		return db.WatchlistPhoto{}, ErrInvalidWatchlistPhoto
+	}
+	if len(data) == 0 || len(data) > MaxWatchlistPhotoSize {
+		return db.WatchlistPhoto{}, ErrInvalidWatchlistPhoto
+	}
+	mimeType := http.DetectContentType(data)
+	if !isValidImageType(mimeType) {
+		return db.WatchlistPhoto{}, ErrInvalidWatchlistPhoto
+	}
+
+	checksum := fmt.Sprintf("%x", sha256.Sum256(data))
+	filename := fmt.Sprintf("%d_%d_%s.%s", entryID, time.Now().Unix(), checksum[:8], getFileExtension(mimeType))
+	dir := filepath.Join(s.photoDir, fmt.Sprint(entryID))
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create watchlist photo directory", "dir", dir, "error", err)
+		return db.WatchlistPhoto{}, ErrInternalServer
+	}
+	storagePath := filepath.Join(dir, filename)
+	if err := os.WriteFile(storagePath, data, 0600); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save watchlist photo", "path", storagePath, "error", err)
+		return db.WatchlistPhoto{}, ErrInternalServer
+	}
+
+	stored, err := s.querier.CreateWatchlistPhoto(ctx, db.CreateWatchlistPhotoParams{
+		EntryID:          entryID,
+		Filename:         filename,
+		MimeType:         mimeType,
+		FileSizeBytes:    int64(len(data)),
+		StoragePath:      storagePath,
+		UploadedByUserID: sql.NullInt64{Int64: adminUserID, Valid: true},
+	})
+	if err != nil {
+		if removeErr := os.Remove(storagePath); removeErr != nil {
+			s.logger.WarnContext(ctx, "Failed to clean up watchlist photo after database error", "path", storagePath, "error", removeErr)
+		}
+		s.logger.ErrorContext(ctx, "Failed to save watchlist photo metadata", "entry_id", entryID, "error", err)
+		return db.WatchlistPhoto{}, ErrInternalServer
+	}
+
+	s.logger.InfoContext(ctx, "Watchlist photo added", "entry_id", entryID, "photo_id", stored.PhotoID, "size_bytes", stored.FileSizeBytes)
+	return stored, nil
+}
+
+// GetPhoto returns a photo belonging to an entry.
+func (s *WatchlistService) GetPhoto(ctx context.Context, entryID, photoID int64) (db.WatchlistPhoto, error) {
+	photo, err := s.querier.GetWatchlistPhoto(ctx, db.GetWatchlistPhotoParams{PhotoID: photoID, EntryID: entryID})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.WatchlistPhoto{}, ErrWatchlistPhotoNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to get watchlist photo", "photo_id", photoID, "error", err)
+		return db.WatchlistPhoto{}, ErrInternalServer
+	}
+	return photo, nil
+}
+
+// DeletePhoto removes a photo from an entry.
+func (s *WatchlistService) DeletePhoto(ctx context.Context, entryID, photoID int64) error {
+	photo, err := s.GetPhoto(ctx, entryID, photoID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.querier.DeleteWatchlistPhoto(ctx, db.DeleteWatchlistPhotoParams{PhotoID: photoID, EntryID: entryID}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete watchlist photo", "photo_id", photoID, "error", err)
+		return ErrInternalServer
+	}
+	if err := os.Remove(photo.StoragePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		s.logger.WarnContext(ctx, "Failed to delete watchlist photo file", "path", photo.StoragePath, "error", err)
+	}
+	return nil
+}
+
+// MatchReport links a new report to every active entry it mentions and alerts
+// on-duty owls and admins about each new link.
+func (s *WatchlistService) MatchReport(ctx context.Context, report db.Report) ([]WatchlistHit, error) {
+	entries, err := s.querier.ListActiveWatchlistEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	text := reportMatchText(report)
+	registrations := NormaliseRegistration(text)
+	words := matchWords(text)
+
+	var hits []WatchlistHit
+	for _, entry := range entries {
+		matchedOn, term, ok := matchEntry(entry, registrations, words)
+		if !ok {
+			continue
+		}
+		linked, err := s.querier.CreateWatchlistMatch(ctx, db.CreateWatchlistMatchParams{
+			EntryID:     entry.EntryID,
+			ReportID:    report.ReportID,
+			MatchedOn:   matchedOn,
+			MatchedTerm: term,
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to link report to watchlist entry", "report_id", report.ReportID, "entry_id", entry.EntryID, "error", err)
+			continue
+		}
+		if linked > 0 {
+			hits = append(hits, WatchlistHit{Entry: entry, MatchedOn: matchedOn, MatchedTerm: term})
+		}
+	}
+
+	if len(hits) > 0 {
+		s.alert(ctx, report, hits)
+	}
+	return hits, nil
+}
+
+// alert pushes each new match to the owls on duty and all admins, and
+// publishes it to the real-time stream.
+func (s *WatchlistService) alert(ctx context.Context, report db.Report, hits []WatchlistHit) {
+	now := time.Now().UTC()
+	recipients, _, err := onDutyAndAdminUserIDs(ctx, s.querier, now)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to resolve watchlist alert recipients", "report_id", report.ReportID, "error", err)
+		return
+	}
+
+	for _, hit := range hits {
+		data := map[string]interface{}{
+			"type":         "watchlist_match",
+			"entry_id":     hit.Entry.EntryID,
+			"kind":         hit.Entry.Kind,
+			"label":        hit.Entry.Label,
+			"report_id":    report.ReportID,
+			"severity":     report.Severity,
+			"matched_on":   hit.MatchedOn,
+			"matched_term": hit.MatchedTerm,
+		}
+		if hit.Entry.Registration.Valid {
+			data["registration"] = hit.Entry.Registration.String
+		}
+		if report.Latitude.Valid && report.Longitude.Valid {
+			data["latitude"] = report.Latitude.Float64
+			data["longitude"] = report.Longitude.Float64
+		}
+
+		body := "A new report mentions this watchlist entry."
+		if hit.Entry.Description.Valid {
+			body = truncateRunes(hit.Entry.Description.String, escalationSMSMessageLimit)
+		}
+		payload, err := json.Marshal(map[string]interface{}{
+			"type":  "watchlist_match",
+			"title": "Watchlist: " + hit.Entry.Label,
+			"body":  body,
+			"data":  data,
+		})
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to marshal watchlist alert", "entry_id", hit.Entry.EntryID, "error", err)
+			continue
+		}
+
+		var alerted []int64
+		for _, userID := range recipients {
+			if report.UserID.Valid && userID == report.UserID.Int64 {
+				continue // The reporter already knows what they saw
+			}
+			if _, err := enqueueOutboxItem(ctx, s.querier, s.logger, db.CreateOutboxItemParams{
+				MessageType: "push",
+				Payload:     sql.NullString{String: string(payload), Valid: true},
+				UserID:      sql.NullInt64{Int64: userID, Valid: true},
+				SendAt:      now.Add(-1 * time.Second),
+				Category:    sql.NullString{String: NotificationCategoryReportUpdates, Valid: true},
+			}); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to enqueue watchlist alert", "entry_id", hit.Entry.EntryID, "user_id", userID, "error", err)
+				continue
+			}
+			alerted = append(alerted, userID)
+		}
+
+		s.events.Publish(DomainEvent{Type: EventWatchlistMatch, Data: data, UserIDs: alerted})
+		s.logger.InfoContext(ctx, "Report matched watchlist entry",
+			"report_id", report.ReportID,
+			"entry_id", hit.Entry.EntryID,
+			"matched_on", hit.MatchedOn,
+			"alerted_count", len(alerted))
+	}
+}
@@ -157,8 +157,9 @@ func GenerateBookingICS(booking db.Booking, scheduleName string) ICSData {
 	}
 }
 
-// GenerateUserCalendarFeed creates a multi-event ICS feed for WebCal subscription
-func GenerateUserCalendarFeed(bookings []db.ListBookingsByUserIDWithScheduleRow, userID int64) ICSData {
+// GenerateUserCalendarFeed creates a multi-event ICS feed for WebCal subscription.
+// handovers maps booking IDs to the handover notes left for that shift.
+func GenerateUserCalendarFeed(bookings []db.ListBookingsByUserIDWithScheduleRow, handovers map[int64][]db.ListIncomingHandoverNotesRow, userID int64) ICSData {
 	if len(bookings) == 0 {
 		// Empty calendar
 		content := generateEmptyCalendar(userID)
@@ -184,7 +185,7 @@ func GenerateUserCalendarFeed(bookings []db.ListBookingsByUserIDWithScheduleRow,
 	for _, booking := range bookings {
 		// Only include future shifts in WebCal feed
 		if booking.ShiftStart.After(time.Now()) {
-			event := bookingRowToCalendarEvent(booking)
+			event := bookingRowToCalendarEvent(booking, handovers[booking.BookingID])
 			eventContent := generateVEvent(event)
 			sb.WriteString(eventContent)
 		}
@@ -200,7 +201,7 @@ func GenerateUserCalendarFeed(bookings []db.ListBookingsByUserIDWithScheduleRow,
 }
 
 // bookingRowToCalendarEvent converts a booking row to a calendar event
-func bookingRowToCalendarEvent(booking db.ListBookingsByUserIDWithScheduleRow) CalendarEvent {
+func bookingRowToCalendarEvent(booking db.ListBookingsByUserIDWithScheduleRow, handovers []db.ListIncomingHandoverNotesRow) CalendarEvent {
 	// Calculate shift end time (assuming 2-hour shifts if not specified)
 	shiftEnd := booking.ShiftEnd
 	if shiftEnd.IsZero() {
@@ -219,6 +220,8 @@ func bookingRowToCalendarEvent(booking db.ListBookingsByUserIDWithScheduleRow) C
 		description.WriteString(fmt.Sprintf("👥 Buddy: %s\\n", escapeICSText(booking.BuddyName.String)))
 	}
 
+	writeHandoverNotes(&description, handovers)
+
 	description.WriteString("\\n📱 Check in through the Night Owls app when your shift starts.")
 	description.WriteString(fmt.Sprintf("\\n🔗 App: %s", CalendarAppURL))
 	// Generate unique UID for this booking
@@ -239,6 +242,38 @@ func bookingRowToCalendarEvent(booking db.ListBookingsByUserIDWithScheduleRow) C
 	}
 }
 
+// writeHandoverNotes adds the notes left by earlier patrols to an event description
+func writeHandoverNotes(description *strings.Builder, handovers []db.ListIncomingHandoverNotesRow) {
+	if len(handovers) == 0 {
+		return
+	}
+
+	description.WriteString("\\n📝 Handover from previous patrols:\\n")
+	for _, note := range handovers {
+		author := note.UserName
+		if author == "" {
+			author = "Previous patrol"
+		}
+		description.WriteString(fmt.Sprintf("%s (%s, %s - %s)\\n",
+			escapeICSText(author),
+			escapeICSText(note.ScheduleName),
+			note.ShiftStart.Format("15:04"),
+			note.ShiftEnd.Format("15:04")))
+
+		fields := []struct{ label, value string }{
+			{"Open issues", note.OpenIssues},
+			{"Vehicles seen", note.VehiclesSeen},
+			{"Gates open", note.GatesOpen},
+			{"Notes", note.Notes},
+		}
+		for _, field := range fields {
+			if field.value != "" {
+				description.WriteString(fmt.Sprintf("• %s: %s\\n", field.label, escapeICSText(field.value)))
+			}
+		}
+	}
+}
+
 // generateEmptyCalendar creates an empty calendar for users with no shifts
 func generateEmptyCalendar(userID int64) string {
 	var sb strings.Builder
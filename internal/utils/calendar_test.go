@@ -144,6 +144,43 @@ func TestBookingToCalendarEvent(t *testing.T) {
 	}
 }
 
+func TestGenerateUserCalendarFeedIncludesHandoverNotes(t *testing.T) {
+	shiftStart := time.Now().UTC().Add(2 * time.Hour).Truncate(time.Hour)
+	bookings := []db.ListBookingsByUserIDWithScheduleRow{
+		{BookingID: 1, ShiftStart: shiftStart, ShiftEnd: shiftStart.Add(2 * time.Hour), ScheduleName: "Night Patrol"},
+		{BookingID: 2, ShiftStart: shiftStart.Add(24 * time.Hour), ShiftEnd: shiftStart.Add(26 * time.Hour), ScheduleName: "Night Patrol"},
+	}
+	handovers := map[int64][]db.ListIncomingHandoverNotesRow{
+		1: {{
+			UserName:     "Outgoing Owl",
+			ScheduleName: "Evening Patrol",
+			ShiftStart:   shiftStart.Add(-2 * time.Hour),
+			ShiftEnd:     shiftStart,
+			OpenIssues:   "Streetlight out on Main Rd",
+			GatesOpen:    "North gate, school gate",
+		}},
+	}
+
+	content := GenerateUserCalendarFeed(bookings, handovers, 7).Content
+
+	if strings.Count(content, "Handover from previous patrols") != 1 {
+		t.Errorf("Expected the handover section on exactly one event, got content: %s", content)
+	}
+	expected := []string{
+		"Outgoing Owl (Evening Patrol",
+		"Open issues: Streetlight out on Main Rd",
+		"Gates open: North gate\\, school gate",
+	}
+	for _, text := range expected {
+		if !strings.Contains(content, text) {
+			t.Errorf("Calendar feed missing %q", text)
+		}
+	}
+	if strings.Contains(content, "Vehicles seen") {
+		t.Error("Empty handover fields should be left out")
+	}
+}
+
 func TestEscapeICSText(t *testing.T) {
 	tests := []struct {
 		input    string
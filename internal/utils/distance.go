@@ -0,0 +1,16 @@
+package utils
+
+import "math"
+
+const earthRadiusMeters = 6371000.0
+
+// DistanceMeters returns the great-circle distance between two coordinates in metres.
+func DistanceMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
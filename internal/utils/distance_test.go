@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistanceMeters(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lon1, lat2, lon2 float64
+		expected, tolerance    float64
+	}{
+		{"same point", -33.9249, 18.4241, -33.9249, 18.4241, 0, 0.001},
+		{"one degree of latitude", 0, 0, 1, 0, 111195, 10},
+		{"Cape Town to Stellenbosch", -33.9249, 18.4241, -33.9321, 18.8602, 40270, 100},
+	}
+
+	for _, tt := range tests {
+		got := DistanceMeters(tt.lat1, tt.lon1, tt.lat2, tt.lon2)
+		if math.Abs(got-tt.expected) > tt.tolerance {
+			t.Errorf("%s: DistanceMeters = %.1f, expected %.1f", tt.name, got, tt.expected)
+		}
+	}
+}
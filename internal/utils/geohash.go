@@ -0,0 +1,102 @@
+package utils
+
+import "strings"
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// GeohashBounds describes the bounding box covered by a geohash cell.
+type GeohashBounds struct {
+	MinLat float64
+	MaxLat float64
+	MinLon float64
+	MaxLon float64
+}
+
+// Center returns the centre point of the bounding box.
+func (b GeohashBounds) Center() (lat, lon float64) {
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLon + b.MaxLon) / 2
+}
+
+// EncodeGeohash encodes a coordinate as a geohash string of the given precision (1-12 characters).
+func EncodeGeohash(lat, lon float64, precision int) string {
+	if precision < 1 {
+		precision = 1
+	}
+	if precision > 12 {
+		precision = 12
+	}
+
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+
+	var sb strings.Builder
+	sb.Grow(precision)
+
+	bit, ch := 0, 0
+	evenBit := true
+	for sb.Len() < precision {
+		if evenBit {
+			mid := (minLon + maxLon) / 2
+			if lon >= mid {
+				ch = ch<<1 | 1
+				minLon = mid
+			} else {
+				ch <<= 1
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if lat >= mid {
+				ch = ch<<1 | 1
+				minLat = mid
+			} else {
+				ch <<= 1
+				maxLat = mid
+			}
+		}
+		evenBit = !evenBit
+
+		bit++
+		if bit == 5 {
+			sb.WriteByte(geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+
+	return sb.String()
+}
+
+// DecodeGeohashBounds returns the bounding box of a geohash cell.
+// Characters outside the geohash alphabet are ignored.
+func DecodeGeohashBounds(hash string) GeohashBounds {
+	b := GeohashBounds{MinLat: -90, MaxLat: 90, MinLon: -180, MaxLon: 180}
+
+	evenBit := true
+	for _, r := range strings.ToLower(hash) {
+		idx := strings.IndexRune(geohashBase32, r)
+		if idx < 0 {
+			continue
+		}
+		for n := 4; n >= 0; n-- {
+			bitSet := (idx>>n)&1 == 1
+			if evenBit {
+				mid := (b.MinLon + b.MaxLon) / 2
+				if bitSet {
+					b.MinLon = mid
+				} else {
+					b.MaxLon = mid
+				}
+			} else {
+				mid := (b.MinLat + b.MaxLat) / 2
+				if bitSet {
+					b.MinLat = mid
+				} else {
+					b.MaxLat = mid
+				}
+			}
+			evenBit = !evenBit
+		}
+	}
+
+	return b
+}
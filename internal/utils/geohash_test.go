@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEncodeGeohash(t *testing.T) {
+	tests := []struct {
+		lat, lon  float64
+		precision int
+		expected  string
+	}{
+		{57.64911, 10.40744, 11, "u4pruydqqvj"},
+		{42.6, -5.6, 5, "ezs42"},
+		{0, 0, 1, "s"},
+	}
+
+	for _, tt := range tests {
+		got := EncodeGeohash(tt.lat, tt.lon, tt.precision)
+		if got != tt.expected {
+			t.Errorf("EncodeGeohash(%v, %v, %d) = %s, expected %s", tt.lat, tt.lon, tt.precision, got, tt.expected)
+		}
+	}
+}
+
+func TestDecodeGeohashBounds(t *testing.T) {
+	lat, lon := -33.9249, 18.4241
+	hash := EncodeGeohash(lat, lon, 7)
+
+	bounds := DecodeGeohashBounds(hash)
+	if lat < bounds.MinLat || lat > bounds.MaxLat || lon < bounds.MinLon || lon > bounds.MaxLon {
+		t.Errorf("Point (%v, %v) not inside decoded bounds %+v", lat, lon, bounds)
+	}
+
+	centerLat, centerLon := bounds.Center()
+	if math.Abs(centerLat-lat) > 0.01 || math.Abs(centerLon-lon) > 0.01 {
+		t.Errorf("Decoded centre (%v, %v) too far from (%v, %v)", centerLat, centerLon, lat, lon)
+	}
+}
@@ -0,0 +1,285 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"io"
+	"strings"
+)
+
+// A4 page size in PDF points
+const (
+	PDFPageWidth  = 595.28
+	PDFPageHeight = 841.89
+)
+
+// helveticaWidths holds the glyph widths (per 1000 units) of Helvetica for
+// the printable ASCII range 32-126, taken from the standard AFM metrics.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// helveticaBoldWidths holds the same metrics for Helvetica-Bold.
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// winAnsiSpecials maps the characters WinAnsiEncoding places in 0x80-0x9F.
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// pdfEncodeText converts text to WinAnsiEncoding, the encoding used for the
+// standard fonts. Characters outside it are replaced with '?'.
+func pdfEncodeText(text string) []byte {
+	out := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r == '\t':
+			out = append(out, ' ')
+		case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+			out = append(out, byte(r))
+		default:
+			if b, ok := winAnsiSpecials[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+// PDFTextWidth returns the width in points of text set in Helvetica at the given size.
+func PDFTextWidth(text string, size float64, bold bool) float64 {
+	widths := &helveticaWidths
+	if bold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, b := range pdfEncodeText(text) {
+		if b >= 32 && b <= 126 {
+			total += widths[b-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// WrapPDFText splits text into lines no wider than maxWidth, breaking on
+// spaces and keeping explicit line breaks. Words longer than a line are split.
+func WrapPDFText(text string, size float64, bold bool, maxWidth float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			for PDFTextWidth(word, size, bold) > maxWidth {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+				runes := []rune(word)
+				cut := len(runes) - 1
+				for cut > 1 && PDFTextWidth(string(runes[:cut]), size, bold) > maxWidth {
+					cut--
+				}
+				lines = append(lines, string(runes[:cut]))
+				word = string(runes[cut:])
+			}
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if PDFTextWidth(candidate, size, bold) > maxWidth {
+				lines = append(lines, line)
+				candidate = word
+			}
+			line = candidate
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfImage is a JPEG encoded image XObject
+type pdfImage struct {
+	data       []byte
+	width      int
+	height     int
+	colorSpace string
+}
+
+// PDFDocument builds a simple PDF made of text, lines and images using the
+// standard Helvetica fonts, so no font files need to be embedded. Coordinates
+// are in points with the origin at the bottom left of the page.
+type PDFDocument struct {
+	pages   []*bytes.Buffer
+	current *bytes.Buffer
+	images  []pdfImage
+}
+
+// NewPDFDocument creates an empty document. Call AddPage before drawing.
+func NewPDFDocument() *PDFDocument {
+	return &PDFDocument{}
+}
+
+// AddPage starts a new A4 page and makes it the current page.
+func (d *PDFDocument) AddPage() {
+	d.current = &bytes.Buffer{}
+	d.pages = append(d.pages, d.current)
+}
+
+// PageCount returns the number of pages added so far.
+func (d *PDFDocument) PageCount() int {
+	return len(d.pages)
+}
+
+// SetPage makes an earlier page current so more content can be drawn on it,
+// for example page numbers once the total is known. Pages are numbered from 1.
+func (d *PDFDocument) SetPage(page int) {
+	if page >= 1 && page <= len(d.pages) {
+		d.current = d.pages[page-1]
+	}
+}
+
+// Text draws a single line of text with its baseline at y.
+func (d *PDFDocument) Text(x, y, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	var escaped bytes.Buffer
+	for _, b := range pdfEncodeText(text) {
+		if b == '(' || b == ')' || b == '\\' {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteByte(b)
+	}
+	fmt.Fprintf(d.current, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escaped.Bytes())
+}
+
+// Line draws a thin grey line.
+func (d *PDFDocument) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.current, "q 0.6 G 0.5 w %.2f %.2f m %.2f %.2f l S Q\n", x1, y1, x2, y2)
+}
+
+// Image draws img scaled into the box with its bottom left corner at x, y.
+// The image is stored JPEG compressed.
+func (d *PDFDocument) Image(img image.Image, x, y, width, height float64) error {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 80}); err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+	colorSpace := "DeviceRGB"
+	if _, ok := img.(*image.Gray); ok {
+		colorSpace = "DeviceGray"
+	}
+	bounds := img.Bounds()
+	d.images = append(d.images, pdfImage{
+		data:       buf.Bytes(),
+		width:      bounds.Dx(),
+		height:     bounds.Dy(),
+		colorSpace: colorSpace,
+	})
+	fmt.Fprintf(d.current, "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", width, height, x, y, len(d.images))
+	return nil
+}
+
+// WriteTo writes the finished document.
+func (d *PDFDocument) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	beginObject := func() int {
+		offsets = append(offsets, out.Len())
+		id := len(offsets)
+		fmt.Fprintf(&out, "%d 0 obj\n", id)
+		return id
+	}
+	writeStream := func(dict string, data []byte) {
+		fmt.Fprintf(&out, "<< %s /Length %d >>\nstream\n", dict, len(data))
+		out.Write(data)
+		out.WriteString("\nendstream\nendobj\n")
+	}
+
+	// Object numbers are fixed up front: catalog, page tree, the two fonts
+	// and the shared resources, then the images, then a page and its
+	// content stream for each page.
+	const resourcesID = 5
+	firstImageID := resourcesID + 1
+	firstPageID := firstImageID + len(d.images)
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	beginObject()
+	out.WriteString("<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+
+	beginObject()
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPageID+2*i)
+	}
+	fmt.Fprintf(&out, "<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(d.pages))
+
+	for _, font := range []string{"Helvetica", "Helvetica-Bold"} {
+		beginObject()
+		fmt.Fprintf(&out, "<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>\nendobj\n", font)
+	}
+
+	beginObject()
+	var xobjects strings.Builder
+	for i := range d.images {
+		fmt.Fprintf(&xobjects, " /Im%d %d 0 R", i+1, firstImageID+i)
+	}
+	fmt.Fprintf(&out, "<< /Font << /F1 3 0 R /F2 4 0 R >> /XObject <<%s >> >>\nendobj\n", xobjects.String())
+
+	for _, img := range d.images {
+		beginObject()
+		writeStream(fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /%s /BitsPerComponent 8 /Filter /DCTDecode",
+			img.width, img.height, img.colorSpace), img.data)
+	}
+
+	for _, page := range d.pages {
+		pageID := beginObject()
+		fmt.Fprintf(&out, "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources %d 0 R /Contents %d 0 R >>\nendobj\n",
+			PDFPageWidth, PDFPageHeight, resourcesID, pageID+1)
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return 0, err
+		}
+		if err := zw.Close(); err != nil {
+			return 0, err
+		}
+		beginObject()
+		writeStream("/Filter /FlateDecode", compressed.Bytes())
+	}
+
+	xrefOffset := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	return out.WriteTo(w)
+}
@@ -0,0 +1,82 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWrapPDFText(t *testing.T) {
+	lines := WrapPDFText("Suspicious white van parked outside number 12\nDriver left on foot", 10, false, 100)
+	for _, line := range lines {
+		if w := PDFTextWidth(line, 10, false); w > 100 {
+			t.Errorf("line %q is %.1fpt wide, expected at most 100", line, w)
+		}
+	}
+	if got := strings.Join(lines, " "); got != "Suspicious white van parked outside number 12 Driver left on foot" {
+		t.Errorf("wrapping lost words: %q", got)
+	}
+	if lines[len(lines)-1] != "Driver left on foot" {
+		t.Errorf("explicit line break not kept: %q", lines)
+	}
+
+	long := WrapPDFText(strings.Repeat("x", 100), 10, false, 50)
+	if len(long) < 2 {
+		t.Errorf("a word longer than a line should be split, got %q", long)
+	}
+}
+
+func TestPDFDocument_WriteTo(t *testing.T) {
+	doc := NewPDFDocument()
+	doc.AddPage()
+	doc.Text(40, 800, 14, true, "Incident (report) #1")
+	doc.Line(40, 790, 555, 790)
+
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 1, color.RGBA{0xff, 0, 0, 0xff})
+	if err := doc.Image(img, 40, 600, 100, 100); err != nil {
+		t.Fatalf("Image failed: %v", err)
+	}
+	doc.AddPage()
+	doc.Text(40, 800, 10, false, "Café – page two")
+
+	if doc.PageCount() != 2 {
+		t.Fatalf("PageCount = %d, expected 2", doc.PageCount())
+	}
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	pdf := buf.String()
+
+	if !strings.HasPrefix(pdf, "%PDF-1.4") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Error("document is missing the PDF header or trailer")
+	}
+	for _, want := range []string{"/Count 2", "/BaseFont /Helvetica-Bold", "/Subtype /Image /Width 4 /Height 4", "/Im1 6 0 R"} {
+		if !strings.Contains(pdf, want) {
+			t.Errorf("document does not contain %q", want)
+		}
+	}
+
+	// Each xref entry must point at the start of its object
+	xref := strings.Index(pdf, "xref\n")
+	entries := strings.Split(pdf[xref:], "\n")[3:]
+	for i, entry := range entries {
+		if !strings.HasSuffix(entry, " n ") {
+			break
+		}
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil {
+			t.Fatalf("bad xref entry %q", entry)
+		}
+		want := fmt.Sprintf("%d 0 obj", i+1)
+		if !strings.HasPrefix(pdf[offset:], want) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[offset:offset+10])
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaskName reduces a full name to the first name and last initial, e.g.
+// "John Doe" becomes "John D.". A single name is cut to its initial, e.g.
+// "Cher" becomes "C***", and an empty name becomes "Anonymous".
+func MaskName(name string) string {
+	parts := strings.Fields(name)
+	switch len(parts) {
+	case 0:
+		return "Anonymous"
+	case 1:
+		return initial(parts[0]) + "***"
+	}
+
+	return parts[0] + " " + initial(parts[len(parts)-1]) + "."
+}
+
+func initial(name string) string {
+	r, _ := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r))
+}
@@ -0,0 +1,20 @@
+package utils
+
+import "testing"
+
+func TestMaskName(t *testing.T) {
+	tests := map[string]string{
+		"John Doe":            "John D.",
+		"  Mary Anne  smith ": "Mary S.",
+		"Cher":                "C***",
+		" élodie ":            "É***",
+		"":                    "Anonymous",
+		"Zoë Ängström":        "Zoë Ä.",
+	}
+
+	for input, expected := range tests {
+		if got := MaskName(input); got != expected {
+			t.Errorf("MaskName(%q) = %q, expected %q", input, got, expected)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/png" // Cached tiles are PNG
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// ErrMapTileNotCached is returned when a tile needed for a static map is not in the offline cache.
+var ErrMapTileNotCached = errors.New("map tile not in offline cache")
+
+// mapTileSize is the size in pixels of a standard slippy map tile
+const mapTileSize = 256
+
+// RenderStaticMap composes a width x height map centred on lat/lon from an
+// offline cache of slippy map tiles stored as {zoom}/{x}/{y}.png under
+// tileDir, and marks the location with a pin. No network requests are made;
+// ErrMapTileNotCached is returned if any tile needed is missing.
+func RenderStaticMap(tileDir string, zoom int, lat, lon float64, width, height int) (image.Image, error) {
+	if tileDir == "" {
+		return nil, ErrMapTileNotCached
+	}
+
+	// Project to global pixel coordinates in Web Mercator
+	scale := float64(mapTileSize) * math.Exp2(float64(zoom))
+	latRad := lat * math.Pi / 180
+	centerX := (lon + 180) / 360 * scale
+	centerY := (1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * scale
+
+	originX := int(math.Floor(centerX)) - width/2
+	originY := int(math.Floor(centerY)) - height/2
+	tilesPerSide := 1 << zoom
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.RGBA{0xdd, 0xdd, 0xdd, 0xff}), image.Point{}, draw.Src)
+
+	for tileY := floorDiv(originY, mapTileSize); tileY <= floorDiv(originY+height-1, mapTileSize); tileY++ {
+		if tileY < 0 || tileY >= tilesPerSide {
+			continue
+		}
+		for tileX := floorDiv(originX, mapTileSize); tileX <= floorDiv(originX+width-1, mapTileSize); tileX++ {
+			wrappedX := ((tileX % tilesPerSide) + tilesPerSide) % tilesPerSide
+			tile, err := loadMapTile(tileDir, zoom, wrappedX, tileY)
+			if err != nil {
+				return nil, err
+			}
+			offset := image.Pt(tileX*mapTileSize-originX, tileY*mapTileSize-originY)
+			draw.Draw(canvas, tile.Bounds().Sub(tile.Bounds().Min).Add(offset), tile, tile.Bounds().Min, draw.Src)
+		}
+	}
+
+	drawMapPin(canvas, width/2, height/2)
+	return canvas, nil
+}
+
+// loadMapTile reads a single tile from the cache.
+func loadMapTile(tileDir string, zoom, x, y int) (image.Image, error) {
+	path := filepath.Join(tileDir, fmt.Sprint(zoom), fmt.Sprint(x), fmt.Sprintf("%d.png", y))
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %d/%d/%d", ErrMapTileNotCached, zoom, x, y)
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	tile, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode map tile %d/%d/%d: %w", zoom, x, y, err)
+	}
+	return tile, nil
+}
+
+// drawMapPin draws a red dot with a white outline centred on cx, cy.
+func drawMapPin(img *image.RGBA, cx, cy int) {
+	const radius = 8
+	red := color.RGBA{0xd0, 0x10, 0x10, 0xff}
+	for y := -radius; y <= radius; y++ {
+		for x := -radius; x <= radius; x++ {
+			d := x*x + y*y
+			switch {
+			case d <= (radius-3)*(radius-3):
+				img.Set(cx+x, cy+y, red)
+			case d <= radius*radius:
+				img.Set(cx+x, cy+y, color.White)
+			}
+		}
+	}
+}
+
+// floorDiv divides rounding towards negative infinity.
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenderStaticMap(t *testing.T) {
+	const zoom = 2
+	tileDir := t.TempDir()
+
+	// Lat/lon 0,0 sits on the corner of tiles 1/1, 2/1, 1/2 and 2/2 at zoom 2
+	writeTile := func(x, y int) {
+		dir := filepath.Join(tileDir, "2", string(rune('0'+x)))
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		tile := image.NewRGBA(image.Rect(0, 0, mapTileSize, mapTileSize))
+		for i := range tile.Pix {
+			tile.Pix[i] = 0xff
+		}
+		tile.Set(0, 0, color.RGBA{0, 0, 0xff, 0xff})
+		f, err := os.Create(filepath.Join(dir, string(rune('0'+y))+".png"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if err := png.Encode(f, tile); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTile(1, 1)
+	writeTile(2, 1)
+	writeTile(1, 2)
+
+	if _, err := RenderStaticMap(tileDir, zoom, 0, 0, 100, 60); !errors.Is(err, ErrMapTileNotCached) {
+		t.Fatalf("expected ErrMapTileNotCached with a tile missing, got %v", err)
+	}
+	if _, err := RenderStaticMap("", zoom, 0, 0, 100, 60); !errors.Is(err, ErrMapTileNotCached) {
+		t.Fatalf("expected ErrMapTileNotCached without a cache, got %v", err)
+	}
+
+	writeTile(2, 2)
+	img, err := RenderStaticMap(tileDir, zoom, 0, 0, 100, 60)
+	if err != nil {
+		t.Fatalf("RenderStaticMap failed: %v", err)
+	}
+	if img.Bounds().Dx() != 100 || img.Bounds().Dy() != 60 {
+		t.Errorf("map is %v, expected 100x60", img.Bounds())
+	}
+	if r, g, b, _ := img.At(50, 30).RGBA(); r>>8 != 0xd0 || g>>8 != 0x10 || b>>8 != 0x10 {
+		t.Errorf("expected the pin at the centre, got %v", img.At(50, 30))
+	}
+	if r, g, b, _ := img.At(5, 5).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+		t.Errorf("expected tile content away from the pin, got %v", img.At(5, 5))
+	}
+}
@@ -0,0 +1,166 @@
+package utils
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// XLSXMaxRows is the number of rows a worksheet can hold
+	XLSXMaxRows = 1048576
+	// xlsxMaxCellLength is the number of characters a cell can hold
+	xlsxMaxCellLength = 32767
+)
+
+// ErrXLSXTooManyRows is returned when a row would not fit in the worksheet.
+var ErrXLSXTooManyRows = errors.New("worksheet row limit reached")
+
+// xlsxStaticParts are the package parts of a workbook with a single sheet.
+// Styles define one extra cell format, a bold font, used for the header row.
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	{"xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+// XLSXWriter streams rows into a single sheet Excel workbook. Strings are
+// written inline rather than to a shared string table, so nothing has to be
+// held in memory between rows. Call Close to finish the workbook.
+type XLSXWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+// NewXLSXWriter writes the workbook parts and opens the named sheet for rows.
+func NewXLSXWriter(w io.Writer, sheetName string) (*XLSXWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		if err := xlsxWritePart(zw, part.name, part.content); err != nil {
+			return nil, err
+		}
+	}
+	workbook := `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + xlsxEscape(sheetName) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	if err := xlsxWritePart(zw, "xl/workbook.xml", workbook); err != nil {
+		return nil, err
+	}
+
+	part, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	sheet := bufio.NewWriter(part)
+	fmt.Fprint(sheet, xml.Header)
+	fmt.Fprint(sheet, `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return &XLSXWriter{zw: zw, sheet: sheet}, nil
+}
+
+// xlsxWritePart adds a complete XML part to the package.
+func xlsxWritePart(zw *zip.Writer, name, content string) error {
+	part, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, xml.Header+content)
+	return err
+}
+
+// xlsxEscape returns s escaped for XML, truncated to the cell length limit.
+// Characters XML cannot represent are replaced.
+func xlsxEscape(s string) string {
+	if utf8.RuneCountInString(s) > xlsxMaxCellLength {
+		s = string([]rune(s)[:xlsxMaxCellLength])
+	}
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// WriteHeader writes a row of bold column titles.
+func (x *XLSXWriter) WriteHeader(titles []string) error {
+	values := make([]interface{}, len(titles))
+	for i, title := range titles {
+		values[i] = title
+	}
+	return x.writeRow(values, ` s="1"`)
+}
+
+// WriteRow writes one row. Integers and floats become numeric cells, bools
+// become boolean cells, times are written as UTC text and nil leaves the
+// cell empty. Anything else is written as text.
+func (x *XLSXWriter) WriteRow(values []interface{}) error {
+	return x.writeRow(values, "")
+}
+
+func (x *XLSXWriter) writeRow(values []interface{}, style string) error {
+	if x.rows >= XLSXMaxRows {
+		return ErrXLSXTooManyRows
+	}
+	x.rows++
+
+	fmt.Fprint(x.sheet, "<row>")
+	for _, value := range values {
+		switch v := value.(type) {
+		case nil:
+			fmt.Fprintf(x.sheet, "<c%s/>", style)
+		case int:
+			fmt.Fprintf(x.sheet, "<c%s><v>%d</v></c>", style, v)
+		case int64:
+			fmt.Fprintf(x.sheet, "<c%s><v>%d</v></c>", style, v)
+		case float64:
+			fmt.Fprintf(x.sheet, "<c%s><v>%s</v></c>", style, strconv.FormatFloat(v, 'f', -1, 64))
+		case bool:
+			b := 0
+			if v {
+				b = 1
+			}
+			fmt.Fprintf(x.sheet, `<c t="b"%s><v>%d</v></c>`, style, b)
+		case time.Time:
+			fmt.Fprintf(x.sheet, `<c t="inlineStr"%s><is><t>%s</t></is></c>`, style, v.UTC().Format("2006-01-02 15:04:05"))
+		default:
+			fmt.Fprintf(x.sheet, `<c t="inlineStr"%s><is><t xml:space="preserve">%s</t></is></c>`, style, xlsxEscape(fmt.Sprint(v)))
+		}
+	}
+	_, err := fmt.Fprint(x.sheet, "</row>")
+	return err
+}
+
+// Close finishes the sheet and writes the zip directory. It does not close
+// the underlying writer.
+func (x *XLSXWriter) Close() error {
+	fmt.Fprint(x.sheet, "</sheetData></worksheet>")
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
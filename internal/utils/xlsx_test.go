@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	x, err := NewXLSXWriter(&buf, "Reports")
+	if err != nil {
+		t.Fatalf("NewXLSXWriter failed: %v", err)
+	}
+	if err := x.WriteHeader([]string{"id", "message", "latitude", "archived", "created_at", "category"}); err != nil {
+		t.Fatalf("WriteHeader failed: %v", err)
+	}
+	created := time.Date(2025, 3, 1, 22, 15, 0, 0, time.FixedZone("SAST", 2*60*60))
+	if err := x.WriteRow([]interface{}{int64(7), "Gate <open> & \x01unlocked", -33.9249, true, created, nil}); err != nil {
+		t.Fatalf("WriteRow failed: %v", err)
+	}
+	if err := x.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("workbook is not a valid zip: %v", err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(content)
+
+		// Every part must be well formed XML
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s is not well formed: %v", f.Name, err)
+			}
+		}
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("workbook is missing %s", name)
+		}
+	}
+	if !strings.Contains(parts["xl/workbook.xml"], `<sheet name="Reports"`) {
+		t.Error("sheet name not set")
+	}
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c t="inlineStr" s="1"><is><t xml:space="preserve">id</t></is></c>`,
+		`<c><v>7</v></c>`,
+		`Gate &lt;open&gt; &amp; `,
+		`<c><v>-33.9249</v></c>`,
+		`<c t="b"><v>1</v></c>`,
+		`<t>2025-03-01 20:15:00</t>`,
+		`<c/></row>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet is missing %q:\n%s", want, sheet)
+		}
+	}
+	if strings.Count(sheet, "<row>") != 2 {
+		t.Errorf("expected 2 rows, got %d", strings.Count(sheet, "<row>"))
+	}
+}
+
+func TestXLSXWriter_TruncatesLongCells(t *testing.T) {
+	if got := xlsxEscape(strings.Repeat("a", xlsxMaxCellLength+10)); len(got) != xlsxMaxCellLength {
+		t.Errorf("long cell was not truncated, length %d", len(got))
+	}
+}